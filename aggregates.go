@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"cppimpbot/scraper"
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dghubble/go-twitter/twitter"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var aggregatesCommand = &cobra.Command{
+	Use:   "aggregates [standard]",
+	Short: "Print how much of every standard each compiler supports, or how that grew with --history",
+	Long: `Print how many of the current features of every standard, core language and library apart, each compiler supports
+fully and partially. A standard like C++20 or C23 limits it to that one. With --history the recorded series of the
+standard and the --compiler is printed instead, as recorded every AggregateInterval.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: aggregatesCmdFunc,
+}
+
+var aggregatesCompiler string
+var aggregatesHistory bool
+
+func init() {
+	aggregatesCommand.Flags().StringVar(&aggregatesCompiler, "compiler", "", "only print the support of this compiler, like gcc")
+	aggregatesCommand.Flags().BoolVar(&aggregatesHistory, "history", false, "print the recorded series of the standard and compiler")
+}
+
+// recordAggregates adds the aggregates of the latest entries that changed to their series, and posts the milestones
+// they reached if MilestoneReports is set, phrased by the templates. the aggregates of milestones that could not be posted are left out, so they
+// are posted on the next run. nothing is recorded while reporting is paused, as the changes may be a broken scrape
+func recordAggregates(cfg *Configuration, templates *compliance.MilestoneTemplates, service compliance.Service, post func(text string) error) error {
+	if cfg.MilestoneReports {
+		pause, err := service.GetReportingPause(context.Background())
+		if err != nil {
+			return err
+		}
+		if pause.Paused() {
+			slog.Debug("reporting is paused, not recording aggregates")
+			return nil
+		}
+	}
+
+	latest, err := service.GetLatestEntries(context.Background())
+	if err != nil {
+		return err
+	}
+
+	stored, err := service.GetLatestAggregates(context.Background())
+	if err != nil {
+		return err
+	}
+
+	current := compliance.ComputeAggregates(latest, time.Now())
+	changed := compliance.ChangedAggregates(stored, current)
+
+	if cfg.MilestoneReports {
+		milestones := compliance.Milestones(stored, current, cfg.Milestones)
+		for i, milestone := range milestones {
+			text, err := templates.Render(milestone)
+			if err == nil {
+				err = post(text)
+			}
+			if err != nil {
+				slog.Error("error posting milestone, trying again on the next run", "channel", compliance.ChannelTwitter, "err", err)
+				changed = withoutMilestones(changed, milestones[i:])
+				break
+			}
+		}
+	}
+
+	if len(changed) == 0 {
+		return nil
+	}
+
+	if err := service.StoreAggregates(context.Background(), changed); err != nil {
+		return err
+	}
+	slog.Info("recorded aggregates", "changed", len(changed), "aggregates", len(current))
+
+	return nil
+}
+
+// withoutMilestones leaves out the aggregates of the milestones
+func withoutMilestones(aggregates []compliance.Aggregate, milestones []compliance.Milestone) []compliance.Aggregate {
+	var result []compliance.Aggregate
+
+	for _, aggregate := range aggregates {
+		reached := false
+		for _, milestone := range milestones {
+			other := milestone.Aggregate
+			reached = reached || aggregate.Language == other.Language && aggregate.CppVersion == other.CppVersion &&
+				aggregate.Kind == other.Kind && aggregate.Compiler == other.Compiler
+		}
+
+		if !reached {
+			result = append(result, aggregate)
+		}
+	}
+
+	return result
+}
+
+func aggregateJob(cfg *Configuration, templates *compliance.MilestoneTemplates, client *twitter.Client, service compliance.Service) func() {
+	return func() {
+		err := recordAggregates(cfg, templates, service, func(text string) error {
+			return postTweet(cfg, client, text)
+		})
+		if err != nil {
+			slog.Error("error recording aggregates", "err", err)
+		}
+	}
+}
+
+func aggregatesCmdFunc(cmd *cobra.Command, args []string) error {
+	cfg := &Configuration{}
+
+	if err := viper.Unmarshal(cfg); err != nil {
+		return err
+	}
+
+	if cfg.StorageMode != "sqlite3" {
+		return fmt.Errorf("aggregates needs storageMode sqlite3, not %s", cfg.StorageMode)
+	}
+
+	language, version := "", 0
+	if len(args) > 0 {
+		var err error
+		if language, version, err = scraper.ParseStandard(args[0]); err != nil {
+			return fmt.Errorf("'%v' is not a standard like C++20 or C23", args[0])
+		}
+	}
+
+	if aggregatesHistory && (language == "" || aggregatesCompiler == "") {
+		return fmt.Errorf("--history needs a standard and a --compiler")
+	}
+
+	complianceStorageService, err := openSqliteService(cfg)
+	if err != nil {
+		return err
+	}
+	defer complianceStorageService.Close(context.Background())
+
+	var aggregates []compliance.Aggregate
+	if aggregatesHistory {
+		aggregates, err = complianceStorageService.GetAggregateSeries(context.Background(), language, version, aggregatesCompiler)
+	} else {
+		var latest []compliance.Feature
+		latest, err = complianceStorageService.GetLatestEntries(context.Background())
+		aggregates = compliance.ComputeAggregates(latest, time.Now())
+	}
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	if aggregatesHistory {
+		fmt.Fprintln(writer, "recorded\tkind\tfull\tpartial\tfeatures")
+	} else {
+		fmt.Fprintln(writer, "standard\tkind\tcompiler\tfull\tpartial\tfeatures")
+	}
+
+	for _, aggregate := range aggregates {
+		if language != "" && (aggregate.Language != language || aggregate.CppVersion != version) ||
+			aggregatesCompiler != "" && aggregate.Compiler != aggregatesCompiler {
+			continue
+		}
+
+		full := fmt.Sprintf("%v%% (%v)", aggregate.FullPercent(), aggregate.Full)
+		partial := fmt.Sprintf("%v%% (%v)", aggregate.PartialPercent(), aggregate.Partial)
+		if aggregatesHistory {
+			fmt.Fprintf(writer, "%v\t%v\t%v\t%v\t%v\n", aggregate.Timestamp.Format(historyTimeFormat), compliance.KindName(aggregate.Kind),
+				full, partial, aggregate.Total)
+		} else {
+			fmt.Fprintf(writer, "%v\t%v\t%v\t%v\t%v\t%v\n", compliance.StandardName(aggregate.Language, aggregate.CppVersion),
+				compliance.KindName(aggregate.Kind), compliance.CompilerDisplayName(aggregate.Compiler), full, partial, aggregate.Total)
+		}
+	}
+
+	return writer.Flush()
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRecordAggregates(t *testing.T) {
+	service := compliance.NewMemoryService()
+	features := []compliance.Feature{
+		{Name: "Concepts", CppVersion: 20, Compilers: []compliance.CompilerSupport{testSupport("gcc", 1, "10", ""), testSupport("clang", 1, "10", "")}},
+		{Name: "Modules", CppVersion: 20, Compilers: []compliance.CompilerSupport{testSupport("gcc", 2, "11", ""), testSupport("clang", 1, "16", "")}},
+		{Name: "Coroutines", CppVersion: 20, Compilers: []compliance.CompilerSupport{testSupport("gcc", 1, "10", ""), testSupport("clang", 0, "", "")}},
+		{Name: "Ranges", CppVersion: 20, Compilers: []compliance.CompilerSupport{testLibrarySupport("libstdcxx", 1, "10", "")}},
+		{Name: "Deducing this", CppVersion: 23, Compilers: []compliance.CompilerSupport{testSupport("gcc", 0, "", "")}},
+	}
+	for i := range features {
+		if err := service.CreateEntry(context.Background(), &features[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := &Configuration{MilestoneReports: true, Milestones: []int{50, 75, 90, 100}}
+	templates, err := compliance.NewMilestoneTemplates(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var posted []string
+	post := func(text string) error {
+		posted = append(posted, text)
+		return nil
+	}
+
+	//the first aggregates are only recorded, there is nothing they passed yet
+	if err := recordAggregates(cfg, templates, service, post); err != nil || len(posted) != 0 {
+		t.Fatalf("first run posted %q, %v", posted, err)
+	}
+
+	for _, update := range []struct {
+		name    string
+		support compliance.CompilerSupport
+	}{
+		{"Modules", testSupport("gcc", 1, "14", "")},
+		{"Coroutines", testSupport("clang", 2, "17", "")},
+		{"Deducing this", testSupport("gcc", 1, "14", "")},
+	} {
+		for i := range features {
+			if features[i].Name == update.name {
+				features[i].SetSupport(update.support)
+				if err := service.CreateEntry(context.Background(), &features[i]); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+	}
+
+	//the milestone that failed to post is posted on the next run
+	failing := true
+	if err := recordAggregates(cfg, templates, service, func(text string) error {
+		if failing && strings.Contains(text, "C++23") {
+			failing = false
+			return fmt.Errorf("twitter is down")
+		}
+		return post(text)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := recordAggregates(cfg, templates, service, post); err != nil {
+		t.Fatal(err)
+	}
+	if err := recordAggregates(cfg, templates, service, post); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"[Milestone] 🎉 GCC completes C++20 core language support! All 3 features are fully supported.",
+		"[Milestone] 🎉 GCC completes C++23 core language support! Its only feature is fully supported.",
+	}
+	if !reflect.DeepEqual(posted, want) {
+		t.Errorf("got posts %q, want %q", posted, want)
+	}
+
+	latest, err := service.GetLatestAggregates(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var aggregates []string
+	for _, aggregate := range latest {
+		aggregates = append(aggregates, fmt.Sprintf("%v %v %v: %v%% full, %v%% partial of %v", compliance.StandardName(aggregate.Language, aggregate.CppVersion),
+			compliance.KindName(aggregate.Kind), aggregate.Compiler, aggregate.FullPercent(), aggregate.PartialPercent(), aggregate.Total))
+	}
+	wantAggregates := []string{
+		"C++20 core language clang: 66% full, 33% partial of 3",
+		"C++20 core language gcc: 100% full, 0% partial of 3",
+		"C++20 library libstdcxx: 100% full, 0% partial of 1",
+		"C++23 core language gcc: 100% full, 0% partial of 1",
+	}
+	if !reflect.DeepEqual(aggregates, wantAggregates) {
+		t.Errorf("got aggregates %q, want %q", aggregates, wantAggregates)
+	}
+
+	if series, err := service.GetAggregateSeries(context.Background(), compliance.LanguageCpp, 20, "gcc"); len(series) != 2 || err != nil {
+		t.Errorf("got %v recordings of gcc C++20, want 2: %v", len(series), err)
+	}
+}
@@ -0,0 +1,160 @@
+//go:build !noanimation
+// +build !noanimation
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"cppimpbot/animation"
+	"cppimpbot/compliance"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var animateCommand = &cobra.Command{
+	Use:   "animate",
+	Short: "Render the support matrix at the end of every month into an animated GIF",
+	Long: `Render the support matrix at the end of every month into an animated GIF, for year-in-review posts.
+Every vendor gets a panel with a square per feature, colored by its support at that time.
+--from and --to are months like 2019-01, and default to the whole stored history.`,
+	RunE: animateCmdFunc,
+}
+
+var animateOutput string
+var animateFrom string
+var animateTo string
+var animateDelay int
+var animateHold int
+
+func init() {
+	//registered here rather than in main, as the noanimation build tag leaves the command out
+	rootCommand.AddCommand(animateCommand)
+
+	animateCommand.Flags().StringVarP(&animateOutput, "output", "o", "matrix.gif", "file to write the GIF to")
+	animateCommand.Flags().StringVar(&animateFrom, "from", "", "first month, like 2019-01")
+	animateCommand.Flags().StringVar(&animateTo, "to", "", "last month, like 2019-12")
+	animateCommand.Flags().IntVar(&animateDelay, "delay", 500, "milliseconds every month is shown")
+	animateCommand.Flags().IntVar(&animateHold, "hold", 3000, "milliseconds the last month is shown")
+}
+
+// monthFlag parses a month given on the command line, or gives the fallback if it is empty
+func monthFlag(name string, value string, fallback time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Date(fallback.Year(), fallback.Month(), 1, 0, 0, 0, 0, time.UTC), nil
+	}
+
+	month, err := time.Parse("2006-01", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("--%s must be a month like 2019-01", name)
+	}
+
+	return month, nil
+}
+
+// animationFrames gets the features at the end of every month from first to last. the last month ends now at the latest
+func animationFrames(service compliance.Service, first time.Time, last time.Time) ([]animation.Frame, error) {
+	var frames []animation.Frame
+	now := time.Now()
+
+	for month := first; !month.After(last); month = month.AddDate(0, 1, 0) {
+		at := month.AddDate(0, 1, 0)
+		if at.After(now) {
+			at = now
+		}
+
+		features, err := service.GetEntriesAt(context.Background(), at)
+		if err != nil {
+			return nil, err
+		}
+
+		//the matrix has a row per C++ version, C versions would mix into the rows of their namesakes
+		frames = append(frames, animation.Frame{Time: month, Features: compliance.CurrentFeatures(compliance.FeaturesOfLanguage(features, compliance.LanguageCpp))})
+	}
+
+	return frames, nil
+}
+
+func animateCmdFunc(cmd *cobra.Command, args []string) error {
+	cfg := &Configuration{}
+
+	if err := viper.Unmarshal(cfg); err != nil {
+		return err
+	}
+
+	if cfg.StorageMode != "sqlite3" {
+		return fmt.Errorf("animate needs storageMode sqlite3, not %s", cfg.StorageMode)
+	}
+
+	complianceStorageService, err := openSqliteService(cfg)
+	if err != nil {
+		return err
+	}
+	defer complianceStorageService.Close(context.Background())
+
+	history, err := complianceStorageService.GetEntriesSince(context.Background(), time.Time{})
+	if err != nil {
+		return err
+	}
+	if len(history) == 0 {
+		return fmt.Errorf("there is no stored history to animate")
+	}
+
+	first, err := monthFlag("from", animateFrom, history[0].Timestamp)
+	if err != nil {
+		return err
+	}
+	last, err := monthFlag("to", animateTo, time.Now())
+	if err != nil {
+		return err
+	}
+	if last.Before(first) {
+		return fmt.Errorf("--to is before --from")
+	}
+
+	frames, err := animationFrames(complianceStorageService, first, last)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(animateOutput)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	err = animation.Render(file, frames, time.Duration(animateDelay)*time.Millisecond, time.Duration(animateHold)*time.Millisecond)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("rendered animation", "months", len(frames), "output", animateOutput)
+
+	return nil
+}
+
+// yearAnimation renders the support matrix at the end of every month of the year as a GIF
+func yearAnimation(service compliance.Service, year int) ([]byte, error) {
+	first := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	last := time.Date(year, time.December, 1, 0, 0, 0, 0, time.UTC)
+	if now := time.Now(); last.After(now) {
+		last = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	frames, err := animationFrames(service, first, last)
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	if err := animation.Render(&buffer, frames, yearReviewFrames*time.Millisecond, yearReviewHold*time.Millisecond); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
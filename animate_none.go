@@ -0,0 +1,18 @@
+//go:build noanimation
+// +build noanimation
+
+package main
+
+import (
+	"cppimpbot/compliance"
+)
+
+// yearAnimation renders nothing, as image rendering was left out of the build. the year review goes without a GIF
+func yearAnimation(service compliance.Service, year int) ([]byte, error) {
+	return nil, nil
+}
+
+// reportCard renders nothing, reports go without image cards
+func reportCard(cfg *Configuration, change compliance.Change) []byte {
+	return nil
+}
@@ -0,0 +1,224 @@
+package animation
+
+import (
+	"cppimpbot/compliance"
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Frame is the support of every feature at one point in time, as given by GetEntriesAt
+type Frame struct {
+	Time     time.Time
+	Features []compliance.Feature
+}
+
+const (
+	cellSize     = 4  //pixels per feature square, including the gap to the next one
+	panelColumns = 16 //feature squares per row of a panel
+	margin       = 8
+	labelScale   = 2 //scale of the date label. vendor names are drawn unscaled
+)
+
+// palette indices
+const (
+	colorBackground = iota
+	colorText
+	colorUnlisted
+	colorNo
+	colorPartial
+	colorYes
+)
+
+var palette = color.Palette{
+	colorBackground: color.RGBA{0xff, 0xff, 0xff, 0xff},
+	colorText:       color.RGBA{0x22, 0x22, 0x22, 0xff},
+	colorUnlisted:   color.RGBA{0xe8, 0xe8, 0xe8, 0xff},
+	colorNo:         color.RGBA{0xe5, 0x73, 0x73, 0xff},
+	colorPartial:    color.RGBA{0xe0, 0xc0, 0x40, 0xff},
+	colorYes:        color.RGBA{0x4c, 0xaf, 0x50, 0xff},
+}
+
+// panel is the square of one vendor, with a square per feature of its kind
+type panel struct {
+	vendor string
+	x      int
+	y      int
+}
+
+// group is the panels of all vendors of a kind, side by side
+type group struct {
+	slugs  []string
+	panels []panel
+	height int
+}
+
+type featureKey struct {
+	kind       string
+	cppVersion int
+	name       string
+}
+
+// groups lays out a panel per vendor for compilers and for libraries. features keep their square in every frame, ordered
+// by C++ version and name as they were last listed
+func groups(frames []Frame, top int) (result []group, width int, height int) {
+	keys := map[string]featureKey{}
+	var vendorOrder []string
+	vendorKinds := map[string]string{}
+
+	for _, frame := range frames {
+		for _, feature := range frame.Features {
+			kind := feature.Kind()
+			keys[feature.Slug] = featureKey{kind, feature.CppVersion, feature.Name}
+
+			for _, support := range feature.Compilers {
+				if _, ok := vendorKinds[support.Compiler]; !ok {
+					vendorKinds[support.Compiler] = kind
+					vendorOrder = append(vendorOrder, support.Compiler)
+				}
+			}
+		}
+	}
+
+	y := top
+	for _, kind := range []string{compliance.KindCompiler, compliance.KindLibrary} {
+		current := group{}
+
+		for slug, key := range keys {
+			if key.kind == kind {
+				current.slugs = append(current.slugs, slug)
+			}
+		}
+		if len(current.slugs) == 0 {
+			continue
+		}
+
+		sort.Slice(current.slugs, func(i, j int) bool {
+			a, b := keys[current.slugs[i]], keys[current.slugs[j]]
+			if a.cppVersion != b.cppVersion {
+				return a.cppVersion < b.cppVersion
+			}
+			return a.name < b.name
+		})
+
+		//the primary vendors come first even if they list nothing yet
+		primary := compliance.PrimaryCompilers
+		if kind == compliance.KindLibrary {
+			primary = compliance.PrimaryLibraries
+		}
+		vendors := append([]string(nil), primary...)
+		for _, vendor := range vendorOrder {
+			if vendorKinds[vendor] == kind && !contains(vendors, vendor) {
+				vendors = append(vendors, vendor)
+			}
+		}
+
+		rows := (len(current.slugs) + panelColumns - 1) / panelColumns
+		current.height = glyphHeight + 2 + rows*cellSize
+
+		x := margin
+		for _, vendor := range vendors {
+			current.panels = append(current.panels, panel{vendor: vendor, x: x, y: y})
+			x += panelColumns*cellSize + margin
+		}
+
+		if x > width {
+			width = x
+		}
+		y += current.height + margin
+		result = append(result, current)
+	}
+
+	return result, width, y
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+func supportColor(feature *compliance.Feature, vendor string) uint8 {
+	if feature == nil {
+		return colorUnlisted
+	}
+
+	support := feature.SupportFor(vendor)
+	if support == nil {
+		return colorUnlisted
+	}
+
+	return levelColor(support.Support)
+}
+
+func levelColor(support int) uint8 {
+	switch support {
+	case compliance.SupportYes:
+		return colorYes
+	case compliance.SupportNo:
+		return colorNo
+	default:
+		return colorPartial
+	}
+}
+
+// Render writes the frames as an animated GIF that loops forever. every frame shows for delay, the last one for hold
+func Render(w io.Writer, frames []Frame, delay time.Duration, hold time.Duration) error {
+	if len(frames) == 0 {
+		return errors.New("no frames to render")
+	}
+
+	top := margin + glyphHeight*labelScale + margin
+	layout, width, height := groups(frames, top)
+	if len(layout) == 0 {
+		return errors.New("no features in any frame")
+	}
+
+	maxLabelLength := (panelColumns*cellSize + 1) / glyphAdvance
+
+	animation := &gif.GIF{}
+
+	for i, frame := range frames {
+		img := image.NewPaletted(image.Rect(0, 0, width, height), palette)
+		drawText(img, margin, margin, frame.Time.Format("2006-01"), labelScale, colorText)
+
+		bySlug := map[string]*compliance.Feature{}
+		for j := range frame.Features {
+			bySlug[frame.Features[j].Slug] = &frame.Features[j]
+		}
+
+		for _, current := range layout {
+			for _, vendorPanel := range current.panels {
+				label := []rune(compliance.CompilerDisplayName(vendorPanel.vendor))
+				if len(label) > maxLabelLength {
+					label = label[:maxLabelLength]
+				}
+				drawText(img, vendorPanel.x, vendorPanel.y, string(label), 1, colorText)
+
+				for index, slug := range current.slugs {
+					x := vendorPanel.x + (index%panelColumns)*cellSize
+					y := vendorPanel.y + glyphHeight + 2 + (index/panelColumns)*cellSize
+					fillRect(img, x, y, cellSize-1, cellSize-1, supportColor(bySlug[slug], vendorPanel.vendor))
+				}
+			}
+		}
+
+		frameDelay := delay
+		if i == len(frames)-1 {
+			frameDelay = hold
+		}
+
+		animation.Image = append(animation.Image, img)
+		animation.Delay = append(animation.Delay, int(frameDelay/(10*time.Millisecond)))
+	}
+
+	return gif.EncodeAll(w, animation)
+}
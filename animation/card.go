@@ -0,0 +1,159 @@
+package animation
+
+import (
+	"cppimpbot/compliance"
+	"image"
+	"image/png"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	cardScale     = 3  //scale of all text on cards
+	cardPadding   = 6  //pixels around the text of a cell
+	cardMinChars  = 36 //characters a title line holds at least, so short tables don't squeeze long names
+	cardCellChars = 14 //characters of support text shown in a cell, longer texts are cut
+)
+
+// cardColumns are the headers of the support table of a card
+var cardColumns = []string{"compiler", "before", "after"}
+
+// HasCard tells if the change has a support table worth drawing, which changes of the feature itself don't have
+func HasCard(change compliance.Change) bool {
+	return change.Feature != nil && len(change.Deltas) > 0
+}
+
+// deltaColor is the color of a support level on cards. the before column of new listings is unlisted
+func deltaColor(support compliance.CompilerSupport, listed bool) uint8 {
+	if !listed {
+		return colorUnlisted
+	}
+
+	return levelColor(support.Support)
+}
+
+// deltaText is what a cell shows of a support level: the version it came with, or the level if there is none
+func deltaText(support compliance.CompilerSupport, listed bool) string {
+	if !listed {
+		return "-"
+	}
+
+	text := support.DisplayText.String
+	if text == "" {
+		text = compliance.SupportLevelName(support.Support)
+	}
+	if len(text) > cardCellChars {
+		text = text[:cardCellChars-1] + "."
+	}
+
+	return text
+}
+
+// wrapLines breaks the text into lines of at most width characters, at spaces where it can
+func wrapLines(text string, width int) (lines []string) {
+	line := ""
+	for _, word := range strings.Fields(text) {
+		for len(word) > width {
+			if line != "" {
+				lines = append(lines, line)
+				line = ""
+			}
+			lines = append(lines, word[:width])
+			word = word[width:]
+		}
+
+		if line == "" {
+			line = word
+		} else if len(line)+1+len(word) <= width {
+			line += " " + word
+		} else {
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+
+	return
+}
+
+// RenderCard writes a PNG of the support of the changed compilers before and after the change, with the standard and
+// name of the feature above. the table shows what text reports of features with long names and many compilers can't fit
+func RenderCard(w io.Writer, change compliance.Change) error {
+	if !HasCard(change) {
+		return errors.Errorf("a %v change has no support table", change.Kind)
+	}
+
+	advance := glyphAdvance * cardScale
+	lineHeight := (glyphHeight + 2) * cardScale
+	rowHeight := glyphHeight*cardScale + 2*cardPadding
+
+	rows := [][]string{cardColumns}
+	for _, delta := range change.Deltas {
+		rows = append(rows, []string{
+			compliance.CompilerDisplayName(delta.Compiler),
+			deltaText(delta.Previous, change.Previous != nil),
+			deltaText(delta.Next, true),
+		})
+	}
+
+	columnWidths := make([]int, len(cardColumns))
+	tableWidth := 0
+	for column := range cardColumns {
+		for _, row := range rows {
+			if width := textWidth(row[column], cardScale) + 2*cardPadding; width > columnWidths[column] {
+				columnWidths[column] = width
+			}
+		}
+		tableWidth += columnWidths[column]
+	}
+
+	titleChars := tableWidth / advance
+	if titleChars < cardMinChars {
+		titleChars = cardMinChars
+	}
+	title := wrapLines("["+change.Feature.Standard()+"] "+change.Feature.Name, titleChars)
+
+	width := titleChars*advance + 2*margin
+	if tableWidth+2*margin > width {
+		width = tableWidth + 2*margin
+	}
+	height := margin + len(title)*lineHeight + margin + len(rows)*rowHeight + margin
+
+	img := image.NewPaletted(image.Rect(0, 0, width, height), palette)
+
+	y := margin
+	for _, line := range title {
+		drawText(img, margin, y, line, cardScale, colorText)
+		y += lineHeight
+	}
+	y += margin
+
+	for r, row := range rows {
+		x := margin
+		for column, text := range row {
+			if r > 0 && column > 0 {
+				delta := change.Deltas[r-1]
+				if column == 1 {
+					fillRect(img, x, y, columnWidths[column]-1, rowHeight-1, deltaColor(delta.Previous, change.Previous != nil))
+				} else {
+					fillRect(img, x, y, columnWidths[column]-1, rowHeight-1, deltaColor(delta.Next, true))
+				}
+			} else if r == 0 {
+				fillRect(img, x, y, columnWidths[column]-1, rowHeight-1, colorUnlisted)
+			}
+			drawText(img, x+cardPadding, y+cardPadding, text, cardScale, colorText)
+			x += columnWidths[column]
+		}
+		y += rowHeight
+	}
+
+	if err := png.Encode(w, img); err != nil {
+		return errors.Wrap(err, "could not encode card")
+	}
+
+	return nil
+}
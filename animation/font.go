@@ -0,0 +1,109 @@
+package animation
+
+import (
+	"image"
+	"strings"
+)
+
+const (
+	glyphWidth   = 3
+	glyphHeight  = 5
+	glyphAdvance = glyphWidth + 1
+)
+
+// a tiny bitmap font for labels, so no font files are needed. every glyph is 3x5 pixels, # is set
+var glyphs = map[rune][glyphHeight]string{
+	'A':  {"###", "#.#", "###", "#.#", "#.#"},
+	'B':  {"##.", "#.#", "##.", "#.#", "##."},
+	'C':  {"###", "#..", "#..", "#..", "###"},
+	'D':  {"##.", "#.#", "#.#", "#.#", "##."},
+	'E':  {"###", "#..", "##.", "#..", "###"},
+	'F':  {"###", "#..", "##.", "#..", "#.."},
+	'G':  {"###", "#..", "#.#", "#.#", "###"},
+	'H':  {"#.#", "#.#", "###", "#.#", "#.#"},
+	'I':  {"###", ".#.", ".#.", ".#.", "###"},
+	'J':  {"..#", "..#", "..#", "#.#", "###"},
+	'K':  {"#.#", "#.#", "##.", "#.#", "#.#"},
+	'L':  {"#..", "#..", "#..", "#..", "###"},
+	'M':  {"#.#", "###", "###", "#.#", "#.#"},
+	'N':  {"##.", "#.#", "#.#", "#.#", "#.#"},
+	'O':  {"###", "#.#", "#.#", "#.#", "###"},
+	'P':  {"###", "#.#", "###", "#..", "#.."},
+	'Q':  {"###", "#.#", "#.#", "###", "..#"},
+	'R':  {"###", "#.#", "##.", "#.#", "#.#"},
+	'S':  {"###", "#..", "###", "..#", "###"},
+	'T':  {"###", ".#.", ".#.", ".#.", ".#."},
+	'U':  {"#.#", "#.#", "#.#", "#.#", "###"},
+	'V':  {"#.#", "#.#", "#.#", "#.#", ".#."},
+	'W':  {"#.#", "#.#", "###", "###", "#.#"},
+	'X':  {"#.#", "#.#", ".#.", "#.#", "#.#"},
+	'Y':  {"#.#", "#.#", ".#.", ".#.", ".#."},
+	'Z':  {"###", "..#", ".#.", "#..", "###"},
+	'0':  {"###", "#.#", "#.#", "#.#", "###"},
+	'1':  {".#.", "##.", ".#.", ".#.", "###"},
+	'2':  {"###", "..#", "###", "#..", "###"},
+	'3':  {"###", "..#", "###", "..#", "###"},
+	'4':  {"#.#", "#.#", "###", "..#", "..#"},
+	'5':  {"###", "#..", "###", "..#", "###"},
+	'6':  {"###", "#..", "###", "#.#", "###"},
+	'7':  {"###", "..#", "..#", "..#", "..#"},
+	'8':  {"###", "#.#", "###", "#.#", "###"},
+	'9':  {"###", "#.#", "###", "..#", "###"},
+	'+':  {"...", ".#.", "###", ".#.", "..."},
+	'-':  {"...", "...", "###", "...", "..."},
+	'.':  {"...", "...", "...", "...", ".#."},
+	'/':  {"..#", "..#", ".#.", "#..", "#.."},
+	' ':  {"...", "...", "...", "...", "..."},
+	'(':  {".#.", "#..", "#..", "#..", ".#."},
+	')':  {".#.", "..#", "..#", "..#", ".#."},
+	'[':  {"##.", "#..", "#..", "#..", "##."},
+	']':  {".##", "..#", "..#", "..#", ".##"},
+	'<':  {"..#", ".#.", "#..", ".#.", "..#"},
+	'>':  {"#..", ".#.", "..#", ".#.", "#.."},
+	',':  {"...", "...", "...", ".#.", "#.."},
+	':':  {"...", ".#.", "...", ".#.", "..."},
+	'*':  {"#.#", ".#.", "#.#", "...", "..."},
+	'"':  {"#.#", "#.#", "...", "...", "..."},
+	'\'': {".#.", ".#.", "...", "...", "..."},
+	'_':  {"...", "...", "...", "...", "###"},
+	'=':  {"...", "###", "...", "###", "..."},
+	'&':  {".#.", "#.#", ".#.", "#.#", ".##"},
+	'!':  {".#.", ".#.", ".#.", "...", ".#."},
+	'?':  {"###", "..#", ".##", "...", ".#."},
+	'#':  {"#.#", "###", "#.#", "###", "#.#"},
+	'%':  {"#.#", "..#", ".#.", "#..", "#.#"},
+}
+
+// textWidth gives the pixels a text takes at the given scale
+func textWidth(text string, scale int) int {
+	if text == "" {
+		return 0
+	}
+	return (len([]rune(text))*glyphAdvance - 1) * scale
+}
+
+// drawText writes text in upper case with its top left corner at x, y. characters the font lacks are left blank
+func drawText(img *image.Paletted, x int, y int, text string, scale int, colorIndex uint8) {
+	for _, r := range strings.ToUpper(text) {
+		glyph, ok := glyphs[r]
+		if ok {
+			for row, line := range glyph {
+				for column, pixel := range line {
+					if pixel == '#' {
+						fillRect(img, x+column*scale, y+row*scale, scale, scale, colorIndex)
+					}
+				}
+			}
+		}
+
+		x += glyphAdvance * scale
+	}
+}
+
+func fillRect(img *image.Paletted, x int, y int, width int, height int, colorIndex uint8) {
+	for py := y; py < y+height; py++ {
+		for px := x; px < x+width; px++ {
+			img.SetColorIndex(px, py, colorIndex)
+		}
+	}
+}
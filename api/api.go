@@ -0,0 +1,415 @@
+package api
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+const RequestTimeout = 10 * time.Second
+
+type CompilerSupport struct {
+	Compiler    string `json:"compiler"`
+	Kind        string `json:"kind"`
+	Name        string `json:"name"`
+	Support     string `json:"support"`
+	DisplayText string `json:"display_text"`
+	ExtraText   string `json:"extra_text"`
+}
+
+type Feature struct {
+	Slug       string            `json:"slug"`
+	Language   string            `json:"language"`
+	Name       string            `json:"name"`
+	Timestamp  time.Time         `json:"timestamp"`
+	CppVersion int               `json:"cpp_version"`
+	PaperName  string            `json:"paper_name"`
+	PaperLink  string            `json:"paper_link"`
+	Compilers  []CompilerSupport `json:"compilers"`
+	Removed    bool              `json:"removed"`
+}
+
+// CompilerDelta is the support of one compiler before and after a change. previous is null for new listings
+type CompilerDelta struct {
+	Compiler string           `json:"compiler"`
+	Previous *CompilerSupport `json:"previous"`
+	Next     CompilerSupport  `json:"next"`
+}
+
+type Change struct {
+	Kind     string          `json:"kind"`
+	Previous *Feature        `json:"previous"`
+	Next     *Feature        `json:"next"`
+	Deltas   []CompilerDelta `json:"deltas"`
+}
+
+// SupportCount is how many of the current features of a language version a compiler lists with a support level
+type SupportCount struct {
+	Language string `json:"language"`
+	Version  int    `json:"version"`
+	Standard string `json:"standard"`
+	Compiler string `json:"compiler"`
+	Support  string `json:"support"`
+	Features int    `json:"features"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+type Server struct {
+	service compliance.Service
+	mux     *http.ServeMux
+	graphql *graphql.Schema
+}
+
+func NewServer(service compliance.Service) *Server {
+	s := &Server{
+		service: service,
+		mux:     http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("/features", s.handleFeatures)
+	s.mux.HandleFunc("/features/", s.handleFeatureHistory)
+	s.mux.HandleFunc("/changes", s.handleChanges)
+	s.mux.HandleFunc("/stats", s.handleStats)
+	s.mux.HandleFunc("/badge/", s.handleBadge)
+
+	s.graphql = newGraphQLSchema(service)
+	s.mux.HandleFunc("/graphql", s.handleGraphQL)
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	//GraphQL clients post their queries
+	if r.Method != http.MethodGet && !(r.Method == http.MethodPost && r.URL.Path == "/graphql") {
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{"only GET is supported"})
+		return
+	}
+
+	s.mux.ServeHTTP(w, r)
+}
+
+func fromNullString(text sql.NullString) string {
+	if text.Valid {
+		return text.String
+	}
+	return ""
+}
+
+// FromFeature converts a stored feature entry into its exported form
+func FromFeature(feature *compliance.Feature) *Feature {
+	if feature == nil {
+		return nil
+	}
+
+	result := &Feature{
+		Slug:       feature.Slug,
+		Language:   feature.Language,
+		Name:       feature.Name,
+		Timestamp:  feature.Timestamp,
+		CppVersion: feature.CppVersion,
+		PaperName:  fromNullString(feature.PaperName),
+		PaperLink:  fromNullString(feature.PaperLink),
+		Compilers:  []CompilerSupport{},
+		Removed:    feature.Removed,
+	}
+
+	for _, support := range feature.Compilers {
+		result.Compilers = append(result.Compilers, fromCompilerSupport(support))
+	}
+
+	return result
+}
+
+func fromCompilerSupport(support compliance.CompilerSupport) CompilerSupport {
+	return CompilerSupport{
+		Compiler:    support.Compiler,
+		Kind:        support.Kind,
+		Name:        compliance.CompilerDisplayName(support.Compiler),
+		Support:     compliance.SupportLevelName(support.Support),
+		DisplayText: fromNullString(support.DisplayText),
+		ExtraText:   fromNullString(support.ExtraText),
+	}
+}
+
+// FromChange converts a diff of two feature entries into its exported form
+func FromChange(change compliance.Change) Change {
+	result := Change{
+		Kind:     change.Kind,
+		Previous: FromFeature(change.Previous),
+		Next:     FromFeature(change.Feature),
+		Deltas:   []CompilerDelta{},
+	}
+
+	for _, delta := range change.Deltas {
+		exported := CompilerDelta{
+			Compiler: delta.Compiler,
+			Next:     fromCompilerSupport(delta.Next),
+		}
+		if change.Previous != nil {
+			previous := fromCompilerSupport(delta.Previous)
+			exported.Previous = &previous
+		}
+
+		result.Deltas = append(result.Deltas, exported)
+	}
+
+	return result
+}
+
+// languageParam gives the language the request is filtered by, empty for all of them. ok is false if an error was written
+func languageParam(w http.ResponseWriter, r *http.Request) (language string, ok bool) {
+	language = r.URL.Query().Get("language")
+	if language != "" && !compliance.IsLanguage(language) {
+		writeJSON(w, http.StatusBadRequest, errorResponse{"language must be one of " + strings.Join(compliance.Languages, ", ")})
+		return "", false
+	}
+
+	return language, true
+}
+
+// ofLanguage leaves out the features of other languages, unless language is empty
+func ofLanguage(features []compliance.Feature, language string) []compliance.Feature {
+	if language == "" {
+		return features
+	}
+
+	return compliance.FeaturesOfLanguage(features, language)
+}
+
+func fromFeatures(features []compliance.Feature) []*Feature {
+	result := []*Feature{}
+
+	for i := range features {
+		result = append(result, FromFeature(&features[i]))
+	}
+
+	return result
+}
+
+// handleFeatures lists the latest entry of every feature, or the entries as they were at the RFC 3339 time given as "at".
+// "language" limits them to the features of a language
+func (s *Server) handleFeatures(w http.ResponseWriter, r *http.Request) {
+	language, ok := languageParam(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), RequestTimeout)
+	defer cancel()
+
+	var features []compliance.Feature
+	var err error
+
+	if atParam := r.URL.Query().Get("at"); atParam != "" {
+		at, parseErr := time.Parse(time.RFC3339, atParam)
+		if parseErr != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{"at must be an RFC 3339 time"})
+			return
+		}
+
+		features, err = s.service.GetEntriesAt(ctx, at)
+	} else {
+		features, err = s.service.GetLatestEntries(ctx)
+	}
+
+	if err != nil {
+		slog.Error("api: error getting latest entries", "err", err)
+		writeJSON(w, http.StatusInternalServerError, errorResponse{"could not get features"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, fromFeatures(ofLanguage(features, language)))
+}
+
+// handleFeatureHistory lists all entries of the feature given as /features/{slug}/history, which includes entries from
+// before it was renamed. /features/{name}/history works as well for features that don't have a slug yet, looked up among
+// the features of the language given as "language", C++ if there is none
+func (s *Server) handleFeatureHistory(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.EscapedPath(), "/features/")
+
+	if !strings.HasSuffix(path, "/history") {
+		writeJSON(w, http.StatusNotFound, errorResponse{"not found"})
+		return
+	}
+
+	name, err := url.PathUnescape(strings.TrimSuffix(path, "/history"))
+	if err != nil || name == "" {
+		writeJSON(w, http.StatusBadRequest, errorResponse{"invalid feature name"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), RequestTimeout)
+	defer cancel()
+
+	history, err := s.service.GetFeatureHistoryBySlug(ctx, name)
+	if err == nil && len(history) == 0 {
+		language, ok := languageParam(w, r)
+		if !ok {
+			return
+		}
+		if language == "" {
+			language = compliance.LanguageCpp
+		}
+		history, err = s.service.GetFeatureHistory(ctx, language, name)
+	}
+	if err != nil {
+		slog.Error("api: error getting history", "feature", name, "err", err)
+		writeJSON(w, http.StatusInternalServerError, errorResponse{"could not get feature history"})
+		return
+	}
+
+	if len(history) == 0 {
+		writeJSON(w, http.StatusNotFound, errorResponse{"no such feature"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, fromFeatures(history))
+}
+
+// limits of the changes of a page, which is read with a query for the entries, one for the entries they replaced and
+// one for the support of both
+const (
+	DefaultChangesLimit = 100
+	MaxChangesLimit     = 250
+)
+
+// ChangesPage is a page of changes, with the cursor to get the next one with. the cursor is empty on the last page
+type ChangesPage struct {
+	Changes []Change `json:"changes"`
+	Next    string   `json:"next"`
+}
+
+// handleChanges lists the entries created after the RFC 3339 time given as "since", together with the entry they
+// replaced, in pages of "limit" changes. the next page is read by passing the "next" of a page as "cursor". "language"
+// limits them to the entries of a language
+func (s *Server) handleChanges(w http.ResponseWriter, r *http.Request) {
+	language, ok := languageParam(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+
+	if query.Get("since") == "" {
+		writeJSON(w, http.StatusBadRequest, errorResponse{"since is required, as an RFC 3339 time"})
+		return
+	}
+	since, err := time.Parse(time.RFC3339, query.Get("since"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{"since must be an RFC 3339 time"})
+		return
+	}
+
+	limit := DefaultChangesLimit
+	if limitParam := query.Get("limit"); limitParam != "" {
+		if limit, err = strconv.Atoi(limitParam); err != nil || limit < 1 || limit > MaxChangesLimit {
+			writeJSON(w, http.StatusBadRequest, errorResponse{fmt.Sprintf("limit must be between 1 and %v", MaxChangesLimit)})
+			return
+		}
+	}
+
+	var after int64
+	if cursor := query.Get("cursor"); cursor != "" {
+		if after, err = strconv.ParseInt(cursor, 10, 64); err != nil || after < 0 {
+			writeJSON(w, http.StatusBadRequest, errorResponse{"invalid cursor"})
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), RequestTimeout)
+	defer cancel()
+
+	entries, err := s.service.GetEntryChanges(ctx, language, since, after, limit)
+	if err != nil {
+		slog.Error("api: error getting changes", "since", since, "err", err)
+		writeJSON(w, http.StatusInternalServerError, errorResponse{"could not get changes"})
+		return
+	}
+
+	page := ChangesPage{Changes: changesOf(entries)}
+	if len(entries) == limit {
+		page.Next = strconv.FormatInt(entries[len(entries)-1].Entry.Id, 10)
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
+
+// changesOf diffs every entry with the one it replaced
+func changesOf(entries []compliance.EntryChange) []Change {
+	changes := []Change{}
+
+	for i := range entries {
+		//entries that differ in nothing reportable are still listed, without a kind
+		change, err := compliance.DiffFeatures(entries[i].Previous, &entries[i].Entry)
+		if err != nil {
+			change = compliance.Change{Previous: entries[i].Previous, Feature: &entries[i].Entry}
+		}
+
+		changes = append(changes, FromChange(change))
+	}
+
+	return changes
+}
+
+// handleStats counts the current support levels of every compiler per language and version. "language" limits the
+// counts to a language
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	language, ok := languageParam(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), RequestTimeout)
+	defer cancel()
+
+	counts, err := s.service.GetSupportCounts(ctx)
+	if err != nil {
+		slog.Error("api: error counting support", "err", err)
+		writeJSON(w, http.StatusInternalServerError, errorResponse{"could not count support"})
+		return
+	}
+
+	result := []SupportCount{}
+	for _, count := range counts {
+		if language != "" && count.Language != language {
+			continue
+		}
+
+		result = append(result, fromSupportCount(count))
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+func fromSupportCount(count compliance.SupportCount) SupportCount {
+	return SupportCount{
+		Language: count.Language,
+		Version:  count.CppVersion,
+		Standard: compliance.StandardName(count.Language, count.CppVersion),
+		Compiler: count.Compiler,
+		Support:  compliance.SupportLevelName(count.Support),
+		Features: count.Features,
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		slog.Warn("api: error writing response", "err", err)
+	}
+}
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func getJSON(t *testing.T, server *Server, target string, value interface{}) int {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+
+	if value != nil && recorder.Code == http.StatusOK {
+		if err := json.Unmarshal(recorder.Body.Bytes(), value); err != nil {
+			t.Fatalf("%v: %v", target, err)
+		}
+	}
+
+	return recorder.Code
+}
+
+func TestHandleChanges(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	service := compliance.NewMemoryService()
+	service.Now = func() time.Time {
+		now = now.Add(time.Minute)
+		return now
+	}
+
+	for _, support := range []int{0, 1, 2} {
+		feature := &compliance.Feature{Name: "Modules", CppVersion: 20, Compilers: []compliance.CompilerSupport{{
+			Compiler: compliance.CompilerGcc, Kind: compliance.KindCompiler, Support: support,
+			DisplayText: sql.NullString{String: "11", Valid: true},
+		}}}
+		if err := service.CreateEntry(context.Background(), feature); err != nil {
+			t.Fatal(err)
+		}
+	}
+	server := NewServer(service)
+
+	for _, target := range []string{"/changes", "/changes?since=yesterday", "/changes?since=2024-03-01T12:00:00Z&limit=0",
+		"/changes?since=2024-03-01T12:00:00Z&limit=1000", "/changes?since=2024-03-01T12:00:00Z&cursor=x"} {
+		if code := getJSON(t, server, target, nil); code != http.StatusBadRequest {
+			t.Errorf("%v: got status %v, want %v", target, code, http.StatusBadRequest)
+		}
+	}
+
+	var page ChangesPage
+	if code := getJSON(t, server, "/changes?since=2024-03-01T12:00:00Z&limit=2", &page); code != http.StatusOK {
+		t.Fatalf("first page: got status %v", code)
+	}
+	if len(page.Changes) != 2 || page.Next == "" || page.Changes[0].Previous != nil || page.Changes[1].Kind != compliance.ChangeSupport {
+		t.Fatalf("first page: got %+v", page)
+	}
+
+	var next ChangesPage
+	if code := getJSON(t, server, "/changes?since=2024-03-01T12:00:00Z&limit=2&cursor="+page.Next, &next); code != http.StatusOK {
+		t.Fatalf("second page: got status %v", code)
+	}
+	if len(next.Changes) != 1 || next.Next != "" || next.Changes[0].Previous == nil || next.Changes[0].Deltas[0].Next.Support != "partial" {
+		t.Errorf("second page: got %+v", next)
+	}
+}
+
+func compareSupport(compiler string, support int, displayText string, extraText string) compliance.CompilerSupport {
+	result := graphQLSupport(compiler, support, displayText)
+	result.ExtraText = sql.NullString{String: extraText, Valid: extraText != ""}
+	return result
+}
+
+func TestFromChange(t *testing.T) {
+	previous := &compliance.Feature{Name: "Modules", CppVersion: 20, PaperName: sql.NullString{String: "P1103R3", Valid: true}, Compilers: []compliance.CompilerSupport{
+		compareSupport(compliance.CompilerGcc, 0, "", ""),
+		compareSupport(compliance.CompilerClang, 1, "16", "partial"),
+	}}
+	next := &compliance.Feature{Name: "Modules", CppVersion: 20, PaperName: previous.PaperName, Compilers: []compliance.CompilerSupport{
+		compareSupport(compliance.CompilerGcc, 1, "11", ""),
+		compareSupport(compliance.CompilerClang, 1, "16", "partial"),
+	}}
+
+	change, err := compliance.DiffFeatures(previous, next)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := json.Marshal(FromChange(change))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"kind":"support",` +
+		`"previous":{"slug":"","language":"","name":"Modules","timestamp":"0001-01-01T00:00:00Z","cpp_version":20,"paper_name":"P1103R3","paper_link":"","compilers":[` +
+		`{"compiler":"gcc","kind":"compiler","name":"GCC","support":"no","display_text":"","extra_text":""},` +
+		`{"compiler":"clang","kind":"compiler","name":"Clang","support":"yes","display_text":"16","extra_text":"partial"}],"removed":false},` +
+		`"next":{"slug":"","language":"","name":"Modules","timestamp":"0001-01-01T00:00:00Z","cpp_version":20,"paper_name":"P1103R3","paper_link":"","compilers":[` +
+		`{"compiler":"gcc","kind":"compiler","name":"GCC","support":"yes","display_text":"11","extra_text":""},` +
+		`{"compiler":"clang","kind":"compiler","name":"Clang","support":"yes","display_text":"16","extra_text":"partial"}],"removed":false},` +
+		`"deltas":[{"compiler":"gcc",` +
+		`"previous":{"compiler":"gcc","kind":"compiler","name":"GCC","support":"no","display_text":"","extra_text":""},` +
+		`"next":{"compiler":"gcc","kind":"compiler","name":"GCC","support":"yes","display_text":"11","extra_text":""}}]}`
+	if string(encoded) != want {
+		t.Errorf("got\n%s\nwant\n%v", encoded, want)
+	}
+
+	listing, err := compliance.DiffFeatures(nil, next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exported := FromChange(listing); exported.Previous != nil || len(exported.Deltas) != 3 || exported.Deltas[0].Previous != nil {
+		t.Errorf("new listing got %+v", exported)
+	}
+}
@@ -0,0 +1,289 @@
+package api
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"fmt"
+	"html"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// colors of the badges, as shields.io has them
+const (
+	colorBrightGreen = "#4c1"
+	colorGreen       = "#97ca00"
+	colorYellow      = "#dfb317"
+	colorOrange      = "#fe7d37"
+	colorRed         = "#e05d44"
+	colorGrey        = "#9f9f9f"
+)
+
+// badgeMaxAge is how long badges may be cached, READMEs are viewed a lot more often than the page is scraped
+const badgeMaxAge = 3600
+
+// widths in pixels of the characters of 11px Verdana that differ much from the average of 7
+var charWidths = map[rune]float64{
+	' ': 3.9, '!': 4.7, '(': 5.5, ')': 5.5, '+': 9.2, ',': 4.4, '-': 5.3, '.': 4.4, '/': 5.3, ':': 5.3, ';': 5.3,
+	'I': 4.6, 'J': 5.5, 'M': 8.7, 'W': 10.7, 'f': 4.4, 'i': 3.1, 'j': 3.9, 'l': 3.1, 'm': 10.7, 'r': 4.9, 't': 4.4,
+	'w': 9.0, '%': 12.3, '·': 4.4,
+}
+
+func textWidth(text string) float64 {
+	width := 0.0
+	for _, c := range text {
+		if w, ok := charWidths[c]; ok {
+			width += w
+		} else {
+			width += 7
+		}
+	}
+
+	return width
+}
+
+// renderBadge draws a badge in the flat style of shields.io, a grey label on the left and a colored message on the right
+func renderBadge(label string, message string, color string) string {
+	labelWidth := int(textWidth(label)) + 10
+	messageWidth := int(textWidth(message)) + 10
+	width := labelWidth + messageWidth
+
+	title := html.EscapeString(label + ": " + message)
+	label, message = html.EscapeString(label), html.EscapeString(message)
+
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%[1]v" height="20" role="img" aria-label="%[2]v">`+
+		`<title>%[2]v</title>`+
+		`<linearGradient id="s" x2="0" y2="100%%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>`+
+		`<clipPath id="r"><rect width="%[1]v" height="20" rx="3" fill="#fff"/></clipPath>`+
+		`<g clip-path="url(#r)"><rect width="%[3]v" height="20" fill="#555"/><rect x="%[3]v" width="%[4]v" height="20" fill="%[5]v"/><rect width="%[1]v" height="20" fill="url(#s)"/></g>`+
+		`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`+
+		`<text x="%[6]v" y="15" fill="#010101" fill-opacity=".3">%[8]v</text><text x="%[6]v" y="14">%[8]v</text>`+
+		`<text x="%[7]v" y="15" fill="#010101" fill-opacity=".3">%[9]v</text><text x="%[7]v" y="14">%[9]v</text>`+
+		`</g></svg>`,
+		width, title, labelWidth, messageWidth, color, float64(labelWidth)/2, float64(labelWidth)+float64(messageWidth)/2, label, message)
+}
+
+// writeBadge writes the badge, with the label given as "label" instead if there is one
+func writeBadge(w http.ResponseWriter, r *http.Request, status int, label string, message string, color string) {
+	if custom := r.URL.Query().Get("label"); custom != "" {
+		label = custom
+	}
+
+	w.Header().Set("Content-Type", "image/svg+xml; charset=utf-8")
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%v", badgeMaxAge))
+	w.WriteHeader(status)
+	w.Write([]byte(renderBadge(label, message, color)))
+}
+
+// completionColor grades how complete the support of a standard is
+func completionColor(percent int) string {
+	switch {
+	case percent >= 90:
+		return colorBrightGreen
+	case percent >= 75:
+		return colorGreen
+	case percent >= 50:
+		return colorYellow
+	case percent >= 25:
+		return colorOrange
+	}
+
+	return colorRed
+}
+
+func supportColor(support int) string {
+	switch support {
+	case compliance.SupportYes:
+		return colorBrightGreen
+	case compliance.SupportPartial:
+		return colorYellow
+	}
+
+	return colorRed
+}
+
+var badgeStandardPattern = regexp.MustCompile(`^(?i)(c\+\+|cpp|c)(\d\d)$`)
+
+// parseBadgeStandard reads a standard as written in badge paths, like c++20, cpp20 or c23
+func parseBadgeStandard(text string) (language string, version int, ok bool) {
+	match := badgeStandardPattern.FindStringSubmatch(text)
+	if match == nil {
+		return "", 0, false
+	}
+
+	language = compliance.LanguageCpp
+	if strings.ToLower(match[1]) == "c" {
+		language = compliance.LanguageC
+	}
+
+	version, _ = strconv.Atoi(match[2])
+	return language, version, true
+}
+
+// handleBadge serves SVG badges to embed in READMEs. /badge/{standard}/{compiler} like /badge/c++20/gcc gives how much
+// of the current features of the standard the compiler fully supports, /badge/feature/{feature} the support of a feature
+// by slug or name, looked up among the features of the language given as "language", C++ if there is none. "compiler"
+// limits a feature badge to one compiler, and "label" replaces the label
+func (s *Server) handleBadge(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/badge/")
+
+	if strings.HasPrefix(path, "feature/") {
+		s.handleFeatureBadge(w, r, strings.TrimPrefix(path, "feature/"))
+		return
+	}
+
+	parts := strings.Split(path, "/")
+	if len(parts) != 2 || parts[1] == "" {
+		writeBadge(w, r, http.StatusNotFound, "badge", "not found", colorGrey)
+		return
+	}
+
+	language, version, ok := parseBadgeStandard(parts[0])
+	if !ok {
+		writeBadge(w, r, http.StatusBadRequest, parts[0], "unknown standard", colorGrey)
+		return
+	}
+
+	standard := compliance.StandardName(language, version)
+	compiler := strings.ToLower(parts[1])
+	label := fmt.Sprintf("%v in %v", standard, compliance.CompilerDisplayName(compiler))
+
+	ctx, cancel := context.WithTimeout(r.Context(), RequestTimeout)
+	defer cancel()
+
+	features, err := s.service.GetLatestEntries(ctx)
+	if err != nil {
+		slog.Error("api: error getting latest entries", "err", err)
+		writeBadge(w, r, http.StatusInternalServerError, label, "unavailable", colorGrey)
+		return
+	}
+
+	total, supported := 0, 0
+	for i := range features {
+		if features[i].Language != language || features[i].CppVersion != version || features[i].Removed {
+			continue
+		}
+
+		total++
+		if supports(&features[i], compiler) {
+			supported++
+		}
+	}
+
+	if total == 0 {
+		writeBadge(w, r, http.StatusNotFound, label, "no features", colorGrey)
+		return
+	}
+
+	percent := supported * 100 / total
+	writeBadge(w, r, http.StatusOK, label, fmt.Sprintf("%v%% complete", percent), completionColor(percent))
+}
+
+// badgeSupportText is the support of a compiler as short as it fits on a badge, the version it came in if known
+func badgeSupportText(support *compliance.CompilerSupport) string {
+	switch {
+	case support.Support == compliance.SupportPartial:
+		return "partial"
+	case support.Support != compliance.SupportYes:
+		return "no"
+	case support.DisplayText.Valid && support.DisplayText.String != "":
+		return support.DisplayText.String
+	}
+
+	return "yes"
+}
+
+// badgeFeature finds the feature by slug, or by name among those of the language
+func badgeFeature(features []compliance.Feature, language string, name string) *compliance.Feature {
+	for i := range features {
+		if features[i].Slug == name {
+			return &features[i]
+		}
+	}
+
+	for i := range features {
+		if features[i].Language == language && features[i].Name == name {
+			return &features[i]
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) handleFeatureBadge(w http.ResponseWriter, r *http.Request, name string) {
+	language := r.URL.Query().Get("language")
+	if language == "" {
+		language = compliance.LanguageCpp
+	}
+	if !compliance.IsLanguage(language) || name == "" {
+		writeBadge(w, r, http.StatusBadRequest, "feature", "invalid request", colorGrey)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), RequestTimeout)
+	defer cancel()
+
+	features, err := s.service.GetLatestEntries(ctx)
+	if err != nil {
+		slog.Error("api: error getting latest entries", "err", err)
+		writeBadge(w, r, http.StatusInternalServerError, name, "unavailable", colorGrey)
+		return
+	}
+
+	feature := badgeFeature(features, language, name)
+
+	if feature == nil {
+		writeBadge(w, r, http.StatusNotFound, name, "no such feature", colorGrey)
+		return
+	}
+
+	label := feature.Name
+	if feature.Removed {
+		writeBadge(w, r, http.StatusOK, label, "removed", colorGrey)
+		return
+	}
+
+	if compiler := strings.ToLower(r.URL.Query().Get("compiler")); compiler != "" {
+		support := feature.SupportFor(compiler)
+		if support == nil {
+			writeBadge(w, r, http.StatusOK, label, compliance.CompilerDisplayName(compiler)+" unknown", colorGrey)
+			return
+		}
+
+		writeBadge(w, r, http.StatusOK, label, compliance.CompilerDisplayName(compiler)+" "+badgeSupportText(support), supportColor(support.Support))
+		return
+	}
+
+	var cells []string
+	listed, supported, partial := 0, 0, 0
+	for _, compiler := range append(append([]string{}, compliance.PrimaryCompilers...), compliance.PrimaryLibraries...) {
+		support := feature.SupportFor(compiler)
+		if support == nil {
+			continue
+		}
+
+		cells = append(cells, compliance.CompilerDisplayName(compiler)+" "+badgeSupportText(support))
+		listed++
+		switch support.Support {
+		case compliance.SupportYes:
+			supported++
+		case compliance.SupportPartial:
+			partial++
+		}
+	}
+
+	color := colorRed
+	switch {
+	case listed == 0:
+		cells, color = []string{"no support listed"}, colorGrey
+	case supported == listed:
+		color = colorBrightGreen
+	case supported > 0 || partial > 0:
+		color = colorYellow
+	}
+
+	writeBadge(w, r, http.StatusOK, label, strings.Join(cells, " · "), color)
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+)
+
+var badgeTitlePattern = regexp.MustCompile(`<title>(.*)</title>`)
+
+func TestBadges(t *testing.T) {
+	server := newGraphQLTestServer(t)
+
+	modules, err := server.service.GetFeatureHistory(context.Background(), compliance.LanguageCpp, "Modules")
+	if err != nil || len(modules) == 0 {
+		t.Fatalf("no history of Modules: %v", err)
+	}
+
+	for _, test := range []struct {
+		path   string
+		status int
+		title  string
+	}{
+		{"/badge/c++20/clang", http.StatusOK, "C++20 in Clang: 66% complete"},
+		{"/badge/cpp20/msvc?label=MSVC", http.StatusOK, "MSVC: 33% complete"},
+		{"/badge/C%2B%2B23/msvc", http.StatusOK, "C++23 in MSVC: 100% complete"},
+		{"/badge/c++17/gcc", http.StatusNotFound, "C++17 in GCC: no features"},
+		{"/badge/c++2a/gcc", http.StatusBadRequest, "c++2a: unknown standard"},
+		{"/badge/c++20", http.StatusNotFound, "badge: not found"},
+		{"/badge/feature/Modules", http.StatusOK, "Modules: Clang 16 · MSVC partial"},
+		{"/badge/feature/" + modules[0].Slug + "?compiler=msvc", http.StatusOK, "Modules: MSVC partial"},
+		{"/badge/feature/constexpr%20new", http.StatusOK, "constexpr new: Clang no"},
+		{"/badge/feature/Deducing%20this?compiler=gcc", http.StatusOK, "Deducing this: GCC unknown"},
+		{"/badge/feature/Nothing", http.StatusNotFound, "Nothing: no such feature"},
+		{"/badge/feature/Modules?language=rust", http.StatusBadRequest, "feature: invalid request"},
+	} {
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, test.path, nil))
+
+		if recorder.Code != test.status {
+			t.Errorf("%v: got status %v, want %v", test.path, recorder.Code, test.status)
+		}
+		if contentType := recorder.Header().Get("Content-Type"); contentType != "image/svg+xml; charset=utf-8" {
+			t.Errorf("%v: got content type %v", test.path, contentType)
+		}
+
+		title := badgeTitlePattern.FindStringSubmatch(recorder.Body.String())
+		if title == nil {
+			t.Errorf("%v: badge has no title: %v", test.path, recorder.Body.String())
+		} else if html.UnescapeString(title[1]) != test.title {
+			t.Errorf("%v: got title %q, want %q", test.path, html.UnescapeString(title[1]), test.title)
+		}
+	}
+}
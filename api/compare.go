@@ -0,0 +1,163 @@
+package api
+
+import (
+	"cppimpbot/compliance"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Divergence is a way a feature differs between two instances of the bot
+type Divergence struct {
+	Feature string //standard and name of the feature, like C++20 "Concepts"
+	What    string
+}
+
+// FetchFeatures gets the latest entry of every feature from the API of another instance at baseURL, like
+// https://example.com/api. language limits them to the features of a language, unless it is empty
+func FetchFeatures(client *http.Client, baseURL string, language string) ([]*Feature, error) {
+	address := strings.TrimRight(baseURL, "/") + "/features"
+	if language != "" {
+		address += "?language=" + url.QueryEscape(language)
+	}
+
+	response, err := client.Get(address)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the features of %v: %v", baseURL, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		failure := errorResponse{}
+		json.NewDecoder(response.Body).Decode(&failure)
+		return nil, fmt.Errorf("%v answered %v: %v", address, response.Status, failure.Error)
+	}
+
+	var features []*Feature
+	if err := json.NewDecoder(response.Body).Decode(&features); err != nil {
+		return nil, fmt.Errorf("%v answered with something other than features: %v", address, err)
+	}
+
+	return features, nil
+}
+
+// featureKey identifies a feature across instances, which may have given it different slugs
+func featureKey(feature *Feature) string {
+	language := feature.Language
+	if language == "" {
+		language = compliance.LanguageCpp
+	}
+
+	return fmt.Sprintf("%v %03d %v", language, feature.CppVersion, feature.Name)
+}
+
+func featureTitle(feature *Feature) string {
+	return fmt.Sprintf("%v \"%v\"", compliance.StandardName(feature.Language, feature.CppVersion), feature.Name)
+}
+
+func supportTitle(support CompilerSupport) string {
+	title := support.Support
+	if support.DisplayText != "" {
+		title += " " + support.DisplayText
+	}
+	if support.ExtraText != "" {
+		title += " (" + support.ExtraText + ")"
+	}
+
+	return title
+}
+
+// CompareFeatures lists how the features of another instance differ from the local ones, by standard and name of the
+// feature. entries are compared by what they list, not by when they were scraped
+func CompareFeatures(local []*Feature, remote []*Feature) (result []Divergence) {
+	byKey := func(features []*Feature) map[string]*Feature {
+		result := map[string]*Feature{}
+		for _, feature := range features {
+			result[featureKey(feature)] = feature
+		}
+		return result
+	}
+	localByKey, remoteByKey := byKey(local), byKey(remote)
+
+	var keys []string
+	for key := range localByKey {
+		keys = append(keys, key)
+	}
+	for key := range remoteByKey {
+		if _, ok := localByKey[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		localFeature, remoteFeature := localByKey[key], remoteByKey[key]
+
+		switch {
+		case remoteFeature == nil:
+			result = append(result, Divergence{featureTitle(localFeature), "listed only locally"})
+		case localFeature == nil:
+			result = append(result, Divergence{featureTitle(remoteFeature), "listed only remotely"})
+		default:
+			for _, what := range compareFeature(localFeature, remoteFeature) {
+				result = append(result, Divergence{featureTitle(localFeature), what})
+			}
+		}
+	}
+
+	return
+}
+
+// compareFeature lists how the two entries of a feature differ
+func compareFeature(local *Feature, remote *Feature) (result []string) {
+	differs := func(field string, localValue string, remoteValue string) {
+		if localValue != remoteValue {
+			result = append(result, fmt.Sprintf("%v is '%v' locally, '%v' remotely", field, localValue, remoteValue))
+		}
+	}
+
+	differs("slug", local.Slug, remote.Slug)
+	differs("removed", fmt.Sprint(local.Removed), fmt.Sprint(remote.Removed))
+	differs("paper", local.PaperName, remote.PaperName)
+	differs("paper link", local.PaperLink, remote.PaperLink)
+
+	supports := func(feature *Feature) map[string]CompilerSupport {
+		result := map[string]CompilerSupport{}
+		for _, support := range feature.Compilers {
+			result[support.Compiler] = support
+		}
+		return result
+	}
+	localSupport, remoteSupport := supports(local), supports(remote)
+
+	var compilers []string
+	for compiler := range localSupport {
+		compilers = append(compilers, compiler)
+	}
+	for compiler := range remoteSupport {
+		if _, ok := localSupport[compiler]; !ok {
+			compilers = append(compilers, compiler)
+		}
+	}
+	sort.Strings(compilers)
+
+	for _, compiler := range compilers {
+		name := compliance.CompilerDisplayName(compiler)
+		localCell, isLocal := localSupport[compiler]
+		remoteCell, isRemote := remoteSupport[compiler]
+
+		switch {
+		case !isRemote:
+			result = append(result, fmt.Sprintf("%v support listed only locally", name))
+		case !isLocal:
+			result = append(result, fmt.Sprintf("%v support listed only remotely", name))
+		default:
+			differs(name+" support", supportTitle(localCell), supportTitle(remoteCell))
+		}
+	}
+
+	return
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"cppimpbot/compliance"
+	"reflect"
+	"testing"
+)
+
+func TestCompareFeatures(t *testing.T) {
+	name := "Initializer list constructors in class template argument deduction"
+	local := compliance.Feature{Name: name, CppVersion: 20, Compilers: []compliance.CompilerSupport{
+		compareSupport(compliance.CompilerGcc, 1, "9*", "still some bugs"),
+		compareSupport(compliance.CompilerClang, 1, "6 (partial)*", "only supported if flag supplied"),
+		compareSupport(compliance.CompilerMsvc, 1, "19.20", ""),
+		compareSupport("apple_clang", 1, "10.0.1", ""),
+	}}
+	remote := compliance.Feature{Name: name, CppVersion: 20, Compilers: []compliance.CompilerSupport{
+		compareSupport(compliance.CompilerGcc, 0, "", ""),
+		compareSupport(compliance.CompilerClang, 1, "6", ""),
+		compareSupport(compliance.CompilerMsvc, 2, "19.20", "one bug"),
+		compareSupport("apple_clang", 0, "", ""),
+	}}
+	library := compliance.Feature{Name: "std::any", CppVersion: 17}
+	renamed := compliance.Feature{Name: "Initializer list constructors in CTAD", CppVersion: 20}
+
+	divergences := CompareFeatures([]*Feature{FromFeature(&local), FromFeature(&library)}, []*Feature{FromFeature(&remote), FromFeature(&renamed)})
+
+	title := `C++20 "` + name + `"`
+	want := []Divergence{
+		{`C++17 "std::any"`, "listed only locally"},
+		{`C++20 "Initializer list constructors in CTAD"`, "listed only remotely"},
+		{title, "Apple Clang support is 'yes 10.0.1' locally, 'no' remotely"},
+		{title, "Clang support is 'yes 6 (partial)* (only supported if flag supplied)' locally, 'yes 6' remotely"},
+		{title, "GCC support is 'yes 9* (still some bugs)' locally, 'no' remotely"},
+		{title, "MSVC support is 'yes 19.20' locally, 'partial 19.20 (one bug)' remotely"},
+	}
+	if !reflect.DeepEqual(divergences, want) {
+		t.Errorf("got %q, want %q", divergences, want)
+	}
+
+	if divergences := CompareFeatures([]*Feature{FromFeature(&local)}, []*Feature{FromFeature(&local)}); len(divergences) != 0 {
+		t.Errorf("feature differs from itself: %q", divergences)
+	}
+}
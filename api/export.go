@@ -0,0 +1,43 @@
+package api
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"time"
+)
+
+// ExportSchemaVersion is raised whenever a field of the export is changed or removed. added fields don't raise it
+const ExportSchemaVersion = 1
+
+// Export is the whole dataset of the bot, for consumption by scripts
+type Export struct {
+	SchemaVersion int        `json:"schema_version"`
+	Generated     time.Time  `json:"generated"`
+	Features      []*Feature `json:"features"`
+	History       []*Feature `json:"history,omitempty"` //every stored entry, oldest first. only present if asked for
+}
+
+// NewExport gathers the current entry of every feature, and all entries if history is set
+func NewExport(ctx context.Context, service compliance.Service, history bool) (*Export, error) {
+	latest, err := service.GetLatestEntries(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &Export{
+		SchemaVersion: ExportSchemaVersion,
+		Generated:     time.Now().UTC(),
+		Features:      fromFeatures(compliance.CurrentFeatures(latest)),
+	}
+
+	if history {
+		entries, err := service.GetEntriesSince(ctx, time.Time{})
+		if err != nil {
+			return nil, err
+		}
+
+		export.History = fromFeatures(entries)
+	}
+
+	return export, nil
+}
@@ -0,0 +1,602 @@
+package api
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	graphqlerrors "github.com/graph-gophers/graphql-go/errors"
+)
+
+// graphQLSchema is what the GraphQL endpoint serves, what the REST endpoints do with filters for richer queries, like
+// every feature Clang supports but MSVC doesn't:
+//
+//	{ features(supportedBy: "clang", notSupportedBy: "msvc") { name cppVersion } }
+const graphQLSchema = `schema {
+	query: Query
+}
+
+type Query {
+	"The latest entry of every feature, or the entries as they were at a time"
+	features(
+		"only features of this language, cpp, c or cppdr"
+		language: String
+		"only features of this version of the language, like 20"
+		version: Int
+		"only features this compiler lists support of"
+		compiler: String
+		"only features the compiler lists with this support, no, partial or yes"
+		support: String
+		"only features all of these compilers fully support"
+		supportedBy: [String!]
+		"only features none of these compilers fully support"
+		notSupportedBy: [String!]
+		"only features removed from the page, or only those that are not"
+		removed: Boolean
+		"RFC 3339 time to give the entries as they were at"
+		at: String
+	): [Feature!]
+
+	"Every entry of a feature, including those from before it was renamed"
+	history(
+		"slug of the feature, or its name if it has no slug yet"
+		feature: String!
+		"language to look the feature up in by name, cpp if not given"
+		language: String
+	): [Feature!]
+
+	"The entries created in a time range, together with the entry they replaced, a page at a time"
+	changes(
+		"RFC 3339 time to list the changes after"
+		since: String!
+		"RFC 3339 time to list the changes up to"
+		until: String
+		"only changes of features of this language"
+		language: String
+		"only changes of features of this version of the language, within the entries of the page"
+		version: Int
+		"only changes to the support of this compiler, within the entries of the page"
+		compiler: String
+		"entries a page is read from, 100 if not given and at most 250"
+		first: Int
+		"the next of the page before, to read the page after it"
+		after: String
+	): ChangesPage
+
+	"How many of the current features of every language version each compiler lists with each support level"
+	stats(
+		"only the counts of this language"
+		language: String
+		"only the counts of this version of the language"
+		version: Int
+		"only the counts of this compiler"
+		compiler: String
+		"only the counts of this support level, no, partial or yes"
+		support: String
+	): [SupportCount!]
+}
+
+type Feature {
+	slug: String!
+	language: String!
+	name: String!
+	"RFC 3339 time the entry was created at"
+	timestamp: String!
+	cppVersion: Int!
+	paperName: String!
+	paperLink: String!
+	compilers: [CompilerSupport!]!
+	removed: Boolean!
+}
+
+type CompilerSupport {
+	compiler: String!
+	kind: String!
+	name: String!
+	support: String!
+	displayText: String!
+	extraText: String!
+}
+
+"The support of one compiler before and after a change, previous is null for new listings"
+type CompilerDelta {
+	compiler: String!
+	previous: CompilerSupport
+	next: CompilerSupport!
+}
+
+type Change {
+	kind: String!
+	previous: Feature
+	next: Feature
+	deltas: [CompilerDelta!]!
+}
+
+"A page of changes, with the cursor of the next page, which is null on the last page"
+type ChangesPage {
+	changes: [Change!]!
+	next: String
+}
+
+type SupportCount {
+	language: String!
+	version: Int!
+	standard: String!
+	compiler: String!
+	support: String!
+	features: Int!
+}
+`
+
+// graphQLFeature resolves the fields of a feature GraphQL has no type for
+type graphQLFeature struct {
+	Feature
+}
+
+func (f graphQLFeature) Timestamp() string {
+	return f.Feature.Timestamp.Format(time.RFC3339)
+}
+
+func (f graphQLFeature) CppVersion() int32 {
+	return int32(f.Feature.CppVersion)
+}
+
+// graphQLFeatures gives the features as the result of a query, which is null if it fails
+func graphQLFeatures(features []*Feature) *[]graphQLFeature {
+	result := []graphQLFeature{}
+	for _, feature := range features {
+		result = append(result, graphQLFeature{*feature})
+	}
+	return &result
+}
+
+type graphQLChange struct {
+	Change
+}
+
+func (c graphQLChange) Previous() *graphQLFeature {
+	if c.Change.Previous == nil {
+		return nil
+	}
+	return &graphQLFeature{*c.Change.Previous}
+}
+
+func (c graphQLChange) Next() *graphQLFeature {
+	if c.Change.Next == nil {
+		return nil
+	}
+	return &graphQLFeature{*c.Change.Next}
+}
+
+type graphQLChangesPage struct {
+	changes []graphQLChange
+	next    *string
+}
+
+func (p graphQLChangesPage) Changes() []graphQLChange {
+	return p.changes
+}
+
+func (p graphQLChangesPage) Next() *string {
+	return p.next
+}
+
+type graphQLSupportCount struct {
+	SupportCount
+}
+
+func (c graphQLSupportCount) Version() int32 {
+	return int32(c.SupportCount.Version)
+}
+
+func (c graphQLSupportCount) Features() int32 {
+	return int32(c.SupportCount.Features)
+}
+
+// languageArg gives the language the query is filtered by, empty for all of them
+func languageArg(language *string) (string, error) {
+	if language == nil || *language == "" {
+		return "", nil
+	}
+	if !compliance.IsLanguage(*language) {
+		return "", fmt.Errorf("language must be one of %v", strings.Join(compliance.Languages, ", "))
+	}
+
+	return *language, nil
+}
+
+// timeArg gives the RFC 3339 time of the argument, the zero time if it isn't given
+func timeArg(value *string, name string) (time.Time, error) {
+	if value == nil || *value == "" {
+		return time.Time{}, nil
+	}
+
+	at, err := time.Parse(time.RFC3339, *value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%v must be an RFC 3339 time", name)
+	}
+
+	return at, nil
+}
+
+// supportArg gives the support level named by the argument, -1 if it isn't given
+func supportArg(name *string) (int, error) {
+	if name == nil || *name == "" {
+		return -1, nil
+	}
+
+	for _, support := range []int{compliance.SupportNo, compliance.SupportPartial, compliance.SupportYes} {
+		if compliance.SupportLevelName(support) == *name {
+			return support, nil
+		}
+	}
+
+	return -1, fmt.Errorf("support must be one of no, partial, yes")
+}
+
+func stringArg(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func stringsArg(values *[]string) []string {
+	if values == nil {
+		return nil
+	}
+	return *values
+}
+
+// featureArgs are the arguments of the features query
+type featureArgs struct {
+	Language       *string
+	Version        *int32
+	Compiler       *string
+	Support        *string
+	SupportedBy    *[]string
+	NotSupportedBy *[]string
+	Removed        *bool
+	At             *string
+}
+
+// featureFilter picks features by the arguments of the features query
+type featureFilter struct {
+	language       string
+	version        *int32
+	compiler       string
+	support        int
+	supportedBy    []string
+	notSupportedBy []string
+	removed        *bool
+}
+
+func newFeatureFilter(args featureArgs) (*featureFilter, error) {
+	filter := &featureFilter{
+		version:        args.Version,
+		compiler:       stringArg(args.Compiler),
+		supportedBy:    stringsArg(args.SupportedBy),
+		notSupportedBy: stringsArg(args.NotSupportedBy),
+		removed:        args.Removed,
+	}
+	var err error
+
+	if filter.language, err = languageArg(args.Language); err != nil {
+		return nil, err
+	}
+	if filter.support, err = supportArg(args.Support); err != nil {
+		return nil, err
+	}
+	if filter.support >= 0 && filter.compiler == "" {
+		return nil, fmt.Errorf("support needs the compiler it is the support of")
+	}
+
+	return filter, nil
+}
+
+// supports tells if the compiler lists full support of the feature
+func supports(feature *compliance.Feature, compiler string) bool {
+	support := feature.SupportFor(compiler)
+	return support != nil && support.Support == compliance.SupportYes
+}
+
+func (f *featureFilter) matches(feature *compliance.Feature) bool {
+	if f.language != "" && feature.Language != f.language {
+		return false
+	}
+	if f.version != nil && feature.CppVersion != int(*f.version) {
+		return false
+	}
+	if f.removed != nil && feature.Removed != *f.removed {
+		return false
+	}
+
+	if f.compiler != "" {
+		support := feature.SupportFor(f.compiler)
+		if support == nil || f.support >= 0 && support.Support != f.support {
+			return false
+		}
+	}
+
+	for _, compiler := range f.supportedBy {
+		if !supports(feature, compiler) {
+			return false
+		}
+	}
+	for _, compiler := range f.notSupportedBy {
+		if supports(feature, compiler) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// graphQLResolver resolves the queries of the GraphQL schema
+type graphQLResolver struct {
+	service compliance.Service
+}
+
+func (r *graphQLResolver) Features(ctx context.Context, args featureArgs) (*[]graphQLFeature, error) {
+	filter, err := newFeatureFilter(args)
+	if err != nil {
+		return nil, err
+	}
+	at, err := timeArg(args.At, "at")
+	if err != nil {
+		return nil, err
+	}
+
+	var features []compliance.Feature
+	if at.IsZero() {
+		features, err = r.service.GetLatestEntries(ctx)
+	} else {
+		features, err = r.service.GetEntriesAt(ctx, at)
+	}
+	if err != nil {
+		slog.Error("api: error getting latest entries", "err", err)
+		return nil, fmt.Errorf("could not get features")
+	}
+
+	var result []compliance.Feature
+	for i := range features {
+		if filter.matches(&features[i]) {
+			result = append(result, features[i])
+		}
+	}
+
+	return graphQLFeatures(fromFeatures(result)), nil
+}
+
+func (r *graphQLResolver) History(ctx context.Context, args struct {
+	Feature  string
+	Language *string
+}) (*[]graphQLFeature, error) {
+	language, err := languageArg(args.Language)
+	if err != nil {
+		return nil, err
+	}
+	if language == "" {
+		language = compliance.LanguageCpp
+	}
+
+	history, err := r.service.GetFeatureHistoryBySlug(ctx, args.Feature)
+	if err == nil && len(history) == 0 {
+		history, err = r.service.GetFeatureHistory(ctx, language, args.Feature)
+	}
+	if err != nil {
+		slog.Error("api: error getting history", "feature", args.Feature, "err", err)
+		return nil, fmt.Errorf("could not get feature history")
+	}
+
+	return graphQLFeatures(fromFeatures(history)), nil
+}
+
+func (r *graphQLResolver) Changes(ctx context.Context, args struct {
+	Since    string
+	Until    *string
+	Language *string
+	Version  *int32
+	Compiler *string
+	First    *int32
+	After    *string
+}) (*graphQLChangesPage, error) {
+	since, err := timeArg(&args.Since, "since")
+	if err != nil {
+		return nil, err
+	}
+	until, err := timeArg(args.Until, "until")
+	if err != nil {
+		return nil, err
+	}
+	language, err := languageArg(args.Language)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := newFeatureFilter(featureArgs{Version: args.Version})
+	if err != nil {
+		return nil, err
+	}
+	compiler := stringArg(args.Compiler)
+
+	limit := DefaultChangesLimit
+	if args.First != nil {
+		if limit = int(*args.First); limit < 1 || limit > MaxChangesLimit {
+			return nil, fmt.Errorf("first must be between 1 and %v", MaxChangesLimit)
+		}
+	}
+	var after int64
+	if cursor := stringArg(args.After); cursor != "" {
+		if after, err = strconv.ParseInt(cursor, 10, 64); err != nil || after < 0 {
+			return nil, fmt.Errorf("invalid cursor in after")
+		}
+	}
+
+	entries, err := r.service.GetEntryChanges(ctx, language, since, after, limit)
+	if err != nil {
+		slog.Error("api: error getting changes", "since", since, "err", err)
+		return nil, fmt.Errorf("could not get changes")
+	}
+
+	//entries come in the order they were created, so the page ends at the first one after until
+	var next *string
+	if len(entries) == limit {
+		cursor := strconv.FormatInt(entries[len(entries)-1].Entry.Id, 10)
+		next = &cursor
+	}
+	var inRange []compliance.EntryChange
+	for i := range entries {
+		if !until.IsZero() && entries[i].Entry.Timestamp.After(until) {
+			next = nil
+			break
+		}
+		if filter.matches(&entries[i].Entry) {
+			inRange = append(inRange, entries[i])
+		}
+	}
+
+	result := []graphQLChange{}
+	for _, change := range changesOf(inRange) {
+		if compiler == "" {
+			result = append(result, graphQLChange{change})
+			continue
+		}
+
+		for _, delta := range change.Deltas {
+			if delta.Compiler == compiler {
+				result = append(result, graphQLChange{change})
+				break
+			}
+		}
+	}
+
+	return &graphQLChangesPage{changes: result, next: next}, nil
+}
+
+func (r *graphQLResolver) Stats(ctx context.Context, args struct {
+	Language *string
+	Version  *int32
+	Compiler *string
+	Support  *string
+}) (*[]graphQLSupportCount, error) {
+	language, err := languageArg(args.Language)
+	if err != nil {
+		return nil, err
+	}
+	support, err := supportArg(args.Support)
+	if err != nil {
+		return nil, err
+	}
+	compiler := stringArg(args.Compiler)
+
+	counts, err := r.service.GetSupportCounts(ctx)
+	if err != nil {
+		slog.Error("api: error counting support", "err", err)
+		return nil, fmt.Errorf("could not count support")
+	}
+
+	result := []graphQLSupportCount{}
+	for _, count := range counts {
+		if language != "" && count.Language != language || args.Version != nil && count.CppVersion != int(*args.Version) ||
+			compiler != "" && count.Compiler != compiler || support >= 0 && count.Support != support {
+			continue
+		}
+
+		result = append(result, graphQLSupportCount{fromSupportCount(count)})
+	}
+
+	return &result, nil
+}
+
+// limits of a GraphQL query. queries of the schema nest a few fields deep, the introspection query of GraphQL clients
+// nests deepest, about a dozen fields. at most graphQLMaxParallelism fields are resolved at the same time
+const (
+	graphQLMaxDepth       = 15
+	graphQLMaxParallelism = 4
+)
+
+func newGraphQLSchema(service compliance.Service) *graphql.Schema {
+	return graphql.MustParseSchema(graphQLSchema, &graphQLResolver{service}, graphql.UseFieldResolvers(), graphql.UseStringDescriptions(),
+		graphql.MaxDepth(graphQLMaxDepth), graphql.MaxParallelism(graphQLMaxParallelism))
+}
+
+// graphQLRequest is a query as GraphQL clients send it
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// readGraphQLRequest reads the query of a GET request from its parameters, and that of a POST request from its json
+// body, or the body itself if it is sent as application/graphql
+func readGraphQLRequest(w http.ResponseWriter, r *http.Request) (graphQLRequest, error) {
+	request := graphQLRequest{}
+
+	if r.Method == http.MethodGet {
+		query := r.URL.Query()
+		request.Query = query.Get("query")
+		request.OperationName = query.Get("operationName")
+		if variables := query.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &request.Variables); err != nil {
+				return request, fmt.Errorf("variables must be a json object")
+			}
+		}
+		return request, nil
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 1<<20))
+	if err != nil {
+		return request, fmt.Errorf("could not read the request")
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/graphql" {
+		request.Query = string(body)
+	} else if err := json.Unmarshal(body, &request); err != nil {
+		return request, fmt.Errorf("request must be a json object with the query")
+	}
+
+	return request, nil
+}
+
+// handleGraphQL runs GraphQL queries given as GET parameters or posted, and gives the schema in the GraphQL schema
+// language to requests without a query
+func (s *Server) handleGraphQL(w http.ResponseWriter, r *http.Request) {
+	request, err := readGraphQLRequest(w, r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, graphql.Response{Errors: []*graphqlerrors.QueryError{{Message: err.Error()}}})
+		return
+	}
+	if request.Query == "" {
+		if r.Method == http.MethodGet {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.Write([]byte(graphQLSchema))
+			return
+		}
+
+		writeJSON(w, http.StatusBadRequest, graphql.Response{Errors: []*graphqlerrors.QueryError{{Message: "no query given"}}})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), RequestTimeout)
+	defer cancel()
+
+	response := s.graphql.Exec(ctx, request.Query, request.OperationName, request.Variables)
+
+	//requests that could not be run at all have no data
+	status := http.StatusOK
+	if response.Data == nil {
+		status = http.StatusBadRequest
+	}
+
+	writeJSON(w, status, response)
+}
@@ -0,0 +1,182 @@
+package api
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func graphQLSupport(compiler string, support int, displayText string) compliance.CompilerSupport {
+	return compliance.CompilerSupport{
+		Compiler:    compiler,
+		Kind:        compliance.KindCompiler,
+		Support:     support,
+		DisplayText: sql.NullString{String: displayText, Valid: true},
+	}
+}
+
+func newGraphQLTestServer(t *testing.T) *Server {
+	t.Helper()
+
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	service := compliance.NewMemoryService()
+	service.Now = func() time.Time {
+		now = now.Add(time.Minute)
+		return now
+	}
+
+	for _, feature := range []compliance.Feature{
+		{Name: "Modules", CppVersion: 20, Compilers: []compliance.CompilerSupport{graphQLSupport("clang", 1, "16"), graphQLSupport("msvc", 2, "19.28")}},
+		{Name: "Coroutines", CppVersion: 20, Compilers: []compliance.CompilerSupport{graphQLSupport("clang", 1, "14"), graphQLSupport("msvc", 1, "19.28")}},
+		{Name: "Deducing this", CppVersion: 23, Compilers: []compliance.CompilerSupport{graphQLSupport("clang", 1, "18")}},
+		{Name: "Deducing this", CppVersion: 23, Compilers: []compliance.CompilerSupport{graphQLSupport("clang", 1, "18"), graphQLSupport("msvc", 1, "19.32")}},
+		{Name: "constexpr new", CppVersion: 20, Compilers: []compliance.CompilerSupport{graphQLSupport("clang", 0, "")}},
+	} {
+		feature := feature
+		if err := service.CreateEntry(context.Background(), &feature); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return NewServer(service)
+}
+
+type graphQLResult struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func serveGraphQL(t *testing.T, server *Server, request *http.Request) (int, graphQLResult) {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, request)
+
+	result := graphQLResult{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &result); err != nil {
+		t.Fatalf("%v: %v", recorder.Body.String(), err)
+	}
+
+	return recorder.Code, result
+}
+
+func queryGraphQL(t *testing.T, server *Server, query string) (int, graphQLResult) {
+	t.Helper()
+	return serveGraphQL(t, server, httptest.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape(query), nil))
+}
+
+func TestGraphQLQueries(t *testing.T) {
+	server := newGraphQLTestServer(t)
+
+	for _, test := range []struct {
+		query string
+		field string
+		want  string
+	}{
+		{`{ features(supportedBy: "clang", notSupportedBy: "msvc") { name cppVersion } }`, "features",
+			`[{"name":"Modules","cppVersion":20}]`},
+		{`query Partial($support: String = "partial") { partial: features(compiler: "msvc", support: $support) { ...names } } fragment names on Feature { __typename name compilers { compiler support } }`, "partial",
+			`[{"__typename":"Feature","name":"Modules","compilers":[{"compiler":"clang","support":"yes"},{"compiler":"msvc","support":"partial"}]}]`},
+		{`{ changes(since: "2024-03-01T12:00:00Z", compiler: "msvc", version: 23) { changes { kind next { name } deltas { compiler previous { support } next { support displayText } } } next } }`, "changes",
+			`{"changes":[{"kind":"new_listing","next":{"name":"Deducing this"},"deltas":[{"compiler":"gcc","previous":null,"next":{"support":"no","displayText":""}},` +
+				`{"compiler":"clang","previous":null,"next":{"support":"yes","displayText":"18"}},{"compiler":"msvc","previous":null,"next":{"support":"no","displayText":""}}]},` +
+				`{"kind":"support","next":{"name":"Deducing this"},"deltas":[{"compiler":"msvc","previous":{"support":"no"},"next":{"support":"yes","displayText":"19.32"}}]}],"next":null}`},
+		{`{ changes(since: "2024-03-01T12:00:00Z", first: 1) { changes { next { name } } next } }`, "changes",
+			`{"changes":[{"next":{"name":"Modules"}}],"next":"1"}`},
+		{`{ changes(since: "2024-03-01T12:00:00Z", first: 2, after: "1") { changes { next { name } } next } }`, "changes",
+			`{"changes":[{"next":{"name":"Coroutines"}},{"next":{"name":"Deducing this"}}],"next":"3"}`},
+		{`{ changes(since: "2024-03-01T12:00:00Z", until: "2024-03-01T12:02:00Z", first: 3) { changes { next { name } } next } }`, "changes",
+			`{"changes":[{"next":{"name":"Modules"}},{"next":{"name":"Coroutines"}}],"next":null}`},
+		{`{ stats(compiler: "clang", support: "yes") { standard features } }`, "stats",
+			`[{"standard":"C++20","features":2},{"standard":"C++23","features":1}]`},
+	} {
+		code, result := queryGraphQL(t, server, test.query)
+		if code != http.StatusOK || len(result.Errors) != 0 {
+			t.Errorf("%v: got status %v, errors %+v", test.query, code, result.Errors)
+			continue
+		}
+
+		var got, want interface{}
+		json.Unmarshal(result.Data[test.field], &got)
+		json.Unmarshal([]byte(test.want), &want)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%v: got %s, want %v", test.query, result.Data[test.field], test.want)
+		}
+	}
+}
+
+func TestGraphQLErrors(t *testing.T) {
+	server := newGraphQLTestServer(t)
+
+	//queries that can't be run at all have no data
+	for _, query := range []string{
+		`{ stats(compiler: "clang", supported: "yes") { standard } }`,
+		`{ features { name size } }`,
+		`{ features { name compilers } }`,
+		`{ changes { kind } }`,
+		`{ features { name`,
+		`mutation { features { name } }`,
+		`{ __schema { types { fields { type { ofType { ofType { ofType { ofType { ofType { ofType { ofType { ofType { ofType { ofType { ofType { name } } } } } } } } } } } } } } }`,
+	} {
+		if code, result := queryGraphQL(t, server, query); code != http.StatusBadRequest || len(result.Errors) == 0 || result.Data != nil {
+			t.Errorf("%v: got status %v, %+v", query, code, result)
+		}
+	}
+
+	//invalid arguments fail only their field
+	for _, query := range []string{
+		`{ stats(language: "rust") { standard } }`,
+		`{ features(at: "yesterday") { name } }`,
+		`{ features(support: "yes") { name } }`,
+		`{ changes(since: "2024-03-01T12:00:00Z", first: 251) { next } }`,
+		`{ changes(since: "2024-03-01T12:00:00Z", after: "yesterday") { next } }`,
+	} {
+		code, result := queryGraphQL(t, server, query)
+		if code != http.StatusOK || len(result.Errors) != 1 {
+			t.Errorf("%v: got status %v, %+v", query, code, result)
+		}
+		for field, value := range result.Data {
+			if string(value) != "null" {
+				t.Errorf("%v: got %v %s, want null", query, field, value)
+			}
+		}
+	}
+}
+
+func TestGraphQLRequests(t *testing.T) {
+	server := newGraphQLTestServer(t)
+
+	posted := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(
+		`{"query": "query Version($version: Int!) { features(version: $version, removed: false) { name } }", "variables": {"version": 23}}`))
+	posted.Header.Set("Content-Type", "application/json")
+	if code, result := serveGraphQL(t, server, posted); code != http.StatusOK || string(result.Data["features"]) != `[{"name":"Deducing this"}]` {
+		t.Errorf("json post: got status %v, %+v", code, result)
+	}
+
+	posted = httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{ history(feature: "Modules") { name } }`))
+	posted.Header.Set("Content-Type", "application/graphql")
+	if code, result := serveGraphQL(t, server, posted); code != http.StatusOK || string(result.Data["history"]) != `[{"name":"Modules"}]` {
+		t.Errorf("graphql post: got status %v, %+v", code, result)
+	}
+
+	variables := url.Values{"query": {`query Version($version: Int!) { features(version: $version) { name } }`}, "variables": {`{"version": 17}`}}
+	if code, result := serveGraphQL(t, server, httptest.NewRequest(http.MethodGet, "/graphql?"+variables.Encode(), nil)); code != http.StatusOK || string(result.Data["features"]) != `[]` {
+		t.Errorf("get with variables: got status %v, %+v", code, result)
+	}
+
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/graphql", nil))
+	if recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), "type Feature {") {
+		t.Errorf("schema: got status %v, %v", recorder.Code, recorder.Body.String())
+	}
+}
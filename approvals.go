@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var approveCommand = &cobra.Command{
+	Use:   "approve <id>",
+	Short: "Approve the report of an entry waiting for approval, to be posted on the next tick",
+	Long: `Approve the report of an entry waiting for approval, to be posted on the next tick.
+Reports wait for approval if ReportApproval is set. With --text the report is posted with the given text instead of the
+rendered one.`,
+	Args: cobra.ExactArgs(1),
+	RunE: approveCmdFunc,
+}
+
+var approveListCommand = &cobra.Command{
+	Use:   "list",
+	Short: "List the reports waiting for approval, with the text they would be posted with",
+	Args:  cobra.NoArgs,
+	RunE:  approveListCmdFunc,
+}
+
+var rejectCommand = &cobra.Command{
+	Use:   "reject <id>",
+	Short: "Reject the report of an entry waiting for approval, so it is never posted",
+	Args:  cobra.ExactArgs(1),
+	RunE:  rejectCmdFunc,
+}
+
+var approveText string
+var approveListAll bool
+
+func init() {
+	approveCommand.Flags().StringVar(&approveText, "text", "", "text to post instead of the rendered one")
+	approveListCommand.Flags().BoolVar(&approveListAll, "all", false, "list the decided reports too")
+
+	approveCommand.AddCommand(approveListCommand)
+}
+
+// reportApproval gives the approved report of the entry if reports need approval. waiting is set while the report
+// waits for the maintainer or was rejected. rejected reports are skipped, which also takes them off external queues
+func reportApproval(cfg *Configuration, service compliance.Service, entry *compliance.Feature) (approval *compliance.ReportApproval, waiting bool) {
+	if !cfg.ReportApproval || cfg.SupressReporting {
+		return nil, false
+	}
+
+	approval, err := service.GetReportApproval(context.Background(), entry.Id, compliance.ChannelTwitter)
+	if err != nil {
+		slog.Error("error getting the approval of the report, holding it back", "entry", entry.Id, "feature", entry.Name, "err", err)
+		return nil, true
+	}
+
+	if approval == nil {
+		return nil, false
+	}
+
+	switch approval.Status {
+	case compliance.ApprovalApproved:
+		return approval, false
+	case compliance.ApprovalRejected:
+		if err := service.SetReportSkipped(context.Background(), entry.Id, compliance.ChannelTwitter); err != nil {
+			slog.Error("error skipping rejected report", "entry", entry.Id, "feature", entry.Name, "err", err)
+		}
+	}
+
+	return nil, true
+}
+
+// approvalRequested tells if the report of the entry went to the maintainer for approval already
+func approvalRequested(cfg *Configuration, service compliance.Service, entry *compliance.Feature) bool {
+	if !cfg.ReportApproval {
+		return false
+	}
+
+	approval, err := service.GetReportApproval(context.Background(), entry.Id, compliance.ChannelTwitter)
+	if err != nil {
+		slog.Error("error getting the approval of the report", "entry", entry.Id, "feature", entry.Name, "err", err)
+		return false
+	}
+
+	return approval != nil
+}
+
+// requestApproval stores the rendered report of the entry to wait for approval if reports need it, and tells the
+// maintainer. gives whether the report has to wait
+func requestApproval(cfg *Configuration, service compliance.Service, alert func(message string), entry *compliance.Feature, twitterThread []string, variant string, link string, shortLink string) bool {
+	if !cfg.ReportApproval || cfg.SupressReporting || len(twitterThread) == 0 {
+		return false
+	}
+
+	approval := &compliance.ReportApproval{
+		FeatureID: entry.Id,
+		Channel:   compliance.ChannelTwitter,
+		Status:    compliance.ApprovalPending,
+		Text:      strings.Join(twitterThread, "\n\n"),
+		Variant:   variant,
+		Link:      link,
+		ShortLink: shortLink,
+		Created:   time.Now(),
+	}
+
+	if err := service.StoreReportApproval(context.Background(), approval); err != nil {
+		slog.Error("error storing the report for approval, holding it back", "entry", entry.Id, "feature", entry.Name, "err", err)
+		return true
+	}
+
+	slog.Info("report waits for approval", "entry", entry.Id, "feature", entry.Name)
+	alert(fmt.Sprintf("Hello! The report of '%v' (entry %v) waits for your approval:\n\n%v\n\nApprove it with approve %v or reject it with reject %v.", entry.Name, entry.Id, approval.Text, entry.Id, entry.Id))
+
+	return true
+}
+
+func openApprovals() (*compliance.SqliteService, error) {
+	cfg := &Configuration{}
+
+	if err := viper.Unmarshal(cfg); err != nil {
+		return nil, err
+	}
+
+	if cfg.StorageMode != "sqlite3" {
+		return nil, fmt.Errorf("approve and reject need storageMode sqlite3, not %s", cfg.StorageMode)
+	}
+
+	return openSqliteService(cfg)
+}
+
+func decideApproval(args []string, approve bool, text string) error {
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("'%s' is not an entry id", args[0])
+	}
+
+	complianceStorageService, err := openApprovals()
+	if err != nil {
+		return err
+	}
+	defer complianceStorageService.Close(context.Background())
+
+	if err := complianceStorageService.DecideReportApproval(context.Background(), id, compliance.ChannelTwitter, approve, text); err != nil {
+		return err
+	}
+
+	if approve {
+		slog.Info("approved the report", "entry", id)
+	} else {
+		slog.Info("rejected the report", "entry", id)
+	}
+
+	return nil
+}
+
+func approveCmdFunc(cmd *cobra.Command, args []string) error {
+	return decideApproval(args, true, approveText)
+}
+
+func rejectCmdFunc(cmd *cobra.Command, args []string) error {
+	return decideApproval(args, false, "")
+}
+
+func approveListCmdFunc(cmd *cobra.Command, args []string) error {
+	complianceStorageService, err := openApprovals()
+	if err != nil {
+		return err
+	}
+	defer complianceStorageService.Close(context.Background())
+
+	status := compliance.ApprovalPending
+	if approveListAll {
+		status = ""
+	}
+
+	approvals, err := complianceStorageService.GetReportApprovals(context.Background(), status)
+	if err != nil {
+		return err
+	}
+
+	if len(approvals) == 0 {
+		fmt.Println("no reports wait for approval")
+		return nil
+	}
+
+	for _, approval := range approvals {
+		decided := ""
+		if approval.Decided.Valid {
+			decided = ", decided " + approval.Decided.Time.Format(historyTimeFormat)
+		}
+
+		fmt.Printf("entry %v (%v), rendered %v%v\n", approval.FeatureID, approval.Status, approval.Created.Format(historyTimeFormat), decided)
+
+		for _, line := range strings.Split(approval.Text, "\n") {
+			fmt.Printf("    %v\n", line)
+		}
+		fmt.Println()
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var archiveCommand = &cobra.Command{
+	Use:   "archive",
+	Short: "Move old history out of the database into an archive file",
+	Long: `Move old history out of the database into a new archive file in DatabaseArchiveDir.
+Entries older than DatabaseArchiveAge days are moved, except the latest entry of every feature and
+features with changes that are not reported yet. Old page fingerprints are moved as well.`,
+	RunE: archiveCmdFunc,
+}
+
+const megabyte = 1024 * 1024
+
+// archiveDatabase moves everything older than the configured age into a new file in the archive directory
+func archiveDatabase(cfg *Configuration, service *compliance.SqliteService) (string, compliance.ArchiveResult, error) {
+	if err := os.MkdirAll(cfg.DatabaseArchiveDir, 0755); err != nil {
+		return "", compliance.ArchiveResult{}, err
+	}
+
+	now := time.Now()
+	path := filepath.Join(cfg.DatabaseArchiveDir, "archive-"+now.UTC().Format("20060102150405")+".db")
+	before := now.Add(-time.Duration(cfg.DatabaseArchiveAge) * 24 * time.Hour)
+
+	result, err := service.Archive(context.Background(), path, before)
+	if err != nil {
+		return "", result, err
+	}
+
+	slog.Info("archived old history", "entries", result.Features, "cells", result.Cells, "fingerprints", result.Fingerprints,
+		"before", before.Format(time.RFC3339), "path", path)
+
+	return path, result, nil
+}
+
+// databaseSizeJob alerts the maintainer once the database grows past the configured size, and archives old history
+// when it grows past the rotate size. the alert is raised again only after the size went back below it
+func databaseSizeJob(cfg *Configuration, service *compliance.SqliteService, alert func(message string)) func() {
+	alerted := false
+
+	return func() {
+		size, err := service.DatabaseSize(context.Background())
+		if err != nil {
+			slog.Error("could not check database size", "err", err)
+			return
+		}
+
+		if cfg.DatabaseRotateSize > 0 && size > int64(cfg.DatabaseRotateSize)*megabyte {
+			slog.Info("archiving old history", "megabytes", size/megabyte)
+
+			path, result, err := archiveDatabase(cfg, service)
+			if err != nil {
+				alert(fmt.Sprintf("cppimpbot: database is %v MB and archiving old history failed: %v", size/megabyte, err))
+				return
+			}
+
+			if size, err = service.DatabaseSize(context.Background()); err != nil {
+				slog.Error("could not check database size", "err", err)
+				return
+			}
+
+			alert(fmt.Sprintf("cppimpbot: archived %v entries into %v, database is now %v MB", result.Features, path, size/megabyte))
+		}
+
+		if cfg.DatabaseSizeAlert <= 0 {
+			return
+		}
+
+		if size <= int64(cfg.DatabaseSizeAlert)*megabyte {
+			alerted = false
+			return
+		}
+
+		if !alerted {
+			alert(fmt.Sprintf("cppimpbot: database is %v MB, past the alert size of %v MB", size/megabyte, cfg.DatabaseSizeAlert))
+			alerted = true
+		}
+	}
+}
+
+func archiveCmdFunc(cmd *cobra.Command, args []string) error {
+	cfg := &Configuration{}
+
+	if err := viper.Unmarshal(cfg); err != nil {
+		return err
+	}
+
+	if cfg.StorageMode != "sqlite3" {
+		return fmt.Errorf("archive needs storageMode sqlite3, not %s", cfg.StorageMode)
+	}
+
+	complianceStorageService, err := openSqliteService(cfg)
+	if err != nil {
+		return err
+	}
+	defer complianceStorageService.Close(context.Background())
+
+	sizeBefore, err := complianceStorageService.DatabaseSize(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if _, _, err := archiveDatabase(cfg, complianceStorageService); err != nil {
+		return err
+	}
+
+	sizeAfter, err := complianceStorageService.DatabaseSize(context.Background())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("stored data went from %v to %v KB\n", sizeBefore/1024, sizeAfter/1024)
+
+	return nil
+}
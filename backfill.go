@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"cppimpbot/scraper"
+	"fmt"
+	"io/ioutil"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var backfillCommand = &cobra.Command{
+	Use:   "backfill <snapshot directory>",
+	Short: "Replay dated html snapshots of the compiler support page into the database, oldest first, without reporting them",
+	Long: `Replay dated html snapshots of the compiler support page into the database, oldest first, without reporting them.
+The date is taken from the file name, like the Wayback Machine's 20190315120000.html or 2019-03-15.html.
+Snapshots older than the newest stored entry are skipped, as history can only be appended to.`,
+	Args: cobra.ExactArgs(1),
+	RunE: backfillCmdFunc,
+}
+
+var snapshotDatePattern = regexp.MustCompile(`(\d{4})-?(\d{2})-?(\d{2})(?:[T_ -]?(\d{2})[:-]?(\d{2})[:-]?(\d{2}))?`)
+
+// snapshotDate reads the date a snapshot was taken out of its file name. times are UTC
+func snapshotDate(name string) (time.Time, bool) {
+	match := snapshotDatePattern.FindStringSubmatch(filepath.Base(name))
+	if match == nil {
+		return time.Time{}, false
+	}
+
+	if match[4] == "" {
+		match[4], match[5], match[6] = "00", "00", "00"
+	}
+
+	date, err := time.Parse("2006 01 02 15 04 05", fmt.Sprintf("%v %v %v %v %v %v", match[1], match[2], match[3], match[4], match[5], match[6]))
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return date, true
+}
+
+type snapshotFile struct {
+	path string
+	date time.Time
+}
+
+func snapshotFiles(directory string) ([]snapshotFile, error) {
+	infos, err := ioutil.ReadDir(directory)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []snapshotFile
+	for _, info := range infos {
+		if info.IsDir() {
+			continue
+		}
+
+		date, ok := snapshotDate(info.Name())
+		if !ok {
+			slog.Warn("skipping snapshot, there is no date in its name", "path", info.Name())
+			continue
+		}
+
+		result = append(result, snapshotFile{filepath.Join(directory, info.Name()), date})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].date.Before(result[j].date)
+	})
+
+	return result, nil
+}
+
+func backfillCmdFunc(cmd *cobra.Command, args []string) error {
+	cfg := &Configuration{}
+
+	if err := viper.Unmarshal(cfg); err != nil {
+		return err
+	}
+
+	if cfg.StorageMode != "sqlite3" {
+		return fmt.Errorf("backfill needs storageMode sqlite3, not %s", cfg.StorageMode)
+	}
+
+	snapshots, err := snapshotFiles(args[0])
+	if err != nil {
+		return err
+	}
+
+	complianceStorageService, err := openSqliteService(cfg)
+	if err != nil {
+		return err
+	}
+	defer complianceStorageService.Close(context.Background())
+
+	latest, err := complianceStorageService.GetLatestEntries(context.Background())
+	if err != nil {
+		return err
+	}
+
+	//every language has a history of its own, so C snapshots can be replayed into a database that tracks C++ already
+	newest := map[string]time.Time{}
+	for _, entry := range latest {
+		if entry.Timestamp.After(newest[entry.Language]) {
+			newest[entry.Language] = entry.Timestamp
+		}
+	}
+
+	replayed := 0
+
+	for _, snapshot := range snapshots {
+		file, err := os.Open(snapshot.path)
+		if err != nil {
+			return err
+		}
+
+		scraped, err := scraper.ScrapeFromReader(file, cfg.StrictParsing, cfg.ScrapeDefectReports)
+		file.Close()
+
+		if err != nil {
+			slog.Warn("skipping snapshot", "path", snapshot.path, "err", err)
+			continue
+		}
+
+		var stale []string
+		seen := map[string]bool{}
+		for _, version := range scraped.Versions {
+			if last := newest[version.Language]; !seen[version.Language] && !snapshot.date.After(last) {
+				stale = append(stale, fmt.Sprintf("%v (%v)", compliance.LanguageName(version.Language), last))
+			}
+			seen[version.Language] = true
+		}
+		if len(stale) > 0 {
+			slog.Warn("skipping snapshot, it is not newer than the stored history", "path", snapshot.path, "languages", strings.Join(stale, ", "))
+			continue
+		}
+
+		if len(scraped.Errors) > 0 {
+			slog.Warn("skipped rows", "path", snapshot.path, "rows", len(scraped.Errors), "errors", scraper.ErrorReport(scraped.Errors))
+		}
+
+		slog.Info("replaying snapshot", "path", snapshot.path, "date", snapshot.date)
+
+		date := snapshot.date
+		complianceStorageService.Now = func() time.Time { return date }
+		storeScraped(complianceStorageService, scraped)
+
+		if err := setReplayedReported(complianceStorageService, date); err != nil {
+			return err
+		}
+
+		for _, version := range scraped.Versions {
+			newest[version.Language] = date
+		}
+		replayed++
+	}
+
+	slog.Info("replayed snapshots", "replayed", replayed, "snapshots", len(snapshots))
+
+	return nil
+}
+
+// setReplayedReported marks the entries replayed with the given date as reported, as history is not news and none of it
+// should get tweeted
+func setReplayedReported(service compliance.Service, date time.Time) error {
+	unreported, err := service.GetUnreported(context.Background(), compliance.ChannelTwitter)
+	if err != nil {
+		return err
+	}
+
+	for i := range unreported {
+		if unreported[i].Timestamp.Equal(date) {
+			if err := service.SetReported(context.Background(), unreported[i].Id, compliance.ChannelTwitter); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"cppimpbot/plugins"
+	"cppimpbot/schedule"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/dghubble/go-twitter/twitter"
+)
+
+// batchedReport is an unreported entry waiting to be posted with the other changes of its scrape
+type batchedReport struct {
+	entry  compliance.Feature
+	change compliance.Change
+}
+
+// scraping tells if a source is being scraped right now, so not all changes of the scrape are stored yet
+func scraping(scrapeScheduler *schedule.Scheduler) bool {
+	for _, job := range scrapeScheduler.Status() {
+		if job.Running && strings.HasPrefix(job.Name, "scrape ") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// reportBatch posts the changes of a scrape as a single summary tweet, with a thread of details if ReportBatchDetails
+// is set, instead of a tweet each. the other channels still get them one by one. if posting fails, every entry of the
+// batch counts a failed attempt and the error of posting is given
+func reportBatch(cfg *Configuration, options compliance.ReportOptions, client *twitter.Client, service compliance.Service, alert func(message string), channels reporters, startedPlugins []*plugins.Plugin, batch []batchedReport) error {
+	var changes []compliance.Change
+	for _, report := range batch {
+		changes = append(changes, report.change)
+	}
+
+	thread := compliance.BatchToTwitterThread(options, changes, cfg.ReportBatchDetails, cfg.ReportBatchMaxTweets)
+
+	if cfg.SupressReporting {
+		slog.Info("got batch of reports which will be supressed", "channel", compliance.ChannelTwitter, "reports", len(batch), "thread", thread)
+		for _, report := range batch {
+			service.SetReported(context.Background(), report.entry.Id, compliance.ChannelTwitter)
+		}
+		return nil
+	}
+
+	if len(thread) == 0 {
+		slog.Info("found batch of changes that I don't care about. setting as reported.", "channel", compliance.ChannelTwitter, "changes", len(batch))
+	} else if cfg.DryReporting {
+		slog.Info("Dry run: posting batch", "channel", compliance.ChannelTwitter, "reports", len(batch))
+		for _, tweet := range thread {
+			slog.Info("Dry run: posting tweet", "channel", compliance.ChannelTwitter, "tweet", tweet)
+		}
+	} else if _, _, err := postTweetThread(client, thread, nil); err != nil {
+		slog.Error("error posting batch", "channel", compliance.ChannelTwitter, "reports", len(batch), "err", err)
+		for i := range batch {
+			recordReportFailure(cfg, service, alert, &batch[i].entry, err)
+		}
+		return err
+	}
+
+	for _, report := range batch {
+		reportOnChannels(cfg, service, channels, report.entry.Id, report.change)
+		reportChangeToPlugins(cfg, startedPlugins, report.change, compliance.DefaultReportText(options, report.change, ""))
+
+		if cfg.DryReporting {
+			continue
+		}
+
+		if len(thread) > 0 && compliance.ChangeReportText(options, report.change) != "" {
+			recordPostLatency(cfg, service, time.Since(report.entry.Timestamp))
+		}
+		service.SetReported(context.Background(), report.entry.Id, compliance.ChannelTwitter)
+		if err := service.ClearReportAttempt(context.Background(), report.entry.Id, compliance.ChannelTwitter); err != nil {
+			slog.Error("error clearing report attempts", "channel", compliance.ChannelTwitter, "entry", report.entry.Id, "feature", report.entry.Name, "err", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,251 @@
+package bluesky
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultHost    = "https://bsky.social"
+	requestTimeout = 10 * time.Second
+	postCollection = "app.bsky.feed.post"
+	notifications  = 100 //notifications read per poll, the most the api gives at once
+)
+
+// Client talks to the XRPC api of the server of a Bluesky account, logged in with an app password. the session is
+// created on the first call and again once it expires
+type Client struct {
+	host     string
+	handle   string
+	password string
+	client   *http.Client
+	session  *session
+	mutex    sync.Mutex
+}
+
+type session struct {
+	AccessJwt string `json:"accessJwt"`
+	Did       string `json:"did"`
+}
+
+func NewClient(host string, handle string, password string) *Client {
+	return &Client{
+		host:     strings.TrimSuffix(host, "/"),
+		handle:   handle,
+		password: password,
+		client:   &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// Ref points at a post
+type Ref struct {
+	URI string `json:"uri"`
+	CID string `json:"cid"`
+}
+
+// Mention is a post mentioning the account
+type Mention struct {
+	Ref
+	Author    string //handle of the author
+	AuthorDID string
+	Text      string
+	Root      Ref //first post of the thread the mention is in, the mention itself if it starts one
+	IndexedAt time.Time
+}
+
+type xrpcError struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
+
+// expired tells if the answer asks for a new session
+func expired(status int, answer xrpcError) bool {
+	return status == http.StatusUnauthorized || answer.Error == "ExpiredToken" || answer.Error == "InvalidToken"
+}
+
+func (c *Client) request(ctx context.Context, method string, nsid string, query url.Values, body interface{}, token string) (*http.Response, error) {
+	target := c.host + "/xrpc/" + nsid
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var payload io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not encode %v", nsid)
+		}
+		payload = bytes.NewReader(encoded)
+	}
+
+	request, err := http.NewRequest(method, target, payload)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not create %v request", nsid)
+	}
+	request = request.WithContext(ctx)
+
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	return c.client.Do(request)
+}
+
+func decode(nsid string, response *http.Response, result interface{}) (xrpcError, error) {
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		answer := xrpcError{}
+		json.NewDecoder(response.Body).Decode(&answer)
+		return answer, fmt.Errorf("bluesky refused %v with %v: %v %v", nsid, response.Status, answer.Error, answer.Message)
+	}
+
+	if result == nil {
+		return xrpcError{}, nil
+	}
+
+	if err := json.NewDecoder(response.Body).Decode(result); err != nil {
+		return xrpcError{}, errors.Wrapf(err, "could not decode bluesky answer to %v", nsid)
+	}
+
+	return xrpcError{}, nil
+}
+
+// login gives the current session, created first if there is none. the mutex has to be held
+func (c *Client) login(ctx context.Context) (*session, error) {
+	if c.session != nil {
+		return c.session, nil
+	}
+
+	nsid := "com.atproto.server.createSession"
+	response, err := c.request(ctx, http.MethodPost, nsid, nil, map[string]string{"identifier": c.handle, "password": c.password}, "")
+	if err != nil {
+		return nil, errors.Wrap(err, "could not log in to bluesky")
+	}
+
+	created := &session{}
+	if _, err := decode(nsid, response, created); err != nil {
+		return nil, err
+	}
+	c.session = created
+
+	return created, nil
+}
+
+// call calls the api as the account, logging in again once if the session expired
+func (c *Client) call(ctx context.Context, method string, nsid string, query url.Values, body func(did string) interface{}, result interface{}) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for retried := false; ; retried = true {
+		current, err := c.login(ctx)
+		if err != nil {
+			return err
+		}
+
+		var payload interface{}
+		if body != nil {
+			payload = body(current.Did)
+		}
+
+		response, err := c.request(ctx, method, nsid, query, payload, current.AccessJwt)
+		if err != nil {
+			return errors.Wrapf(err, "could not call %v", nsid)
+		}
+
+		answer, err := decode(nsid, response, result)
+		if err != nil && !retried && expired(response.StatusCode, answer) {
+			c.session = nil
+			continue
+		}
+
+		return err
+	}
+}
+
+type notificationList struct {
+	Notifications []struct {
+		Ref
+		Reason string `json:"reason"`
+		Author struct {
+			Did    string `json:"did"`
+			Handle string `json:"handle"`
+		} `json:"author"`
+		Record struct {
+			Text  string `json:"text"`
+			Reply *struct {
+				Root Ref `json:"root"`
+			} `json:"reply"`
+		} `json:"record"`
+		IndexedAt time.Time `json:"indexedAt"`
+	} `json:"notifications"`
+}
+
+// Mentions gives the mentions of the account indexed after since, oldest first. only the latest notifications are
+// looked at, so polls have to be frequent enough for those to cover the time since the last one
+func (c *Client) Mentions(ctx context.Context, since time.Time) ([]Mention, error) {
+	list := notificationList{}
+	query := url.Values{"limit": {fmt.Sprint(notifications)}}
+	if err := c.call(ctx, http.MethodGet, "app.bsky.notification.listNotifications", query, nil, &list); err != nil {
+		return nil, err
+	}
+
+	var result []Mention
+	for _, notification := range list.Notifications {
+		if notification.Reason != "mention" || !notification.IndexedAt.After(since) {
+			continue
+		}
+
+		mention := Mention{
+			Ref:       notification.Ref,
+			Author:    notification.Author.Handle,
+			AuthorDID: notification.Author.Did,
+			Text:      notification.Record.Text,
+			Root:      notification.Ref,
+			IndexedAt: notification.IndexedAt,
+		}
+		if notification.Record.Reply != nil {
+			mention.Root = notification.Record.Reply.Root
+		}
+
+		result = append(result, mention)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].IndexedAt.Before(result[j].IndexedAt)
+	})
+
+	return result, nil
+}
+
+// Reply posts the text as a reply to the mention
+func (c *Client) Reply(ctx context.Context, to Mention, text string) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	return c.call(ctx, http.MethodPost, "com.atproto.repo.createRecord", nil, func(did string) interface{} {
+		return map[string]interface{}{
+			"repo":       did,
+			"collection": postCollection,
+			"record": map[string]interface{}{
+				"$type":     postCollection,
+				"text":      text,
+				"createdAt": now,
+				"reply":     map[string]Ref{"root": to.Root, "parent": to.Ref},
+			},
+		}
+	}, nil)
+}
@@ -0,0 +1,112 @@
+package bluesky
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// fakeServer answers the calls of the client like a bluesky server, expiring the first session after the first call
+type fakeServer struct {
+	sessions int
+	calls    int
+	replies  []map[string]interface{}
+}
+
+func (f *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	writeJSON := func(status int, value interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(value)
+	}
+
+	if r.URL.Path == "/xrpc/com.atproto.server.createSession" {
+		credentials := map[string]string{}
+		json.NewDecoder(r.Body).Decode(&credentials)
+		if credentials["identifier"] != "bot.test" || credentials["password"] != "secret" {
+			writeJSON(http.StatusUnauthorized, xrpcError{"AuthenticationRequired", "Invalid identifier or password"})
+			return
+		}
+
+		f.sessions++
+		writeJSON(http.StatusOK, session{AccessJwt: "token" + string(rune('0'+f.sessions)), Did: "did:plc:bot"})
+		return
+	}
+
+	f.calls++
+	if r.Header.Get("Authorization") != "Bearer token"+string(rune('0'+f.sessions)) || (f.sessions == 1 && f.calls > 1) {
+		writeJSON(http.StatusBadRequest, xrpcError{"ExpiredToken", "Token has expired"})
+		return
+	}
+
+	switch r.URL.Path {
+	case "/xrpc/app.bsky.notification.listNotifications":
+		w.Write([]byte(`{"notifications": [
+			{"uri": "at://did:plc:ada/app.bsky.feed.post/3", "cid": "c3", "reason": "mention", "author": {"did": "did:plc:ada", "handle": "ada.test"},
+			 "record": {"text": "@bot.test support for modules?", "reply": {"root": {"uri": "at://did:plc:ada/app.bsky.feed.post/1", "cid": "c1"}}},
+			 "indexedAt": "2024-03-01T12:03:00.000Z"},
+			{"uri": "at://did:plc:ada/app.bsky.feed.post/2", "cid": "c2", "reason": "like", "author": {"did": "did:plc:ada", "handle": "ada.test"},
+			 "record": {}, "indexedAt": "2024-03-01T12:02:00.000Z"},
+			{"uri": "at://did:plc:grace/app.bsky.feed.post/1", "cid": "g1", "reason": "mention", "author": {"did": "did:plc:grace", "handle": "grace.test"},
+			 "record": {"text": "@bot.test P1103"}, "indexedAt": "2024-03-01T12:01:00.000Z"}
+		]}`))
+	case "/xrpc/com.atproto.repo.createRecord":
+		reply := map[string]interface{}{}
+		json.NewDecoder(r.Body).Decode(&reply)
+		f.replies = append(f.replies, reply)
+		writeJSON(http.StatusOK, map[string]string{"uri": "at://did:plc:bot/app.bsky.feed.post/1", "cid": "b1"})
+	default:
+		writeJSON(http.StatusNotFound, xrpcError{"MethodNotImplemented", r.URL.Path})
+	}
+}
+
+func TestMentionsAndReply(t *testing.T) {
+	fake := &fakeServer{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	client := NewClient(server.URL+"/", "bot.test", "secret")
+
+	mentions, err := client.Mentions(context.Background(), time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mentions) != 2 || mentions[0].Author != "grace.test" || mentions[1].Author != "ada.test" {
+		t.Fatalf("got mentions %+v, want those of grace and ada, oldest first", mentions)
+	}
+	if mentions[0].Root != mentions[0].Ref || mentions[1].Root.URI != "at://did:plc:ada/app.bsky.feed.post/1" {
+		t.Errorf("got roots %+v and %+v", mentions[0].Root, mentions[1].Root)
+	}
+
+	//the first session expires after the first call, the calls after it log in again
+	since := mentions[0].IndexedAt
+	if newer, err := client.Mentions(context.Background(), since); err != nil || len(newer) != 1 || newer[0].CID != "c3" {
+		t.Errorf("mentions after %v: got %+v, %v", since, newer, err)
+	}
+
+	if err := client.Reply(context.Background(), mentions[1], "Modules: GCC 11"); err != nil {
+		t.Fatal(err)
+	}
+	if fake.sessions != 2 || len(fake.replies) != 1 {
+		t.Fatalf("got %v sessions and %v replies, want 2 and 1", fake.sessions, len(fake.replies))
+	}
+
+	record := fake.replies[0]["record"].(map[string]interface{})
+	reply := record["reply"].(map[string]interface{})
+	if fake.replies[0]["repo"] != "did:plc:bot" || record["text"] != "Modules: GCC 11" ||
+		reply["parent"].(map[string]interface{})["cid"] != "c3" || reply["root"].(map[string]interface{})["cid"] != "c1" {
+		t.Errorf("got reply %+v", fake.replies[0])
+	}
+}
+
+func TestLoginFailure(t *testing.T) {
+	server := httptest.NewServer(&fakeServer{})
+	defer server.Close()
+
+	if _, err := NewClient(server.URL, "bot.test", "wrong").Mentions(context.Background(), time.Time{}); err == nil {
+		t.Errorf("wrong password logged in")
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/bluesky"
+	"cppimpbot/compliance"
+	"cppimpbot/mentions"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// blueskyChannel tells bluesky users apart from twitter users of the same id in the mention limiter
+const blueskyChannel = "bluesky"
+
+// respondToBlueskyMentions replies to the bluesky mentions indexed after since, and gives back the time of the newest
+// one seen. once ctx is done the rest are left to the next poll
+func respondToBlueskyMentions(ctx context.Context, cfg *Configuration, reportOptions compliance.ReportOptions, client *bluesky.Client, service compliance.Service, limiter *mentions.UserLimiter, since time.Time) time.Time {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	posts, err := client.Mentions(ctx, since)
+	if err != nil {
+		slog.Error("error getting mentions", "channel", blueskyChannel, "err", err)
+		return since
+	}
+
+	replies := 0
+
+	for _, post := range posts {
+		if ctx.Err() != nil {
+			break
+		}
+		if post.IndexedAt.After(since) {
+			since = post.IndexedAt
+		}
+
+		query, ok := mentions.ParseQuery(post.Text)
+		if !ok {
+			slog.Info("ignoring mention", "channel", blueskyChannel, "user", post.Author, "post", post.Text)
+			continue
+		}
+
+		if replies >= cfg.MentionMaxReplies {
+			slog.Warn("reached reply limit for this poll, ignoring mention", "channel", blueskyChannel, "user", post.Author)
+			continue
+		}
+
+		if !limiter.Allow(blueskyChannel+":"+post.AuthorDID, time.Now()) {
+			slog.Warn("user is rate limited, ignoring mention", "channel", blueskyChannel, "user", post.Author)
+			continue
+		}
+
+		//replies show up in the thread of the mention, they don't need to mention the user
+		reply, err := mentionReply(reportOptions, service, query, "")
+		if err != nil {
+			slog.Error("error searching features for mention", "query", query.Text, "err", err)
+			continue
+		}
+
+		replies++
+
+		if cfg.DryReporting {
+			slog.Info("Dry run: replying to mention", "channel", blueskyChannel, "user", post.Author, "reply", reply)
+			continue
+		}
+
+		slog.Info("replying to mention", "channel", blueskyChannel, "user", post.Author, "reply", reply)
+		//a reply being posted when shutting down is let through, the mention counts as seen already
+		if err := client.Reply(context.WithoutCancel(ctx), post, reply); err != nil {
+			slog.Error("error replying to mention", "channel", blueskyChannel, "user", post.Author, "err", err)
+		}
+	}
+
+	return since
+}
+
+// startBlueskyMentions answers the bluesky mentions arriving after startup every MentionPollInterval seconds, until
+// ctx is done. running is done once it stopped
+func startBlueskyMentions(ctx context.Context, cfg *Configuration, reportOptions compliance.ReportOptions, client *bluesky.Client, service compliance.Service, limiter *mentions.UserLimiter, running *sync.WaitGroup) {
+	mentionTicker := time.NewTicker(time.Duration(cfg.MentionPollInterval) * time.Second)
+
+	running.Add(1)
+	go func() {
+		defer running.Done()
+		slog.Info("starting mention responder ticker", "channel", blueskyChannel, "interval", cfg.MentionPollInterval)
+
+		//only answer mentions arriving after startup
+		since := time.Now()
+		if latest, err := client.Mentions(ctx, time.Time{}); err != nil {
+			slog.Error("error getting latest mention, will not answer mentions", "channel", blueskyChannel, "err", err)
+			mentionTicker.Stop()
+			return
+		} else if len(latest) > 0 {
+			since = latest[len(latest)-1].IndexedAt
+		}
+
+		for {
+			select {
+			case <-mentionTicker.C:
+				since = respondToBlueskyMentions(ctx, cfg, reportOptions, client, service, limiter, since)
+			case <-ctx.Done():
+				slog.Info("stopping mention responder ticker", "channel", blueskyChannel)
+				mentionTicker.Stop()
+				return
+			}
+		}
+	}()
+}
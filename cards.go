@@ -0,0 +1,116 @@
+//go:build !noanimation
+// +build !noanimation
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"cppimpbot/animation"
+	"cppimpbot/compliance"
+	"fmt"
+	"io/ioutil"
+	"log/slog"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var reportCardCommand = &cobra.Command{
+	Use:   "card",
+	Short: "Render the image card of the report of a stored entry into a PNG, as it would be attached with ReportImageCards",
+	Args:  cobra.NoArgs,
+	RunE:  reportCardCmdFunc,
+}
+
+var reportCardID int64
+var reportCardOutput string
+
+func init() {
+	//registered here rather than in report.go, as the noanimation build tag leaves the command out
+	reportCommand.AddCommand(reportCardCommand)
+
+	reportCardCommand.Flags().Int64Var(&reportCardID, "id", 0, "id of the entry to render the card of")
+	reportCardCommand.Flags().StringVarP(&reportCardOutput, "output", "o", "card.png", "file to write the PNG to")
+	reportCardCommand.MarkFlagRequired("id")
+}
+
+// reportCard renders the image card of the change, nil if cards are off or the change has no support table to show
+func reportCard(cfg *Configuration, change compliance.Change) []byte {
+	if !cfg.ReportImageCards || change.Kind == "" || !animation.HasCard(change) {
+		return nil
+	}
+
+	var card bytes.Buffer
+	if err := animation.RenderCard(&card, change); err != nil {
+		slog.Warn("error rendering the image card, reporting without it", "feature", change.Feature.Name, "err", err)
+		return nil
+	}
+
+	return card.Bytes()
+}
+
+func reportCardCmdFunc(cmd *cobra.Command, args []string) error {
+	cfg := &Configuration{}
+
+	if err := viper.Unmarshal(cfg); err != nil {
+		return err
+	}
+
+	if cfg.StorageMode != "sqlite3" {
+		return fmt.Errorf("report card needs storageMode sqlite3, not %s", cfg.StorageMode)
+	}
+
+	complianceStorageService, err := openSqliteService(cfg)
+	if err != nil {
+		return err
+	}
+	defer complianceStorageService.Close(context.Background())
+
+	entries, err := complianceStorageService.GetEntriesByID(context.Background(), []int64{reportCardID})
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("there is no entry %v", reportCardID)
+	}
+	entry := entries[0]
+
+	previous, err := complianceStorageService.GetPreviousEntry(context.Background(), entry.Id)
+	if err != nil {
+		return err
+	}
+
+	change, err := compliance.DiffFeatures(previous, &entry)
+	if err != nil {
+		return fmt.Errorf("could not turn entry %v into a report: %v", entry.Id, err)
+	}
+
+	reportOptions, err := loadReportOptions(cfg)
+	if err != nil {
+		return err
+	}
+
+	reportHooks, err := compliance.LoadHooks(cfg.ReportHooks)
+	if err != nil {
+		return err
+	}
+
+	change = reportHooks.Filter(reportOptions, reportedChange(cfg, change))
+	if change.Kind == "" || !animation.HasCard(change) {
+		return fmt.Errorf("the report of entry %v has no support table to draw a card of", entry.Id)
+	}
+
+	var card bytes.Buffer
+	if err := animation.RenderCard(&card, change); err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(reportCardOutput, card.Bytes(), 0644); err != nil {
+		return err
+	}
+
+	slog.Info("wrote the card", "entry", entry.Id, "feature", entry.Name, "output", reportCardOutput)
+
+	return nil
+}
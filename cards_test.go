@@ -0,0 +1,25 @@
+//go:build !noanimation
+// +build !noanimation
+
+package main
+
+import (
+	"bytes"
+	"cppimpbot/compliance"
+	"testing"
+)
+
+func TestReportCard(t *testing.T) {
+	base, supported := sampleFeature(), sampleSupported()
+	change := sampleChange(t, &base, &supported)
+
+	if card := reportCard(&Configuration{ReportImageCards: true}, change); !bytes.HasPrefix(card, []byte("\x89PNG\r\n\x1a\n")) {
+		t.Errorf("card is not a PNG: %q", card)
+	}
+	if card := reportCard(&Configuration{}, change); card != nil {
+		t.Errorf("cards are off, got a card of %v bytes", len(card))
+	}
+	if card := reportCard(&Configuration{ReportImageCards: true}, change.OnlyCompilers([]string{compliance.CompilerClang})); card != nil {
+		t.Errorf("unreported change got a card of %v bytes", len(card))
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/dghubble/go-twitter/twitter"
+)
+
+// coalescedChange is the net change of a feature over all its unreported entries
+type coalescedChange struct {
+	change  compliance.Change
+	entries []compliance.Feature
+}
+
+// coalesceChanges diffs the entry before the first unreported one of every feature against its last unreported one,
+// so a feature that changed several times while the bot was down makes a single change. features whose net change
+// can't be worked out are left to the reporter
+func coalesceChanges(cfg *Configuration, service compliance.Service, entries []compliance.Feature) ([]coalescedChange, error) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+
+	var slugs []string
+	perFeature := map[string][]compliance.Feature{}
+	for _, entry := range entries {
+		slug := entry.Slug
+		if slug == "" {
+			slug = entry.Name
+		}
+
+		if _, ok := perFeature[slug]; !ok {
+			slugs = append(slugs, slug)
+		}
+		perFeature[slug] = append(perFeature[slug], entry)
+	}
+
+	var result []coalescedChange
+	for _, slug := range slugs {
+		featureEntries := perFeature[slug]
+		first, last := featureEntries[0], featureEntries[len(featureEntries)-1]
+
+		previous, err := service.GetPreviousEntry(context.Background(), first.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		change, err := compliance.DiffFeatures(previous, &last)
+		if err != nil {
+			slog.Warn("could not coalesce the changes, leaving them to the reporter", "feature", slug, "err", err)
+			continue
+		}
+
+		change = withoutIgnored(service, reportedChange(cfg, change))
+
+		result = append(result, coalescedChange{change, featureEntries})
+	}
+
+	return result, nil
+}
+
+// catchUp checks if the reporter was down for longer than CatchUpAfter. if so, it scrapes once and posts the changes
+// missed meanwhile as a single thread instead of a report each, which would flood the timeline and trip safe mode
+func catchUp(cfg *Configuration, reportOptions compliance.ReportOptions, client *twitter.Client, service compliance.Service, reportHooks *compliance.Hooks, alert func(message string)) error {
+	lastSeen, err := service.GetHeartbeat(context.Background())
+	if err != nil {
+		return err
+	}
+
+	downtime := time.Since(lastSeen)
+	if lastSeen.IsZero() || downtime < time.Duration(cfg.CatchUpAfter)*time.Second {
+		return nil
+	}
+
+	slog.Info("was down, catching up", "since", lastSeen.Format(time.RFC3339))
+
+	//sources of plugins are left to their own schedule
+	for _, source := range sourceConfigs(cfg) {
+		scrape, ok := builtinSource(source.Name)
+		if !ok {
+			continue
+		}
+
+		err := scrape(cfg, service, alert)
+		recordScrape(cfg, service, source.Name, err == nil)
+
+		if err != nil {
+			slog.Error("error scraping to catch up", "source", source.Name, "err", err)
+		}
+	}
+
+	entries, err := service.GetUnreported(context.Background(), compliance.ChannelTwitter)
+	if err != nil {
+		return err
+	}
+
+	coalesced, err := coalesceChanges(cfg, service, pendingReports(service, entries, time.Now()))
+	if err != nil {
+		return err
+	}
+
+	var changes []compliance.Change
+	for _, c := range coalesced {
+		checkWatchlist(service, alert, c.change)
+		changes = append(changes, reportHooks.Filter(reportOptions, c.change))
+	}
+
+	thread := compliance.CatchUpToTwitterThread(reportOptions, changes, lastSeen, cfg.CatchUpMaxTweets)
+
+	if len(thread) == 0 {
+		slog.Info("nothing worth reporting happened while I was away")
+	} else if cfg.SupressReporting {
+		slog.Info("got catch-up thread which will be supressed", "channel", compliance.ChannelTwitter, "thread", thread)
+	} else if cfg.DryReporting {
+		for _, tweet := range thread {
+			slog.Info("Dry run: posting tweet", "channel", compliance.ChannelTwitter, "tweet", tweet)
+		}
+	} else if _, _, err := postTweetThread(client, thread, nil); err != nil {
+		//the changes are still unreported, so the reporter posts them one by one instead
+		return err
+	}
+
+	if !cfg.DryReporting {
+		for _, c := range coalesced {
+			for i := range c.entries {
+				service.SetReported(context.Background(), c.entries[i].Id, compliance.ChannelTwitter)
+			}
+		}
+	}
+
+	slog.Info("caught up", "features", len(coalesced))
+
+	return service.StoreHeartbeat(context.Background(), time.Now())
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/api"
+	"cppimpbot/compliance"
+	"fmt"
+	"net/http"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var compareCommand = &cobra.Command{
+	Use:   "compare",
+	Short: "Diff the features stored here against the REST API of another instance of the bot",
+	Long: `Diff the latest entry of every feature stored here against those the REST API of another instance of the bot serves,
+and list where they diverge: features only one of them lists, and differences in slugs, papers and the support of
+compilers. Useful to check a migration, a new parser or a mirror before switching over to it. Fails if anything diverges.`,
+	Args: cobra.NoArgs,
+	RunE: compareCmdFunc,
+}
+
+var compareRemote string
+var compareLanguage string
+
+func init() {
+	compareCommand.Flags().StringVar(&compareRemote, "remote", "", "base URL of the REST API of the other instance, like https://other-instance/api")
+	compareCommand.Flags().StringVar(&compareLanguage, "language", "", "compare only the features of this language, like cpp or c")
+	compareCommand.MarkFlagRequired("remote")
+}
+
+func compareCmdFunc(cmd *cobra.Command, args []string) error {
+	cfg := &Configuration{}
+
+	if err := viper.Unmarshal(cfg); err != nil {
+		return err
+	}
+
+	if cfg.StorageMode != "sqlite3" {
+		return fmt.Errorf("compare needs storageMode sqlite3, not %s", cfg.StorageMode)
+	}
+
+	if compareLanguage != "" && !compliance.IsLanguage(compareLanguage) {
+		return fmt.Errorf("unknown language: %s", compareLanguage)
+	}
+
+	complianceStorageService, err := openSqliteService(cfg)
+	if err != nil {
+		return err
+	}
+	defer complianceStorageService.Close(context.Background())
+
+	entries, err := complianceStorageService.GetLatestEntries(context.Background())
+	if err != nil {
+		return err
+	}
+
+	//filtered like the API filters the remote ones
+	if compareLanguage != "" {
+		entries = compliance.FeaturesOfLanguage(entries, compareLanguage)
+	}
+
+	var local []*api.Feature
+	for i := range entries {
+		local = append(local, api.FromFeature(&entries[i]))
+	}
+
+	remote, err := api.FetchFeatures(&http.Client{Timeout: api.RequestTimeout}, compareRemote, compareLanguage)
+	if err != nil {
+		return err
+	}
+
+	divergences := api.CompareFeatures(local, remote)
+	for _, divergence := range divergences {
+		fmt.Printf("%v: %v\n", divergence.Feature, divergence.What)
+	}
+
+	fmt.Printf("compared %v local and %v remote features\n", len(local), len(remote))
+	if len(divergences) > 0 {
+		return fmt.Errorf("%v divergences from %v", len(divergences), compareRemote)
+	}
+
+	return nil
+}
@@ -0,0 +1,226 @@
+package compliance
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Aggregate is how many of the current features of a standard, of one kind, a compiler supports fully and partially
+type Aggregate struct {
+	Id         int64     `db:"id"`
+	Timestamp  time.Time `db:"timestamp"`
+	Language   string    `db:"language"`
+	CppVersion int       `db:"cpp_version"`
+	Kind       string    `db:"kind"` //KindCompiler for core language features, KindLibrary for library features
+	Compiler   string    `db:"compiler"`
+	Full       int       `db:"full"`
+	Partial    int       `db:"partial"`
+	Total      int       `db:"total"` //features of the standard and kind, whether the compiler lists them or not
+}
+
+func (a *Aggregate) key() string {
+	return fmt.Sprintf("%v/%v/%v/%v", a.Language, a.CppVersion, a.Kind, a.Compiler)
+}
+
+// FullPercent gives the share of the features fully supported, rounded down so 100 means all of them
+func (a *Aggregate) FullPercent() int {
+	if a.Total == 0 {
+		return 0
+	}
+
+	return a.Full * 100 / a.Total
+}
+
+// PartialPercent gives the share of the features partially supported, rounded down
+func (a *Aggregate) PartialPercent() int {
+	if a.Total == 0 {
+		return 0
+	}
+
+	return a.Partial * 100 / a.Total
+}
+
+// KindName names the kind of features as in the headings of the page, core language or library
+func KindName(kind string) string {
+	if kind == KindLibrary {
+		return "library"
+	}
+
+	return "core language"
+}
+
+// ComputeAggregates counts the support of every compiler listed for a standard and kind among the latest entries,
+// leaving out removed features and the defect reports, which belong to no standard
+func ComputeAggregates(latest []Feature, at time.Time) []Aggregate {
+	byKey := map[string]*Aggregate{}
+	totals := map[string]int{}
+	var keys []string
+
+	current := CurrentFeatures(latest)
+	for i := range current {
+		feature := &current[i]
+		if feature.Removed || feature.Language == LanguageCppDR {
+			continue
+		}
+
+		kind := feature.Kind()
+		totals[fmt.Sprintf("%v/%v/%v", feature.Language, feature.CppVersion, kind)]++
+
+		for _, support := range feature.Compilers {
+			aggregate := &Aggregate{Timestamp: at, Language: feature.Language, CppVersion: feature.CppVersion, Kind: kind, Compiler: support.Compiler}
+			if existing, ok := byKey[aggregate.key()]; ok {
+				aggregate = existing
+			} else {
+				byKey[aggregate.key()] = aggregate
+				keys = append(keys, aggregate.key())
+			}
+
+			switch support.Support {
+			case SupportYes:
+				aggregate.Full++
+			case SupportPartial:
+				aggregate.Partial++
+			}
+		}
+	}
+
+	var result []Aggregate
+	for _, key := range keys {
+		aggregate := byKey[key]
+		aggregate.Total = totals[fmt.Sprintf("%v/%v/%v", aggregate.Language, aggregate.CppVersion, aggregate.Kind)]
+		result = append(result, *aggregate)
+	}
+
+	sortAggregates(result)
+	return result
+}
+
+// sortAggregates orders aggregates by language, standard by year, kind and compiler
+func sortAggregates(aggregates []Aggregate) {
+	sort.SliceStable(aggregates, func(i, j int) bool {
+		a, b := aggregates[i], aggregates[j]
+		switch {
+		case a.Language != b.Language:
+			return a.Language > b.Language //cpp before c
+		case a.CppVersion != b.CppVersion:
+			return StandardYear(a.CppVersion) < StandardYear(b.CppVersion)
+		case a.Kind != b.Kind:
+			return a.Kind == KindCompiler
+		}
+		return a.Compiler < b.Compiler
+	})
+}
+
+// ChangedAggregates gives the current aggregates whose counts differ from the latest stored ones, or that are new
+func ChangedAggregates(stored []Aggregate, current []Aggregate) []Aggregate {
+	previous := map[string]Aggregate{}
+	for _, aggregate := range stored {
+		previous[aggregate.key()] = aggregate
+	}
+
+	var result []Aggregate
+	for _, aggregate := range current {
+		last, ok := previous[aggregate.key()]
+		if !ok || last.Full != aggregate.Full || last.Partial != aggregate.Partial || last.Total != aggregate.Total {
+			result = append(result, aggregate)
+		}
+	}
+
+	return result
+}
+
+// Milestone is a compiler reaching a share of full support of a standard
+type Milestone struct {
+	Aggregate Aggregate
+	Percent   int
+}
+
+// Milestones gives the highest of the percentages every compiler's full support of a standard reached since the
+// stored aggregates. compilers newly listed for a standard reach none, their first counts are only the baseline
+func Milestones(stored []Aggregate, current []Aggregate, percentages []int) []Milestone {
+	previous := map[string]Aggregate{}
+	for _, aggregate := range stored {
+		previous[aggregate.key()] = aggregate
+	}
+
+	var result []Milestone
+	for _, aggregate := range current {
+		last, ok := previous[aggregate.key()]
+		if !ok || aggregate.Total == 0 {
+			continue
+		}
+
+		reached := -1
+		for _, percent := range percentages {
+			//compared as fractions, so 100 is only reached with every feature supported
+			reachedNow := aggregate.Full*100 >= percent*aggregate.Total
+			reachedBefore := last.Total > 0 && last.Full*100 >= percent*last.Total
+			if reachedNow && !reachedBefore && percent > reached {
+				reached = percent
+			}
+		}
+
+		if reached >= 0 {
+			result = append(result, Milestone{Aggregate: aggregate, Percent: reached})
+		}
+	}
+
+	return result
+}
+
+// StoreAggregates adds the aggregates to their time series
+func (s *SqliteService) StoreAggregates(ctx context.Context, aggregates []Aggregate) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "could not store aggregates")
+	}
+	defer tx.Rollback()
+
+	query := "INSERT INTO aggregates (timestamp, language, cpp_version, kind, compiler, full, partial, total) VALUES(?, ?, ?, ?, ?, ?, ?, ?)"
+	for _, aggregate := range aggregates {
+		if _, err := tx.ExecContext(ctx, query, aggregate.Timestamp, aggregate.Language, aggregate.CppVersion, aggregate.Kind,
+			aggregate.Compiler, aggregate.Full, aggregate.Partial, aggregate.Total); err != nil {
+			return errors.Wrap(err, "could not store aggregate")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "could not store aggregates")
+	}
+
+	return nil
+}
+
+// GetLatestAggregates gives the latest stored aggregate of every compiler, standard and kind
+func (s *SqliteService) GetLatestAggregates(ctx context.Context) ([]Aggregate, error) {
+	query := `SELECT id, timestamp, language, cpp_version, kind, compiler, full, partial, total FROM aggregates a
+		WHERE id = (SELECT id FROM aggregates b
+			WHERE b.language=a.language AND b.cpp_version=a.cpp_version AND b.kind=a.kind AND b.compiler=a.compiler
+			ORDER BY timestamp DESC, id DESC LIMIT 1)`
+
+	result := []Aggregate{}
+	if err := s.db.SelectContext(ctx, &result, query); err != nil {
+		return nil, errors.Wrap(err, "could not get latest aggregates")
+	}
+
+	sortAggregates(result)
+	return result, nil
+}
+
+// GetAggregateSeries gives the stored aggregates of a compiler and standard of both kinds, oldest first
+func (s *SqliteService) GetAggregateSeries(ctx context.Context, language string, version int, compiler string) ([]Aggregate, error) {
+	query := `SELECT id, timestamp, language, cpp_version, kind, compiler, full, partial, total FROM aggregates
+		WHERE language=? AND cpp_version=? AND compiler=?
+		ORDER BY timestamp, id`
+
+	result := []Aggregate{}
+	if err := s.db.SelectContext(ctx, &result, query, language, version, compiler); err != nil {
+		return nil, errors.Wrap(err, "could not get aggregate series")
+	}
+
+	return result, nil
+}
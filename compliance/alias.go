@@ -0,0 +1,86 @@
+package compliance
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// how a feature alias came to be
+const (
+	AliasDetected = "detected" //the scraper saw a feature disappear and one with the same paper appear
+	AliasMerged   = "merged"   //the maintainer merged the histories by hand
+)
+
+// StoreFeatureAlias records that the feature called alias is now called name. a later rename of the same old name wins
+func (s *SqliteService) StoreFeatureAlias(ctx context.Context, alias string, name string, source string) error {
+	query := "INSERT OR REPLACE INTO feature_aliases (alias, name, source, timestamp) VALUES(?, ?, ?, ?)"
+
+	if _, err := s.db.ExecContext(ctx, query, alias, name, source, time.Now()); err != nil {
+		return errors.Wrap(err, "failed to store feature alias")
+	}
+
+	return nil
+}
+
+// latestBySlugOrName gives the latest entry of the feature with the slug, or with the name if no feature has that slug
+func latestBySlugOrName(ctx context.Context, tx *sql.Tx, feature string) (name string, slug string, timestamp time.Time, err error) {
+	query := `SELECT name, slug, timestamp FROM features
+		WHERE slug=? OR (name=? AND NOT EXISTS (SELECT 1 FROM features WHERE slug=?))
+		ORDER BY timestamp DESC
+		LIMIT 1`
+
+	err = tx.QueryRowContext(ctx, query, feature, feature, feature).Scan(&name, &slug, &timestamp)
+	if err == sql.ErrNoRows {
+		err = errors.Errorf("no feature with slug or name '%s'", feature)
+	} else if err != nil {
+		err = errors.Wrap(err, "could not get feature")
+	}
+
+	return
+}
+
+// MergeFeatures joins the history of the feature from into the one of the feature into, both given by slug or name,
+// for renames that weren't detected. the entries of from take the slug of into, and the latest name of from becomes an
+// alias of into. from must be the older of the two
+func (s *SqliteService) MergeFeatures(ctx context.Context, from string, into string) (fromName string, intoName string, err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", "", errors.Wrap(err, "Failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	fromName, fromSlug, fromTime, err := latestBySlugOrName(ctx, tx, from)
+	if err != nil {
+		return "", "", err
+	}
+
+	intoName, intoSlug, intoTime, err := latestBySlugOrName(ctx, tx, into)
+	if err != nil {
+		return "", "", err
+	}
+
+	if fromSlug == intoSlug {
+		return "", "", errors.Errorf("'%s' and '%s' already share the slug '%s'", fromName, intoName, intoSlug)
+	}
+	if !fromTime.Before(intoTime) {
+		return "", "", errors.Errorf("'%s' was seen after '%s', so it can't have been renamed to it", fromName, intoName)
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE features SET slug=? WHERE slug=?", intoSlug, fromSlug); err != nil {
+		return "", "", errors.Wrap(err, "failed to merge slugs")
+	}
+
+	query := "INSERT OR REPLACE INTO feature_aliases (alias, name, source, timestamp) VALUES(?, ?, ?, ?)"
+	if _, err := tx.ExecContext(ctx, query, fromName, intoName, AliasMerged, time.Now()); err != nil {
+		return "", "", errors.Wrap(err, "failed to store feature alias")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return "", "", errors.Wrap(err, "Failed to commit transaction")
+	}
+
+	return fromName, intoName, nil
+}
@@ -0,0 +1,115 @@
+package compliance
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// states of a report waiting for the maintainer
+const (
+	ApprovalPending  = "pending"
+	ApprovalApproved = "approved"
+	ApprovalRejected = "rejected"
+)
+
+// ReportApproval is the report of an entry as it was rendered, held back until the maintainer approves it
+type ReportApproval struct {
+	FeatureID int64        `db:"entry_id"`
+	Channel   string       `db:"channel"`
+	Status    string       `db:"status"`
+	Text      string       `db:"text"`
+	Variant   string       `db:"variant"`
+	Link      string       `db:"link"`
+	ShortLink string       `db:"short_link"`
+	Created   time.Time    `db:"created"`
+	Decided   sql.NullTime `db:"decided"`
+}
+
+const approvalColumns = "entry_id, channel, status, text, variant, link, short_link, created, decided"
+
+// GetReportApproval gives the report of the entry waiting for or decided by the maintainer, nil if there is none
+func (s *SqliteService) GetReportApproval(ctx context.Context, featureID int64, channel string) (*ReportApproval, error) {
+	result := &ReportApproval{}
+
+	query := "SELECT " + approvalColumns + " FROM report_approvals WHERE entry_id=? AND channel=?"
+	if err := s.db.GetContext(ctx, result, query, featureID, channel); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "could not get report approval")
+	}
+
+	return result, nil
+}
+
+// StoreReportApproval stores a rendered report to wait for the maintainer
+func (s *SqliteService) StoreReportApproval(ctx context.Context, approval *ReportApproval) error {
+	query := `INSERT OR REPLACE INTO report_approvals (` + approvalColumns + `)
+		VALUES(:entry_id, :channel, :status, :text, :variant, :link, :short_link, :created, :decided)`
+
+	if _, err := s.db.NamedExecContext(ctx, query, approval); err != nil {
+		return errors.Wrap(err, "failed to store report approval")
+	}
+
+	return nil
+}
+
+// GetReportApprovals lists the reports with the status, or all of them if status is empty, oldest first
+func (s *SqliteService) GetReportApprovals(ctx context.Context, status string) ([]ReportApproval, error) {
+	query := "SELECT " + approvalColumns + " FROM report_approvals WHERE ?='' OR status=? ORDER BY created, entry_id"
+
+	var result []ReportApproval
+	if err := s.db.SelectContext(ctx, &result, query, status, status); err != nil {
+		return nil, errors.Wrap(err, "could not get report approvals")
+	}
+
+	return result, nil
+}
+
+// DecideReportApproval approves or rejects a pending report. text replaces the rendered one if set. rejected reports
+// are skipped right away, so they stop waiting to be reported
+func (s *SqliteService) DecideReportApproval(ctx context.Context, featureID int64, channel string, approve bool, text string) error {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "Failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	var status string
+	err = tx.GetContext(ctx, &status, "SELECT status FROM report_approvals WHERE entry_id=? AND channel=?", featureID, channel)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("no report of entry %v waits for approval on %v", featureID, channel)
+	} else if err != nil {
+		return errors.Wrap(err, "could not get report approval")
+	}
+	if status != ApprovalPending {
+		return fmt.Errorf("the report of entry %v was %v already", featureID, status)
+	}
+
+	status = ApprovalRejected
+	if approve {
+		status = ApprovalApproved
+	}
+
+	query := "UPDATE report_approvals SET status=?, decided=?, text=CASE WHEN ?='' THEN text ELSE ? END WHERE entry_id=? AND channel=?"
+	if _, err := tx.ExecContext(ctx, query, status, time.Now(), text, text, featureID, channel); err != nil {
+		return errors.Wrap(err, "failed to decide report approval")
+	}
+
+	if !approve {
+		query := "INSERT OR REPLACE INTO reports (entry_id, channel, reported_at, status) VALUES(?, ?, ?, ?)"
+		if _, err := tx.ExecContext(ctx, query, featureID, channel, time.Now(), ReportStatusSkipped); err != nil {
+			return errors.Wrapf(err, "Failed to set feature to skipped on %v", channel)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "Failed to commit transaction")
+	}
+
+	return nil
+}
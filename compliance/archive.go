@@ -0,0 +1,210 @@
+package compliance
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ArchiveResult counts the rows Archive moved out of the database
+type ArchiveResult struct {
+	Features     int64
+	Cells        int64
+	Fingerprints int64
+}
+
+// the archive keeps the id of every feature, which the feature_id of its compiler support refers to
+var archiveSchema = []string{
+	`CREATE TABLE archive.features (
+		id INTEGER PRIMARY KEY,
+		language TEXT NOT NULL,
+		name TEXT,
+		timestamp DATETIME,
+		cpp_version INT NOT NULL,
+		paper_name TEXT,
+		paper_link TEXT,
+		reported_broken BOOLEAN,
+		slug TEXT NOT NULL DEFAULT '',
+		removed BOOLEAN NOT NULL DEFAULT 0
+		)`,
+	`CREATE TABLE archive.reports (
+		entry_id INTEGER NOT NULL,
+		channel TEXT NOT NULL,
+		reported_at DATETIME NOT NULL,
+		status TEXT NOT NULL
+		)`,
+	`CREATE TABLE archive.compiler_support (
+		feature_id INTEGER NOT NULL,
+		compiler TEXT NOT NULL,
+		kind TEXT NOT NULL,
+		support INT NOT NULL,
+		display_text TEXT,
+		extra_text TEXT,
+		timestamp DATETIME
+		)`,
+	`CREATE TABLE archive.fingerprints (
+		source TEXT NOT NULL,
+		timestamp DATETIME NOT NULL,
+		data TEXT NOT NULL
+		)`,
+}
+
+// DatabaseSize gives the bytes used by stored data. pages freed by deletes are not counted, as new rows reuse them
+func (s *SqliteService) DatabaseSize(ctx context.Context) (int64, error) {
+	var pageCount, freePages, pageSize int64
+
+	if err := s.db.GetContext(ctx, &pageCount, "PRAGMA page_count"); err != nil {
+		return 0, errors.Wrap(err, "could not get page count")
+	}
+	if err := s.db.GetContext(ctx, &freePages, "PRAGMA freelist_count"); err != nil {
+		return 0, errors.Wrap(err, "could not get free page count")
+	}
+	if err := s.db.GetContext(ctx, &pageSize, "PRAGMA page_size"); err != nil {
+		return 0, errors.Wrap(err, "could not get page size")
+	}
+
+	return (pageCount - freePages) * pageSize, nil
+}
+
+// Archive moves the entries from before the given time into a new database file at path, along with the fingerprints
+// of that time. the latest entry of every feature stays, as do all entries of features with unreported changes. support
+// cells of archived entries that later entries still build on are kept with their original timestamp.
+// the database is not vacuumed afterwards, as that may renumber the feature rowids compiler support refers to. the freed
+// pages are reused by new entries instead
+func (s *SqliteService) Archive(ctx context.Context, path string, before time.Time) (ArchiveResult, error) {
+	result := ArchiveResult{}
+
+	//attaching can't happen inside a transaction, so everything runs on one connection
+	conn, err := s.db.DB.Conn(ctx)
+	if err != nil {
+		return result, errors.Wrap(err, "could not get connection")
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "ATTACH DATABASE ? AS archive", path); err != nil {
+		return result, errors.Wrap(err, "could not attach archive")
+	}
+	defer conn.ExecContext(context.Background(), "DETACH DATABASE archive")
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return result, errors.Wrap(err, "Failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	for _, statement := range archiveSchema {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return result, errors.Wrap(err, "could not create archive tables")
+		}
+	}
+
+	selectQuery := `CREATE TEMP TABLE archived_features AS
+		SELECT id, language, name FROM features f
+		WHERE timestamp<?
+		AND timestamp<(SELECT MAX(timestamp) FROM features WHERE language=f.language AND name=f.name)
+		AND NOT EXISTS (
+			SELECT 1 FROM features u
+			WHERE u.language=f.language AND u.name=f.name AND NOT COALESCE(u.reported_broken, 0)
+			AND NOT EXISTS (SELECT 1 FROM reports WHERE entry_id=u.id AND channel=?))`
+
+	if _, err := tx.ExecContext(ctx, selectQuery, before, ChannelTwitter); err != nil {
+		return result, errors.Wrap(err, "could not select entries to archive")
+	}
+
+	copyQueries := []struct {
+		query string
+		args  []interface{}
+	}{
+		{`INSERT INTO archive.features
+			SELECT id, language, name, timestamp, cpp_version, paper_name, paper_link, reported_broken, slug, removed
+			FROM features WHERE id IN (SELECT id FROM archived_features)`, nil},
+		{`INSERT INTO archive.reports
+			SELECT entry_id, channel, reported_at, status
+			FROM reports WHERE entry_id IN (SELECT id FROM archived_features)`, nil},
+		{`INSERT INTO archive.compiler_support
+			SELECT feature_id, compiler, kind, support, display_text, extra_text, timestamp
+			FROM compiler_support WHERE feature_id IN (SELECT id FROM archived_features)
+			ORDER BY timestamp, rowid`, nil},
+		{`INSERT INTO archive.fingerprints
+			SELECT source, timestamp, data FROM fingerprints fp
+			WHERE timestamp<? AND timestamp<(SELECT MAX(timestamp) FROM fingerprints WHERE source=fp.source)`, []interface{}{before}},
+	}
+
+	for _, statement := range copyQueries {
+		if _, err := tx.ExecContext(ctx, statement.query, statement.args...); err != nil {
+			return result, errors.Wrap(err, "could not copy to archive")
+		}
+	}
+
+	//unless FullHistory is set, the oldest kept entry may rely on cells of archived ones. the latest archived cell of every
+	//compiler the kept entry has no cell of its own for moves over to it
+	keepQuery := `UPDATE compiler_support SET feature_id=(
+			SELECT k.id FROM features k
+			WHERE (k.language, k.name)=(SELECT language, name FROM archived_features WHERE id=compiler_support.feature_id)
+			AND k.id NOT IN (SELECT id FROM archived_features)
+			ORDER BY k.timestamp
+			LIMIT 1)
+		WHERE feature_id IN (SELECT id FROM archived_features)
+		AND rowid=(
+			SELECT cs.rowid FROM compiler_support cs
+			JOIN archived_features a ON a.id=cs.feature_id
+			WHERE (a.language, a.name)=(SELECT language, name FROM archived_features WHERE id=compiler_support.feature_id)
+			AND cs.compiler=compiler_support.compiler
+			ORDER BY cs.timestamp DESC, cs.rowid DESC
+			LIMIT 1)
+		AND NOT EXISTS (
+			SELECT 1 FROM compiler_support own
+			WHERE own.compiler=compiler_support.compiler
+			AND own.feature_id=(
+				SELECT k.id FROM features k
+				WHERE (k.language, k.name)=(SELECT language, name FROM archived_features WHERE id=compiler_support.feature_id)
+				AND k.id NOT IN (SELECT id FROM archived_features)
+				ORDER BY k.timestamp
+				LIMIT 1))`
+
+	if _, err := tx.ExecContext(ctx, keepQuery); err != nil {
+		return result, errors.Wrap(err, "could not keep support cells still in use")
+	}
+
+	deleteCells, err := tx.ExecContext(ctx, "DELETE FROM compiler_support WHERE feature_id IN (SELECT id FROM archived_features)")
+	if err != nil {
+		return result, errors.Wrap(err, "could not delete archived support cells")
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM reports WHERE entry_id IN (SELECT id FROM archived_features)"); err != nil {
+		return result, errors.Wrap(err, "could not delete reports of archived entries")
+	}
+	deleteFeatures, err := tx.ExecContext(ctx, "DELETE FROM features WHERE id IN (SELECT id FROM archived_features)")
+	if err != nil {
+		return result, errors.Wrap(err, "could not delete archived entries")
+	}
+	deleteFingerprints, err := tx.ExecContext(ctx, `DELETE FROM fingerprints
+		WHERE timestamp<? AND timestamp<(SELECT MAX(timestamp) FROM fingerprints fp WHERE fp.source=fingerprints.source)`, before)
+	if err != nil {
+		return result, errors.Wrap(err, "could not delete archived fingerprints")
+	}
+
+	//on a rollback the temporary table goes away with the rest
+	if _, err := tx.ExecContext(ctx, "DROP TABLE temp.archived_features"); err != nil {
+		return result, errors.Wrap(err, "could not drop archived entry list")
+	}
+
+	result.Cells = rowsAffected(deleteCells)
+	result.Features = rowsAffected(deleteFeatures)
+	result.Fingerprints = rowsAffected(deleteFingerprints)
+
+	if err = tx.Commit(); err != nil {
+		return result, errors.Wrap(err, "Failed to commit transaction")
+	}
+
+	return result, nil
+}
+
+func rowsAffected(result sql.Result) int64 {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0
+	}
+	return affected
+}
@@ -0,0 +1,99 @@
+package compliance
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ReportAttempt is the failed attempts of reporting an entry to a channel. after too many it becomes a dead letter
+// that is no longer retried until it is requeued
+type ReportAttempt struct {
+	FeatureID   int64     `db:"feature_id"`
+	Channel     string    `db:"channel"`
+	Attempts    int       `db:"attempts"`
+	LastError   string    `db:"last_error"`
+	LastAttempt time.Time `db:"last_attempt"`
+	NextAttempt time.Time `db:"next_attempt"`
+	DeadLetter  bool      `db:"dead_letter"`
+
+	//how much of a thread the failed attempts posted, so the next one resumes it instead of starting over
+	ThreadPosted  int   `db:"thread_posted"`
+	ThreadFirstID int64 `db:"thread_first_id"`
+	ThreadLastID  int64 `db:"thread_last_id"`
+}
+
+// Failed counts a failed attempt, backing off exponentially from backoff up to maxBackoff until there were
+// maxAttempts of them, when the attempt becomes a dead letter
+func (a *ReportAttempt) Failed(err error, now time.Time, maxAttempts int, backoff time.Duration, maxBackoff time.Duration) {
+	a.Attempts++
+	a.LastError = err.Error()
+	a.LastAttempt = now
+
+	for i := 1; i < a.Attempts && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	a.NextAttempt = now.Add(backoff)
+
+	a.DeadLetter = a.Attempts >= maxAttempts
+}
+
+// Due tells if the entry should be reported again
+func (a *ReportAttempt) Due(now time.Time) bool {
+	return !a.DeadLetter && !now.Before(a.NextAttempt)
+}
+
+// GetReportAttempt gives the failed attempts of reporting the entry to the channel, nil if there were none
+func (s *SqliteService) GetReportAttempt(ctx context.Context, featureID int64, channel string) (*ReportAttempt, error) {
+	result := &ReportAttempt{}
+
+	query := "SELECT feature_id, channel, attempts, last_error, last_attempt, next_attempt, dead_letter, thread_posted, thread_first_id, thread_last_id FROM report_attempts WHERE feature_id=? AND channel=?"
+	if err := s.db.GetContext(ctx, result, query, featureID, channel); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "could not get report attempt")
+	}
+
+	return result, nil
+}
+
+func (s *SqliteService) StoreReportAttempt(ctx context.Context, attempt *ReportAttempt) error {
+	query := `INSERT OR REPLACE INTO report_attempts (feature_id, channel, attempts, last_error, last_attempt, next_attempt, dead_letter,
+		 thread_posted, thread_first_id, thread_last_id)
+		VALUES(:feature_id, :channel, :attempts, :last_error, :last_attempt, :next_attempt, :dead_letter,
+		 :thread_posted, :thread_first_id, :thread_last_id)`
+
+	if _, err := s.db.NamedExecContext(ctx, query, attempt); err != nil {
+		return errors.Wrap(err, "failed to store report attempt")
+	}
+
+	return nil
+}
+
+// ClearReportAttempt forgets the failed attempts of reporting the entry to the channel, once it was reported or to
+// requeue a dead letter
+func (s *SqliteService) ClearReportAttempt(ctx context.Context, featureID int64, channel string) error {
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM report_attempts WHERE feature_id=? AND channel=?", featureID, channel); err != nil {
+		return errors.Wrap(err, "failed to clear report attempt")
+	}
+
+	return nil
+}
+
+// GetDeadLetters gives the reports that failed too often to be retried, oldest first
+func (s *SqliteService) GetDeadLetters(ctx context.Context) ([]ReportAttempt, error) {
+	var result []ReportAttempt
+
+	query := "SELECT feature_id, channel, attempts, last_error, last_attempt, next_attempt, dead_letter, thread_posted, thread_first_id, thread_last_id FROM report_attempts WHERE dead_letter=1 ORDER BY last_attempt"
+	if err := s.db.SelectContext(ctx, &result, query); err != nil {
+		return nil, errors.Wrap(err, "could not get dead letters")
+	}
+
+	return result, nil
+}
@@ -0,0 +1,53 @@
+package compliance
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestReportAttemptFailed(t *testing.T) {
+	attempt := ReportAttempt{FeatureID: 1, Channel: ChannelTwitter}
+	failedAt := time.Date(2019, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+	for i, test := range []struct {
+		backoff    time.Duration
+		deadLetter bool
+	}{
+		{time.Minute, false},
+		{2 * time.Minute, false},
+		{4 * time.Minute, false},
+		{8 * time.Minute, false},
+		{10 * time.Minute, true},
+	} {
+		attempt.Failed(errors.New("over capacity"), failedAt, 5, time.Minute, 10*time.Minute)
+
+		if attempt.Attempts != i+1 || attempt.LastError != "over capacity" {
+			t.Errorf("failure %v: got %v attempts with error '%v'", i+1, attempt.Attempts, attempt.LastError)
+		}
+		if backoff := attempt.NextAttempt.Sub(failedAt); backoff != test.backoff {
+			t.Errorf("failure %v: got backoff %v, want %v", i+1, backoff, test.backoff)
+		}
+		if attempt.DeadLetter != test.deadLetter {
+			t.Errorf("failure %v: got dead letter %v, want %v", i+1, attempt.DeadLetter, test.deadLetter)
+		}
+	}
+}
+
+func TestReportAttemptDue(t *testing.T) {
+	failedAt := time.Date(2019, time.June, 1, 12, 0, 0, 0, time.UTC)
+	attempt := ReportAttempt{}
+	attempt.Failed(errors.New("over capacity"), failedAt, 2, time.Minute, 10*time.Minute)
+
+	if attempt.Due(failedAt.Add(time.Second)) {
+		t.Errorf("attempt is due before its backoff")
+	}
+	if !attempt.Due(failedAt.Add(time.Minute)) {
+		t.Errorf("attempt isn't due after its backoff")
+	}
+
+	attempt.Failed(errors.New("over capacity"), failedAt, 2, time.Minute, 10*time.Minute)
+	if attempt.Due(failedAt.Add(time.Hour)) {
+		t.Errorf("dead letter is due")
+	}
+}
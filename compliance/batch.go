@@ -0,0 +1,169 @@
+package compliance
+
+import (
+	"cppimpbot/limits"
+	"fmt"
+	"strings"
+)
+
+// batchGroup is a kind of change a batch of changes made to several features at once
+type batchGroup struct {
+	what     string //what happened to the features, like "gained GCC support in C++23"
+	features []string
+}
+
+func (g *batchGroup) line(names int) string {
+	subject := "1 feature"
+	if len(g.features) != 1 {
+		subject = fmt.Sprintf("%v features", len(g.features))
+	}
+
+	text := subject + " " + g.what
+	if names == 0 {
+		return text
+	}
+
+	if names >= len(g.features) {
+		return text + ": " + strings.Join(g.features, ", ")
+	}
+
+	return fmt.Sprintf("%v: %v and %v more", text, strings.Join(g.features[:names], ", "), len(g.features)-names)
+}
+
+// joinNames lists the names like "GCC, Clang and MSVC"
+func joinNames(names []string) string {
+	if len(names) < 2 {
+		return strings.Join(names, "")
+	}
+
+	return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+}
+
+// batchGroups sorts the changes into what happened to their features, in the order they first happened
+func batchGroups(options ReportOptions, changes []Change) (groups []*batchGroup) {
+	byWhat := map[string]*batchGroup{}
+	add := func(what string, feature *Feature) {
+		group, ok := byWhat[what]
+		if !ok {
+			group = &batchGroup{what: what}
+			byWhat[what] = group
+			groups = append(groups, group)
+		}
+
+		for _, name := range group.features {
+			if name == feature.Name {
+				return
+			}
+		}
+		group.features = append(group.features, feature.Name)
+	}
+
+	for _, change := range changes {
+		if ChangeReportText(options, change) == "" {
+			continue
+		}
+
+		feature := change.Feature
+		standard := feature.Standard()
+
+		switch change.Kind {
+		case ChangeRemoved:
+			add("no longer listed in "+standard, feature)
+		case ChangeNewListing:
+			add("newly listed in "+standard, feature)
+		case ChangeRename:
+			add("renamed in "+standard, feature)
+		case ChangePaper:
+			add("with a new paper in "+standard, feature)
+		case ChangeText:
+			add("with new support notes in "+standard, feature)
+		case ChangeSupport:
+			var gained, partial, lost, changed []string
+			for _, delta := range change.Deltas {
+				name := CompilerDisplayName(delta.Compiler)
+				switch delta.Transition() {
+				case TransitionFull, TransitionCompleted:
+					gained = append(gained, name)
+				case TransitionPartial:
+					partial = append(partial, name)
+				case TransitionRegressed:
+					lost = append(lost, name)
+				default:
+					changed = append(changed, name)
+				}
+			}
+
+			if len(gained) > 0 {
+				add(fmt.Sprintf("gained %v support in %v", joinNames(gained), standard), feature)
+			}
+			if len(partial) > 0 {
+				add(fmt.Sprintf("gained partial %v support in %v", joinNames(partial), standard), feature)
+			}
+			if len(lost) > 0 {
+				add(fmt.Sprintf("lost %v support in %v", joinNames(lost), standard), feature)
+			}
+			if len(changed) > 0 {
+				add(fmt.Sprintf("with changed %v support in %v", joinNames(changed), standard), feature)
+			}
+		}
+	}
+
+	return groups
+}
+
+// batchSummary sums the groups up in a single tweet, listing as many feature names per group as fit
+func batchSummary(groups []*batchGroup) string {
+	header := "[Update Summary] "
+
+	render := func(names int) string {
+		if len(groups) == 1 {
+			return header + groups[0].line(names) + "."
+		}
+
+		lines := []string{header + "cppreference changed:"}
+		for _, group := range groups {
+			lines = append(lines, "- "+group.line(names))
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	longest := 0
+	for _, group := range groups {
+		if len(group.features) > longest {
+			longest = len(group.features)
+		}
+	}
+
+	for names := longest; names > 0; names-- {
+		if text := render(names); limits.Twitter.Fits(text) {
+			return text
+		}
+	}
+
+	return limits.Twitter.Trim(render(0))
+}
+
+// BatchToTwitterThread renders the changes of a scrape as a single summary tweet grouping them by what happened, like
+// "5 features gained GCC support in C++23", followed by replies with a line per feature if details are wanted. the
+// thread is at most maxTweets tweets long. changes not worth reporting are left out, and no tweets are given if none
+// are left
+func BatchToTwitterThread(options ReportOptions, changes []Change, details bool, maxTweets int) []string {
+	groups := batchGroups(options, changes)
+	if len(groups) == 0 {
+		return nil
+	}
+
+	thread := []string{batchSummary(groups)}
+	if !details || maxTweets < 2 {
+		return thread
+	}
+
+	var lines []string
+	for _, change := range changes {
+		if ChangeReportText(options, change) != "" {
+			lines = append(lines, catchUpLine(options, change))
+		}
+	}
+
+	return append(thread, fitLines("Details:", lines, maxTweets-1)...)
+}
@@ -0,0 +1,36 @@
+package compliance
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBatchToTwitterThread(t *testing.T) {
+	renamed := sampleFeature(testSupport(CompilerGcc, 1, "9*", "still some bugs"))
+	renamed.Name = "Initializer list constructors in CTAD"
+	changes := append(partChanges(t, 12), sampleChange(t, nil, renamed))
+
+	summary := "[Update Summary] cppreference changed:\n" +
+		"- 12 features gained GCC, MSVC and Apple Clang support in C++20: Initializer list constructors in class template argument deduction, part 1 and 11 more\n" +
+		"- 1 feature newly listed in C++20: Initializer list constructors in CTAD"
+	if thread := BatchToTwitterThread(ReportOptions{}, changes, false, 4); !reflect.DeepEqual(thread, []string{summary}) {
+		t.Errorf("got thread without details %q", thread)
+	}
+
+	thread := BatchToTwitterThread(ReportOptions{}, changes, true, 4)
+	if len(thread) != 4 || thread[0] != summary {
+		t.Fatalf("got thread with details %q", thread)
+	}
+	if !strings.HasPrefix(thread[1], "Details:\n- C++20 \"Initializer list constructors in class template argument deduction, part 1\"") || !strings.HasSuffix(thread[1], "(1/3)") {
+		t.Errorf("first detail tweet is\n%v", thread[1])
+	}
+	if !strings.HasSuffix(thread[3], "\n...and 10 more. (3/3)") {
+		t.Errorf("last detail tweet is\n%v", thread[3])
+	}
+
+	single := BatchToTwitterThread(ReportOptions{}, changes[:1], false, 4)
+	if !reflect.DeepEqual(single, []string{"[Update Summary] 1 feature gained GCC, MSVC and Apple Clang support in C++20: Initializer list constructors in class template argument deduction, part 1."}) {
+		t.Errorf("got thread of a single change %q", single)
+	}
+}
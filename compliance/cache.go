@@ -0,0 +1,130 @@
+package compliance
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type cacheEntry struct {
+	value   interface{}
+	expires time.Time
+}
+
+// CachingService keeps the results of the reads the API and dashboard do on every request for a while, so they don't hit
+// the backend each time. every write drops the whole cache since a new entry changes most of them
+type CachingService struct {
+	Service
+	ttl     time.Duration
+	entries map[string]cacheEntry
+	mutex   sync.Mutex
+}
+
+func NewCachingService(next Service, ttl time.Duration) *CachingService {
+	return &CachingService{
+		Service: next,
+		ttl:     ttl,
+		entries: map[string]cacheEntry{},
+	}
+}
+
+func (s *CachingService) cached(key string, load func() (interface{}, error)) (interface{}, error) {
+	s.mutex.Lock()
+	entry, ok := s.entries[key]
+	s.mutex.Unlock()
+
+	if ok && time.Now().Before(entry.expires) {
+		return entry.value, nil
+	}
+
+	value, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	s.entries[key] = cacheEntry{value: value, expires: time.Now().Add(s.ttl)}
+	s.mutex.Unlock()
+
+	return value, nil
+}
+
+func (s *CachingService) invalidate() {
+	s.mutex.Lock()
+	s.entries = map[string]cacheEntry{}
+	s.mutex.Unlock()
+}
+
+// cachedFeatures gives a copy of the cached list so callers can't modify the cache
+func (s *CachingService) cachedFeatures(key string, load func() ([]Feature, error)) ([]Feature, error) {
+	value, err := s.cached(key, func() (interface{}, error) {
+		return load()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	features := value.([]Feature)
+	return append([]Feature(nil), features...), nil
+}
+
+func (s *CachingService) GetLatestEntries(ctx context.Context) ([]Feature, error) {
+	return s.cachedFeatures("latest", func() ([]Feature, error) {
+		return s.Service.GetLatestEntries(ctx)
+	})
+}
+
+func (s *CachingService) GetFeatureHistory(ctx context.Context, language string, name string) ([]Feature, error) {
+	return s.cachedFeatures("history:"+language+":"+name, func() ([]Feature, error) {
+		return s.Service.GetFeatureHistory(ctx, language, name)
+	})
+}
+
+func (s *CachingService) GetFeatureHistoryBySlug(ctx context.Context, slug string) ([]Feature, error) {
+	return s.cachedFeatures("slug:"+slug, func() ([]Feature, error) {
+		return s.Service.GetFeatureHistoryBySlug(ctx, slug)
+	})
+}
+
+func (s *CachingService) GetSupportCounts(ctx context.Context) ([]SupportCount, error) {
+	value, err := s.cached("counts", func() (interface{}, error) {
+		return s.Service.GetSupportCounts(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	counts := value.([]SupportCount)
+	return append([]SupportCount(nil), counts...), nil
+}
+
+func (s *CachingService) GetLastUpdate(ctx context.Context) (time.Time, error) {
+	value, err := s.cached("lastUpdate", func() (interface{}, error) {
+		return s.Service.GetLastUpdate(ctx)
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return value.(time.Time), nil
+}
+
+func (s *CachingService) CreateEntry(ctx context.Context, feature *Feature) error {
+	defer s.invalidate()
+	return s.Service.CreateEntry(ctx, feature)
+}
+
+func (s *CachingService) SetReported(ctx context.Context, id int64, channel string) error {
+	defer s.invalidate()
+	return s.Service.SetReported(ctx, id, channel)
+}
+
+func (s *CachingService) SetReportSkipped(ctx context.Context, id int64, channel string) error {
+	defer s.invalidate()
+	return s.Service.SetReportSkipped(ctx, id, channel)
+}
+
+func (s *CachingService) SetErrorReported(ctx context.Context, id int64) error {
+	defer s.invalidate()
+	return s.Service.SetErrorReported(ctx, id)
+}
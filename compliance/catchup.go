@@ -0,0 +1,68 @@
+package compliance
+
+import (
+	"cppimpbot/limits"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// catchUpLine sums up a change in a line of the catch-up digest
+func catchUpLine(options ReportOptions, change Change) string {
+	feature := change.Feature
+
+	switch change.Kind {
+	case ChangeRemoved:
+		return fmt.Sprintf("- %v \"%v\" is no longer listed", feature.Standard(), feature.Name)
+	case ChangeNewListing:
+		return fmt.Sprintf("- %v \"%v\" was listed", feature.Standard(), feature.Name)
+	case ChangeRename:
+		return fmt.Sprintf("- %v \"%v\" is now listed as \"%v\"", feature.Standard(), change.Previous.Name, feature.Name)
+	case ChangePaper:
+		return fmt.Sprintf("- %v \"%v\" now refers to %v instead of %v", feature.Standard(), feature.Name, PaperOf(feature), PaperOf(change.Previous))
+	}
+
+	var supports []string
+	for _, delta := range change.Deltas {
+		supports = append(supports, supportListingLine(options, delta.Next))
+	}
+
+	return fmt.Sprintf("- %v \"%v\": %v", feature.Standard(), feature.Name, strings.Join(supports, ", "))
+}
+
+// CatchUpToTwitterThread renders the changes missed while the bot was down since the given time as a single thread of
+// at most maxTweets tweets. changes that don't fit are only counted. changes not worth reporting are left out, and
+// no tweets are given if none are left
+func CatchUpToTwitterThread(options ReportOptions, changes []Change, since time.Time, maxTweets int) []string {
+	var lines []string
+	for _, change := range changes {
+		if ChangeReportText(options, change) != "" {
+			lines = append(lines, catchUpLine(options, change))
+		}
+	}
+
+	if len(lines) == 0 {
+		return nil
+	}
+
+	header := fmt.Sprintf("[While I was away] Since %v, cppreference saw %v changes:", since.Format("January 2"), len(lines))
+
+	return fitLines(header, lines, maxTweets)
+}
+
+// fitLines renders the header and as many of the lines as fit in a thread of at most maxTweets tweets, counting the
+// ones left out
+func fitLines(header string, lines []string, maxTweets int) []string {
+	for shown := len(lines); shown > 0; shown-- {
+		text := header + "\n" + strings.Join(lines[:shown], "\n")
+		if shown < len(lines) {
+			text += fmt.Sprintf("\n...and %v more.", len(lines)-shown)
+		}
+
+		if thread := limits.Twitter.Split(text); len(thread) <= maxTweets {
+			return thread
+		}
+	}
+
+	return limits.Twitter.Split(fmt.Sprintf("%v\n...too many to list.", header))
+}
@@ -0,0 +1,56 @@
+package compliance
+
+import (
+	"cppimpbot/limits"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// partChanges gives count changes of GCC, MSVC and Apple Clang gaining support of the sample feature, each of a feature
+// of its own
+func partChanges(t *testing.T, count int) []Change {
+	t.Helper()
+
+	var changes []Change
+	for i := 0; i < count; i++ {
+		change := sampleChange(t, sampleFeature(), sampleSupported())
+		change.Feature.Name = fmt.Sprintf("%v, part %v", change.Feature.Name, i+1)
+		changes = append(changes, change)
+	}
+
+	return changes
+}
+
+func TestCatchUpToTwitterThread(t *testing.T) {
+	since := time.Date(2019, time.June, 1, 12, 0, 0, 0, time.UTC)
+	changes := partChanges(t, 12)
+
+	thread := CatchUpToTwitterThread(ReportOptions{}, changes, since, 4)
+	if len(thread) != 4 {
+		t.Fatalf("got %v tweets, want 4", len(thread))
+	}
+	if !strings.HasPrefix(thread[0], "[While I was away] Since June 1, cppreference saw 12 changes:\n"+
+		`- C++20 "Initializer list constructors in class template argument deduction, part 1": GCC - [yes] 9*(still some bugs), MSVC - [yes] 19.20, Apple Clang - [yes] 10.0.1`) {
+		t.Errorf("first tweet is\n%v", thread[0])
+	}
+	if !strings.HasSuffix(thread[3], "part 4\": GCC - [yes] 9*(still some bugs), MSVC - [yes] 19.20, Apple Clang - [yes] 10.0.1\n...and 8 more. (4/4)") {
+		t.Errorf("last tweet is\n%v", thread[3])
+	}
+	for i, tweet := range thread {
+		if !limits.Twitter.Fits(tweet) {
+			t.Errorf("tweet %v is %v long", i+1, limits.Twitter.Count(tweet))
+		}
+	}
+
+	if thread := CatchUpToTwitterThread(ReportOptions{}, changes, since, 1); len(thread) != 1 || !strings.HasSuffix(thread[0], "\n...and 11 more.") {
+		t.Errorf("thread of a single tweet is %q", thread)
+	}
+
+	//changes not worth reporting are left out
+	unreported := changes[0].OnlyCompilers([]string{CompilerClang})
+	if thread := CatchUpToTwitterThread(ReportOptions{}, []Change{unreported}, since, 4); len(thread) != 0 {
+		t.Errorf("thread of nothing to report is %q", thread)
+	}
+}
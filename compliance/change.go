@@ -0,0 +1,121 @@
+package compliance
+
+import (
+	"github.com/pkg/errors"
+)
+
+// kinds of changes between two entries of a feature, in the order they take precedence
+const (
+	ChangeRemoved    = "removed"
+	ChangeNewListing = "new_listing"
+	ChangeRename     = "rename"
+	ChangePaper      = "paper"
+	ChangeSupport    = "support"
+	ChangeText       = "text"
+)
+
+// CompilerDelta is the support of one compiler before and after a change. Previous is empty for new listings
+type CompilerDelta struct {
+	Compiler string
+	Previous CompilerSupport
+	Next     CompilerSupport
+}
+
+// Change is what happened between two entries of a feature, for renderers of every output channel to report on
+type Change struct {
+	Kind     string
+	Previous *Feature
+	Feature  *Feature
+	Deltas   []CompilerDelta
+}
+
+func compilerDeltas(previous *Feature, next *Feature, compilers []string) (result []CompilerDelta) {
+	for _, compiler := range compilers {
+		delta := CompilerDelta{
+			Compiler: compiler,
+			Next:     supportOrEmpty(next, compiler),
+		}
+		if previous != nil {
+			delta.Previous = supportOrEmpty(previous, compiler)
+		}
+
+		result = append(result, delta)
+	}
+
+	return
+}
+
+// DiffFeatures works out what changed from the previous entry of a feature to the next one. previous is nil for newly listed features
+func DiffFeatures(previous *Feature, next *Feature) (Change, error) {
+	change := Change{
+		Previous: previous,
+		Feature:  next,
+	}
+
+	if isReportTypeRemoved(previous, next) {
+		change.Kind = ChangeRemoved
+	} else if isReportTypeNewFeatureAdded(previous, next) {
+		change.Kind = ChangeNewListing
+		change.Deltas = compilerDeltas(nil, next, newListingCompilers(next))
+	} else if isReportTypeRenamed(previous, next) {
+		change.Kind = ChangeRename
+		change.Deltas = compilerDeltas(previous, next, supportLevelChangedCompilers(previous, next))
+	} else if isReportTypePaperModified(previous, next) {
+		change.Kind = ChangePaper
+		change.Deltas = compilerDeltas(previous, next, supportLevelChangedCompilers(previous, next))
+	} else if isReportTypeSupportLevelChanged(previous, next) {
+		change.Kind = ChangeSupport
+		change.Deltas = compilerDeltas(previous, next, supportLevelChangedCompilers(previous, next))
+	} else if isReportTypeTextChanged(previous, next) {
+		change.Kind = ChangeText
+		change.Deltas = compilerDeltas(previous, next, textChangedCompilers(previous, next))
+	} else {
+		return change, errors.Errorf("cannot handle")
+	}
+
+	return change, nil
+}
+
+// OnlyCompilers leaves out the deltas of compilers not in the list. support and text changes left without deltas have
+// no kind, as nothing about them is worth reporting. changes to the feature itself, like removals, are kept whole
+func (c Change) OnlyCompilers(compilers []string) Change {
+	if len(c.Deltas) == 0 {
+		return c
+	}
+
+	enabled := map[string]bool{}
+	for _, compiler := range compilers {
+		enabled[compiler] = true
+	}
+
+	var deltas []CompilerDelta
+	for _, delta := range c.Deltas {
+		if enabled[delta.Compiler] {
+			deltas = append(deltas, delta)
+		}
+	}
+	c.Deltas = deltas
+
+	if len(deltas) == 0 && (c.Kind == ChangeSupport || c.Kind == ChangeText) {
+		c.Kind = ""
+	}
+
+	return c
+}
+
+// OnlyLanguages leaves changes of features of languages not in the list without a kind, so they aren't reported at all
+func (c Change) OnlyLanguages(languages []string) Change {
+	language := c.Feature.Language
+	if language == "" {
+		language = LanguageCpp
+	}
+
+	for _, enabled := range languages {
+		if enabled == language {
+			return c
+		}
+	}
+
+	c.Kind = ""
+	return c
+}
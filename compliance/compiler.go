@@ -0,0 +1,191 @@
+package compliance
+
+import (
+	"database/sql"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// keys of the compilers that are always listed in reports
+const (
+	CompilerGcc   = "gcc"
+	CompilerClang = "clang"
+	CompilerMsvc  = "msvc"
+)
+
+var PrimaryCompilers = []string{CompilerGcc, CompilerClang, CompilerMsvc}
+
+// keys of the standard library implementations that are always listed in reports of library features
+const (
+	LibraryLibstdcxx = "libstdcxx"
+	LibraryLibcxx    = "libcxx"
+	LibraryMsvcStl   = "msvc_stl"
+)
+
+var PrimaryLibraries = []string{LibraryLibstdcxx, LibraryLibcxx, LibraryMsvcStl}
+
+// what a support cell is about: a compiler implementing a core language feature or a standard library implementing a library feature
+const (
+	KindCompiler = "compiler"
+	KindLibrary  = "library"
+)
+
+// header texts on cppreference that don't normalise nicely into a key
+var compilerHeaderKeys = map[string]string{
+	"edg eccp":                  "edg",
+	"intel c++":                 "intel",
+	"ibm xl c++":                "ibm_xl",
+	"sun/oracle c++":            "oracle",
+	"embarcadero c++ builder":   "embarcadero",
+	"portland group (pgi)":      "pgi",
+	"nvidia nvcc":               "nvcc",
+	"nvidia hpc c++ (ex pgi)":   "nvhpc",
+	"microsoft visual c++":      "msvc",
+	"gnu compiler collection":   "gcc",
+	"apple clang":               "apple_clang",
+	"apple clang (xcode)":       "apple_clang",
+	"ibm open xl c/c++ for aix": "ibm_openxl",
+}
+
+// header texts of the library tables. they name the compiler a library ships with as often as the library itself
+var libraryHeaderKeys = map[string]string{
+	"gcc libstdc++":       "libstdcxx",
+	"gcc":                 "libstdcxx",
+	"libstdc++":           "libstdcxx",
+	"clang libc++":        "libcxx",
+	"clang":               "libcxx",
+	"libc++":              "libcxx",
+	"msvc stl":            "msvc_stl",
+	"msvc":                "msvc_stl",
+	"apple clang":         "apple_libcxx",
+	"apple clang (xcode)": "apple_libcxx",
+}
+
+var compilerDisplayNames = map[string]string{
+	"gcc":         "GCC",
+	"clang":       "Clang",
+	"msvc":        "MSVC",
+	"apple_clang": "Apple Clang",
+	"edg":         "EDG",
+	"intel":       "Intel",
+	"ibm_xl":      "IBM XL",
+	"ibm_openxl":  "IBM Open XL",
+	"oracle":      "Oracle",
+	"embarcadero": "Embarcadero",
+	"cray":        "Cray",
+	"pgi":         "PGI",
+	"nvcc":        "NVCC",
+	"nvhpc":       "NVIDIA HPC",
+
+	"libstdcxx":    "libstdc++",
+	"libcxx":       "libc++",
+	"msvc_stl":     "MSVC STL",
+	"apple_libcxx": "Apple libc++",
+}
+
+// CompilerSupport is the support of one vendor for a feature. despite the name the vendor is a standard library for library features, see Kind
+type CompilerSupport struct {
+	FeatureId   int64          `db:"feature_id"`
+	Compiler    string         `db:"compiler"`
+	Kind        string         `db:"kind"`
+	Support     int            `db:"support"`
+	DisplayText sql.NullString `db:"display_text"`
+	ExtraText   sql.NullString `db:"extra_text"`
+	Timestamp   time.Time      `db:"timestamp"`
+
+	//the version named by the display text, see SupportVersion. filled in when reading from storage
+	Version string `db:"-"`
+}
+
+func normalizedHeader(header string) string {
+	return strings.ToLower(strings.Join(strings.Fields(header), " "))
+}
+
+// SupportCount is how many features of a language version a compiler currently lists with the given support level
+type SupportCount struct {
+	Language   string `db:"language"`
+	CppVersion int    `db:"cpp_version"`
+	Compiler   string `db:"compiler"`
+	Support    int    `db:"support"`
+	Features   int    `db:"features"`
+}
+
+// version numbers in a support cell, like 19.38 in "19.38*"
+var supportVersionPattern = regexp.MustCompile(`\d+(\.\d+)*`)
+
+// SupportVersion gives the first version the display text of a support cell names, like "19.38" for "19.38*", or "" if there is none
+func SupportVersion(displayText string) string {
+	return supportVersionPattern.FindString(displayText)
+}
+
+// VersionIn tells if a version belongs to a release series, like 14.1 and 14 do to 14
+func VersionIn(version string, series string) bool {
+	return version != "" && (version == series || strings.HasPrefix(version, series+"."))
+}
+
+// CompilerKey turns a compiler column header from cppreference into the key it is stored under
+func CompilerKey(header string) string {
+	header = normalizedHeader(header)
+
+	if key, ok := compilerHeaderKeys[header]; ok {
+		return key
+	}
+
+	return headerKey(header)
+}
+
+// LibraryKey turns a column header of a library feature table into the key of the library implementation
+func LibraryKey(header string) string {
+	header = normalizedHeader(header)
+
+	if key, ok := libraryHeaderKeys[header]; ok {
+		return key
+	}
+
+	return headerKey(header)
+}
+
+func headerKey(header string) string {
+	key := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, header)
+
+	return strings.Trim(key, "_")
+}
+
+// CompilerDisplayName gives the name used for a compiler key in reports
+func CompilerDisplayName(key string) string {
+	if name, ok := compilerDisplayNames[key]; ok {
+		return name
+	}
+
+	return key
+}
+
+// primaryVendors gives the vendors always listed in reports of features of the given kind
+func primaryVendors(kind string) []string {
+	if kind == KindLibrary {
+		return PrimaryLibraries
+	}
+
+	return PrimaryCompilers
+}
+
+func isPrimaryVendor(kind string, key string) bool {
+	for _, primary := range primaryVendors(kind) {
+		if primary == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sameCompilerSupportText(a *CompilerSupport, b *CompilerSupport) bool {
+	return a.DisplayText == b.DisplayText && a.ExtraText == b.ExtraText
+}
@@ -0,0 +1,275 @@
+package compliance
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// the probes that look for cppreference data that appears wrong, named in the file names of their drafts
+const (
+	ProbeCrossLanguage = "cross-language" //the C and C++ tables disagree on the support of a compiler for the same paper
+	ProbeCellText      = "cell-text"      //the support level of a cell disagrees with its own text
+)
+
+// correctionSuffix ends the file names of drafted corrections in an outbox
+const correctionSuffix = ".wiki"
+
+// Discrepancy is a support cell of cppreference that appears wrong, with what gives it away
+type Discrepancy struct {
+	Probe    string
+	Feature  Feature
+	Compiler string
+	Problem  string   //what appears wrong, as a sentence without the final dot
+	Evidence []string //links backing the problem up
+}
+
+// Key names the discrepancy the same way every time it is found again, like "p1234-gcc-cell-text"
+func (d Discrepancy) Key() string {
+	slug := d.Feature.Slug
+	if slug == "" {
+		slug = BaseSlug(&d.Feature)
+	}
+
+	return slug + "-" + d.Compiler + "-" + d.Probe
+}
+
+// cellTitle is how a support cell reads on the page, with its level, like "[partial] 10 (some parts)"
+func cellTitle(support CompilerSupport) string {
+	title := "[" + SupportLevelName(support.Support) + "]"
+	if text := SupportText(support); text != "" {
+		title += " " + text
+	}
+
+	return title
+}
+
+// FindDiscrepancies runs every probe over the latest entries of all features. pages has the compiler support page of
+// every language, like the ScrapeUrl of LanguageCpp, to link to the tables involved as evidence
+func FindDiscrepancies(features []Feature, pages map[string]string) (result []Discrepancy) {
+	var current []Feature
+	for _, feature := range CurrentFeatures(features) {
+		if !feature.Removed {
+			current = append(current, feature)
+		}
+	}
+
+	evidence := func(features ...*Feature) (links []string) {
+		for _, feature := range features {
+			if page := pages[languageOrCpp(feature.Language)]; page != "" {
+				links = append(links, CppreferenceLink(page, feature))
+			}
+		}
+		if paper := fromNullString(features[0].PaperLink); paper != "" {
+			links = append(links, paper)
+		}
+		return
+	}
+
+	result = append(result, crossLanguageDiscrepancies(current, evidence)...)
+	result = append(result, cellTextDiscrepancies(current, evidence)...)
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Key() < result[j].Key()
+	})
+
+	return
+}
+
+func languageOrCpp(language string) string {
+	if language == "" {
+		return LanguageCpp
+	}
+
+	return language
+}
+
+// crossLanguageDiscrepancies finds compilers listed with a different support level for a paper in the C table than in
+// the C++ one. a compiler can well implement a paper for one language first, but the tables are updated by different
+// people, so it is worth a look. the draft goes to the C++ row, the C row is evidence
+func crossLanguageDiscrepancies(features []Feature, evidence func(features ...*Feature) []string) (result []Discrepancy) {
+	cByPaper := map[string][]*Feature{}
+	for i := range features {
+		if paper := PaperNumber(&features[i]); paper != "" && features[i].Language == LanguageC {
+			cByPaper[paper] = append(cByPaper[paper], &features[i])
+		}
+	}
+
+	for i := range features {
+		feature := &features[i]
+		if languageOrCpp(feature.Language) != LanguageCpp || feature.Kind() != KindCompiler {
+			continue
+		}
+
+		for _, other := range cByPaper[PaperNumber(feature)] {
+			for _, support := range feature.Compilers {
+				otherSupport := other.SupportFor(support.Compiler)
+				if otherSupport == nil || otherSupport.Support == support.Support {
+					continue
+				}
+
+				result = append(result, Discrepancy{
+					Probe:    ProbeCrossLanguage,
+					Feature:  *feature,
+					Compiler: support.Compiler,
+					Problem: fmt.Sprintf("the %v table lists %v for the same paper as %v",
+						other.Standard(), CompilerDisplayName(support.Compiler), cellTitle(*otherSupport)),
+					Evidence: evidence(feature, other),
+				})
+			}
+		}
+	}
+
+	return
+}
+
+// cellTextDiscrepancies finds cells whose support level contradicts their text: unsupported ones naming the version
+// that supports it, and fully supported ones calling the support partial
+func cellTextDiscrepancies(features []Feature, evidence func(features ...*Feature) []string) (result []Discrepancy) {
+	for i := range features {
+		feature := &features[i]
+
+		for _, support := range feature.Compilers {
+			problem := ""
+			switch {
+			case support.Support == SupportNo && SupportVersion(fromNullString(support.DisplayText)) != "":
+				problem = fmt.Sprintf("it is marked as unsupported, but names version %v", SupportVersion(fromNullString(support.DisplayText)))
+			case support.Support == SupportYes && strings.Contains(strings.ToLower(SupportText(support)), "partial"):
+				problem = "it is marked as fully supported, but its text calls the support partial"
+			default:
+				continue
+			}
+
+			result = append(result, Discrepancy{
+				Probe:    ProbeCellText,
+				Feature:  *feature,
+				Compiler: support.Compiler,
+				Problem:  problem,
+				Evidence: evidence(feature),
+			})
+		}
+	}
+
+	return
+}
+
+// CorrectionDraft words the discrepancy as a note for the talk page of the compiler support page, in wiki markup. it is
+// meant to be checked and posted by hand, signed with the four tildes it ends with
+func CorrectionDraft(d Discrepancy) string {
+	var draft strings.Builder
+
+	fmt.Fprintf(&draft, "== %v \"%v\": %v ==\n", d.Feature.Standard(), d.Feature.Name, CompilerDisplayName(d.Compiler))
+
+	cell := "is not listed"
+	if support := d.Feature.SupportFor(d.Compiler); support != nil {
+		cell = "reads <code><nowiki>" + cellTitle(*support) + "</nowiki></code>"
+	}
+	paper := ""
+	if name := fromNullString(d.Feature.PaperName); name != "" {
+		paper = " (" + name + ")"
+	}
+	fmt.Fprintf(&draft, "The %v cell of \"%v\"%v %v, which looks wrong: %v.\n", CompilerDisplayName(d.Compiler), d.Feature.Name, paper, cell, d.Problem)
+
+	if len(d.Evidence) > 0 {
+		draft.WriteString("\nEvidence:\n")
+		for _, link := range d.Evidence {
+			fmt.Fprintf(&draft, "* %v\n", link)
+		}
+	}
+
+	draft.WriteString("\n~~~~\n")
+
+	return draft.String()
+}
+
+// Correction is a drafted correction waiting in an outbox
+type Correction struct {
+	Key     string
+	Drafted time.Time
+	Text    string
+}
+
+// WriteCorrections drafts a correction into dir for every discrepancy that has none yet, and removes the drafts of
+// discrepancies that are gone, as cppreference was corrected or the probes changed. drafts that are still valid are
+// left alone, so they can be edited before being posted. gives how many drafts it wrote and removed
+func WriteCorrections(dir string, discrepancies []Discrepancy) (written int, removed int, err error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, 0, errors.Wrap(err, "could not create the correction outbox")
+	}
+
+	found := map[string]bool{}
+	for _, discrepancy := range discrepancies {
+		key := discrepancy.Key()
+		if found[key] {
+			continue
+		}
+		found[key] = true
+
+		path := filepath.Join(dir, key+correctionSuffix)
+		if _, err := os.Stat(path); err == nil {
+			continue
+		}
+
+		if err := ioutil.WriteFile(path, []byte(CorrectionDraft(discrepancy)), 0644); err != nil {
+			return written, removed, errors.Wrapf(err, "could not draft correction %v", key)
+		}
+		written++
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*"+correctionSuffix))
+	if err != nil {
+		return written, removed, errors.Wrap(err, "could not list drafted corrections")
+	}
+
+	for _, file := range files {
+		if found[strings.TrimSuffix(filepath.Base(file), correctionSuffix)] {
+			continue
+		}
+
+		if err := os.Remove(file); err != nil {
+			return written, removed, errors.Wrapf(err, "could not remove outdated correction %v", file)
+		}
+		removed++
+	}
+
+	return written, removed, nil
+}
+
+// LoadCorrections reads the drafted corrections in dir, newest first. a dir that doesn't exist yet has none
+func LoadCorrections(dir string) ([]Correction, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*"+correctionSuffix))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list drafted corrections")
+	}
+
+	var result []Correction
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read drafted correction")
+		}
+
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read drafted correction")
+		}
+
+		result = append(result, Correction{
+			Key:     strings.TrimSuffix(filepath.Base(file), correctionSuffix),
+			Drafted: info.ModTime(),
+			Text:    string(data),
+		})
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Drafted.After(result[j].Drafted)
+	})
+
+	return result, nil
+}
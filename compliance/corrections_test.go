@@ -0,0 +1,84 @@
+package compliance
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// correctionFeatures gives a C++ feature whose MSVC cell names a version while marked unsupported, and the C feature
+// of the same paper that GCC supports
+func correctionFeatures() []Feature {
+	cpp := sampleFeature(testSupport(CompilerClang, 2, "6", "only supported if flag supplied"), testSupport(CompilerMsvc, 0, "19.20", ""))
+	cpp.Slug = "p0702"
+	c := sampleFeature(testSupport(CompilerGcc, 1, "13", ""), testSupport(CompilerClang, 2, "6", "only supported if flag supplied"))
+	c.Language = LanguageC
+	c.CppVersion = 23
+	c.Slug = "c-p0702"
+
+	return []Feature{*cpp, *c}
+}
+
+var correctionPages = map[string]string{
+	LanguageCpp: "https://en.cppreference.com/w/cpp/compiler_support",
+	LanguageC:   "https://en.cppreference.com/w/c/compiler_support",
+}
+
+func TestFindDiscrepancies(t *testing.T) {
+	problems := map[string]string{}
+	discrepancies := FindDiscrepancies(correctionFeatures(), correctionPages)
+	for _, discrepancy := range discrepancies {
+		problems[discrepancy.Key()] = discrepancy.Problem
+	}
+
+	want := map[string]string{
+		"p0702-gcc-cross-language": "the C23 table lists GCC for the same paper as [yes] 13",
+		"p0702-msvc-cell-text":     "it is marked as unsupported, but names version 19.20",
+	}
+	if !reflect.DeepEqual(problems, want) {
+		t.Fatalf("got problems %v, want %v", problems, want)
+	}
+
+	draft := `== C++20 "Initializer list constructors in class template argument deduction": GCC ==
+The GCC cell of "Initializer list constructors in class template argument deduction" (P0702R1) reads <code><nowiki>[no]</nowiki></code>, which looks wrong: the C23 table lists GCC for the same paper as [yes] 13.
+
+Evidence:
+* https://en.cppreference.com/w/cpp/compiler_support#C.2B.2B20_core_language_features
+* https://en.cppreference.com/w/c/compiler_support#C23_core_language_features
+* https://wg21.link/P0702R1
+
+~~~~
+`
+	for _, discrepancy := range discrepancies {
+		if discrepancy.Probe == ProbeCrossLanguage && CorrectionDraft(discrepancy) != draft {
+			t.Errorf("got draft\n%v\nwant\n%v", CorrectionDraft(discrepancy), draft)
+		}
+	}
+}
+
+func TestWriteCorrections(t *testing.T) {
+	outbox := filepath.Join(t.TempDir(), "corrections")
+	discrepancies := FindDiscrepancies(correctionFeatures(), correctionPages)
+	if len(discrepancies) != 2 {
+		t.Fatalf("got %v discrepancies, want 2", len(discrepancies))
+	}
+
+	if written, removed, err := WriteCorrections(outbox, discrepancies); written != 2 || removed != 0 || err != nil {
+		t.Errorf("first scrape drafted %v and removed %v, %v", written, removed, err)
+	}
+	if written, removed, err := WriteCorrections(outbox, discrepancies); written != 0 || removed != 0 || err != nil {
+		t.Errorf("same scrape again drafted %v and removed %v, %v", written, removed, err)
+	}
+	if written, removed, err := WriteCorrections(outbox, discrepancies[:1]); written != 0 || removed != 1 || err != nil {
+		t.Errorf("scrape after a correction drafted %v and removed %v, %v", written, removed, err)
+	}
+
+	corrections, err := LoadCorrections(outbox)
+	if err != nil || len(corrections) != 1 || corrections[0].Key != discrepancies[0].Key() || corrections[0].Text != CorrectionDraft(discrepancies[0]) {
+		t.Errorf("got corrections %+v, %v", corrections, err)
+	}
+
+	if corrections, err := LoadCorrections(filepath.Join(t.TempDir(), "missing")); len(corrections) != 0 || err != nil {
+		t.Errorf("missing outbox has corrections %+v, %v", corrections, err)
+	}
+}
@@ -0,0 +1,159 @@
+package compliance
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// the probes comparing cppreference with other sources, named after the source. a discrepancy they find may as well be
+// a mistake of the other source, so they are recorded instead of drafted as corrections
+const (
+	ProbeGccStatus   = "gcc-status"   //the C++ status page of GCC lists a different support level for a paper
+	ProbeClangStatus = "clang-status" //the C++ status page of Clang lists a different support level for a paper
+)
+
+// CrossCheck compares the support of compilers another source lists, like the status page of GCC, with that of the
+// latest stored features of the same papers. features are matched by paper number, as the sources name them
+// differently, and compilers only if both list them. description tells what the other source is in the problems, like
+// "the C++ status page of GCC", and page is linked to as evidence
+func CrossCheck(features []Feature, checked []Feature, probe string, description string, page string) (result []Discrepancy) {
+	byPaper := map[string][]*Feature{}
+	for _, feature := range CurrentFeatures(features) {
+		if paper := PaperNumber(&feature); paper != "" && !feature.Removed && feature.Kind() == KindCompiler {
+			key := languageOrCpp(feature.Language) + "/" + paper
+			feature := feature
+			byPaper[key] = append(byPaper[key], &feature)
+		}
+	}
+
+	found := map[string]bool{}
+	for i := range checked {
+		other := &checked[i]
+
+		for _, feature := range byPaper[languageOrCpp(other.Language)+"/"+PaperNumber(other)] {
+			for _, otherSupport := range other.Compilers {
+				support := feature.SupportFor(otherSupport.Compiler)
+				if support == nil || support.Support == otherSupport.Support {
+					continue
+				}
+
+				discrepancy := Discrepancy{
+					Probe:    probe,
+					Feature:  *feature,
+					Compiler: otherSupport.Compiler,
+					Problem: fmt.Sprintf("%v lists %v for the same paper as %v", description,
+						CompilerDisplayName(otherSupport.Compiler), cellTitle(otherSupport)),
+				}
+				if page != "" {
+					discrepancy.Evidence = append(discrepancy.Evidence, page)
+				}
+				if paper := fromNullString(feature.PaperLink); paper != "" {
+					discrepancy.Evidence = append(discrepancy.Evidence, paper)
+				}
+
+				//sources listing a paper in several rows are taken by their first one
+				if !found[discrepancy.Key()] {
+					found[discrepancy.Key()] = true
+					result = append(result, discrepancy)
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Key() < result[j].Key()
+	})
+
+	return
+}
+
+// StoredDiscrepancy is the record of a discrepancy a probe found, open until it finds it no more
+type StoredDiscrepancy struct {
+	Id       int64        `db:"id"`
+	Probe    string       `db:"probe"`
+	Key      string       `db:"key"`
+	Language string       `db:"language"`
+	Slug     string       `db:"slug"`
+	Compiler string       `db:"compiler"`
+	Problem  string       `db:"problem"`
+	Evidence string       `db:"evidence"` //links, one per line
+	Found    time.Time    `db:"found"`
+	Resolved sql.NullTime `db:"resolved"`
+}
+
+// StoreDiscrepancies records what the probe found this time: discrepancies without an open record get one, and open
+// records of discrepancies no longer found are resolved. gives the discrepancies that were newly recorded
+func (s *SqliteService) StoreDiscrepancies(ctx context.Context, probe string, discrepancies []Discrepancy) ([]Discrepancy, error) {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	var open []string
+	if err := tx.SelectContext(ctx, &open, "SELECT key FROM discrepancies WHERE probe=? AND resolved IS NULL", probe); err != nil {
+		return nil, errors.Wrap(err, "could not get open discrepancies")
+	}
+	isOpen := map[string]bool{}
+	for _, key := range open {
+		isOpen[key] = true
+	}
+
+	now := time.Now()
+	found := map[string]bool{}
+	var added []Discrepancy
+
+	for _, discrepancy := range discrepancies {
+		key := discrepancy.Key()
+		found[key] = true
+		if isOpen[key] {
+			continue
+		}
+
+		query := `INSERT INTO discrepancies (probe, key, language, slug, compiler, problem, evidence, found)
+			VALUES(?, ?, ?, ?, ?, ?, ?, ?)`
+		if _, err := tx.ExecContext(ctx, query, probe, key, languageOrCpp(discrepancy.Feature.Language), discrepancy.Feature.Slug,
+			discrepancy.Compiler, discrepancy.Problem, strings.Join(discrepancy.Evidence, "\n"), now); err != nil {
+			return nil, errors.Wrapf(err, "failed to store discrepancy %v", key)
+		}
+
+		isOpen[key] = true
+		added = append(added, discrepancy)
+	}
+
+	for _, key := range open {
+		if found[key] {
+			continue
+		}
+
+		if _, err := tx.ExecContext(ctx, "UPDATE discrepancies SET resolved=? WHERE probe=? AND key=? AND resolved IS NULL", now, probe, key); err != nil {
+			return nil, errors.Wrapf(err, "failed to resolve discrepancy %v", key)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "failed to commit discrepancies")
+	}
+
+	return added, nil
+}
+
+// GetOpenDiscrepancies lists the discrepancies found by the last run of every probe, oldest first
+func (s *SqliteService) GetOpenDiscrepancies(ctx context.Context) ([]StoredDiscrepancy, error) {
+	query := `SELECT id, probe, key, language, slug, compiler, problem, evidence, found, resolved FROM discrepancies
+		WHERE resolved IS NULL
+		ORDER BY found, id`
+
+	var result []StoredDiscrepancy
+	if err := s.db.SelectContext(ctx, &result, query); err != nil {
+		return nil, errors.Wrap(err, "could not get open discrepancies")
+	}
+
+	return result, nil
+}
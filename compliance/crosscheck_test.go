@@ -0,0 +1,44 @@
+package compliance
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+func TestCrossCheck(t *testing.T) {
+	stored := Feature{Name: "Explicit object parameter", CppVersion: 23, Slug: "p0847",
+		PaperName: sql.NullString{String: "P0847R7", Valid: true}, PaperLink: sql.NullString{String: "https://wg21.link/P0847R7", Valid: true}}
+	stored.SetSupport(testSupport(CompilerGcc, 0, "", ""))
+	stored.SetSupport(testSupport(CompilerClang, 1, "18", ""))
+	agreeing := Feature{Name: "Grouped", CppVersion: 23, Slug: "p0004", PaperName: sql.NullString{String: "P0004R1", Valid: true}}
+	agreeing.SetSupport(testSupport(CompilerGcc, 0, "", ""))
+
+	//how the status page of GCC lists the papers, under names of its own
+	deducingThis := Feature{Name: "Deducing this", CppVersion: 23, PaperName: sql.NullString{String: "P0847R7", Valid: true}}
+	deducingThis.SetSupport(testSupport(CompilerGcc, 1, "14", ""))
+	grouped := Feature{Name: "Grouped feature", CppVersion: 23, PaperName: sql.NullString{String: "P0004R1", Valid: true}}
+	grouped.SetSupport(testSupport(CompilerGcc, 0, "No", ""))
+	unstored := Feature{Name: "Grouped feature", CppVersion: 23, PaperName: sql.NullString{String: "P0002R1", Valid: true}}
+	unstored.SetSupport(testSupport(CompilerGcc, 2, "12 (partial)", ""))
+
+	discrepancies := CrossCheck([]Feature{stored, agreeing}, []Feature{deducingThis, grouped, unstored}, ProbeGccStatus, "the C++ status page of GCC", "https://gcc.gnu.org/projects/cxx-status.html")
+	if len(discrepancies) != 1 {
+		t.Fatalf("got %v discrepancies, want 1: %+v", len(discrepancies), discrepancies)
+	}
+
+	discrepancy := discrepancies[0]
+	if discrepancy.Key() != "p0847-gcc-gcc-status" || discrepancy.Problem != "the C++ status page of GCC lists GCC for the same paper as [yes] 14" {
+		t.Errorf("got %v: %v", discrepancy.Key(), discrepancy.Problem)
+	}
+	if evidence := []string{"https://gcc.gnu.org/projects/cxx-status.html", "https://wg21.link/P0847R7"}; !reflect.DeepEqual(discrepancy.Evidence, evidence) {
+		t.Errorf("got evidence %v, want %v", discrepancy.Evidence, evidence)
+	}
+
+	//compilers only one of the sources lists aren't compared
+	clangOnly := Feature{Name: "Deducing this", CppVersion: 23, PaperName: sql.NullString{String: "P0847R7", Valid: true}}
+	clangOnly.SetSupport(testSupport(CompilerMsvc, 1, "19.32", ""))
+	if discrepancies := CrossCheck([]Feature{stored}, []Feature{clangOnly}, ProbeClangStatus, "the C++ status page of Clang", "https://clang.llvm.org/cxx_status.html"); len(discrepancies) != 0 {
+		t.Errorf("got discrepancies of unlisted compilers %+v", discrepancies)
+	}
+}
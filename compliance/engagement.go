@@ -0,0 +1,98 @@
+package compliance
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ReportPost is a posted report, remembered to track how the variant it was phrased with is received
+type ReportPost struct {
+	TweetID   int64     `db:"tweet_id"`
+	FeatureID int64     `db:"feature_id"`
+	Kind      string    `db:"kind"`
+	Variant   string    `db:"variant"`
+	Timestamp time.Time `db:"timestamp"`
+	Likes     int       `db:"likes"`
+	Retweets  int       `db:"retweets"`
+	Link      string    `db:"link"`       //link the report included, if any
+	ShortLink string    `db:"short_link"` //shortened link the report was posted with, if any
+}
+
+// VariantStats is how the posts of a report variant were received on average
+type VariantStats struct {
+	Kind         string
+	Variant      string
+	Posts        int
+	MeanLikes    float64
+	MeanRetweets float64
+}
+
+func (s *SqliteService) StoreReportPost(ctx context.Context, post *ReportPost) error {
+	query := `INSERT OR REPLACE INTO report_posts (tweet_id, feature_id, kind, variant, timestamp, likes, retweets, link, short_link)
+		VALUES(:tweet_id, :feature_id, :kind, :variant, :timestamp, :likes, :retweets, :link, :short_link)`
+
+	if _, err := s.db.NamedExecContext(ctx, query, post); err != nil {
+		return errors.Wrap(err, "failed to store report post")
+	}
+
+	return nil
+}
+
+// GetReportPostsSince gives the reports posted after the given time, oldest first
+func (s *SqliteService) GetReportPostsSince(ctx context.Context, since time.Time) ([]ReportPost, error) {
+	var result []ReportPost
+
+	query := "SELECT tweet_id, feature_id, kind, variant, timestamp, likes, retweets, link, short_link FROM report_posts WHERE timestamp>? ORDER BY timestamp"
+	if err := s.db.SelectContext(ctx, &result, query, since); err != nil {
+		return nil, errors.Wrap(err, "could not get report posts")
+	}
+
+	return result, nil
+}
+
+func (s *SqliteService) UpdateReportPostEngagement(ctx context.Context, tweetID int64, likes int, retweets int) error {
+	query := "UPDATE report_posts SET likes=?, retweets=? WHERE tweet_id=?"
+
+	if _, err := s.db.ExecContext(ctx, query, likes, retweets, tweetID); err != nil {
+		return errors.Wrap(err, "failed to update report post engagement")
+	}
+
+	return nil
+}
+
+// SummarizeVariants averages the engagement of the posts per kind and variant, ordered by kind and then by mean likes
+func SummarizeVariants(posts []ReportPost) []VariantStats {
+	var result []VariantStats
+	index := map[[2]string]int{}
+
+	for _, post := range posts {
+		key := [2]string{post.Kind, post.Variant}
+		i, ok := index[key]
+		if !ok {
+			i = len(result)
+			index[key] = i
+			result = append(result, VariantStats{Kind: post.Kind, Variant: post.Variant})
+		}
+
+		result[i].Posts++
+		result[i].MeanLikes += float64(post.Likes)
+		result[i].MeanRetweets += float64(post.Retweets)
+	}
+
+	for i := range result {
+		result[i].MeanLikes /= float64(result[i].Posts)
+		result[i].MeanRetweets /= float64(result[i].Posts)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Kind != result[j].Kind {
+			return result[i].Kind < result[j].Kind
+		}
+		return result[i].MeanLikes > result[j].MeanLikes
+	})
+
+	return result
+}
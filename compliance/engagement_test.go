@@ -0,0 +1,24 @@
+package compliance
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSummarizeVariants(t *testing.T) {
+	stats := SummarizeVariants([]ReportPost{
+		{Kind: ChangeSupport, Variant: "short", Likes: 4, Retweets: 1},
+		{Kind: ChangeSupport, Variant: "short", Likes: 6, Retweets: 3},
+		{Kind: ChangeSupport, Variant: "detailed", Likes: 10, Retweets: 2},
+		{Kind: ChangeNewListing, Variant: DefaultVariant, Likes: 1},
+	})
+
+	want := []VariantStats{
+		{Kind: ChangeNewListing, Variant: DefaultVariant, Posts: 1, MeanLikes: 1},
+		{Kind: ChangeSupport, Variant: "detailed", Posts: 1, MeanLikes: 10, MeanRetweets: 2},
+		{Kind: ChangeSupport, Variant: "short", Posts: 2, MeanLikes: 5, MeanRetweets: 2},
+	}
+	if !reflect.DeepEqual(stats, want) {
+		t.Errorf("got %+v, want %+v", stats, want)
+	}
+}
@@ -1,11 +1,10 @@
 package compliance
 
 import (
+	"cppimpbot/limits"
 	"database/sql"
 	"fmt"
 	"time"
-
-	"github.com/pkg/errors"
 )
 
 const (
@@ -17,41 +16,102 @@ const (
 type Features []*Feature
 
 type Feature struct {
-	Name              string
-	Timestamp         time.Time
-	CppVersion        int            `db:"cpp_version"`
-	PaperName         sql.NullString `db:"paper_name"`
-	PaperLink         sql.NullString `db:"paper_link"`
-	GccSupport        int            `db:"gcc_support"`
-	GccDisplayText    sql.NullString `db:"gcc_display_text"`
-	GccExtraText      sql.NullString `db:"gcc_extra_text"`
-	ClangSupport      int            `db:"clang_support"`
-	ClangDisplayText  sql.NullString `db:"clang_display_text"`
-	ClangExtraText    sql.NullString `db:"clang_extra_text"`
-	MsvcSupport       int            `db:"msvc_support"`
-	MsvcDisplayText   sql.NullString `db:"msvc_display_text"`
-	MsvcExtraText     sql.NullString `db:"msvc_extra_text"`
-	ReportedToTwitter bool           `db:"reported_to_twitter"`
-	ReportedBroken    bool           `db:"reported_broken"`
+	Id             int64  `db:"id"`
+	Language       string `db:"language"` //partition the feature is stored in, LanguageCpp, LanguageC or LanguageCppDR
+	Name           string
+	Timestamp      time.Time
+	CppVersion     int               `db:"cpp_version"`
+	PaperName      sql.NullString    `db:"paper_name"`
+	PaperLink      sql.NullString    `db:"paper_link"`
+	Compilers      []CompilerSupport `db:"-"`
+	ReportedBroken bool              `db:"reported_broken"`
+	Slug           string            `db:"slug"`    //stable identifier for external references, kept across renames
+	Removed        bool              `db:"removed"` //set on the entry recording that the feature disappeared from the page
 }
 
-const (
-	TwitterLimit        = 280
-	CppRefLinkSize      = len("https://en.cppreference.com/w/cpp/compiler_support")
-	TwitterShortUrlSize = len("https://t.co/iqNEBAK9qG")
-	TrimLimit           = TwitterLimit + (CppRefLinkSize - TwitterShortUrlSize)
-)
+// EntryChange is a stored entry together with the entry of the feature before it, nil for its first one
+type EntryChange struct {
+	Entry    Feature
+	Previous *Feature
+}
 
-func twitterTrimmed(text string) (result string) {
-	if len(text) > TrimLimit {
-		result = text[0:TrimLimit-3] + "..."
-	} else {
-		result = text
+// SupportFor returns the support listed for the given compiler key, or nil if the feature has no such column
+func (f *Feature) SupportFor(compiler string) *CompilerSupport {
+	for i := range f.Compilers {
+		if f.Compilers[i].Compiler == compiler {
+			return &f.Compilers[i]
+		}
+	}
+
+	return nil
+}
+
+// SetSupport replaces the support of the given compiler, adding it if the feature doesn't list it yet
+func (f *Feature) SetSupport(support CompilerSupport) {
+	if existing := f.SupportFor(support.Compiler); existing != nil {
+		*existing = support
+		return
+	}
+
+	f.Compilers = append(f.Compilers, support)
+}
+
+// Kind tells if the feature is a core language or a library feature, by what its support cells are about
+func (f *Feature) Kind() string {
+	for _, support := range f.Compilers {
+		if support.Kind == KindLibrary {
+			return KindLibrary
+		}
+	}
+
+	return KindCompiler
+}
+
+func supportOrEmpty(feature *Feature, compiler string) CompilerSupport {
+	if support := feature.SupportFor(compiler); support != nil {
+		return *support
+	}
+
+	return CompilerSupport{
+		Compiler:    compiler,
+		Kind:        feature.Kind(),
+		Support:     SupportNo,
+		DisplayText: sql.NullString{String: "", Valid: true},
+		ExtraText:   sql.NullString{String: "", Valid: true},
+	}
+}
+
+// compilerKeys gives all compilers listed by either feature, in the column order of next
+func compilerKeys(previous *Feature, next *Feature) (result []string) {
+	seen := map[string]bool{}
+
+	for _, feature := range []*Feature{next, previous} {
+		if feature == nil {
+			continue
+		}
+		for _, support := range feature.Compilers {
+			if !seen[support.Compiler] {
+				seen[support.Compiler] = true
+				result = append(result, support.Compiler)
+			}
+		}
+	}
+
+	if next != nil {
+		return sortCompilers(next.Kind(), result)
+	}
+	if previous != nil {
+		return sortCompilers(previous.Kind(), result)
 	}
 
 	return
 }
 
+// twitterTrimmed cuts the text off where twitter would refuse it, counting characters as twitter does
+func twitterTrimmed(text string) string {
+	return limits.Twitter.Trim(text)
+}
+
 func fromNullString(text sql.NullString) string {
 	if text.Valid {
 		return text.String
@@ -60,23 +120,24 @@ func fromNullString(text sql.NullString) string {
 	}
 }
 
-func compilerVersionTextString(displayText string, extraText string) string {
-	result := displayText
-	extra := extraText
-	if len(extra) > 0 {
-		result += "(" + extraText + ")"
+// SupportLevelName gives the name of a support level as used in reports and exported data
+func SupportLevelName(support int) string {
+	switch support {
+	case SupportNo:
+		return "no"
+	case SupportYes:
+		return "yes"
+	default:
+		return "partial"
 	}
-	return result
 }
 
-func compilerSupportString(support int, displayText string, extraText string) string {
-	if support == 0 {
-		return "[no] " + compilerVersionTextString(displayText, extraText)
-	} else if support == 1 {
-		return "[yes] " + compilerVersionTextString(displayText, extraText)
-	} else {
-		return "[partial] " + compilerVersionTextString(displayText, extraText)
+func isReportTypeRenamed(previous *Feature, next *Feature) bool {
+	if previous == nil || next == nil || previous.Removed || next.Removed {
+		return false
 	}
+
+	return previous.Name != next.Name
 }
 
 func isReportTypePaperModified(previous *Feature, next *Feature) bool {
@@ -88,8 +149,36 @@ func isReportTypePaperModified(previous *Feature, next *Feature) bool {
 		previous.PaperName != next.PaperName
 }
 
+// isReportTypeNewFeatureAdded is also true for a feature listed again after it was removed
 func isReportTypeNewFeatureAdded(previous *Feature, next *Feature) bool {
-	return previous == nil && next != nil
+	return (previous == nil || previous.Removed) && next != nil && !next.Removed
+}
+
+func isReportTypeRemoved(previous *Feature, next *Feature) bool {
+	return next != nil && next.Removed && (previous == nil || !previous.Removed)
+}
+
+func supportLevelChangedCompilers(previous *Feature, next *Feature) (result []string) {
+	for _, compiler := range compilerKeys(previous, next) {
+		if supportOrEmpty(previous, compiler).Support != supportOrEmpty(next, compiler).Support {
+			result = append(result, compiler)
+		}
+	}
+
+	return
+}
+
+func textChangedCompilers(previous *Feature, next *Feature) (result []string) {
+	for _, compiler := range compilerKeys(previous, next) {
+		previousSupport := supportOrEmpty(previous, compiler)
+		nextSupport := supportOrEmpty(next, compiler)
+
+		if !sameCompilerSupportText(&previousSupport, &nextSupport) {
+			result = append(result, compiler)
+		}
+	}
+
+	return
 }
 
 func isReportTypeSupportLevelChanged(previous *Feature, next *Feature) bool {
@@ -97,9 +186,7 @@ func isReportTypeSupportLevelChanged(previous *Feature, next *Feature) bool {
 		return false
 	}
 
-	return (previous.GccSupport != next.GccSupport) ||
-		(previous.ClangSupport != next.ClangSupport) ||
-		(previous.MsvcSupport != next.MsvcSupport)
+	return len(supportLevelChangedCompilers(previous, next)) > 0
 }
 
 func isReportTypeTextChanged(previous *Feature, next *Feature) bool {
@@ -107,82 +194,148 @@ func isReportTypeTextChanged(previous *Feature, next *Feature) bool {
 		return false
 	}
 
-	return (previous.GccDisplayText != next.GccDisplayText) ||
-		(previous.GccExtraText != next.GccExtraText) ||
-		(previous.ClangDisplayText != next.ClangDisplayText) ||
-		(previous.ClangExtraText != next.ClangExtraText) ||
-		(previous.MsvcDisplayText != next.MsvcDisplayText) ||
-		(previous.MsvcExtraText != next.MsvcExtraText)
+	return len(textChangedCompilers(previous, next)) > 0
 }
 
-func compilerSupportListing(feature *Feature, listGcc bool, listClang bool, listMsvc bool) (result string) {
-	gccBit := "GCC - " + compilerSupportString(feature.GccSupport, fromNullString(feature.GccDisplayText), fromNullString(feature.GccExtraText))
-	clangBit := "Clang - " + compilerSupportString(feature.ClangSupport, fromNullString(feature.ClangDisplayText), fromNullString(feature.ClangExtraText))
-	msvcBit := "MSVC - " + compilerSupportString(feature.MsvcSupport, fromNullString(feature.MsvcDisplayText), fromNullString(feature.MsvcExtraText))
+// newListingCompilers gives the vendors worth mentioning for a freshly listed feature: the primary ones of its kind plus any that support it
+func newListingCompilers(feature *Feature) (result []string) {
+	kind := feature.Kind()
 
-	first := true
-
-	if listGcc {
-		result += gccBit
-		first = false
-	}
-	if listClang {
-		if !first {
-			result += "\n"
+	for _, compiler := range primaryVendors(kind) {
+		if feature.SupportFor(compiler) == nil {
+			result = append(result, compiler)
 		}
-		result += clangBit
+	}
 
-		first = false
+	for _, support := range feature.Compilers {
+		if isPrimaryVendor(kind, support.Compiler) || support.Support != SupportNo || fromNullString(support.DisplayText) != "" {
+			result = append(result, support.Compiler)
+		}
 	}
-	if listMsvc {
-		if !first {
+
+	return sortCompilers(kind, result)
+}
+
+func supportListingLine(options ReportOptions, support CompilerSupport) string {
+	return RenderText(options, ChannelTwitter, "compilerSupport", support)
+}
+
+// deltaListing lists the support before or after the change of every compiler in the deltas
+func deltaListing(options ReportOptions, deltas []CompilerDelta, previous bool) (result string) {
+	for i, delta := range deltas {
+		if i > 0 {
 			result += "\n"
 		}
-		result += msvcBit
 
-		first = false
+		if previous {
+			result += supportListingLine(options, delta.Previous)
+		} else {
+			result += supportListingLine(options, delta.Next)
+		}
 	}
 
 	return
 }
 
-func FeatureToTwitterReport(previous *Feature, next *Feature) (string, error) {
-	if isReportTypePaperModified(previous, next) {
-		return "", nil //returning empty string means that this is a change we don't care about reporting at all. will be marked reported
-	} else if isReportTypeNewFeatureAdded(previous, next) {
-		supportListing := compilerSupportListing(next, true, true, true)
+// built-in wording of the tweets, see LoadTexts
+const twitterCommonText = `{{define "compilerSupport"}}{{compiler .Compiler}} - [{{level .Support}}] {{.DisplayText.String}}{{with .ExtraText.String}}({{.}}){{end}}{{end}}
+{{define "fromTo"}}{{if .ChangesOnly}}{{.Changes}}{{else}}From:
+{{.From}}
 
-		reportText := fmt.Sprintf("[New Listing] C++%v - \"%v\".\n\nSupport:\n%v", next.CppVersion, next.Name, supportListing)
-		reportText = twitterTrimmed(reportText)
+To:
+{{.To}}{{end}}{{end}}
+{{define "paperChange"}}{{if eq .OldPaper.Name .NewPaper.Name}}{{.NewPaper}}, {{or .OldPaper.Link "unlinked"}} → {{or .NewPaper.Link "unlinked"}}{{else}}{{.OldPaper}} → {{.NewPaper}}{{end}}{{end}}`
 
-		return reportText, nil
+var twitterTexts = map[string]string{
+	ChangeRemoved: `[Removed Listing] {{.Standard}} - "{{.Name}}" is no longer listed.`,
+	ChangeRename: `[Renamed] {{.Standard}} - "{{.PreviousName}}" is now listed as "{{.Name}}".{{if .Deltas}}
 
-	} else if isReportTypeSupportLevelChanged(previous, next) {
+{{template "fromTo" .}}{{end}}`,
+	ChangePaper: `[Paper Update] {{.Standard}} - "{{.Name}}".
 
-		listGcc := previous.GccSupport != next.GccSupport
-		listClang := previous.ClangSupport != next.ClangSupport
-		listMsvc := previous.MsvcSupport != next.MsvcSupport
+Paper: {{template "paperChange" .}}{{if .Deltas}}
 
-		previousSupportListing := compilerSupportListing(previous, listGcc, listClang, listMsvc)
-		nextSupportListing := compilerSupportListing(next, listGcc, listClang, listMsvc)
+{{template "fromTo" .}}{{end}}`,
+	ChangeNewListing: `[New Listing] {{.Standard}} - "{{.Name}}".
 
-		reportText := fmt.Sprintf("[Support Update] C++%v - \"%v\".\n\nFrom:\n%v\n\nTo:\n%v", next.CppVersion, next.Name, previousSupportListing, nextSupportListing)
-		reportText = twitterTrimmed(reportText)
+Support:
+{{.To}}`,
+	ChangeSupport: `[Support Update] {{.Standard}} - "{{.Name}}".
 
-		return reportText, nil
-	} else if isReportTypeTextChanged(previous, next) {
-		listGcc := previous.GccDisplayText != next.GccDisplayText || previous.GccExtraText != next.GccExtraText
-		listClang := previous.ClangDisplayText != next.ClangDisplayText || previous.ClangExtraText != next.ClangExtraText
-		listMsvc := previous.MsvcDisplayText != next.MsvcDisplayText || previous.MsvcExtraText != next.MsvcExtraText
+{{template "fromTo" .}}`,
+	ChangeText: `[Text Update] {{.Standard}} - "{{.Name}}".
 
-		previousSupportListing := compilerSupportListing(previous, listGcc, listClang, listMsvc)
-		nextSupportListing := compilerSupportListing(next, listGcc, listClang, listMsvc)
+{{template "fromTo" .}}`,
+}
 
-		reportText := fmt.Sprintf("[Text Update] C++%v - \"%v\".\n\nFrom:\n%v\n\nTo:\n%v", next.CppVersion, next.Name, previousSupportListing, nextSupportListing)
-		reportText = twitterTrimmed(reportText)
+func init() {
+	RegisterTexts(ChannelTwitter, twitterCommonText, twitterTexts, nil)
+}
 
-		return reportText, nil
-	} else {
-		return "", errors.Errorf("cannot handle")
+// ChangeReportText renders a change as a report of any length, for targets to fit into their posts. changes not worth
+// reporting give an empty string, as their kinds have no text
+func ChangeReportText(options ReportOptions, change Change) string {
+	return RenderText(options, ChannelTwitter, change.Kind, NewTextData(options, change, ""))
+}
+
+// ChangeToTwitterReport renders a change as a single tweet, trimmed if it doesn't fit. changes not worth reporting give
+// an empty string
+func ChangeToTwitterReport(options ReportOptions, change Change) string {
+	return twitterTrimmed(ChangeReportText(options, change))
+}
+
+// ChangeToTwitterThread renders a change as the tweets of a thread, a single one if the report fits, so that nothing is
+// trimmed away. changes not worth reporting give no tweets
+func ChangeToTwitterThread(options ReportOptions, change Change) []string {
+	reportText := ChangeReportText(options, change)
+	if reportText == "" {
+		return nil
+	}
+
+	return limits.Twitter.Split(reportText)
+}
+
+// FeatureToTwitterReport diffs two entries of a feature and renders the change as a tweet
+func FeatureToTwitterReport(options ReportOptions, previous *Feature, next *Feature) (string, error) {
+	change, err := DiffFeatures(previous, next)
+	if err != nil {
+		return "", err
+	}
+
+	return ChangeToTwitterReport(options, change), nil
+}
+
+func releaseReportText(compiler string, version string, features []Feature) string {
+	reportText := fmt.Sprintf("[Release] %v %v is out! Newly supported:", CompilerDisplayName(compiler), version)
+
+	for _, feature := range features {
+		reportText += fmt.Sprintf("\n- %v \"%v\"", feature.Standard(), feature.Name)
 	}
+
+	return reportText
+}
+
+// ReleaseToTwitterReport renders the roundup of the features a newly released compiler version supports
+func ReleaseToTwitterReport(compiler string, version string, features []Feature) string {
+	return twitterTrimmed(releaseReportText(compiler, version, features))
+}
+
+// ReleaseToTwitterThread renders the roundup of a release as the tweets of a thread, as big releases support more
+// features than fit a tweet
+func ReleaseToTwitterThread(compiler string, version string, features []Feature) []string {
+	return limits.Twitter.Split(releaseReportText(compiler, version, features))
+}
+
+// FeatureToTwitterMatrix renders the current support of a feature, used when someone asks the bot about it
+func FeatureToTwitterMatrix(options ReportOptions, feature *Feature, prefix string) string {
+	supportListing := deltaListing(options, compilerDeltas(nil, feature, newListingCompilers(feature)), false)
+
+	paper := ""
+	if paperName := fromNullString(feature.PaperName); paperName != "" {
+		paper = " (" + paperName + ")"
+	}
+
+	reportText := fmt.Sprintf("%v%v - \"%v\"%v.\n\nSupport:\n%v", prefix, feature.Standard(), feature.Name, paper, supportListing)
+
+	return twitterTrimmed(reportText)
 }
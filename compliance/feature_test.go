@@ -0,0 +1,252 @@
+package compliance
+
+import (
+	"cppimpbot/limits"
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sampleFeature is a C++20 feature only Clang supports, with the support given set on top
+func sampleFeature(supports ...CompilerSupport) *Feature {
+	feature := &Feature{
+		Name:       "Initializer list constructors in class template argument deduction",
+		CppVersion: 20,
+		PaperName:  sql.NullString{String: "P0702R1", Valid: true},
+		PaperLink:  sql.NullString{String: "https://wg21.link/P0702R1", Valid: true},
+	}
+	feature.SetSupport(testSupport(CompilerGcc, 0, "", ""))
+	feature.SetSupport(testSupport(CompilerClang, 1, "6 (partial)*", "only supported if flag supplied"))
+	feature.SetSupport(testSupport(CompilerMsvc, 0, "", ""))
+	feature.SetSupport(testSupport("apple_clang", 0, "", ""))
+
+	for _, support := range supports {
+		feature.SetSupport(support)
+	}
+
+	return feature
+}
+
+// sampleSupported is the sample feature after GCC, MSVC and Apple Clang gained support
+func sampleSupported() *Feature {
+	return sampleFeature(testSupport(CompilerGcc, 1, "9*", "still some bugs"), testSupport(CompilerMsvc, 1, "19.20", ""), testSupport("apple_clang", 1, "10.0.1", ""))
+}
+
+func sampleChange(t *testing.T, previous *Feature, next *Feature) Change {
+	t.Helper()
+
+	change, err := DiffFeatures(previous, next)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return change
+}
+
+// trimLines drops the spaces ending the lines of a report, which it has after empty support texts
+func trimLines(text string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], " ")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func TestLibraryReport(t *testing.T) {
+	library := &Feature{Name: "std::any", CppVersion: 17}
+	for _, support := range []CompilerSupport{testSupport(LibraryLibstdcxx, 1, "7", ""), testSupport(LibraryMsvcStl, 1, "19.10", ""), testSupport("apple_libcxx", 1, "10.0.0", "")} {
+		support.Kind = KindLibrary
+		library.SetSupport(support)
+	}
+
+	report, err := FeatureToTwitterReport(ReportOptions{}, nil, library)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//libc++ is always listed for library features, even before it lists support
+	want := "Support:\nlibstdc++ - [yes] 7\nlibc++ - [no]\nMSVC STL - [yes] 19.10\nApple libc++ - [yes] 10.0.0"
+	if !strings.HasSuffix(trimLines(report), want) {
+		t.Errorf("got\n%v\nwant it to end in\n%v", report, want)
+	}
+}
+
+func TestOnlyCompilers(t *testing.T) {
+	change := sampleChange(t, sampleFeature(), sampleSupported())
+	if compilers := fmt.Sprint(deltaCompilers(change)); compilers != "[gcc msvc apple_clang]" {
+		t.Fatalf("got deltas of %v", compilers)
+	}
+
+	only := change.OnlyCompilers([]string{CompilerGcc, CompilerClang})
+	if only.Kind != ChangeSupport || fmt.Sprint(deltaCompilers(only)) != "[gcc]" {
+		t.Errorf("got kind '%v' with deltas of %v, want the support of gcc", only.Kind, deltaCompilers(only))
+	}
+
+	if none := change.OnlyCompilers([]string{CompilerClang}); none.Kind != "" {
+		t.Errorf("change of no reported compiler has kind '%v'", none.Kind)
+	}
+}
+
+func TestReleaseToTwitterThread(t *testing.T) {
+	var features []Feature
+	for i := 0; i < 12; i++ {
+		feature := sampleFeature()
+		feature.Name = fmt.Sprintf("%v, part %v", feature.Name, i+1)
+		features = append(features, *feature)
+	}
+
+	thread := ReleaseToTwitterThread(CompilerGcc, "9.1.0", features)
+	if len(thread) != 5 {
+		t.Fatalf("got %v tweets, want 5", len(thread))
+	}
+	if !strings.HasPrefix(thread[0], "[Release] GCC 9.1.0 is out! Newly supported:\n") {
+		t.Errorf("first tweet is\n%v", thread[0])
+	}
+
+	listed := 0
+	for i, tweet := range thread {
+		listed += strings.Count(tweet, "- C++20 \"")
+		if !limits.Twitter.Fits(tweet) {
+			t.Errorf("tweet %v is %v long", i+1, limits.Twitter.Count(tweet))
+		}
+		if !strings.HasSuffix(tweet, fmt.Sprintf("(%v/5)", i+1)) {
+			t.Errorf("tweet %v isn't numbered: %v", i+1, tweet)
+		}
+	}
+	if listed != 12 {
+		t.Errorf("listed %v of 12 features", listed)
+	}
+}
+
+func TestOnlyLanguages(t *testing.T) {
+	cFeature := sampleFeature()
+	cFeature.Language = LanguageC
+	cFeature.CppVersion = 23
+	cFeature.Name = "Binary integer constants"
+
+	change := sampleChange(t, nil, cFeature)
+	if report := ChangeToTwitterReport(ReportOptions{}, change); !strings.HasPrefix(report, `[New Listing] C23 - "Binary integer constants".`) {
+		t.Errorf("got report\n%v", report)
+	}
+
+	if only := change.OnlyLanguages([]string{LanguageCpp}); only.Kind != "" {
+		t.Errorf("C change with only C++ reported has kind '%v'", only.Kind)
+	}
+	if only := change.OnlyLanguages([]string{LanguageCpp, LanguageC}); only.Kind != ChangeNewListing {
+		t.Errorf("C change with C reported has kind '%v'", only.Kind)
+	}
+}
+
+func TestFeatureToTwitterReport(t *testing.T) {
+	gccSupport := testSupport(CompilerGcc, 1, "9*", "still some bugs")
+	msvcPartial := testSupport(CompilerMsvc, 2, "19.20", "not bug free")
+
+	renamed := sampleFeature(gccSupport)
+	renamed.Name = "Initializer list constructors in CTAD"
+	removed := sampleFeature(gccSupport)
+	removed.Removed = true
+
+	for _, test := range []struct {
+		what     string
+		previous *Feature
+		next     *Feature
+		report   string
+	}{
+		{"new listing", nil, sampleFeature(), `[New Listing] C++20 - "Initializer list constructors in class template argument deduction".
+
+Support:
+GCC - [no]
+Clang - [yes] 6 (partial)*(only supported if flag supplied)
+MSVC - [no] `},
+		{"gained support", sampleFeature(), sampleFeature(gccSupport), `[Support Update] C++20 - "Initializer list constructors in class template argument deduction".
+
+From:
+GCC - [no]
+
+To:
+GCC - [yes] 9*(still some bugs)`},
+		{"lost support", sampleSupported(), sampleFeature(), `[Support Update] C++20 - "Initializer list constructors in class template argument deduction".
+
+From:
+GCC - [yes] 9*(still some bugs)
+MSVC - [yes] 19.20
+Apple Clang - [yes] 10.0.1
+
+To:
+GCC - [no]
+MSVC - [no]
+Apple Clang - [no] `},
+		{"text changed", sampleFeature(msvcPartial), sampleFeature(msvcPartial, testSupport(CompilerClang, 1, "6", ""), testSupport(CompilerMsvc, 2, "19.20", "one bug")), `[Text Update] C++20 - "Initializer list constructors in class template argument deduction".
+
+From:
+Clang - [yes] 6 (partial)*(only supported if flag supplied)
+MSVC - [partial] 19.20(not bug free)
+
+To:
+Clang - [yes] 6
+MSVC - [partial] 19.20(one bug)`},
+		{"renamed", sampleFeature(), renamed, `[Renamed] C++20 - "Initializer list constructors in class template argument deduction" is now listed as "Initializer list constructors in CTAD".
+
+From:
+GCC - [no]
+
+To:
+GCC - [yes] 9*(still some bugs)`},
+		{"removed", sampleFeature(gccSupport), removed, `[Removed Listing] C++20 - "Initializer list constructors in class template argument deduction" is no longer listed.`},
+	} {
+		report, err := FeatureToTwitterReport(ReportOptions{}, test.previous, test.next)
+		if err != nil {
+			t.Errorf("%v: %v", test.what, err)
+			continue
+		}
+		if trimLines(report) != trimLines(test.report) {
+			t.Errorf("%v: got\n%v\nwant\n%v", test.what, report, test.report)
+		}
+	}
+}
+
+func TestPaperReports(t *testing.T) {
+	revised := sampleFeature()
+	revised.PaperName = sql.NullString{String: "P0702R2", Valid: true}
+	revised.PaperLink = sql.NullString{String: "https://wg21.link/P0702R2", Valid: true}
+	relinked := sampleFeature()
+	relinked.PaperLink = sql.NullString{String: "https://wg21.link/P0702", Valid: true}
+	revisedAndSupported := sampleFeature(testSupport(CompilerGcc, 1, "11", ""))
+	revisedAndSupported.PaperName = revised.PaperName
+	revisedAndSupported.PaperLink = revised.PaperLink
+	unlisted := sampleFeature()
+	unlisted.PaperName = sql.NullString{}
+	unlisted.PaperLink = sql.NullString{}
+
+	header := `[Paper Update] C++20 - "Initializer list constructors in class template argument deduction".` + "\n\n"
+	for _, test := range []struct {
+		what   string
+		next   *Feature
+		report string
+	}{
+		{"revised", revised, header + "Paper: P0702R1 → P0702R2"},
+		{"relinked", relinked, header + "Paper: P0702R1, https://wg21.link/P0702R1 → https://wg21.link/P0702"},
+		{"revised and supported", revisedAndSupported, header + "Paper: P0702R1 → P0702R2\n\nFrom:\nGCC - [no]\n\nTo:\nGCC - [yes] 11"},
+		{"unlisted", unlisted, header + "Paper: P0702R1 → none"},
+	} {
+		report, err := FeatureToTwitterReport(ReportOptions{}, sampleFeature(), test.next)
+		if err != nil {
+			t.Errorf("%v: %v", test.what, err)
+			continue
+		}
+		if trimLines(report) != test.report {
+			t.Errorf("%v: got\n%v\nwant\n%v", test.what, report, test.report)
+		}
+	}
+
+	since := time.Date(2019, time.June, 1, 12, 0, 0, 0, time.UTC)
+	thread := CatchUpToTwitterThread(ReportOptions{}, []Change{sampleChange(t, sampleFeature(), revised)}, since, 1)
+	want := "[While I was away] Since June 1, cppreference saw 1 changes:\n" +
+		`- C++20 "Initializer list constructors in class template argument deduction" now refers to P0702R2 instead of P0702R1`
+	if len(thread) != 1 || thread[0] != want {
+		t.Errorf("got catch-up %q, want %q", thread, want)
+	}
+}
@@ -0,0 +1,257 @@
+package compliance
+
+import (
+	"io/ioutil"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+// names of the functions of a hook script. scripts define the ones they need, like def veto(change): ...
+const (
+	HookVeto = "veto" //changes it returns a true value for aren't reported, a returned string is logged as the reason
+	HookMute = "mute" //compilers of the list it returns are left out of the change
+	HookText = "text" //replaces the report text with the string it returns, change.text being the rendered one. "" leaves the tweet out, None keeps it
+)
+
+// hookSteps bounds the work of a single hook call, so a script stuck in a loop fails instead of holding up reports
+const hookSteps = 1000000
+
+// Hooks run a Starlark script on every change about to be reported, to veto it, mute compilers of it or rewrite its
+// report text. the script is loaded again whenever it changes, so it can be edited while the bot runs
+type Hooks struct {
+	path     string
+	modified time.Time
+	globals  starlark.StringDict
+	mutex    sync.Mutex
+}
+
+// LoadHooks runs the hook script at path to get its functions. an empty path gives no hooks, which leave every change
+// as it is
+func LoadHooks(path string) (*Hooks, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	hooks := &Hooks{path: path}
+	if err := hooks.load(); err != nil {
+		return nil, err
+	}
+
+	return hooks, nil
+}
+
+func newHookThread(name string) *starlark.Thread {
+	thread := &starlark.Thread{
+		Name: name,
+		Print: func(thread *starlark.Thread, msg string) {
+			slog.Info("hook script", "hook", thread.Name, "msg", msg)
+		},
+	}
+	thread.SetMaxExecutionSteps(hookSteps)
+
+	return thread
+}
+
+func (h *Hooks) load() error {
+	info, err := os.Stat(h.path)
+	if err != nil {
+		return errors.Wrap(err, "could not read hook script")
+	}
+
+	if h.globals != nil && info.ModTime().Equal(h.modified) {
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(h.path)
+	if err != nil {
+		return errors.Wrap(err, "could not read hook script")
+	}
+
+	globals, err := starlark.ExecFile(newHookThread("load"), h.path, data, nil)
+	if err != nil {
+		return errors.Wrapf(err, "invalid hook script %v", h.path)
+	}
+
+	for _, name := range []string{HookVeto, HookMute, HookText} {
+		if hook, ok := globals[name]; ok {
+			if _, callable := hook.(starlark.Callable); !callable {
+				return errors.Errorf("invalid hook script %v, %v is a %v instead of a function", h.path, name, hook.Type())
+			}
+		}
+	}
+
+	h.globals, h.modified = globals, info.ModTime()
+
+	return nil
+}
+
+// current gives the globals of the script, run again if it changed. a script that became invalid keeps the last valid
+// one running
+func (h *Hooks) current() starlark.StringDict {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if err := h.load(); err != nil {
+		slog.Warn("keeping the hooks loaded before", "err", err)
+	}
+
+	return h.globals
+}
+
+func hookStrings(values []string) starlark.Tuple {
+	tuple := make(starlark.Tuple, 0, len(values))
+	for _, value := range values {
+		tuple = append(tuple, starlark.String(value))
+	}
+
+	return tuple
+}
+
+// hookChange is the change as hooks get it: the data of ReportVariants in snake case, its kind, the compilers it
+// changed and the rendered report text, which is only set for the text hook
+func hookChange(options ReportOptions, change Change, link string, text string) *starlarkstruct.Struct {
+	data := reportData(options, change, link)
+
+	var compilers []string
+	for _, delta := range change.Deltas {
+		compilers = append(compilers, delta.Compiler)
+	}
+
+	return starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+		"kind":           starlark.String(change.Kind),
+		"compilers":      hookStrings(compilers),
+		"language":       starlark.String(data.Language),
+		"standard":       starlark.String(data.Standard),
+		"cpp_version":    starlark.MakeInt(data.CppVersion),
+		"name":           starlark.String(data.Name),
+		"previous_name":  starlark.String(data.PreviousName),
+		"paper":          starlark.String(data.Paper),
+		"previous_paper": starlark.String(data.PreviousPaper),
+		"from":           starlark.String(data.From),
+		"to":             starlark.String(data.To),
+		"changes":        starlark.String(data.Changes),
+		"transitions":    starlark.String(data.Transitions),
+		"link":           starlark.String(data.Link),
+		"text":           starlark.String(text),
+	})
+}
+
+// run calls the named function of the script with the change, ok being false if the script doesn't define it
+func run(globals starlark.StringDict, name string, change *starlarkstruct.Struct) (result starlark.Value, ok bool, err error) {
+	hook, ok := globals[name]
+	if !ok {
+		return nil, false, nil
+	}
+
+	result, err = starlark.Call(newHookThread(name), hook, starlark.Tuple{change}, nil)
+	if err != nil {
+		return nil, true, errors.Wrapf(err, "hook %v failed", name)
+	}
+
+	return result, true, nil
+}
+
+// mutedCompilers gives the compiler keys of the list or tuple the mute hook returned
+func mutedCompilers(result starlark.Value) (map[string]bool, error) {
+	if result == starlark.None {
+		return nil, nil
+	}
+
+	iterable, ok := result.(starlark.Iterable)
+	if !ok {
+		return nil, errors.Errorf("hook %v returned a %v instead of a list of compilers", HookMute, result.Type())
+	}
+
+	muted := map[string]bool{}
+	iterator := iterable.Iterate()
+	defer iterator.Done()
+
+	var compiler starlark.Value
+	for iterator.Next(&compiler) {
+		key, ok := starlark.AsString(compiler)
+		if !ok {
+			return nil, errors.Errorf("hook %v returned a %v instead of a compiler key", HookMute, compiler.Type())
+		}
+		muted[key] = true
+	}
+
+	return muted, nil
+}
+
+// Filter runs the veto and mute hooks on the change. vetoed changes lose their kind, so they aren't reported at all,
+// like changes of muted languages. a failing hook leaves the change as it is
+func (h *Hooks) Filter(options ReportOptions, change Change) Change {
+	if h == nil || change.Kind == "" {
+		return change
+	}
+	globals := h.current()
+
+	veto, ok, err := run(globals, HookVeto, hookChange(options, change, "", ""))
+	if err != nil {
+		slog.Warn("not vetoing change", "feature", change.Feature.Name, "err", err)
+	} else if ok && bool(veto.Truth()) {
+		reason, _ := starlark.AsString(veto)
+		slog.Info("hooks vetoed change", "feature", change.Feature.Name, "reason", reason)
+		change.Kind = ""
+		return change
+	}
+
+	result, ok, err := run(globals, HookMute, hookChange(options, change, "", ""))
+	if err != nil {
+		slog.Warn("not muting compilers", "feature", change.Feature.Name, "err", err)
+		return change
+	}
+	if !ok {
+		return change
+	}
+
+	muted, err := mutedCompilers(result)
+	if err != nil {
+		slog.Warn("not muting compilers", "feature", change.Feature.Name, "err", err)
+		return change
+	}
+	if len(muted) == 0 {
+		return change
+	}
+
+	var keep []string
+	for _, delta := range change.Deltas {
+		if !muted[delta.Compiler] {
+			keep = append(keep, delta.Compiler)
+		}
+	}
+
+	return change.OnlyCompilers(keep)
+}
+
+// Text runs the text hook on the rendered report of the change. an empty report stays empty, and a failing hook
+// keeps the rendered one
+func (h *Hooks) Text(options ReportOptions, change Change, link string, text string) string {
+	if h == nil || text == "" {
+		return text
+	}
+
+	result, ok, err := run(h.current(), HookText, hookChange(options, change, link, text))
+	if err != nil {
+		slog.Warn("keeping the rendered text", "feature", change.Feature.Name, "err", err)
+		return text
+	}
+	if !ok || result == starlark.None {
+		return text
+	}
+
+	rewritten, isString := starlark.AsString(result)
+	if !isString {
+		slog.Warn("keeping the rendered text", "feature", change.Feature.Name, "err", errors.Errorf("hook %v returned a %v instead of a string", HookText, result.Type()))
+		return text
+	}
+
+	return strings.TrimSpace(rewritten)
+}
@@ -0,0 +1,151 @@
+package compliance
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const testHookScript = `
+def veto(change):
+    if "Concepts" in change.name:
+        return "no concepts"
+
+def mute(change):
+    return ["msvc", "clang"]
+
+def text(change):
+    if change.cpp_version == 20:
+        return ""
+    if change.paper:
+        return change.text + " #" + change.paper
+`
+
+func writeHooks(t *testing.T, path string, script string, modified time.Time) {
+	t.Helper()
+
+	if err := ioutil.WriteFile(path, []byte(script), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modified, modified); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func loadTestHooks(t *testing.T, script string) (*Hooks, string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "hooks.star")
+	writeHooks(t, path, script, time.Now().Add(-time.Hour))
+
+	hooks, err := LoadHooks(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return hooks, path
+}
+
+func supportChange(t *testing.T, name string) Change {
+	t.Helper()
+
+	change, err := DiffFeatures(ruleFeature(name, 0, 0, ""), ruleFeature(name, 1, 1, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return change
+}
+
+func TestHooksFilter(t *testing.T) {
+	hooks, _ := loadTestHooks(t, testHookScript)
+
+	filtered := hooks.Filter(ReportOptions{}, supportChange(t, "Pack indexing"))
+	if filtered.Kind != ChangeSupport || !reflect.DeepEqual(deltaCompilers(filtered), []string{CompilerGcc}) {
+		t.Errorf("muted change has kind '%v' and compilers %v, want support of gcc", filtered.Kind, deltaCompilers(filtered))
+	}
+
+	if vetoed := hooks.Filter(ReportOptions{}, supportChange(t, "Concepts")); vetoed.Kind != "" {
+		t.Errorf("vetoed change has kind '%v'", vetoed.Kind)
+	}
+
+	var none *Hooks
+	if change := none.Filter(ReportOptions{}, supportChange(t, "Concepts")); change.Kind != ChangeSupport || len(change.Deltas) != 2 {
+		t.Errorf("no hooks changed the change to kind '%v' with compilers %v", change.Kind, deltaCompilers(change))
+	}
+}
+
+func TestHooksText(t *testing.T) {
+	hooks, _ := loadTestHooks(t, testHookScript)
+
+	change := supportChange(t, "Pack indexing")
+	if text := hooks.Text(ReportOptions{}, change, "", "GCC supports Pack indexing"); text != "GCC supports Pack indexing" {
+		t.Errorf("None changed the text to '%v'", text)
+	}
+
+	change.Feature.PaperName.String, change.Feature.PaperName.Valid = "P2662", true
+	if text := hooks.Text(ReportOptions{}, change, "", "GCC supports Pack indexing"); text != "GCC supports Pack indexing #P2662" {
+		t.Errorf("got text '%v'", text)
+	}
+
+	change.Feature.CppVersion = 20
+	if text := hooks.Text(ReportOptions{}, change, "", "GCC supports Pack indexing"); text != "" {
+		t.Errorf("got text '%v', want it left out", text)
+	}
+}
+
+func TestHooksFailing(t *testing.T) {
+	hooks, _ := loadTestHooks(t, `
+def veto(change):
+    return change.missing
+
+def mute(change):
+    return "gcc"
+
+def text(change):
+    for i in range(100000000):
+        pass
+`)
+
+	change := hooks.Filter(ReportOptions{}, supportChange(t, "Pack indexing"))
+	if change.Kind != ChangeSupport || len(change.Deltas) != 2 {
+		t.Errorf("failing hooks changed the change to kind '%v' with compilers %v", change.Kind, deltaCompilers(change))
+	}
+
+	if text := hooks.Text(ReportOptions{}, change, "", "GCC supports Pack indexing"); text != "GCC supports Pack indexing" {
+		t.Errorf("a hook running out of steps changed the text to '%v'", text)
+	}
+}
+
+func TestHooksReload(t *testing.T) {
+	hooks, path := loadTestHooks(t, testHookScript)
+
+	writeHooks(t, path, "def veto(change):\n    return True\n", time.Now())
+	if change := hooks.Filter(ReportOptions{}, supportChange(t, "Pack indexing")); change.Kind != "" {
+		t.Errorf("edited script didn't veto, kind '%v'", change.Kind)
+	}
+
+	writeHooks(t, path, "def veto(change)\n", time.Now().Add(time.Hour))
+	if change := hooks.Filter(ReportOptions{}, supportChange(t, "Pack indexing")); change.Kind != "" {
+		t.Errorf("invalid script replaced the last valid one, kind '%v'", change.Kind)
+	}
+}
+
+func TestLoadHooks(t *testing.T) {
+	if hooks, err := LoadHooks(""); hooks != nil || err != nil {
+		t.Errorf("empty path gave hooks %v, error %v", hooks, err)
+	}
+
+	dir := t.TempDir()
+	for _, script := range []string{"def veto(change)\n", "veto = 1\n", "fail('broken')\n"} {
+		path := filepath.Join(dir, "hooks.star")
+		writeHooks(t, path, script, time.Now())
+
+		if _, err := LoadHooks(path); err == nil {
+			t.Errorf("script %q loaded", script)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package compliance
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// IgnoredFeature is a feature whose changes are stored but not reported, like a row cppreference editors keep
+// tweaking. it is kept by slug, so it stays ignored when renamed
+type IgnoredFeature struct {
+	Slug      string    `db:"slug"`
+	Name      string    `db:"name"` //name of the feature when it was ignored
+	Reason    string    `db:"reason"`
+	Timestamp time.Time `db:"timestamp"`
+}
+
+// IgnoreFeature adds a feature to the ignored ones, or updates its name and reason if it is ignored already
+func (s *SqliteService) IgnoreFeature(ctx context.Context, ignored *IgnoredFeature) error {
+	query := "INSERT OR REPLACE INTO ignored_features (slug, name, reason, timestamp) VALUES(?, ?, ?, ?)"
+
+	if _, err := s.db.ExecContext(ctx, query, ignored.Slug, ignored.Name, ignored.Reason, ignored.Timestamp); err != nil {
+		return errors.Wrap(err, "could not ignore feature")
+	}
+
+	return nil
+}
+
+// UnignoreFeature reports the changes of the feature again, and tells if it was ignored
+func (s *SqliteService) UnignoreFeature(ctx context.Context, slug string) (bool, error) {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM ignored_features WHERE slug=?", slug)
+	if err != nil {
+		return false, errors.Wrap(err, "could not unignore feature")
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "could not unignore feature")
+	}
+
+	return affected > 0, nil
+}
+
+// GetIgnoredFeatures lists the ignored features, the most recently ignored first
+func (s *SqliteService) GetIgnoredFeatures(ctx context.Context) ([]IgnoredFeature, error) {
+	result := []IgnoredFeature{}
+
+	if err := s.db.SelectContext(ctx, &result, "SELECT slug, name, reason, timestamp FROM ignored_features ORDER BY timestamp DESC, slug"); err != nil {
+		return nil, errors.Wrap(err, "could not get ignored features")
+	}
+
+	return result, nil
+}
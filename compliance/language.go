@@ -0,0 +1,70 @@
+package compliance
+
+import "fmt"
+
+// languages the stored features are partitioned by. every language has its own history, so features of different
+// languages never share a name, slug or version
+const (
+	LanguageCpp   = "cpp"
+	LanguageC     = "c"
+	LanguageCppDR = "cppdr" //defect reports against C++, stored with version 0 as they apply to every standard they affect
+)
+
+var Languages = []string{LanguageCpp, LanguageC, LanguageCppDR}
+
+var languageNames = map[string]string{
+	LanguageCpp:   "C++",
+	LanguageC:     "C",
+	LanguageCppDR: "C++ DR",
+}
+
+// IsLanguage tells if the key is one of the known languages
+func IsLanguage(language string) bool {
+	_, ok := languageNames[language]
+	return ok
+}
+
+// LanguageName gives the name of a language as written before its standard versions, like C++ in C++20.
+// features stored before languages existed have none and are C++
+func LanguageName(language string) string {
+	if name, ok := languageNames[language]; ok {
+		return name
+	}
+
+	return languageNames[LanguageCpp]
+}
+
+// StandardName names a standard revision of a language, like C++20 or C23. defect reports belong to no single revision
+// and are all named C++ DR
+func StandardName(language string, version int) string {
+	if language == LanguageCppDR {
+		return LanguageName(language)
+	}
+
+	return fmt.Sprintf("%v%02d", LanguageName(language), version)
+}
+
+// StandardYear gives the year of a standard revision, for ordering revisions across the turn of the century like C99 and C11
+func StandardYear(version int) int {
+	if version >= 70 {
+		return 1900 + version
+	}
+
+	return 2000 + version
+}
+
+// Standard names the standard revision the feature is part of, like C++20 or C23
+func (f *Feature) Standard() string {
+	return StandardName(f.Language, f.CppVersion)
+}
+
+// FeaturesOfLanguage leaves out the features of other languages
+func FeaturesOfLanguage(features []Feature, language string) (result []Feature) {
+	for _, feature := range features {
+		if feature.Language == language {
+			result = append(result, feature)
+		}
+	}
+
+	return
+}
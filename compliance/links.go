@@ -0,0 +1,74 @@
+package compliance
+
+import (
+	"cppimpbot/limits"
+	"fmt"
+	"strings"
+)
+
+// CppreferenceLink gives the link to the section of the compiler support page the feature is listed in. cppreference
+// has no anchors for single rows, so it points at the table of the standard, worked out from the usual heading like
+// "C++20 core language features", or "C++ defect reports" for DRs
+func CppreferenceLink(page string, feature *Feature) string {
+	heading := feature.Standard() + " core language features"
+	if feature.Language == LanguageCppDR {
+		heading = "C++ defect reports"
+	} else if feature.Kind() == KindLibrary {
+		heading = feature.Standard() + " library features"
+	}
+
+	return page + "#" + headingAnchor(heading)
+}
+
+// headingAnchor encodes a section heading the way mediawiki does for its id: spaces become underscores and characters
+// not allowed in ids are encoded like in URLs, with dots instead of percent signs
+func headingAnchor(heading string) string {
+	var anchor strings.Builder
+	for _, b := range []byte(strings.Replace(heading, " ", "_", -1)) {
+		switch {
+		case b >= 'a' && b <= 'z', b >= 'A' && b <= 'Z', b >= '0' && b <= '9', strings.IndexByte("_-.:", b) >= 0:
+			anchor.WriteByte(b)
+		default:
+			fmt.Fprintf(&anchor, ".%02X", b)
+		}
+	}
+
+	return anchor.String()
+}
+
+// WithLinks ends the text of a report with the links that have room for them, after an empty line. links come in the
+// order they are worth the room, and empty ones are skipped. a text fitting a tweet stays a single tweet: its prose is
+// trimmed to make room for the first link, which tells more than the end of a sentence, while the others are left out
+// if they don't fit. texts already too long for a tweet get the links that don't make their thread any longer
+func WithLinks(text string, links []string) string {
+	separator := "\n\n"
+
+	for i, link := range links {
+		if link == "" {
+			continue
+		}
+
+		switch {
+		case hasRoom(text, separator+link):
+			text += separator + link
+		case limits.Twitter.Fits(text) && i == 0:
+			text = limits.Twitter.TrimFor(text, separator+link)
+		default:
+			continue
+		}
+
+		separator = "\n"
+	}
+
+	return text
+}
+
+// hasRoom tells if the suffix fits after the text of a report: in its tweet, or in the last tweet of its thread if it
+// is already too long for one, so that the thread gets no longer for it
+func hasRoom(text string, suffix string) bool {
+	if limits.Twitter.Fits(text) {
+		return limits.Twitter.Fits(text + suffix)
+	}
+
+	return len(limits.Twitter.Split(text+suffix)) == len(limits.Twitter.Split(text))
+}
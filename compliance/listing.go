@@ -0,0 +1,108 @@
+package compliance
+
+import (
+	"sort"
+	"strings"
+)
+
+// ListingLine is a line of a support listing: the support of a compiler before and after a change, with what of it
+// changed. channels render it with their listingLine template
+type ListingLine struct {
+	CompilerDelta
+	Listed       bool //if the support before the change is worth showing, false for new listings
+	Full         bool //if the support is shown whole on both sides of the arrow, rather than only what changed
+	LevelChanged bool
+	TextChanged  bool
+}
+
+// built-in listing templates every channel has. channels style the lines by redefining compilerName, supportLevel and
+// supportText in their common templates, or all of it. transitionLine phrases how the support level of a compiler
+// changed, like "GCC gains full support". paperChange shows the papers of a paper update, styled by paperRef
+const listingText = `{{define "compilerName"}}{{compiler .}}{{end}}
+{{define "supportLevel"}}[{{level .}}]{{end}}
+{{define "supportText"}}{{supportText .}}{{end}}
+{{define "supportCell"}}{{template "supportLevel" .Support}}{{if supportText .}} {{template "supportText" .}}{{end}}{{end}}
+{{define "supportChange"}}{{if not .Listed}}{{template "supportCell" .Next}}{{else if or .Full (and .LevelChanged .TextChanged)}}{{template "supportCell" .Previous}} → {{template "supportCell" .Next}}{{else if .LevelChanged}}{{template "supportLevel" .Previous.Support}} → {{template "supportCell" .Next}}{{else if and .TextChanged (supportText .Next)}}{{template "supportCell" .Previous}} → {{template "supportText" .Next}}{{else}}{{template "supportCell" .Previous}} → {{template "supportCell" .Next}}{{end}}{{end}}
+{{define "listingLine"}}{{template "compilerName" .Compiler}}: {{template "supportChange" .}}{{end}}
+{{define "transition"}}{{if eq .Transition "full"}}gains full support{{else if eq .Transition "partial"}}gains partial support{{else if eq .Transition "completed"}}completes its support{{else if eq .Next.Support 2}}downgraded to partial{{else}}drops support{{end}}{{end}}
+{{define "transitionLine"}}{{template "compilerName" .Compiler}} {{template "transition" .}}{{end}}
+{{define "paperRef"}}{{.}}{{end}}
+{{define "paperChange"}}{{template "paperRef" .OldPaper}} → {{template "paperRef" .NewPaper}}{{end}}`
+
+// SupportText gives the display text of a support cell with the extra text after it in parentheses
+func SupportText(support CompilerSupport) string {
+	display := fromNullString(support.DisplayText)
+	extra := fromNullString(support.ExtraText)
+
+	switch {
+	case extra == "":
+		return display
+	case display == "":
+		return "(" + extra + ")"
+	}
+
+	return display + " (" + extra + ")"
+}
+
+// sortCompilers orders compiler keys the same way in every listing: the primary vendors of the kind first, in their
+// usual order, then the others by name
+func sortCompilers(kind string, compilers []string) []string {
+	rank := func(compiler string) int {
+		for i, primary := range primaryVendors(kind) {
+			if primary == compiler {
+				return i
+			}
+		}
+		return len(primaryVendors(kind))
+	}
+
+	sort.SliceStable(compilers, func(i, j int) bool {
+		if rank(compilers[i]) != rank(compilers[j]) {
+			return rank(compilers[i]) < rank(compilers[j])
+		}
+		return CompilerDisplayName(compilers[i]) < CompilerDisplayName(compilers[j])
+	})
+
+	return compilers
+}
+
+// ListingLines gives a listing line per delta of the change, in the order of the deltas. full shows the whole support
+// on both sides of the arrow, otherwise only what changed of it is
+func ListingLines(change Change, full bool) (result []ListingLine) {
+	listed := change.Previous != nil && change.Kind != ChangeNewListing
+
+	for _, delta := range change.Deltas {
+		result = append(result, ListingLine{
+			CompilerDelta: delta,
+			Listed:        listed,
+			Full:          full,
+			LevelChanged:  delta.Previous.Support != delta.Next.Support,
+			TextChanged:   !sameCompilerSupportText(&delta.Previous, &delta.Next),
+		})
+	}
+
+	return
+}
+
+// SupportTransitions phrases how the support level of every compiler of the lines changed with the transitionLine
+// template of the channel, a line each. compilers whose support level stayed the same are left out
+func SupportTransitions(options ReportOptions, channel string, lines []ListingLine) string {
+	var rendered []string
+	for _, line := range lines {
+		if line.Transition() != "" {
+			rendered = append(rendered, RenderText(options, channel, "transitionLine", line))
+		}
+	}
+
+	return strings.Join(rendered, "\n")
+}
+
+// SupportListing renders the lines with the listingLine template of the channel, a line each
+func SupportListing(options ReportOptions, channel string, lines []ListingLine) string {
+	var rendered []string
+	for _, line := range lines {
+		rendered = append(rendered, RenderText(options, channel, "listingLine", line))
+	}
+
+	return strings.Join(rendered, "\n")
+}
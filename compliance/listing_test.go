@@ -0,0 +1,33 @@
+package compliance
+
+import "testing"
+
+func TestListChangesOnly(t *testing.T) {
+	msvcPartial := testSupport(CompilerMsvc, 2, "19.20", "not bug free")
+	textChanged := sampleFeature(msvcPartial, testSupport(CompilerClang, 1, "6", ""), testSupport(CompilerMsvc, 2, "19.20", "one bug"))
+	levelChanged := sampleFeature(testSupport(CompilerClang, 1, "6", ""), testSupport(CompilerMsvc, 1, "19.20", "one bug"), testSupport(CompilerGcc, 2, "9", ""))
+
+	for _, test := range []struct {
+		what     string
+		previous *Feature
+		next     *Feature
+		report   string
+	}{
+		{"gained support", sampleFeature(), sampleSupported(), `[Support Update] C++20 - "Initializer list constructors in class template argument deduction".
+
+GCC: [no] → [yes] 9* (still some bugs)
+MSVC: [no] → [yes] 19.20
+Apple Clang: [no] → [yes] 10.0.1`},
+		{"text changed", sampleFeature(msvcPartial, testSupport(CompilerClang, 1, "6", "")), textChanged, `[Text Update] C++20 - "Initializer list constructors in class template argument deduction".
+
+MSVC: [partial] 19.20 (not bug free) → 19.20 (one bug)`},
+		{"level changed", textChanged, levelChanged, `[Support Update] C++20 - "Initializer list constructors in class template argument deduction".
+
+GCC: [no] → [partial] 9
+MSVC: [partial] → [yes] 19.20 (one bug)`},
+	} {
+		if report := ChangeReportText(ReportOptions{ChangesOnly: true}, sampleChange(t, test.previous, test.next)); trimLines(report) != test.report {
+			t.Errorf("%v: got\n%v\nwant\n%v", test.what, report, test.report)
+		}
+	}
+}
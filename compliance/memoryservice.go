@@ -0,0 +1,1113 @@
+package compliance
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// reportKey is an entry on a channel, what reports, attempts and approvals are kept by
+type reportKey struct {
+	entryID int64
+	channel string
+}
+
+type objectiveEvent struct {
+	objective string
+	timestamp time.Time
+	good      bool
+}
+
+// MemoryService keeps everything in memory instead of a database, for tests and demos of the storage mode dummy. it
+// behaves like SqliteService as far as the Service interface goes, but forgets everything once the bot stops, and
+// keeps no support transitions
+type MemoryService struct {
+	mutex sync.Mutex
+
+	//gives the timestamp of new entries. time.Now if nil
+	Now func() time.Time
+
+	entries         []Feature //in the order they were created, the id of an entry being its index plus one
+	reports         map[reportKey]string
+	aliases         map[string]string //old name to new name
+	fingerprints    map[string]string
+	validators      map[string][2]string
+	releases        map[string][]string
+	yearReviews     map[int]bool
+	objectiveEvents []objectiveEvent
+	digests         []time.Time
+	weeklySummaries []time.Time
+	ignored         map[string]IgnoredFeature
+	heartbeat       time.Time
+	posts           []ReportPost
+	attempts        map[reportKey]ReportAttempt
+	pause           *ReportingPause
+	approvals       map[reportKey]ReportApproval
+	suggestions     []Suggestion
+	watchlist       []Watch
+	discrepancies   []StoredDiscrepancy
+	snapshots       []ScrapeSnapshot //oldest first
+	aggregates      []Aggregate      //oldest first
+}
+
+func NewMemoryService() *MemoryService {
+	return &MemoryService{
+		reports:      map[reportKey]string{},
+		aliases:      map[string]string{},
+		fingerprints: map[string]string{},
+		validators:   map[string][2]string{},
+		releases:     map[string][]string{},
+		yearReviews:  map[int]bool{},
+		attempts:     map[reportKey]ReportAttempt{},
+		approvals:    map[reportKey]ReportApproval{},
+		ignored:      map[string]IgnoredFeature{},
+	}
+}
+
+// copyEntry gives a copy of a stored entry that callers can change without changing the stored one
+func copyEntry(entry *Feature) Feature {
+	result := *entry
+	result.Compilers = append([]CompilerSupport(nil), entry.Compilers...)
+	return result
+}
+
+// copyEntries copies the stored entries in order
+func copyEntries(entries []*Feature) []Feature {
+	var result []Feature
+	for _, entry := range entries {
+		result = append(result, copyEntry(entry))
+	}
+
+	return result
+}
+
+// sortByTimestamp orders entries by timestamp, keeping the order they were created in for equal ones
+func sortByTimestamp(entries []*Feature) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+}
+
+// sortListed orders entries by language, standard and name, like the latest entries are listed
+func sortListed(entries []*Feature) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Language != entries[j].Language {
+			return entries[i].Language < entries[j].Language
+		}
+		if entries[i].CppVersion != entries[j].CppVersion {
+			return entries[i].CppVersion < entries[j].CppVersion
+		}
+		return entries[i].Name < entries[j].Name
+	})
+}
+
+// entry gives the stored entry with the id, nil if there is none
+func (s *MemoryService) entry(id int64) *Feature {
+	if id < 1 || id > int64(len(s.entries)) {
+		return nil
+	}
+
+	return &s.entries[id-1]
+}
+
+// last gives the latest entry of the feature before the given time, or at it if inclusive is set, nil if there is none.
+// a nil time leaves no entry out
+func (s *MemoryService) last(language string, name string, before *time.Time, inclusive bool) *Feature {
+	var result *Feature
+	for i := range s.entries {
+		entry := &s.entries[i]
+		if entry.Language != language || entry.Name != name {
+			continue
+		}
+		if before != nil && (entry.Timestamp.After(*before) || (!inclusive && entry.Timestamp.Equal(*before))) {
+			continue
+		}
+		if result == nil || !entry.Timestamp.Before(result.Timestamp) {
+			result = entry
+		}
+	}
+
+	return result
+}
+
+// latestAt gives the latest entry of every feature listed at the given time, or listed now if it is nil, ordered like
+// they are listed
+func (s *MemoryService) latestAt(at *time.Time) []*Feature {
+	var result []*Feature
+	seen := map[[2]string]bool{}
+
+	for i := range s.entries {
+		key := [2]string{s.entries[i].Language, s.entries[i].Name}
+		if seen[key] {
+			continue
+		}
+
+		if latest := s.last(key[0], key[1], at, true); latest != nil {
+			seen[key] = true
+			if !latest.Removed {
+				result = append(result, latest)
+			}
+		}
+	}
+
+	sortListed(result)
+	return result
+}
+
+// slugFor gives the slug of the earlier entries of the feature, or a new one no other feature uses if there are none
+func (s *MemoryService) slugFor(feature *Feature) string {
+	for i := len(s.entries) - 1; i >= 0; i-- {
+		entry := &s.entries[i]
+		if entry.Language == feature.Language && entry.Name == feature.Name && entry.Slug != "" {
+			return entry.Slug
+		}
+	}
+
+	used := func(slug string) bool {
+		for i := range s.entries {
+			entry := &s.entries[i]
+			if entry.Slug == slug && !(entry.Language == feature.Language && entry.Name == feature.Name) {
+				return true
+			}
+		}
+		return false
+	}
+
+	//features sharing a paper are told apart by their name
+	base := BaseSlug(feature)
+	candidates := []string{base}
+	if nameSlug := NameSlug(feature.Name); nameSlug != "" && nameSlug != base {
+		candidates = append(candidates, base+"-"+nameSlug)
+	}
+
+	for i := 2; ; i++ {
+		for _, candidate := range candidates {
+			if !used(candidate) {
+				return candidate
+			}
+		}
+
+		candidates = []string{fmt.Sprintf("%v-%v", base, i)}
+	}
+}
+
+func (s *MemoryService) CreateEntry(ctx context.Context, feature *Feature) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	//fill automatic fields
+	feature.Timestamp = time.Now()
+	if s.Now != nil {
+		feature.Timestamp = s.Now()
+	}
+	feature.ReportedBroken = false
+	if feature.Language == "" {
+		feature.Language = LanguageCpp
+	}
+
+	//a slug given by the caller is taken over from a renamed feature
+	if feature.Slug == "" {
+		feature.Slug = s.slugFor(feature)
+	}
+
+	feature.Id = int64(len(s.entries)) + 1
+
+	//the support is stored as it is after this entry. compilers it no longer lists are gone, like the dropped cells
+	//sqlite stores
+	stored := copyEntry(feature)
+	stored.Compilers = nil
+
+	for i := range feature.Compilers {
+		support := &feature.Compilers[i]
+		support.FeatureId = feature.Id
+		support.Timestamp = feature.Timestamp
+
+		cell := *support
+		cell.Version = SupportVersion(cell.DisplayText.String)
+		stored.SetSupport(cell)
+	}
+
+	s.entries = append(s.entries, stored)
+	return nil
+}
+
+func (s *MemoryService) GetLastIfDiffers(ctx context.Context, feature *Feature) (bool, *Feature, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	language := feature.Language
+	if language == "" {
+		language = LanguageCpp
+	}
+
+	lastEntry := s.last(language, feature.Name, nil, true)
+	if lastEntry == nil { //no entry, so it differs
+		return true, nil, nil
+	}
+
+	if !meaningfulDifference(feature, lastEntry) {
+		return false, nil, nil
+	}
+
+	result := copyEntry(lastEntry)
+	return true, &result, nil
+}
+
+// GetUnreported gives the entries not reported on the channel yet
+func (s *MemoryService) GetUnreported(ctx context.Context, channel string) ([]Feature, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var result []Feature
+	for i := range s.entries {
+		if _, ok := s.reports[reportKey{s.entries[i].Id, channel}]; !ok {
+			result = append(result, copyEntry(&s.entries[i]))
+		}
+	}
+
+	return result, nil
+}
+
+// GetEntriesByID gives the entries with the given ids in the order they were stored, leaving out unknown ids
+func (s *MemoryService) GetEntriesByID(ctx context.Context, ids []int64) ([]Feature, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	wanted := map[int64]bool{}
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	var result []Feature
+	for i := range s.entries {
+		if wanted[s.entries[i].Id] {
+			result = append(result, copyEntry(&s.entries[i]))
+		}
+	}
+
+	return result, nil
+}
+
+// GetPreviousEntry gives the entry of a feature before the one with the given id, which is an entry of an earlier name
+// if the feature was renamed
+func (s *MemoryService) GetPreviousEntry(ctx context.Context, id int64) (*Feature, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	current := s.entry(id)
+	if current == nil {
+		return nil, nil
+	}
+
+	return s.previousEntry(current), nil
+}
+
+// previousEntry gives a copy of the entry before current, nil if there is none. the mutex has to be held
+func (s *MemoryService) previousEntry(current *Feature) *Feature {
+	var result *Feature
+	for i := range s.entries {
+		entry := &s.entries[i]
+		if entry.Language != current.Language || !entry.Timestamp.Before(current.Timestamp) {
+			continue
+		}
+		if entry.Name != current.Name && s.aliases[entry.Name] != current.Name {
+			continue
+		}
+		if result == nil || !entry.Timestamp.Before(result.Timestamp) {
+			result = entry
+		}
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	previous := copyEntry(result)
+	return &previous
+}
+
+// setReportStatus records the status of reporting the entry on the channel, replacing an earlier one
+func (s *MemoryService) setReportStatus(id int64, channel string, status string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.reports[reportKey{id, channel}] = status
+}
+
+// SetReported marks the entry with the given id as reported on the channel
+func (s *MemoryService) SetReported(ctx context.Context, id int64, channel string) error {
+	s.setReportStatus(id, channel, ReportStatusReported)
+	return nil
+}
+
+// SetReportSkipped marks the entry as not waiting to be reported on the channel anymore, without it having been
+func (s *MemoryService) SetReportSkipped(ctx context.Context, id int64, channel string) error {
+	s.setReportStatus(id, channel, ReportStatusSkipped)
+	return nil
+}
+
+// SetReportStatus records how reporting the entry on the channel went
+func (s *MemoryService) SetReportStatus(ctx context.Context, id int64, channel string, status string) error {
+	s.setReportStatus(id, channel, status)
+	return nil
+}
+
+// SetErrorReported marks the entry with the given id as one the maintainer was told couldn't be turned into a report
+func (s *MemoryService) SetErrorReported(ctx context.Context, id int64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if entry := s.entry(id); entry != nil {
+		entry.ReportedBroken = true
+	}
+
+	return nil
+}
+
+// SearchLatestEntries finds the latest entry of the features matching the paper or containing the name. empty arguments match nothing
+func (s *MemoryService) SearchLatestEntries(ctx context.Context, paper string, name string) ([]Feature, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var matches []*Feature
+	for _, entry := range s.latestAt(nil) {
+		if (paper != "" && strings.HasPrefix(strings.ToLower(entry.PaperName.String), strings.ToLower(paper))) ||
+			(name != "" && strings.Contains(strings.ToLower(entry.Name), strings.ToLower(name))) {
+			matches = append(matches, entry)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].CppVersion != matches[j].CppVersion {
+			return matches[i].CppVersion > matches[j].CppVersion
+		}
+		return matches[i].Name < matches[j].Name
+	})
+
+	if len(matches) > 10 {
+		matches = matches[:10]
+	}
+
+	return copyEntries(matches), nil
+}
+
+// GetLatestEntries gives the latest entry of every feature still listed
+func (s *MemoryService) GetLatestEntries(ctx context.Context) ([]Feature, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return copyEntries(s.latestAt(nil)), nil
+}
+
+// GetEntriesAt gives the latest entry of every feature listed at the given time
+func (s *MemoryService) GetEntriesAt(ctx context.Context, at time.Time) ([]Feature, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return copyEntries(s.latestAt(&at)), nil
+}
+
+// history gives the entries matching, ordered by timestamp
+func (s *MemoryService) history(matches func(entry *Feature) bool) []Feature {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var result []*Feature
+	for i := range s.entries {
+		if matches(&s.entries[i]) {
+			result = append(result, &s.entries[i])
+		}
+	}
+
+	sortByTimestamp(result)
+	return copyEntries(result)
+}
+
+// GetFeatureHistory lists all entries of the feature of the language with the name
+func (s *MemoryService) GetFeatureHistory(ctx context.Context, language string, name string) ([]Feature, error) {
+	return s.history(func(entry *Feature) bool {
+		return entry.Language == language && entry.Name == name
+	}), nil
+}
+
+// GetFeatureHistoryBySlug lists all entries with the slug, including those from before the feature was renamed
+func (s *MemoryService) GetFeatureHistoryBySlug(ctx context.Context, slug string) ([]Feature, error) {
+	return s.history(func(entry *Feature) bool {
+		return entry.Slug == slug
+	}), nil
+}
+
+func (s *MemoryService) GetEntriesSince(ctx context.Context, since time.Time) ([]Feature, error) {
+	return s.history(func(entry *Feature) bool {
+		return entry.Timestamp.After(since)
+	}), nil
+}
+
+// GetEntryChanges gives up to limit entries stored after since with ids above afterID, in the order of their ids and of
+// the language unless it is empty, each with the entry before it like GetPreviousEntry gives
+func (s *MemoryService) GetEntryChanges(ctx context.Context, language string, since time.Time, afterID int64, limit int) ([]EntryChange, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var result []EntryChange
+	for i := range s.entries {
+		entry := &s.entries[i]
+		if len(result) == limit {
+			break
+		}
+		if entry.Id <= afterID || !entry.Timestamp.After(since) || (language != "" && entry.Language != language) {
+			continue
+		}
+
+		result = append(result, EntryChange{Entry: copyEntry(entry), Previous: s.previousEntry(entry)})
+	}
+
+	return result, nil
+}
+
+// GetSupportCounts counts the current support levels of every compiler per language and version. renamed features count once
+func (s *MemoryService) GetSupportCounts(ctx context.Context) ([]SupportCount, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	latest := map[string]*Feature{}
+	for i := range s.entries {
+		entry := &s.entries[i]
+		if current, ok := latest[entry.Slug]; !ok || !entry.Timestamp.Before(current.Timestamp) {
+			latest[entry.Slug] = entry
+		}
+	}
+
+	counts := map[SupportCount]int{}
+	for _, entry := range latest {
+		if entry.Removed {
+			continue
+		}
+
+		for _, support := range entry.Compilers {
+			counts[SupportCount{Language: entry.Language, CppVersion: entry.CppVersion, Compiler: support.Compiler, Support: support.Support}]++
+		}
+	}
+
+	var result []SupportCount
+	for count, features := range counts {
+		count.Features = features
+		result = append(result, count)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Language != b.Language {
+			return a.Language < b.Language
+		}
+		if a.CppVersion != b.CppVersion {
+			return a.CppVersion < b.CppVersion
+		}
+		if a.Compiler != b.Compiler {
+			return a.Compiler < b.Compiler
+		}
+		return a.Support < b.Support
+	})
+
+	return result, nil
+}
+
+// GetLastUpdate gives the time of the newest entry, or the zero time if there are none
+func (s *MemoryService) GetLastUpdate(ctx context.Context) (time.Time, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var result time.Time
+	for i := range s.entries {
+		if s.entries[i].Timestamp.After(result) {
+			result = s.entries[i].Timestamp
+		}
+	}
+
+	return result, nil
+}
+
+// GetLastFingerprint gives the latest stored page structure of the source, or an empty string if there is none
+func (s *MemoryService) GetLastFingerprint(ctx context.Context, source string) (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.fingerprints[source], nil
+}
+
+func (s *MemoryService) StoreFingerprint(ctx context.Context, source string, data string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.fingerprints[source] = data
+	return nil
+}
+
+// StoreScrapeSnapshot keeps the snapshot and sets its id
+func (s *MemoryService) StoreScrapeSnapshot(ctx context.Context, snapshot *ScrapeSnapshot) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	snapshot.Id = 1
+	if len(s.snapshots) > 0 {
+		snapshot.Id = s.snapshots[len(s.snapshots)-1].Id + 1
+	}
+
+	stored := *snapshot
+	stored.Page = append([]byte(nil), snapshot.Page...)
+	s.snapshots = append(s.snapshots, stored)
+	sort.SliceStable(s.snapshots, func(i, j int) bool {
+		return s.snapshots[i].Timestamp.Before(s.snapshots[j].Timestamp)
+	})
+
+	return nil
+}
+
+// GetScrapeSnapshots lists up to limit snapshots of the source without their pages, newest first. an empty source
+// lists those of all sources, a limit of 0 all of them
+func (s *MemoryService) GetScrapeSnapshots(ctx context.Context, source string, limit int) ([]ScrapeSnapshot, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	result := []ScrapeSnapshot{}
+	for i := len(s.snapshots) - 1; i >= 0 && (limit <= 0 || len(result) < limit); i-- {
+		if source == "" || s.snapshots[i].Source == source {
+			listed := s.snapshots[i]
+			listed.Page = nil
+			result = append(result, listed)
+		}
+	}
+
+	return result, nil
+}
+
+// GetScrapeSnapshot gives the snapshot of the id with its page, nil if there is none
+func (s *MemoryService) GetScrapeSnapshot(ctx context.Context, id int64) (*ScrapeSnapshot, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, snapshot := range s.snapshots {
+		if snapshot.Id == id {
+			snapshot.Page = append([]byte(nil), snapshot.Page...)
+			return &snapshot, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// PruneScrapeSnapshots drops the snapshots of the source beyond the newest keep
+func (s *MemoryService) PruneScrapeSnapshots(ctx context.Context, source string, keep int) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var kept []ScrapeSnapshot
+	newer := 0
+	for i := len(s.snapshots) - 1; i >= 0; i-- {
+		if s.snapshots[i].Source == source {
+			newer++
+			if newer > keep {
+				continue
+			}
+		}
+		kept = append([]ScrapeSnapshot{s.snapshots[i]}, kept...)
+	}
+	s.snapshots = kept
+
+	return nil
+}
+
+// StoreAggregates adds the aggregates to their time series
+func (s *MemoryService) StoreAggregates(ctx context.Context, aggregates []Aggregate) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, aggregate := range aggregates {
+		aggregate.Id = int64(len(s.aggregates) + 1)
+		s.aggregates = append(s.aggregates, aggregate)
+	}
+	sort.SliceStable(s.aggregates, func(i, j int) bool {
+		return s.aggregates[i].Timestamp.Before(s.aggregates[j].Timestamp)
+	})
+
+	return nil
+}
+
+// GetLatestAggregates gives the latest stored aggregate of every compiler, standard and kind
+func (s *MemoryService) GetLatestAggregates(ctx context.Context) ([]Aggregate, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	latest := map[string]Aggregate{}
+	for _, aggregate := range s.aggregates {
+		latest[aggregate.key()] = aggregate
+	}
+
+	result := []Aggregate{}
+	for _, aggregate := range latest {
+		result = append(result, aggregate)
+	}
+	sortAggregates(result)
+
+	return result, nil
+}
+
+// GetAggregateSeries gives the stored aggregates of a compiler and standard of both kinds, oldest first
+func (s *MemoryService) GetAggregateSeries(ctx context.Context, language string, version int, compiler string) ([]Aggregate, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	result := []Aggregate{}
+	for _, aggregate := range s.aggregates {
+		if aggregate.Language == language && aggregate.CppVersion == version && aggregate.Compiler == compiler {
+			result = append(result, aggregate)
+		}
+	}
+
+	return result, nil
+}
+
+// GetHttpValidators gives the ETag and Last-Modified of the last version of the page that was stored, empty if there are none
+func (s *MemoryService) GetHttpValidators(ctx context.Context, url string) (string, string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	validators := s.validators[url]
+	return validators[0], validators[1], nil
+}
+
+func (s *MemoryService) StoreHttpValidators(ctx context.Context, url string, etag string, lastModified string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.validators[url] = [2]string{etag, lastModified}
+	return nil
+}
+
+// GetAnnouncedReleases lists the versions of the compiler that were seen in its release feed, oldest first
+func (s *MemoryService) GetAnnouncedReleases(ctx context.Context, compiler string) ([]string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]string(nil), s.releases[compiler]...), nil
+}
+
+func (s *MemoryService) StoreAnnouncedRelease(ctx context.Context, compiler string, version string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, announced := range s.releases[compiler] {
+		if announced == version {
+			return nil
+		}
+	}
+
+	s.releases[compiler] = append(s.releases[compiler], version)
+	return nil
+}
+
+// StoreFeatureAlias records that the feature called alias is now called name. a later rename of the same old name wins
+func (s *MemoryService) StoreFeatureAlias(ctx context.Context, alias string, name string, source string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.aliases[alias] = name
+	return nil
+}
+
+// GetYearReviewPosted tells if the review of the year was posted already
+func (s *MemoryService) GetYearReviewPosted(ctx context.Context, year int) (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.yearReviews[year], nil
+}
+
+func (s *MemoryService) StoreYearReviewPosted(ctx context.Context, year int) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.yearReviews[year] = true
+	return nil
+}
+
+// StoreObjectiveEvent records a good or bad event of a service level objective
+func (s *MemoryService) StoreObjectiveEvent(ctx context.Context, objective string, good bool) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.objectiveEvents = append(s.objectiveEvents, objectiveEvent{objective: objective, timestamp: time.Now(), good: good})
+	return nil
+}
+
+// GetObjectiveCounts gives the amount of good events and of all events of an objective since the given time
+func (s *MemoryService) GetObjectiveCounts(ctx context.Context, objective string, since time.Time) (good int, total int, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, event := range s.objectiveEvents {
+		if event.objective != objective || event.timestamp.Before(since) {
+			continue
+		}
+
+		total++
+		if event.good {
+			good++
+		}
+	}
+
+	return good, total, nil
+}
+
+// QueryReadOnly can't run queries, as there is no database to run them on
+func (s *MemoryService) QueryReadOnly(ctx context.Context, query string, args []interface{}, maxRows int) (*QueryResult, error) {
+	return nil, errors.New("queries need the storage mode sqlite3")
+}
+
+// GetLastDigest gives the end of the window of the last digest sent, or the zero time if none was sent yet
+func (s *MemoryService) GetLastDigest(ctx context.Context) (time.Time, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var result time.Time
+	for _, digest := range s.digests {
+		if digest.After(result) {
+			result = digest
+		}
+	}
+
+	return result, nil
+}
+
+func (s *MemoryService) StoreDigest(ctx context.Context, until time.Time, changes int) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.digests = append(s.digests, until)
+	return nil
+}
+
+// GetLastWeeklySummary gives the end of the week of the last summary posted, or the zero time if none was posted yet
+func (s *MemoryService) GetLastWeeklySummary(ctx context.Context) (time.Time, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var result time.Time
+	for _, summary := range s.weeklySummaries {
+		if summary.After(result) {
+			result = summary
+		}
+	}
+
+	return result, nil
+}
+
+// StoreWeeklySummary records that the summary of the week until the given time was posted
+func (s *MemoryService) StoreWeeklySummary(ctx context.Context, until time.Time, changes int) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.weeklySummaries = append(s.weeklySummaries, until)
+	return nil
+}
+
+// IgnoreFeature adds a feature to the ignored ones, or updates its name and reason if it is ignored already
+func (s *MemoryService) IgnoreFeature(ctx context.Context, ignored *IgnoredFeature) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.ignored[ignored.Slug] = *ignored
+	return nil
+}
+
+// UnignoreFeature reports the changes of the feature again, and tells if it was ignored
+func (s *MemoryService) UnignoreFeature(ctx context.Context, slug string) (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, ok := s.ignored[slug]
+	delete(s.ignored, slug)
+	return ok, nil
+}
+
+// GetIgnoredFeatures lists the ignored features, the most recently ignored first
+func (s *MemoryService) GetIgnoredFeatures(ctx context.Context) ([]IgnoredFeature, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	result := []IgnoredFeature{}
+	for _, ignored := range s.ignored {
+		result = append(result, ignored)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].Timestamp.Equal(result[j].Timestamp) {
+			return result[i].Timestamp.After(result[j].Timestamp)
+		}
+		return result[i].Slug < result[j].Slug
+	})
+
+	return result, nil
+}
+
+func (s *MemoryService) StoreReportPost(ctx context.Context, post *ReportPost) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i := range s.posts {
+		if s.posts[i].TweetID == post.TweetID {
+			s.posts[i] = *post
+			return nil
+		}
+	}
+
+	s.posts = append(s.posts, *post)
+	return nil
+}
+
+// GetReportPostsSince gives the reports posted after the given time, oldest first
+func (s *MemoryService) GetReportPostsSince(ctx context.Context, since time.Time) ([]ReportPost, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var result []ReportPost
+	for _, post := range s.posts {
+		if post.Timestamp.After(since) {
+			result = append(result, post)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Timestamp.Before(result[j].Timestamp)
+	})
+
+	return result, nil
+}
+
+func (s *MemoryService) UpdateReportPostEngagement(ctx context.Context, tweetID int64, likes int, retweets int) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i := range s.posts {
+		if s.posts[i].TweetID == tweetID {
+			s.posts[i].Likes = likes
+			s.posts[i].Retweets = retweets
+		}
+	}
+
+	return nil
+}
+
+// GetReportAttempt gives the failed attempts of reporting the entry to the channel, nil if there were none
+func (s *MemoryService) GetReportAttempt(ctx context.Context, featureID int64, channel string) (*ReportAttempt, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	attempt, ok := s.attempts[reportKey{featureID, channel}]
+	if !ok {
+		return nil, nil
+	}
+
+	return &attempt, nil
+}
+
+func (s *MemoryService) StoreReportAttempt(ctx context.Context, attempt *ReportAttempt) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.attempts[reportKey{attempt.FeatureID, attempt.Channel}] = *attempt
+	return nil
+}
+
+// ClearReportAttempt forgets the failed attempts of reporting the entry to the channel
+func (s *MemoryService) ClearReportAttempt(ctx context.Context, featureID int64, channel string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.attempts, reportKey{featureID, channel})
+	return nil
+}
+
+// GetDeadLetters gives the reports that failed too often to be retried, oldest first
+func (s *MemoryService) GetDeadLetters(ctx context.Context) ([]ReportAttempt, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var result []ReportAttempt
+	for _, attempt := range s.attempts {
+		if attempt.DeadLetter {
+			result = append(result, attempt)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].LastAttempt.Before(result[j].LastAttempt)
+	})
+
+	return result, nil
+}
+
+// GetHeartbeat gives the last time the reporter was running, or the zero time if it never was
+func (s *MemoryService) GetHeartbeat(ctx context.Context) (time.Time, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.heartbeat, nil
+}
+
+func (s *MemoryService) StoreHeartbeat(ctx context.Context, at time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.heartbeat = at
+	return nil
+}
+
+// GetReportingPause gives the last time safe mode paused reporting, nil if it never did
+func (s *MemoryService) GetReportingPause(ctx context.Context) (*ReportingPause, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.pause == nil {
+		return nil, nil
+	}
+
+	pause := *s.pause
+	return &pause, nil
+}
+
+// PauseReporting stores that safe mode paused reporting, keeping the entries acknowledged before
+func (s *MemoryService) PauseReporting(ctx context.Context, at time.Time, pending int) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	pause := &ReportingPause{PausedAt: at, Pending: pending}
+	if s.pause != nil {
+		pause.AcknowledgedID = s.pause.AcknowledgedID
+	}
+
+	s.pause = pause
+	return nil
+}
+
+// GetReportApproval gives the report of the entry waiting for or decided by the maintainer, nil if there is none
+func (s *MemoryService) GetReportApproval(ctx context.Context, featureID int64, channel string) (*ReportApproval, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	approval, ok := s.approvals[reportKey{featureID, channel}]
+	if !ok {
+		return nil, nil
+	}
+
+	return &approval, nil
+}
+
+// StoreReportApproval stores a rendered report to wait for the maintainer
+func (s *MemoryService) StoreReportApproval(ctx context.Context, approval *ReportApproval) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.approvals[reportKey{approval.FeatureID, approval.Channel}] = *approval
+	return nil
+}
+
+// StoreSuggestion stores a new pending suggestion and sets its id
+func (s *MemoryService) StoreSuggestion(ctx context.Context, suggestion *Suggestion) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	suggestion.Id = int64(len(s.suggestions)) + 1
+	suggestion.Timestamp = time.Now()
+	suggestion.Status = SuggestionPending
+
+	s.suggestions = append(s.suggestions, *suggestion)
+	return nil
+}
+
+// GetWatchlist lists the watch-list, including the entries whose feature was listed already
+func (s *MemoryService) GetWatchlist(ctx context.Context) ([]Watch, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]Watch(nil), s.watchlist...), nil
+}
+
+// SetWatchListed records the entry the watched feature was first listed with
+func (s *MemoryService) SetWatchListed(ctx context.Context, watchID int64, entryID int64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i := range s.watchlist {
+		if s.watchlist[i].Id == watchID {
+			s.watchlist[i].ListedEntryId.Int64 = entryID
+			s.watchlist[i].ListedEntryId.Valid = true
+		}
+	}
+
+	return nil
+}
+
+// StoreDiscrepancies records what the probe found this time: discrepancies without an open record get one, and open
+// records of discrepancies no longer found are resolved. gives the discrepancies that were newly recorded
+func (s *MemoryService) StoreDiscrepancies(ctx context.Context, probe string, discrepancies []Discrepancy) ([]Discrepancy, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	isOpen := map[string]bool{}
+	for _, stored := range s.discrepancies {
+		if stored.Probe == probe && !stored.Resolved.Valid {
+			isOpen[stored.Key] = true
+		}
+	}
+
+	now := time.Now()
+	found := map[string]bool{}
+	var added []Discrepancy
+
+	for _, discrepancy := range discrepancies {
+		key := discrepancy.Key()
+		found[key] = true
+		if isOpen[key] {
+			continue
+		}
+
+		s.discrepancies = append(s.discrepancies, StoredDiscrepancy{
+			Id:       int64(len(s.discrepancies)) + 1,
+			Probe:    probe,
+			Key:      key,
+			Language: languageOrCpp(discrepancy.Feature.Language),
+			Slug:     discrepancy.Feature.Slug,
+			Compiler: discrepancy.Compiler,
+			Problem:  discrepancy.Problem,
+			Evidence: strings.Join(discrepancy.Evidence, "\n"),
+			Found:    now,
+		})
+
+		isOpen[key] = true
+		added = append(added, discrepancy)
+	}
+
+	for i := range s.discrepancies {
+		stored := &s.discrepancies[i]
+		if stored.Probe == probe && !stored.Resolved.Valid && !found[stored.Key] {
+			stored.Resolved.Time = now
+			stored.Resolved.Valid = true
+		}
+	}
+
+	return added, nil
+}
+
+// Ping never fails, as there is no database to reach
+func (s *MemoryService) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (s *MemoryService) Close(ctx context.Context) error {
+	return nil
+}
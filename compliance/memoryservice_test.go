@@ -0,0 +1,16 @@
+package compliance_test
+
+import (
+	"cppimpbot/compliance"
+	"cppimpbot/compliance/servicetest"
+	"testing"
+	"time"
+)
+
+func TestMemoryService(t *testing.T) {
+	servicetest.TestService(t, func(now func() time.Time) (compliance.Service, error) {
+		service := compliance.NewMemoryService()
+		service.Now = now
+		return service, nil
+	})
+}
@@ -0,0 +1,68 @@
+package compliance
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type methodMetrics struct {
+	calls    int
+	errors   int
+	duration time.Duration
+}
+
+// Metrics counts the calls, errors and time spent per Service method
+type Metrics struct {
+	methods map[string]*methodMetrics
+	mutex   sync.Mutex
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{
+		methods: map[string]*methodMetrics{},
+	}
+}
+
+// Middleware gives the middleware that records every call into the metrics
+func (m *Metrics) Middleware() Middleware {
+	return func(ctx context.Context, method string, call func() error) error {
+		start := time.Now()
+		err := call()
+		duration := time.Since(start)
+
+		m.mutex.Lock()
+		defer m.mutex.Unlock()
+
+		metrics, ok := m.methods[method]
+		if !ok {
+			metrics = &methodMetrics{}
+			m.methods[method] = metrics
+		}
+
+		metrics.calls++
+		metrics.duration += duration
+		if err != nil {
+			metrics.errors++
+		}
+
+		return err
+	}
+}
+
+// String lists the metrics of every method that was called, one per line
+func (m *Metrics) String() string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var lines []string
+	for method, metrics := range m.methods {
+		lines = append(lines, fmt.Sprintf("%v: %v calls, %v errors, %v average", method, metrics.calls, metrics.errors, metrics.duration/time.Duration(metrics.calls)))
+	}
+	sort.Strings(lines)
+
+	return strings.Join(lines, "\n")
+}
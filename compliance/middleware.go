@@ -0,0 +1,471 @@
+package compliance
+
+import (
+	"context"
+	"time"
+)
+
+// Middleware runs a call to a Service, named by its method. it has to call call at least once for the call to happen,
+// and gives back the error that should reach the caller
+type Middleware func(ctx context.Context, method string, call func() error) error
+
+type wrappedService struct {
+	next       Service
+	middleware Middleware
+}
+
+// Wrap gives a Service running every call to next through the middleware
+func Wrap(next Service, middleware Middleware) Service {
+	return &wrappedService{
+		next:       next,
+		middleware: middleware,
+	}
+}
+
+func (s *wrappedService) CreateEntry(ctx context.Context, feature *Feature) error {
+	return s.middleware(ctx, "CreateEntry", func() error {
+		return s.next.CreateEntry(ctx, feature)
+	})
+}
+
+func (s *wrappedService) GetLastIfDiffers(ctx context.Context, feature *Feature) (differs bool, last *Feature, err error) {
+	err = s.middleware(ctx, "GetLastIfDiffers", func() (err error) {
+		differs, last, err = s.next.GetLastIfDiffers(ctx, feature)
+		return
+	})
+	return
+}
+
+func (s *wrappedService) GetUnreported(ctx context.Context, channel string) (result []Feature, err error) {
+	err = s.middleware(ctx, "GetUnreported", func() (err error) {
+		result, err = s.next.GetUnreported(ctx, channel)
+		return
+	})
+	return
+}
+
+func (s *wrappedService) GetEntriesByID(ctx context.Context, ids []int64) (result []Feature, err error) {
+	err = s.middleware(ctx, "GetEntriesByID", func() (err error) {
+		result, err = s.next.GetEntriesByID(ctx, ids)
+		return
+	})
+	return
+}
+
+func (s *wrappedService) GetPreviousEntry(ctx context.Context, id int64) (result *Feature, err error) {
+	err = s.middleware(ctx, "GetPreviousEntry", func() (err error) {
+		result, err = s.next.GetPreviousEntry(ctx, id)
+		return
+	})
+	return
+}
+
+func (s *wrappedService) SetReported(ctx context.Context, id int64, channel string) error {
+	return s.middleware(ctx, "SetReported", func() error {
+		return s.next.SetReported(ctx, id, channel)
+	})
+}
+
+func (s *wrappedService) SetReportSkipped(ctx context.Context, id int64, channel string) error {
+	return s.middleware(ctx, "SetReportSkipped", func() error {
+		return s.next.SetReportSkipped(ctx, id, channel)
+	})
+}
+
+func (s *wrappedService) SetReportStatus(ctx context.Context, id int64, channel string, status string) error {
+	return s.middleware(ctx, "SetReportStatus", func() error {
+		return s.next.SetReportStatus(ctx, id, channel, status)
+	})
+}
+
+func (s *wrappedService) SetErrorReported(ctx context.Context, id int64) error {
+	return s.middleware(ctx, "SetErrorReported", func() error {
+		return s.next.SetErrorReported(ctx, id)
+	})
+}
+
+func (s *wrappedService) SearchLatestEntries(ctx context.Context, paper string, name string) (result []Feature, err error) {
+	err = s.middleware(ctx, "SearchLatestEntries", func() (err error) {
+		result, err = s.next.SearchLatestEntries(ctx, paper, name)
+		return
+	})
+	return
+}
+
+func (s *wrappedService) GetLatestEntries(ctx context.Context) (result []Feature, err error) {
+	err = s.middleware(ctx, "GetLatestEntries", func() (err error) {
+		result, err = s.next.GetLatestEntries(ctx)
+		return
+	})
+	return
+}
+
+func (s *wrappedService) GetEntriesAt(ctx context.Context, at time.Time) (result []Feature, err error) {
+	err = s.middleware(ctx, "GetEntriesAt", func() (err error) {
+		result, err = s.next.GetEntriesAt(ctx, at)
+		return
+	})
+	return
+}
+
+func (s *wrappedService) GetFeatureHistory(ctx context.Context, language string, name string) (result []Feature, err error) {
+	err = s.middleware(ctx, "GetFeatureHistory", func() (err error) {
+		result, err = s.next.GetFeatureHistory(ctx, language, name)
+		return
+	})
+	return
+}
+
+func (s *wrappedService) GetFeatureHistoryBySlug(ctx context.Context, slug string) (result []Feature, err error) {
+	err = s.middleware(ctx, "GetFeatureHistoryBySlug", func() (err error) {
+		result, err = s.next.GetFeatureHistoryBySlug(ctx, slug)
+		return
+	})
+	return
+}
+
+func (s *wrappedService) GetEntriesSince(ctx context.Context, since time.Time) (result []Feature, err error) {
+	err = s.middleware(ctx, "GetEntriesSince", func() (err error) {
+		result, err = s.next.GetEntriesSince(ctx, since)
+		return
+	})
+	return
+}
+
+func (s *wrappedService) GetEntryChanges(ctx context.Context, language string, since time.Time, afterID int64, limit int) (result []EntryChange, err error) {
+	err = s.middleware(ctx, "GetEntryChanges", func() (err error) {
+		result, err = s.next.GetEntryChanges(ctx, language, since, afterID, limit)
+		return
+	})
+	return
+}
+
+func (s *wrappedService) GetLastFingerprint(ctx context.Context, source string) (result string, err error) {
+	err = s.middleware(ctx, "GetLastFingerprint", func() (err error) {
+		result, err = s.next.GetLastFingerprint(ctx, source)
+		return
+	})
+	return
+}
+
+func (s *wrappedService) StoreFingerprint(ctx context.Context, source string, data string) error {
+	return s.middleware(ctx, "StoreFingerprint", func() error {
+		return s.next.StoreFingerprint(ctx, source, data)
+	})
+}
+
+func (s *wrappedService) StoreScrapeSnapshot(ctx context.Context, snapshot *ScrapeSnapshot) error {
+	return s.middleware(ctx, "StoreScrapeSnapshot", func() error {
+		return s.next.StoreScrapeSnapshot(ctx, snapshot)
+	})
+}
+
+func (s *wrappedService) GetScrapeSnapshots(ctx context.Context, source string, limit int) (result []ScrapeSnapshot, err error) {
+	err = s.middleware(ctx, "GetScrapeSnapshots", func() (err error) {
+		result, err = s.next.GetScrapeSnapshots(ctx, source, limit)
+		return
+	})
+	return
+}
+
+func (s *wrappedService) GetScrapeSnapshot(ctx context.Context, id int64) (result *ScrapeSnapshot, err error) {
+	err = s.middleware(ctx, "GetScrapeSnapshot", func() (err error) {
+		result, err = s.next.GetScrapeSnapshot(ctx, id)
+		return
+	})
+	return
+}
+
+func (s *wrappedService) PruneScrapeSnapshots(ctx context.Context, source string, keep int) error {
+	return s.middleware(ctx, "PruneScrapeSnapshots", func() error {
+		return s.next.PruneScrapeSnapshots(ctx, source, keep)
+	})
+}
+
+func (s *wrappedService) StoreAggregates(ctx context.Context, aggregates []Aggregate) error {
+	return s.middleware(ctx, "StoreAggregates", func() error {
+		return s.next.StoreAggregates(ctx, aggregates)
+	})
+}
+
+func (s *wrappedService) GetLatestAggregates(ctx context.Context) (result []Aggregate, err error) {
+	err = s.middleware(ctx, "GetLatestAggregates", func() (err error) {
+		result, err = s.next.GetLatestAggregates(ctx)
+		return err
+	})
+	return result, err
+}
+
+func (s *wrappedService) GetAggregateSeries(ctx context.Context, language string, version int, compiler string) (result []Aggregate, err error) {
+	err = s.middleware(ctx, "GetAggregateSeries", func() (err error) {
+		result, err = s.next.GetAggregateSeries(ctx, language, version, compiler)
+		return err
+	})
+	return result, err
+}
+
+func (s *wrappedService) GetSupportCounts(ctx context.Context) (result []SupportCount, err error) {
+	err = s.middleware(ctx, "GetSupportCounts", func() (err error) {
+		result, err = s.next.GetSupportCounts(ctx)
+		return
+	})
+	return
+}
+
+func (s *wrappedService) GetLastUpdate(ctx context.Context) (result time.Time, err error) {
+	err = s.middleware(ctx, "GetLastUpdate", func() (err error) {
+		result, err = s.next.GetLastUpdate(ctx)
+		return
+	})
+	return
+}
+
+func (s *wrappedService) GetHttpValidators(ctx context.Context, url string) (etag string, lastModified string, err error) {
+	err = s.middleware(ctx, "GetHttpValidators", func() (err error) {
+		etag, lastModified, err = s.next.GetHttpValidators(ctx, url)
+		return
+	})
+	return
+}
+
+func (s *wrappedService) StoreHttpValidators(ctx context.Context, url string, etag string, lastModified string) error {
+	return s.middleware(ctx, "StoreHttpValidators", func() error {
+		return s.next.StoreHttpValidators(ctx, url, etag, lastModified)
+	})
+}
+
+func (s *wrappedService) GetAnnouncedReleases(ctx context.Context, compiler string) (result []string, err error) {
+	err = s.middleware(ctx, "GetAnnouncedReleases", func() (err error) {
+		result, err = s.next.GetAnnouncedReleases(ctx, compiler)
+		return
+	})
+	return
+}
+
+func (s *wrappedService) StoreAnnouncedRelease(ctx context.Context, compiler string, version string) error {
+	return s.middleware(ctx, "StoreAnnouncedRelease", func() error {
+		return s.next.StoreAnnouncedRelease(ctx, compiler, version)
+	})
+}
+
+func (s *wrappedService) StoreFeatureAlias(ctx context.Context, alias string, name string, source string) error {
+	return s.middleware(ctx, "StoreFeatureAlias", func() error {
+		return s.next.StoreFeatureAlias(ctx, alias, name, source)
+	})
+}
+
+func (s *wrappedService) GetYearReviewPosted(ctx context.Context, year int) (result bool, err error) {
+	err = s.middleware(ctx, "GetYearReviewPosted", func() (err error) {
+		result, err = s.next.GetYearReviewPosted(ctx, year)
+		return
+	})
+	return
+}
+
+func (s *wrappedService) StoreYearReviewPosted(ctx context.Context, year int) error {
+	return s.middleware(ctx, "StoreYearReviewPosted", func() error {
+		return s.next.StoreYearReviewPosted(ctx, year)
+	})
+}
+
+func (s *wrappedService) StoreObjectiveEvent(ctx context.Context, objective string, good bool) error {
+	return s.middleware(ctx, "StoreObjectiveEvent", func() error {
+		return s.next.StoreObjectiveEvent(ctx, objective, good)
+	})
+}
+
+func (s *wrappedService) GetObjectiveCounts(ctx context.Context, objective string, since time.Time) (good int, total int, err error) {
+	err = s.middleware(ctx, "GetObjectiveCounts", func() (err error) {
+		good, total, err = s.next.GetObjectiveCounts(ctx, objective, since)
+		return
+	})
+	return
+}
+
+func (s *wrappedService) QueryReadOnly(ctx context.Context, query string, args []interface{}, maxRows int) (result *QueryResult, err error) {
+	err = s.middleware(ctx, "QueryReadOnly", func() (err error) {
+		result, err = s.next.QueryReadOnly(ctx, query, args, maxRows)
+		return
+	})
+	return
+}
+
+func (s *wrappedService) GetLastDigest(ctx context.Context) (result time.Time, err error) {
+	err = s.middleware(ctx, "GetLastDigest", func() (err error) {
+		result, err = s.next.GetLastDigest(ctx)
+		return
+	})
+	return
+}
+
+func (s *wrappedService) StoreDigest(ctx context.Context, until time.Time, changes int) error {
+	return s.middleware(ctx, "StoreDigest", func() error {
+		return s.next.StoreDigest(ctx, until, changes)
+	})
+}
+
+func (s *wrappedService) GetLastWeeklySummary(ctx context.Context) (result time.Time, err error) {
+	err = s.middleware(ctx, "GetLastWeeklySummary", func() (err error) {
+		result, err = s.next.GetLastWeeklySummary(ctx)
+		return
+	})
+	return
+}
+
+func (s *wrappedService) StoreWeeklySummary(ctx context.Context, until time.Time, changes int) error {
+	return s.middleware(ctx, "StoreWeeklySummary", func() error {
+		return s.next.StoreWeeklySummary(ctx, until, changes)
+	})
+}
+
+func (s *wrappedService) IgnoreFeature(ctx context.Context, ignored *IgnoredFeature) error {
+	return s.middleware(ctx, "IgnoreFeature", func() error {
+		return s.next.IgnoreFeature(ctx, ignored)
+	})
+}
+
+func (s *wrappedService) UnignoreFeature(ctx context.Context, slug string) (result bool, err error) {
+	err = s.middleware(ctx, "UnignoreFeature", func() (err error) {
+		result, err = s.next.UnignoreFeature(ctx, slug)
+		return
+	})
+	return
+}
+
+func (s *wrappedService) GetIgnoredFeatures(ctx context.Context) (result []IgnoredFeature, err error) {
+	err = s.middleware(ctx, "GetIgnoredFeatures", func() (err error) {
+		result, err = s.next.GetIgnoredFeatures(ctx)
+		return
+	})
+	return
+}
+
+func (s *wrappedService) StoreReportPost(ctx context.Context, post *ReportPost) error {
+	return s.middleware(ctx, "StoreReportPost", func() error {
+		return s.next.StoreReportPost(ctx, post)
+	})
+}
+
+func (s *wrappedService) GetReportPostsSince(ctx context.Context, since time.Time) (result []ReportPost, err error) {
+	err = s.middleware(ctx, "GetReportPostsSince", func() (err error) {
+		result, err = s.next.GetReportPostsSince(ctx, since)
+		return
+	})
+	return
+}
+
+func (s *wrappedService) UpdateReportPostEngagement(ctx context.Context, tweetID int64, likes int, retweets int) error {
+	return s.middleware(ctx, "UpdateReportPostEngagement", func() error {
+		return s.next.UpdateReportPostEngagement(ctx, tweetID, likes, retweets)
+	})
+}
+
+func (s *wrappedService) GetReportAttempt(ctx context.Context, featureID int64, channel string) (attempt *ReportAttempt, err error) {
+	err = s.middleware(ctx, "GetReportAttempt", func() (err error) {
+		attempt, err = s.next.GetReportAttempt(ctx, featureID, channel)
+		return
+	})
+	return
+}
+
+func (s *wrappedService) StoreReportAttempt(ctx context.Context, attempt *ReportAttempt) error {
+	return s.middleware(ctx, "StoreReportAttempt", func() error {
+		return s.next.StoreReportAttempt(ctx, attempt)
+	})
+}
+
+func (s *wrappedService) ClearReportAttempt(ctx context.Context, featureID int64, channel string) error {
+	return s.middleware(ctx, "ClearReportAttempt", func() error {
+		return s.next.ClearReportAttempt(ctx, featureID, channel)
+	})
+}
+
+func (s *wrappedService) GetDeadLetters(ctx context.Context) (attempts []ReportAttempt, err error) {
+	err = s.middleware(ctx, "GetDeadLetters", func() (err error) {
+		attempts, err = s.next.GetDeadLetters(ctx)
+		return
+	})
+	return
+}
+
+func (s *wrappedService) GetHeartbeat(ctx context.Context) (timestamp time.Time, err error) {
+	err = s.middleware(ctx, "GetHeartbeat", func() (err error) {
+		timestamp, err = s.next.GetHeartbeat(ctx)
+		return
+	})
+	return
+}
+
+func (s *wrappedService) StoreHeartbeat(ctx context.Context, at time.Time) error {
+	return s.middleware(ctx, "StoreHeartbeat", func() error {
+		return s.next.StoreHeartbeat(ctx, at)
+	})
+}
+
+func (s *wrappedService) GetReportingPause(ctx context.Context) (pause *ReportingPause, err error) {
+	err = s.middleware(ctx, "GetReportingPause", func() (err error) {
+		pause, err = s.next.GetReportingPause(ctx)
+		return
+	})
+	return
+}
+
+func (s *wrappedService) PauseReporting(ctx context.Context, at time.Time, pending int) error {
+	return s.middleware(ctx, "PauseReporting", func() error {
+		return s.next.PauseReporting(ctx, at, pending)
+	})
+}
+
+func (s *wrappedService) GetReportApproval(ctx context.Context, featureID int64, channel string) (approval *ReportApproval, err error) {
+	err = s.middleware(ctx, "GetReportApproval", func() (err error) {
+		approval, err = s.next.GetReportApproval(ctx, featureID, channel)
+		return
+	})
+	return
+}
+
+func (s *wrappedService) StoreReportApproval(ctx context.Context, approval *ReportApproval) error {
+	return s.middleware(ctx, "StoreReportApproval", func() error {
+		return s.next.StoreReportApproval(ctx, approval)
+	})
+}
+
+func (s *wrappedService) StoreSuggestion(ctx context.Context, suggestion *Suggestion) error {
+	return s.middleware(ctx, "StoreSuggestion", func() error {
+		return s.next.StoreSuggestion(ctx, suggestion)
+	})
+}
+
+func (s *wrappedService) GetWatchlist(ctx context.Context) (result []Watch, err error) {
+	err = s.middleware(ctx, "GetWatchlist", func() (err error) {
+		result, err = s.next.GetWatchlist(ctx)
+		return
+	})
+	return
+}
+
+func (s *wrappedService) SetWatchListed(ctx context.Context, watchID int64, entryID int64) error {
+	return s.middleware(ctx, "SetWatchListed", func() error {
+		return s.next.SetWatchListed(ctx, watchID, entryID)
+	})
+}
+
+func (s *wrappedService) StoreDiscrepancies(ctx context.Context, probe string, discrepancies []Discrepancy) (result []Discrepancy, err error) {
+	err = s.middleware(ctx, "StoreDiscrepancies", func() (err error) {
+		result, err = s.next.StoreDiscrepancies(ctx, probe, discrepancies)
+		return
+	})
+	return
+}
+
+func (s *wrappedService) Ping(ctx context.Context) error {
+	return s.middleware(ctx, "Ping", func() error {
+		return s.next.Ping(ctx)
+	})
+}
+
+func (s *wrappedService) Close(ctx context.Context) error {
+	return s.middleware(ctx, "Close", func() error {
+		return s.next.Close(ctx)
+	})
+}
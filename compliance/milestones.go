@@ -0,0 +1,105 @@
+package compliance
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+// MilestoneTemplate phrases the milestones of a percentage, 0 for those without a template of their own. Template is a
+// text/template executed on a MilestoneData
+type MilestoneTemplate struct {
+	Percent  int
+	Template string
+}
+
+// MilestoneData is what milestone templates can use
+type MilestoneData struct {
+	Compiler   string //display name of the compiler, like GCC
+	Language   string //name of the language, like C++ or C
+	Standard   string //like C++20 or C23
+	CppVersion int
+	Kind       string //core language or library
+	Percent    int    //the milestone reached
+	Full       int    //features fully supported
+	Partial    int    //features partially supported
+	Total      int    //features of the standard of the kind
+	Remaining  int    //features not fully supported yet
+}
+
+// DefaultMilestoneTemplates are the built-in phrasings, celebrating the halfway mark, the last stretch and completing a
+// standard apart from the other milestones
+var DefaultMilestoneTemplates = []MilestoneTemplate{
+	{0, "[Milestone] {{.Compiler}} reaches {{.Percent}}% {{.Standard}} {{.Kind}} support, {{.Full}} of {{.Total}} features are fully supported."},
+	{50, "[Milestone] Halfway there! {{.Compiler}} fully supports {{.Full}} of the {{.Total}} {{.Standard}} {{.Kind}} features."},
+	{90, "[Milestone] Almost there! {{.Compiler}} reaches {{.Percent}}% {{.Standard}} {{.Kind}} support, with only {{.Remaining}} {{if eq .Remaining 1}}feature{{else}}features{{end}} left to go."},
+	{100, "[Milestone] 🎉 {{.Compiler}} completes {{.Standard}} {{.Kind}} support! {{if eq .Total 1}}Its only feature is{{else}}All {{.Total}} features are{{end}} fully supported."},
+}
+
+// MilestoneTemplates renders milestones with the template of their percentage
+type MilestoneTemplates struct {
+	templates map[int]*template.Template
+}
+
+// NewMilestoneTemplates parses the built-in templates and the configured ones, which replace those of the same
+// percentage
+func NewMilestoneTemplates(configured []MilestoneTemplate) (*MilestoneTemplates, error) {
+	result := &MilestoneTemplates{templates: map[int]*template.Template{}}
+
+	for _, milestone := range DefaultMilestoneTemplates {
+		result.templates[milestone.Percent] = template.Must(template.New(fmt.Sprint(milestone.Percent)).Parse(milestone.Template))
+	}
+
+	seen := map[int]bool{}
+	for _, milestone := range configured {
+		if milestone.Percent < 0 || milestone.Percent > 100 {
+			return nil, fmt.Errorf("milestone template of %v%% is out of range, expected 0 to 100", milestone.Percent)
+		}
+		if seen[milestone.Percent] {
+			return nil, fmt.Errorf("milestone template of %v%% configured more than once", milestone.Percent)
+		}
+		seen[milestone.Percent] = true
+
+		parsed, err := template.New(fmt.Sprint(milestone.Percent)).Parse(milestone.Template)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid milestone template of %v%%", milestone.Percent)
+		}
+		result.templates[milestone.Percent] = parsed
+	}
+
+	return result, nil
+}
+
+func milestoneData(milestone Milestone) MilestoneData {
+	aggregate := milestone.Aggregate
+
+	return MilestoneData{
+		Compiler:   CompilerDisplayName(aggregate.Compiler),
+		Language:   LanguageName(aggregate.Language),
+		Standard:   StandardName(aggregate.Language, aggregate.CppVersion),
+		CppVersion: aggregate.CppVersion,
+		Kind:       KindName(aggregate.Kind),
+		Percent:    milestone.Percent,
+		Full:       aggregate.Full,
+		Partial:    aggregate.Partial,
+		Total:      aggregate.Total,
+		Remaining:  aggregate.Total - aggregate.Full,
+	}
+}
+
+// Render phrases a milestone for twitter, like "[Milestone] 🎉 GCC completes C++20 core language support! ..."
+func (t *MilestoneTemplates) Render(milestone Milestone) (string, error) {
+	parsed, ok := t.templates[milestone.Percent]
+	if !ok {
+		parsed = t.templates[0]
+	}
+
+	var buffer bytes.Buffer
+	if err := parsed.Execute(&buffer, milestoneData(milestone)); err != nil {
+		return "", errors.Wrapf(err, "could not render milestone template of %v%%", milestone.Percent)
+	}
+
+	return twitterTrimmed(buffer.String()), nil
+}
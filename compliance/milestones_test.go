@@ -0,0 +1,50 @@
+package compliance
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMilestoneTemplates(t *testing.T) {
+	templates, err := NewMilestoneTemplates([]MilestoneTemplate{
+		{Percent: 75, Template: "[Milestone] Three quarters of {{.Standard}} {{.Kind}} done in {{.Compiler}}, {{.Remaining}} to go"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		milestone Milestone
+		text      string
+	}{
+		{Milestone{Aggregate: Aggregate{Language: LanguageCpp, CppVersion: 26, Kind: KindCompiler, Compiler: CompilerClang, Full: 6, Total: 20}, Percent: 30},
+			"[Milestone] Clang reaches 30% C++26 core language support, 6 of 20 features are fully supported."},
+		{Milestone{Aggregate: Aggregate{Language: LanguageCpp, CppVersion: 26, Kind: KindCompiler, Compiler: CompilerGcc, Full: 10, Total: 20}, Percent: 50},
+			"[Milestone] Halfway there! GCC fully supports 10 of the 20 C++26 core language features."},
+		{Milestone{Aggregate: Aggregate{Language: LanguageCpp, CppVersion: 23, Kind: KindLibrary, Compiler: LibraryLibcxx, Full: 31, Total: 40}, Percent: 75},
+			"[Milestone] Three quarters of C++23 library done in libc++, 9 to go"},
+		{Milestone{Aggregate: Aggregate{Language: LanguageC, CppVersion: 23, Kind: KindCompiler, Compiler: CompilerMsvc, Full: 19, Total: 20}, Percent: 90},
+			"[Milestone] Almost there! MSVC reaches 90% C23 core language support, with only 1 feature left to go."},
+		{Milestone{Aggregate: Aggregate{Language: LanguageCpp, CppVersion: 20, Kind: KindCompiler, Compiler: CompilerGcc, Full: 1, Total: 1}, Percent: 100},
+			"[Milestone] 🎉 GCC completes C++20 core language support! Its only feature is fully supported."},
+	} {
+		if text, err := templates.Render(test.milestone); text != test.text || err != nil {
+			t.Errorf("%v%%: got %q, %v, want %q", test.milestone.Percent, text, err, test.text)
+		}
+	}
+}
+
+func TestMilestoneTemplatesInvalid(t *testing.T) {
+	for _, test := range []struct {
+		templates []MilestoneTemplate
+		err       string
+	}{
+		{[]MilestoneTemplate{{Percent: 101, Template: "too much"}}, "out of range"},
+		{[]MilestoneTemplate{{Percent: 50, Template: "{{.Compiler"}}, "invalid milestone template of 50%"},
+		{[]MilestoneTemplate{{Percent: 50, Template: "a"}, {Percent: 50, Template: "b"}}, "configured more than once"},
+	} {
+		if _, err := NewMilestoneTemplates(test.templates); err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%+v: got %v, want an error about %v", test.templates, err, test.err)
+		}
+	}
+}
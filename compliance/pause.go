@@ -0,0 +1,69 @@
+package compliance
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ReportingPause is set when safe mode stops the reporter, which stays paused until the maintainer resumes it
+type ReportingPause struct {
+	PausedAt       time.Time    `db:"paused_at"`
+	Pending        int          `db:"pending"` //amount of reports that tripped safe mode
+	ResumedAt      sql.NullTime `db:"resumed_at"`
+	AcknowledgedID int64        `db:"acknowledged_id"` //newest entry let through on resuming, only later ones count against safe mode
+}
+
+// Paused tells if the reporter has to wait for the maintainer
+func (p *ReportingPause) Paused() bool {
+	return p != nil && !p.ResumedAt.Valid
+}
+
+// Acknowledged tells if the entry was waiting when the maintainer last resumed reporting
+func (p *ReportingPause) Acknowledged(entryID int64) bool {
+	return p != nil && entryID <= p.AcknowledgedID
+}
+
+// GetReportingPause gives the last time safe mode paused reporting, nil if it never did
+func (s *SqliteService) GetReportingPause(ctx context.Context) (*ReportingPause, error) {
+	result := &ReportingPause{}
+
+	if err := s.db.GetContext(ctx, result, "SELECT paused_at, pending, resumed_at, acknowledged_id FROM reporting_pauses WHERE id=1"); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "could not get reporting pause")
+	}
+
+	return result, nil
+}
+
+// PauseReporting stores that safe mode paused reporting, keeping the entries acknowledged before
+func (s *SqliteService) PauseReporting(ctx context.Context, at time.Time, pending int) error {
+	query := `INSERT OR REPLACE INTO reporting_pauses (id, paused_at, pending, resumed_at, acknowledged_id)
+		VALUES(1, ?, ?, NULL, COALESCE((SELECT acknowledged_id FROM reporting_pauses WHERE id=1), 0))`
+
+	if _, err := s.db.ExecContext(ctx, query, at, pending); err != nil {
+		return errors.Wrap(err, "failed to pause reporting")
+	}
+
+	return nil
+}
+
+// ResumeReporting lifts the pause, letting the entries up to acknowledgedID through without tripping safe mode again
+func (s *SqliteService) ResumeReporting(ctx context.Context, acknowledgedID int64) error {
+	result, err := s.db.ExecContext(ctx, "UPDATE reporting_pauses SET resumed_at=?, acknowledged_id=MAX(acknowledged_id, ?) WHERE id=1 AND resumed_at IS NULL", time.Now(), acknowledgedID)
+	if err != nil {
+		return errors.Wrap(err, "failed to resume reporting")
+	}
+
+	if affected, err := result.RowsAffected(); err != nil {
+		return errors.Wrap(err, "failed to resume reporting")
+	} else if affected == 0 {
+		return errors.New("reporting is not paused")
+	}
+
+	return nil
+}
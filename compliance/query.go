@@ -0,0 +1,115 @@
+package compliance
+
+import (
+	"context"
+	"database/sql/driver"
+	"log/slog"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// QueryResult is what a read-only query gave, every row holding the values of the columns in order
+type QueryResult struct {
+	Columns   []string        `json:"columns"`
+	Rows      [][]interface{} `json:"rows"`
+	Truncated bool            `json:"truncated"`
+}
+
+// CheckReadOnly allows a single SELECT statement, optionally preceded by a WITH clause. it is a keyword filter that
+// gives a clear error for obvious writes, not what keeps the database safe. that is the read-only connection
+func CheckReadOnly(query string) error {
+	query = strings.TrimSpace(query)
+	query = strings.TrimSuffix(query, ";")
+
+	if strings.Contains(query, ";") {
+		return errors.New("only a single statement is allowed")
+	}
+
+	fields := strings.Fields(strings.ToUpper(query))
+	if len(fields) == 0 {
+		return errors.New("empty query")
+	}
+
+	if fields[0] != "SELECT" && fields[0] != "WITH" {
+		return errors.New("only SELECT queries are allowed")
+	}
+
+	for _, field := range fields {
+		switch field {
+		case "INSERT", "UPDATE", "DELETE", "REPLACE", "DROP", "ALTER", "CREATE", "ATTACH", "DETACH", "PRAGMA", "VACUUM":
+			return errors.Errorf("%v is not allowed", field)
+		}
+	}
+
+	return nil
+}
+
+// QueryReadOnly runs a SELECT given by the user with the args bound in order, giving at most maxRows rows. the service
+// should be opened with util.SqliteConnectReadOnly. the query also runs on a connection switched to query_only, so
+// sqlite itself refuses anything that writes even if it was not
+func (s *SqliteService) QueryReadOnly(ctx context.Context, query string, args []interface{}, maxRows int) (*QueryResult, error) {
+	if err := CheckReadOnly(query); err != nil {
+		return nil, err
+	}
+
+	conn, err := s.db.DB.Conn(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get a connection")
+	}
+	defer conn.Close()
+
+	//connections of util.SqliteConnectReadOnly are query_only already and stay that way
+	var queryOnly bool
+	if err := conn.QueryRowContext(ctx, "PRAGMA query_only").Scan(&queryOnly); err != nil {
+		return nil, errors.Wrap(err, "could not check if the connection is read-only")
+	}
+
+	if !queryOnly {
+		if _, err := conn.ExecContext(ctx, "PRAGMA query_only = ON"); err != nil {
+			return nil, errors.Wrap(err, "could not make the connection read-only")
+		}
+		//the connection goes back to the pool, so the other queries can write again. one that can't be reset is dropped
+		defer func() {
+			if _, err := conn.ExecContext(context.Background(), "PRAGMA query_only = OFF"); err != nil {
+				slog.Warn("could not reset query_only, dropping the connection", "err", err)
+				conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+			}
+		}()
+	}
+
+	rows, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "query failed")
+	}
+	defer rows.Close()
+
+	result := &QueryResult{Rows: [][]interface{}{}}
+
+	if result.Columns, err = rows.Columns(); err != nil {
+		return nil, errors.Wrap(err, "could not get columns")
+	}
+
+	for rows.Next() {
+		if len(result.Rows) >= maxRows {
+			result.Truncated = true
+			break
+		}
+
+		row, err := sqlx.SliceScan(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not scan row")
+		}
+
+		for i, value := range row {
+			if bytes, ok := value.([]byte); ok {
+				row[i] = string(bytes)
+			}
+		}
+
+		result.Rows = append(result.Rows, row)
+	}
+
+	return result, rows.Err()
+}
@@ -0,0 +1,121 @@
+package compliance
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// Queue holds the ids of the entries waiting to be reported on each channel. ids come out in the order the entries
+// were stored and stay queued until acknowledged, so a report that fails or is cut short by a crash is tried again
+type Queue interface {
+	Push(ctx context.Context, channel string, ids ...int64) error
+	Pending(ctx context.Context, channel string) ([]int64, error)
+	Ack(ctx context.Context, channel string, id int64) error
+	Close() error
+}
+
+// QueuedService keeps the entries waiting to be reported in an external queue instead of working them out from the
+// reports table. the reports table is still written, so the history of what was reported stays in the database
+type QueuedService struct {
+	Service
+	queue    Queue
+	channels []string
+}
+
+func NewQueuedService(next Service, queue Queue, channels []string) *QueuedService {
+	return &QueuedService{
+		Service:  next,
+		queue:    queue,
+		channels: channels,
+	}
+}
+
+// Sync makes the queue of every channel hold exactly the entries the database has as unreported. entries stored by
+// other commands, like scrape, only make it into the queue this way
+func (s *QueuedService) Sync(ctx context.Context) error {
+	for _, channel := range s.channels {
+		unreported, err := s.Service.GetUnreported(ctx, channel)
+		if err != nil {
+			return err
+		}
+
+		waiting := map[int64]bool{}
+		var ids []int64
+		for _, entry := range unreported {
+			waiting[entry.Id] = true
+			ids = append(ids, entry.Id)
+		}
+
+		if len(ids) > 0 {
+			if err := s.queue.Push(ctx, channel, ids...); err != nil {
+				return errors.Wrapf(err, "could not queue unreported entries of %v", channel)
+			}
+		}
+
+		pending, err := s.queue.Pending(ctx, channel)
+		if err != nil {
+			return err
+		}
+
+		for _, id := range pending {
+			if !waiting[id] {
+				if err := s.queue.Ack(ctx, channel, id); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *QueuedService) CreateEntry(ctx context.Context, feature *Feature) error {
+	if err := s.Service.CreateEntry(ctx, feature); err != nil {
+		return err
+	}
+
+	for _, channel := range s.channels {
+		if err := s.queue.Push(ctx, channel, feature.Id); err != nil {
+			return errors.Wrapf(err, "could not queue entry %v for %v", feature.Id, channel)
+		}
+	}
+
+	return nil
+}
+
+func (s *QueuedService) GetUnreported(ctx context.Context, channel string) ([]Feature, error) {
+	ids, err := s.queue.Pending(ctx, channel)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Service.GetEntriesByID(ctx, ids)
+}
+
+// SetReported stores the report before acknowledging it, so a crash in between reports the entry again rather than
+// losing it
+func (s *QueuedService) SetReported(ctx context.Context, id int64, channel string) error {
+	if err := s.Service.SetReported(ctx, id, channel); err != nil {
+		return err
+	}
+
+	return s.queue.Ack(ctx, channel, id)
+}
+
+// SetReportSkipped stores the entry as skipped before acknowledging it, like SetReported
+func (s *QueuedService) SetReportSkipped(ctx context.Context, id int64, channel string) error {
+	if err := s.Service.SetReportSkipped(ctx, id, channel); err != nil {
+		return err
+	}
+
+	return s.queue.Ack(ctx, channel, id)
+}
+
+func (s *QueuedService) Close(ctx context.Context) error {
+	if err := s.queue.Close(); err != nil {
+		return err
+	}
+
+	return s.Service.Close(ctx)
+}
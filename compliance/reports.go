@@ -0,0 +1,14 @@
+package compliance
+
+// channels entries are reported on, each tracked on its own
+const (
+	ChannelTwitter = "twitter"
+)
+
+// statuses of an entry on a channel once it is no longer waiting to be reported
+const (
+	ReportStatusReported = "reported"
+	ReportStatusSkipped  = "skipped" //the maintainer decided against reporting it when resuming from safe mode
+	ReportStatusFailed   = "failed"  //posting it on the channel failed. only reporters besides twitter give up on it
+	ReportStatusBlocked  = "blocked" //not posted, as a channel it is posted after with ReportAfter didn't post it
+)
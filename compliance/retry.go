@@ -0,0 +1,38 @@
+package compliance
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// IsBusy tells if an error is sqlite refusing a call because another connection holds a lock, which passes by itself
+func IsBusy(err error) bool {
+	return err != nil && (strings.Contains(err.Error(), "database is locked") || strings.Contains(err.Error(), "database table is locked"))
+}
+
+// RetryMiddleware gives a middleware that tries failed calls again, up to attempts calls in total, if retryable says the
+// error is worth it. the wait doubles after every attempt
+func RetryMiddleware(attempts int, backoff time.Duration, retryable func(error) bool) Middleware {
+	return func(ctx context.Context, method string, call func() error) error {
+		wait := backoff
+
+		for attempt := 1; ; attempt++ {
+			err := call()
+			if err == nil || attempt >= attempts || !retryable(err) {
+				return err
+			}
+
+			slog.Warn("storage call failed, retrying", "method", method, "wait", wait, "err", err)
+
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				return err
+			}
+
+			wait *= 2
+		}
+	}
+}
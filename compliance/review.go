@@ -0,0 +1,217 @@
+package compliance
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// VendorYear is the progress one compiler or library made over a year
+type VendorYear struct {
+	Compiler       string
+	NewlySupported int         //features fully supported at the end of the year that weren't at its start
+	PerVersion     map[int]int //NewlySupported per C++ version
+	BusiestMonth   time.Month  //month in which the most features became fully supported, 0 if none did
+	BusiestCount   int
+	Completed      []int //C++ versions fully supported at the end of the year that weren't at its start
+}
+
+// YearReview is the compliance progress of a year, per compiler
+type YearReview struct {
+	Year        int
+	Vendors     []VendorYear
+	NewListings int //features listed at the end of the year that weren't listed at its start
+	Removed     int //features listed at the start of the year that aren't anymore
+}
+
+// fullySupported gives the slugs of the features the vendor fully supports
+func fullySupported(features []Feature, vendor string) map[string]bool {
+	result := map[string]bool{}
+
+	for i := range features {
+		if support := features[i].SupportFor(vendor); support != nil && support.Support == SupportYes {
+			result[features[i].Slug] = true
+		}
+	}
+
+	return result
+}
+
+// completedVersions gives the C++ versions whose features of the vendors kind are all fully supported by the vendor
+func completedVersions(features []Feature, vendor string, kind string) map[int]bool {
+	result := map[int]bool{}
+	incomplete := map[int]bool{}
+
+	for i := range features {
+		if features[i].Kind() != kind {
+			continue
+		}
+
+		version := features[i].CppVersion
+		if support := features[i].SupportFor(vendor); support != nil && support.Support == SupportYes {
+			result[version] = true
+		} else {
+			incomplete[version] = true
+		}
+	}
+
+	for version := range incomplete {
+		delete(result, version)
+	}
+
+	return result
+}
+
+// ReviewYear sums up the progress of a year. snapshots[0] are the features current at the start of the year and
+// snapshots[i] those current at the end of month i, so a year that isn't over yet can be reviewed with fewer than 13
+func ReviewYear(year int, snapshots [][]Feature) YearReview {
+	review := YearReview{Year: year}
+	if len(snapshots) < 2 {
+		return review
+	}
+
+	start, end := snapshots[0], snapshots[len(snapshots)-1]
+
+	startSlugs := map[string]bool{}
+	for _, feature := range start {
+		startSlugs[feature.Slug] = true
+	}
+	endSlugs := map[string]bool{}
+	for _, feature := range end {
+		endSlugs[feature.Slug] = true
+		if !startSlugs[feature.Slug] {
+			review.NewListings++
+		}
+	}
+	for slug := range startSlugs {
+		if !endSlugs[slug] {
+			review.Removed++
+		}
+	}
+
+	//vendors in the order they were last listed, compilers before libraries and the primary ones first
+	var vendors []string
+	vendorKinds := map[string]string{}
+	for _, snapshot := range [][]Feature{end, start} {
+		for _, feature := range snapshot {
+			for _, support := range feature.Compilers {
+				if _, ok := vendorKinds[support.Compiler]; !ok {
+					vendorKinds[support.Compiler] = support.Kind
+					vendors = append(vendors, support.Compiler)
+				}
+			}
+		}
+	}
+	sort.SliceStable(vendors, func(i, j int) bool {
+		a, b := vendors[i], vendors[j]
+		if vendorKinds[a] != vendorKinds[b] {
+			return vendorKinds[a] == KindCompiler
+		}
+		return isPrimaryVendor(vendorKinds[a], a) && !isPrimaryVendor(vendorKinds[b], b)
+	})
+
+	for _, vendor := range vendors {
+		vendorYear := VendorYear{Compiler: vendor, PerVersion: map[int]int{}}
+
+		before := fullySupported(start, vendor)
+		for _, feature := range end {
+			if support := feature.SupportFor(vendor); support != nil && support.Support == SupportYes && !before[feature.Slug] {
+				vendorYear.NewlySupported++
+				vendorYear.PerVersion[feature.CppVersion]++
+			}
+		}
+
+		previous := before
+		for month := 1; month < len(snapshots); month++ {
+			current := fullySupported(snapshots[month], vendor)
+
+			count := 0
+			for slug := range current {
+				if !previous[slug] {
+					count++
+				}
+			}
+			if count > vendorYear.BusiestCount {
+				vendorYear.BusiestMonth = time.Month(month)
+				vendorYear.BusiestCount = count
+			}
+
+			previous = current
+		}
+
+		completedBefore := completedVersions(start, vendor, vendorKinds[vendor])
+		for version := range completedVersions(end, vendor, vendorKinds[vendor]) {
+			if !completedBefore[version] {
+				vendorYear.Completed = append(vendorYear.Completed, version)
+			}
+		}
+		sort.Ints(vendorYear.Completed)
+
+		review.Vendors = append(review.Vendors, vendorYear)
+	}
+
+	return review
+}
+
+// NewlySupported gives the sum of the features that became fully supported, over all vendors
+func (r *YearReview) NewlySupported() (result int) {
+	for _, vendor := range r.Vendors {
+		result += vendor.NewlySupported
+	}
+
+	return
+}
+
+func (v *VendorYear) perVersionText() string {
+	var versions []int
+	for version := range v.PerVersion {
+		versions = append(versions, version)
+	}
+	sort.Ints(versions)
+
+	var parts []string
+	for _, version := range versions {
+		parts = append(parts, fmt.Sprintf("C++%v: %v", version, v.PerVersion[version]))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// YearReviewToTwitterThread renders the review as the tweets of a thread, an overview followed by a tweet per vendor
+// that made any progress
+func YearReviewToTwitterThread(review YearReview) []string {
+	overview := fmt.Sprintf("[Year in Review] %v on cppreference: compilers and libraries gained full support of a feature %v times and %v new features were listed",
+		review.Year, review.NewlySupported(), review.NewListings)
+	if review.Removed > 0 {
+		overview += fmt.Sprintf(" and %v were unlisted", review.Removed)
+	}
+	overview += "."
+
+	var vendorTweets []string
+	for _, vendor := range review.Vendors {
+		if vendor.NewlySupported == 0 && len(vendor.Completed) == 0 {
+			continue
+		}
+
+		text := fmt.Sprintf("%v in %v: %v newly supported", CompilerDisplayName(vendor.Compiler), review.Year, vendor.NewlySupported)
+		if vendor.NewlySupported > 0 {
+			text += " (" + vendor.perVersionText() + ")"
+		}
+		text += "."
+		if vendor.BusiestCount > 0 {
+			text += fmt.Sprintf("\nBusiest month: %v with %v.", vendor.BusiestMonth, vendor.BusiestCount)
+		}
+		for _, version := range vendor.Completed {
+			text += fmt.Sprintf("\nMilestone: C++%v is now fully supported!", version)
+		}
+
+		vendorTweets = append(vendorTweets, twitterTrimmed(text))
+	}
+
+	if len(vendorTweets) > 0 {
+		overview += " A thread:"
+	}
+
+	return append([]string{twitterTrimmed(overview)}, vendorTweets...)
+}
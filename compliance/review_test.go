@@ -0,0 +1,71 @@
+package compliance
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestReviewYear(t *testing.T) {
+	listed := sampleFeature()
+	partial := sampleFeature(testSupport(CompilerMsvc, 2, "19.20", "not bug free"))
+	supported := sampleSupported()
+	listedLater := sampleSupported()
+	listedLater.Name = "A feature listed in summer"
+	for _, feature := range []*Feature{listed, partial, supported} {
+		feature.Slug = "p0702"
+	}
+	listedLater.Slug = "p1234"
+
+	//listed at the start of the year, partially supported by MSVC in March and supported by all in July
+	snapshots := [][]Feature{{*listed}}
+	for month := 1; month <= 12; month++ {
+		switch {
+		case month < 3:
+			snapshots = append(snapshots, []Feature{*listed})
+		case month < 7:
+			snapshots = append(snapshots, []Feature{*partial})
+		default:
+			snapshots = append(snapshots, []Feature{*supported, *listedLater})
+		}
+	}
+
+	review := ReviewYear(2019, snapshots)
+	if review.NewListings != 1 || review.Removed != 0 || review.NewlySupported() != 7 {
+		t.Errorf("got %v new listings, %v removed and %v newly supported, want 1, 0 and 7", review.NewListings, review.Removed, review.NewlySupported())
+	}
+
+	want := []VendorYear{
+		{Compiler: CompilerGcc, NewlySupported: 2, PerVersion: map[int]int{20: 2}, BusiestMonth: time.July, BusiestCount: 2, Completed: []int{20}},
+		{Compiler: CompilerClang, NewlySupported: 1, PerVersion: map[int]int{20: 1}, BusiestMonth: time.July, BusiestCount: 1},
+		{Compiler: CompilerMsvc, NewlySupported: 2, PerVersion: map[int]int{20: 2}, BusiestMonth: time.July, BusiestCount: 2, Completed: []int{20}},
+		{Compiler: "apple_clang", NewlySupported: 2, PerVersion: map[int]int{20: 2}, BusiestMonth: time.July, BusiestCount: 2, Completed: []int{20}},
+	}
+	if !reflect.DeepEqual(review.Vendors, want) {
+		t.Errorf("got vendors\n%+v\nwant\n%+v", review.Vendors, want)
+	}
+
+	thread := YearReviewToTwitterThread(review)
+	wantThread := []string{
+		"[Year in Review] 2019 on cppreference: compilers and libraries gained full support of a feature 7 times and 1 new features were listed. A thread:",
+		"GCC in 2019: 2 newly supported (C++20: 2).\nBusiest month: July with 2.\nMilestone: C++20 is now fully supported!",
+		"Clang in 2019: 1 newly supported (C++20: 1).\nBusiest month: July with 1.",
+		"MSVC in 2019: 2 newly supported (C++20: 2).\nBusiest month: July with 2.\nMilestone: C++20 is now fully supported!",
+		"Apple Clang in 2019: 2 newly supported (C++20: 2).\nBusiest month: July with 2.\nMilestone: C++20 is now fully supported!",
+	}
+	if !reflect.DeepEqual(thread, wantThread) {
+		t.Errorf("got thread %q, want %q", thread, wantThread)
+	}
+}
+
+func TestReviewYearWithoutProgress(t *testing.T) {
+	if review := ReviewYear(2019, [][]Feature{{*sampleFeature()}}); len(review.Vendors) != 0 {
+		t.Errorf("year without months has vendors %+v", review.Vendors)
+	}
+
+	listed := sampleFeature()
+	thread := YearReviewToTwitterThread(ReviewYear(2019, [][]Feature{{*listed}, {*listed}}))
+	if len(thread) != 1 || thread[0] != "[Year in Review] 2019 on cppreference: compilers and libraries gained full support of a feature 0 times and 0 new features were listed." {
+		t.Errorf("got thread %q", thread)
+	}
+}
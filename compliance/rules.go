@@ -0,0 +1,255 @@
+package compliance
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// actions of report rules
+const (
+	RuleSuppress = "suppress"
+	RuleAllow    = "allow"
+)
+
+// ReportRule suppresses or allows the reports of the changes it matches. every condition set has to match, empty ones
+// match anything. the compiler conditions are checked per changed compiler for support and text changes, so a rule
+// can suppress the report of one compiler and leave the others, and match if any compiler does for the other kinds
+type ReportRule struct {
+	Name      string   //logged when the rule suppresses a report
+	Action    string   //suppress or allow
+	Kinds     []string //kinds of change, like text or support
+	Languages []string //cpp, c or cppdr
+	Versions  []int    //versions of the standard, like 20 for C++20
+	Feature   string   //regular expression the name of the feature has to match, like "^__has_include"
+	Paper     string   //regular expression the paper of the feature has to match
+	Compilers []string //compiler and library keys, like gcc or libstdcxx
+	From      []string //support levels the compilers change from, no, partial or yes
+	To        []string //support levels the compilers change to
+}
+
+// patterns caches the compiled regular expressions of the rules, as they are matched against every reported change
+var patterns = struct {
+	compiled map[string]*regexp.Regexp
+	mutex    sync.Mutex
+}{compiled: map[string]*regexp.Regexp{}}
+
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	patterns.mutex.Lock()
+	defer patterns.mutex.Unlock()
+
+	if compiled, ok := patterns.compiled[pattern]; ok {
+		return compiled, nil
+	}
+
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	patterns.compiled[pattern] = compiled
+
+	return compiled, nil
+}
+
+func matchesPattern(pattern string, text string) (bool, error) {
+	if pattern == "" {
+		return true, nil
+	}
+
+	compiled, err := compilePattern(pattern)
+	if err != nil {
+		return false, errors.Wrapf(err, "invalid pattern '%v'", pattern)
+	}
+
+	return compiled.MatchString(text), nil
+}
+
+func containsString(list []string, value string) bool {
+	for _, entry := range list {
+		if entry == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CheckReportRules tells what is wrong with the first invalid rule, if any
+func CheckReportRules(rules []ReportRule) error {
+	for i, rule := range rules {
+		name := rule.Name
+		if name == "" {
+			name = fmt.Sprint(i + 1)
+		}
+
+		if rule.Action != RuleSuppress && rule.Action != RuleAllow {
+			return fmt.Errorf("report rule %v has action '%v', expected %v or %v", name, rule.Action, RuleSuppress, RuleAllow)
+		}
+
+		for _, kind := range rule.Kinds {
+			if !containsString(reportKinds, kind) {
+				return fmt.Errorf("report rule %v has unknown kind '%v'", name, kind)
+			}
+		}
+
+		for _, language := range rule.Languages {
+			if !IsLanguage(language) {
+				return fmt.Errorf("report rule %v has unknown language '%v'", name, language)
+			}
+		}
+
+		for _, level := range append(append([]string{}, rule.From...), rule.To...) {
+			if level != SupportLevelName(SupportNo) && level != SupportLevelName(SupportPartial) && level != SupportLevelName(SupportYes) {
+				return fmt.Errorf("report rule %v has unknown support level '%v', expected no, partial or yes", name, level)
+			}
+		}
+
+		for _, pattern := range []string{rule.Feature, rule.Paper} {
+			if _, err := compilePattern(pattern); err != nil {
+				return errors.Wrapf(err, "report rule %v has an invalid pattern", name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// matchesFeature tells if the conditions of the rule on the change itself match
+func (r *ReportRule) matchesFeature(change Change) (bool, error) {
+	language := change.Feature.Language
+	if language == "" {
+		language = LanguageCpp
+	}
+
+	if len(r.Kinds) > 0 && !containsString(r.Kinds, change.Kind) {
+		return false, nil
+	}
+	if len(r.Languages) > 0 && !containsString(r.Languages, language) {
+		return false, nil
+	}
+
+	versionMatches := len(r.Versions) == 0
+	for _, version := range r.Versions {
+		versionMatches = versionMatches || version == change.Feature.CppVersion
+	}
+	if !versionMatches {
+		return false, nil
+	}
+
+	if matches, err := matchesPattern(r.Feature, change.Feature.Name); !matches || err != nil {
+		return false, err
+	}
+
+	return matchesPattern(r.Paper, fromNullString(change.Feature.PaperName))
+}
+
+// matchesDelta tells if the conditions of the rule on the changed compilers match the delta
+func (r *ReportRule) matchesDelta(delta CompilerDelta) bool {
+	return (len(r.Compilers) == 0 || containsString(r.Compilers, delta.Compiler)) &&
+		(len(r.From) == 0 || containsString(r.From, SupportLevelName(delta.Previous.Support))) &&
+		(len(r.To) == 0 || containsString(r.To, SupportLevelName(delta.Next.Support)))
+}
+
+func (r *ReportRule) hasDeltaConditions() bool {
+	return len(r.Compilers) > 0 || len(r.From) > 0 || len(r.To) > 0
+}
+
+// firstMatch gives the first rule matching the change, and the delta if one is given, or nil if none does
+func firstMatch(rules []ReportRule, change Change, delta *CompilerDelta) (*ReportRule, error) {
+	for i := range rules {
+		rule := &rules[i]
+		if matches, err := rule.matchesFeature(change); err != nil {
+			return nil, errors.Wrapf(err, "report rule %v", rule.Name)
+		} else if !matches {
+			continue
+		}
+
+		if delta != nil {
+			if rule.matchesDelta(*delta) {
+				return rule, nil
+			}
+			continue
+		}
+
+		matches := !rule.hasDeltaConditions()
+		for _, other := range change.Deltas {
+			matches = matches || rule.matchesDelta(other)
+		}
+		if matches {
+			return rule, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// suppresses tells if the matched rule suppresses what it matched. nil matches nothing and suppresses nothing
+func suppresses(rule *ReportRule) (bool, error) {
+	if rule == nil {
+		return false, nil
+	}
+
+	switch rule.Action {
+	case RuleSuppress:
+		return true, nil
+	case RuleAllow:
+		return false, nil
+	default:
+		return false, fmt.Errorf("report rule %v has action '%v', expected %v or %v", rule.Name, rule.Action, RuleSuppress, RuleAllow)
+	}
+}
+
+// ApplyReportRules leaves out what the first rule matching it suppresses: the compilers of support and text changes,
+// which lose their kind once no compiler is left like with OnlyCompilers, and the whole change for the other kinds.
+// what no rule matches is reported. a rule with an invalid pattern or action fails the change unchanged, see
+// CheckReportRules
+func ApplyReportRules(rules []ReportRule, change Change) (Change, error) {
+	if len(rules) == 0 || change.Kind == "" {
+		return change, nil
+	}
+
+	if change.Kind != ChangeSupport && change.Kind != ChangeText {
+		rule, err := firstMatch(rules, change, nil)
+		if err != nil {
+			return change, err
+		}
+
+		suppressed, err := suppresses(rule)
+		if err != nil {
+			return change, err
+		}
+		if suppressed {
+			slog.Debug("report rule suppressed change", "rule", rule.Name, "feature", change.Feature.Name, "kind", change.Kind)
+			change.Kind = ""
+		}
+		return change, nil
+	}
+
+	var keep []string
+	for i := range change.Deltas {
+		delta := &change.Deltas[i]
+		rule, err := firstMatch(rules, change, delta)
+		if err != nil {
+			return change, err
+		}
+
+		suppressed, err := suppresses(rule)
+		if err != nil {
+			return change, err
+		}
+		if suppressed {
+			slog.Debug("report rule suppressed compiler", "rule", rule.Name, "feature", change.Feature.Name, "compiler", delta.Compiler)
+			continue
+		}
+		keep = append(keep, delta.Compiler)
+	}
+
+	if len(keep) == len(change.Deltas) {
+		return change, nil
+	}
+
+	return change.OnlyCompilers(keep), nil
+}
@@ -0,0 +1,110 @@
+package compliance
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+func testSupport(compiler string, support int, displayText string, extraText string) CompilerSupport {
+	return CompilerSupport{
+		Compiler:    compiler,
+		Kind:        KindCompiler,
+		Support:     support,
+		DisplayText: sql.NullString{String: displayText, Valid: true},
+		ExtraText:   sql.NullString{String: extraText, Valid: true},
+	}
+}
+
+func ruleFeature(name string, gcc int, clang int, gccText string) *Feature {
+	return &Feature{Name: name, CppVersion: 26, Compilers: []CompilerSupport{testSupport(CompilerGcc, gcc, "15", gccText), testSupport(CompilerClang, clang, "19", "")}}
+}
+
+func deltaCompilers(change Change) []string {
+	var compilers []string
+	for _, delta := range change.Deltas {
+		compilers = append(compilers, delta.Compiler)
+	}
+
+	return compilers
+}
+
+func TestApplyReportRules(t *testing.T) {
+	rules := []ReportRule{
+		{Name: "no text changes", Action: RuleSuppress, Kinds: []string{ChangeText}},
+		{Name: "no feature test rows", Action: RuleSuppress, Feature: "^__has_include"},
+		{Name: "full support", Action: RuleAllow, Kinds: []string{ChangeSupport}, To: []string{"yes"}},
+		{Name: "no other support changes", Action: RuleSuppress, Kinds: []string{ChangeSupport}},
+	}
+	if err := CheckReportRules(rules); err != nil {
+		t.Fatalf("valid rules: %v", err)
+	}
+
+	for _, test := range []struct {
+		what      string
+		previous  *Feature
+		next      *Feature
+		kind      string
+		compilers []string
+	}{
+		{"text change", ruleFeature("Pack indexing", 1, 0, ""), ruleFeature("Pack indexing", 1, 0, "*"), "", nil},
+		{"feature test row", ruleFeature("__has_include", 0, 0, ""), ruleFeature("__has_include", 1, 0, ""), "", nil},
+		{"full and partial support", ruleFeature("Pack indexing", 0, 0, ""), ruleFeature("Pack indexing", 1, 2, ""), ChangeSupport, []string{CompilerGcc}},
+		{"only partial support", ruleFeature("Pack indexing", 0, 0, ""), ruleFeature("Pack indexing", 2, 0, ""), "", nil},
+		{"new listing", nil, ruleFeature("Reflection", 0, 0, ""), ChangeNewListing, nil},
+	} {
+		change, err := DiffFeatures(test.previous, test.next)
+		if err != nil {
+			t.Fatalf("%v: %v", test.what, err)
+		}
+
+		change, err = ApplyReportRules(rules, change)
+		if err != nil {
+			t.Errorf("%v: %v", test.what, err)
+		}
+		if change.Kind != test.kind {
+			t.Errorf("%v: got kind '%v', want '%v'", test.what, change.Kind, test.kind)
+		}
+		if test.kind == ChangeSupport && !reflect.DeepEqual(deltaCompilers(change), test.compilers) {
+			t.Errorf("%v: got compilers %v, want %v", test.what, deltaCompilers(change), test.compilers)
+		}
+	}
+}
+
+func TestApplyReportRulesInvalid(t *testing.T) {
+	change, err := DiffFeatures(ruleFeature("Pack indexing", 0, 0, ""), ruleFeature("Pack indexing", 1, 0, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, rule := range []ReportRule{
+		{Name: "bad pattern", Action: RuleSuppress, Feature: "(Pack"},
+		{Name: "bad paper pattern", Action: RuleSuppress, Paper: "[P"},
+		{Name: "typo", Action: "supress"},
+		{Name: "no action"},
+	} {
+		if err := CheckReportRules([]ReportRule{rule}); err == nil {
+			t.Errorf("%v: passed the check", rule.Name)
+		}
+
+		result, err := ApplyReportRules([]ReportRule{rule}, change)
+		if err == nil {
+			t.Errorf("%v: was applied without an error", rule.Name)
+		}
+		if result.Kind != ChangeSupport || len(result.Deltas) != 1 {
+			t.Errorf("%v: changed the change to kind '%v' with compilers %v", rule.Name, result.Kind, deltaCompilers(result))
+		}
+	}
+}
+
+func TestCheckReportRules(t *testing.T) {
+	for _, rule := range []ReportRule{
+		{Name: "bad kind", Action: RuleSuppress, Kinds: []string{"typo"}},
+		{Name: "bad level", Action: RuleAllow, To: []string{"full"}},
+		{Name: "bad language", Action: RuleAllow, Languages: []string{"rust"}},
+	} {
+		if err := CheckReportRules([]ReportRule{rule}); err == nil {
+			t.Errorf("%v: passed the check", rule.Name)
+		}
+	}
+}
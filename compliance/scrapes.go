@@ -0,0 +1,117 @@
+package compliance
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"io/ioutil"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ScrapeSnapshot is the page of a scrape as it was fetched, kept so that a bad diff can be looked into and the page
+// parsed again by a better parser
+type ScrapeSnapshot struct {
+	Id        int64     `db:"id"`
+	Source    string    `db:"source"`
+	Timestamp time.Time `db:"timestamp"`
+	Hash      string    `db:"hash"`     //of what was parsed of the page, so a page that parses the same isn't kept twice
+	Features  int       `db:"features"` //amount parsed out of the page
+	Page      []byte    `db:"page"`     //gzipped html, not filled in by listings
+}
+
+// NewScrapeSnapshot gzips the html of the page of a scrape of the source
+func NewScrapeSnapshot(source string, at time.Time, hash string, features int, html string) (*ScrapeSnapshot, error) {
+	var buffer bytes.Buffer
+
+	writer := gzip.NewWriter(&buffer)
+	if _, err := writer.Write([]byte(html)); err != nil {
+		return nil, errors.Wrap(err, "could not compress page")
+	}
+	if err := writer.Close(); err != nil {
+		return nil, errors.Wrap(err, "could not compress page")
+	}
+
+	return &ScrapeSnapshot{Source: source, Timestamp: at, Hash: hash, Features: features, Page: buffer.Bytes()}, nil
+}
+
+// Html gives the page of the snapshot uncompressed
+func (s *ScrapeSnapshot) Html() (string, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(s.Page))
+	if err != nil {
+		return "", errors.Wrap(err, "could not decompress page")
+	}
+	defer reader.Close()
+
+	html, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return "", errors.Wrap(err, "could not decompress page")
+	}
+
+	return string(html), nil
+}
+
+// StoreScrapeSnapshot keeps the snapshot and sets its id
+func (s *SqliteService) StoreScrapeSnapshot(ctx context.Context, snapshot *ScrapeSnapshot) error {
+	query := "INSERT INTO scrapes (source, timestamp, hash, features, page) VALUES(?, ?, ?, ?, ?)"
+
+	result, err := s.db.ExecContext(ctx, query, snapshot.Source, snapshot.Timestamp, snapshot.Hash, snapshot.Features, snapshot.Page)
+	if err != nil {
+		return errors.Wrap(err, "could not store scrape snapshot")
+	}
+
+	if snapshot.Id, err = result.LastInsertId(); err != nil {
+		return errors.Wrap(err, "could not get id of scrape snapshot")
+	}
+
+	return nil
+}
+
+// GetScrapeSnapshots lists up to limit snapshots of the source without their pages, newest first. an empty source
+// lists those of all sources, a limit of 0 all of them
+func (s *SqliteService) GetScrapeSnapshots(ctx context.Context, source string, limit int) ([]ScrapeSnapshot, error) {
+	query := `SELECT id, source, timestamp, hash, features FROM scrapes
+		WHERE ?='' OR source=?
+		ORDER BY timestamp DESC, id DESC`
+	args := []interface{}{source, source}
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+
+	result := []ScrapeSnapshot{}
+	if err := s.db.SelectContext(ctx, &result, query, args...); err != nil {
+		return nil, errors.Wrap(err, "could not get scrape snapshots")
+	}
+
+	return result, nil
+}
+
+// GetScrapeSnapshot gives the snapshot of the id with its page, nil if there is none
+func (s *SqliteService) GetScrapeSnapshot(ctx context.Context, id int64) (*ScrapeSnapshot, error) {
+	query := "SELECT id, source, timestamp, hash, features, page FROM scrapes WHERE id=?"
+
+	snapshot := &ScrapeSnapshot{}
+	err := s.db.GetContext(ctx, snapshot, query, id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "could not get scrape snapshot")
+	}
+
+	return snapshot, nil
+}
+
+// PruneScrapeSnapshots drops the snapshots of the source beyond the newest keep
+func (s *SqliteService) PruneScrapeSnapshots(ctx context.Context, source string, keep int) error {
+	query := `DELETE FROM scrapes WHERE source=? AND id NOT IN (
+		SELECT id FROM scrapes WHERE source=? ORDER BY timestamp DESC, id DESC LIMIT ?)`
+
+	if _, err := s.db.ExecContext(ctx, query, source, source, keep); err != nil {
+		return errors.Wrap(err, "could not prune scrape snapshots")
+	}
+
+	return nil
+}
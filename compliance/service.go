@@ -1,14 +1,73 @@
 package compliance
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type Service interface {
 	CreateEntry(ctx context.Context, feature *Feature) error
 	GetLastIfDiffers(ctx context.Context, feature *Feature) (bool, *Feature, error)
-	GetNotTwitterReported(ctx context.Context) ([]Feature, error)
-	GetPreviousFeatureEntry(ctx context.Context, feature *Feature) (*Feature, error)
-	SetTwitterReported(ctx context.Context, feature *Feature) error
-	SetErrorReported(ctx context.Context, feature *Feature) error
+	GetUnreported(ctx context.Context, channel string) ([]Feature, error)
+	GetEntriesByID(ctx context.Context, ids []int64) ([]Feature, error)
+	GetPreviousEntry(ctx context.Context, id int64) (*Feature, error)
+	SetReported(ctx context.Context, id int64, channel string) error
+	SetReportSkipped(ctx context.Context, id int64, channel string) error
+	SetReportStatus(ctx context.Context, id int64, channel string, status string) error
+	SetErrorReported(ctx context.Context, id int64) error
+	SearchLatestEntries(ctx context.Context, paper string, name string) ([]Feature, error)
+	GetLatestEntries(ctx context.Context) ([]Feature, error)
+	GetEntriesAt(ctx context.Context, at time.Time) ([]Feature, error)
+	GetFeatureHistory(ctx context.Context, language string, name string) ([]Feature, error)
+	GetFeatureHistoryBySlug(ctx context.Context, slug string) ([]Feature, error)
+	GetEntriesSince(ctx context.Context, since time.Time) ([]Feature, error)
+	GetEntryChanges(ctx context.Context, language string, since time.Time, afterID int64, limit int) ([]EntryChange, error)
+	GetLastFingerprint(ctx context.Context, source string) (string, error)
+	StoreFingerprint(ctx context.Context, source string, data string) error
+	StoreScrapeSnapshot(ctx context.Context, snapshot *ScrapeSnapshot) error
+	GetScrapeSnapshots(ctx context.Context, source string, limit int) ([]ScrapeSnapshot, error)
+	GetScrapeSnapshot(ctx context.Context, id int64) (*ScrapeSnapshot, error)
+	PruneScrapeSnapshots(ctx context.Context, source string, keep int) error
+	StoreAggregates(ctx context.Context, aggregates []Aggregate) error
+	GetLatestAggregates(ctx context.Context) ([]Aggregate, error)
+	GetAggregateSeries(ctx context.Context, language string, version int, compiler string) ([]Aggregate, error)
+	GetSupportCounts(ctx context.Context) ([]SupportCount, error)
+	GetLastUpdate(ctx context.Context) (time.Time, error)
+	GetHttpValidators(ctx context.Context, url string) (etag string, lastModified string, err error)
+	StoreHttpValidators(ctx context.Context, url string, etag string, lastModified string) error
+	GetAnnouncedReleases(ctx context.Context, compiler string) ([]string, error)
+	StoreAnnouncedRelease(ctx context.Context, compiler string, version string) error
+	StoreFeatureAlias(ctx context.Context, alias string, name string, source string) error
+	GetYearReviewPosted(ctx context.Context, year int) (bool, error)
+	StoreYearReviewPosted(ctx context.Context, year int) error
+	StoreObjectiveEvent(ctx context.Context, objective string, good bool) error
+	GetObjectiveCounts(ctx context.Context, objective string, since time.Time) (good int, total int, err error)
+	QueryReadOnly(ctx context.Context, query string, args []interface{}, maxRows int) (*QueryResult, error)
+	GetLastDigest(ctx context.Context) (time.Time, error)
+	StoreDigest(ctx context.Context, until time.Time, changes int) error
+	GetLastWeeklySummary(ctx context.Context) (time.Time, error)
+	StoreWeeklySummary(ctx context.Context, until time.Time, changes int) error
+	IgnoreFeature(ctx context.Context, ignored *IgnoredFeature) error
+	UnignoreFeature(ctx context.Context, slug string) (bool, error)
+	GetIgnoredFeatures(ctx context.Context) ([]IgnoredFeature, error)
+	StoreReportPost(ctx context.Context, post *ReportPost) error
+	GetReportPostsSince(ctx context.Context, since time.Time) ([]ReportPost, error)
+	UpdateReportPostEngagement(ctx context.Context, tweetID int64, likes int, retweets int) error
+	GetReportAttempt(ctx context.Context, featureID int64, channel string) (*ReportAttempt, error)
+	StoreReportAttempt(ctx context.Context, attempt *ReportAttempt) error
+	ClearReportAttempt(ctx context.Context, featureID int64, channel string) error
+	GetDeadLetters(ctx context.Context) ([]ReportAttempt, error)
+	GetHeartbeat(ctx context.Context) (time.Time, error)
+	StoreHeartbeat(ctx context.Context, at time.Time) error
+	GetReportingPause(ctx context.Context) (*ReportingPause, error)
+	PauseReporting(ctx context.Context, at time.Time, pending int) error
+	GetReportApproval(ctx context.Context, featureID int64, channel string) (*ReportApproval, error)
+	StoreReportApproval(ctx context.Context, approval *ReportApproval) error
+	StoreSuggestion(ctx context.Context, suggestion *Suggestion) error
+	GetWatchlist(ctx context.Context) ([]Watch, error)
+	SetWatchListed(ctx context.Context, watchID int64, entryID int64) error
+	StoreDiscrepancies(ctx context.Context, probe string, discrepancies []Discrepancy) ([]Discrepancy, error)
+	Ping(ctx context.Context) error
 	//Create(ctx context.Context, dog *Dog) error
 	//Get(ctx context.Context, id uint64) (*Dog, error)
 	//List(ctx context.Context) (Dogs, error)
@@ -0,0 +1,496 @@
+// Package servicetest checks that a storage backend behaves like the Service interface expects, the same way for
+// sqlite, memory and whatever backend comes next
+package servicetest
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// T is the part of *testing.T the suite reports through
+type T interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+	Fatalf(format string, args ...interface{})
+}
+
+// Factory creates an empty service whose new entries are timestamped by now, like the Now of SqliteService
+type Factory func(now func() time.Time) (compliance.Service, error)
+
+// start is when the clock of every case starts, in whole minutes as sqlite compares timestamps as text
+var start = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// clock timestamps the entries of a case, a minute apart unless set
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.now = c.now.Add(time.Minute)
+	return c.now
+}
+
+// set makes the next entry be created at the given time
+func (c *clock) set(at time.Time) {
+	c.now = at.Add(-time.Minute)
+}
+
+type testCase struct {
+	name string
+	run  func(t T, service compliance.Service, clock *clock)
+}
+
+var cases = []testCase{
+	{"create entry", testCreateEntry},
+	{"last if differs", testLastIfDiffers},
+	{"previous entry", testPreviousEntry},
+	{"entry changes", testEntryChanges},
+	{"compiler dropped", testCompilerDropped},
+	{"reported", testReported},
+	{"history order", testHistoryOrder},
+	{"latest entries", testLatestEntries},
+	{"search", testSearch},
+}
+
+// namedT prefixes the failures with the case they happened in
+type namedT struct {
+	T
+	name string
+}
+
+func (t namedT) Errorf(format string, args ...interface{}) {
+	t.T.Helper()
+	t.T.Errorf("%v: %v", t.name, fmt.Sprintf(format, args...))
+}
+
+func (t namedT) Fatalf(format string, args ...interface{}) {
+	t.T.Helper()
+	t.T.Fatalf("%v: %v", t.name, fmt.Sprintf(format, args...))
+}
+
+// TestService runs every case on a new service of the factory, which is closed after
+func TestService(t T, factory Factory) {
+	t.Helper()
+
+	for _, c := range cases {
+		runCase(namedT{t, c.name}, factory, c)
+	}
+}
+
+func runCase(t T, factory Factory, c testCase) {
+	t.Helper()
+
+	clock := &clock{now: start}
+	service, err := factory(clock.Now)
+	if err != nil {
+		t.Fatalf("could not create service: %v", err)
+	}
+	defer service.Close(context.Background())
+
+	c.run(t, service, clock)
+}
+
+func feature(name string, paper string, support int) *compliance.Feature {
+	return &compliance.Feature{
+		Name:       name,
+		CppVersion: 20,
+		PaperName:  sql.NullString{String: paper, Valid: paper != ""},
+		Compilers: []compliance.CompilerSupport{{
+			Compiler:    compliance.CompilerGcc,
+			Kind:        compliance.KindCompiler,
+			Support:     support,
+			DisplayText: sql.NullString{String: "11", Valid: true},
+		}},
+	}
+}
+
+func create(t T, service compliance.Service, feature *compliance.Feature) *compliance.Feature {
+	t.Helper()
+
+	if err := service.CreateEntry(context.Background(), feature); err != nil {
+		t.Fatalf("could not create %v: %v", feature.Name, err)
+	}
+
+	return feature
+}
+
+func ids(entries []compliance.Feature) []int64 {
+	result := []int64{}
+	for _, entry := range entries {
+		result = append(result, entry.Id)
+	}
+
+	return result
+}
+
+func sortedIds(entries []compliance.Feature) []int64 {
+	result := ids(entries)
+	sort.Slice(result, func(i, j int) bool {
+		return result[i] < result[j]
+	})
+
+	return result
+}
+
+func expectIds(t T, what string, got []int64, want ...int64) {
+	t.Helper()
+
+	if fmt.Sprint(got) != fmt.Sprint(append([]int64{}, want...)) {
+		t.Errorf("%v: got entries %v, want %v", what, got, want)
+	}
+}
+
+func gccSupport(entry *compliance.Feature) int {
+	if support := entry.SupportFor(compliance.CompilerGcc); support != nil {
+		return support.Support
+	}
+
+	return -1
+}
+
+func testCreateEntry(t T, service compliance.Service, clock *clock) {
+	modules := create(t, service, feature("Modules", "P1103R3", 0))
+	if modules.Id == 0 {
+		t.Errorf("no id set")
+	}
+	if !modules.Timestamp.Equal(start.Add(time.Minute)) {
+		t.Errorf("timestamp %v, want %v", modules.Timestamp, start.Add(time.Minute))
+	}
+	if modules.Language != compliance.LanguageCpp {
+		t.Errorf("language %v, want %v", modules.Language, compliance.LanguageCpp)
+	}
+	if modules.Slug != "p1103" {
+		t.Errorf("slug %v, want p1103", modules.Slug)
+	}
+
+	again := create(t, service, feature("Modules", "P1103R3", 1))
+	if again.Id == modules.Id {
+		t.Errorf("second entry has the id %v of the first", again.Id)
+	}
+	if again.Slug != modules.Slug {
+		t.Errorf("second entry has slug %v, want %v of the first", again.Slug, modules.Slug)
+	}
+
+	sharing := create(t, service, feature("Header units", "P1103R3", 0))
+	if sharing.Slug == modules.Slug {
+		t.Errorf("features sharing a paper share the slug %v", sharing.Slug)
+	}
+}
+
+func testLastIfDiffers(t T, service compliance.Service, clock *clock) {
+	ctx := context.Background()
+
+	differs, last, err := service.GetLastIfDiffers(ctx, feature("Modules", "P1103R3", 0))
+	if err != nil || !differs || last != nil {
+		t.Errorf("new feature: got %v, %v, %v, want true, nil, nil", differs, last, err)
+	}
+
+	first := create(t, service, feature("Modules", "P1103R3", 0))
+
+	differs, last, err = service.GetLastIfDiffers(ctx, feature("Modules", "P1103R3", 0))
+	if err != nil || differs || last != nil {
+		t.Errorf("unchanged feature: got %v, %v, %v, want false, nil, nil", differs, last, err)
+	}
+
+	differs, last, err = service.GetLastIfDiffers(ctx, feature("Modules", "P1103R3", 1))
+	if err != nil || !differs || last == nil {
+		t.Fatalf("changed support: got %v, %v, %v, want true, the last entry, nil", differs, last, err)
+	}
+	if last.Id != first.Id || gccSupport(last) != 0 {
+		t.Errorf("changed support: last entry %v with gcc support %v, want %v with 0", last.Id, gccSupport(last), first.Id)
+	}
+
+	changedText := feature("Modules", "P1103R3", 0)
+	changedText.Compilers[0].DisplayText.String = "11 (partial)"
+	if differs, _, err := service.GetLastIfDiffers(ctx, changedText); err != nil || !differs {
+		t.Errorf("changed text: got %v, %v, want true, nil", differs, err)
+	}
+
+	otherLanguage := feature("Modules", "P1103R3", 0)
+	otherLanguage.Language = compliance.LanguageC
+	differs, last, err = service.GetLastIfDiffers(ctx, otherLanguage)
+	if err != nil || !differs || last != nil {
+		t.Errorf("other language: got %v, %v, %v, want true, nil, nil", differs, last, err)
+	}
+}
+
+func testPreviousEntry(t T, service compliance.Service, clock *clock) {
+	ctx := context.Background()
+
+	otherLanguage := feature("Modules", "", 2)
+	otherLanguage.Language = compliance.LanguageC
+	create(t, service, otherLanguage)
+
+	first := create(t, service, feature("Modules", "P1103R3", 0))
+	if previous, err := service.GetPreviousEntry(ctx, first.Id); err != nil || previous != nil {
+		t.Errorf("first entry: got %v, %v, want nil, nil", previous, err)
+	}
+
+	second := create(t, service, feature("Modules", "P1103R3", 1))
+	previous, err := service.GetPreviousEntry(ctx, second.Id)
+	if err != nil || previous == nil {
+		t.Fatalf("second entry: got %v, %v, want the first entry", previous, err)
+	}
+	if previous.Id != first.Id || gccSupport(previous) != 0 {
+		t.Errorf("second entry: previous %v with gcc support %v, want %v with 0", previous.Id, gccSupport(previous), first.Id)
+	}
+
+	if err := service.StoreFeatureAlias(ctx, "Modules", "Named modules", compliance.AliasDetected); err != nil {
+		t.Fatalf("could not store alias: %v", err)
+	}
+	renamed := feature("Named modules", "P1103R3", 1)
+	renamed.Slug = second.Slug
+	create(t, service, renamed)
+
+	previous, err = service.GetPreviousEntry(ctx, renamed.Id)
+	if err != nil || previous == nil {
+		t.Fatalf("renamed entry: got %v, %v, want the entry of the old name", previous, err)
+	}
+	if previous.Id != second.Id || previous.Name != "Modules" {
+		t.Errorf("renamed entry: previous %v %v, want %v Modules", previous.Id, previous.Name, second.Id)
+	}
+
+	if previous, err := service.GetPreviousEntry(ctx, renamed.Id+100); err != nil || previous != nil {
+		t.Errorf("unknown entry: got %v, %v, want nil, nil", previous, err)
+	}
+}
+
+func testEntryChanges(t T, service compliance.Service, clock *clock) {
+	ctx := context.Background()
+
+	before := create(t, service, feature("Modules", "P1103R3", 0))
+	first := create(t, service, feature("Modules", "P1103R3", 1))
+	otherLanguage := feature("Modules", "", 2)
+	otherLanguage.Language = compliance.LanguageC
+	create(t, service, otherLanguage)
+	second := create(t, service, feature("Coroutines", "P0912R5", 1))
+	third := create(t, service, feature("Modules", "P1103R3", 2))
+
+	changes, err := service.GetEntryChanges(ctx, compliance.LanguageCpp, before.Timestamp, 0, 2)
+	if err != nil {
+		t.Fatalf("could not get entry changes: %v", err)
+	}
+	if len(changes) != 2 || changes[0].Entry.Id != first.Id || changes[1].Entry.Id != second.Id {
+		t.Fatalf("first page: got %v changes, want entries %v and %v", len(changes), first.Id, second.Id)
+	}
+	if previous := changes[0].Previous; previous == nil || previous.Id != before.Id || gccSupport(previous) != 0 || gccSupport(&changes[0].Entry) != 1 {
+		t.Errorf("first page: %v has previous %v, want %v with gcc support 0", first.Id, previous, before.Id)
+	}
+	if changes[1].Previous != nil {
+		t.Errorf("first page: first entry of Coroutines has previous %v", changes[1].Previous.Id)
+	}
+
+	changes, err = service.GetEntryChanges(ctx, compliance.LanguageCpp, before.Timestamp, second.Id, 2)
+	if err != nil {
+		t.Fatalf("could not get entry changes: %v", err)
+	}
+	if len(changes) != 1 || changes[0].Entry.Id != third.Id || changes[0].Previous == nil || changes[0].Previous.Id != first.Id {
+		t.Fatalf("second page: got %v changes, want entry %v after %v", len(changes), third.Id, first.Id)
+	}
+	if gccSupport(&changes[0].Entry) != 2 || gccSupport(changes[0].Previous) != 1 {
+		t.Errorf("second page: gcc support %v after %v, want 2 after 1", gccSupport(&changes[0].Entry), gccSupport(changes[0].Previous))
+	}
+
+	changes, err = service.GetEntryChanges(ctx, "", before.Timestamp, 0, 10)
+	if err != nil || len(changes) != 4 {
+		t.Errorf("all languages: got %v changes, %v, want 4", len(changes), err)
+	}
+}
+
+func testCompilerDropped(t T, service compliance.Service, clock *clock) {
+	ctx := context.Background()
+
+	modules := feature("Modules", "P1103R3", 0)
+	modules.Compilers = append(modules.Compilers, compliance.CompilerSupport{
+		Compiler: compliance.CompilerClang,
+		Kind:     compliance.KindCompiler,
+		Support:  2,
+	})
+	first := create(t, service, modules)
+
+	//the scrape no longer lists clang, which differs once and is stored without it
+	differs, _, err := service.GetLastIfDiffers(ctx, feature("Modules", "P1103R3", 0))
+	if err != nil || !differs {
+		t.Fatalf("dropping clang: got differs %v, %v, want true", differs, err)
+	}
+	second := create(t, service, feature("Modules", "P1103R3", 0))
+
+	for scrape := 0; scrape < 2; scrape++ {
+		differs, last, err := service.GetLastIfDiffers(ctx, feature("Modules", "P1103R3", 0))
+		if err != nil || differs {
+			t.Errorf("scrape %v after dropping clang: got differs %v with %v, %v, want false", scrape, differs, last, err)
+		}
+	}
+
+	history, err := service.GetFeatureHistory(ctx, compliance.LanguageCpp, "Modules")
+	if err != nil || len(history) != 2 {
+		t.Fatalf("got %v entries, %v, want 2", len(history), err)
+	}
+
+	if clang := history[0].SupportFor(compliance.CompilerClang); history[0].Id != first.Id || clang == nil || clang.Support != 2 {
+		t.Errorf("first entry %v has clang support %v, want %v with 2", history[0].Id, clang, first.Id)
+	}
+
+	latest := history[1]
+	if latest.Id != second.Id || len(latest.Compilers) != 1 {
+		t.Errorf("latest entry %v has %v compilers, want %v with 1", latest.Id, len(latest.Compilers), second.Id)
+	}
+	if gcc := latest.SupportFor(compliance.CompilerGcc); gcc == nil || gcc.Version != "11" {
+		t.Errorf("latest entry has gcc version %v, want 11", gcc)
+	}
+}
+
+func testReported(t T, service compliance.Service, clock *clock) {
+	ctx := context.Background()
+
+	first := create(t, service, feature("Modules", "P1103R3", 0))
+	second := create(t, service, feature("Coroutines", "P0912R5", 0))
+	third := create(t, service, feature("Concepts", "P0734R0", 0))
+
+	unreported, err := service.GetUnreported(ctx, "twitter")
+	if err != nil {
+		t.Fatalf("could not get unreported: %v", err)
+	}
+	expectIds(t, "nothing reported", sortedIds(unreported), first.Id, second.Id, third.Id)
+
+	for _, err := range []error{
+		service.SetReported(ctx, first.Id, "twitter"),
+		service.SetReported(ctx, first.Id, "twitter"),
+		service.SetReportSkipped(ctx, second.Id, "twitter"),
+		service.SetReportStatus(ctx, third.Id, "slack", compliance.ReportStatusFailed),
+		service.SetErrorReported(ctx, second.Id),
+	} {
+		if err != nil {
+			t.Fatalf("could not set reported: %v", err)
+		}
+	}
+
+	unreported, err = service.GetUnreported(ctx, "twitter")
+	if err != nil {
+		t.Fatalf("could not get unreported: %v", err)
+	}
+	expectIds(t, "unreported on twitter", sortedIds(unreported), third.Id)
+
+	unreported, err = service.GetUnreported(ctx, "slack")
+	if err != nil {
+		t.Fatalf("could not get unreported: %v", err)
+	}
+	expectIds(t, "unreported on slack", sortedIds(unreported), first.Id, second.Id)
+
+	entries, err := service.GetEntriesByID(ctx, []int64{second.Id})
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("got %v entries, %v, want 1", len(entries), err)
+	}
+	if !entries[0].ReportedBroken {
+		t.Errorf("entry not marked as reported broken")
+	}
+}
+
+func testHistoryOrder(t T, service compliance.Service, clock *clock) {
+	ctx := context.Background()
+
+	//a replayed snapshot is stored after the entries it is older than
+	clock.set(start.Add(time.Hour))
+	later := create(t, service, feature("Modules", "P1103R3", 1))
+	clock.set(start.Add(30 * time.Minute))
+	earlier := create(t, service, feature("Modules", "P1103R3", 0))
+	other := create(t, service, feature("Coroutines", "P0912R5", 0))
+
+	history, err := service.GetFeatureHistory(ctx, compliance.LanguageCpp, "Modules")
+	if err != nil {
+		t.Fatalf("could not get history: %v", err)
+	}
+	expectIds(t, "history", ids(history), earlier.Id, later.Id)
+
+	history, err = service.GetFeatureHistoryBySlug(ctx, later.Slug)
+	if err != nil {
+		t.Fatalf("could not get history by slug: %v", err)
+	}
+	expectIds(t, "history by slug", ids(history), earlier.Id, later.Id)
+
+	differs, last, err := service.GetLastIfDiffers(ctx, feature("Modules", "P1103R3", 0))
+	if err != nil || !differs || last == nil || last.Id != later.Id {
+		t.Errorf("last if differs: got %v, %v, %v, want true, entry %v, nil", differs, last, err, later.Id)
+	}
+
+	entries, err := service.GetEntriesByID(ctx, []int64{other.Id, later.Id, other.Id + 100})
+	if err != nil {
+		t.Fatalf("could not get entries by id: %v", err)
+	}
+	expectIds(t, "entries by id", ids(entries), later.Id, other.Id)
+
+	entries, err = service.GetEntriesSince(ctx, earlier.Timestamp)
+	if err != nil {
+		t.Fatalf("could not get entries since: %v", err)
+	}
+	expectIds(t, "entries since", ids(entries), other.Id, later.Id)
+
+	lastUpdate, err := service.GetLastUpdate(ctx)
+	if err != nil || !lastUpdate.Equal(later.Timestamp) {
+		t.Errorf("last update: got %v, %v, want %v", lastUpdate, err, later.Timestamp)
+	}
+}
+
+func testLatestEntries(t T, service compliance.Service, clock *clock) {
+	ctx := context.Background()
+
+	newer := feature("Deducing this", "P0847R7", 0)
+	newer.CppVersion = 23
+	create(t, service, newer)
+	zeta := create(t, service, feature("Zeta", "", 0))
+	alpha := create(t, service, feature("Alpha", "", 0))
+	c := feature("Alpha", "", 0)
+	c.Language = compliance.LanguageC
+	create(t, service, c)
+	gone := create(t, service, feature("Gone", "", 0))
+	listed := clock.now
+
+	removed := feature("Gone", "", 0)
+	removed.Removed = true
+	create(t, service, removed)
+	alphaAgain := create(t, service, feature("Alpha", "", 1))
+
+	latest, err := service.GetLatestEntries(ctx)
+	if err != nil {
+		t.Fatalf("could not get latest entries: %v", err)
+	}
+	expectIds(t, "latest entries", ids(latest), c.Id, alphaAgain.Id, zeta.Id, newer.Id)
+
+	at, err := service.GetEntriesAt(ctx, listed)
+	if err != nil {
+		t.Fatalf("could not get entries at: %v", err)
+	}
+	expectIds(t, "entries at", ids(at), c.Id, alpha.Id, gone.Id, zeta.Id, newer.Id)
+}
+
+func testSearch(t T, service compliance.Service, clock *clock) {
+	ctx := context.Background()
+
+	create(t, service, feature("Modules", "P1103R3", 0))
+	modules := create(t, service, feature("Modules", "P1103R3", 1))
+	newer := feature("Module declarations", "P1103R4", 0)
+	newer.CppVersion = 23
+	create(t, service, newer)
+	create(t, service, feature("Coroutines", "P0912R5", 0))
+
+	found, err := service.SearchLatestEntries(ctx, "P1103", "")
+	if err != nil {
+		t.Fatalf("could not search by paper: %v", err)
+	}
+	expectIds(t, "search by paper", ids(found), newer.Id, modules.Id)
+
+	found, err = service.SearchLatestEntries(ctx, "", "modul")
+	if err != nil {
+		t.Fatalf("could not search by name: %v", err)
+	}
+	expectIds(t, "search by name", ids(found), newer.Id, modules.Id)
+
+	found, err = service.SearchLatestEntries(ctx, "", "")
+	if err != nil || len(found) != 0 {
+		t.Errorf("empty search: got %v, %v, want nothing", ids(found), err)
+	}
+}
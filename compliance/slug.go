@@ -0,0 +1,108 @@
+package compliance
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// longest slug made from a feature name
+const maxNameSlugLength = 60
+
+// paper numbers, or the issue numbers of defect reports like CWG2518, which take the place of the paper in their table
+var slugPaperPattern = regexp.MustCompile(`(?i)^\s*([PN]\d{3,4}|[CL]WG\d+)`)
+
+// NameSlug turns a feature name into lowercase words joined by dashes
+func NameSlug(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !(r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)))
+	})
+
+	slug := strings.Join(words, "-")
+	if len(slug) > maxNameSlugLength {
+		slug = strings.TrimRight(slug[:maxNameSlugLength], "-")
+	}
+
+	return slug
+}
+
+// BaseSlug gives the slug a feature would like to have: its paper number without revision, as that survives renames,
+// or its name if it has no paper. CreateEntry makes it unique and keeps it for later entries of the feature.
+// slugs of languages other than C++ start with the language, so they can't take the slug a C++ feature would get
+func BaseSlug(feature *Feature) string {
+	prefix := ""
+	if feature.Language != "" && feature.Language != LanguageCpp {
+		prefix = feature.Language + "-"
+	}
+
+	if paper := slugPaperPattern.FindStringSubmatch(fromNullString(feature.PaperName)); paper != nil {
+		return prefix + strings.ToLower(paper[1])
+	}
+
+	if slug := NameSlug(feature.Name); slug != "" {
+		return prefix + slug
+	}
+
+	return prefix + "feature"
+}
+
+// CurrentFeatures leaves out the latest entries of features that were renamed since, which share the slug of the new name
+func CurrentFeatures(features []Feature) (result []Feature) {
+	newest := map[string]*Feature{}
+	for i := range features {
+		if current, ok := newest[features[i].Slug]; !ok || features[i].Timestamp.After(current.Timestamp) {
+			newest[features[i].Slug] = &features[i]
+		}
+	}
+
+	for i := range features {
+		if features[i].Slug == "" || newest[features[i].Slug] == &features[i] {
+			result = append(result, features[i])
+		}
+	}
+
+	return
+}
+
+var linkPaperPattern = regexp.MustCompile(`(?i)\b([PN]\d{3,4})(?:R\d+)?(?:\.\w+)?$`)
+
+// PaperNumber gives the lowercase paper number of a feature without revision, from its paper name or else from the
+// paper link, or an empty string if it has neither
+func PaperNumber(feature *Feature) string {
+	if paper := slugPaperPattern.FindStringSubmatch(fromNullString(feature.PaperName)); paper != nil {
+		return strings.ToLower(paper[1])
+	}
+
+	link := strings.TrimRight(fromNullString(feature.PaperLink), "/")
+	if paper := linkPaperPattern.FindStringSubmatch(link); paper != nil {
+		return strings.ToLower(paper[1])
+	}
+
+	return ""
+}
+
+// NameSimilarity tells how alike two feature names are, from 0 for no shared words to 1 for the same words
+func NameSimilarity(a string, b string) float64 {
+	wordsA := strings.Split(NameSlug(a), "-")
+	wordsB := map[string]bool{}
+	for _, word := range strings.Split(NameSlug(b), "-") {
+		wordsB[word] = true
+	}
+
+	shared := 0
+	union := len(wordsB)
+	for _, word := range wordsA {
+		if wordsB[word] {
+			shared++
+			delete(wordsB, word)
+		} else {
+			union++
+		}
+	}
+
+	if union == 0 {
+		return 0
+	}
+
+	return float64(shared) / float64(union)
+}
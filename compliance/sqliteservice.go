@@ -3,6 +3,7 @@ package compliance
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -11,6 +12,12 @@ import (
 
 type SqliteService struct {
 	db *sqlx.DB
+
+	//if set, every compiler cell of a new entry is written instead of only the ones that changed since the previous entry
+	FullHistory bool
+
+	//gives the timestamp of new entries. time.Now if nil, replaying old snapshots sets it to their date
+	Now func() time.Time
 }
 
 func meaningfulDifference(a *Feature, b *Feature) bool {
@@ -18,15 +25,163 @@ func meaningfulDifference(a *Feature, b *Feature) bool {
 		a.CppVersion != b.CppVersion ||
 		a.PaperName != b.PaperName ||
 		a.PaperLink != b.PaperLink ||
-		a.GccSupport != b.GccSupport ||
-		a.GccDisplayText != b.GccDisplayText ||
-		a.GccExtraText != b.GccExtraText ||
-		a.ClangSupport != b.ClangSupport ||
-		a.ClangDisplayText != b.ClangDisplayText ||
-		a.ClangExtraText != b.ClangExtraText ||
-		a.MsvcSupport != b.MsvcSupport ||
-		a.MsvcDisplayText != b.MsvcDisplayText ||
-		a.MsvcExtraText != b.MsvcExtraText
+		a.Removed != b.Removed ||
+		len(supportLevelChangedCompilers(a, b)) > 0 ||
+		len(textChangedCompilers(a, b)) > 0
+}
+
+// supportDropped is the support of the cell written when a compiler is no longer listed for a feature, so folding the
+// cells leaves the compiler out from then on
+const supportDropped = -1
+
+// compilerStateAt folds the stored compiler cell changes of a feature into the support as it was at the given time.
+// if inclusive is false, changes made exactly at that time are left out
+func compilerStateAt(ctx context.Context, tx *sqlx.Tx, language string, name string, at time.Time, inclusive bool) ([]CompilerSupport, error) {
+	query := `SELECT cs.feature_id, cs.compiler, cs.kind, cs.support, cs.display_text, cs.extra_text, cs.timestamp
+		FROM compiler_support cs
+		JOIN features f ON f.id=cs.feature_id
+		WHERE f.language=? AND f.name=? AND (cs.timestamp<? OR (? AND cs.timestamp=?))
+		ORDER BY cs.timestamp, cs.rowid`
+
+	var changes []CompilerSupport
+	if err := tx.SelectContext(ctx, &changes, query, language, name, at, inclusive, at); err != nil {
+		return nil, errors.Wrap(err, "could not load compiler support")
+	}
+
+	return foldCompilerSupport(changes), nil
+}
+
+// foldCompilerSupport gives the support of every compiler after the cells, which are in the order they were written
+func foldCompilerSupport(changes []CompilerSupport) []CompilerSupport {
+	state := &Feature{}
+	for _, change := range changes {
+		if change.Support == supportDropped {
+			state.Compilers = withoutCompiler(state.Compilers, change.Compiler)
+			continue
+		}
+
+		change.Version = SupportVersion(change.DisplayText.String)
+		state.SetSupport(change)
+	}
+
+	return state.Compilers
+}
+
+func withoutCompiler(compilers []CompilerSupport, compiler string) []CompilerSupport {
+	var result []CompilerSupport
+	for _, support := range compilers {
+		if support.Compiler != compiler {
+			result = append(result, support)
+		}
+	}
+
+	return result
+}
+
+// compilerBatch bounds the entries loadCompilers reads the cells of in one query, below the variable limit of sqlite
+const compilerBatch = 500
+
+// loadCompilers fills in the per-compiler support of the given features as it was at the time of each entry, reading
+// the cells of up to compilerBatch entries at once
+func loadCompilers(ctx context.Context, tx *sqlx.Tx, features ...*Feature) error {
+	for start := 0; start < len(features); start += compilerBatch {
+		end := start + compilerBatch
+		if end > len(features) {
+			end = len(features)
+		}
+
+		if err := loadCompilerBatch(ctx, tx, features[start:end]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func entryPointers(features []Feature) []*Feature {
+	pointers := make([]*Feature, 0, len(features))
+	for i := range features {
+		pointers = append(pointers, &features[i])
+	}
+
+	return pointers
+}
+
+func loadCompilerBatch(ctx context.Context, tx *sqlx.Tx, features []*Feature) error {
+	ids := make([]int64, 0, len(features))
+	for _, feature := range features {
+		ids = append(ids, feature.Id)
+	}
+
+	//the cells of every earlier entry of the same feature, up to the entry itself
+	query, args, err := sqlx.In(`SELECT e.id AS entry_id, cs.feature_id, cs.compiler, cs.kind, cs.support, cs.display_text,
+		 cs.extra_text, cs.timestamp
+		FROM features e
+		JOIN features f ON f.language=e.language AND f.name=e.name
+		JOIN compiler_support cs ON cs.feature_id=f.id
+		WHERE e.id IN (?) AND cs.timestamp<=e.timestamp
+		ORDER BY e.id, cs.timestamp, cs.rowid`, ids)
+	if err != nil {
+		return errors.Wrap(err, "could not build compiler support query")
+	}
+
+	var cells []struct {
+		EntryId int64 `db:"entry_id"`
+		CompilerSupport
+	}
+	if err := tx.SelectContext(ctx, &cells, query, args...); err != nil {
+		return errors.Wrap(err, "could not load compiler support")
+	}
+
+	changes := map[int64][]CompilerSupport{}
+	for _, cell := range cells {
+		changes[cell.EntryId] = append(changes[cell.EntryId], cell.CompilerSupport)
+	}
+
+	for _, feature := range features {
+		feature.Compilers = foldCompilerSupport(changes[feature.Id])
+	}
+
+	return nil
+}
+
+// slugFor gives the slug of the earlier entries of the feature, or a new one no other feature uses if there are none
+func slugFor(ctx context.Context, tx *sqlx.Tx, feature *Feature) (string, error) {
+	query := `SELECT slug FROM features
+		WHERE language=? AND name=? AND slug<>''
+		ORDER BY timestamp DESC
+		LIMIT 1`
+
+	var slug string
+
+	err := tx.GetContext(ctx, &slug, query, feature.Language, feature.Name)
+	if err == nil {
+		return slug, nil
+	} else if err != sql.ErrNoRows {
+		return "", errors.Wrap(err, "could not get slug")
+	}
+
+	//features sharing a paper are told apart by their name
+	base := BaseSlug(feature)
+	candidates := []string{base}
+	if nameSlug := NameSlug(feature.Name); nameSlug != "" && nameSlug != base {
+		candidates = append(candidates, base+"-"+nameSlug)
+	}
+
+	for i := 2; ; i++ {
+		for _, candidate := range candidates {
+			var users int
+			if err := tx.GetContext(ctx, &users, "SELECT COUNT(*) FROM features WHERE slug=? AND NOT (language=? AND name=?)", candidate, feature.Language, feature.Name); err != nil {
+				return "", errors.Wrap(err, "could not check slug")
+			}
+
+			if users == 0 {
+				return candidate, nil
+			}
+		}
+
+		candidates = []string{fmt.Sprintf("%v-%v", base, i)}
+	}
 }
 
 func NewSqliteService(db *sqlx.DB) *SqliteService {
@@ -37,21 +192,26 @@ func NewSqliteService(db *sqlx.DB) *SqliteService {
 
 func (s *SqliteService) CreateEntry(ctx context.Context, feature *Feature) error {
 	query := `INSERT INTO features
-		(name, timestamp, cpp_version, paper_name, paper_link,
-		 gcc_support, gcc_display_text, gcc_extra_text,
-	     clang_support, clang_display_text, clang_extra_text,
-	     msvc_support, msvc_display_text, msvc_extra_text,
-	     reported_to_twitter, reported_broken)
-		VALUES(:name, :timestamp, :cpp_version, :paper_name, :paper_link,
-		 :gcc_support, :gcc_display_text, :gcc_extra_text,
-		 :clang_support, :clang_display_text, :clang_extra_text,
-		 :msvc_support, :msvc_display_text, :msvc_extra_text,
-		 :reported_to_twitter, :reported_broken)`
+		(language, name, timestamp, cpp_version, paper_name, paper_link,
+		 reported_broken, slug, removed)
+		VALUES(:language, :name, :timestamp, :cpp_version, :paper_name, :paper_link,
+		 :reported_broken, :slug, :removed)`
+	supportQuery := `INSERT INTO compiler_support
+		(feature_id, compiler, kind, support, display_text, extra_text, timestamp)
+		VALUES(:feature_id, :compiler, :kind, :support, :display_text, :extra_text, :timestamp)`
+	transitionQuery := `INSERT INTO support_transitions
+		(entry_id, language, slug, cpp_version, compiler, previous_support, support, transition, timestamp)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
 	//fill automatic fields
 	feature.Timestamp = time.Now()
-	feature.ReportedToTwitter = false
+	if s.Now != nil {
+		feature.Timestamp = s.Now()
+	}
 	feature.ReportedBroken = false
+	if feature.Language == "" {
+		feature.Language = LanguageCpp
+	}
 
 	tx, err := s.db.Beginx()
 
@@ -60,10 +220,73 @@ func (s *SqliteService) CreateEntry(ctx context.Context, feature *Feature) error
 	}
 	defer tx.Rollback()
 
-	if _, err := tx.NamedExecContext(ctx, query, feature); err != nil {
+	//a slug given by the caller is taken over from a renamed feature
+	if feature.Slug == "" {
+		if feature.Slug, err = slugFor(ctx, tx, feature); err != nil {
+			return err
+		}
+	}
+
+	res, err := tx.NamedExecContext(ctx, query, feature)
+	if err != nil {
 		return errors.Wrap(err, "failed to insert feature")
 	}
 
+	feature.Id, err = res.LastInsertId()
+	if err != nil {
+		return errors.Wrap(err, "failed to get id of inserted feature")
+	}
+
+	//the support before this entry, to classify the changes of support level of every compiler
+	previousFeature := &Feature{}
+	previousFeature.Compilers, err = compilerStateAt(ctx, tx, feature.Language, feature.Name, feature.Timestamp, false)
+	if err != nil {
+		return err
+	}
+
+	for i := range feature.Compilers {
+		support := &feature.Compilers[i]
+		previousSupport := previousFeature.SupportFor(support.Compiler)
+
+		if previousSupport != nil && !feature.Removed {
+			if transition := SupportTransition(previousSupport.Support, support.Support); transition != "" {
+				if _, err := tx.ExecContext(ctx, transitionQuery, feature.Id, feature.Language, feature.Slug, feature.CppVersion,
+					support.Compiler, previousSupport.Support, support.Support, transition, feature.Timestamp); err != nil {
+					return errors.Wrap(err, "failed to insert support transition")
+				}
+			}
+		}
+
+		//reading folds the cells of all earlier entries, so writing the unchanged cells again is redundant but harmless
+		if !s.FullHistory && previousSupport != nil &&
+			previousSupport.Support == support.Support && sameCompilerSupportText(previousSupport, support) {
+			support.FeatureId = previousSupport.FeatureId
+			support.Timestamp = previousSupport.Timestamp
+			continue
+		}
+
+		support.FeatureId = feature.Id
+		support.Timestamp = feature.Timestamp
+
+		if _, err := tx.NamedExecContext(ctx, supportQuery, support); err != nil {
+			return errors.Wrap(err, "failed to insert compiler support")
+		}
+	}
+
+	//a compiler missing from the entry would otherwise be folded back in from the earlier cells, and the entry would
+	//differ from every later scrape
+	for _, previousSupport := range previousFeature.Compilers {
+		if feature.SupportFor(previousSupport.Compiler) != nil {
+			continue
+		}
+
+		dropped := CompilerSupport{FeatureId: feature.Id, Compiler: previousSupport.Compiler, Kind: previousSupport.Kind,
+			Support: supportDropped, Timestamp: feature.Timestamp}
+		if _, err := tx.NamedExecContext(ctx, supportQuery, &dropped); err != nil {
+			return errors.Wrap(err, "failed to insert dropped compiler support")
+		}
+	}
+
 	err = tx.Commit()
 	if err != nil {
 		return errors.Wrap(err, "Failed to commit transaction")
@@ -72,13 +295,10 @@ func (s *SqliteService) CreateEntry(ctx context.Context, feature *Feature) error
 	return nil
 }
 func (s *SqliteService) GetLastIfDiffers(ctx context.Context, feature *Feature) (bool, *Feature, error) {
-	query := `SELECT name, timestamp, cpp_version, paper_name, paper_link,
-		 gcc_support, gcc_display_text, gcc_extra_text,
-	     clang_support, clang_display_text, clang_extra_text,
-	     msvc_support, msvc_display_text, msvc_extra_text,
-	     reported_to_twitter, reported_broken
+	query := `SELECT id, language, name, timestamp, cpp_version, paper_name, paper_link,
+		 reported_broken, slug, removed
 		FROM features
-		WHERE name=?
+		WHERE language=? AND name=?
 		ORDER BY timestamp DESC
 		LIMIT 1`
 
@@ -91,7 +311,12 @@ func (s *SqliteService) GetLastIfDiffers(ctx context.Context, feature *Feature)
 	differs := false
 	lastEntry := &Feature{}
 
-	row := tx.QueryRowxContext(ctx, query, feature.Name)
+	language := feature.Language
+	if language == "" {
+		language = LanguageCpp
+	}
+
+	row := tx.QueryRowxContext(ctx, query, language, feature.Name)
 	err = row.StructScan(lastEntry)
 
 	if err == sql.ErrNoRows { //no entry, so it differs
@@ -100,6 +325,10 @@ func (s *SqliteService) GetLastIfDiffers(ctx context.Context, feature *Feature)
 	} else if err != nil { //there was another error
 		return false, nil, errors.Wrap(err, "could not scan struct")
 	} else { //there is an entry. it might differ or it might not
+		if err = loadCompilers(ctx, tx, lastEntry); err != nil {
+			return false, nil, err
+		}
+
 		if meaningfulDifference(feature, lastEntry) {
 			differs = true
 		} else {
@@ -114,14 +343,12 @@ func (s *SqliteService) GetLastIfDiffers(ctx context.Context, feature *Feature)
 	return differs, lastEntry, nil
 }
 
-func (s *SqliteService) GetNotTwitterReported(ctx context.Context) ([]Feature, error) {
-	query := `SELECT name, timestamp, cpp_version, paper_name, paper_link,
-		 gcc_support, gcc_display_text, gcc_extra_text,
-	     clang_support, clang_display_text, clang_extra_text,
-	     msvc_support, msvc_display_text, msvc_extra_text,
-	     reported_to_twitter, reported_broken
-		FROM features
-		WHERE reported_to_twitter=false`
+// GetUnreported gives the entries not reported on the channel yet
+func (s *SqliteService) GetUnreported(ctx context.Context, channel string) ([]Feature, error) {
+	query := `SELECT id, language, name, timestamp, cpp_version, paper_name, paper_link,
+		 reported_broken, slug, removed
+		FROM features f
+		WHERE NOT EXISTS (SELECT 1 FROM reports WHERE entry_id=f.id AND channel=?)`
 
 	tx, err := s.db.Beginx()
 	if err != nil {
@@ -129,7 +356,7 @@ func (s *SqliteService) GetNotTwitterReported(ctx context.Context) ([]Feature, e
 	}
 	defer tx.Rollback()
 
-	rows, err := tx.QueryxContext(ctx, query)
+	rows, err := tx.QueryxContext(ctx, query, channel)
 	if err != nil {
 		return nil, err
 	}
@@ -144,6 +371,11 @@ func (s *SqliteService) GetNotTwitterReported(ctx context.Context) ([]Feature, e
 		}
 		result = append(result, feature)
 	}
+	rows.Close()
+
+	if err := loadCompilers(ctx, tx, entryPointers(result)...); err != nil {
+		return nil, err
+	}
 
 	if err = tx.Commit(); err != nil {
 		return nil, errors.Wrap(err, "Failed to commit transaction")
@@ -152,15 +384,16 @@ func (s *SqliteService) GetNotTwitterReported(ctx context.Context) ([]Feature, e
 	return result, nil
 }
 
-func (s *SqliteService) GetPreviousFeatureEntry(ctx context.Context, feature *Feature) (*Feature, error) {
-	query := `SELECT name, timestamp, cpp_version, paper_name, paper_link,
-		 gcc_support, gcc_display_text, gcc_extra_text,
-	     clang_support, clang_display_text, clang_extra_text,
-	     msvc_support, msvc_display_text, msvc_extra_text,
-	     reported_to_twitter, reported_broken
-		FROM features
-		WHERE name=? and timestamp<?
-		ORDER BY timestamp DESC
+// GetPreviousEntry gives the entry of a feature before the one with the given id, which is an entry of an earlier name
+// if the feature was renamed
+func (s *SqliteService) GetPreviousEntry(ctx context.Context, id int64) (*Feature, error) {
+	query := `SELECT f.id, f.language, f.name, f.timestamp, f.cpp_version, f.paper_name, f.paper_link,
+		 f.reported_broken, f.slug, f.removed
+		FROM features f
+		JOIN features e ON e.id=?
+		WHERE f.language=e.language AND (f.name=e.name OR f.name IN (SELECT alias FROM feature_aliases WHERE name=e.name))
+		AND f.timestamp<e.timestamp
+		ORDER BY f.timestamp DESC
 		LIMIT 1`
 
 	tx, err := s.db.Beginx()
@@ -171,7 +404,7 @@ func (s *SqliteService) GetPreviousFeatureEntry(ctx context.Context, feature *Fe
 
 	result := &Feature{}
 
-	row := tx.QueryRowxContext(ctx, query, feature.Name, feature.Timestamp)
+	row := tx.QueryRowxContext(ctx, query, id)
 	err = row.StructScan(result)
 
 	if err == sql.ErrNoRows { //no entry, return nil
@@ -181,6 +414,9 @@ func (s *SqliteService) GetPreviousFeatureEntry(ctx context.Context, feature *Fe
 	}
 
 	//there is an entry.
+	if err = loadCompilers(ctx, tx, result); err != nil {
+		return nil, err
+	}
 
 	if err = tx.Commit(); err != nil {
 		return nil, errors.Wrap(err, "Failed to commit transaction")
@@ -189,43 +425,500 @@ func (s *SqliteService) GetPreviousFeatureEntry(ctx context.Context, feature *Fe
 	return result, nil
 }
 
-func (s *SqliteService) SetTwitterReported(ctx context.Context, feature *Feature) error {
-	query := "UPDATE features SET reported_to_twitter=1 WHERE name=:name AND timestamp=:timestamp"
+// SetReported marks the entry with the given id as reported on the channel
+func (s *SqliteService) SetReported(ctx context.Context, id int64, channel string) error {
+	query := "INSERT OR REPLACE INTO reports (entry_id, channel, reported_at, status) VALUES(?, ?, ?, ?)"
+
+	if _, err := s.db.ExecContext(ctx, query, id, channel, time.Now(), ReportStatusReported); err != nil {
+		return errors.Wrapf(err, "Failed to set feature to reported on %v", channel)
+	}
+
+	return nil
+}
+
+// SetReportStatus records how reporting the entry on the channel went, like ReportStatusFailed for reporters besides
+// twitter, which don't retry
+func (s *SqliteService) SetReportStatus(ctx context.Context, id int64, channel string, status string) error {
+	query := "INSERT OR REPLACE INTO reports (entry_id, channel, reported_at, status) VALUES(?, ?, ?, ?)"
+
+	if _, err := s.db.ExecContext(ctx, query, id, channel, time.Now(), status); err != nil {
+		return errors.Wrapf(err, "Failed to set report status of feature on %v", channel)
+	}
+
+	return nil
+}
+
+// SetReportSkipped marks the entry as not waiting to be reported on the channel anymore, without it having been
+func (s *SqliteService) SetReportSkipped(ctx context.Context, id int64, channel string) error {
+	query := "INSERT OR REPLACE INTO reports (entry_id, channel, reported_at, status) VALUES(?, ?, ?, ?)"
+
+	if _, err := s.db.ExecContext(ctx, query, id, channel, time.Now(), ReportStatusSkipped); err != nil {
+		return errors.Wrapf(err, "Failed to set feature to skipped on %v", channel)
+	}
+
+	return nil
+}
+
+// SetErrorReported marks the entry with the given id as one the maintainer was told couldn't be turned into a report
+func (s *SqliteService) SetErrorReported(ctx context.Context, id int64) error {
+	if _, err := s.db.ExecContext(ctx, "UPDATE features SET reported_broken=1 WHERE id=?", id); err != nil {
+		return errors.Wrap(err, "Failed to set feature to reported broken")
+	}
+
+	return nil
+}
 
+// SearchLatestEntries finds the latest entry of the features matching the paper or containing the name. empty arguments match nothing
+func (s *SqliteService) SearchLatestEntries(ctx context.Context, paper string, name string) ([]Feature, error) {
+	query := `SELECT id, language, name, timestamp, cpp_version, paper_name, paper_link,
+		 reported_broken, slug, removed
+		FROM features f
+		WHERE ((?<>'' AND paper_name LIKE ? || '%') OR (?<>'' AND name LIKE '%' || ? || '%'))
+		AND timestamp=(SELECT MAX(timestamp) FROM features WHERE language=f.language AND name=f.name) AND NOT removed
+		ORDER BY cpp_version DESC, name
+		LIMIT 10`
+
+	return s.selectEntries(ctx, query, paper, paper, name, name)
+}
+
+// selectEntries runs a query selecting feature rows and loads the compiler support of all of them
+func (s *SqliteService) selectEntries(ctx context.Context, query string, args ...interface{}) ([]Feature, error) {
 	tx, err := s.db.Beginx()
 	if err != nil {
-		return errors.Wrap(err, "Failed to begin transaction")
+		return nil, errors.Wrap(err, "Failed to begin transaction")
 	}
 	defer tx.Rollback()
 
-	if _, err := tx.NamedExecContext(ctx, query, feature); err != nil {
-		return errors.Wrap(err, "Failed to set feature to reported to twitter")
+	var result []Feature
+
+	if err := tx.SelectContext(ctx, &result, query, args...); err != nil {
+		return nil, errors.Wrap(err, "could not select features")
 	}
 
-	err = tx.Commit()
+	if err := loadCompilers(ctx, tx, entryPointers(result)...); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "Failed to commit transaction")
+	}
+
+	return result, nil
+}
+
+// GetEntriesByID gives the entries with the given ids in the order they were stored. ids of entries that are gone,
+// like archived ones, are left out
+func (s *SqliteService) GetEntriesByID(ctx context.Context, ids []int64) ([]Feature, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	query, args, err := sqlx.In(`SELECT id, language, name, timestamp, cpp_version, paper_name, paper_link,
+		 reported_broken, slug, removed
+		FROM features
+		WHERE id IN (?)
+		ORDER BY id`, ids)
 	if err != nil {
+		return nil, errors.Wrap(err, "could not build entry query")
+	}
+
+	return s.selectEntries(ctx, query, args...)
+}
+
+// GetLatestEntries gives the latest entry of every feature still listed
+func (s *SqliteService) GetLatestEntries(ctx context.Context) ([]Feature, error) {
+	query := `SELECT id, language, name, timestamp, cpp_version, paper_name, paper_link,
+		 reported_broken, slug, removed
+		FROM features f
+		WHERE timestamp=(SELECT MAX(timestamp) FROM features WHERE language=f.language AND name=f.name) AND NOT removed
+		ORDER BY language, cpp_version, name`
+
+	return s.selectEntries(ctx, query)
+}
+
+// GetEntriesAt gives the latest entry of every feature listed at the given time
+func (s *SqliteService) GetEntriesAt(ctx context.Context, at time.Time) ([]Feature, error) {
+	query := `SELECT id, language, name, timestamp, cpp_version, paper_name, paper_link,
+		 reported_broken, slug, removed
+		FROM features f
+		WHERE timestamp=(SELECT MAX(timestamp) FROM features WHERE language=f.language AND name=f.name AND timestamp<=?) AND NOT removed
+		ORDER BY language, cpp_version, name`
+
+	return s.selectEntries(ctx, query, at)
+}
+
+// GetFeatureHistory lists all entries of the feature of the language with the name
+func (s *SqliteService) GetFeatureHistory(ctx context.Context, language string, name string) ([]Feature, error) {
+	query := `SELECT id, language, name, timestamp, cpp_version, paper_name, paper_link,
+		 reported_broken, slug, removed
+		FROM features
+		WHERE language=? AND name=?
+		ORDER BY timestamp`
+
+	return s.selectEntries(ctx, query, language, name)
+}
+
+// GetFeatureHistoryBySlug lists all entries with the slug, including those from before the feature was renamed
+func (s *SqliteService) GetFeatureHistoryBySlug(ctx context.Context, slug string) ([]Feature, error) {
+	query := `SELECT id, language, name, timestamp, cpp_version, paper_name, paper_link,
+		 reported_broken, slug, removed
+		FROM features
+		WHERE slug=?
+		ORDER BY timestamp`
+
+	return s.selectEntries(ctx, query, slug)
+}
+
+// BackfillSlugs gives a slug to the features stored before slugs existed, oldest feature first
+func (s *SqliteService) BackfillSlugs(ctx context.Context) error {
+	query := `SELECT id, language, name, timestamp, cpp_version, paper_name, paper_link,
+		 reported_broken, slug, removed
+		FROM features f
+		WHERE slug='' AND timestamp=(SELECT MIN(timestamp) FROM features WHERE language=f.language AND name=f.name)
+		ORDER BY timestamp, id`
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "Failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	var features []Feature
+	if err := tx.SelectContext(ctx, &features, query); err != nil {
+		return errors.Wrap(err, "could not select features without slug")
+	}
+
+	for i := range features {
+		slug, err := slugFor(ctx, tx, &features[i])
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, "UPDATE features SET slug=? WHERE language=? AND name=? AND slug=''", slug, features[i].Language, features[i].Name); err != nil {
+			return errors.Wrap(err, "failed to set slug")
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
 		return errors.Wrap(err, "Failed to commit transaction")
 	}
 
 	return nil
 }
 
-func (s *SqliteService) SetErrorReported(ctx context.Context, feature *Feature) error {
-	query := "UPDATE features SET reported_broken=1 WHERE name=:name AND timestamp=:timestamp"
+func (s *SqliteService) GetEntriesSince(ctx context.Context, since time.Time) ([]Feature, error) {
+	query := `SELECT id, language, name, timestamp, cpp_version, paper_name, paper_link,
+		 reported_broken, slug, removed
+		FROM features
+		WHERE timestamp>?
+		ORDER BY timestamp`
+
+	return s.selectEntries(ctx, query, since)
+}
+
+// GetEntryChanges gives up to limit entries stored after since with ids above afterID, in the order of their ids and of
+// the language unless it is empty, each with the entry before it like GetPreviousEntry gives
+func (s *SqliteService) GetEntryChanges(ctx context.Context, language string, since time.Time, afterID int64, limit int) ([]EntryChange, error) {
+	entryQuery := `SELECT id, language, name, timestamp, cpp_version, paper_name, paper_link,
+		 reported_broken, slug, removed
+		FROM features
+		WHERE timestamp>? AND id>? AND (?='' OR language=?)
+		ORDER BY id
+		LIMIT ?`
+
+	previousQuery := `SELECT e.id AS entry_id, f.id, f.language, f.name, f.timestamp, f.cpp_version, f.paper_name,
+		 f.paper_link, f.reported_broken, f.slug, f.removed
+		FROM features e
+		JOIN features f ON f.id=(
+			SELECT p.id FROM features p
+			WHERE p.language=e.language AND (p.name=e.name OR p.name IN (SELECT alias FROM feature_aliases WHERE name=e.name))
+			AND p.timestamp<e.timestamp
+			ORDER BY p.timestamp DESC
+			LIMIT 1)
+		WHERE e.id IN (?)`
 
 	tx, err := s.db.Beginx()
 	if err != nil {
-		return errors.Wrap(err, "Failed to begin transaction")
+		return nil, errors.Wrap(err, "Failed to begin transaction")
 	}
 	defer tx.Rollback()
 
-	if _, err := tx.NamedExecContext(ctx, query, feature); err != nil {
-		return errors.Wrap(err, "Failed to set feature to reported broken")
+	var entries []Feature
+	if err := tx.SelectContext(ctx, &entries, entryQuery, since, afterID, language, language, limit); err != nil {
+		return nil, errors.Wrap(err, "could not select features")
 	}
 
-	err = tx.Commit()
+	if len(entries) == 0 {
+		return nil, tx.Commit()
+	}
+
+	ids := make([]int64, 0, len(entries))
+	for _, entry := range entries {
+		ids = append(ids, entry.Id)
+	}
+
+	query, args, err := sqlx.In(previousQuery, ids)
 	if err != nil {
-		return errors.Wrap(err, "Failed to commit transaction")
+		return nil, errors.Wrap(err, "could not build previous entry query")
+	}
+
+	var previous []struct {
+		EntryId int64 `db:"entry_id"`
+		Feature
+	}
+	if err := tx.SelectContext(ctx, &previous, query, args...); err != nil {
+		return nil, errors.Wrap(err, "could not select previous features")
+	}
+
+	result := make([]EntryChange, len(entries))
+	index := map[int64]int{}
+	for i := range entries {
+		result[i].Entry = entries[i]
+		index[entries[i].Id] = i
+	}
+
+	features := make([]*Feature, 0, len(entries)+len(previous))
+	for i := range result {
+		features = append(features, &result[i].Entry)
+	}
+	for i := range previous {
+		result[index[previous[i].EntryId]].Previous = &previous[i].Feature
+		features = append(features, &previous[i].Feature)
+	}
+
+	if err := loadCompilers(ctx, tx, features...); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "Failed to commit transaction")
+	}
+
+	return result, nil
+}
+
+// GetSupportCounts counts the current support levels of every compiler per language and version. renamed features count once
+func (s *SqliteService) GetSupportCounts(ctx context.Context) ([]SupportCount, error) {
+	query := `SELECT latest.language, latest.cpp_version, cs.compiler, cs.support, COUNT(*) AS features
+		FROM features latest
+		JOIN features f ON f.language=latest.language AND f.name=latest.name
+		JOIN compiler_support cs ON cs.feature_id=f.id
+		WHERE latest.timestamp=(SELECT MAX(timestamp) FROM features WHERE slug=latest.slug) AND NOT latest.removed
+		AND cs.rowid=(
+			SELECT cs2.rowid FROM compiler_support cs2
+			JOIN features f2 ON f2.id=cs2.feature_id
+			WHERE f2.language=latest.language AND f2.name=latest.name AND cs2.compiler=cs.compiler
+			ORDER BY cs2.timestamp DESC, cs2.rowid DESC
+			LIMIT 1)
+		AND cs.support<>?
+		GROUP BY latest.language, latest.cpp_version, cs.compiler, cs.support
+		ORDER BY latest.language, latest.cpp_version, cs.compiler, cs.support`
+
+	var result []SupportCount
+
+	if err := s.db.SelectContext(ctx, &result, query, supportDropped); err != nil {
+		return nil, errors.Wrap(err, "could not count support")
+	}
+
+	return result, nil
+}
+
+// GetLastUpdate gives the time of the newest entry, or the zero time if there are none
+func (s *SqliteService) GetLastUpdate(ctx context.Context) (time.Time, error) {
+	query := `SELECT timestamp FROM features
+		ORDER BY timestamp DESC
+		LIMIT 1`
+
+	var result time.Time
+
+	err := s.db.GetContext(ctx, &result, query)
+	if err != nil && err != sql.ErrNoRows {
+		return time.Time{}, errors.Wrap(err, "could not get last update")
+	}
+
+	return result, nil
+}
+
+// GetLastFingerprint gives the latest stored page structure of the source, or an empty string if there is none
+func (s *SqliteService) GetLastFingerprint(ctx context.Context, source string) (string, error) {
+	query := `SELECT data FROM fingerprints
+		WHERE source=?
+		ORDER BY timestamp DESC
+		LIMIT 1`
+
+	var data string
+
+	err := s.db.GetContext(ctx, &data, query, source)
+	if err == sql.ErrNoRows {
+		return "", nil
+	} else if err != nil {
+		return "", errors.Wrap(err, "could not get fingerprint")
+	}
+
+	return data, nil
+}
+
+func (s *SqliteService) StoreFingerprint(ctx context.Context, source string, data string) error {
+	query := "INSERT INTO fingerprints (source, timestamp, data) VALUES(?, ?, ?)"
+
+	if _, err := s.db.ExecContext(ctx, query, source, time.Now(), data); err != nil {
+		return errors.Wrap(err, "failed to store fingerprint")
+	}
+
+	return nil
+}
+
+// GetHttpValidators gives the ETag and Last-Modified of the last version of the page that was stored, empty if there are none
+func (s *SqliteService) GetHttpValidators(ctx context.Context, url string) (string, string, error) {
+	query := "SELECT etag, last_modified FROM http_validators WHERE url=?"
+
+	var validators struct {
+		ETag         string `db:"etag"`
+		LastModified string `db:"last_modified"`
+	}
+
+	err := s.db.GetContext(ctx, &validators, query, url)
+	if err == sql.ErrNoRows {
+		return "", "", nil
+	} else if err != nil {
+		return "", "", errors.Wrap(err, "could not get http validators")
+	}
+
+	return validators.ETag, validators.LastModified, nil
+}
+
+func (s *SqliteService) StoreHttpValidators(ctx context.Context, url string, etag string, lastModified string) error {
+	query := "INSERT OR REPLACE INTO http_validators (url, etag, last_modified, timestamp) VALUES(?, ?, ?, ?)"
+
+	if _, err := s.db.ExecContext(ctx, query, url, etag, lastModified, time.Now()); err != nil {
+		return errors.Wrap(err, "failed to store http validators")
+	}
+
+	return nil
+}
+
+// GetAnnouncedReleases lists the versions of the compiler that were seen in its release feed, oldest first
+func (s *SqliteService) GetAnnouncedReleases(ctx context.Context, compiler string) ([]string, error) {
+	query := `SELECT version FROM announced_releases
+		WHERE compiler=?
+		ORDER BY timestamp, rowid`
+
+	var versions []string
+
+	if err := s.db.SelectContext(ctx, &versions, query, compiler); err != nil {
+		return nil, errors.Wrap(err, "could not get announced releases")
+	}
+
+	return versions, nil
+}
+
+func (s *SqliteService) StoreAnnouncedRelease(ctx context.Context, compiler string, version string) error {
+	query := "INSERT OR IGNORE INTO announced_releases (compiler, version, timestamp) VALUES(?, ?, ?)"
+
+	if _, err := s.db.ExecContext(ctx, query, compiler, version, time.Now()); err != nil {
+		return errors.Wrap(err, "failed to store announced release")
+	}
+
+	return nil
+}
+
+// GetYearReviewPosted tells if the review of the year was posted already
+func (s *SqliteService) GetYearReviewPosted(ctx context.Context, year int) (bool, error) {
+	var count int
+
+	if err := s.db.GetContext(ctx, &count, "SELECT COUNT(*) FROM year_reviews WHERE year=?", year); err != nil {
+		return false, errors.Wrap(err, "could not get year review")
+	}
+
+	return count > 0, nil
+}
+
+func (s *SqliteService) StoreYearReviewPosted(ctx context.Context, year int) error {
+	query := "INSERT OR IGNORE INTO year_reviews (year, timestamp) VALUES(?, ?)"
+
+	if _, err := s.db.ExecContext(ctx, query, year, time.Now()); err != nil {
+		return errors.Wrap(err, "failed to store year review")
+	}
+
+	return nil
+}
+
+// StoreObjectiveEvent records a good or bad event of a service level objective
+func (s *SqliteService) StoreObjectiveEvent(ctx context.Context, objective string, good bool) error {
+	query := "INSERT INTO objective_events (objective, timestamp, good) VALUES(?, ?, ?)"
+
+	if _, err := s.db.ExecContext(ctx, query, objective, time.Now(), good); err != nil {
+		return errors.Wrap(err, "failed to store objective event")
+	}
+
+	return nil
+}
+
+// GetObjectiveCounts gives the amount of good events and of all events of an objective since the given time
+func (s *SqliteService) GetObjectiveCounts(ctx context.Context, objective string, since time.Time) (good int, total int, err error) {
+	counts := struct {
+		Good  sql.NullInt64 `db:"good"`
+		Total int           `db:"total"`
+	}{}
+
+	query := "SELECT SUM(good) AS good, COUNT(*) AS total FROM objective_events WHERE objective=? AND timestamp>=?"
+	if err := s.db.GetContext(ctx, &counts, query, objective, since); err != nil {
+		return 0, 0, errors.Wrap(err, "could not count objective events")
+	}
+
+	return int(counts.Good.Int64), counts.Total, nil
+}
+
+// GetLastDigest gives the end of the window of the last digest sent, or the zero time if none was sent yet
+func (s *SqliteService) GetLastDigest(ctx context.Context) (time.Time, error) {
+	var timestamp time.Time
+
+	err := s.db.GetContext(ctx, &timestamp, "SELECT timestamp FROM digests ORDER BY timestamp DESC LIMIT 1")
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil
+	} else if err != nil {
+		return time.Time{}, errors.Wrap(err, "could not get last digest")
+	}
+
+	return timestamp, nil
+}
+
+func (s *SqliteService) StoreDigest(ctx context.Context, until time.Time, changes int) error {
+	query := "INSERT INTO digests (timestamp, changes) VALUES(?, ?)"
+
+	if _, err := s.db.ExecContext(ctx, query, until, changes); err != nil {
+		return errors.Wrap(err, "failed to store digest")
+	}
+
+	return nil
+}
+
+// GetHeartbeat gives the last time the reporter was running, or the zero time if it never was
+func (s *SqliteService) GetHeartbeat(ctx context.Context) (time.Time, error) {
+	var timestamp time.Time
+
+	err := s.db.GetContext(ctx, &timestamp, "SELECT timestamp FROM heartbeats WHERE id=1")
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil
+	} else if err != nil {
+		return time.Time{}, errors.Wrap(err, "could not get heartbeat")
+	}
+
+	return timestamp, nil
+}
+
+func (s *SqliteService) StoreHeartbeat(ctx context.Context, at time.Time) error {
+	if _, err := s.db.ExecContext(ctx, "INSERT OR REPLACE INTO heartbeats (id, timestamp) VALUES(1, ?)", at); err != nil {
+		return errors.Wrap(err, "failed to store heartbeat")
+	}
+
+	return nil
+}
+
+// Ping checks the database can still be reached
+func (s *SqliteService) Ping(ctx context.Context) error {
+	if err := s.db.PingContext(ctx); err != nil {
+		return errors.Wrap(err, "could not reach database")
 	}
 
 	return nil
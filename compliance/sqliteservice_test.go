@@ -0,0 +1,71 @@
+package compliance_test
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"cppimpbot/compliance/servicetest"
+	"cppimpbot/util"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func sqliteFactory(t *testing.T, fullHistory bool) servicetest.Factory {
+	dir := t.TempDir()
+	databases := 0
+
+	return func(now func() time.Time) (compliance.Service, error) {
+		databases++
+		database := filepath.Join(dir, fmt.Sprintf("%v.db", databases))
+		if err := util.SqliteMigrateUp(database, "../migrations"); err != nil {
+			return nil, err
+		}
+
+		db, err := util.SqliteConnect(database)
+		if err != nil {
+			return nil, err
+		}
+
+		service := compliance.NewSqliteService(db)
+		service.FullHistory = fullHistory
+		service.Now = now
+		return service, nil
+	}
+}
+
+func TestSqliteService(t *testing.T) {
+	servicetest.TestService(t, sqliteFactory(t, false))
+}
+
+func TestSqliteServiceFullHistory(t *testing.T) {
+	servicetest.TestService(t, sqliteFactory(t, true))
+}
+
+func TestQueryReadOnlyResetsConnection(t *testing.T) {
+	db, err := util.SqliteConnect(filepath.Join(t.TempDir(), "query.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec("CREATE TABLE notes (text TEXT)"); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := compliance.NewSqliteService(db).QueryReadOnly(context.Background(), "SELECT count(*) FROM notes", nil, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(result.Rows) != "[[0]]" {
+		t.Errorf("expected a count of 0, got %v", result.Rows)
+	}
+
+	//the only connection went back to the pool and has to write again
+	if _, err := db.Exec("INSERT INTO notes VALUES ('written')"); err != nil {
+		t.Errorf("expected the connection to be writable after the query, got %v", err)
+	}
+}
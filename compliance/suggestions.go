@@ -0,0 +1,161 @@
+package compliance
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// states of a suggestion
+const (
+	SuggestionPending  = "pending"
+	SuggestionApproved = "approved"
+	SuggestionRejected = "rejected"
+)
+
+// where suggestions come from
+const (
+	SuggestionSourceForm = "form"
+)
+
+// Suggestion is a feature or paper a follower would like the bot to watch. Paper or Name is what was read out of Text,
+// and what gets watched once the suggestion is approved
+type Suggestion struct {
+	Id        int64        `db:"id"`
+	Source    string       `db:"source"`
+	Author    string       `db:"author"` //whatever the follower gave to be credited as, may be empty
+	Text      string       `db:"text"`
+	Paper     string       `db:"paper"`
+	Name      string       `db:"name"`
+	Timestamp time.Time    `db:"timestamp"`
+	Status    string       `db:"status"`
+	Decided   sql.NullTime `db:"decided"`
+}
+
+// Watch is an entry of the watch-list. it matches the first listing of a feature with the paper, or if there is no
+// paper, with a name containing Name
+type Watch struct {
+	Id            int64         `db:"id"`
+	Paper         string        `db:"paper"`
+	Name          string        `db:"name"`
+	SuggestionId  sql.NullInt64 `db:"suggestion_id"`
+	Added         time.Time     `db:"added"`
+	ListedEntryId sql.NullInt64 `db:"listed_entry_id"` //entry the watched feature was first listed with
+}
+
+// Matches tells if the feature is the one watched for
+func (w *Watch) Matches(feature *Feature) bool {
+	if w.Paper != "" {
+		return strings.EqualFold(w.Paper, PaperNumber(feature))
+	}
+
+	return w.Name != "" && strings.Contains(strings.ToLower(feature.Name), strings.ToLower(w.Name))
+}
+
+// StoreSuggestion stores a new pending suggestion and sets its id
+func (s *SqliteService) StoreSuggestion(ctx context.Context, suggestion *Suggestion) error {
+	query := `INSERT INTO suggestions (source, author, text, paper, name, timestamp, status)
+		VALUES(:source, :author, :text, :paper, :name, :timestamp, :status)`
+
+	suggestion.Timestamp = time.Now()
+	suggestion.Status = SuggestionPending
+
+	res, err := s.db.NamedExecContext(ctx, query, suggestion)
+	if err != nil {
+		return errors.Wrap(err, "failed to store suggestion")
+	}
+
+	suggestion.Id, err = res.LastInsertId()
+	if err != nil {
+		return errors.Wrap(err, "failed to get id of stored suggestion")
+	}
+
+	return nil
+}
+
+// GetSuggestions lists the suggestions with the status, or all of them if status is empty, oldest first
+func (s *SqliteService) GetSuggestions(ctx context.Context, status string) ([]Suggestion, error) {
+	query := `SELECT id, source, author, text, paper, name, timestamp, status, decided FROM suggestions
+		WHERE ?='' OR status=?
+		ORDER BY id`
+
+	var result []Suggestion
+	if err := s.db.SelectContext(ctx, &result, query, status, status); err != nil {
+		return nil, errors.Wrap(err, "could not get suggestions")
+	}
+
+	return result, nil
+}
+
+// DecideSuggestion approves or rejects a pending suggestion. an approved one is put on the watch-list, watching for
+// the paper or name given instead of the ones read out of the suggestion if they aren't empty
+func (s *SqliteService) DecideSuggestion(ctx context.Context, id int64, approve bool, paper string, name string) error {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "Failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	suggestion := Suggestion{}
+	err = tx.GetContext(ctx, &suggestion, "SELECT id, source, author, text, paper, name, timestamp, status, decided FROM suggestions WHERE id=?", id)
+	if err == sql.ErrNoRows {
+		return errors.Errorf("no suggestion with id %v", id)
+	} else if err != nil {
+		return errors.Wrap(err, "could not get suggestion")
+	}
+
+	if suggestion.Status != SuggestionPending {
+		return errors.Errorf("suggestion %v was %v already", id, suggestion.Status)
+	}
+
+	status := SuggestionRejected
+	if approve {
+		status = SuggestionApproved
+
+		if paper == "" && name == "" {
+			paper, name = suggestion.Paper, suggestion.Name
+		}
+		if paper == "" && name == "" {
+			return errors.Errorf("suggestion %v names no paper or feature to watch, give one", id)
+		}
+
+		query := "INSERT INTO watchlist (paper, name, suggestion_id, added) VALUES(?, ?, ?, ?)"
+		if _, err := tx.ExecContext(ctx, query, paper, name, id, time.Now()); err != nil {
+			return errors.Wrap(err, "failed to add to the watch-list")
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE suggestions SET status=?, decided=? WHERE id=?", status, time.Now(), id); err != nil {
+		return errors.Wrap(err, "failed to decide on suggestion")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "Failed to commit transaction")
+	}
+
+	return nil
+}
+
+// GetWatchlist lists the watch-list, including the entries whose feature was listed already
+func (s *SqliteService) GetWatchlist(ctx context.Context) ([]Watch, error) {
+	query := "SELECT id, paper, name, suggestion_id, added, listed_entry_id FROM watchlist ORDER BY id"
+
+	var result []Watch
+	if err := s.db.SelectContext(ctx, &result, query); err != nil {
+		return nil, errors.Wrap(err, "could not get watch-list")
+	}
+
+	return result, nil
+}
+
+// SetWatchListed records the entry the watched feature was first listed with
+func (s *SqliteService) SetWatchListed(ctx context.Context, watchID int64, entryID int64) error {
+	if _, err := s.db.ExecContext(ctx, "UPDATE watchlist SET listed_entry_id=? WHERE id=?", entryID, watchID); err != nil {
+		return errors.Wrap(err, "failed to set watched feature listed")
+	}
+
+	return nil
+}
@@ -0,0 +1,24 @@
+package compliance
+
+import "testing"
+
+func TestWatchMatches(t *testing.T) {
+	feature := sampleFeature()
+
+	for _, test := range []struct {
+		watch   Watch
+		matches bool
+	}{
+		{Watch{Paper: "P0702"}, true},
+		{Watch{Paper: "p0702"}, true},
+		{Watch{Paper: "P0703"}, false},
+		{Watch{Name: "template argument deduction"}, true},
+		{Watch{Name: "Template Argument"}, true},
+		{Watch{Name: "concepts"}, false},
+		{Watch{}, false},
+	} {
+		if matches := test.watch.Matches(feature); matches != test.matches {
+			t.Errorf("%+v: got %v, want %v", test.watch, matches, test.matches)
+		}
+	}
+}
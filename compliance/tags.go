@@ -0,0 +1,72 @@
+package compliance
+
+import (
+	"strings"
+)
+
+// ReportTags gives the tags a report of the change ends with: the mentions of the compilers it is about, in the order
+// of its deltas, then the hashtags. mentions are by compiler key, and tags without their # or @ get it
+func ReportTags(change Change, hashtags []string, mentions map[string]string) (result []string) {
+	seen := map[string]bool{}
+	add := func(tag string) {
+		if tag != "" && !seen[strings.ToLower(tag)] {
+			seen[strings.ToLower(tag)] = true
+			result = append(result, tag)
+		}
+	}
+
+	for _, delta := range change.Deltas {
+		if mention := strings.TrimPrefix(mentions[delta.Compiler], "@"); mention != "" {
+			add("@" + mention)
+		}
+	}
+	for _, hashtag := range hashtags {
+		if hashtag = strings.TrimPrefix(hashtag, "#"); hashtag != "" {
+			add("#" + hashtag)
+		}
+	}
+
+	return
+}
+
+// ValidTag tells if a hashtag or mention is a single word twitter links, like #cpp or @VisualC
+func ValidTag(tag string) bool {
+	tag = strings.TrimLeft(tag, "#@")
+	if tag == "" {
+		return false
+	}
+
+	for _, r := range tag {
+		if !(r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// WithTags ends the text of a report with a line of the tags that have room for them, in order. they are only added
+// where they fit, like links, but no tag is worth trimming the report for
+func WithTags(text string, tags []string) string {
+	if text == "" {
+		return text
+	}
+
+	line := ""
+	for _, tag := range tags {
+		candidate := tag
+		if line != "" {
+			candidate = line + " " + tag
+		}
+
+		if hasRoom(text, "\n\n"+candidate) {
+			line = candidate
+		}
+	}
+
+	if line == "" {
+		return text
+	}
+
+	return text + "\n\n" + line
+}
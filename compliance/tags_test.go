@@ -0,0 +1,20 @@
+package compliance
+
+import "testing"
+
+func TestValidTag(t *testing.T) {
+	for _, test := range []struct {
+		tag   string
+		valid bool
+	}{
+		{"#cpp", true},
+		{"@VisualC", true},
+		{"c++", false},
+		{"two words", false},
+		{"#", false},
+	} {
+		if valid := ValidTag(test.tag); valid != test.valid {
+			t.Errorf("%v: got %v, want %v", test.tag, valid, test.valid)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package compliance
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultVariant is the name of the built-in phrasing of a report, used for kinds without configured variants
+const DefaultVariant = "default"
+
+// ReportVariant is a phrasing of the reports of one kind of change, picked at random by weight among the variants of
+// its kind. Template is a text/template executed on a ReportData
+type ReportVariant struct {
+	Kind     string //kind of change, like support or new_listing
+	Name     string //name engagement is tracked under
+	Weight   int
+	Template string
+}
+
+// ReportData is what report templates can use
+type ReportData struct {
+	Language      string //name of the language, like C++ or C
+	Standard      string //standard revision the feature is part of, like C++20 or C23
+	CppVersion    int
+	Name          string
+	PreviousName  string //name before a rename
+	Paper         string
+	PreviousPaper string //paper before a paper update
+	From          string //support of the changed compilers before the change, a line per compiler
+	To            string //support of the changed compilers after the change, or all listed support for new listings
+	Changes       string //what changed of the support of the changed compilers, a line per compiler with arrows
+	Transitions   string //how the support level of the changed compilers changed, a line each like "GCC gains full support"
+	Link          string //link to the paper, shortened if a shortener is set up. empty unless links are reported
+}
+
+type parsedVariant struct {
+	name     string
+	weight   int
+	template *template.Template
+}
+
+// ReportTemplates picks and renders the variant of every report
+type ReportTemplates struct {
+	variants map[string][]parsedVariant
+	random   *rand.Rand
+	mutex    sync.Mutex
+}
+
+var reportKinds = []string{ChangeRemoved, ChangeNewListing, ChangeRename, ChangePaper, ChangeSupport, ChangeText}
+
+// NewReportTemplates parses the configured variants. kinds without variants keep the built-in phrasing
+func NewReportTemplates(variants []ReportVariant) (*ReportTemplates, error) {
+	result := &ReportTemplates{
+		variants: map[string][]parsedVariant{},
+		random:   rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+
+	for _, variant := range variants {
+		known := false
+		for _, kind := range reportKinds {
+			known = known || kind == variant.Kind
+		}
+		if !known {
+			return nil, fmt.Errorf("report variant %v has unknown kind '%v'", variant.Name, variant.Kind)
+		}
+
+		if variant.Name == "" || variant.Name == DefaultVariant {
+			return nil, fmt.Errorf("report variant of kind %v needs a name other than '%v'", variant.Kind, DefaultVariant)
+		}
+
+		for _, existing := range result.variants[variant.Kind] {
+			if existing.name == variant.Name {
+				return nil, fmt.Errorf("report variant %v configured more than once for kind %v", variant.Name, variant.Kind)
+			}
+		}
+
+		if variant.Weight <= 0 {
+			return nil, fmt.Errorf("report variant %v needs a positive weight", variant.Name)
+		}
+
+		parsed, err := template.New(variant.Name).Parse(variant.Template)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid template of report variant %v", variant.Name)
+		}
+
+		result.variants[variant.Kind] = append(result.variants[variant.Kind], parsedVariant{variant.Name, variant.Weight, parsed})
+	}
+
+	return result, nil
+}
+
+func reportData(options ReportOptions, change Change, link string) ReportData {
+	lines := ListingLines(change, false)
+	data := ReportData{
+		Link:        link,
+		Language:    LanguageName(change.Feature.Language),
+		Standard:    change.Feature.Standard(),
+		CppVersion:  change.Feature.CppVersion,
+		Name:        change.Feature.Name,
+		Paper:       fromNullString(change.Feature.PaperName),
+		From:        deltaListing(options, change.Deltas, true),
+		To:          deltaListing(options, change.Deltas, false),
+		Changes:     SupportListing(options, ChannelTwitter, lines),
+		Transitions: SupportTransitions(options, ChannelTwitter, lines),
+	}
+
+	if change.Previous != nil {
+		data.PreviousName = change.Previous.Name
+		data.PreviousPaper = fromNullString(change.Previous.PaperName)
+	}
+
+	return data
+}
+
+func (t *ReportTemplates) pick(kind string) *parsedVariant {
+	variants := t.variants[kind]
+	if len(variants) == 0 {
+		return nil
+	}
+
+	total := 0
+	for _, variant := range variants {
+		total += variant.weight
+	}
+
+	t.mutex.Lock()
+	roll := t.random.Intn(total)
+	t.mutex.Unlock()
+
+	for i := range variants {
+		if roll < variants[i].weight {
+			return &variants[i]
+		}
+		roll -= variants[i].weight
+	}
+
+	return &variants[len(variants)-1]
+}
+
+// DefaultReportText renders a change with the built-in phrasing, ending with the link if there is one. changes not
+// worth reporting give an empty string
+func DefaultReportText(options ReportOptions, change Change, link string) string {
+	text := ChangeReportText(options, change)
+	if text != "" && link != "" {
+		text += "\n\n" + link
+	}
+
+	return text
+}
+
+// Render renders a change with a variant picked by weight among those of its kind, and tells which one it was.
+// changes not worth reporting give an empty string
+func (t *ReportTemplates) Render(options ReportOptions, change Change, link string) (text string, variant string, err error) {
+	picked := t.pick(change.Kind)
+	if picked == nil {
+		return DefaultReportText(options, change, link), DefaultVariant, nil
+	}
+
+	var buffer bytes.Buffer
+	if err := picked.template.Execute(&buffer, reportData(options, change, link)); err != nil {
+		return "", picked.name, errors.Wrapf(err, "could not render report variant %v", picked.name)
+	}
+
+	return buffer.String(), picked.name, nil
+}
+
+// RenderEach renders a change with every variant of its kind, or the built-in phrasing if it has none, so that all of
+// them can be checked rather than the one picked. the texts are by variant name
+func (t *ReportTemplates) RenderEach(options ReportOptions, change Change, link string) (map[string]string, error) {
+	variants := t.variants[change.Kind]
+	if len(variants) == 0 {
+		return map[string]string{DefaultVariant: DefaultReportText(options, change, link)}, nil
+	}
+
+	texts := map[string]string{}
+	for _, variant := range variants {
+		var buffer bytes.Buffer
+		if err := variant.template.Execute(&buffer, reportData(options, change, link)); err != nil {
+			return nil, errors.Wrapf(err, "could not render report variant %v", variant.name)
+		}
+		texts[variant.name] = buffer.String()
+	}
+
+	return texts, nil
+}
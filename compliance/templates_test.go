@@ -0,0 +1,66 @@
+package compliance
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReportTemplates(t *testing.T) {
+	templates, err := NewReportTemplates([]ReportVariant{
+		{Kind: ChangeSupport, Name: "short", Weight: 1, Template: "C++{{.CppVersion}} \"{{.Name}}\" gained support:\n{{.To}}\n{{.Link}}"},
+		{Kind: ChangeSupport, Name: "detailed", Weight: 3, Template: "Support of C++{{.CppVersion}} \"{{.Name}}\" ({{.Paper}}) changed from:\n{{.From}}\nto:\n{{.To}}"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	change := sampleChange(t, sampleFeature(), sampleSupported())
+	texts, err := templates.RenderEach(ReportOptions{}, change, "https://wg21.link/P0702R1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"short": "C++20 \"Initializer list constructors in class template argument deduction\" gained support:\n" +
+			"GCC - [yes] 9*(still some bugs)\nMSVC - [yes] 19.20\nApple Clang - [yes] 10.0.1\nhttps://wg21.link/P0702R1",
+		"detailed": "Support of C++20 \"Initializer list constructors in class template argument deduction\" (P0702R1) changed from:\n" +
+			"GCC - [no]\nMSVC - [no]\nApple Clang - [no]\nto:\nGCC - [yes] 9*(still some bugs)\nMSVC - [yes] 19.20\nApple Clang - [yes] 10.0.1",
+	}
+	for name := range texts {
+		texts[name] = trimLines(texts[name])
+	}
+	if !reflect.DeepEqual(texts, want) {
+		t.Errorf("got %q, want %q", texts, want)
+	}
+
+	for i := 0; i < 10; i++ {
+		text, variant, err := templates.Render(ReportOptions{}, change, "https://wg21.link/P0702R1")
+		if err != nil || trimLines(text) != want[variant] {
+			t.Errorf("variant %v rendered %q, %v", variant, text, err)
+		}
+	}
+
+	//kinds without variants keep the built-in phrasing
+	listing := sampleChange(t, nil, sampleFeature())
+	if text, variant, err := templates.Render(ReportOptions{}, listing, ""); variant != DefaultVariant || text != ChangeReportText(ReportOptions{}, listing) || err != nil {
+		t.Errorf("new listing rendered with variant %v: %q, %v", variant, text, err)
+	}
+}
+
+func TestReportTemplatesInvalid(t *testing.T) {
+	for _, test := range []struct {
+		what    string
+		variant ReportVariant
+		err     string
+	}{
+		{"unknown kind", ReportVariant{Kind: "nonsense", Name: "broken", Weight: 1}, "unknown kind 'nonsense'"},
+		{"default name", ReportVariant{Kind: ChangeSupport, Name: DefaultVariant, Weight: 1}, "needs a name"},
+		{"no weight", ReportVariant{Kind: ChangeSupport, Name: "short"}, "positive weight"},
+		{"broken template", ReportVariant{Kind: ChangeSupport, Name: "short", Weight: 1, Template: "{{.Name"}, "invalid template"},
+	} {
+		if _, err := NewReportTemplates([]ReportVariant{test.variant}); err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%v: got %v, want an error about %v", test.what, err, test.err)
+		}
+	}
+}
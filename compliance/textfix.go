@@ -0,0 +1,97 @@
+package compliance
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// support cell columns holding text that can be fixed up
+const (
+	FieldDisplayText = "display_text"
+	FieldExtraText   = "extra_text"
+)
+
+// TextFix is one support cell text rewritten by FixText
+type TextFix struct {
+	FeatureId int64     `db:"feature_id"`
+	Name      string    `db:"name"`
+	Timestamp time.Time `db:"timestamp"`
+	Compiler  string    `db:"compiler"`
+	Field     string    `db:"-"`
+	OldText   string    `db:"-"`
+	NewText   string    `db:"-"`
+}
+
+type textFixCell struct {
+	TextFix
+	RowId int64          `db:"rowid"`
+	Text  sql.NullString `db:"text"`
+}
+
+// FixText replaces the matches of pattern in the given field of the stored support cells of a compiler, or of all compilers
+// if compiler is empty. every rewrite is recorded in the text_fixes table. with dryRun nothing is written, the fixes are only returned
+func (s *SqliteService) FixText(ctx context.Context, compiler string, field string, pattern *regexp.Regexp, replacement string, dryRun bool) ([]TextFix, error) {
+	if field != FieldDisplayText && field != FieldExtraText {
+		return nil, errors.Errorf("unknown text field '%s'", field)
+	}
+
+	//field is one of the known column names, so it can go into the query
+	query := `SELECT cs.rowid, cs.feature_id, f.name, cs.timestamp, cs.compiler, cs.` + field + ` AS text
+		FROM compiler_support cs
+		JOIN features f ON f.id=cs.feature_id
+		WHERE (?='' OR cs.compiler=?) AND cs.` + field + ` IS NOT NULL
+		ORDER BY cs.timestamp, cs.rowid`
+	updateQuery := `UPDATE compiler_support SET ` + field + `=? WHERE rowid=?`
+	auditQuery := `INSERT INTO text_fixes
+		(timestamp, feature_id, compiler, field, pattern, old_text, new_text)
+		VALUES(?, ?, ?, ?, ?, ?, ?)`
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	var cells []textFixCell
+	if err := tx.SelectContext(ctx, &cells, query, compiler, compiler); err != nil {
+		return nil, errors.Wrap(err, "could not select support texts")
+	}
+
+	var result []TextFix
+	now := time.Now()
+
+	for _, cell := range cells {
+		fixed := pattern.ReplaceAllString(cell.Text.String, replacement)
+		if fixed == cell.Text.String {
+			continue
+		}
+
+		fix := cell.TextFix
+		fix.Field = field
+		fix.OldText = cell.Text.String
+		fix.NewText = fixed
+		result = append(result, fix)
+
+		if dryRun {
+			continue
+		}
+
+		if _, err := tx.ExecContext(ctx, updateQuery, fixed, cell.RowId); err != nil {
+			return nil, errors.Wrap(err, "failed to update support text")
+		}
+
+		if _, err := tx.ExecContext(ctx, auditQuery, now, fix.FeatureId, fix.Compiler, field, pattern.String(), fix.OldText, fix.NewText); err != nil {
+			return nil, errors.Wrap(err, "failed to record text fix")
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "Failed to commit transaction")
+	}
+
+	return result, nil
+}
@@ -0,0 +1,241 @@
+package compliance
+
+import (
+	"io/ioutil"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+// TextCommon is the name of the template of a channel that defines what the others share, like how support reads
+const TextCommon = "common"
+
+// TextData is what report text templates get: the report data, the kind of the change and its compiler deltas
+type TextData struct {
+	ReportData
+	Kind        string
+	PaperLink   string //link to the paper, never shortened
+	Deltas      []CompilerDelta
+	Lines       []ListingLine //a listing line per delta, showing only what changed if ChangesOnly is set
+	ChangesOnly bool          //see ReportOptions
+	OldPaper    PaperRef      //paper before the change, the same as NewPaper for new listings
+	NewPaper    PaperRef
+}
+
+// PaperRef is the paper a feature refers to, as listed
+type PaperRef struct {
+	Name string
+	Link string
+}
+
+// PaperOf gives the paper the feature refers to
+func PaperOf(feature *Feature) PaperRef {
+	return PaperRef{fromNullString(feature.PaperName), fromNullString(feature.PaperLink)}
+}
+
+// String gives the name of the paper, or its link if it has no name, or "none" if the feature refers to no paper
+func (p PaperRef) String() string {
+	switch {
+	case p.Name != "":
+		return p.Name
+	case p.Link != "":
+		return p.Link
+	}
+
+	return "none"
+}
+
+// ReportOptions is how the reports of every channel are worded. the zero value words them with the built-in texts and
+// lists the whole support before and after every change
+type ReportOptions struct {
+	Texts       *ReportTexts //texts rewording the reports, see LoadTexts. the built-in ones if nil
+	ChangesOnly bool         //list only what changed of the support of every compiler, like "MSVC: [no] → [yes] 19.38"
+}
+
+// NewTextData gives the data report text templates get for the change
+func NewTextData(options ReportOptions, change Change, link string) TextData {
+	oldPaper := PaperOf(change.Feature)
+	if change.Previous != nil {
+		oldPaper = PaperOf(change.Previous)
+	}
+
+	return TextData{
+		ReportData:  reportData(options, change, link),
+		Kind:        change.Kind,
+		PaperLink:   fromNullString(change.Feature.PaperLink),
+		Deltas:      change.Deltas,
+		Lines:       ListingLines(change, !options.ChangesOnly),
+		ChangesOnly: options.ChangesOnly,
+		OldPaper:    oldPaper,
+		NewPaper:    PaperOf(change.Feature),
+	}
+}
+
+// textDefaults is the built-in wording of a channel
+type textDefaults struct {
+	common string
+	named  map[string]string
+	funcs  template.FuncMap
+}
+
+var textRegistry = map[string]textDefaults{}
+
+// functions templates of all channels can use, besides those of their channel
+var textFuncs = template.FuncMap{
+	"compiler":    CompilerDisplayName,
+	"level":       SupportLevelName,
+	"supportText": SupportText,
+	"contains":    strings.Contains,
+	"hasPrefix":   strings.HasPrefix,
+	"hasSuffix":   strings.HasSuffix,
+	"lower":       strings.ToLower,
+	"upper":       strings.ToUpper,
+	"trim":        strings.TrimSpace,
+	"join":        strings.Join,
+	"split":       strings.Split,
+	"replace": func(s string, old string, new string) string {
+		return strings.Replace(s, old, new, -1)
+	},
+}
+
+// RegisterTexts registers the built-in wording of a channel, by the package rendering its messages. common defines what
+// the others share, named has a template per kind of change and whatever else the channel renders, and funcs are
+// the functions they can use besides those of textFuncs
+func RegisterTexts(channel string, common string, named map[string]string, funcs template.FuncMap) {
+	textRegistry[channel] = textDefaults{common, named, funcs}
+}
+
+// ReportTexts are the templates reports are worded with, a set per channel
+type ReportTexts struct {
+	sets map[string]*template.Template
+}
+
+// LoadTexts parses the built-in wording of every channel with the templates in dir on top, so wording and emoji can be
+// changed without building the bot again. they are named like twitter/support.tmpl, after the channel and the kind of
+// change or another template of the channel, and common.tmpl of a channel can redefine what its templates share, like
+// the "compilerSupport" template. an empty dir gives the built-in wording
+func LoadTexts(dir string) (*ReportTexts, error) {
+	texts := &ReportTexts{sets: map[string]*template.Template{}}
+
+	for channel, defaults := range textRegistry {
+		set := template.New(channel).Funcs(textFuncs).Funcs(defaults.funcs)
+		if _, err := set.New("listing").Parse(listingText); err != nil {
+			return nil, errors.Wrap(err, "invalid built-in listing texts")
+		}
+		if _, err := set.New(TextCommon).Parse(defaults.common); err != nil {
+			return nil, errors.Wrapf(err, "invalid built-in %v texts", channel)
+		}
+		for name, text := range defaults.named {
+			if _, err := set.New(name).Parse(text); err != nil {
+				return nil, errors.Wrapf(err, "invalid built-in %v text %v", channel, name)
+			}
+		}
+
+		texts.sets[channel] = set
+	}
+
+	if dir == "" {
+		return texts, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*", "*.tmpl"))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list report texts")
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return nil, errors.Wrap(err, "could not read report texts")
+	}
+
+	//common first, as the others may use what it defines
+	sort.Slice(files, func(i, j int) bool {
+		return filepath.Base(files[i]) == TextCommon+".tmpl" && filepath.Base(files[j]) != TextCommon+".tmpl"
+	})
+
+	for _, file := range files {
+		channel := filepath.Base(filepath.Dir(file))
+		name := strings.TrimSuffix(filepath.Base(file), ".tmpl")
+
+		set, ok := texts.sets[channel]
+		if !ok {
+			return nil, errors.Errorf("report text %v is for unknown channel %v", file, channel)
+		}
+		if _, ok := textRegistry[channel].named[name]; !ok && name != TextCommon {
+			return nil, errors.Errorf("report text %v replaces nothing, the texts of %v are %v", file, channel, strings.Join(textNames(channel), ", "))
+		}
+
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read report text")
+		}
+		if _, err := set.New(name).Parse(string(data)); err != nil {
+			return nil, errors.Wrapf(err, "invalid report text %v", file)
+		}
+	}
+
+	return texts, nil
+}
+
+func textNames(channel string) (names []string) {
+	names = append(names, TextCommon)
+	for name := range textRegistry[channel].named {
+		names = append(names, name)
+	}
+	sort.Strings(names[1:])
+
+	return
+}
+
+// builtinTexts parses the built-in texts on first use, once every channel registered its own
+var builtinTexts = sync.OnceValue(func() *ReportTexts {
+	texts, err := LoadTexts("")
+	if err != nil {
+		panic(err)
+	}
+
+	return texts
+})
+
+func (t *ReportTexts) render(channel string, name string, data interface{}) (string, bool, error) {
+	set, ok := t.sets[channel]
+	if !ok || set.Lookup(name) == nil {
+		return "", false, nil
+	}
+
+	var text strings.Builder
+	if err := set.ExecuteTemplate(&text, name, data); err != nil {
+		return "", true, errors.Wrapf(err, "could not render the %v text %v", channel, name)
+	}
+
+	return text.String(), true, nil
+}
+
+// RenderText renders the named template of the channel with the texts of the options, like the one of a kind of change.
+// channels without such a template give an empty string, and a template that fails is replaced by the built-in one
+func RenderText(options ReportOptions, channel string, name string, data interface{}) string {
+	builtin := builtinTexts()
+	active := options.Texts
+	if active == nil {
+		active = builtin
+	}
+
+	text, ok, err := active.render(channel, name, data)
+	if err == nil && ok {
+		return text
+	}
+	if err != nil {
+		slog.Warn("using the built-in text", "channel", channel, "text", name, "err", err)
+	}
+
+	text, _, err = builtin.render(channel, name, data)
+	if err != nil {
+		slog.Error("error rendering built-in text", "channel", channel, "text", name, "err", err)
+	}
+
+	return text
+}
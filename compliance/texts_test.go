@@ -0,0 +1,31 @@
+package compliance
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReportOptionsTexts(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, ChannelTwitter), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ChannelTwitter, ChangeSupport+".tmpl"), []byte(`{{.Name}} is better supported`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	texts, err := LoadTexts(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//the texts only word the reports rendered with them, the others keep the built-in ones
+	change := sampleChange(t, sampleFeature(), sampleSupported())
+	if report := ChangeReportText(ReportOptions{Texts: texts}, change); report != change.Feature.Name+" is better supported" {
+		t.Errorf("with the texts: got %q", report)
+	}
+	if report := ChangeReportText(ReportOptions{}, change); report == change.Feature.Name+" is better supported" {
+		t.Errorf("without the texts: got %q", report)
+	}
+}
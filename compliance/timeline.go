@@ -0,0 +1,80 @@
+package compliance
+
+import (
+	"time"
+)
+
+// TimelineSpan is a stretch of time during which the support of a compiler stayed the same
+type TimelineSpan struct {
+	Start   time.Time
+	End     time.Time
+	Support CompilerSupport
+}
+
+// TimelineLane is the support of one compiler over the history of a feature
+type TimelineLane struct {
+	Compiler string
+	Spans    []TimelineSpan
+}
+
+// Timeline turns the history of a feature, oldest entry first, into a lane per compiler in the order the compilers
+// first appear. a new span starts whenever the support level or the display text of a compiler changes, and the last
+// span of every lane lasts until end, unless the feature was removed by then
+func Timeline(history []Feature, end time.Time) []TimelineLane {
+	var lanes []TimelineLane
+	laneIndex := map[string]int{}
+
+	for i := range history {
+		entry := &history[i]
+
+		//while a feature is removed, none of its lanes has a span
+		if entry.Removed {
+			for j := range lanes {
+				if count := len(lanes[j].Spans); count > 0 && lanes[j].Spans[count-1].End.IsZero() {
+					lanes[j].Spans[count-1].End = entry.Timestamp
+				}
+			}
+			continue
+		}
+
+		for _, support := range entry.Compilers {
+			index, ok := laneIndex[support.Compiler]
+			if !ok {
+				index = len(lanes)
+				laneIndex[support.Compiler] = index
+				lanes = append(lanes, TimelineLane{Compiler: support.Compiler})
+			}
+
+			lane := &lanes[index]
+			if count := len(lane.Spans); count > 0 && lane.Spans[count-1].End.IsZero() {
+				last := &lane.Spans[count-1]
+				if last.Support.Support == support.Support && last.Support.DisplayText.String == support.DisplayText.String {
+					continue
+				}
+
+				last.End = entry.Timestamp
+			}
+
+			lane.Spans = append(lane.Spans, TimelineSpan{Start: entry.Timestamp, Support: support})
+		}
+	}
+
+	for i := range lanes {
+		if last := &lanes[i].Spans[len(lanes[i].Spans)-1]; last.End.IsZero() {
+			last.End = end
+		}
+	}
+
+	return lanes
+}
+
+// At gives the span of the lane covering the given time, or nil if the compiler wasn't listed yet
+func (lane *TimelineLane) At(at time.Time) *TimelineSpan {
+	for i := range lane.Spans {
+		if !at.Before(lane.Spans[i].Start) && at.Before(lane.Spans[i].End) {
+			return &lane.Spans[i]
+		}
+	}
+
+	return nil
+}
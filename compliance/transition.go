@@ -0,0 +1,35 @@
+package compliance
+
+// transitions of the support level of a compiler, see SupportTransition
+const (
+	TransitionPartial   = "partial"   //no → partial
+	TransitionFull      = "full"      //no → yes
+	TransitionCompleted = "completed" //partial → yes
+	TransitionRegressed = "regressed" //yes → partial or no, partial → no
+)
+
+// SupportTransition classifies a change of support level, or gives "" if the level stayed the same
+func SupportTransition(previous int, next int) string {
+	switch {
+	case previous == next:
+		return ""
+	case previous == SupportNo && next == SupportPartial:
+		return TransitionPartial
+	case previous == SupportNo && next == SupportYes:
+		return TransitionFull
+	case previous == SupportPartial && next == SupportYes:
+		return TransitionCompleted
+	}
+
+	return TransitionRegressed
+}
+
+// Transition classifies how the support level of the compiler changed, or gives "" if it stayed the same or the
+// compiler wasn't listed before, like in new listings
+func (d CompilerDelta) Transition() string {
+	if d.Previous.Compiler == "" {
+		return ""
+	}
+
+	return SupportTransition(d.Previous.Support, d.Next.Support)
+}
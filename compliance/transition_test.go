@@ -0,0 +1,36 @@
+package compliance
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTransitions(t *testing.T) {
+	transitioned := sampleFeature(testSupport(CompilerGcc, 2, "11", ""), testSupport(CompilerClang, 0, "", ""), testSupport(CompilerMsvc, 1, "19.30", ""))
+	change := sampleChange(t, sampleFeature(), transitioned)
+
+	transitions := map[string]string{}
+	for _, delta := range change.Deltas {
+		transitions[delta.Compiler] = delta.Transition()
+	}
+	want := map[string]string{CompilerGcc: "partial", CompilerClang: "regressed", CompilerMsvc: "full"}
+	if !reflect.DeepEqual(transitions, want) {
+		t.Errorf("got transitions %v, want %v", transitions, want)
+	}
+
+	phrased := "GCC gains partial support\nClang drops support\nMSVC gains full support"
+	if text := SupportTransitions(ReportOptions{}, ChannelTwitter, ListingLines(change, false)); text != phrased {
+		t.Errorf("got phrased\n%v\nwant\n%v", text, phrased)
+	}
+
+	templates, err := NewReportTemplates([]ReportVariant{
+		{Kind: ChangeSupport, Name: "transitions", Weight: 1, Template: "{{.Standard}} \"{{.Name}}\":\n{{.Transitions}}"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, _, err := templates.Render(ReportOptions{}, change, "")
+	if want := "C++20 \"Initializer list constructors in class template argument deduction\":\n" + phrased; report != want || err != nil {
+		t.Errorf("got variant\n%v\n%v\nwant\n%v", report, err, want)
+	}
+}
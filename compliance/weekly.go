@@ -0,0 +1,197 @@
+package compliance
+
+import (
+	"context"
+	"cppimpbot/limits"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// CompilerWeek is how the support of one compiler or library changed over a week
+type CompilerWeek struct {
+	Compiler string
+	Changes  int //changes of its support
+	Gained   int //features it came to fully support
+}
+
+// StandardWeek is how many features of a standard changed over a week
+type StandardWeek struct {
+	Language   string
+	CppVersion int
+	Changes    int
+}
+
+// WeeklySummary is what changed over a week, most changes first
+type WeeklySummary struct {
+	Since       time.Time
+	Until       time.Time
+	Changes     int
+	NewListings int
+	Removed     int
+	Compilers   []CompilerWeek
+	Standards   []StandardWeek
+	Highlights  []string //features that came to be fully supported and newly listed ones, a line each
+}
+
+// SummarizeWeek counts the changes of a week per compiler and per standard, and picks the features a compiler came
+// to fully support and the newly listed ones as highlights
+func SummarizeWeek(changes []Change, since time.Time, until time.Time) WeeklySummary {
+	summary := WeeklySummary{Since: since, Until: until, Changes: len(changes)}
+
+	compilers := map[string]*CompilerWeek{}
+	standards := map[string]*StandardWeek{}
+	var gained, listed []string
+
+	for _, change := range changes {
+		feature := change.Feature
+
+		key := StandardName(feature.Language, feature.CppVersion)
+		if standards[key] == nil {
+			standards[key] = &StandardWeek{Language: feature.Language, CppVersion: feature.CppVersion}
+		}
+		standards[key].Changes++
+
+		switch change.Kind {
+		case ChangeNewListing:
+			summary.NewListings++
+			listed = append(listed, fmt.Sprintf("- %v \"%v\" was listed", feature.Standard(), feature.Name))
+			continue
+		case ChangeRemoved:
+			summary.Removed++
+			continue
+		}
+
+		for _, delta := range change.Deltas {
+			if compilers[delta.Compiler] == nil {
+				compilers[delta.Compiler] = &CompilerWeek{Compiler: delta.Compiler}
+			}
+			compilers[delta.Compiler].Changes++
+
+			if delta.Next.Support == SupportYes && delta.Previous.Support != SupportYes {
+				compilers[delta.Compiler].Gained++
+				gained = append(gained, fmt.Sprintf("- %v fully supports %v \"%v\"", CompilerDisplayName(delta.Compiler), feature.Standard(), feature.Name))
+			}
+		}
+	}
+
+	for _, compiler := range compilers {
+		summary.Compilers = append(summary.Compilers, *compiler)
+	}
+	sort.Slice(summary.Compilers, func(i, j int) bool {
+		a, b := summary.Compilers[i], summary.Compilers[j]
+		if a.Changes != b.Changes {
+			return a.Changes > b.Changes
+		}
+		return a.Compiler < b.Compiler
+	})
+
+	for _, standard := range standards {
+		summary.Standards = append(summary.Standards, *standard)
+	}
+	sort.Slice(summary.Standards, func(i, j int) bool {
+		a, b := summary.Standards[i], summary.Standards[j]
+		if a.Changes != b.Changes {
+			return a.Changes > b.Changes
+		}
+		if a.Language != b.Language {
+			return a.Language < b.Language
+		}
+		return a.CppVersion > b.CppVersion
+	})
+
+	summary.Highlights = append(gained, listed...)
+
+	return summary
+}
+
+// weeklySummaryHeader sums up the week in a few lines, like "[Weekly Summary] March 1 to March 8: 12 changes ..."
+func weeklySummaryHeader(summary WeeklySummary) string {
+	changes := "changes"
+	if summary.Changes == 1 {
+		changes = "change"
+	}
+	header := fmt.Sprintf("[Weekly Summary] %v to %v: %v %v on cppreference", summary.Since.Format("January 2"),
+		summary.Until.Format("January 2"), summary.Changes, changes)
+	if summary.NewListings == 1 {
+		header += ", 1 new feature listed"
+	} else if summary.NewListings > 1 {
+		header += fmt.Sprintf(", %v new features listed", summary.NewListings)
+	}
+	if summary.Removed > 0 {
+		header += fmt.Sprintf(", %v unlisted", summary.Removed)
+	}
+	header += "."
+
+	var compilers []string
+	for _, compiler := range summary.Compilers {
+		compilers = append(compilers, fmt.Sprintf("%v %v", CompilerDisplayName(compiler.Compiler), compiler.Changes))
+	}
+	if len(compilers) > 0 {
+		header += "\nBy compiler: " + strings.Join(compilers, ", ")
+	}
+
+	var standards []string
+	for _, standard := range summary.Standards {
+		standards = append(standards, fmt.Sprintf("%v %v", StandardName(standard.Language, standard.CppVersion), standard.Changes))
+	}
+	if len(standards) > 0 {
+		header += "\nBy standard: " + strings.Join(standards, ", ")
+	}
+
+	return header
+}
+
+// WeeklySummaryText renders the whole summary as a single message, for channels without a length limit
+func WeeklySummaryText(summary WeeklySummary) string {
+	text := weeklySummaryHeader(summary)
+	if len(summary.Highlights) > 0 {
+		text += "\nHighlights:\n" + strings.Join(summary.Highlights, "\n")
+	}
+
+	return text
+}
+
+// WeeklySummaryToTwitterThread renders the summary as a thread of at most maxTweets tweets, with as many of the
+// highlights as fit. a week without changes gives no tweets
+func WeeklySummaryToTwitterThread(summary WeeklySummary, maxTweets int) []string {
+	if summary.Changes == 0 {
+		return nil
+	}
+
+	header := weeklySummaryHeader(summary)
+	if len(summary.Highlights) == 0 {
+		return limits.Twitter.Split(header)
+	}
+
+	return fitLines(header+"\nHighlights:", summary.Highlights, maxTweets)
+}
+
+// GetLastWeeklySummary gives the end of the week of the last summary posted, or the zero time if none was posted yet
+func (s *SqliteService) GetLastWeeklySummary(ctx context.Context) (time.Time, error) {
+	var timestamp time.Time
+
+	err := s.db.GetContext(ctx, &timestamp, "SELECT timestamp FROM weekly_summaries ORDER BY timestamp DESC LIMIT 1")
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil
+	} else if err != nil {
+		return time.Time{}, errors.Wrap(err, "could not get last weekly summary")
+	}
+
+	return timestamp, nil
+}
+
+// StoreWeeklySummary records that the summary of the week until the given time was posted
+func (s *SqliteService) StoreWeeklySummary(ctx context.Context, until time.Time, changes int) error {
+	query := "INSERT INTO weekly_summaries (timestamp, changes) VALUES(?, ?)"
+
+	if _, err := s.db.ExecContext(ctx, query, until, changes); err != nil {
+		return errors.Wrap(err, "could not store weekly summary")
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package compliance
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// weekChanges gives the changes of a week: GCC and Clang gaining support of C++20 modules, Clang of C++26 pack indexing
+// and GCC of C23 embed, and C++26 reflection being listed
+func weekChanges(t *testing.T) []Change {
+	t.Helper()
+
+	feature := func(name string, language string, version int, supports ...CompilerSupport) *Feature {
+		return &Feature{Name: name, Language: language, CppVersion: version, Compilers: supports}
+	}
+
+	return []Change{
+		sampleChange(t, feature("Modules", LanguageCpp, 20, testSupport(CompilerGcc, 2, "11", ""), testSupport(CompilerClang, 0, "", "")),
+			feature("Modules", LanguageCpp, 20, testSupport(CompilerGcc, 1, "14", ""), testSupport(CompilerClang, 0, "", ""))),
+		sampleChange(t, feature("Modules", LanguageCpp, 20, testSupport(CompilerGcc, 1, "14", ""), testSupport(CompilerClang, 0, "", "")),
+			feature("Modules", LanguageCpp, 20, testSupport(CompilerGcc, 1, "14", ""), testSupport(CompilerClang, 2, "17", ""))),
+		sampleChange(t, feature("Pack indexing", LanguageCpp, 26, testSupport(CompilerGcc, 0, "", ""), testSupport(CompilerClang, 0, "", "")),
+			feature("Pack indexing", LanguageCpp, 26, testSupport(CompilerGcc, 0, "", ""), testSupport(CompilerClang, 1, "19", ""))),
+		sampleChange(t, feature("Embed", LanguageC, 23, testSupport(CompilerGcc, 0, "", "")),
+			feature("Embed", LanguageC, 23, testSupport(CompilerGcc, 1, "15", ""))),
+		sampleChange(t, nil, feature("Reflection", LanguageCpp, 26, testSupport(CompilerClang, 0, "", ""))),
+	}
+}
+
+func TestSummarizeWeek(t *testing.T) {
+	since := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	summary := SummarizeWeek(weekChanges(t), since, since.AddDate(0, 0, 7))
+
+	if summary.Changes != 5 || summary.NewListings != 1 || summary.Removed != 0 {
+		t.Errorf("got %v changes, %v new listings and %v removed, want 5, 1 and 0", summary.Changes, summary.NewListings, summary.Removed)
+	}
+	if want := []CompilerWeek{{CompilerClang, 2, 1}, {CompilerGcc, 2, 2}}; !reflect.DeepEqual(summary.Compilers, want) {
+		t.Errorf("got compilers %+v, want %+v", summary.Compilers, want)
+	}
+	if want := []StandardWeek{{LanguageCpp, 26, 2}, {LanguageCpp, 20, 2}, {LanguageC, 23, 1}}; !reflect.DeepEqual(summary.Standards, want) {
+		t.Errorf("got standards %+v, want %+v", summary.Standards, want)
+	}
+
+	text := `[Weekly Summary] March 1 to March 8: 5 changes on cppreference, 1 new feature listed.
+By compiler: Clang 2, GCC 2
+By standard: C++26 2, C++20 2, C23 1
+Highlights:
+- GCC fully supports C++20 "Modules"
+- Clang fully supports C++26 "Pack indexing"
+- GCC fully supports C23 "Embed"
+- C++26 "Reflection" was listed`
+	if got := WeeklySummaryText(summary); got != text {
+		t.Errorf("got text\n%v\nwant\n%v", got, text)
+	}
+
+	thread := WeeklySummaryToTwitterThread(summary, 4)
+	want := []string{
+		"[Weekly Summary] March 1 to March 8: 5 changes on cppreference, 1 new feature listed.\nBy compiler: Clang 2, GCC 2\nBy standard: C++26 2, C++20 2, C23 1\n" +
+			"Highlights:\n- GCC fully supports C++20 \"Modules\"\n- Clang fully supports C++26 \"Pack indexing\" (1/2)",
+		"- GCC fully supports C23 \"Embed\"\n- C++26 \"Reflection\" was listed (2/2)",
+	}
+	if !reflect.DeepEqual(thread, want) {
+		t.Errorf("got thread %q, want %q", thread, want)
+	}
+
+	if thread := WeeklySummaryToTwitterThread(SummarizeWeek(nil, since, since.AddDate(0, 0, 7)), 4); len(thread) != 0 {
+		t.Errorf("week without changes has thread %q", thread)
+	}
+}
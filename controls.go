@@ -0,0 +1,307 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"cppimpbot/plugins"
+	"cppimpbot/schedule"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// controls the bot takes while running, sent as signals where the platform has them. see signals_unix.go
+const (
+	controlReload = iota //read the config again and restart with it
+	controlScrape        //run all scrapes right away
+	controlDump          //log the internal state
+)
+
+// restartRequested is set once the bot shut down to restart with a reloaded config
+var restartRequested bool
+
+// stopRequests shut the bot down like an interrupt, for service managers that don't send signals. see service_windows.go
+var stopRequests = make(chan struct{}, 1)
+
+// checkConfig reads the config file again and checks it would start the bot, without touching the running one. it
+// reads into a viper of its own, the global one keeps what the bot was started with
+func checkConfig() error {
+	v := viper.New()
+	setConfigDefaults(v)
+	setConfigFile(v)
+	//flags given on the command line are given to the restarted bot as well
+	for key, flag := range configFlags {
+		v.BindPFlag(key, flag)
+	}
+
+	if err := v.ReadInConfig(); err != nil {
+		return err
+	}
+
+	cfg := &Configuration{}
+	if err := v.Unmarshal(cfg); err != nil {
+		return err
+	}
+
+	return validateConfig(cfg)
+}
+
+// validateConfig checks everything of the config that can be told wrong before the bot starts with it, both at startup
+// and when the config is reloaded
+func validateConfig(cfg *Configuration) error {
+	if cfg.StorageMode != "sqlite3" && cfg.StorageMode != "dummy" {
+		return fmt.Errorf("Invalid storageMode: %s", cfg.StorageMode)
+	}
+
+	if _, err := parseExperimentalFeatures(cfg); err != nil {
+		return err
+	}
+
+	if err := checkLogging(cfg); err != nil {
+		return err
+	}
+
+	if err := validateObjectives(cfg); err != nil {
+		return err
+	}
+
+	if err := checkMode(cfg); err != nil {
+		return err
+	}
+
+	if _, err := newNotifier(cfg, nil); err != nil {
+		return err
+	}
+
+	for _, language := range cfg.ReportLanguages {
+		if !compliance.IsLanguage(language) {
+			return fmt.Errorf("unknown language in ReportLanguages: %s", language)
+		}
+	}
+
+	if err := checkTargets(cfg); err != nil {
+		return err
+	}
+
+	if err := compliance.CheckReportRules(cfg.ReportRules); err != nil {
+		return err
+	}
+
+	for _, percent := range cfg.Milestones {
+		if percent <= 0 || percent > 100 {
+			return fmt.Errorf("invalid percentage in Milestones: %v, expected one from 1 to 100", percent)
+		}
+	}
+	if _, err := compliance.NewMilestoneTemplates(cfg.MilestoneTemplates); err != nil {
+		return err
+	}
+	for _, milestone := range cfg.MilestoneTemplates {
+		used := milestone.Percent == 0
+		for _, percent := range cfg.Milestones {
+			used = used || percent == milestone.Percent
+		}
+		if !used {
+			slog.Warn("milestone template of a percentage that isn't one of the Milestones", "percent", milestone.Percent)
+		}
+	}
+
+	for _, source := range sourceConfigs(cfg) {
+		if _, err := scheduleJob("scrape "+source.Name, source.Interval, source.Jitter, source.Align, source.Schedule); err != nil {
+			return err
+		}
+	}
+	if _, err := scheduleJob("report", cfg.TwitterReportInterval, cfg.TwitterReportJitter, cfg.TwitterReportAlign, cfg.TwitterReportSchedule); err != nil {
+		return err
+	}
+	//the jobs without a schedule of their own run every interval, if they run at all
+	for _, job := range []struct {
+		name     string
+		interval int
+		enabled  bool
+	}{
+		{"ReleasePollInterval", cfg.ReleasePollInterval, len(cfg.ReleaseFeeds) > 0},
+		{"DatabaseCheckInterval", cfg.DatabaseCheckInterval, cfg.DatabaseSizeAlert > 0 || cfg.DatabaseRotateSize > 0},
+		{"ObjectiveReportInterval", cfg.ObjectiveReportInterval, len(cfg.Objectives) > 0},
+		{"ObjectiveCheckInterval", cfg.ObjectiveCheckInterval, len(cfg.Objectives) > 0},
+		{"DigestCheckInterval", cfg.DigestCheckInterval, digestEnabled(cfg)},
+		{"YearReviewInterval", cfg.YearReviewInterval, cfg.YearReview},
+	} {
+		if !job.enabled {
+			continue
+		}
+		if _, err := scheduleJob(job.name, job.interval, 0, "", ""); err != nil {
+			return err
+		}
+	}
+	if _, err := newReportPace(cfg, nil); err != nil {
+		return err
+	}
+	if cfg.WeeklySummary {
+		if cfg.WeeklySummarySchedule == "" {
+			return fmt.Errorf("WeeklySummary needs a WeeklySummarySchedule, like \"0 9 * * 1\" for Mondays at 9:00")
+		}
+		if _, err := scheduleJob("weekly summary", 0, 0, "", cfg.WeeklySummarySchedule); err != nil {
+			return err
+		}
+	}
+
+	for _, hashtag := range cfg.ReportHashtags {
+		if !compliance.ValidTag(hashtag) {
+			return fmt.Errorf("invalid hashtag in ReportHashtags: %s", hashtag)
+		}
+	}
+	for compiler, mention := range cfg.ReportMentions {
+		if !compliance.ValidTag(mention) {
+			return fmt.Errorf("invalid mention of %s in ReportMentions: %s", compiler, mention)
+		}
+	}
+
+	//ordered as if every reporter compiled in was set up, to find cycles before one is configured
+	var compiled reporters
+	for _, name := range compiledReporters() {
+		compiled = append(compiled, namedReporter{name: name, after: cfg.ReportAfter[name]})
+	}
+	for name := range cfg.ReportAfter {
+		if _, ok := reporterRegistry[name]; !ok {
+			return fmt.Errorf("ReportAfter orders %s, which is no reporter compiled in. twitter always posts first", name)
+		}
+	}
+	if _, err := orderReporters(compiled); err != nil {
+		return err
+	}
+
+	if _, err := compliance.LoadTexts(cfg.ReportTextDir); err != nil {
+		return err
+	}
+
+	if cfg.MentionReplies && cfg.MentionPollInterval <= 0 {
+		return fmt.Errorf("MentionReplies needs a MentionPollInterval of at least a second")
+	}
+	if cfg.BlueskyHandle != "" && cfg.BlueskyAppPassword == "" {
+		return fmt.Errorf("BlueskyHandle needs a BlueskyAppPassword to log in with")
+	}
+
+	if cfg.ReportBatching && cfg.ReportApproval {
+		return fmt.Errorf("ReportBatching and ReportApproval can't be used together, reports are approved one by one")
+	}
+
+	return nil
+}
+
+// waitForQuit handles interrupts, SIGTERM, stop requests and runtime controls until the bot shuts down, and closes
+// quitChan then. a reload with a valid config shuts the bot down too, to restart it with the new config once everything
+// stopped
+func waitForQuit(quitChan chan struct{}, scrapeScheduler *schedule.Scheduler, dumpState func()) {
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(interrupts) //a second interrupt kills the bot if shutting down takes too long
+
+	controls := make(chan int, 1)
+	notifyControls(controls)
+
+	for {
+		select {
+		case sig := <-interrupts:
+			slog.Info("will shut down...", "signal", sig)
+			close(quitChan)
+			return
+		case <-stopRequests:
+			slog.Info("service stop requested, will shut down...")
+			close(quitChan)
+			return
+		case control := <-controls:
+			switch control {
+			case controlReload:
+				if err := checkConfig(); err != nil {
+					slog.Error("not reloading, the config is invalid", "err", err)
+					continue
+				}
+
+				slog.Info("config reloaded, will restart...")
+				restartRequested = true
+				close(quitChan)
+				return
+			case controlScrape:
+				triggered := scrapeScheduler.Trigger(func(name string) bool {
+					return strings.HasPrefix(name, "scrape ")
+				})
+				slog.Info("triggered scrapes", "scrapes", triggered)
+			case controlDump:
+				dumpState()
+			}
+		}
+	}
+}
+
+// dumpState logs what the bot is up to: its jobs, the reporter, plugins and storage
+func dumpState(started time.Time, service compliance.Service, scrapeScheduler *schedule.Scheduler, startedPlugins []*plugins.Plugin, metrics *compliance.Metrics) {
+	var state strings.Builder
+
+	fmt.Fprintf(&state, "state dump, up since %v (%v), %v goroutines\n", started.Format(time.RFC3339), time.Since(started).Round(time.Second), runtime.NumGoroutine())
+
+	fmt.Fprintf(&state, "jobs:\n")
+	for _, job := range scrapeScheduler.Status() {
+		if job.Schedule != "" {
+			fmt.Fprintf(&state, "  %v: on schedule %v, ran %v times", job.Name, job.Schedule, job.Runs)
+		} else {
+			fmt.Fprintf(&state, "  %v: every %v, ran %v times", job.Name, job.Interval, job.Runs)
+		}
+		if job.Runs > 0 {
+			fmt.Fprintf(&state, ", last at %v taking %v", job.LastRun.Format(time.RFC3339), job.LastDuration.Round(time.Millisecond))
+		}
+		if job.Running {
+			fmt.Fprintf(&state, ", running now")
+		} else if !job.NextRun.IsZero() {
+			fmt.Fprintf(&state, ", next at %v", job.NextRun.Format(time.RFC3339))
+		}
+		fmt.Fprintln(&state)
+	}
+
+	fmt.Fprintf(&state, "reporter:\n")
+	if heartbeat, err := service.GetHeartbeat(context.Background()); err != nil {
+		fmt.Fprintf(&state, "  heartbeat unknown: %v\n", err)
+	} else {
+		fmt.Fprintf(&state, "  last heartbeat at %v\n", heartbeat.Format(time.RFC3339))
+	}
+	if pause, err := service.GetReportingPause(context.Background()); err != nil {
+		fmt.Fprintf(&state, "  pause unknown: %v\n", err)
+	} else if pause.Paused() {
+		fmt.Fprintf(&state, "  paused by safe mode since %v with %v reports waiting\n", pause.PausedAt.Format(time.RFC3339), pause.Pending)
+	}
+	if unreported, err := service.GetUnreported(context.Background(), compliance.ChannelTwitter); err != nil {
+		fmt.Fprintf(&state, "  unreported entries unknown: %v\n", err)
+	} else {
+		fmt.Fprintf(&state, "  %v entries not reported yet\n", len(unreported))
+	}
+	if update, err := service.GetLastUpdate(context.Background()); err != nil {
+		fmt.Fprintf(&state, "  last update unknown: %v\n", err)
+	} else {
+		fmt.Fprintf(&state, "  last update stored at %v\n", update.Format(time.RFC3339))
+	}
+
+	fmt.Fprintf(&state, "reporters compiled in: %v\n", strings.Join(compiledReporters(), ", "))
+
+	if len(startedPlugins) > 0 {
+		fmt.Fprintf(&state, "plugins:\n")
+		for _, plugin := range startedPlugins {
+			status := "running"
+			if plugin.Exited() {
+				status = "exited"
+			}
+			fmt.Fprintf(&state, "  %v: %v\n", plugin.Name, status)
+		}
+	}
+
+	if metrics != nil {
+		fmt.Fprintf(&state, "storage metrics:\n%v\n", metrics)
+	}
+
+	slog.Info("internal state:\n" + state.String())
+}
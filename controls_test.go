@@ -0,0 +1,86 @@
+package main
+
+import (
+	"cppimpbot/compliance"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+var defaultsOnce sync.Once
+
+// defaultConfig gives the config the bot runs with when the config file sets nothing
+func defaultConfig(t *testing.T) *Configuration {
+	defaultsOnce.Do(initConfig)
+
+	cfg := &Configuration{}
+	if err := viper.Unmarshal(cfg); err != nil {
+		t.Fatalf("could not read the default config: %v", err)
+	}
+
+	return cfg
+}
+
+func TestValidateConfig(t *testing.T) {
+	if err := validateConfig(defaultConfig(t)); err != nil {
+		t.Fatalf("default config: %v", err)
+	}
+
+	for _, test := range []struct {
+		what   string
+		change func(cfg *Configuration)
+	}{
+		{"hashtag with a space", func(cfg *Configuration) { cfg.ReportHashtags = []string{"cpp", "c plus plus"} }},
+		{"empty hashtag", func(cfg *Configuration) { cfg.ReportHashtags = []string{"#"} }},
+		{"milestone of 0 percent", func(cfg *Configuration) { cfg.Milestones = []int{0, 50} }},
+		{"milestone over 100 percent", func(cfg *Configuration) { cfg.Milestones = []int{50, 150} }},
+		{"milestone template that doesn't parse", func(cfg *Configuration) {
+			cfg.MilestoneTemplates = []compliance.MilestoneTemplate{{Percent: 50, Template: "{{.Compiler"}}
+		}},
+		{"milestone template over 100 percent", func(cfg *Configuration) {
+			cfg.MilestoneTemplates = []compliance.MilestoneTemplate{{Percent: 120, Template: "{{.Compiler}}"}}
+		}},
+		{"source without an interval", func(cfg *Configuration) { cfg.Sources = []SourceConfig{{Name: "cppreference"}} }},
+		{"year review without an interval", func(cfg *Configuration) { cfg.YearReview, cfg.YearReviewInterval = true, 0 }},
+		{"mention with a dash", func(cfg *Configuration) { cfg.ReportMentions = map[string]string{"gcc": "gnu-gcc"} }},
+		{"mention replies without an interval", func(cfg *Configuration) { cfg.MentionReplies, cfg.MentionPollInterval = true, 0 }},
+		{"bluesky without a password", func(cfg *Configuration) { cfg.BlueskyHandle = "cppimpbot.bsky.social" }},
+	} {
+		cfg := defaultConfig(t)
+		test.change(cfg)
+		if err := validateConfig(cfg); err == nil {
+			t.Errorf("%v: passed validation", test.what)
+		}
+	}
+}
+
+func TestCheckConfig(t *testing.T) {
+	started := defaultConfig(t)
+	defer func(file string) { cfgFile = file }(cfgFile)
+
+	for _, test := range []struct {
+		config string
+		valid  bool
+	}{
+		{`StorageMode = "nosuch"`, false},
+		{`Mode = "scrape"`, true},
+		{`LogLevel = [`, false},
+	} {
+		cfgFile = filepath.Join(t.TempDir(), "config.toml")
+		if err := os.WriteFile(cfgFile, []byte(test.config), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := checkConfig(); (err == nil) != test.valid {
+			t.Errorf("%v: expected valid %v, got %v", test.config, test.valid, err)
+		}
+
+		//the running bot keeps the config it was started with
+		if viper.GetString("StorageMode") != started.StorageMode || viper.GetString("Mode") != started.Mode {
+			t.Errorf("%v: checking changed the running config", test.config)
+		}
+	}
+}
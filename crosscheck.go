@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"cppimpbot/scraper"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var discrepanciesCommand = &cobra.Command{
+	Use:   "discrepancies",
+	Short: "List where cppreference disagrees with the sources it is cross-checked with, like the C++ status pages of GCC and Clang",
+	Args:  cobra.NoArgs,
+	RunE:  discrepanciesCmdFunc,
+}
+
+// crossCheck is a source cppreference is cross-checked with, instead of one whose features are stored
+type crossCheck struct {
+	description string //what the source is, for the problems found, like "the C++ status page of GCC"
+	page        func(cfg *Configuration) string
+	newSource   func(cfg *Configuration) (scraper.Source, error)
+}
+
+// crossChecks sets up every built-in cross-check source by the name it is configured as, which is also the probe its
+// discrepancies are recorded by
+var crossChecks = map[string]crossCheck{
+	compliance.ProbeGccStatus: {
+		description: "the C++ status page of GCC",
+		page: func(cfg *Configuration) string {
+			return cfg.GccStatusUrl
+		},
+		newSource: func(cfg *Configuration) (scraper.Source, error) {
+			s, err := newScraper(cfg, compliance.ProbeGccStatus, cfg.GccStatusUrl)
+			if err != nil {
+				return nil, err
+			}
+
+			return &scraper.GccStatusScraper{Scraper: s}, nil
+		},
+	},
+	compliance.ProbeClangStatus: {
+		description: "the C++ status page of Clang",
+		page: func(cfg *Configuration) string {
+			return cfg.ClangStatusUrl
+		},
+		newSource: func(cfg *Configuration) (scraper.Source, error) {
+			s, err := newScraper(cfg, compliance.ProbeClangStatus, cfg.ClangStatusUrl)
+			if err != nil {
+				return nil, err
+			}
+
+			return &scraper.ClangStatusScraper{Scraper: s}, nil
+		},
+	},
+}
+
+// crossCheckSource scrapes a source cppreference is cross-checked with and records where the two disagree. with
+// DiscrepancyAlerts the maintainer hears about the disagreements found for the first time
+func crossCheckSource(cfg *Configuration, complianceStorageService compliance.Service, alert func(message string), name string, check crossCheck) error {
+	source, err := check.newSource(cfg)
+	if err != nil {
+		slog.Error("error setting up source", "source", name, "err", err)
+		return err
+	}
+
+	//fetched in full every time, as the stored features it is compared with may have changed since
+	scraped, err := scraper.ScrapeSource(source)
+	if err != nil {
+		slog.Error("error when scraping", "source", name, "err", err)
+		return err
+	}
+	reportRowErrors(name, scraped, alert)
+
+	var checked []compliance.Feature
+	for _, version := range scraped.Versions {
+		for _, feature := range version.Features {
+			checked = append(checked, featureFromScraped(&version, &feature))
+		}
+	}
+
+	features, err := complianceStorageService.GetLatestEntries(context.Background())
+	if err != nil {
+		slog.Error("error getting latest entries, not cross-checking", "source", name, "err", err)
+		return err
+	}
+
+	discrepancies := compliance.CrossCheck(features, checked, name, check.description, check.page(cfg))
+
+	added, err := complianceStorageService.StoreDiscrepancies(context.Background(), name, discrepancies)
+	if err != nil {
+		slog.Error("error storing discrepancies", "source", name, "err", err)
+		return err
+	}
+
+	slog.Info("cross-checked cppreference", "source", name, "discrepancies", len(discrepancies), "new", len(added))
+
+	if cfg.DiscrepancyAlerts && len(added) > 0 {
+		var lines []string
+		for _, discrepancy := range added {
+			lines = append(lines, fmt.Sprintf("%v \"%v\": %v", discrepancy.Feature.Standard(), discrepancy.Feature.Name, discrepancy.Problem))
+		}
+		alert(fmt.Sprintf("Hello! cppreference disagrees with %v:\n- %v", check.description, strings.Join(lines, "\n- ")))
+	}
+
+	return nil
+}
+
+func discrepanciesCmdFunc(cmd *cobra.Command, args []string) error {
+	cfg := &Configuration{}
+
+	if err := viper.Unmarshal(cfg); err != nil {
+		return err
+	}
+
+	if cfg.StorageMode != "sqlite3" {
+		return fmt.Errorf("discrepancies needs storageMode sqlite3, not %s", cfg.StorageMode)
+	}
+
+	service, err := openSqliteService(cfg)
+	if err != nil {
+		return err
+	}
+	defer service.Close(context.Background())
+
+	discrepancies, err := service.GetOpenDiscrepancies(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if len(discrepancies) == 0 {
+		fmt.Println("no discrepancies")
+		return nil
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "found\tprobe\tfeature\tcompiler\tproblem")
+	for _, discrepancy := range discrepancies {
+		fmt.Fprintf(writer, "%v\t%v\t%v\t%v\t%v\n", discrepancy.Found.Format(historyTimeFormat), discrepancy.Probe, discrepancy.Slug,
+			compliance.CompilerDisplayName(discrepancy.Compiler), discrepancy.Problem)
+	}
+
+	return writer.Flush()
+}
@@ -0,0 +1,41 @@
+package dashboard
+
+import (
+	"cppimpbot/compliance"
+	"log/slog"
+	"net/http"
+)
+
+// ShowCorrections lists the corrections drafted for cppreference into the outbox dir at /corrections, for the
+// maintainer to check and post
+func (s *Server) ShowCorrections(dir string) {
+	s.correctionOutbox = dir
+	s.mux.HandleFunc("/corrections", s.handleCorrections)
+}
+
+type correctionEntry struct {
+	Key     string
+	Drafted string
+	Text    string
+}
+
+// handleCorrections shows every drafted correction with its wiki markup, newest first
+func (s *Server) handleCorrections(w http.ResponseWriter, r *http.Request) {
+	corrections, err := compliance.LoadCorrections(s.correctionOutbox)
+	if err != nil {
+		slog.Error("dashboard: error loading corrections", "err", err)
+		http.Error(w, "could not get corrections", http.StatusInternalServerError)
+		return
+	}
+
+	var entries []correctionEntry
+	for _, correction := range corrections {
+		entries = append(entries, correctionEntry{
+			Key:     correction.Key,
+			Drafted: correction.Drafted.Format("2006-01-02 15:04 MST"),
+			Text:    correction.Text,
+		})
+	}
+
+	render(w, correctionsTemplate, entries)
+}
@@ -0,0 +1,415 @@
+package dashboard
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"cppimpbot/mentions"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const RequestTimeout = 10 * time.Second
+
+type cell struct {
+	Class string
+	Text  string
+	Title string
+}
+
+type row struct {
+	Slug      string
+	Name      string
+	PaperName string
+	PaperLink string
+	Cells     []cell
+}
+
+type section struct {
+	Title     string
+	Compilers []string
+	Summary   []string
+	Rows      []row
+}
+
+type historyDelta struct {
+	Compiler string
+	Previous cell
+	Next     cell
+}
+
+type historyEntry struct {
+	Time   string
+	Kind   string
+	Name   string //only set if the feature was called differently than now
+	Deltas []historyDelta
+}
+
+type timelineBlock struct {
+	Class string
+	Title string
+	Style template.CSS
+}
+
+type timelineLane struct {
+	Compiler string
+	Blocks   []timelineBlock
+}
+
+type history struct {
+	Slug      string
+	Name      string
+	Standard  string
+	PaperName string
+	PaperLink string
+	Start     string
+	End       string
+	Lanes     []timelineLane
+	Entries   []historyEntry
+}
+
+var changeKindNames = map[string]string{
+	compliance.ChangeRemoved:    "Removed",
+	compliance.ChangeRename:     "Renamed",
+	compliance.ChangeNewListing: "New listing",
+	compliance.ChangeSupport:    "Support update",
+	compliance.ChangeText:       "Text update",
+	compliance.ChangePaper:      "Paper update",
+}
+
+// Server renders the support matrix and the history of every feature as html
+type Server struct {
+	service           compliance.Service
+	mux               *http.ServeMux
+	suggestionLimiter *mentions.UserLimiter //suggestions are only taken if it is set
+	correctionOutbox  string                //drafted corrections are only shown if it is set
+}
+
+func NewServer(service compliance.Service) *Server {
+	s := &Server{
+		service: service,
+		mux:     http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("/", s.handleMatrix)
+	s.mux.HandleFunc("/feature/", s.handleHistory)
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	//the suggestion form is the only thing that can be posted to
+	if r.Method != http.MethodGet && !(r.Method == http.MethodPost && r.URL.Path == "/suggest") {
+		http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.mux.ServeHTTP(w, r)
+}
+
+func supportCell(support *compliance.CompilerSupport) cell {
+	if support == nil {
+		return cell{}
+	}
+
+	return cell{
+		Class: compliance.SupportLevelName(support.Support),
+		Text:  support.DisplayText.String,
+		Title: support.ExtraText.String,
+	}
+}
+
+// sectionCompilers gives the columns of a table: the primary vendors of its kind, then the others in the order they first appear
+func sectionCompilers(kind string, features []compliance.Feature) []string {
+	result := append([]string(nil), compliance.PrimaryCompilers...)
+	if kind == compliance.KindLibrary {
+		result = append([]string(nil), compliance.PrimaryLibraries...)
+	}
+
+	seen := map[string]bool{}
+	for _, compiler := range result {
+		seen[compiler] = true
+	}
+
+	for _, feature := range features {
+		for _, support := range feature.Compilers {
+			if !seen[support.Compiler] {
+				seen[support.Compiler] = true
+				result = append(result, support.Compiler)
+			}
+		}
+	}
+
+	return result
+}
+
+// matrixSections groups the features into a table per language, version and kind. C++ comes first, then newest version first
+func matrixSections(features []compliance.Feature, counts []compliance.SupportCount) []section {
+	type sectionKey struct {
+		language string
+		version  int
+		kind     string
+	}
+
+	grouped := map[sectionKey][]compliance.Feature{}
+	var keys []sectionKey
+
+	for _, feature := range features {
+		key := sectionKey{feature.Language, feature.CppVersion, feature.Kind()}
+		if _, ok := grouped[key]; !ok {
+			keys = append(keys, key)
+		}
+		grouped[key] = append(grouped[key], feature)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].language != keys[j].language {
+			return keys[i].language == compliance.LanguageCpp
+		}
+		if keys[i].version != keys[j].version {
+			return compliance.StandardYear(keys[i].version) > compliance.StandardYear(keys[j].version)
+		}
+		return keys[i].kind < keys[j].kind
+	})
+
+	supported := map[string]int{}
+	for _, count := range counts {
+		if count.Support == compliance.SupportYes {
+			supported[fmt.Sprintf("%v/%v/%v", count.Language, count.CppVersion, count.Compiler)] = count.Features
+		}
+	}
+
+	var result []section
+
+	for _, key := range keys {
+		sectionFeatures := grouped[key]
+		compilers := sectionCompilers(key.kind, sectionFeatures)
+
+		standard := compliance.StandardName(key.language, key.version)
+		title := fmt.Sprintf("%v core language features", standard)
+		if key.kind == compliance.KindLibrary {
+			title = fmt.Sprintf("%v library features", standard)
+		}
+
+		current := section{Title: title}
+
+		for _, compiler := range compilers {
+			current.Compilers = append(current.Compilers, compliance.CompilerDisplayName(compiler))
+			current.Summary = append(current.Summary, fmt.Sprintf("%v/%v", supported[fmt.Sprintf("%v/%v/%v", key.language, key.version, compiler)], len(sectionFeatures)))
+		}
+
+		for i := range sectionFeatures {
+			feature := &sectionFeatures[i]
+
+			featureRow := row{
+				Slug:      feature.Slug,
+				Name:      feature.Name,
+				PaperName: feature.PaperName.String,
+				PaperLink: feature.PaperLink.String,
+			}
+
+			for _, compiler := range compilers {
+				featureRow.Cells = append(featureRow.Cells, supportCell(feature.SupportFor(compiler)))
+			}
+
+			current.Rows = append(current.Rows, featureRow)
+		}
+
+		result = append(result, current)
+	}
+
+	return result
+}
+
+func render(w http.ResponseWriter, page *template.Template, data interface{}) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if err := page.Execute(w, data); err != nil {
+		slog.Error("dashboard: error rendering", "page", page.Name(), "err", err)
+	}
+}
+
+// handleMatrix shows the latest support of every feature, or of the features of the language given as "language"
+func (s *Server) handleMatrix(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	language := r.URL.Query().Get("language")
+	if language != "" && !compliance.IsLanguage(language) {
+		http.Error(w, "unknown language", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), RequestTimeout)
+	defer cancel()
+
+	features, err := s.service.GetLatestEntries(ctx)
+	if err != nil {
+		slog.Error("dashboard: error getting latest entries", "err", err)
+		http.Error(w, "could not get features", http.StatusInternalServerError)
+		return
+	}
+
+	counts, err := s.service.GetSupportCounts(ctx)
+	if err != nil {
+		slog.Error("dashboard: error counting support", "err", err)
+		http.Error(w, "could not get features", http.StatusInternalServerError)
+		return
+	}
+
+	lastUpdate, err := s.service.GetLastUpdate(ctx)
+	if err != nil {
+		slog.Error("dashboard: error getting last update", "err", err)
+		http.Error(w, "could not get features", http.StatusInternalServerError)
+		return
+	}
+
+	title := "C and C++ compiler support"
+	if language != "" {
+		features = compliance.FeaturesOfLanguage(features, language)
+		title = compliance.LanguageName(language) + " compiler support"
+	}
+
+	render(w, matrixTemplate, struct {
+		Title       string
+		LastUpdate  string
+		Sections    []section
+		Suggestions bool
+		Corrections bool
+	}{
+		Title:       title,
+		Suggestions: s.suggestionLimiter != nil,
+		Corrections: s.correctionOutbox != "",
+		LastUpdate:  lastUpdate.Format("2006-01-02 15:04 MST"),
+		Sections:    matrixSections(compliance.CurrentFeatures(features), counts),
+	})
+}
+
+// timelineLanes lays out the support of every compiler as a strip of blocks, each as wide as the time it lasted
+func timelineLanes(entries []compliance.Feature, end time.Time) []timelineLane {
+	start := entries[0].Timestamp
+	total := end.Sub(start)
+	if total <= 0 {
+		total = 1
+	}
+
+	width := func(from time.Time, to time.Time) template.CSS {
+		return template.CSS(fmt.Sprintf("width: %.2f%%", float64(to.Sub(from))*100/float64(total)))
+	}
+
+	var result []timelineLane
+
+	for _, lane := range compliance.Timeline(entries, end) {
+		current := timelineLane{Compiler: compliance.CompilerDisplayName(lane.Compiler)}
+
+		//compilers listed later than the feature, and times the feature was removed, are gaps
+		cursor := start
+		for _, span := range lane.Spans {
+			if span.Start.After(cursor) {
+				current.Blocks = append(current.Blocks, timelineBlock{Class: "unlisted", Title: "not listed", Style: width(cursor, span.Start)})
+			}
+			cursor = span.End
+
+			support := supportCell(&span.Support)
+			title := fmt.Sprintf("%v %v from %v", support.Class, support.Text, span.Start.Format("2006-01-02"))
+
+			current.Blocks = append(current.Blocks, timelineBlock{Class: support.Class, Title: title, Style: width(span.Start, span.End)})
+		}
+
+		if end.After(cursor) {
+			current.Blocks = append(current.Blocks, timelineBlock{Class: "unlisted", Title: "not listed", Style: width(cursor, end)})
+		}
+
+		result = append(result, current)
+	}
+
+	return result
+}
+
+// handleHistory shows every change of the feature given as /feature/{slug}, or by name for features without a slug. names
+// are looked up among the features of the language given as "language", C++ if there is none
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	slug, err := url.PathUnescape(strings.TrimPrefix(r.URL.EscapedPath(), "/feature/"))
+	if err != nil || slug == "" {
+		http.Error(w, "invalid feature", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), RequestTimeout)
+	defer cancel()
+
+	entries, err := s.service.GetFeatureHistoryBySlug(ctx, slug)
+	if err == nil && len(entries) == 0 {
+		language := r.URL.Query().Get("language")
+		if language == "" {
+			language = compliance.LanguageCpp
+		}
+		entries, err = s.service.GetFeatureHistory(ctx, language, slug)
+	}
+	if err != nil {
+		slog.Error("dashboard: error getting history", "feature", slug, "err", err)
+		http.Error(w, "could not get feature history", http.StatusInternalServerError)
+		return
+	}
+
+	if len(entries) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	latest := &entries[len(entries)-1]
+	page := history{
+		Slug:      latest.Slug,
+		Name:      latest.Name,
+		Standard:  latest.Standard(),
+		PaperName: latest.PaperName.String,
+		PaperLink: latest.PaperLink.String,
+		Start:     entries[0].Timestamp.Format("2006-01-02"),
+		End:       "now",
+		Lanes:     timelineLanes(entries, time.Now()),
+	}
+
+	//newest first, like a timeline
+	for i := len(entries) - 1; i >= 0; i-- {
+		var previous *compliance.Feature
+		if i > 0 {
+			previous = &entries[i-1]
+		}
+
+		entry := historyEntry{
+			Time: entries[i].Timestamp.Format("2006-01-02 15:04"),
+			Kind: "No visible change",
+		}
+		if entries[i].Name != latest.Name {
+			entry.Name = entries[i].Name
+		}
+
+		if change, err := compliance.DiffFeatures(previous, &entries[i]); err == nil {
+			entry.Kind = changeKindNames[change.Kind]
+
+			for j := range change.Deltas {
+				delta := &change.Deltas[j]
+				historyDelta := historyDelta{
+					Compiler: compliance.CompilerDisplayName(delta.Compiler),
+					Next:     supportCell(&delta.Next),
+				}
+				if previous != nil {
+					historyDelta.Previous = supportCell(&delta.Previous)
+				}
+
+				entry.Deltas = append(entry.Deltas, historyDelta)
+			}
+		}
+
+		page.Entries = append(page.Entries, entry)
+	}
+
+	render(w, historyTemplate, page)
+}
@@ -0,0 +1,87 @@
+package dashboard
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"cppimpbot/mentions"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const maxAuthorLength = 50
+
+// AcceptSuggestions serves a form at /suggest where followers can suggest features or papers to watch, limited per
+// address by limiter. the suggestions wait for the maintainer to approve them with the suggestions command
+func (s *Server) AcceptSuggestions(limiter *mentions.UserLimiter) {
+	s.suggestionLimiter = limiter
+	s.mux.HandleFunc("/suggest", s.handleSuggest)
+}
+
+// remoteHost is the address the request came from, without the port
+func remoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+type suggestPage struct {
+	Message string
+	Error   bool
+}
+
+// handleSuggest shows the suggestion form, and stores what is posted through it
+func (s *Server) handleSuggest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		render(w, suggestTemplate, suggestPage{})
+		return
+	}
+
+	//the form shares the checks of mentions, which keep out links and spam
+	query, ok := mentions.ParseSuggestion(r.PostFormValue("suggestion"))
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		render(w, suggestTemplate, suggestPage{Message: "Please name a paper like P1234 or a feature, without links.", Error: true})
+		return
+	}
+
+	if !s.suggestionLimiter.Allow(remoteHost(r), time.Now()) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		render(w, suggestTemplate, suggestPage{Message: "That's plenty of suggestions for now, thank you! Please come back later.", Error: true})
+		return
+	}
+
+	author := strings.TrimSpace(r.PostFormValue("author"))
+	if len(author) > maxAuthorLength {
+		author = author[:maxAuthorLength]
+	}
+
+	suggestion := &compliance.Suggestion{
+		Source: compliance.SuggestionSourceForm,
+		Author: author,
+		Text:   strings.TrimSpace(r.PostFormValue("suggestion")),
+	}
+	if query.IsPaper {
+		suggestion.Paper = query.Text
+	} else {
+		suggestion.Name = query.Text
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), RequestTimeout)
+	defer cancel()
+
+	if err := s.service.StoreSuggestion(ctx, suggestion); err != nil {
+		slog.Error("dashboard: error storing suggestion", "err", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		render(w, suggestTemplate, suggestPage{Message: "Your suggestion could not be stored, please try again later.", Error: true})
+		return
+	}
+
+	slog.Info("dashboard: got suggestion", "suggestion", suggestion.Id, "watch", query.Text)
+	render(w, suggestTemplate, suggestPage{Message: "Thank you! Your suggestion will be looked at soon."})
+}
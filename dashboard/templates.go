@@ -0,0 +1,127 @@
+package dashboard
+
+import "html/template"
+
+const style = `
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 0.2em 0.5em; font-size: 0.9em; }
+th { background: #eee; }
+td.yes { background: #b6f2b6; }
+td.partial { background: #f7e58f; }
+td.no { background: #f5b7b7; }
+td.summary { font-weight: bold; }
+.strip { display: flex; width: 40em; height: 1.2em; border: 1px solid #ccc; }
+.strip div { height: 100%; }
+.strip .yes { background: #4caf50; }
+.strip .partial { background: #e0c040; }
+.strip .no { background: #e57373; }
+.strip .unlisted { background: #fff; }
+.muted { color: #777; }
+`
+
+var matrixTemplate = template.Must(template.New("matrix").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>` + style + `</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="muted">Last change seen {{.LastUpdate}}{{if .Suggestions}}. Missing something? <a href="/suggest">Suggest a feature to watch</a>{{end}}{{if .Corrections}}. <a href="/corrections">Corrections drafted for cppreference</a>{{end}}</p>
+{{range .Sections}}
+<h2>{{.Title}}</h2>
+<table>
+<tr><th>Feature</th><th>Paper</th>{{range .Compilers}}<th>{{.}}</th>{{end}}</tr>
+<tr><td class="summary">Supported</td><td></td>{{range .Summary}}<td class="summary">{{.}}</td>{{end}}</tr>
+{{range .Rows}}
+<tr>
+<td><a href="/feature/{{.Slug}}">{{.Name}}</a></td>
+<td>{{if .PaperLink}}<a href="{{.PaperLink}}">{{.PaperName}}</a>{{else}}{{.PaperName}}{{end}}</td>
+{{range .Cells}}<td class="{{.Class}}" title="{{.Title}}">{{.Text}}</td>{{end}}
+</tr>
+{{end}}
+</table>
+{{end}}
+</body>
+</html>
+`))
+
+var historyTemplate = template.Must(template.New("history").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Name}} - compiler support</title>
+<style>` + style + `</style>
+</head>
+<body>
+<p><a href="/">All features</a></p>
+<h1>{{.Name}}</h1>
+<p class="muted">{{.Standard}}{{if .PaperName}}, <a href="{{.PaperLink}}">{{.PaperName}}</a>{{end}}. Refer to this feature as <code>{{.Slug}}</code></p>
+<table>
+<tr><th></th><th><span class="muted">{{.Start}}</span><span class="muted" style="float: right">{{.End}}</span></th></tr>
+{{range .Lanes}}
+<tr><td>{{.Compiler}}</td><td><div class="strip">{{range .Blocks}}<div class="{{.Class}}" title="{{.Title}}" style="{{.Style}}"></div>{{end}}</div></td></tr>
+{{end}}
+</table>
+<table>
+<tr><th>Time</th><th>Change</th><th>Details</th></tr>
+{{range .Entries}}
+<tr>
+<td>{{.Time}}</td>
+<td>{{.Kind}}{{if .Name}}<br><span class="muted">as "{{.Name}}"</span>{{end}}</td>
+<td>
+<table>
+{{range .Deltas}}<tr><td>{{.Compiler}}</td><td class="{{.Previous.Class}}" title="{{.Previous.Title}}">{{.Previous.Text}}</td><td class="{{.Next.Class}}" title="{{.Next.Title}}">{{.Next.Text}}</td></tr>{{end}}
+</table>
+</td>
+</tr>
+{{end}}
+</table>
+</body>
+</html>
+`))
+
+var suggestTemplate = template.Must(template.New("suggest").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Suggest a feature - compiler support</title>
+<style>` + style + `</style>
+</head>
+<body>
+<p><a href="/">All features</a></p>
+<h1>Suggest a feature to watch</h1>
+{{if .Message}}<p{{if .Error}} class="muted"{{end}}>{{.Message}}</p>{{end}}
+<form method="post" action="/suggest">
+<p><label>Paper or feature<br><input name="suggestion" size="60" maxlength="100" placeholder="P1234 or std::feature"></label></p>
+<p><label>Credit me as (optional)<br><input name="author" size="30" maxlength="50" placeholder="@handle"></label></p>
+<p><button type="submit">Suggest</button></p>
+</form>
+<p class="muted">Suggestions are looked at by the maintainer, who hears about it as soon as cppreference lists a watched feature.</p>
+</body>
+</html>
+`))
+
+var correctionsTemplate = template.Must(template.New("corrections").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Drafted corrections - compiler support</title>
+<style>` + style + `</style>
+</head>
+<body>
+<p><a href="/">All features</a></p>
+<h1>Corrections drafted for cppreference</h1>
+<p class="muted">Support cells that look wrong, with a note for the talk page of cppreference. Check them before posting.</p>
+{{range .}}
+<h2>{{.Key}}</h2>
+<p class="muted">Drafted {{.Drafted}}</p>
+<pre>{{.Text}}</pre>
+{{else}}
+<p>Nothing looks wrong right now.</p>
+{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var deadLettersCommand = &cobra.Command{
+	Use:   "dead-letters",
+	Short: "List the reports that failed too often to be retried, and optionally requeue them",
+	Long: `List the reports that failed too often to be retried, with the last error of each.
+With --requeue the failed attempts of the given entries are forgotten, so the reporter tries them again on its next tick.`,
+	RunE: deadLettersCmdFunc,
+}
+
+var deadLettersRequeue []string
+var deadLettersRequeueAll bool
+var deadLettersChannel string
+
+func init() {
+	deadLettersCommand.Flags().StringSliceVar(&deadLettersRequeue, "requeue", nil, "ids of the entries to requeue")
+	deadLettersCommand.Flags().BoolVar(&deadLettersRequeueAll, "all", false, "requeue all dead letters")
+	deadLettersCommand.Flags().StringVar(&deadLettersChannel, "channel", compliance.ChannelTwitter, "channel of the reports to requeue")
+}
+
+// pendingReports leaves out the entries whose reports are dead letters or still backing off after a failure, so they
+// neither get posted nor count against safe mode
+func pendingReports(service compliance.Service, entries []compliance.Feature, now time.Time) []compliance.Feature {
+	var result []compliance.Feature
+
+	for _, entry := range entries {
+		attempt, err := service.GetReportAttempt(context.Background(), entry.Id, compliance.ChannelTwitter)
+		if err != nil {
+			slog.Error("error getting report attempts, reporting it anyway", "channel", compliance.ChannelTwitter, "entry", entry.Id, "feature", entry.Name, "err", err)
+		} else if attempt != nil && !attempt.Due(now) {
+			continue
+		}
+
+		result = append(result, entry)
+	}
+
+	return result
+}
+
+// recordReportFailure counts a failed report of the entry and tells the maintainer once it became a dead letter
+func recordReportFailure(cfg *Configuration, service compliance.Service, notify func(string), entry *compliance.Feature, reportErr error) {
+	attempt, err := service.GetReportAttempt(context.Background(), entry.Id, compliance.ChannelTwitter)
+	if err != nil {
+		slog.Error("error getting report attempts", "channel", compliance.ChannelTwitter, "entry", entry.Id, "feature", entry.Name, "err", err)
+		return
+	}
+	if attempt == nil {
+		attempt = &compliance.ReportAttempt{FeatureID: entry.Id, Channel: compliance.ChannelTwitter}
+	}
+
+	attempt.Failed(reportErr, time.Now(), cfg.ReportMaxAttempts, time.Duration(cfg.ReportRetryBackoff)*time.Second, time.Duration(cfg.ReportMaxBackoff)*time.Second)
+
+	if err := service.StoreReportAttempt(context.Background(), attempt); err != nil {
+		slog.Error("error storing report attempt", "channel", compliance.ChannelTwitter, "entry", entry.Id, "feature", entry.Name, "err", err)
+		return
+	}
+
+	if attempt.DeadLetter {
+		slog.Error("giving up reporting", "channel", compliance.ChannelTwitter, "entry", entry.Id, "feature", entry.Name, "attempts", attempt.Attempts)
+		notify(fmt.Sprintf("Hello! I gave up reporting the change of '%v' (entry %v) after %v attempts. The last error was: %v\nRequeue it with the dead-letters command once it is fixed.", entry.Name, entry.Id, attempt.Attempts, attempt.LastError))
+	} else {
+		slog.Warn("will retry reporting", "channel", compliance.ChannelTwitter, "entry", entry.Id, "feature", entry.Name, "at", attempt.NextAttempt.Format(time.RFC3339))
+	}
+}
+
+// recordThreadProgress adds how far the thread of the entry got before failing to its failed attempt, so the next one
+// resumes the thread instead of posting its first tweets again
+func recordThreadProgress(service compliance.Service, entry *compliance.Feature, thread *threadError) {
+	attempt, err := service.GetReportAttempt(context.Background(), entry.Id, compliance.ChannelTwitter)
+	if err != nil || attempt == nil {
+		slog.Error("error getting report attempts to record the posted tweets", "channel", compliance.ChannelTwitter, "entry", entry.Id, "feature", entry.Name, "err", err)
+		return
+	}
+
+	if attempt.ThreadPosted == 0 {
+		attempt.ThreadFirstID = thread.firstID
+	}
+	attempt.ThreadPosted += thread.posted
+	attempt.ThreadLastID = thread.lastID
+
+	if err := service.StoreReportAttempt(context.Background(), attempt); err != nil {
+		slog.Error("error storing the posted tweets of the report attempt", "channel", compliance.ChannelTwitter, "entry", entry.Id, "feature", entry.Name, "err", err)
+	}
+}
+
+// resumeThread leaves out the tweets of the thread that earlier attempts already posted. the attempt is given if there
+// are any, nil if the thread starts over
+func resumeThread(service compliance.Service, entry *compliance.Feature, thread []string) ([]string, *compliance.ReportAttempt) {
+	attempt, err := service.GetReportAttempt(context.Background(), entry.Id, compliance.ChannelTwitter)
+	if err != nil {
+		slog.Error("error getting report attempts, posting the whole thread", "channel", compliance.ChannelTwitter, "entry", entry.Id, "feature", entry.Name, "err", err)
+		return thread, nil
+	}
+	if attempt == nil || attempt.ThreadPosted == 0 {
+		return thread, nil
+	}
+
+	if attempt.ThreadPosted >= len(thread) {
+		return nil, attempt
+	}
+
+	return thread[attempt.ThreadPosted:], attempt
+}
+
+func deadLettersCmdFunc(cmd *cobra.Command, args []string) error {
+	cfg := &Configuration{}
+
+	if err := viper.Unmarshal(cfg); err != nil {
+		return err
+	}
+
+	if cfg.StorageMode != "sqlite3" {
+		return fmt.Errorf("dead-letters needs storageMode sqlite3, not %s", cfg.StorageMode)
+	}
+
+	complianceStorageService, err := openSqliteService(cfg)
+	if err != nil {
+		return err
+	}
+	defer complianceStorageService.Close(context.Background())
+
+	deadLetters, err := complianceStorageService.GetDeadLetters(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if deadLettersRequeueAll || len(deadLettersRequeue) > 0 {
+		requeue := map[int64]bool{}
+		for _, arg := range deadLettersRequeue {
+			id, err := strconv.ParseInt(arg, 10, 64)
+			if err != nil {
+				return fmt.Errorf("'%s' is not an entry id", arg)
+			}
+			requeue[id] = true
+		}
+
+		for _, deadLetter := range deadLetters {
+			if deadLetter.Channel != deadLettersChannel || !(deadLettersRequeueAll || requeue[deadLetter.FeatureID]) {
+				continue
+			}
+
+			if err := complianceStorageService.ClearReportAttempt(context.Background(), deadLetter.FeatureID, deadLetter.Channel); err != nil {
+				return err
+			}
+			slog.Info("requeued entry", "entry", deadLetter.FeatureID, "channel", deadLetter.Channel)
+			delete(requeue, deadLetter.FeatureID)
+		}
+
+		for id := range requeue {
+			slog.Warn("entry is no dead letter", "entry", id, "channel", deadLettersChannel)
+		}
+
+		return nil
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "entry\tchannel\tattempts\tlast attempt\tlast error")
+	for _, deadLetter := range deadLetters {
+		fmt.Fprintf(writer, "%v\t%v\t%v\t%v\t%v\n", deadLetter.FeatureID, deadLetter.Channel, deadLetter.Attempts, deadLetter.LastAttempt.Format(time.RFC3339), deadLetter.LastError)
+	}
+
+	return writer.Flush()
+}
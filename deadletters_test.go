@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestResumeThread(t *testing.T) {
+	cfg := &Configuration{ReportMaxAttempts: 5, ReportRetryBackoff: 60, ReportMaxBackoff: 3600}
+	service := compliance.NewMemoryService()
+	entry := &compliance.Feature{Name: "Modules", CppVersion: 20}
+	if err := service.CreateEntry(context.Background(), entry); err != nil {
+		t.Fatal(err)
+	}
+	thread := []string{"first", "second", "third", "fourth"}
+
+	if remaining, attempt := resumeThread(service, entry, thread); !reflect.DeepEqual(remaining, thread) || attempt != nil {
+		t.Errorf("without attempts: got %v, %v, want the whole thread", remaining, attempt)
+	}
+
+	//the first attempt posts two tweets, the second one more
+	for _, broken := range []*threadError{
+		{posted: 2, firstID: 100, lastID: 101, err: fmt.Errorf("over capacity")},
+		{posted: 1, firstID: 102, lastID: 102, err: fmt.Errorf("over capacity")},
+	} {
+		recordReportFailure(cfg, service, func(string) {}, entry, broken)
+		recordThreadProgress(service, entry, broken)
+	}
+
+	remaining, attempt := resumeThread(service, entry, thread)
+	if !reflect.DeepEqual(remaining, []string{"fourth"}) || attempt == nil {
+		t.Fatalf("got %v, %v, want the fourth tweet", remaining, attempt)
+	}
+	if attempt.ThreadFirstID != 100 || attempt.ThreadLastID != 102 || attempt.Attempts != 2 {
+		t.Errorf("got first tweet %v, last %v after %v attempts, want 100, 102 after 2", attempt.ThreadFirstID, attempt.ThreadLastID, attempt.Attempts)
+	}
+
+	//a thread rendered shorter this time is complete
+	if remaining, attempt := resumeThread(service, entry, thread[:2]); len(remaining) != 0 || attempt == nil {
+		t.Errorf("shorter thread: got %v, %v, want nothing left", remaining, attempt)
+	}
+}
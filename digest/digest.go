@@ -0,0 +1,136 @@
+package digest
+
+import (
+	"bytes"
+	"cppimpbot/compliance"
+	"fmt"
+	"html/template"
+	"time"
+)
+
+var changeTitles = map[string]string{
+	compliance.ChangeRemoved:    "Removed Listing",
+	compliance.ChangeNewListing: "New Listing",
+	compliance.ChangeRename:     "Renamed",
+	compliance.ChangePaper:      "Paper Update",
+	compliance.ChangeSupport:    "Support Update",
+	compliance.ChangeText:       "Text Update",
+}
+
+// Reportable tells if a change belongs into a digest, the same changes the tweets report on
+func Reportable(change compliance.Change) bool {
+	_, ok := changeTitles[change.Kind]
+	return ok
+}
+
+type deltaRow struct {
+	Compiler string
+	Previous string
+	Next     string
+}
+
+type changeItem struct {
+	Title      string
+	CppVersion int
+	Name       string
+	Previous   string //name before a rename
+	OldPaper   string //paper before a paper update
+	PaperName  string
+	PaperLink  string
+	NewListing bool
+	Deltas     []deltaRow
+}
+
+type digestPage struct {
+	Since   time.Time
+	Until   time.Time
+	Changes []changeItem
+}
+
+func supportText(support compliance.CompilerSupport) string {
+	text := compliance.SupportLevelName(support.Support)
+
+	if display := support.DisplayText.String; display != "" {
+		text += " " + display
+	}
+	if extra := support.ExtraText.String; extra != "" {
+		text += " (" + extra + ")"
+	}
+
+	return text
+}
+
+var digestTemplate = template.Must(template.New("digest").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif; color: #222;">
+<h2>C++ compiler support changes</h2>
+<p>{{len .Changes}} changes on cppreference from {{.Since.Format "2006-01-02 15:04"}} to {{.Until.Format "2006-01-02 15:04"}} UTC.</p>
+{{range .Changes}}
+<h3 style="margin-bottom: 4px;">[{{.Title}}] C++{{.CppVersion}} - {{.Name}}</h3>
+{{if .Previous}}<p style="margin: 0;">Previously listed as {{.Previous}}.</p>{{end}}
+{{if .OldPaper}}<p style="margin: 0;">Previously referred to {{.OldPaper}}.</p>{{end}}
+{{if eq .Title "Removed Listing"}}<p style="margin: 0;">No longer listed.</p>{{end}}
+{{if .PaperName}}<p style="margin: 0;">Paper: {{if .PaperLink}}<a href="{{.PaperLink}}">{{.PaperName}}</a>{{else}}{{.PaperName}}{{end}}</p>{{end}}
+{{if .Deltas}}
+<table style="border-collapse: collapse; margin-top: 4px;">
+{{$newListing := .NewListing}}
+{{range .Deltas}}
+<tr>
+<td style="padding: 2px 12px 2px 0;"><b>{{.Compiler}}</b></td>
+{{if $newListing}}<td>{{.Next}}</td>{{else}}<td>{{.Previous}}</td><td style="padding: 0 8px;">&rarr;</td><td>{{.Next}}</td>{{end}}
+</tr>
+{{end}}
+</table>
+{{end}}
+{{end}}
+<p style="color: #888; font-size: small;">Source: https://en.cppreference.com/w/cpp/compiler_support</p>
+</body>
+</html>
+`))
+
+// Render renders the changes between since and until as the subject and the HTML body of a digest mail. changes not
+// worth reporting are left out
+func Render(changes []compliance.Change, since time.Time, until time.Time) (subject string, body string, err error) {
+	page := digestPage{Since: since.UTC(), Until: until.UTC()}
+
+	for _, change := range changes {
+		if !Reportable(change) {
+			continue
+		}
+
+		feature := change.Feature
+		item := changeItem{
+			Title:      changeTitles[change.Kind],
+			CppVersion: feature.CppVersion,
+			Name:       feature.Name,
+			PaperName:  feature.PaperName.String,
+			PaperLink:  feature.PaperLink.String,
+			NewListing: change.Kind == compliance.ChangeNewListing,
+		}
+		if change.Kind == compliance.ChangeRename {
+			item.Previous = change.Previous.Name
+		}
+		if change.Kind == compliance.ChangePaper {
+			item.OldPaper = compliance.PaperOf(change.Previous).String()
+		}
+
+		for _, delta := range change.Deltas {
+			item.Deltas = append(item.Deltas, deltaRow{
+				Compiler: compliance.CompilerDisplayName(delta.Compiler),
+				Previous: supportText(delta.Previous),
+				Next:     supportText(delta.Next),
+			})
+		}
+
+		page.Changes = append(page.Changes, item)
+	}
+
+	var buffer bytes.Buffer
+	if err := digestTemplate.Execute(&buffer, page); err != nil {
+		return "", "", err
+	}
+
+	subject = fmt.Sprintf("C++ compiler support: %v changes until %v", len(page.Changes), page.Until.Format("2006-01-02"))
+
+	return subject, buffer.String(), nil
+}
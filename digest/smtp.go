@@ -0,0 +1,63 @@
+package digest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Mailer sends mails through an SMTP server. the connection is upgraded with STARTTLS when the server offers it, as
+// smtp.SendMail does
+type Mailer struct {
+	Address  string //host:port of the server
+	Username string //no authentication if empty
+	Password string
+	From     string
+}
+
+// message builds a mail with an HTML body, base64 encoded so that long lines and any characters survive
+func (m *Mailer) message(to []string, subject string, htmlBody string) []byte {
+	var buffer bytes.Buffer
+
+	fmt.Fprintf(&buffer, "From: %v\r\n", m.From)
+	fmt.Fprintf(&buffer, "To: %v\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&buffer, "Subject: %v\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buffer, "Date: %v\r\n", time.Now().Format(time.RFC1123Z))
+	buffer.WriteString("MIME-Version: 1.0\r\n")
+	buffer.WriteString("Content-Type: text/html; charset=utf-8\r\n")
+	buffer.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+
+	encoded := base64.StdEncoding.EncodeToString([]byte(htmlBody))
+	for len(encoded) > 76 {
+		buffer.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buffer.WriteString(encoded + "\r\n")
+
+	return buffer.Bytes()
+}
+
+// Send mails the HTML body to all recipients
+func (m *Mailer) Send(to []string, subject string, htmlBody string) error {
+	var auth smtp.Auth
+	if m.Username != "" {
+		host, _, err := net.SplitHostPort(m.Address)
+		if err != nil {
+			return errors.Wrap(err, "invalid SMTP address")
+		}
+		auth = smtp.PlainAuth("", m.Username, m.Password, host)
+	}
+
+	if err := smtp.SendMail(m.Address, auth, m.From, to, m.message(to, subject, htmlBody)); err != nil {
+		return errors.Wrap(err, "could not send mail")
+	}
+
+	return nil
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"cppimpbot/digest"
+	"fmt"
+	"io/ioutil"
+	"log/slog"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var digestCommand = &cobra.Command{
+	Use:   "digest",
+	Short: "Render the email digest of the changes over the last digest interval into an HTML file",
+	RunE:  digestCmdFunc,
+}
+
+var digestOutput string
+var digestSince string
+
+func init() {
+	digestCommand.Flags().StringVarP(&digestOutput, "output", "o", "digest.html", "file to write the digest to")
+	digestCommand.Flags().StringVar(&digestSince, "since", "", "start of the digest as RFC 3339 time. defaults to one DigestInterval ago")
+}
+
+func digestEnabled(cfg *Configuration) bool {
+	return cfg.SmtpAddress != "" && len(cfg.DigestTo) > 0
+}
+
+// digestChanges gives the reportable changes of the entries stored after since and until until, limited to the
+// compilers reported on and leaving out the ignored features
+func digestChanges(cfg *Configuration, service compliance.Service, since time.Time, until time.Time) ([]compliance.Change, error) {
+	entries, err := service.GetEntriesSince(context.Background(), since)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []compliance.Change
+	for i := range entries {
+		if entries[i].Timestamp.After(until) {
+			break
+		}
+
+		previous, err := service.GetPreviousEntry(context.Background(), entries[i].Id)
+		if err != nil {
+			return nil, err
+		}
+
+		change, err := compliance.DiffFeatures(previous, &entries[i])
+		if err != nil {
+			continue
+		}
+
+		change = withoutIgnored(service, reportedChange(cfg, change))
+
+		if digest.Reportable(change) {
+			result = append(result, change)
+		}
+	}
+
+	return result, nil
+}
+
+// sendDigest mails the digest of the changes since the last one, or only logs it if a dry run. windows without
+// changes send no mail
+func sendDigest(cfg *Configuration, service compliance.Service, since time.Time, until time.Time) error {
+	changes, err := digestChanges(cfg, service, since, until)
+	if err != nil {
+		return err
+	}
+
+	if len(changes) == 0 {
+		slog.Info("no changes for the digest", "channel", "digest", "since", since.Format(time.RFC3339))
+		return service.StoreDigest(context.Background(), until, 0)
+	}
+
+	subject, body, err := digest.Render(changes, since, until)
+	if err != nil {
+		return err
+	}
+
+	if cfg.SupressReporting {
+		slog.Info("got digest which will be supressed", "channel", "digest", "subject", subject)
+	} else if cfg.DryReporting {
+		slog.Info("Dry run: mailing digest", "channel", "digest", "subject", subject, "to", cfg.DigestTo)
+		return nil
+	} else {
+		mailer := &digest.Mailer{Address: cfg.SmtpAddress, Username: cfg.SmtpUsername, Password: cfg.SmtpPassword, From: cfg.DigestFrom}
+		if err := mailer.Send(cfg.DigestTo, subject, body); err != nil {
+			return err
+		}
+		slog.Info("mailed digest", "channel", "digest", "subject", subject, "to", cfg.DigestTo)
+	}
+
+	return service.StoreDigest(context.Background(), until, len(changes))
+}
+
+// digestJob sends a digest once DigestInterval passed since the window of the last one ended. the first digest
+// starts its window when the job first runs
+func digestJob(cfg *Configuration, service compliance.Service) func() {
+	interval := time.Duration(cfg.DigestInterval) * time.Hour
+
+	return func() {
+		now := time.Now()
+
+		last, err := service.GetLastDigest(context.Background())
+		if err != nil {
+			slog.Error("error getting the last digest", "channel", "digest", "err", err)
+			return
+		}
+
+		if last.IsZero() {
+			if err := service.StoreDigest(context.Background(), now, 0); err != nil {
+				slog.Error("error starting the digest window", "channel", "digest", "err", err)
+			}
+			return
+		}
+
+		if now.Sub(last) < interval {
+			return
+		}
+
+		if err := sendDigest(cfg, service, last, now); err != nil {
+			slog.Error("error sending the digest", "channel", "digest", "err", err)
+		}
+	}
+}
+
+func digestCmdFunc(cmd *cobra.Command, args []string) error {
+	cfg := &Configuration{}
+
+	if err := viper.Unmarshal(cfg); err != nil {
+		return err
+	}
+
+	if cfg.StorageMode != "sqlite3" {
+		return fmt.Errorf("digest needs storageMode sqlite3, not %s", cfg.StorageMode)
+	}
+
+	until := time.Now()
+	since := until.Add(-time.Duration(cfg.DigestInterval) * time.Hour)
+	if digestSince != "" {
+		var err error
+		if since, err = time.Parse(time.RFC3339, digestSince); err != nil {
+			return fmt.Errorf("--since must be an RFC 3339 time")
+		}
+	}
+
+	complianceStorageService, err := openSqliteService(cfg)
+	if err != nil {
+		return err
+	}
+	defer complianceStorageService.Close(context.Background())
+
+	changes, err := digestChanges(cfg, complianceStorageService, since, until)
+	if err != nil {
+		return err
+	}
+
+	subject, body, err := digest.Render(changes, since, until)
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(digestOutput, []byte(body), 0644); err != nil {
+		return err
+	}
+
+	slog.Info("wrote digest", "subject", subject, "output", digestOutput)
+
+	return nil
+}
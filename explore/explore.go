@@ -0,0 +1,68 @@
+package explore
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const QueryTimeout = 5 * time.Second
+
+type errorResult struct {
+	Error string `json:"error"`
+}
+
+type Handler struct {
+	service compliance.Service
+	maxRows int
+}
+
+// NewHandler serves read-only queries through the service. the database should be opened read-only as well, the
+// checks of QueryReadOnly are only the first line of defence
+func NewHandler(service compliance.Service, maxRows int) *Handler {
+	return &Handler{
+		service: service,
+		maxRows: maxRows,
+	}
+}
+
+// ServeHTTP runs the query given in the "sql" parameter with the "arg" parameters bound in order
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResult{err.Error()})
+		return
+	}
+
+	var args []interface{}
+	for _, arg := range r.Form["arg"] {
+		args = append(args, arg)
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), QueryTimeout)
+	defer cancel()
+
+	result, err := h.service.QueryReadOnly(ctx, r.Form.Get("sql"), args, h.maxRows)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResult{err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		slog.Warn("explore: error writing response", "err", err)
+	}
+}
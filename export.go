@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/api"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var exportCommand = &cobra.Command{
+	Use:   "export",
+	Short: "Dump the current support of every feature as JSON, optionally with the full history",
+	Long: `Dump the current support of every feature as JSON, optionally with the full history.
+The schema is the one of the REST API. schema_version is raised whenever a field changes
+or goes away, so scripts can check it.`,
+	RunE: exportCmdFunc,
+}
+
+var exportHistory bool
+var exportOutput string
+
+func init() {
+	exportCommand.Flags().BoolVar(&exportHistory, "history", false, "include every stored entry, not only the current ones")
+	exportCommand.Flags().StringVarP(&exportOutput, "output", "o", "-", "file to write to, - for stdout")
+}
+
+func exportCmdFunc(cmd *cobra.Command, args []string) error {
+	cfg := &Configuration{}
+
+	if err := viper.Unmarshal(cfg); err != nil {
+		return err
+	}
+
+	if cfg.StorageMode != "sqlite3" {
+		return fmt.Errorf("export needs storageMode sqlite3, not %s", cfg.StorageMode)
+	}
+
+	complianceStorageService, err := openSqliteService(cfg)
+	if err != nil {
+		return err
+	}
+	defer complianceStorageService.Close(context.Background())
+
+	export, err := api.NewExport(context.Background(), complianceStorageService, exportHistory)
+	if err != nil {
+		return err
+	}
+
+	var output io.Writer = os.Stdout
+	if exportOutput != "-" {
+		file, err := os.Create(exportOutput)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		output = file
+	}
+
+	encoder := json.NewEncoder(output)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(export)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var fixTextCommand = &cobra.Command{
+	Use:   "fix-text",
+	Short: "Rewrite stored display or extra texts matching a regex, to clean up after parser bugs",
+	Long: `Rewrite stored display or extra texts matching a regex, to clean up after parser bugs.
+--field is <compiler>_display_text or <compiler>_extra_text, like gcc_display_text, or just display_text
+or extra_text for every compiler. --replace may refer to groups of --match as $1. Every rewrite is logged
+and recorded in the text_fixes table.`,
+	RunE: fixTextCmdFunc,
+}
+
+var fixTextMatch string
+var fixTextReplace string
+var fixTextField string
+var fixTextDryRun bool
+
+func init() {
+	fixTextCommand.Flags().StringVar(&fixTextMatch, "match", "", "regex to find in the texts")
+	fixTextCommand.Flags().StringVar(&fixTextReplace, "replace", "", "replacement for every match")
+	fixTextCommand.Flags().StringVar(&fixTextField, "field", "", "text to rewrite, like gcc_display_text")
+	fixTextCommand.Flags().BoolVar(&fixTextDryRun, "dry-run", false, "only show what would be rewritten")
+}
+
+// parseTextField splits a field like apple_clang_extra_text into the compiler key and the text column
+func parseTextField(field string) (compiler string, column string, err error) {
+	for _, column := range []string{compliance.FieldDisplayText, compliance.FieldExtraText} {
+		if field == column {
+			return "", column, nil
+		}
+		if strings.HasSuffix(field, "_"+column) {
+			return strings.TrimSuffix(field, "_"+column), column, nil
+		}
+	}
+
+	return "", "", fmt.Errorf("unknown field '%s', expected <compiler>_display_text or <compiler>_extra_text", field)
+}
+
+func fixTextCmdFunc(cmd *cobra.Command, args []string) error {
+	cfg := &Configuration{}
+
+	if err := viper.Unmarshal(cfg); err != nil {
+		return err
+	}
+
+	if cfg.StorageMode != "sqlite3" {
+		return fmt.Errorf("fix-text needs storageMode sqlite3, not %s", cfg.StorageMode)
+	}
+
+	if fixTextMatch == "" {
+		return fmt.Errorf("--match is required")
+	}
+
+	pattern, err := regexp.Compile(fixTextMatch)
+	if err != nil {
+		return fmt.Errorf("invalid --match: %v", err)
+	}
+
+	compiler, column, err := parseTextField(fixTextField)
+	if err != nil {
+		return err
+	}
+
+	complianceStorageService, err := openSqliteService(cfg)
+	if err != nil {
+		return err
+	}
+	defer complianceStorageService.Close(context.Background())
+
+	fixes, err := complianceStorageService.FixText(context.Background(), compiler, column, pattern, fixTextReplace, fixTextDryRun)
+	if err != nil {
+		return err
+	}
+
+	for _, fix := range fixes {
+		slog.Info("rewrote text", "dryRun", fixTextDryRun, "column", fix.Compiler+"_"+fix.Field, "feature", fix.Name, "timestamp", fix.Timestamp,
+			"old", fix.OldText, "new", fix.NewText)
+	}
+
+	slog.Info("rewrote texts", "dryRun", fixTextDryRun, "texts", len(fixes))
+
+	return nil
+}
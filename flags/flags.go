@@ -0,0 +1,72 @@
+package flags
+
+import (
+	"log/slog"
+	"strings"
+)
+
+type Flag struct {
+	Name        string
+	Description string
+}
+
+// Known are the experimental subsystems that can be enabled through ExperimentalFeatures in the config
+var Known = []Flag{}
+
+// Retired are flags of subsystems that are no longer experimental. configs naming them still load, the flag is ignored
+var Retired = []Flag{
+	{"incremental-history", "on by default, FullHistory in the config turns it off"},
+}
+
+// Set holds which experimental subsystems are enabled
+type Set map[string]bool
+
+// Parse builds a set out of the configured flag names. names that aren't known are returned separately
+func Parse(names []string) (Set, []string) {
+	set := Set{}
+	var unknown []string
+
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		if retired, ok := find(Retired, name); ok {
+			slog.Warn("experimental feature is retired, remove it from ExperimentalFeatures", "flag", name, "description", retired.Description)
+			continue
+		}
+
+		if _, ok := find(Known, name); !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+
+		set[name] = true
+	}
+
+	return set, unknown
+}
+
+func find(flags []Flag, name string) (Flag, bool) {
+	for _, flag := range flags {
+		if flag.Name == name {
+			return flag, true
+		}
+	}
+
+	return Flag{}, false
+}
+
+func (s Set) Enabled(name string) bool {
+	return s[name]
+}
+
+// LogStatus prints the state of every known flag, so that it is visible in the log what an instance runs with
+func (s Set) LogStatus() {
+	for _, flag := range Known {
+		state := "disabled"
+		if s.Enabled(flag.Name) {
+			state = "enabled"
+		}
+
+		slog.Info("experimental feature", "flag", flag.Name, "state", state, "description", flag.Description)
+	}
+}
@@ -0,0 +1,116 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const RequestTimeout = 10 * time.Second
+
+// statuses of a response, ok only if every check it ran passed
+const (
+	StatusOK      = "ok"
+	StatusFailing = "failing"
+)
+
+// Check is a part of the bot whose health is reported, like the database. Run gives what it found, and an error if
+// the part is unhealthy
+type Check struct {
+	Name string
+	Live bool //checked by /healthz as well as /readyz, for parts a restart of the bot may fix
+	Run  func(ctx context.Context) (detail string, err error)
+}
+
+// Result is how a check went
+type Result struct {
+	Name   string `json:"name"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type Response struct {
+	Status string   `json:"status"`
+	Checks []Result `json:"checks"`
+}
+
+// Server serves /healthz, running the live checks for liveness probes and watchdogs, and /readyz, running all of
+// them for readiness probes. both answer 503 if a check they ran failed
+type Server struct {
+	checks []Check
+	mux    *http.ServeMux
+}
+
+func NewServer(checks []Check) *Server {
+	s := &Server{
+		checks: checks,
+		mux:    http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		s.respond(w, r, true)
+	})
+	s.mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		s.respond(w, r, false)
+	})
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.mux.ServeHTTP(w, r)
+}
+
+// Run runs the checks, only the live ones if liveOnly is set, one after the other
+func Run(ctx context.Context, checks []Check, liveOnly bool) Response {
+	response := Response{Status: StatusOK, Checks: []Result{}}
+
+	for _, check := range checks {
+		if liveOnly && !check.Live {
+			continue
+		}
+
+		detail, err := check.Run(ctx)
+		result := Result{Name: check.Name, OK: err == nil, Detail: detail}
+		if err != nil {
+			result.Error = err.Error()
+			response.Status = StatusFailing
+		}
+
+		response.Checks = append(response.Checks, result)
+	}
+
+	return response
+}
+
+func (s *Server) respond(w http.ResponseWriter, r *http.Request, liveOnly bool) {
+	ctx, cancel := context.WithTimeout(r.Context(), RequestTimeout)
+	defer cancel()
+
+	response := Run(ctx, s.checks, liveOnly)
+
+	status := http.StatusOK
+	if response.Status != StatusOK {
+		status = http.StatusServiceUnavailable
+		for _, result := range response.Checks {
+			if !result.OK {
+				slog.Debug("health: check failing", "check", result.Name, "path", r.URL.Path, "err", result.Error)
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		slog.Warn("health: error writing response", "err", err)
+	}
+}
@@ -0,0 +1,38 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestServer(t *testing.T) {
+	server := NewServer([]Check{
+		{Name: "database", Live: true, Run: func(ctx context.Context) (string, error) { return "reachable", nil }},
+		{Name: "scrapes", Run: func(ctx context.Context) (string, error) {
+			return "", errors.New("cppreference not scraped successfully for 4h0m0s")
+		}},
+	})
+
+	for _, test := range []struct {
+		method string
+		path   string
+		status int
+		body   string
+	}{
+		{http.MethodGet, "/healthz", http.StatusOK, `{"status":"ok","checks":[{"name":"database","ok":true,"detail":"reachable"}]}`},
+		{http.MethodGet, "/readyz", http.StatusServiceUnavailable, `{"status":"failing","checks":[{"name":"database","ok":true,"detail":"reachable"},` +
+			`{"name":"scrapes","ok":false,"error":"cppreference not scraped successfully for 4h0m0s"}]}`},
+		{http.MethodPost, "/healthz", http.StatusMethodNotAllowed, "only GET is supported"},
+	} {
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, httptest.NewRequest(test.method, test.path, nil))
+
+		if recorder.Code != test.status || strings.TrimSpace(recorder.Body.String()) != test.body {
+			t.Errorf("%v %v: got %v %v, want %v %v", test.method, test.path, recorder.Code, recorder.Body.String(), test.status, test.body)
+		}
+	}
+}
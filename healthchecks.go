@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"cppimpbot/health"
+	"cppimpbot/plugins"
+	"cppimpbot/schedule"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// scrapeRecord is how the scrapes of a source went, for the health checks
+type scrapeRecord struct {
+	interval    time.Duration
+	once        bool
+	lastSuccess time.Time
+	lastErr     error
+}
+
+// scrapeHealth keeps track of when every scheduled source was last scraped successfully
+type scrapeHealth struct {
+	sync.Mutex
+	started time.Time
+	names   []string
+	sources map[string]*scrapeRecord
+}
+
+func newScrapeHealth(started time.Time) *scrapeHealth {
+	return &scrapeHealth{started: started, sources: map[string]*scrapeRecord{}}
+}
+
+// add starts keeping track of a source scraped by the job. the longest wait between its runs counts as its interval
+func (h *scrapeHealth) add(name string, job schedule.Job) {
+	h.Lock()
+	defer h.Unlock()
+
+	h.names = append(h.names, name)
+	h.sources[name] = &scrapeRecord{
+		interval: job.Period(h.started) + job.Jitter,
+		once:     job.Once,
+	}
+}
+
+// record remembers how a scrape of the source went
+func (h *scrapeHealth) record(name string, err error, at time.Time) {
+	h.Lock()
+	defer h.Unlock()
+
+	record, ok := h.sources[name]
+	if !ok {
+		return
+	}
+
+	record.lastErr = err
+	if err == nil {
+		record.lastSuccess = at
+	}
+}
+
+// check fails if a source wasn't scraped successfully for longer than maxAge, or three of its intervals if maxAge is
+// 0. sources scraped once only fail if that scrape did. the time since startup counts for sources not scraped yet
+func (h *scrapeHealth) check(maxAge time.Duration, now time.Time) (string, error) {
+	h.Lock()
+	defer h.Unlock()
+
+	var details, stale []string
+	for _, name := range h.names {
+		record := h.sources[name]
+
+		if record.lastSuccess.IsZero() {
+			details = append(details, fmt.Sprintf("%v not scraped successfully yet", name))
+		} else {
+			details = append(details, fmt.Sprintf("%v last scraped successfully at %v", name, record.lastSuccess.Format(time.RFC3339)))
+		}
+
+		if record.once {
+			if record.lastSuccess.IsZero() && record.lastErr != nil {
+				stale = append(stale, fmt.Sprintf("%v failed: %v", name, record.lastErr))
+			}
+			continue
+		}
+
+		limit := maxAge
+		if limit == 0 {
+			limit = 3 * record.interval
+		}
+
+		since := record.lastSuccess
+		if since.IsZero() {
+			since = h.started
+		}
+		if now.Sub(since) > limit {
+			problem := fmt.Sprintf("%v not scraped successfully for %v", name, now.Sub(since).Round(time.Second))
+			if record.lastErr != nil {
+				problem += fmt.Sprintf(", last error: %v", record.lastErr)
+			}
+			stale = append(stale, problem)
+		}
+	}
+
+	if len(stale) > 0 {
+		return strings.Join(details, ", "), fmt.Errorf("%s", strings.Join(stale, ", "))
+	}
+
+	return strings.Join(details, ", "), nil
+}
+
+// failures gives the sources whose last scrape failed, with the error
+func (h *scrapeHealth) failures() []string {
+	h.Lock()
+	defer h.Unlock()
+
+	var result []string
+	for _, name := range h.names {
+		if err := h.sources[name].lastErr; err != nil {
+			result = append(result, fmt.Sprintf("%v: %v", name, err))
+		}
+	}
+
+	return result
+}
+
+// healthChecks are what /healthz and /readyz report on: the database and the reporter, which a restart may bring
+// back, as well as the scrapes and plugins. a service that doesn't report has no reporter to check
+func healthChecks(cfg *Configuration, service compliance.Service, started time.Time, scrapes *scrapeHealth, startedPlugins []*plugins.Plugin) []health.Check {
+	checks := []health.Check{
+		{
+			Name: "database",
+			Live: true,
+			Run: func(ctx context.Context) (string, error) {
+				if err := service.Ping(ctx); err != nil {
+					return "", err
+				}
+
+				return fmt.Sprintf("%v database reachable", cfg.StorageMode), nil
+			},
+		},
+		{
+			Name: "reporter",
+			Live: true,
+			Run: func(ctx context.Context) (string, error) {
+				return reporterHealth(ctx, cfg, service, started, time.Now())
+			},
+		},
+		{
+			Name: "scrapes",
+			Run: func(ctx context.Context) (string, error) {
+				return scrapes.check(time.Duration(cfg.HealthScrapeAge)*time.Second, time.Now())
+			},
+		},
+		{
+			Name: "plugins",
+			Run: func(ctx context.Context) (string, error) {
+				var running, exited []string
+				for _, plugin := range startedPlugins {
+					if plugin.Exited() {
+						exited = append(exited, plugin.Name)
+					} else {
+						running = append(running, plugin.Name)
+					}
+				}
+
+				detail := fmt.Sprintf("%v plugins running", len(running))
+				if len(exited) > 0 {
+					return detail, fmt.Errorf("plugins exited: %v", strings.Join(exited, ", "))
+				}
+
+				return detail, nil
+			},
+		},
+	}
+
+	if !modeReports(cfg) {
+		for i, check := range checks {
+			if check.Name == "reporter" {
+				checks = append(checks[:i], checks[i+1:]...)
+				break
+			}
+		}
+	}
+
+	return checks
+}
+
+// reporterHealth fails if the reporter missed three of its ticks, which store a heartbeat even while reporting is
+// paused by safe mode. the pause is told about, but up to the maintainer to resolve
+func reporterHealth(ctx context.Context, cfg *Configuration, service compliance.Service, started time.Time, now time.Time) (string, error) {
+	heartbeat, err := service.GetHeartbeat(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	detail := "no heartbeat since startup"
+	since := started
+	if heartbeat.After(started) {
+		detail = fmt.Sprintf("last heartbeat at %v", heartbeat.Format(time.RFC3339))
+		since = heartbeat
+	}
+
+	if pause, err := service.GetReportingPause(ctx); err == nil && pause.Paused() {
+		detail += fmt.Sprintf(", paused by safe mode since %v", pause.PausedAt.Format(time.RFC3339))
+	}
+
+	//a schedule reporting in business hours only has the nights between its ticks
+	reportJob, err := scheduleJob("report", cfg.TwitterReportInterval, cfg.TwitterReportJitter, cfg.TwitterReportAlign, cfg.TwitterReportSchedule)
+	if err != nil {
+		return detail, err
+	}
+
+	if limit := 3 * (reportJob.Period(now) + reportJob.Jitter); now.Sub(since) > limit {
+		return detail, fmt.Errorf("reporter missed its ticks for %v", now.Sub(since).Round(time.Second))
+	}
+
+	return detail, nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"cppimpbot/schedule"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestScrapeHealth(t *testing.T) {
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	scrapes := newScrapeHealth(start)
+	scrapes.add("cppreference", schedule.Job{Interval: 300 * time.Second})
+	scrapes.add("gcc-status", schedule.Job{Interval: time.Hour, Jitter: 10 * time.Minute})
+	scrapes.add("backfill", schedule.Job{Once: true})
+	scrapes.record("cppreference", nil, start.Add(10*time.Minute))
+	scrapes.record("cppreference", fmt.Errorf("503 Service Unavailable"), start.Add(15*time.Minute))
+	scrapes.record("backfill", fmt.Errorf("no snapshots"), start)
+
+	detail := "cppreference last scraped successfully at 2024-03-01T12:10:00Z, gcc-status not scraped successfully yet, backfill not scraped successfully yet"
+	for _, test := range []struct {
+		maxAge time.Duration
+		after  time.Duration
+		err    string
+	}{
+		{0, 10 * time.Minute, "backfill failed: no snapshots"},
+		{0, 30 * time.Minute, "cppreference not scraped successfully for 20m0s, last error: 503 Service Unavailable, backfill failed: no snapshots"},
+		{0, 4 * time.Hour, "cppreference not scraped successfully for 3h50m0s, last error: 503 Service Unavailable, " +
+			"gcc-status not scraped successfully for 4h0m0s, backfill failed: no snapshots"},
+		{time.Minute, 12 * time.Minute, "cppreference not scraped successfully for 2m0s, last error: 503 Service Unavailable, " +
+			"gcc-status not scraped successfully for 12m0s, backfill failed: no snapshots"},
+	} {
+		got, err := scrapes.check(test.maxAge, start.Add(test.after))
+		if got != detail {
+			t.Errorf("after %v: got detail %q", test.after, got)
+		}
+		if err == nil || err.Error() != test.err {
+			t.Errorf("after %v with a max age of %v: got %v, want %v", test.after, test.maxAge, err, test.err)
+		}
+	}
+
+	failures := []string{"cppreference: 503 Service Unavailable", "backfill: no snapshots"}
+	if got := scrapes.failures(); !reflect.DeepEqual(got, failures) {
+		t.Errorf("got failures %q, want %q", got, failures)
+	}
+}
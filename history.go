@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"cppimpbot/util"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var historyCommand = &cobra.Command{
+	Use:   "history",
+	Short: "Audit the stored history of the features, read-only",
+}
+
+var historyListCommand = &cobra.Command{
+	Use:   "list",
+	Short: "List every stored feature with its number of entries and its latest one",
+	Args:  cobra.NoArgs,
+	RunE:  historyListCmdFunc,
+}
+
+var historyShowCommand = &cobra.Command{
+	Use:   "show <feature>",
+	Short: "Print every stored entry of a feature, by slug or name, with the support of every compiler",
+	Args:  cobra.ExactArgs(1),
+	RunE:  historyShowCmdFunc,
+}
+
+var historyDiffCommand = &cobra.Command{
+	Use:   "diff <id1> <id2>",
+	Short: "Print two stored entries side by side, marking what differs",
+	Args:  cobra.ExactArgs(2),
+	RunE:  historyDiffCmdFunc,
+}
+
+var historyLanguage string
+
+func init() {
+	historyShowCommand.Flags().StringVar(&historyLanguage, "language", compliance.LanguageCpp, "language to look the feature up in by name, cpp, c or cppdr")
+
+	historyCommand.AddCommand(historyListCommand)
+	historyCommand.AddCommand(historyShowCommand)
+	historyCommand.AddCommand(historyDiffCommand)
+}
+
+const historyTimeFormat = "2006-01-02 15:04"
+
+// openHistory opens the database read-only, as auditing should never change what it audits
+func openHistory() (*compliance.SqliteService, func(), error) {
+	cfg := &Configuration{}
+
+	if err := viper.Unmarshal(cfg); err != nil {
+		return nil, nil, err
+	}
+
+	if cfg.StorageMode != "sqlite3" {
+		return nil, nil, fmt.Errorf("history needs storageMode sqlite3, not %s", cfg.StorageMode)
+	}
+
+	db, err := util.SqliteConnectReadOnly(cfg.Database)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return compliance.NewSqliteService(db), func() { db.Close() }, nil
+}
+
+func supportCell(support *compliance.CompilerSupport) string {
+	if support == nil {
+		return "-"
+	}
+
+	cell := compliance.SupportLevelName(support.Support)
+	if display := support.DisplayText.String; display != "" {
+		cell += " " + display
+	}
+	if extra := support.ExtraText.String; extra != "" {
+		cell += " (" + extra + ")"
+	}
+
+	return cell
+}
+
+// historyVendors gives the vendors listed in any of the entries, in the order they first show up
+func historyVendors(entries ...*compliance.Feature) (result []string) {
+	seen := map[string]bool{}
+
+	for _, entry := range entries {
+		for _, support := range entry.Compilers {
+			if !seen[support.Compiler] {
+				seen[support.Compiler] = true
+				result = append(result, support.Compiler)
+			}
+		}
+	}
+
+	return
+}
+
+func historyListCmdFunc(cmd *cobra.Command, args []string) error {
+	service, closeHistory, err := openHistory()
+	if err != nil {
+		return err
+	}
+	defer closeHistory()
+
+	entries, err := service.GetEntriesSince(context.Background(), time.Time{})
+	if err != nil {
+		return err
+	}
+
+	var slugs []string
+	counts := map[string]int{}
+	latest := map[string]compliance.Feature{}
+	for _, entry := range entries {
+		if counts[entry.Slug] == 0 {
+			slugs = append(slugs, entry.Slug)
+		}
+		counts[entry.Slug]++
+		latest[entry.Slug] = entry
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "slug\tname\tstandard\tentries\tlatest id\tlatest entry\tstate")
+	for _, slug := range slugs {
+		entry := latest[slug]
+
+		state := "listed"
+		if entry.Removed {
+			state = "removed"
+		}
+
+		fmt.Fprintf(writer, "%v\t%v\t%v\t%v\t%v\t%v\t%v\n", slug, entry.Name, entry.Standard(), counts[slug], entry.Id, entry.Timestamp.Format(historyTimeFormat), state)
+	}
+
+	return writer.Flush()
+}
+
+func historyShowCmdFunc(cmd *cobra.Command, args []string) error {
+	if !compliance.IsLanguage(historyLanguage) {
+		return fmt.Errorf("unknown language '%s'", historyLanguage)
+	}
+
+	service, closeHistory, err := openHistory()
+	if err != nil {
+		return err
+	}
+	defer closeHistory()
+
+	entries, err := service.GetFeatureHistoryBySlug(context.Background(), args[0])
+	if err == nil && len(entries) == 0 {
+		entries, err = service.GetFeatureHistory(context.Background(), historyLanguage, args[0])
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(entries) == 0 {
+		return fmt.Errorf("no feature with slug or name '%s'", args[0])
+	}
+
+	var pointers []*compliance.Feature
+	for i := range entries {
+		pointers = append(pointers, &entries[i])
+	}
+	vendors := historyVendors(pointers...)
+
+	header := []string{"id", "timestamp", "name", "paper"}
+	for _, vendor := range vendors {
+		header = append(header, compliance.CompilerDisplayName(vendor))
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, strings.Join(header, "\t"))
+	for _, entry := range entries {
+		name := entry.Name
+		if entry.Removed {
+			name += " (removed)"
+		}
+
+		row := []string{strconv.FormatInt(entry.Id, 10), entry.Timestamp.Format(historyTimeFormat), name, entry.PaperName.String}
+		for _, vendor := range vendors {
+			row = append(row, supportCell(entry.SupportFor(vendor)))
+		}
+		fmt.Fprintln(writer, strings.Join(row, "\t"))
+	}
+
+	return writer.Flush()
+}
+
+func historyDiffCmdFunc(cmd *cobra.Command, args []string) error {
+	var ids []int64
+	for _, arg := range args {
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			return fmt.Errorf("'%s' is not an entry id", arg)
+		}
+		ids = append(ids, id)
+	}
+
+	service, closeHistory, err := openHistory()
+	if err != nil {
+		return err
+	}
+	defer closeHistory()
+
+	entries, err := service.GetEntriesByID(context.Background(), ids)
+	if err != nil {
+		return err
+	}
+
+	byID := map[int64]*compliance.Feature{}
+	for i := range entries {
+		byID[entries[i].Id] = &entries[i]
+	}
+	for _, id := range ids {
+		if byID[id] == nil {
+			return fmt.Errorf("no entry with id %v", id)
+		}
+	}
+	a, b := byID[ids[0]], byID[ids[1]]
+
+	rows := [][3]string{
+		{"name", a.Name, b.Name},
+		{"timestamp", a.Timestamp.Format(historyTimeFormat), b.Timestamp.Format(historyTimeFormat)},
+		{"standard", a.Standard(), b.Standard()},
+		{"paper", a.PaperName.String, b.PaperName.String},
+		{"paper link", a.PaperLink.String, b.PaperLink.String},
+		{"slug", a.Slug, b.Slug},
+		{"removed", strconv.FormatBool(a.Removed), strconv.FormatBool(b.Removed)},
+	}
+	for _, vendor := range historyVendors(a, b) {
+		rows = append(rows, [3]string{compliance.CompilerDisplayName(vendor), supportCell(a.SupportFor(vendor)), supportCell(b.SupportFor(vendor))})
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(writer, "\tfield\t%v\t%v\n", ids[0], ids[1])
+	for _, row := range rows {
+		//the timestamps of two entries always differ, so they aren't marked
+		marker := ""
+		if row[1] != row[2] && row[0] != "timestamp" {
+			marker = "*"
+		}
+		fmt.Fprintf(writer, "%v\t%v\t%v\t%v\n", marker, row[0], row[1], row[2])
+	}
+
+	return writer.Flush()
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var ignoreCommand = &cobra.Command{
+	Use:   "ignore",
+	Short: "Mute the reports of features cppreference editors keep tweaking, while still recording their changes",
+}
+
+var ignoreAddCommand = &cobra.Command{
+	Use:   "add <feature>",
+	Short: "Stop reporting the changes of a feature, by slug or name",
+	Args:  cobra.ExactArgs(1),
+	RunE:  ignoreAddCmdFunc,
+}
+
+var ignoreRemoveCommand = &cobra.Command{
+	Use:   "remove <feature>",
+	Short: "Report the changes of an ignored feature again, by slug or name",
+	Args:  cobra.ExactArgs(1),
+	RunE:  ignoreRemoveCmdFunc,
+}
+
+var ignoreListCommand = &cobra.Command{
+	Use:   "list",
+	Short: "List the ignored features",
+	Args:  cobra.NoArgs,
+	RunE:  ignoreListCmdFunc,
+}
+
+var ignoreLanguage string
+var ignoreReason string
+
+func init() {
+	ignoreAddCommand.Flags().StringVar(&ignoreLanguage, "language", compliance.LanguageCpp, "language to look the feature up in by name, cpp, c or cppdr")
+	ignoreAddCommand.Flags().StringVar(&ignoreReason, "reason", "", "why the feature is ignored, shown by ignore list")
+
+	ignoreCommand.AddCommand(ignoreAddCommand)
+	ignoreCommand.AddCommand(ignoreRemoveCommand)
+	ignoreCommand.AddCommand(ignoreListCommand)
+}
+
+// withoutIgnored leaves changes of ignored features without a kind, so they aren't reported at all. if the ignored
+// features can't be read the change is reported as usual
+func withoutIgnored(service compliance.Service, change compliance.Change) compliance.Change {
+	if change.Kind == "" {
+		return change
+	}
+
+	ignored, err := service.GetIgnoredFeatures(context.Background())
+	if err != nil {
+		slog.Error("error getting the ignored features", "err", err)
+		return change
+	}
+
+	for _, feature := range ignored {
+		if feature.Slug == change.Feature.Slug {
+			slog.Info("not reporting change of ignored feature", "feature", change.Feature.Name, "kind", change.Kind)
+			change.Kind = ""
+			break
+		}
+	}
+
+	return change
+}
+
+func openIgnored() (*compliance.SqliteService, error) {
+	cfg := &Configuration{}
+
+	if err := viper.Unmarshal(cfg); err != nil {
+		return nil, err
+	}
+
+	if cfg.StorageMode != "sqlite3" {
+		return nil, fmt.Errorf("ignore needs storageMode sqlite3, not %s", cfg.StorageMode)
+	}
+
+	return openSqliteService(cfg)
+}
+
+// ignoreFeature ignores the feature of the slug or the name in the language, under its latest name
+func ignoreFeature(service compliance.Service, feature string, language string, reason string) (*compliance.IgnoredFeature, error) {
+	entries, err := service.GetFeatureHistoryBySlug(context.Background(), feature)
+	if err == nil && len(entries) == 0 {
+		entries, err = service.GetFeatureHistory(context.Background(), language, feature)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no feature with slug or name '%s'", feature)
+	}
+
+	latest := entries[len(entries)-1]
+	ignored := &compliance.IgnoredFeature{Slug: latest.Slug, Name: latest.Name, Reason: reason, Timestamp: time.Now()}
+	if err := service.IgnoreFeature(context.Background(), ignored); err != nil {
+		return nil, err
+	}
+
+	return ignored, nil
+}
+
+// unignoreFeature reports the ignored feature of the slug or name again
+func unignoreFeature(service compliance.Service, feature string) (*compliance.IgnoredFeature, error) {
+	ignored, err := service.GetIgnoredFeatures(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range ignored {
+		if ignored[i].Slug != feature && ignored[i].Name != feature {
+			continue
+		}
+
+		if _, err := service.UnignoreFeature(context.Background(), ignored[i].Slug); err != nil {
+			return nil, err
+		}
+		return &ignored[i], nil
+	}
+
+	return nil, fmt.Errorf("'%s' is not ignored, see ignore list", feature)
+}
+
+func ignoreAddCmdFunc(cmd *cobra.Command, args []string) error {
+	if !compliance.IsLanguage(ignoreLanguage) {
+		return fmt.Errorf("unknown language '%s'", ignoreLanguage)
+	}
+
+	service, err := openIgnored()
+	if err != nil {
+		return err
+	}
+	defer service.Close(context.Background())
+
+	ignored, err := ignoreFeature(service, args[0], ignoreLanguage, ignoreReason)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("ignoring \"%v\" (%v), its changes are recorded but not reported\n", ignored.Name, ignored.Slug)
+	return nil
+}
+
+func ignoreRemoveCmdFunc(cmd *cobra.Command, args []string) error {
+	service, err := openIgnored()
+	if err != nil {
+		return err
+	}
+	defer service.Close(context.Background())
+
+	ignored, err := unignoreFeature(service, args[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("reporting \"%v\" (%v) again\n", ignored.Name, ignored.Slug)
+	return nil
+}
+
+func ignoreListCmdFunc(cmd *cobra.Command, args []string) error {
+	service, err := openIgnored()
+	if err != nil {
+		return err
+	}
+	defer service.Close(context.Background())
+
+	ignored, err := service.GetIgnoredFeatures(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if len(ignored) == 0 {
+		fmt.Println("no ignored features")
+		return nil
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "slug\tname\tignored\treason")
+	for _, feature := range ignored {
+		fmt.Fprintf(writer, "%v\t%v\t%v\t%v\n", feature.Slug, feature.Name, feature.Timestamp.Format(historyTimeFormat), feature.Reason)
+	}
+
+	return writer.Flush()
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"testing"
+)
+
+func TestIgnoreFeature(t *testing.T) {
+	service := compliance.NewMemoryService()
+	feature := compliance.Feature{Name: "__has_include", CppVersion: 17, Compilers: []compliance.CompilerSupport{testSupport("gcc", 0, "", "")}}
+	if err := service.CreateEntry(context.Background(), &feature); err != nil {
+		t.Fatal(err)
+	}
+	previous := copyTestFeature(feature)
+	feature.Compilers = []compliance.CompilerSupport{testSupport("gcc", 0, "", "tweaked")}
+	if err := service.CreateEntry(context.Background(), &feature); err != nil {
+		t.Fatal(err)
+	}
+	change := sampleChange(t, &previous, &feature)
+
+	if kind := withoutIgnored(service, change).Kind; kind != compliance.ChangeText {
+		t.Errorf("before ignoring: got kind '%v'", kind)
+	}
+
+	ignored, err := ignoreFeature(service, "__has_include", compliance.LanguageCpp, "keeps getting reworded")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ignored.Name != "__has_include" || ignored.Slug != "has-include" || ignored.Reason != "keeps getting reworded" {
+		t.Errorf("ignored %+v", ignored)
+	}
+	if kind := withoutIgnored(service, change).Kind; kind != "" {
+		t.Errorf("while ignored: got kind '%v'", kind)
+	}
+	if history, err := service.GetFeatureHistory(context.Background(), compliance.LanguageCpp, "__has_include"); len(history) != 2 || err != nil {
+		t.Errorf("ignored feature has %v entries, %v", len(history), err)
+	}
+
+	if _, err := ignoreFeature(service, "nonexistent", compliance.LanguageCpp, ""); err == nil || err.Error() != "no feature with slug or name 'nonexistent'" {
+		t.Errorf("ignoring an unknown feature: got %v", err)
+	}
+
+	if _, err := unignoreFeature(service, ignored.Slug); err != nil {
+		t.Fatal(err)
+	}
+	if kind := withoutIgnored(service, change).Kind; kind != compliance.ChangeText {
+		t.Errorf("after unignoring: got kind '%v'", kind)
+	}
+	if _, err := unignoreFeature(service, "__has_include"); err == nil || err.Error() != "'__has_include' is not ignored, see ignore list" {
+		t.Errorf("unignoring again: got %v", err)
+	}
+}
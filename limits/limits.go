@@ -0,0 +1,318 @@
+package limits
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Target is a place posts go to, with the way it counts the length of a post
+type Target struct {
+	Name      string
+	Limit     int  //length a single post may have
+	URLLength int  //length every link counts as, no matter how long it is. 0 if links count like other text
+	Weighted  bool //if set, characters outside the ranges twitter counts as light count twice
+	Threads   bool //if set, posts that don't fit are posted as replies to each other instead of one after the other
+}
+
+var (
+	Twitter  = Target{Name: "twitter", Limit: 280, URLLength: 23, Weighted: true, Threads: true}
+	Mastodon = Target{Name: "mastodon", Limit: 500, URLLength: 23, Threads: true}
+	Discord  = Target{Name: "discord", Limit: 2000}
+	Telegram = Target{Name: "telegram", Limit: 4096}
+)
+
+// Targets are all targets the posts are checked against
+var Targets = []Target{Twitter, Mastodon, Discord, Telegram}
+
+// links are counted as long as twitter's link shortener makes them, no matter how long they are. like twitter, bare
+// domains of the common top level domains count as links too, trailing punctuation doesn't
+var urlPattern = regexp.MustCompile(`(?i)\b(?:https?://\S+|(?:[a-z0-9-]+\.)+(?:com|org|net|edu|gov|io|dev|app|info)\b(?:/\S*)?)`)
+
+const urlTrailing = ".,;:!?'\")"
+
+// the code points twitter counts as a single character, everything else counts as two. emoji count as two no matter
+// how many code points they are made of
+var lightRanges = [][2]rune{{0, 4351}, {8192, 8205}, {8208, 8223}, {8242, 8247}}
+
+var emojiRanges = [][2]rune{{0x2300, 0x23ff}, {0x2600, 0x27bf}, {0x2b00, 0x2bff}, {0x1f000, 0x1faff}}
+
+const (
+	zeroWidthJoiner    = 0x200d
+	emojiPresentation  = 0xfe0f
+	combiningKeycap    = 0x20e3
+	regionalIndicatorA = 0x1f1e6
+	regionalIndicatorZ = 0x1f1ff
+)
+
+func inRanges(r rune, ranges [][2]rune) bool {
+	for _, bounds := range ranges {
+		if r >= bounds[0] && r <= bounds[1] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// extends tells if the code point belongs to the character before it: combining marks, variation selectors, skin
+// tones, the tags of subdivision flags and zero width joiners
+func extends(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc) || r == zeroWidthJoiner ||
+		(r >= 0xfe00 && r <= 0xfe0f) || (r >= 0x1f3fb && r <= 0x1f3ff) || (r >= 0xe0020 && r <= 0xe007f)
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= regionalIndicatorA && r <= regionalIndicatorZ
+}
+
+// graphemes splits the text into what readers see as single characters, so that nothing is cut in the middle of an
+// accented letter, a flag or an emoji joined from several ones
+func graphemes(text string) (result []string) {
+	runes := []rune(text)
+
+	for start := 0; start < len(runes); {
+		end := start + 1
+		if isRegionalIndicator(runes[start]) && end < len(runes) && isRegionalIndicator(runes[end]) {
+			end++
+		}
+		for end < len(runes) && (extends(runes[end]) || runes[end-1] == zeroWidthJoiner) {
+			end++
+		}
+
+		result = append(result, string(runes[start:end]))
+		start = end
+	}
+
+	return
+}
+
+func isEmoji(grapheme string) bool {
+	for _, r := range grapheme {
+		if r == emojiPresentation || r == combiningKeycap || inRanges(r, emojiRanges) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// urlSpans gives the start and end of every link in the text, without the punctuation that ends a sentence after it
+// or closes parentheses around it
+func urlSpans(text string) (result [][2]int) {
+	for _, span := range urlPattern.FindAllStringIndex(text, -1) {
+		end := span[1]
+		for end > span[0] && strings.ContainsRune(urlTrailing, rune(text[end-1])) {
+			if text[end-1] == ')' && strings.Count(text[span[0]:end], "(") >= strings.Count(text[span[0]:end], ")") {
+				break
+			}
+			end--
+		}
+
+		result = append(result, [2]int{span[0], end})
+	}
+
+	return
+}
+
+// segment is a piece of text posts can be cut between, a link or a single character
+type segment struct {
+	text string
+	url  bool
+}
+
+// segments splits the text into its links and characters. the text is normalized first if the target counts as twitter
+// does, so that an accented letter counts the same no matter how it was typed
+func (t Target) segments(text string) (result []segment) {
+	if t.Weighted {
+		text = norm.NFC.String(text)
+	}
+
+	if t.URLLength == 0 {
+		for _, grapheme := range graphemes(text) {
+			result = append(result, segment{text: grapheme})
+		}
+		return
+	}
+
+	last := 0
+	for _, span := range urlSpans(text) {
+		for _, grapheme := range graphemes(text[last:span[0]]) {
+			result = append(result, segment{text: grapheme})
+		}
+		result = append(result, segment{text: text[span[0]:span[1]], url: true})
+		last = span[1]
+	}
+	for _, grapheme := range graphemes(text[last:]) {
+		result = append(result, segment{text: grapheme})
+	}
+
+	return
+}
+
+func (t Target) weight(s segment) (result int) {
+	if s.url {
+		return t.URLLength
+	}
+	if !t.Weighted {
+		return utf8.RuneCountInString(s.text)
+	}
+	if isEmoji(s.text) {
+		return 2
+	}
+
+	for _, r := range s.text {
+		if inRanges(r, lightRanges) {
+			result++
+		} else {
+			result += 2
+		}
+	}
+
+	return
+}
+
+// Count gives the length of the text as the target counts it
+func (t Target) Count(text string) (result int) {
+	for _, s := range t.segments(text) {
+		result += t.weight(s)
+	}
+
+	return
+}
+
+// Fits tells if the text can be posted as a single post
+func (t Target) Fits(text string) bool {
+	return t.Count(text) <= t.Limit
+}
+
+// Trim cuts the text off with an ellipsis where the target would refuse it. it is cut between characters and before
+// links, never within them
+func (t Target) Trim(text string) string {
+	return t.TrimFor(text, "")
+}
+
+// TrimFor cuts the text off like Trim, but early enough for the suffix to fit after the ellipsis, and ends it with the
+// suffix. a text that fits with the suffix is kept whole
+func (t Target) TrimFor(text string, suffix string) string {
+	if t.Fits(text + suffix) {
+		return text + suffix
+	}
+
+	segments := t.segments(text)
+	length := t.Count("..." + suffix)
+	kept := 0
+	for _, s := range segments {
+		if length+t.weight(s) > t.Limit {
+			break
+		}
+		length += t.weight(s)
+		kept++
+	}
+
+	var trimmed strings.Builder
+	for _, s := range segments[:kept] {
+		trimmed.WriteString(s.text)
+	}
+
+	return trimmed.String() + "..." + suffix
+}
+
+// counterReserve is the room left in every post of a split text for its counter, like " (2/3)"
+const counterReserve = len(" (99/99)")
+
+// pieces cuts a line that is too long for a post at spaces, or between any characters if a single word is too long as well
+func (t Target) pieces(line string, budget int) (result []string) {
+	current := ""
+
+	for _, word := range strings.Split(line, " ") {
+		candidate := word
+		if current != "" {
+			candidate = current + " " + word
+		}
+
+		if t.Count(candidate) <= budget {
+			current = candidate
+			continue
+		}
+
+		if current != "" {
+			result = append(result, current)
+		}
+		current = word
+
+		for t.Count(current) > budget {
+			characters := graphemes(current)
+			cut := len(characters)
+			for cut > 1 && t.Count(strings.Join(characters[:cut], "")) > budget {
+				cut--
+			}
+
+			result = append(result, strings.Join(characters[:cut], ""))
+			current = strings.Join(characters[cut:], "")
+		}
+	}
+
+	if current != "" {
+		result = append(result, current)
+	}
+
+	return
+}
+
+// Split gives the posts the text has to be posted as. a text that fits is a single post, others are split between
+// lines, or within lines that are too long by themselves, and every post gets a counter like " (1/3)" so that nothing
+// has to be trimmed away
+func (t Target) Split(text string) []string {
+	if t.Fits(text) {
+		return []string{text}
+	}
+
+	budget := t.Limit - counterReserve
+
+	var posts []string
+	current := ""
+
+	flush := func() {
+		if trimmed := strings.Trim(current, "\n"); trimmed != "" {
+			posts = append(posts, trimmed)
+		}
+		current = ""
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		candidate := line
+		if current != "" {
+			candidate = current + "\n" + line
+		}
+
+		if t.Count(candidate) <= budget {
+			current = candidate
+			continue
+		}
+
+		flush()
+
+		if t.Count(line) <= budget {
+			current = line
+			continue
+		}
+
+		pieces := t.pieces(line, budget)
+		for _, piece := range pieces[:len(pieces)-1] {
+			posts = append(posts, piece)
+		}
+		current = pieces[len(pieces)-1]
+	}
+	flush()
+
+	for i := range posts {
+		posts[i] += fmt.Sprintf(" (%v/%v)", i+1, len(posts))
+	}
+
+	return posts
+}
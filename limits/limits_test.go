@@ -0,0 +1,55 @@
+package limits
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCount(t *testing.T) {
+	for _, test := range []struct {
+		text    string
+		twitter int
+		discord int
+	}{
+		{"plain ascii", 11, 11},
+		{"ümlauts", 7, 7},
+		{"u\u0308mlauts typed with a combining mark", 35, 36},
+		{"日本語", 6, 3},
+		{"link https://en.cppreference.com/w/cpp/compiler_support", 28, 55},
+		{"bare link cppreference.com/w/cpp", 33, 32},
+		{"(see https://wg21.link/p0702r1).", 30, 32},
+		{"flag 🇸🇪", 7, 7},
+		{"family 👨\u200d👩\u200d👧", 9, 12},
+		{"thumbs 👍🏽", 9, 9},
+		{"keycap 1\ufe0f\u20e3", 9, 10},
+		{"heart \u2764\ufe0f", 8, 8},
+	} {
+		if count := Twitter.Count(test.text); count != test.twitter {
+			t.Errorf("'%v': got %v on twitter, want %v", test.text, count, test.twitter)
+		}
+		if count := Discord.Count(test.text); count != test.discord {
+			t.Errorf("'%v': got %v on discord, want %v", test.text, count, test.discord)
+		}
+	}
+}
+
+func TestTrim(t *testing.T) {
+	for _, test := range []struct {
+		text   string
+		count  int
+		ending string
+	}{
+		{strings.Repeat("a", 278) + "👨\u200d👩\u200d👧 and more", 280, "aaaaa..."},
+		{strings.Repeat("a", 270) + " https://en.cppreference.com/w/cpp", 274, "aaaa ..."},
+		{strings.Repeat("e\u0301", 281), 280, "\u00e9..."}, //composed, as twitter counts it
+	} {
+		trimmed := Twitter.Trim(test.text)
+		if count := Twitter.Count(trimmed); count != test.count || !strings.HasSuffix(trimmed, test.ending) {
+			t.Errorf("%.20q...: trimmed to %v, want %v ending in %q", test.text, count, test.count, test.ending)
+		}
+	}
+
+	if text := "fits as is"; Twitter.Trim(text) != text {
+		t.Errorf("got %q trimmed", Twitter.Trim(text))
+	}
+}
@@ -0,0 +1,135 @@
+package limits
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// quietHours is a time of day nothing is posted, in minutes since midnight. it ends the next day if end is before start
+type quietHours struct {
+	start int
+	end   int
+}
+
+func (q quietHours) contains(minute int) bool {
+	if q.start <= q.end {
+		return minute >= q.start && minute < q.end
+	}
+	return minute >= q.start || minute < q.end
+}
+
+func parseClock(clock string) (int, error) {
+	parsed, err := time.Parse("15:04", strings.TrimSpace(clock))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q, expected one like 22:00", clock)
+	}
+
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
+
+// parseQuietHours reads comma separated times of day like "22:00-07:00, 12:00-13:00"
+func parseQuietHours(spec string) ([]quietHours, error) {
+	var result []quietHours
+
+	for _, part := range strings.Split(spec, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+
+		bounds := strings.Split(part, "-")
+		if len(bounds) != 2 {
+			return nil, fmt.Errorf("invalid quiet hours %q, expected a start and end like 22:00-07:00", part)
+		}
+
+		start, err := parseClock(bounds[0])
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseClock(bounds[1])
+		if err != nil {
+			return nil, err
+		}
+		if start == end {
+			return nil, fmt.Errorf("quiet hours %q start and end at the same time", part)
+		}
+
+		result = append(result, quietHours{start: start, end: end})
+	}
+
+	return result, nil
+}
+
+// Pace spaces out posts so followers aren't flooded when a lot changes at once: at most a number of posts per hour, a
+// minimum time between posts and quiet hours without posts, in local time. what is held back is posted later
+type Pace struct {
+	maxPerHour int
+	minSpacing time.Duration
+	quiet      []quietHours
+	posted     []time.Time //posts of the last hour
+	mutex      sync.Mutex
+}
+
+// NewPace sets up a pace of at most maxPerHour posts an hour, minSpacing apart, and none during the quiet hours, like
+// "22:00-07:00". zero values and empty quiet hours don't hold posts back
+func NewPace(maxPerHour int, minSpacing time.Duration, quiet string) (*Pace, error) {
+	if maxPerHour < 0 || minSpacing < 0 {
+		return nil, fmt.Errorf("the posts per hour and the spacing can't be negative")
+	}
+
+	parsed, err := parseQuietHours(quiet)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Pace{maxPerHour: maxPerHour, minSpacing: minSpacing, quiet: parsed}, nil
+}
+
+// Wait tells how long a post has to wait from now, 0 if it can be posted, and why if not
+func (p *Pace) Wait(now time.Time) (time.Duration, string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	local := now.In(time.Local)
+	minute := local.Hour()*60 + local.Minute()
+	for _, quiet := range p.quiet {
+		if quiet.contains(minute) {
+			wait := quiet.end - minute
+			if wait <= 0 {
+				wait += 24 * 60
+			}
+			return time.Duration(wait)*time.Minute - time.Duration(local.Second())*time.Second, "quiet hours"
+		}
+	}
+
+	p.forget(now)
+
+	if p.minSpacing > 0 && len(p.posted) > 0 {
+		if wait := p.posted[len(p.posted)-1].Add(p.minSpacing).Sub(now); wait > 0 {
+			return wait, "spacing between posts"
+		}
+	}
+
+	if p.maxPerHour > 0 && len(p.posted) >= p.maxPerHour {
+		return p.posted[len(p.posted)-p.maxPerHour].Add(time.Hour).Sub(now), fmt.Sprintf("%v posts an hour", p.maxPerHour)
+	}
+
+	return 0, ""
+}
+
+// Posted counts a post made at the given time
+func (p *Pace) Posted(at time.Time) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.posted = append(p.posted, at)
+	p.forget(at)
+}
+
+// forget drops the posts older than an hour, which count against neither the spacing nor the posts per hour
+func (p *Pace) forget(now time.Time) {
+	for len(p.posted) > 0 && now.Sub(p.posted[0]) >= time.Hour && now.Sub(p.posted[0]) >= p.minSpacing {
+		p.posted = p.posted[1:]
+	}
+}
@@ -0,0 +1,54 @@
+package limits
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPace(t *testing.T) {
+	pace, err := NewPace(3, 10*time.Minute, "22:00-07:00, 12:00-12:30")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.Local)
+	for _, test := range []struct {
+		after time.Duration
+		wait  time.Duration
+		why   string
+	}{
+		{0, 0, ""},
+		{5 * time.Minute, 5 * time.Minute, "spacing between posts"},
+		{10 * time.Minute, 0, ""},
+		{20 * time.Minute, 0, ""},
+		{30 * time.Minute, 30 * time.Minute, "3 posts an hour"},
+		{40 * time.Minute, 20 * time.Minute, "3 posts an hour"},
+		{61 * time.Minute, 0, ""},
+		{3*time.Hour + 10*time.Minute, 20 * time.Minute, "quiet hours"},
+		{13 * time.Hour, 9 * time.Hour, "quiet hours"},
+	} {
+		at := start.Add(test.after)
+		wait, why := pace.Wait(at)
+		if wait != test.wait || why != test.why {
+			t.Errorf("%v: got wait %v by '%v', want %v by '%v'", at.Format("15:04"), wait, why, test.wait, test.why)
+		}
+		if wait == 0 {
+			pace.Posted(at)
+		}
+	}
+}
+
+func TestPaceInvalid(t *testing.T) {
+	for _, test := range []struct {
+		spec string
+		err  string
+	}{
+		{"22:00", `invalid quiet hours "22:00", expected a start and end like 22:00-07:00`},
+		{"25:00-07:00", `invalid time of day "25:00", expected one like 22:00`},
+		{"07:00-07:00", `quiet hours "07:00-07:00" start and end at the same time`},
+	} {
+		if _, err := NewPace(0, 0, test.spec); err == nil || err.Error() != test.err {
+			t.Errorf("%q: got %v, want %v", test.spec, err, test.err)
+		}
+	}
+}
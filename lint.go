@@ -0,0 +1,388 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"cppimpbot/limits"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var lintReportsCommand = &cobra.Command{
+	Use:   "lint-reports",
+	Short: "Render synthetic and stored changes with every report renderer, and fail if any report is too long, empty or can't be rendered",
+	Long: `Render synthetic and stored changes with every report renderer, and fail if any report is too long, empty or can't be rendered.
+The synthetic changes cover edge cases like long and non-ASCII names, many compilers and every kind of change. The
+stored ones are every entry against the one before it, since --since if given. Reports are rendered with every
+configured variant, ReportHooks and the reporters compiled in, and so are the batch and catch-up threads of all of them.
+Exits with an error if anything was found, so it can gate a deploy of a new build or config.`,
+	Args: cobra.NoArgs,
+	RunE: lintReportsCmdFunc,
+}
+
+var lintSince string
+var lintHistory bool
+var lintMaxTweets int
+
+func init() {
+	lintReportsCommand.Flags().StringVar(&lintSince, "since", "", "lint the stored changes scraped since this day, like 2019-01-02, instead of all")
+	lintReportsCommand.Flags().BoolVar(&lintHistory, "history", true, "lint the stored changes besides the synthetic ones, needs storageMode sqlite3")
+	lintReportsCommand.Flags().IntVar(&lintMaxTweets, "max-tweets", 4, "tweets a report thread may have before it counts as too long")
+}
+
+// lintCase is a change to lint the reports of, between two entries of a feature. previous is nil for new listings
+type lintCase struct {
+	name     string
+	previous *compliance.Feature
+	next     *compliance.Feature
+}
+
+// lintReport is the reports of a change to lint
+type lintReport struct {
+	name   string
+	change compliance.Change
+}
+
+// lintFixtures are synthetic changes of the edge cases reports have to handle
+func lintFixtures() []lintCase {
+	base := compliance.Feature{
+		Name:       "Lambda capture of structured bindings",
+		CppVersion: 20,
+		PaperName:  sql.NullString{String: "P1091R3", Valid: true},
+		PaperLink:  sql.NullString{String: "https://wg21.link/P1091R3", Valid: true},
+	}
+	base.SetSupport(fixtureSupport(compliance.CompilerGcc, 0, "", ""))
+	base.SetSupport(fixtureSupport(compliance.CompilerClang, 2, "8 (partial)*", "only with -fexperimental"))
+	base.SetSupport(fixtureSupport(compliance.CompilerMsvc, 0, "", ""))
+
+	supported := copyFixture(base)
+	supported.SetSupport(fixtureSupport(compliance.CompilerGcc, 1, "10", ""))
+
+	textChanged := copyFixture(base)
+	textChanged.SetSupport(fixtureSupport(compliance.CompilerClang, 2, "8 (partial)*", "only with -fexperimental-structured-bindings"))
+
+	longName := copyFixture(base)
+	longName.Name = strings.Repeat("Relaxing the rules about the lifetime of temporaries bound to references ", 4)
+	longNameSupported := copyFixture(longName)
+	longNameSupported.SetSupport(fixtureSupport(compliance.CompilerGcc, 1, "10", ""))
+
+	unicodeName := copyFixture(base)
+	unicodeName.Name = "Unicode identifiers: ünïcödé, 日本語の識別子 and 👨‍👩‍👧 in std::format"
+	unicodeNameSupported := copyFixture(unicodeName)
+	unicodeNameSupported.SetSupport(fixtureSupport(compliance.CompilerGcc, 1, "10", ""))
+
+	markupName := copyFixture(base)
+	markupName.Name = `operator<=> & "*bold*" <b>tags</b> _under_ https://example.com/path)`
+	markupNameSupported := copyFixture(markupName)
+	markupNameSupported.SetSupport(fixtureSupport(compliance.CompilerMsvc, 1, "19.22", ""))
+
+	longTexts := copyFixture(base)
+	longTexts.SetSupport(fixtureSupport(compliance.CompilerMsvc, 2, "19.20 (partial)*", strings.Repeat("not supported in constant expressions, ", 5)))
+
+	allCompilers := copyFixture(base)
+	allCompilersSupported := copyFixture(base)
+	for _, compiler := range []string{"gcc", "clang", "msvc", "apple_clang", "edg", "intel", "ibm_xl", "ibm_openxl", "oracle", "embarcadero", "cray", "pgi", "nvcc", "nvhpc"} {
+		allCompilers.SetSupport(fixtureSupport(compiler, 0, "", ""))
+		allCompilersSupported.SetSupport(fixtureSupport(compiler, 1, "12.1.0", ""))
+	}
+
+	renamed := copyFixture(supported)
+	renamed.Name = "Capturing structured bindings in lambdas"
+
+	removed := copyFixture(base)
+	removed.Removed = true
+
+	paperChanged := copyFixture(base)
+	paperChanged.PaperName = sql.NullString{String: "P1091R4", Valid: true}
+
+	library := compliance.Feature{Name: "std::format", CppVersion: 20}
+	library.SetSupport(fixtureLibrarySupport(compliance.LibraryLibstdcxx, 0, "", ""))
+	library.SetSupport(fixtureLibrarySupport(compliance.LibraryLibcxx, 2, "14*", ""))
+	library.SetSupport(fixtureLibrarySupport(compliance.LibraryMsvcStl, 1, "19.29", ""))
+	librarySupported := copyFixture(library)
+	librarySupported.SetSupport(fixtureLibrarySupport(compliance.LibraryLibstdcxx, 1, "13", ""))
+
+	cFeature := compliance.Feature{Name: "typeof and typeof_unqual", CppVersion: 23, Language: compliance.LanguageC}
+	cFeature.SetSupport(fixtureSupport(compliance.CompilerGcc, 1, "13", ""))
+	cFeature.SetSupport(fixtureSupport(compliance.CompilerClang, 1, "16", ""))
+
+	return []lintCase{
+		{"new listing", nil, &base},
+		{"support gained", &base, &supported},
+		{"support lost", &supported, &base},
+		{"text changed", &base, &textChanged},
+		{"long name", &longName, &longNameSupported},
+		{"new listing of a long name", nil, &longName},
+		{"non-ASCII name", &unicodeName, &unicodeNameSupported},
+		{"name with markup and a link", &markupName, &markupNameSupported},
+		{"long support texts", &base, &longTexts},
+		{"every compiler", &allCompilers, &allCompilersSupported},
+		{"new listing with every compiler", nil, &allCompilersSupported},
+		{"renamed", &base, &renamed},
+		{"removed", &base, &removed},
+		{"listed again", &removed, &base},
+		{"paper changed", &base, &paperChanged},
+		{"library", &library, &librarySupported},
+		{"C feature", nil, &cFeature},
+	}
+}
+
+// fixtureSupport is the support of a compiler in a synthetic feature
+func fixtureSupport(compiler string, support int, displayText string, extraText string) compliance.CompilerSupport {
+	return compliance.CompilerSupport{
+		Compiler:    compiler,
+		Kind:        compliance.KindCompiler,
+		Support:     support,
+		DisplayText: sql.NullString{String: displayText, Valid: true},
+		ExtraText:   sql.NullString{String: extraText, Valid: true},
+	}
+}
+
+// fixtureLibrarySupport is the support of a standard library in a synthetic feature
+func fixtureLibrarySupport(library string, support int, displayText string, extraText string) compliance.CompilerSupport {
+	result := fixtureSupport(library, support, displayText, extraText)
+	result.Kind = compliance.KindLibrary
+	return result
+}
+
+// copyFixture copies a synthetic feature so that changing support of the copy leaves the original alone
+func copyFixture(feature compliance.Feature) compliance.Feature {
+	feature.Compilers = append([]compliance.CompilerSupport(nil), feature.Compilers...)
+	return feature
+}
+
+// reportableKind tells if changes of the kind are reported at all, which those left without a kind by filters aren't
+func reportableKind(kind string) bool {
+	return kind != ""
+}
+
+// linter renders reports as the bot would and collects what is wrong with them
+type linter struct {
+	cfg       *Configuration
+	options   compliance.ReportOptions
+	templates *compliance.ReportTemplates
+	hooks     *compliance.Hooks
+	maxTweets int
+	problems  []string
+}
+
+func newLinter(cfg *Configuration, maxTweets int) (*linter, error) {
+	options, err := loadReportOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	templates, err := compliance.NewReportTemplates(cfg.ReportVariants)
+	if err != nil {
+		return nil, err
+	}
+
+	hooks, err := compliance.LoadHooks(cfg.ReportHooks)
+	if err != nil {
+		return nil, err
+	}
+
+	return &linter{cfg: cfg, options: options, templates: templates, hooks: hooks, maxTweets: maxTweets}, nil
+}
+
+func (l *linter) problem(name string, format string, args ...interface{}) {
+	l.problems = append(l.problems, fmt.Sprintf("%v: %v", name, fmt.Sprintf(format, args...)))
+}
+
+// thread checks the tweets of a thread rendered by the named renderer, which may have up to maxTweets of them
+func (l *linter) thread(name string, tweets []string, maxTweets int) {
+	if len(tweets) > maxTweets {
+		l.problem(name, "thread of %v tweets, more than %v", len(tweets), maxTweets)
+	}
+
+	for i, tweet := range tweets {
+		if strings.TrimSpace(tweet) == "" {
+			l.problem(name, "tweet %v of %v is empty", i+1, len(tweets))
+		}
+		if !limits.Twitter.Fits(tweet) {
+			l.problem(name, "tweet %v of %v counts %v of %v", i+1, len(tweets), limits.Twitter.Count(tweet), limits.Twitter.Limit)
+		}
+		if strings.Contains(tweet, "<no value>") || strings.Contains(tweet, "%!") {
+			l.problem(name, "tweet %v of %v has a placeholder left: %q", i+1, len(tweets), tweet)
+		}
+	}
+}
+
+// diff diffs the entries of the case, and gives the change as it would be reported. ok is false if it can't be
+func (l *linter) diff(c lintCase) (report lintReport, ok bool) {
+	change, err := compliance.DiffFeatures(c.previous, c.next)
+	if err != nil {
+		l.problem(c.name, "cannot handle: %v", err)
+		return lintReport{}, false
+	}
+
+	return lintReport{c.name, l.hooks.Filter(l.options, reportedChange(l.cfg, change))}, true
+}
+
+// report renders the change with every variant and reporter
+func (l *linter) report(report lintReport) {
+	change := report.change
+	if !reportableKind(change.Kind) {
+		return
+	}
+
+	link, _ := reportLink(l.cfg, l.options, nil, change)
+	texts, err := l.templates.RenderEach(l.options, change, templateLink(l.cfg, link))
+	if err != nil {
+		l.problem(report.name, "%v", err)
+	}
+
+	var variants []string
+	for variant := range texts {
+		variants = append(variants, variant)
+	}
+	sort.Strings(variants)
+
+	for _, variant := range variants {
+		name := fmt.Sprintf("%v, twitter variant %v", report.name, variant)
+
+		text := reportFooter(l.cfg, change, l.hooks.Text(l.options, change, templateLink(l.cfg, link), texts[variant]), link)
+		if strings.TrimSpace(text) == "" {
+			if texts[variant] == "" {
+				l.problem(name, "empty report of a %v change", change.Kind)
+			}
+			//left out by the text hook on purpose
+			continue
+		}
+
+		l.thread(name, limits.Twitter.Split(text), l.maxTweets)
+	}
+
+	for _, reporter := range compiledReporters() {
+		if lint := reporterRegistry[reporter].lint; lint != nil {
+			for _, problem := range lint(l.options, change) {
+				l.problem(fmt.Sprintf("%v, %v", report.name, reporter), "%v", problem)
+			}
+		}
+	}
+}
+
+// threads renders the reports together, as batches and catch-up threads do
+func (l *linter) threads(name string, reports []lintReport) {
+	var changes []compliance.Change
+	for _, report := range reports {
+		changes = append(changes, report.change)
+	}
+
+	for _, details := range []bool{false, true} {
+		l.thread(fmt.Sprintf("%v, batch thread (details %v)", name, details), compliance.BatchToTwitterThread(l.options, changes, details, l.cfg.ReportBatchMaxTweets), l.cfg.ReportBatchMaxTweets)
+	}
+	l.thread(name+", catch-up thread", compliance.CatchUpToTwitterThread(l.options, changes, time.Now().AddDate(0, 0, -1), l.cfg.CatchUpMaxTweets), l.cfg.CatchUpMaxTweets)
+}
+
+// lint lints every case on its own and all of them together
+func (l *linter) lint(name string, cases []lintCase) {
+	var reports []lintReport
+	for _, c := range cases {
+		if report, ok := l.diff(c); ok {
+			l.report(report)
+			reports = append(reports, report)
+		}
+	}
+
+	if len(reports) > 0 {
+		l.threads(name, reports)
+	}
+}
+
+// scrapeGroups splits the cases of stored entries into the scrapes that stored them, as a scrape stores its changes
+// within moments of each other
+func scrapeGroups(cases []lintCase) (groups [][]lintCase) {
+	for i, c := range cases {
+		if i == 0 || c.next.Timestamp.Sub(cases[i-1].next.Timestamp) > time.Minute {
+			groups = append(groups, nil)
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], c)
+	}
+
+	return
+}
+
+// storedLintCases gives every stored entry since the given time against the one before it
+func storedLintCases(service compliance.Service, since time.Time) ([]lintCase, error) {
+	entries, err := service.GetEntriesSince(context.Background(), since)
+	if err != nil {
+		return nil, err
+	}
+
+	var cases []lintCase
+	for i := range entries {
+		previous, err := service.GetPreviousEntry(context.Background(), entries[i].Id)
+		if err != nil {
+			return nil, err
+		}
+
+		cases = append(cases, lintCase{fmt.Sprintf("entry %v '%v'", entries[i].Id, entries[i].Name), previous, &entries[i]})
+	}
+
+	return cases, nil
+}
+
+func lintReportsCmdFunc(cmd *cobra.Command, args []string) error {
+	cfg := &Configuration{}
+
+	if err := viper.Unmarshal(cfg); err != nil {
+		return err
+	}
+
+	since := time.Time{}
+	if lintSince != "" {
+		var err error
+		if since, err = time.Parse("2006-01-02", lintSince); err != nil {
+			return fmt.Errorf("--since must be a day like 2019-01-02")
+		}
+	}
+
+	l, err := newLinter(cfg, lintMaxTweets)
+	if err != nil {
+		return err
+	}
+
+	fixtures := lintFixtures()
+	l.lint("synthetic changes", fixtures)
+	linted := len(fixtures)
+
+	if lintHistory {
+		if cfg.StorageMode != "sqlite3" {
+			return fmt.Errorf("lint-reports needs storageMode sqlite3 to lint the stored changes, not %s. lint only the synthetic ones with --history=false", cfg.StorageMode)
+		}
+
+		complianceStorageService, err := openSqliteService(cfg)
+		if err != nil {
+			return err
+		}
+		defer complianceStorageService.Close(context.Background())
+
+		stored, err := storedLintCases(complianceStorageService, since)
+		if err != nil {
+			return err
+		}
+		for _, group := range scrapeGroups(stored) {
+			l.lint(fmt.Sprintf("changes scraped %v", group[0].next.Timestamp.Format(historyTimeFormat)), group)
+		}
+		linted += len(stored)
+	}
+
+	for _, problem := range l.problems {
+		fmt.Println(problem)
+	}
+	slog.Info("linted the reports", "changes", linted, "reporters", len(compiledReporters()), "problems", len(l.problems))
+
+	if len(l.problems) > 0 {
+		return fmt.Errorf("%v problems in the reports", len(l.problems))
+	}
+
+	return nil
+}
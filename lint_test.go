@@ -0,0 +1,15 @@
+package main
+
+import "testing"
+
+func TestLintFixtures(t *testing.T) {
+	l, err := newLinter(&Configuration{ReportBatchMaxTweets: 4, CatchUpMaxTweets: 4}, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l.lint("synthetic changes", lintFixtures())
+	for _, problem := range l.problems {
+		t.Errorf("problem: %v", problem)
+	}
+}
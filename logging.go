@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"strings"
+)
+
+// logOutput is where the logs are written, taken from the log package the first time logging is configured, so it
+// follows log.SetOutput like the log file of the Windows service
+var logOutput io.Writer
+
+// parseLogLevel reads a LogLevel of the config, debug, info, warn or error. info if empty
+func parseLogLevel(level string) (slog.Level, error) {
+	var result slog.Level
+	if level == "" {
+		return slog.LevelInfo, nil
+	}
+
+	if err := result.UnmarshalText([]byte(level)); err != nil {
+		return result, fmt.Errorf("unknown LogLevel %s, expected debug, info, warn or error", level)
+	}
+
+	return result, nil
+}
+
+// configureLogging sets up the logger everything logs through for the config. the text format keeps the lines of the
+// log package with the level in front and the fields after the message, json writes an object per line to be shipped
+// to log collectors, with the message, level and fields like feature, compiler and channel as keys
+func configureLogging(level string, format string) error {
+	parsed, err := parseLogLevel(level)
+	if err != nil {
+		return err
+	}
+
+	if logOutput == nil {
+		logOutput = log.Writer()
+	}
+
+	switch strings.ToLower(format) {
+	case "", "text":
+		slog.SetLogLoggerLevel(parsed)
+	case "json":
+		//the lines still logged through the log package, like those of the test command, go through it at info level
+		slog.SetDefault(slog.New(slog.NewJSONHandler(logOutput, &slog.HandlerOptions{Level: parsed})))
+	default:
+		return fmt.Errorf("unknown LogFormat %s, expected text or json", format)
+	}
+
+	return nil
+}
+
+// checkLogging checks the logging settings of the config without applying them
+func checkLogging(cfg *Configuration) error {
+	if _, err := parseLogLevel(cfg.LogLevel); err != nil {
+		return err
+	}
+
+	switch strings.ToLower(cfg.LogFormat) {
+	case "", "text", "json":
+		return nil
+	}
+
+	return fmt.Errorf("unknown LogFormat %s, expected text or json", cfg.LogFormat)
+}
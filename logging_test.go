@@ -0,0 +1,29 @@
+package main
+
+import (
+	"log/slog"
+	"testing"
+)
+
+func TestLogging(t *testing.T) {
+	for _, test := range []struct {
+		cfg   Configuration
+		level slog.Level
+		err   string
+	}{
+		{Configuration{}, slog.LevelInfo, ""},
+		{Configuration{LogLevel: "debug", LogFormat: "json"}, slog.LevelDebug, ""},
+		{Configuration{LogLevel: "WARN", LogFormat: "text"}, slog.LevelWarn, ""},
+		{Configuration{LogLevel: "verbose"}, slog.LevelInfo, "unknown LogLevel verbose, expected debug, info, warn or error"},
+		{Configuration{LogFormat: "xml"}, slog.LevelInfo, "unknown LogFormat xml, expected text or json"},
+	} {
+		if level, _ := parseLogLevel(test.cfg.LogLevel); level != test.level {
+			t.Errorf("level '%v': got %v, want %v", test.cfg.LogLevel, level, test.level)
+		}
+
+		err := checkLogging(&test.cfg)
+		if (err == nil) != (test.err == "") || (err != nil && err.Error() != test.err) {
+			t.Errorf("level '%v' format '%v': got %v, want %q", test.cfg.LogLevel, test.cfg.LogFormat, err, test.err)
+		}
+	}
+}
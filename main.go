@@ -2,17 +2,35 @@ package main
 
 import (
 	"context"
+	"cppimpbot/api"
+	"cppimpbot/bluesky"
 	"cppimpbot/compliance"
+	"cppimpbot/dashboard"
+	"cppimpbot/explore"
+	"cppimpbot/flags"
+	"cppimpbot/health"
+	"cppimpbot/limits"
+	"cppimpbot/mentions"
+	"cppimpbot/plugins"
+	"cppimpbot/schedule"
 	"cppimpbot/scraper"
+	"cppimpbot/shortener"
+	"cppimpbot/slo"
 	"cppimpbot/util"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"log"
+	"log/slog"
+	"net/http"
+	"net/url"
 	"os"
-	"os/signal"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 
 	"github.com/dghubble/go-twitter/twitter"
@@ -20,27 +38,178 @@ import (
 	_ "github.com/mattn/go-sqlite3"
 )
 
+type SourceConfig struct {
+	Name     string
+	Interval int    //seconds between scrapes
+	Jitter   int    //up to this many seconds are randomly added to every interval
+	Align    string //if set, scrapes are this far into every interval counted from midnight UTC, like "17m" for :17
+	Schedule string //cron expression of when to scrape, like "0 */2 * * *", instead of every Interval
+	Once     bool   //scrape a single time at startup, e.g. for backfills
+}
+
 type Configuration struct {
-	StorageMode           string
-	Database              string
-	MigrateDir            string
-	ConsumerKey           string
-	ConsumerSecret        string
-	AccessToken           string
-	AccessSecret          string
-	MaintainerTwitterId   string
-	SafeMode              bool
-	SafeModeMaxReports    int
-	WebScrapeInterval     int
-	TwitterReportInterval int
-	SupressReporting      bool //if this is true, all changes will be marked as reported without actually reporting them
-	DryReporting          bool //if this is true, changes will be reported using prints only, and not marked as reported
+	Mode                    string //all, or scrape or report to split the work with another service sharing the database
+	StorageMode             string
+	StorageMiddleware       []string //layers wrapped around the storage service, outermost first: metrics, retry, cache
+	StorageCacheTTL         int      //seconds cached reads are kept by the cache layer
+	StorageRetryAttempts    int      //calls per storage operation the retry layer makes before giving up
+	StorageRetryBackoff     int      //milliseconds before the first retry, doubled for every following one
+	Database                string
+	DatabaseSizeAlert       int    //MB of stored data past which the maintainer is alerted. 0 disables the alert
+	DatabaseRotateSize      int    //MB of stored data past which old history is moved to an archive file. 0 disables archiving
+	DatabaseArchiveDir      string //directory the archive files are written to
+	DatabaseArchiveAge      int    //days of history that always stay in the database when archiving
+	DatabaseCheckInterval   int    //seconds between checks of the database size
+	MigrateDir              string
+	ConsumerKey             string
+	ConsumerSecret          string
+	AccessToken             string
+	AccessSecret            string
+	MaintainerTwitterId     string
+	MaintainerNotifiers     []string //how the maintainer is reached: twitter, email, telegram, ntfy or pushover. see maintainerNotifiers if empty
+	MaintainerEmail         []string //addresses the email notifier mails to
+	NtfyServer              string   //ntfy server the ntfy notifier publishes to, the public ntfy.sh if empty
+	NtfyTopic               string
+	NtfyToken               string //access token of a protected topic
+	PushoverToken           string //api token of the Pushover application the pushover notifier pushes through
+	PushoverUser            string //user key of the maintainer on Pushover
+	SafeMode                bool
+	SafeModeMaxReports      int
+	WebScrapeInterval       int            //used if no Sources are configured
+	WebScrapeJitter         int            //like the Jitter of a source, used if no Sources are configured
+	WebScrapeAlign          string         //like the Align of a source, used if no Sources are configured
+	ScrapeSchedule          string         //like the Schedule of a source, used if no Sources are configured
+	Targets                 []string       //languages whose compiler support pages are scraped every WebScrapeInterval if no Sources are configured, cpp or c
+	Sources                 []SourceConfig //sources to scrape, each on its own schedule
+	ScrapeMaxAttempts       int            //attempts per scrape before giving up until the next interval
+	ScrapeBackoff           int            //seconds before the first retry, doubled for every following one
+	ScrapeMaxBackoff        int
+	ScrapeRetryJitter       int
+	MaintenancePause        int //seconds to stop scraping a source after it served a maintenance page
+	ScrapeUrl               string
+	ScrapeCUrl              string //compiler support page of C, scraped by the cppreference-c source
+	ScrapeUserAgent         string
+	ScrapeTimeout           int     //seconds per request
+	ScrapeProxy             string  //proxy url for scraping. the usual proxy environment variables are used if empty
+	FingerprintMaxRowChange float64 //fraction the row count of a table may change between scrapes before the maintainer is alerted
+	StrictParsing           bool    //skip table rows that can't be parsed without guessing and alert the maintainer about them
+	ScrapeMinKnown          float64 //fraction of the known features of a language a scrape has to list to be stored, 0 to store any
+	ScrapeSnapshots         bool    //keep the page of every scrape that parsed differently than the one before
+	ScrapeSnapshotKeep      int     //pages kept per source, the oldest are dropped. 0 keeps all
+	ScrapeDefectReports     bool    //also scrape the table of C++ defect reports into the cppdr language
+	CorrectionOutbox        string  //directory corrections of cppreference data that looks wrong are drafted into. disabled if empty
+	GccStatusUrl            string  //C++ status page of GCC, cross-checked with cppreference by the gcc-status source
+	ClangStatusUrl          string  //C++ status page of Clang, cross-checked with cppreference by the clang-status source
+	DiscrepancyAlerts       bool    //alert the maintainer about disagreements cross-check sources newly find
+	FullHistory             bool    //store every compiler cell of each new entry instead of only the ones that changed
+
+	ReleaseFeeds             []ReleaseFeedConfig
+	ReleasePollInterval      int //seconds between checks of the release feeds
+	TwitterReportInterval    int
+	TwitterReportJitter      int                            //up to this many seconds are randomly added to every report interval
+	TwitterReportAlign       string                         //like the Align of a source, for the reports
+	TwitterReportSchedule    string                         //cron expression of when to report, like "*/5 9-17 * * 1-5", instead of every TwitterReportInterval
+	ReportMaxPerHour         int                            //reports posted an hour at most, the others wait. no limit if 0
+	ReportMinSpacing         int                            //seconds between two reports at least
+	ReportQuietHours         string                         //times of day no reports are posted, like "22:00-07:00". they are posted after
+	ReportCompilers          []string                       //compiler and library keys whose changes are reported, like gcc or libstdcxx. all if empty
+	ReportLanguages          []string                       //languages whose changes are reported, cpp, c or cppdr. all if empty
+	YearReview               bool                           //if this is true, a thread reviewing the previous year is posted in January
+	YearReviewInterval       int                            //seconds between checks if the review is due
+	WeeklySummary            bool                           //if this is true, a summary of the changes of the week is posted on twitter and the reporters
+	WeeklySummarySchedule    string                         //cron expression of when the weekly summary is posted, like "0 9 * * 1"
+	WeeklySummaryMaxTweets   int                            //tweets the weekly summary thread is limited to
+	AggregateInterval        int                            //seconds between recordings of how much of every standard each compiler supports. 0 disables it
+	MilestoneReports         bool                           //if this is true, compilers reaching one of the Milestones of a standard are tweeted about
+	Milestones               []int                          //percentages of full support of a standard that are milestones
+	MilestoneTemplates       []compliance.MilestoneTemplate //phrasings of the milestones of a percentage, replacing the built-in ones
+	ReportVariants           []compliance.ReportVariant     //phrasings of the reports, picked by weight per kind of change
+	EngagementInterval       int                            //seconds between updates of the likes and retweets of posted reports. 0 disables it
+	EngagementWindow         int                            //days after posting the engagement of a report is followed
+	ReportMaxAttempts        int                            //failed attempts after which a report becomes a dead letter, requeued with the dead-letters command
+	ReportRetryBackoff       int                            //seconds to wait before retrying a failed report, doubled with every further failure
+	ReportMaxBackoff         int                            //seconds the wait before retrying a failed report is capped at
+	CatchUpAfter             int                            //seconds the reporter has to be down for the missed changes to be posted as one thread. 0 disables it
+	CatchUpMaxTweets         int                            //tweets the catch-up thread is limited to
+	ReportPaperLinks         bool                           //if this is true, reports link to the paper of the feature
+	ReportLinksIfRoom        bool                           //link reports to the paper only where their tweets have room, trimming the prose of single tweets for it
+	ReportCppreferenceLinks  bool                           //with ReportLinksIfRoom, also link to the table of cppreference the feature is listed in if there is room
+	ReportHashtags           []string                       //hashtags reports end with where there is room, like #cpp
+	ReportMentions           map[string]string              //accounts mentioned in reports of a compiler where there is room, by compiler key
+	ReportAfter              map[string][]string            //channels a reporter only posts a change on after they did, like telegram = ["slack"]
+	LinkShortener            string                         //shortener the links of reports go through: yourls, bitly, or none if empty
+	LinkShortenerUrl         string                         //API endpoint of a self-hosted shortener
+	LinkShortenerToken       string                         //signature or access token of the shortener API
+	QueueBackend             string                         //where entries waiting to be reported are queued: database, or redis for deployments running it anyway
+	RedisAddress             string
+	RedisPassword            string
+	RedisDatabase            int
+	RedisKeyPrefix           string //prefix of the keys of the report queue
+	Suggestions              bool   //if this is true, the dashboard takes suggestions of features to watch at /suggest
+	SuggestionLimit          int    //amount of suggestions a single address can make within SuggestionWindow seconds
+	SuggestionWindow         int
+	Plugins                  []plugins.Config        //binaries started along the bot that report changes or are sources
+	ReportApproval           bool                    //if this is true, reports wait for the maintainer to approve them with the approve command before they are posted
+	ReportHooks              string                  //Starlark script that can veto changes, mute compilers of them and rewrite report texts. reloaded when it changes
+	ReportRules              []compliance.ReportRule //rules suppressing or allowing reports by kind, feature, paper and support level, the first matching one deciding
+	ReportThreads            bool                    //reports of a feature or paper changed more than once at a time reply to each other
+	ReportBatching           bool                    //post the changes of a scrape as a single summary tweet instead of one tweet each
+	ReportBatchDetails       bool                    //follow the summary with a thread listing every change
+	ReportBatchMaxTweets     int                     //tweets a batch thread is limited to
+	Reporters                []string                //reporters changes and alerts go to besides twitter, all compiled in if empty
+	ReportImageCards         bool                    //attach a PNG card with the support before and after the change to the first tweet of reports
+	ReportTextDir            string                  //directory of templates rewording the reports of every channel, see compliance.LoadTexts
+	ReportChangesOnly        bool                    //list only what changed of the support of every compiler in reports, with arrows
+	SupressReporting         bool                    //if this is true, all changes will be marked as reported without actually reporting them
+	DryReporting             bool                    //if this is true, changes will be reported using prints only, and not marked as reported
+	SlackWebhookUrl          string                  //incoming webhook changes are posted to as well. Slack reporting is disabled if empty
+	SlackChannel             string                  //channel to post to instead of the one of the webhook, if the webhook allows it
+	SlackAlerts              bool                    //if this is true, maintainer alerts like safe mode trips and scrape errors go to Slack too
+	TelegramBotToken         string                  //token of the Telegram bot. Telegram is disabled if empty
+	TelegramChatId           string                  //chat changes are posted to as well, not posted if empty
+	TelegramMaintainerChatId string                  //private chat the telegram notifier messages the maintainer in
+	SmtpAddress              string                  //host:port of the SMTP server digests are mailed through. digests are disabled if empty
+	SmtpUsername             string
+	SmtpPassword             string
+	DigestFrom               string
+	DigestTo                 []string //recipients of the digest
+	DigestInterval           int      //hours of changes every digest covers
+	DigestCheckInterval      int      //seconds between checks if a digest is due
+	MentionReplies           bool     //if this is true, mentions asking about a paper or feature get a reply with its support
+	MentionPollInterval      int
+	MentionUserLimit         int //amount of replies a single user can get within MentionUserWindow seconds
+	MentionUserWindow        int
+	MentionMaxReplies        int             //amount of replies sent at most per poll
+	BlueskyHost              string          //server of the bluesky account, bsky.social unless it is self-hosted
+	BlueskyHandle            string          //bluesky account mentions are answered on as well, like cppimpbot.bsky.social. disabled if empty
+	BlueskyAppPassword       string          //app password of the bluesky account
+	Objectives               []slo.Objective //service level objectives the bot keeps track of
+	ObjectiveWindow          int             //days the objectives are measured over
+	ObjectiveReportInterval  int             //seconds between reports of how the objectives fare
+	ObjectiveCheckInterval   int             //seconds between checks if an error budget ran out
+	ApiAddress               string          //address the REST API is served on, with GraphQL at /graphql. the API is disabled if empty
+	DashboardAddress         string          //address the html dashboard is served on. the dashboard is disabled if empty
+	HealthAddress            string          //address /healthz and /readyz are served on for service managers. disabled if empty
+	HealthScrapeAge          int             //seconds without a successful scrape of a source after which /readyz fails. 0 allows three intervals
+	ExploreAddress           string
+	ExploreMaxRows           int
+	ExperimentalFeatures     []string //experimental subsystems to enable, see the flags package
+	LogLevel                 string   //least severe level logged: debug, info, warn or error
+	LogFormat                string   //text, or json for one object per line with the fields of every log as keys
 }
 
 var rootCommand = &cobra.Command{
 	Use:   "server",
 	Short: "Start cpp impl bot service",
-	RunE:  rootCmdFunc,
+	Long: `Start cpp impl bot service.
+An interrupt or SIGTERM shuts it down gracefully, on Windows it can run as a service, see the service command.
+While it runs, SIGHUP reloads the config by restarting the bot in place if the new config is valid, SIGUSR1 runs all
+scrapes right away and SIGUSR2 dumps the internal state to the log.
+With --once it scrapes every source, reports what changed and exits, for running it from cron or CI. It exits with 2
+if a scrape failed, 3 if changes could not be reported and 4 if reporting is paused by safe mode.
+With --mode scrape or --mode report it only scrapes into the database or only reports from it, so the two can run
+apart from each other.`,
+	RunE: rootCmdFunc,
 }
 
 var testCommand = &cobra.Command{
@@ -49,41 +218,775 @@ var testCommand = &cobra.Command{
 	RunE:  testCmdFunc,
 }
 
+// sendMaintainerMessage sends a direct message on twitter to the maintainer
+func sendMaintainerMessage(cfg *Configuration, client *twitter.Client, message string) error {
+	//directmessage, httpresponse, err
+	_, _, err := client.DirectMessages.EventsNew(&twitter.DirectMessageEventsNewParams{
+		Event: &twitter.DirectMessageEvent{
+			Type: "message_create",
+			Message: &twitter.DirectMessageEventMessage{
+				Target: &twitter.DirectMessageTarget{
+					RecipientID: cfg.MaintainerTwitterId,
+				},
+				Data: &twitter.DirectMessageData{
+					Text: message,
+				},
+			},
+		},
+	})
+
+	return err
+}
+
+// wrapStorage puts the configured middleware layers around the storage service. the first layer in the config is the
+// outermost one. the metrics are nil if the metrics layer isn't configured
+func wrapStorage(cfg *Configuration, service compliance.Service) (compliance.Service, *compliance.Metrics, error) {
+	var metrics *compliance.Metrics
+
+	for i := len(cfg.StorageMiddleware) - 1; i >= 0; i-- {
+		switch layer := cfg.StorageMiddleware[i]; layer {
+		case "cache":
+			service = compliance.NewCachingService(service, time.Duration(cfg.StorageCacheTTL)*time.Second)
+		case "retry":
+			retry := compliance.RetryMiddleware(cfg.StorageRetryAttempts, time.Duration(cfg.StorageRetryBackoff)*time.Millisecond, compliance.IsBusy)
+			service = compliance.Wrap(service, retry)
+		case "metrics":
+			if metrics != nil {
+				return nil, nil, fmt.Errorf("storage middleware metrics configured more than once")
+			}
+			metrics = compliance.NewMetrics()
+			service = compliance.Wrap(service, metrics.Middleware())
+		default:
+			return nil, nil, fmt.Errorf("unknown storage middleware: %s", layer)
+		}
+	}
+
+	return service, metrics, nil
+}
+
+// queueBackends are the external report queues compiled in, each registered from its own file, which a build tag like
+// noredis leaves out
+var queueBackends = map[string]func(cfg *Configuration, service compliance.Service) (compliance.Service, error){}
+
+// queueReports moves the queue of entries waiting to be reported to the configured backend. the database backend works
+// them out of the reports table and needs nothing more
+func queueReports(cfg *Configuration, service compliance.Service) (compliance.Service, error) {
+	switch cfg.QueueBackend {
+	case "database":
+		return service, nil
+	default:
+		if backend, ok := queueBackends[cfg.QueueBackend]; ok {
+			return backend(cfg, service)
+		}
+
+		return nil, fmt.Errorf("unknown queueBackend or not compiled in: %s", cfg.QueueBackend)
+	}
+}
+
+// featureFromScraped turns a scraped table row into a feature entry for storage
+func featureFromScraped(cppVersion *scraper.CppVersionSupport, feature *scraper.CppFeature) compliance.Feature {
+	dbFeature := compliance.Feature{
+		Language:   cppVersion.Language,
+		Name:       feature.Name,
+		CppVersion: cppVersion.Version,
+		PaperName:  sql.NullString{String: feature.PaperName, Valid: true},
+		PaperLink:  sql.NullString{String: feature.PaperLink, Valid: true},
+	}
+
+	for _, support := range feature.Compilers {
+		key, kind := compliance.CompilerKey(support.Compiler), compliance.KindCompiler
+		if cppVersion.Library {
+			key, kind = compliance.LibraryKey(support.Compiler), compliance.KindLibrary
+		}
+
+		dbFeature.SetSupport(compliance.CompilerSupport{
+			Compiler:    key,
+			Kind:        kind,
+			Support:     support.Support,
+			DisplayText: sql.NullString{String: support.DisplayString, Valid: true},
+			ExtraText:   sql.NullString{String: support.ExtraString, Valid: true},
+			Version:     compliance.SupportVersion(support.DisplayString),
+		})
+	}
+
+	return dbFeature
+}
+
+var exploreCommand = &cobra.Command{
+	Use:   "explore",
+	Short: "Serve read-only SQL queries against the database over HTTP",
+	RunE:  exploreCmdFunc,
+}
+
+func parseExperimentalFeatures(cfg *Configuration) (flags.Set, error) {
+	experimental, unknown := flags.Parse(cfg.ExperimentalFeatures)
+
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("unknown experimental features in config: %v", unknown)
+	}
+
+	return experimental, nil
+}
+
+// sources sets up every built-in source by the name it is configured as. pages laid out differently, like the C++
+// status page of GCC, get a scraper.Source of their own added here
+var sources = map[string]func(cfg *Configuration) (scraper.Source, error){
+	"cppreference": func(cfg *Configuration) (scraper.Source, error) {
+		return newScraper(cfg, "cppreference", cfg.ScrapeUrl)
+	},
+	"cppreference-c": func(cfg *Configuration) (scraper.Source, error) {
+		return newScraper(cfg, "cppreference-c", cfg.ScrapeCUrl)
+	},
+}
+
+// targetSources names the source scraping the compiler support page of every language that can be a target
+var targetSources = map[string]string{
+	compliance.LanguageCpp: "cppreference",
+	compliance.LanguageC:   "cppreference-c",
+}
+
+// checkTargets fails on targets without a compiler support page of their own
+func checkTargets(cfg *Configuration) error {
+	for _, target := range cfg.Targets {
+		if _, ok := targetSources[target]; !ok {
+			return fmt.Errorf("unknown language in Targets: %s, the targets are cpp and c", target)
+		}
+	}
+
+	return nil
+}
+
+// sourceConfigs gives the configured sources, falling back to scraping the page of every target every WebScrapeInterval
+func sourceConfigs(cfg *Configuration) []SourceConfig {
+	if len(cfg.Sources) > 0 {
+		return cfg.Sources
+	}
+
+	var sources []SourceConfig
+	for _, target := range cfg.Targets {
+		sources = append(sources, SourceConfig{
+			Name:     targetSources[target],
+			Interval: cfg.WebScrapeInterval,
+			Jitter:   cfg.WebScrapeJitter,
+			Align:    cfg.WebScrapeAlign,
+			Schedule: cfg.ScrapeSchedule,
+		})
+	}
+
+	return sources
+}
+
+// scheduleJob sets up a job running every interval seconds plus up to jitter seconds, aligned according to align. align
+// is how far into every interval the job runs, like "17m" for every hour at :17 with an interval of 3600. it isn't
+// aligned if align is empty. a cron expression in cron replaces the interval, which stays the fallback if it is empty.
+// without either the job would run without a pause, so that fails
+func scheduleJob(name string, interval int, jitter int, align string, cron string) (schedule.Job, error) {
+	job := schedule.Job{
+		Name:     name,
+		Interval: time.Duration(interval) * time.Second,
+		Jitter:   time.Duration(jitter) * time.Second,
+	}
+
+	if cron == "" && interval <= 0 {
+		return job, fmt.Errorf("%s needs an interval of at least a second or a schedule, got an interval of %v", name, interval)
+	}
+
+	if cron != "" {
+		if align != "" {
+			return job, fmt.Errorf("%s can't have both a schedule and an Align, put the minute into the schedule", name)
+		}
+
+		parsed, err := schedule.ParseCron(cron)
+		if err != nil {
+			return job, fmt.Errorf("invalid schedule of %s: %v", name, err)
+		}
+
+		job.Cron = parsed
+		return job, nil
+	}
+
+	if align == "" {
+		return job, nil
+	}
+
+	offset, err := time.ParseDuration(align)
+	if err != nil || offset < 0 {
+		return job, fmt.Errorf("invalid Align %s of %s, expected a duration like 17m", align, name)
+	}
+
+	job.Aligned = true
+	job.Offset = offset
+	return job, nil
+}
+
+// addJob adds a job running every interval seconds to the scheduler
+func addJob(scheduler *schedule.Scheduler, name string, interval int, run func()) error {
+	job, err := scheduleJob(name, interval, 0, "", "")
+	if err != nil {
+		return err
+	}
+
+	job.Run = run
+	scheduler.Add(job)
+	return nil
+}
+
+func scrapeRetryPolicy(cfg *Configuration) scraper.RetryPolicy {
+	return scraper.RetryPolicy{
+		MaxAttempts: cfg.ScrapeMaxAttempts,
+		Backoff:     time.Duration(cfg.ScrapeBackoff) * time.Second,
+		MaxBackoff:  time.Duration(cfg.ScrapeMaxBackoff) * time.Second,
+		Jitter:      time.Duration(cfg.ScrapeRetryJitter) * time.Second,
+	}
+}
+
+// newScraper sets up a scraper of the given page according to the HTTP settings in the config, as the source of the name
+func newScraper(cfg *Configuration, name string, pageUrl string) (*scraper.Scraper, error) {
+	transport := &http.Transport{Proxy: http.ProxyFromEnvironment}
+
+	if cfg.ScrapeProxy != "" {
+		proxyUrl, err := url.Parse(cfg.ScrapeProxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ScrapeProxy: %v", err)
+		}
+		transport.Proxy = http.ProxyURL(proxyUrl)
+	}
+
+	s := scraper.NewScraper(&http.Client{Transport: transport}, pageUrl)
+	s.SourceName = name
+	s.UserAgent = cfg.ScrapeUserAgent
+	s.Timeout = time.Duration(cfg.ScrapeTimeout) * time.Second
+	s.Retry = scrapeRetryPolicy(cfg)
+	s.Strict = cfg.StrictParsing
+	s.DefectReports = cfg.ScrapeDefectReports
+
+	return s, nil
+}
+
+// builtinSource gives how the built-in source of the name is scraped and stored, or cross-checked, if there is one
+func builtinSource(name string) (func(cfg *Configuration, complianceStorageService compliance.Service, alert func(message string)) error, bool) {
+	if check, ok := crossChecks[name]; ok {
+		return func(cfg *Configuration, complianceStorageService compliance.Service, alert func(message string)) error {
+			return crossCheckSource(cfg, complianceStorageService, alert, name, check)
+		}, true
+	}
+
+	newSource, ok := sources[name]
+	if !ok {
+		return nil, false
+	}
+
+	return func(cfg *Configuration, complianceStorageService compliance.Service, alert func(message string)) error {
+		source, err := newSource(cfg)
+		if err != nil {
+			slog.Error("error setting up source", "source", name, "err", err)
+			return err
+		}
+
+		return scrapeSource(cfg, complianceStorageService, alert, source)
+	}, true
+}
+
+// scrapeSource scrapes a source and stores its features in the partition of the language its tables are about
+func scrapeSource(cfg *Configuration, complianceStorageService compliance.Service, alert func(message string), source scraper.Source) error {
+	conditional, isConditional := source.(scraper.ConditionalSource)
+	if isConditional {
+		pageUrl, validators := conditional.Conditional()
+
+		etag, lastModified, err := complianceStorageService.GetHttpValidators(context.Background(), pageUrl)
+		if err != nil {
+			slog.Error("error getting http validators, fetching the full page", "source", source.Name(), "url", pageUrl, "err", err)
+		}
+		*validators = scraper.Validators{ETag: etag, LastModified: lastModified}
+	}
+
+	scraped, page, err := scraper.ScrapeSourcePage(source)
+
+	if err == scraper.ErrNotModified {
+		slog.Info("unchanged since the last scrape", "source", source.Name())
+		return nil
+	} else if err != nil {
+		slog.Error("error when scraping", "source", source.Name(), "err", err)
+		return err
+	}
+
+	snapshotScrape(cfg, complianceStorageService, source.Name(), scraped, page)
+
+	if err := checkScrape(cfg, complianceStorageService, source.Name(), scraped, alert); err != nil {
+		return err
+	}
+
+	checkFingerprint(cfg, complianceStorageService, source.Name(), scraped, alert)
+	reportRowErrors(source.Name(), scraped, alert)
+
+	storeScraped(complianceStorageService, scraped)
+	draftCorrections(cfg, complianceStorageService)
+
+	//only remembered once the page is stored, so that a failed scrape is retried in full
+	if isConditional {
+		pageUrl, validators := conditional.Conditional()
+		if err := complianceStorageService.StoreHttpValidators(context.Background(), pageUrl, validators.ETag, validators.LastModified); err != nil {
+			slog.Error("error storing http validators", "source", source.Name(), "url", pageUrl, "err", err)
+		}
+	}
+
+	return nil
+}
+
+// checkFingerprint compares the structure of the scraped page to the last one seen, and alerts the maintainer if it drifted
+func checkFingerprint(cfg *Configuration, complianceStorageService compliance.Service, source string, scraped scraper.CppSupport, alert func(message string)) {
+	fingerprint := scraper.FingerprintOf(scraped)
+
+	data, err := json.Marshal(fingerprint)
+	if err != nil {
+		slog.Error("error encoding fingerprint", "source", source, "err", err)
+		return
+	}
+
+	lastData, err := complianceStorageService.GetLastFingerprint(context.Background(), source)
+	if err != nil {
+		slog.Error("error getting last fingerprint", "source", source, "err", err)
+		return
+	}
+
+	if lastData == string(data) {
+		return
+	}
+
+	if lastData != "" {
+		var last scraper.Fingerprint
+		if err := json.Unmarshal([]byte(lastData), &last); err != nil {
+			slog.Error("error decoding last fingerprint", "source", source, "err", err)
+		} else if drift := scraper.Drift(last, fingerprint, cfg.FingerprintMaxRowChange); len(drift) > 0 {
+			slog.Warn("structure drifted", "source", source, "drift", drift)
+			alert(fmt.Sprintf("Hello! The structure of %v changed, the parser likely needs updating:\n- %v", source, strings.Join(drift, "\n- ")))
+		}
+	}
+
+	if err := complianceStorageService.StoreFingerprint(context.Background(), source, string(data)); err != nil {
+		slog.Error("error storing fingerprint", "source", source, "err", err)
+	}
+}
+
+// last row error report sent per source, so the maintainer hears about the same bad rows only once
+var sentRowErrorReports = struct {
+	sync.Mutex
+	reports map[string]string
+}{reports: map[string]string{}}
+
+// reportRowErrors alerts the maintainer about rows the strict parser skipped, unless the same rows were reported last time
+func reportRowErrors(source string, scraped scraper.CppSupport, alert func(message string)) {
+	report := scraper.ErrorReport(scraped.Errors)
+
+	sentRowErrorReports.Lock()
+	alreadySent := sentRowErrorReports.reports[source] == report
+	sentRowErrorReports.reports[source] = report
+	sentRowErrorReports.Unlock()
+
+	if report == "" || alreadySent {
+		return
+	}
+
+	slog.Warn("skipped rows", "source", source, "rows", len(scraped.Errors), "errors", report)
+	alert(fmt.Sprintf("Hello! %v rows of %v could not be parsed and were skipped:\n%v", len(scraped.Errors), source, report))
+}
+
+// minimum NameSimilarity for a gone and an appeared feature sharing a paper with others to be taken as a rename
+const renameMinSimilarity = 0.5
+
+// detectRenames finds stored features that are gone from the page while a new feature with the same paper, by number
+// or link, appeared in the same C++ version. it maps the new names to the latest entry of the feature they replace, so
+// the new name keeps its slug and history. if several features of a paper changed at once, they are paired up by name
+func detectRenames(stored []compliance.Feature, scraped []compliance.Feature) map[string]*compliance.Feature {
+	scrapedNames := map[string]bool{}
+	for _, feature := range scraped {
+		scrapedNames[feature.Name] = true
+	}
+
+	storedNames := map[string]bool{}
+	for _, feature := range stored {
+		storedNames[feature.Name] = true
+	}
+
+	renameKey := func(feature *compliance.Feature) string {
+		paper := compliance.PaperNumber(feature)
+		if paper == "" {
+			return ""
+		}
+		return fmt.Sprintf("%v/%v", feature.CppVersion, paper)
+	}
+
+	gone := map[string][]*compliance.Feature{}
+	for i := range stored {
+		if key := renameKey(&stored[i]); key != "" && !scrapedNames[stored[i].Name] {
+			gone[key] = append(gone[key], &stored[i])
+		}
+	}
+
+	appeared := map[string][]*compliance.Feature{}
+	for i := range scraped {
+		if key := renameKey(&scraped[i]); key != "" && !storedNames[scraped[i].Name] {
+			appeared[key] = append(appeared[key], &scraped[i])
+		}
+	}
+
+	result := map[string]*compliance.Feature{}
+	for key, features := range appeared {
+		candidates := gone[key]
+
+		if len(features) == 1 && len(candidates) == 1 {
+			result[features[0].Name] = candidates[0]
+			continue
+		}
+
+		//the most alike pair is taken first, until no pair is alike enough
+		taken := map[*compliance.Feature]bool{}
+		for {
+			var bestFeature, bestCandidate *compliance.Feature
+			best := renameMinSimilarity
+
+			for _, feature := range features {
+				if taken[feature] {
+					continue
+				}
+				for _, candidate := range candidates {
+					if taken[candidate] {
+						continue
+					}
+					if similarity := compliance.NameSimilarity(feature.Name, candidate.Name); similarity >= best {
+						best, bestFeature, bestCandidate = similarity, feature, candidate
+					}
+				}
+			}
+
+			if bestFeature == nil {
+				break
+			}
+
+			taken[bestFeature], taken[bestCandidate] = true, true
+			result[bestFeature.Name] = bestCandidate
+		}
+	}
+
+	return result
+}
+
+// storeScraped creates a new entry for every scraped feature that differs from what is stored
+func storeScraped(complianceStorageService compliance.Service, scraped scraper.CppSupport) {
+	byLanguage := map[string][]compliance.Feature{}
+	var languages []string
+	for _, cppVersion := range scraped.Versions {
+		if _, ok := byLanguage[cppVersion.Language]; !ok {
+			languages = append(languages, cppVersion.Language)
+		}
+		for _, feature := range cppVersion.Features {
+			byLanguage[cppVersion.Language] = append(byLanguage[cppVersion.Language], featureFromScraped(&cppVersion, &feature))
+		}
+	}
+
+	stored, storedErr := complianceStorageService.GetLatestEntries(context.Background())
+	if storedErr != nil {
+		slog.Error("error getting latest entries, renamed features will get new slugs and removals are not detected", "err", storedErr)
+	}
+
+	//every language is its own partition, renames and removals are only looked for among the features of the same one
+	for _, language := range languages {
+		storeLanguage(complianceStorageService, compliance.FeaturesOfLanguage(stored, language), storedErr == nil && len(scraped.Errors) == 0, byLanguage[language])
+	}
+}
+
+// draftCorrections drafts a correction note into the CorrectionOutbox for every support cell of cppreference that looks
+// wrong, and removes the drafts of those that were corrected since
+func draftCorrections(cfg *Configuration, complianceStorageService compliance.Service) {
+	if cfg.CorrectionOutbox == "" {
+		return
+	}
+
+	features, err := complianceStorageService.GetLatestEntries(context.Background())
+	if err != nil {
+		slog.Error("error getting latest entries, not drafting corrections", "err", err)
+		return
+	}
+
+	pages := map[string]string{
+		compliance.LanguageCpp:   supportPage(cfg, compliance.LanguageCpp),
+		compliance.LanguageC:     supportPage(cfg, compliance.LanguageC),
+		compliance.LanguageCppDR: supportPage(cfg, compliance.LanguageCppDR),
+	}
+	discrepancies := compliance.FindDiscrepancies(features, pages)
+
+	written, removed, err := compliance.WriteCorrections(cfg.CorrectionOutbox, discrepancies)
+	if err != nil {
+		slog.Error("error drafting corrections", "outbox", cfg.CorrectionOutbox, "err", err)
+	}
+	if written > 0 || removed > 0 {
+		slog.Info("drafted corrections of cppreference", "outbox", cfg.CorrectionOutbox, "written", written, "removed", removed, "total", len(discrepancies))
+	}
+}
+
+// storeLanguage stores the scraped features of a language, given the latest stored entries of that language. removals
+// are only detected if complete is set, which means the whole page was read and the stored features are known
+func storeLanguage(complianceStorageService compliance.Service, stored []compliance.Feature, complete bool, dbFeatures []compliance.Feature) {
+	renames := detectRenames(stored, dbFeatures)
+
+	for i := range dbFeatures {
+		dbFeature := &dbFeatures[i]
+
+		differs, lastEntry, err := complianceStorageService.GetLastIfDiffers(context.Background(), dbFeature)
+
+		if err != nil {
+			slog.Error("Error getting last differing, skipping entry", "feature", dbFeature.Name, "err", err)
+			continue
+		}
+
+		if differs && lastEntry == nil { //there was no prior entry, so add the first one
+			renamed, isRename := renames[dbFeature.Name]
+			if isRename {
+				slog.Info("creating new entry in database, keeping the slug as it looks like it was renamed", "feature", dbFeature.Name, "slug", renamed.Slug, "previousName", renamed.Name)
+				dbFeature.Slug = renamed.Slug
+
+				//stored before the entry, so the reporter never sees the entry without it and reports a new listing
+				if err := complianceStorageService.StoreFeatureAlias(context.Background(), renamed.Name, dbFeature.Name, compliance.AliasDetected); err != nil {
+					slog.Error("error storing alias", "feature", dbFeature.Name, "alias", renamed.Name, "err", err)
+				}
+			} else {
+				slog.Info("creating new entry in database because there is no previous one", "feature", dbFeature.Name)
+			}
+
+			err = complianceStorageService.CreateEntry(context.Background(), dbFeature)
+
+			if err != nil {
+				slog.Error("error creating entry", "feature", dbFeature.Name, "err", err)
+			}
+		} else if differs {
+			slog.Info("creating new entry in database because the old one is different", "feature", dbFeature.Name)
+
+			err = complianceStorageService.CreateEntry(context.Background(), dbFeature)
+
+			if err != nil {
+				slog.Error("error creating entry", "feature", dbFeature.Name, "err", err)
+			}
+		} else {
+			//log.Printf("nothing to be done")
+		}
+	}
+
+	//a feature can only be told gone if the whole page was read and the feature was stored before
+	if !complete {
+		return
+	}
+
+	//the old names of renamed features are long gone, they are no removals
+	for _, removed := range removedFeatures(compliance.CurrentFeatures(stored), dbFeatures, renames) {
+		slog.Info("creating removal entry in database because it is no longer listed", "feature", removed.Name)
+
+		removed.Removed = true
+		if err := complianceStorageService.CreateEntry(context.Background(), &removed); err != nil {
+			slog.Error("error creating entry", "feature", removed.Name, "err", err)
+		}
+	}
+}
+
+// removedFeatures finds the stored features missing from the scraped page that weren't renamed. only tables that were
+// scraped count, so a table the page failed to deliver doesn't remove all of its features
+func removedFeatures(stored []compliance.Feature, scraped []compliance.Feature, renames map[string]*compliance.Feature) (result []compliance.Feature) {
+	sectionKey := func(feature *compliance.Feature) string {
+		return fmt.Sprintf("%v/%v", feature.CppVersion, feature.Kind())
+	}
+
+	scrapedNames := map[string]bool{}
+	scrapedSections := map[string]bool{}
+	for i := range scraped {
+		scrapedNames[scraped[i].Name] = true
+		scrapedSections[sectionKey(&scraped[i])] = true
+	}
+
+	renamedNames := map[string]bool{}
+	for _, renamed := range renames {
+		renamedNames[renamed.Name] = true
+	}
+
+	for i := range stored {
+		feature := &stored[i]
+		if !scrapedNames[feature.Name] && !renamedNames[feature.Name] && scrapedSections[sectionKey(feature)] {
+			result = append(result, *feature)
+		}
+	}
+
+	return
+}
+
+// openSqliteService migrates the configured database and creates a storage service on it
+func openSqliteService(cfg *Configuration) (*compliance.SqliteService, error) {
+	//database migration
+	if err := util.SqliteMigrateUp(cfg.Database, cfg.MigrateDir); err != nil {
+		return nil, err
+	}
+
+	//create database instance that services will use
+	db, err := util.SqliteConnect(cfg.Database)
+	if err != nil {
+		return nil, err
+	}
+
+	sqliteService := compliance.NewSqliteService(db)
+	sqliteService.FullHistory = cfg.FullHistory
+
+	if err := sqliteService.BackfillSlugs(context.Background()); err != nil {
+		return nil, err
+	}
+
+	return sqliteService, nil
+}
+
+// serveUntilQuit serves the handler on the address in the background, until quitChan is closed
+func serveUntilQuit(name string, address string, handler http.Handler, quitChan chan struct{}) {
+	server := &http.Server{
+		Addr:    address,
+		Handler: handler,
+	}
+
+	go func() {
+		slog.Info("serving", "server", name, "address", address)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			slog.Error("error serving", "server", name, "err", err)
+		}
+	}()
+
+	go func() {
+		<-quitChan
+		slog.Info("stopping", "server", name)
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		server.Shutdown(ctx)
+		cancel()
+	}()
+}
+
+// loadReportOptions loads the texts the config rewords the reports of every channel with
+func loadReportOptions(cfg *Configuration) (compliance.ReportOptions, error) {
+	texts, err := compliance.LoadTexts(cfg.ReportTextDir)
+	if err != nil {
+		return compliance.ReportOptions{}, err
+	}
+
+	return compliance.ReportOptions{Texts: texts, ChangesOnly: cfg.ReportChangesOnly}, nil
+}
+
+// renderTwitterReport renders the change with a variant picked among the configured ones, as a thread of tweets. it
+// is empty for changes not worth reporting
+func renderTwitterReport(cfg *Configuration, reportOptions compliance.ReportOptions, reportTemplates *compliance.ReportTemplates, reportHooks *compliance.Hooks, linkShortener shortener.Shortener, change compliance.Change) (thread []string, variant string, link string, shortLink string) {
+	link, shortLink = reportLink(cfg, reportOptions, linkShortener, change)
+	postedLink := link
+	if shortLink != "" {
+		postedLink = shortLink
+	}
+
+	reportText, variant, err := reportTemplates.Render(reportOptions, change, templateLink(cfg, postedLink))
+	if err != nil {
+		slog.Warn("using the default phrasing", "feature", change.Feature.Name, "err", err)
+		reportText, variant = compliance.DefaultReportText(reportOptions, change, templateLink(cfg, postedLink)), compliance.DefaultVariant
+	}
+	reportText = reportFooter(cfg, change, reportHooks.Text(reportOptions, change, templateLink(cfg, postedLink), reportText), postedLink)
+
+	//reports too long for a tweet become a thread instead of being trimmed
+	if reportText != "" {
+		thread = limits.Twitter.Split(reportText)
+	}
+
+	return thread, variant, link, shortLink
+}
+
+// reportThread is the last report posted of a feature or paper while reporting the entries of a tick
+type reportThread struct {
+	entryID     int64
+	lastTweetID int64 //zero on dry runs
+}
+
+// reportThreadKey gives what reports are threaded by: the paper of the feature without its revision, so the C and C++
+// rows and the revisions of a paper end up together, or the feature itself if it has no paper
+func reportThreadKey(feature *compliance.Feature) string {
+	if paper := compliance.PaperNumber(feature); paper != "" {
+		return paper
+	}
+
+	return feature.Slug
+}
+
+// reportedChange leaves out what the config mutes from a change about to be reported
+func reportedChange(cfg *Configuration, change compliance.Change) compliance.Change {
+	if len(cfg.ReportCompilers) > 0 {
+		change = change.OnlyCompilers(cfg.ReportCompilers)
+	}
+	if len(cfg.ReportLanguages) > 0 {
+		change = change.OnlyLanguages(cfg.ReportLanguages)
+	}
+	if len(cfg.ReportRules) > 0 {
+		var err error
+		if change, err = compliance.ApplyReportRules(cfg.ReportRules, change); err != nil {
+			slog.Error("error applying the report rules, reporting the change as if there were none", "feature", change.Feature.Name, "err", err)
+		}
+	}
+
+	return change
+}
+
 func rootCmdFunc(cmd *cobra.Command, args []string) error {
+	started := time.Now()
 
 	cfg := &Configuration{}
 
-	if err := viper.Unmarshal(cfg); err != nil {
+	if err := viper.Unmarshal(cfg); err != nil {
+		return err
+	}
+
+	if err := validateConfig(cfg); err != nil {
+		return err
+	}
+
+	experimental, err := parseExperimentalFeatures(cfg)
+	if err != nil {
 		return err
 	}
+	experimental.LogStatus()
+	slog.Info("starting", "mode", cfg.Mode)
 
 	//services
 	var complianceStorageService compliance.Service
+	var sqliteService *compliance.SqliteService
 
 	//initialise services
 	switch cfg.StorageMode {
 	case "sqlite3":
-		//database migration
-		if err := util.SqliteMigrateUp(cfg.Database, cfg.MigrateDir); err != nil {
-			return err
-		}
-
-		//create database instance that services will use
-		db, err := util.SqliteConnect(cfg.Database)
+		sqliteService, err = openSqliteService(cfg)
 		if err != nil {
 			return err
 		}
 
-		complianceStorageService = compliance.NewSqliteService(db)
+		complianceStorageService = sqliteService
 	case "dummy":
-		//complianceStorageService = dog.NewDummySerbice(db)
+		//everything is kept in memory and forgotten on exit, so the first scrape lists every feature as new
+		complianceStorageService = compliance.NewMemoryService()
 	default:
 		return fmt.Errorf("Invalid storageMode: %s", cfg.StorageMode)
 	}
+
+	complianceStorageService, metrics, err := wrapStorage(cfg, complianceStorageService)
+	if err != nil {
+		return err
+	}
+
+	complianceStorageService, err = queueReports(cfg, complianceStorageService)
+	if err != nil {
+		return err
+	}
 	defer func() {
 		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 		complianceStorageService.Close(ctx)
 		cancel()
+
+		if metrics != nil {
+			slog.Info(fmt.Sprintf("storage metrics:\n%v", metrics))
+		}
 	}()
 
 	//set up twitter client
@@ -94,260 +997,487 @@ func rootCmdFunc(cmd *cobra.Command, args []string) error {
 	// Twitter client
 	client := twitter.NewClient(httpClient)
 
+	reportTemplates, err := compliance.NewReportTemplates(cfg.ReportVariants)
+	if err != nil {
+		return err
+	}
+
+	reportHooks, err := compliance.LoadHooks(cfg.ReportHooks)
+	if err != nil {
+		return err
+	}
+
+	reportOptions, err := loadReportOptions(cfg)
+	if err != nil {
+		return err
+	}
+
+	milestoneTemplates, err := compliance.NewMilestoneTemplates(cfg.MilestoneTemplates)
+	if err != nil {
+		return err
+	}
+
+	linkShortener, err := shortener.New(cfg.LinkShortener, cfg.LinkShortenerUrl, cfg.LinkShortenerToken)
+	if err != nil {
+		return err
+	}
+
+	changeReporters, err := newReporters(cfg, reportOptions)
+	if err != nil {
+		return err
+	}
+	slog.Info("reporters set up", "compiled", strings.Join(compiledReporters(), ", "), "reporting", len(changeReporters))
+
 	//signal that's used to signal quit
 	quitChan := make(chan struct{})
 
-	//launch ticker that polls website
-	webFetcherTicker := time.NewTicker(time.Duration(cfg.WebScrapeInterval) * time.Second)
-	go func() {
-		log.Printf("starting web fetcher ticker with %v seconds interval", cfg.WebScrapeInterval)
-		for {
-			select {
-			case <-webFetcherTicker.C:
-				scraped, err := scraper.ScrapeCppSupport()
-
-				if err != nil {
-					log.Printf("error when scraping cpp support data: %v\n", err)
-				} else {
-					for _, cppVersion := range scraped.Versions {
-						for _, feature := range cppVersion.Features {
-
-							dbFeature := compliance.Feature{
-								Name:             feature.Name,
-								CppVersion:       cppVersion.Version,
-								PaperName:        sql.NullString{feature.PaperName, true},
-								PaperLink:        sql.NullString{feature.PaperLink, true},
-								GccSupport:       feature.GccSupport.Support,
-								GccDisplayText:   sql.NullString{feature.GccSupport.DisplayString, true},
-								GccExtraText:     sql.NullString{feature.GccSupport.ExtraString, true},
-								ClangSupport:     feature.ClangSupport.Support,
-								ClangDisplayText: sql.NullString{feature.ClangSupport.DisplayString, true},
-								ClangExtraText:   sql.NullString{feature.ClangSupport.ExtraString, true},
-								MsvcSupport:      feature.MsvcSupport.Support,
-								MsvcDisplayText:  sql.NullString{feature.MsvcSupport.DisplayString, true},
-								MsvcExtraText:    sql.NullString{feature.MsvcSupport.ExtraString, true},
-							}
-
-							differs, lastEntry, err := complianceStorageService.GetLastIfDiffers(context.Background(), &dbFeature)
-
-							if err != nil {
-								log.Printf("Error getting last differing for feature '%v', skipping entry: %v\n", feature.Name, err)
-								continue
-							}
-
-							if differs && lastEntry == nil { //there was no prior entry, so add the first one
-								log.Printf("creating new entry of feature '%v' in database because there is no previous one", feature.Name)
-
-								err = complianceStorageService.CreateEntry(context.Background(), &dbFeature)
-
-								if err != nil {
-									log.Printf("error creating entry: %v", err)
-								}
-							} else if differs {
-								log.Printf("creating new entry of feature '%v' in database because the old one is different", feature.Name)
-
-								err = complianceStorageService.CreateEntry(context.Background(), &dbFeature)
-
-								if err != nil {
-									log.Printf("error creating entry: %v", err)
-								}
-							} else {
-								//log.Printf("nothing to be done")
-							}
-						}
-					}
-				}
-			case <-quitChan:
-				log.Println("stopping web fetcher ticker")
-				webFetcherTicker.Stop()
+	maintainer, err := newNotifier(cfg, client)
+	if err != nil {
+		return err
+	}
+	slog.Info("maintainer notifiers set up", "notifiers", strings.Join(maintainerNotifiers(cfg), ", "))
+
+	//maintainer alerts raised while scraping
+	alert := func(message string) {
+		if err := maintainer.Notify(message); err != nil {
+			slog.Error("did not manage to alert the maintainer", "err", err)
+		}
+		changeReporters.Alert(message)
+	}
+
+	//third party reporters and sources
+	startedPlugins, err := startPlugins(cfg)
+	if err != nil {
+		return err
+	}
+	defer stopPlugins(startedPlugins)
+
+	//schedule scraping of all configured sources
+	scrapeScheduler := schedule.New()
+	scrapes := newScrapeHealth(started)
+	var scrapeJobs []schedule.Job
+	sources := sourceConfigs(cfg)
+	if !modeScrapes(cfg) {
+		sources = nil
+	}
+	for _, source := range sources {
+		scrape, ok := builtinSource(source.Name)
+		if !ok {
+			scrape, ok = pluginSource(startedPlugins, source.Name)
+		}
+		if !ok {
+			return fmt.Errorf("unknown source in config: %s", source.Name)
+		}
+
+		storageService := complianceStorageService
+		sourceName := source.Name
+		pausedUntil := time.Time{}
+
+		job, err := scheduleJob("scrape "+source.Name, source.Interval, source.Jitter, source.Align, source.Schedule)
+		if err != nil {
+			return err
+		}
+		job.Once = source.Once
+		scrapes.add(source.Name, job)
+		job.Run = func() {
+			if time.Now().Before(pausedUntil) {
+				slog.Info("skipping scrape, paused for maintenance", "source", sourceName, "until", pausedUntil.Format(time.RFC3339))
 				return
 			}
+
+			err := scrape(cfg, storageService, alert)
+			recordScrape(cfg, storageService, sourceName, err == nil)
+			scrapes.record(sourceName, err, time.Now())
+
+			if scraper.IsMaintenance(err) {
+				pausedUntil = time.Now().Add(time.Duration(cfg.MaintenancePause) * time.Second)
+				slog.Warn("pausing scrapes for maintenance", "source", sourceName, "until", pausedUntil.Format(time.RFC3339))
+			} else if err != nil {
+				//too frequent for direct messages, so failed scrapes only go to Slack
+				changeReporters.Alert(fmt.Sprintf("cppimpbot: scraping %v failed: %v", sourceName, err))
+			}
 		}
-	}()
+		scrapeScheduler.Add(job)
+		scrapeJobs = append(scrapeJobs, job)
+	}
 
-	//launch ticker that posts reports as tweets
-	tweetReporterTicker := time.NewTicker(time.Duration(cfg.TwitterReportInterval) * time.Second)
-	go func() {
-		log.Printf("starting tweet reporter ticker with %v seconds interval", cfg.TwitterReportInterval)
-		for {
-			select {
-			case <-tweetReporterTicker.C:
+	//watch compiler release feeds for roundups of what a new version supports
+	feedJobs, err := releaseFeedJobs(cfg)
+	if err != nil {
+		return fmt.Errorf("invalid VersionPattern of a release feed: %v", err)
+	}
+	for _, job := range feedJobs {
+		if !modeReports(cfg) {
+			break
+		}
+		feedJob := job
 
-				unreportedEntries, err := complianceStorageService.GetNotTwitterReported(context.Background())
+		if err := addJob(scrapeScheduler, "release feed "+job.feed.Compiler, cfg.ReleasePollInterval, func() {
+			checkReleaseFeed(cfg, client, complianceStorageService, feedJob)
+		}); err != nil {
+			return err
+		}
+	}
 
-				if err != nil {
-					log.Printf("error getting entries not reported to twitter: %v\n", err)
-					continue
-				}
+	//keep the database small enough for the host
+	if sqliteService != nil && modeScrapes(cfg) && (cfg.DatabaseSizeAlert > 0 || cfg.DatabaseRotateSize > 0) {
+		if err := addJob(scrapeScheduler, "database size", cfg.DatabaseCheckInterval, databaseSizeJob(cfg, sqliteService, alert)); err != nil {
+			return err
+		}
+	}
 
-				amountToReport := len(unreportedEntries)
-
-				if amountToReport > cfg.SafeModeMaxReports && cfg.SafeMode {
-					log.Printf("Found %v entries to report, this is too many for safe mode (limit is %v)... will not report\n", amountToReport, cfg.SafeModeMaxReports)
-
-					message := fmt.Sprintf("Hello! There were too many reports for safe mode (limit is %v). I won't report anything until you look into this. Amount of reports was %v", cfg.SafeModeMaxReports, amountToReport)
-					//directmessage, httpresponse, err
-					_, _, err = client.DirectMessages.EventsNew(&twitter.DirectMessageEventsNewParams{
-						Event: &twitter.DirectMessageEvent{
-							Type: "message_create",
-							Message: &twitter.DirectMessageEventMessage{
-								Target: &twitter.DirectMessageTarget{
-									RecipientID: cfg.MaintainerTwitterId,
-								},
-								Data: &twitter.DirectMessageData{
-									Text: message,
-								},
-							},
-						},
-					})
-
-					if err != nil {
-						log.Printf("did not manage to report by twitter pm that there are too many reports (%v reports). Errors was: %v\n", amountToReport, err)
-					}
+	//keep track of the service level objectives
+	if len(cfg.Objectives) > 0 && modeReports(cfg) {
+		if err := addJob(scrapeScheduler, "objectives report", cfg.ObjectiveReportInterval, objectivesReportJob(cfg, complianceStorageService, changeReporters)); err != nil {
+			return err
+		}
+		if err := addJob(scrapeScheduler, "error budgets", cfg.ObjectiveCheckInterval, errorBudgetJob(cfg, complianceStorageService, alert)); err != nil {
+			return err
+		}
+	}
+
+	//mail digests of the changes to those who prefer them over a stream of tweets
+	if digestEnabled(cfg) && modeReports(cfg) {
+		if err := addJob(scrapeScheduler, "email digest", cfg.DigestCheckInterval, digestJob(cfg, complianceStorageService)); err != nil {
+			return err
+		}
+	}
 
-					log.Printf("stopping tweet reporter ticker\n")
+	//follow how the phrasings of the reports are received
+	if cfg.EngagementInterval > 0 && modeReports(cfg) {
+		if err := addJob(scrapeScheduler, "report engagement", cfg.EngagementInterval, engagementJob(cfg, client, complianceStorageService)); err != nil {
+			return err
+		}
+	}
 
-					return
-				}
+	//sum up the compliance progress of every year
+	if cfg.YearReview && modeReports(cfg) {
+		if err := addJob(scrapeScheduler, "year review", cfg.YearReviewInterval, yearReviewJob(cfg, client, httpClient, complianceStorageService)); err != nil {
+			return err
+		}
+	}
 
-				for _, entry := range unreportedEntries {
-					previous, err := complianceStorageService.GetPreviousFeatureEntry(context.Background(), &entry)
+	//sum up the changes of every week
+	if cfg.WeeklySummary && modeReports(cfg) {
+		job, err := scheduleJob("weekly summary", 0, 0, "", cfg.WeeklySummarySchedule)
+		if err != nil {
+			return err
+		}
+		job.Run = weeklySummaryJob(cfg, client, httpClient, complianceStorageService, changeReporters)
+		scrapeScheduler.Add(job)
+	}
 
-					if err != nil {
-						log.Printf("error when getting previous feature entry: %v\n", err)
-						continue
-					}
+	//record how much of every standard each compiler supports, and tweet the milestones
+	if cfg.AggregateInterval > 0 && modeReports(cfg) {
+		if err := addJob(scrapeScheduler, "aggregates", cfg.AggregateInterval, aggregateJob(cfg, milestoneTemplates, client, complianceStorageService)); err != nil {
+			return err
+		}
+	}
 
-					twitterReport, err := compliance.FeatureToTwitterReport(previous, &entry)
-
-					if err != nil {
-						log.Printf("not capable of turning update into report. will try to report this as private tweet: %v\n", err)
-						if entry.ReportedBroken {
-							log.Printf("this error is already reported, skip entry\n")
-							continue
-						}
-
-						message := fmt.Sprintf("Hello! There was an issue with a change on cppreference that I don't know how to turn into a report.\nThe involved entries are '%v' '%v' and '%v' '%v'. \nFull expansion of those:\n\n%v\n\n%v", previous.Name, previous.Timestamp, entry.Name, entry.Timestamp, previous, entry)
-						//directmessage, httpresponse, err
-						_, _, err = client.DirectMessages.EventsNew(&twitter.DirectMessageEventsNewParams{
-							Event: &twitter.DirectMessageEvent{
-								Type: "message_create",
-								Message: &twitter.DirectMessageEventMessage{
-									Target: &twitter.DirectMessageTarget{
-										RecipientID: cfg.MaintainerTwitterId,
-									},
-									Data: &twitter.DirectMessageData{
-										Text: message,
-									},
-								},
-							},
-						})
-
-						if err != nil {
-							log.Printf("did not manage to report by twitter pm that I couldn't report to twitter: %v\n", err)
-						} else {
-							log.Printf("error report sent.\n")
-							complianceStorageService.SetErrorReported(context.Background(), &entry)
-						}
-						continue
-					}
+	//post what was missed while the bot was down as one thread before reporting changes one by one again. the thread
+	//can't be approved, so the changes go through approval one by one instead if reports need it
+	if cfg.CatchUpAfter > 0 && !cfg.ReportApproval && modeReports(cfg) {
+		if err := catchUp(cfg, reportOptions, client, complianceStorageService, reportHooks, alert); err != nil {
+			slog.Error("error catching up, reporting the missed changes one by one", "channel", compliance.ChannelTwitter, "err", err)
+		}
+	}
+
+	//launch ticker that posts reports as tweets
+	reportJob, err := scheduleJob("report", cfg.TwitterReportInterval, cfg.TwitterReportJitter, cfg.TwitterReportAlign, cfg.TwitterReportSchedule)
+	if err != nil {
+		return err
+	}
+	reportPace, err := newReportPace(cfg, complianceStorageService)
+	if err != nil {
+		return err
+	}
+	reporter := &twitterReporter{
+		cfg:             cfg,
+		client:          client,
+		httpClient:      httpClient,
+		service:         complianceStorageService,
+		options:         reportOptions,
+		templates:       reportTemplates,
+		hooks:           reportHooks,
+		linkShortener:   linkShortener,
+		channels:        changeReporters,
+		startedPlugins:  startedPlugins,
+		notifier:        maintainer,
+		alert:           alert,
+		pace:            reportPace,
+		scrapeScheduler: scrapeScheduler,
+	}
 
-					if !cfg.SupressReporting {
-						messagePrefix := "Dry run: "
-						//tweet, resp, err
-						if !cfg.DryReporting && twitterReport != "" { //do not post if we do dry run or message is empty
-							_, _, err = client.Statuses.Update(twitterReport, nil)
-							messagePrefix = ""
-						}
-
-						if twitterReport != "" {
-							log.Printf(messagePrefix+"posting tweet: %v\n", twitterReport)
-						} else {
-							log.Printf(messagePrefix + "found change that I don't care about. setting as reported.\n")
-						}
-
-						if err != nil {
-							log.Printf("error posting tweet update: %v\n", err)
-							continue
-						} else {
-							if !cfg.DryReporting {
-								complianceStorageService.SetTwitterReported(context.Background(), &entry)
-							}
-						}
-					} else {
-						log.Printf("got twitter report which will be supressed: %v\n", twitterReport)
-						complianceStorageService.SetTwitterReported(context.Background(), &entry)
+	if !modeReports(cfg) {
+		reporter = nil
+	}
+
+	//a failed scrape or report isn't a usage error, the exit code tells what went wrong
+	if runOnce {
+		cmd.SilenceUsage = true
+		return runOnceCycle(scrapeJobs, scrapes, reporter)
+	}
+
+	scrapeScheduler.Start()
+	defer scrapeScheduler.Stop()
+
+	//cancelled on quit. the reporter and the mention responders are waited for, so that what they posted is recorded
+	//before the bot exits or restarts
+	ctx, cancel := context.WithCancel(context.Background())
+	var running sync.WaitGroup
+
+	if reporter != nil {
+		tweetReporterTicker := time.NewTimer(reportJob.NextDelay(time.Now()))
+		running.Add(1)
+		go func() {
+			defer running.Done()
+			slog.Info("starting tweet reporter ticker", "channel", compliance.ChannelTwitter, "interval", cfg.TwitterReportInterval,
+				"jitter", cfg.TwitterReportJitter, "align", cfg.TwitterReportAlign, "schedule", cfg.TwitterReportSchedule)
+			for {
+				select {
+				case <-tweetReporterTicker.C:
+					//rescheduled right away, so reporting taking long doesn't push back the next tick
+					tweetReporterTicker.Reset(reportJob.NextDelay(time.Now()))
+
+					if reporter.run(ctx).stop {
+						slog.Info("stopping tweet reporter ticker", "channel", compliance.ChannelTwitter)
+						return
 					}
+				case <-quitChan:
+					slog.Info("stopping tweet reporter ticker", "channel", compliance.ChannelTwitter)
+					tweetReporterTicker.Stop()
+					return
 				}
-				break
-			case <-quitChan:
-				log.Println("stopping tweet reporter ticker")
-				tweetReporterTicker.Stop()
+			}
+		}()
+	}
+
+	//launch tickers that answer mentions asking about features, on twitter and on bluesky if an account is set up. a
+	//user gets MentionUserLimit replies on each
+	mentionLimiter := mentions.NewUserLimiter(cfg.MentionUserLimit, time.Duration(cfg.MentionUserWindow)*time.Second)
+	if cfg.MentionReplies && modeReports(cfg) && cfg.BlueskyHandle != "" {
+		blueskyClient := bluesky.NewClient(cfg.BlueskyHost, cfg.BlueskyHandle, cfg.BlueskyAppPassword)
+		startBlueskyMentions(ctx, cfg, reportOptions, blueskyClient, complianceStorageService, mentionLimiter, &running)
+	}
+	if cfg.MentionReplies && modeReports(cfg) {
+		mentionTicker := time.NewTicker(time.Duration(cfg.MentionPollInterval) * time.Second)
+
+		running.Add(1)
+		go func() {
+			defer running.Done()
+			slog.Info("starting mention responder ticker", "interval", cfg.MentionPollInterval)
+
+			//only answer mentions arriving after startup
+			var sinceID int64
+			latest, _, err := client.Timelines.MentionTimeline(&twitter.MentionTimelineParams{Count: 1})
+			if err != nil {
+				slog.Error("error getting latest mention, will not answer mentions", "err", err)
+				mentionTicker.Stop()
 				return
 			}
+			if len(latest) > 0 {
+				sinceID = latest[0].ID
+			}
+
+			for {
+				select {
+				case <-mentionTicker.C:
+					sinceID = respondToMentions(ctx, cfg, reportOptions, client, complianceStorageService, mentionLimiter, sinceID)
+				case <-quitChan:
+					slog.Info("stopping mention responder ticker")
+					mentionTicker.Stop()
+					return
+				}
+			}
+		}()
+	}
+
+	//serve the REST API and the dashboard
+	if cfg.ApiAddress != "" {
+		serveUntilQuit("REST API", cfg.ApiAddress, api.NewServer(complianceStorageService), quitChan)
+	}
+	if cfg.DashboardAddress != "" {
+		dashboardServer := dashboard.NewServer(complianceStorageService)
+		if cfg.Suggestions {
+			dashboardServer.AcceptSuggestions(mentions.NewUserLimiter(cfg.SuggestionLimit, time.Duration(cfg.SuggestionWindow)*time.Second))
 		}
-	}()
+		if cfg.CorrectionOutbox != "" {
+			dashboardServer.ShowCorrections(cfg.CorrectionOutbox)
+		}
+		serveUntilQuit("dashboard", cfg.DashboardAddress, dashboardServer, quitChan)
+	}
+	if cfg.HealthAddress != "" {
+		serveUntilQuit("health checks", cfg.HealthAddress, health.NewServer(healthChecks(cfg, complianceStorageService, started, scrapes, startedPlugins)), quitChan)
+	}
 
 	//pause here until quit yo
-	ctrlCChan := make(chan os.Signal, 1)
-	signal.Notify(ctrlCChan, os.Interrupt)
-	go func() {
-		for _ = range ctrlCChan {
-			log.Println("will shut down...")
-			close(quitChan)
-		}
-	}()
+	go waitForQuit(quitChan, scrapeScheduler, func() {
+		dumpState(started, complianceStorageService, scrapeScheduler, startedPlugins, metrics)
+	})
 
 	<-quitChan
 
+	cancel()
+	slog.Info("waiting for the reporter and the mention responders to finish...")
+	running.Wait()
+
 	return nil
 }
 
+// mentionReply answers the query of a mention with the support of the first feature found, starting with prefix
+func mentionReply(reportOptions compliance.ReportOptions, service compliance.Service, query mentions.Query, prefix string) (string, error) {
+	var found []compliance.Feature
+	var err error
+	if query.IsPaper {
+		found, err = service.SearchLatestEntries(context.Background(), query.Text, "")
+	} else {
+		found, err = service.SearchLatestEntries(context.Background(), "", query.Text)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if len(found) == 0 {
+		return fmt.Sprintf("%vSorry, I don't know of any feature matching \"%v\".", prefix, query.Text), nil
+	}
+
+	return compliance.FeatureToTwitterMatrix(reportOptions, &found[0], prefix), nil
+}
+
+// respondToMentions replies to the mentions newer than sinceID, and gives back the id of the newest one seen. once ctx
+// is done the rest are left to the next poll
+func respondToMentions(ctx context.Context, cfg *Configuration, reportOptions compliance.ReportOptions, client *twitter.Client, complianceStorageService compliance.Service, limiter *mentions.UserLimiter, sinceID int64) int64 {
+	params := &twitter.MentionTimelineParams{Count: 200}
+	if sinceID != 0 {
+		params.SinceID = sinceID
+	}
+
+	tweets, _, err := client.Timelines.MentionTimeline(params)
+	if err != nil {
+		slog.Error("error getting mentions", "err", err)
+		return sinceID
+	}
+
+	replies := 0
+
+	//the timeline is newest first, answer in the order they were asked
+	for i := len(tweets) - 1; i >= 0 && ctx.Err() == nil; i-- {
+		tweet := tweets[i]
+		if tweet.ID > sinceID {
+			sinceID = tweet.ID
+		}
+
+		if tweet.User == nil || tweet.RetweetedStatus != nil {
+			continue
+		}
+
+		query, ok := mentions.ParseQuery(tweet.Text)
+		if !ok {
+			slog.Info("ignoring mention", "user", tweet.User.ScreenName, "tweet", tweet.Text)
+			continue
+		}
+
+		if replies >= cfg.MentionMaxReplies {
+			slog.Warn("reached reply limit for this poll, ignoring mention", "user", tweet.User.ScreenName)
+			continue
+		}
+
+		if !limiter.Allow(compliance.ChannelTwitter+":"+tweet.User.IDStr, time.Now()) {
+			slog.Warn("user is rate limited, ignoring mention", "user", tweet.User.ScreenName)
+			continue
+		}
+
+		reply, err := mentionReply(reportOptions, complianceStorageService, query, "@"+tweet.User.ScreenName+" ")
+		if err != nil {
+			slog.Error("error searching features for mention", "query", query.Text, "err", err)
+			continue
+		}
+
+		replies++
+
+		if cfg.DryReporting {
+			slog.Info("Dry run: replying to mention", "user", tweet.User.ScreenName, "reply", reply)
+			continue
+		}
+
+		slog.Info("replying to mention", "user", tweet.User.ScreenName, "reply", reply)
+		if _, _, err = client.Statuses.Update(reply, &twitter.StatusUpdateParams{InReplyToStatusID: tweet.ID}); err != nil {
+			slog.Error("error replying to mention", "user", tweet.User.ScreenName, "err", err)
+		}
+	}
+
+	return sinceID
+}
+
+func exploreCmdFunc(cmd *cobra.Command, args []string) error {
+	cfg := &Configuration{}
+
+	if err := viper.Unmarshal(cfg); err != nil {
+		return err
+	}
+
+	if cfg.StorageMode != "sqlite3" {
+		return fmt.Errorf("explore needs storageMode sqlite3, not %s", cfg.StorageMode)
+	}
+
+	db, err := util.SqliteConnectReadOnly(cfg.Database)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	mux := http.NewServeMux()
+	mux.Handle("/query", explore.NewHandler(compliance.NewSqliteService(db), cfg.ExploreMaxRows))
+
+	slog.Info("serving read-only queries", "address", cfg.ExploreAddress+"/query", "maxRows", cfg.ExploreMaxRows)
+
+	return http.ListenAndServe(cfg.ExploreAddress, mux)
+}
+
 func testCmdFunc(cmd *cobra.Command, args []string) error {
+	cfg := &Configuration{}
+
+	if err := viper.Unmarshal(cfg); err != nil {
+		return err
+	}
+
+	reportOptions, err := loadReportOptions(cfg)
+	if err != nil {
+		return err
+	}
+
 	log.Print("=====Testing text reports=====\n\n")
 
 	//note: fake data
 	baseFeature := compliance.Feature{
-		Name:             "Initializer list constructors in class template argument deduction",
-		CppVersion:       20,
-		PaperName:        sql.NullString{"P0702R1", true},
-		PaperLink:        sql.NullString{"https://wg21.link/P0702R1", true},
-		GccSupport:       0,
-		GccDisplayText:   sql.NullString{"", true},
-		GccExtraText:     sql.NullString{"", true},
-		ClangSupport:     1,
-		ClangDisplayText: sql.NullString{"6 (partial)*", true},
-		ClangExtraText:   sql.NullString{"only supported if flag supplied", true},
-		MsvcSupport:      0,
-		MsvcDisplayText:  sql.NullString{"", true},
-		MsvcExtraText:    sql.NullString{"", true},
-	}
-
-	baseFeatureSupportsTwo := baseFeature
-	baseFeatureSupportsTwo.MsvcSupport = 2
-	baseFeatureSupportsTwo.MsvcDisplayText.String = "19.20"
-	baseFeatureSupportsTwo.MsvcExtraText.String = "not bug free"
-
-	newSupportFeature := baseFeature
-	newSupportFeature.GccSupport = 1
-	newSupportFeature.GccDisplayText = sql.NullString{"9*", true}
-	newSupportFeature.GccExtraText = sql.NullString{"still some bugs", true}
-
-	newSupportMultipleFeature := newSupportFeature
-	newSupportMultipleFeature.MsvcSupport = 1
-	newSupportMultipleFeature.MsvcDisplayText = sql.NullString{"19.20", true}
-	newSupportMultipleFeature.MsvcExtraText = sql.NullString{"", true}
-
-	textChangeFeature := baseFeatureSupportsTwo
-	textChangeFeature.ClangDisplayText = sql.NullString{"6", true}
-	textChangeFeature.ClangExtraText = sql.NullString{"", true}
-
-	textChangeMultipleFeature := textChangeFeature
-	textChangeMultipleFeature.MsvcDisplayText = sql.NullString{"19.20", true}
-	textChangeMultipleFeature.MsvcExtraText = sql.NullString{"one bug", true}
+		Name:       "Initializer list constructors in class template argument deduction",
+		CppVersion: 20,
+		PaperName:  sql.NullString{String: "P0702R1", Valid: true},
+		PaperLink:  sql.NullString{String: "https://wg21.link/P0702R1", Valid: true},
+	}
+	baseFeature.SetSupport(fixtureSupport(compliance.CompilerGcc, 0, "", ""))
+	baseFeature.SetSupport(fixtureSupport(compliance.CompilerClang, 1, "6 (partial)*", "only supported if flag supplied"))
+	baseFeature.SetSupport(fixtureSupport(compliance.CompilerMsvc, 0, "", ""))
+	baseFeature.SetSupport(fixtureSupport("apple_clang", 0, "", ""))
+
+	baseFeatureSupportsTwo := copyFixture(baseFeature)
+	baseFeatureSupportsTwo.SetSupport(fixtureSupport(compliance.CompilerMsvc, 2, "19.20", "not bug free"))
+
+	newSupportFeature := copyFixture(baseFeature)
+	newSupportFeature.SetSupport(fixtureSupport(compliance.CompilerGcc, 1, "9*", "still some bugs"))
+
+	newSupportMultipleFeature := copyFixture(newSupportFeature)
+	newSupportMultipleFeature.SetSupport(fixtureSupport(compliance.CompilerMsvc, 1, "19.20", ""))
+	newSupportMultipleFeature.SetSupport(fixtureSupport("apple_clang", 1, "10.0.1", ""))
+
+	textChangeFeature := copyFixture(baseFeatureSupportsTwo)
+	textChangeFeature.SetSupport(fixtureSupport(compliance.CompilerClang, 1, "6", ""))
+
+	textChangeMultipleFeature := copyFixture(textChangeFeature)
+	textChangeMultipleFeature.SetSupport(fixtureSupport(compliance.CompilerMsvc, 2, "19.20", "one bug"))
 
 	//test for when a new feature is listed
-	text, err := compliance.FeatureToTwitterReport(nil, &baseFeature)
+	text, err := compliance.FeatureToTwitterReport(reportOptions, nil, &baseFeature)
 
 	if err != nil {
 		log.Printf("Report when a new feature is added to the listing:\n Error: %v\n\n", err)
@@ -356,7 +1486,7 @@ func testCmdFunc(cmd *cobra.Command, args []string) error {
 	}
 
 	//test for when a new feature is listed with full support
-	text, err = compliance.FeatureToTwitterReport(nil, &newSupportMultipleFeature)
+	text, err = compliance.FeatureToTwitterReport(reportOptions, nil, &newSupportMultipleFeature)
 
 	if err != nil {
 		log.Printf("Report when a new feature is added to the listing with full support:\n Error: %v\n\n", err)
@@ -365,7 +1495,7 @@ func testCmdFunc(cmd *cobra.Command, args []string) error {
 	}
 
 	//test for when a feature has gained support in a compiler
-	text, err = compliance.FeatureToTwitterReport(&baseFeature, &newSupportFeature)
+	text, err = compliance.FeatureToTwitterReport(reportOptions, &baseFeature, &newSupportFeature)
 
 	if err != nil {
 		log.Printf("Report when a feature has gained compiler support:\n Error: %v\n\n", err)
@@ -374,7 +1504,7 @@ func testCmdFunc(cmd *cobra.Command, args []string) error {
 	}
 
 	//test for when a feature has gained multiple support in a compiler
-	text, err = compliance.FeatureToTwitterReport(&baseFeature, &newSupportMultipleFeature)
+	text, err = compliance.FeatureToTwitterReport(reportOptions, &baseFeature, &newSupportMultipleFeature)
 
 	if err != nil {
 		log.Printf("Report when a feature has gained multiple compiler support:\n Error: %v\n\n", err)
@@ -383,7 +1513,7 @@ func testCmdFunc(cmd *cobra.Command, args []string) error {
 	}
 
 	//test for when a feature has lost support in a compiler
-	text, err = compliance.FeatureToTwitterReport(&newSupportFeature, &baseFeature)
+	text, err = compliance.FeatureToTwitterReport(reportOptions, &newSupportFeature, &baseFeature)
 
 	if err != nil {
 		log.Printf("Report when a feature has lost compiler support:\n Error: %v\n\n", err)
@@ -392,7 +1522,7 @@ func testCmdFunc(cmd *cobra.Command, args []string) error {
 	}
 
 	//test for when a feature has lost multiple support in a compiler
-	text, err = compliance.FeatureToTwitterReport(&newSupportMultipleFeature, &baseFeature)
+	text, err = compliance.FeatureToTwitterReport(reportOptions, &newSupportMultipleFeature, &baseFeature)
 
 	if err != nil {
 		log.Printf("Report when a feature has lost multiple compiler support:\n Error: %v\n\n", err)
@@ -401,7 +1531,7 @@ func testCmdFunc(cmd *cobra.Command, args []string) error {
 	}
 
 	//test for when a feature has had its text changed
-	text, err = compliance.FeatureToTwitterReport(&baseFeatureSupportsTwo, &textChangeFeature)
+	text, err = compliance.FeatureToTwitterReport(reportOptions, &baseFeatureSupportsTwo, &textChangeFeature)
 
 	if err != nil {
 		log.Printf("Report when a feature had its text changed:\n Error: %v\n\n", err)
@@ -410,7 +1540,7 @@ func testCmdFunc(cmd *cobra.Command, args []string) error {
 	}
 
 	//test for when a feature has had mutiple texts changed
-	text, err = compliance.FeatureToTwitterReport(&baseFeatureSupportsTwo, &textChangeMultipleFeature)
+	text, err = compliance.FeatureToTwitterReport(reportOptions, &baseFeatureSupportsTwo, &textChangeMultipleFeature)
 
 	if err != nil {
 		log.Printf("Report when a feature had multiple text changed:\n Error: %v\n\n", err)
@@ -418,46 +1548,296 @@ func testCmdFunc(cmd *cobra.Command, args []string) error {
 		log.Printf("Report when a feature had multiple text changed:\n%v\n\n", text)
 	}
 
+	//test for when a feature was renamed
+	renamedFeature := copyFixture(newSupportFeature)
+	renamedFeature.Name = "Initializer list constructors in CTAD"
+
+	text, err = compliance.FeatureToTwitterReport(reportOptions, &baseFeature, &renamedFeature)
+
+	if err != nil {
+		log.Printf("Report when a feature was renamed:\n Error: %v\n\n", err)
+	} else {
+		log.Printf("Report when a feature was renamed:\n%v\n\n", text)
+	}
+
+	//test for when a feature is removed from the listing
+	removedFeature := copyFixture(newSupportFeature)
+	removedFeature.Removed = true
+
+	text, err = compliance.FeatureToTwitterReport(reportOptions, &newSupportFeature, &removedFeature)
+
+	if err != nil {
+		log.Printf("Report when a feature is removed from the listing:\n Error: %v\n\n", err)
+	} else {
+		log.Printf("Report when a feature is removed from the listing:\n%v\n\n", text)
+	}
+
+	//test for when a library feature is listed
+	libraryFeature := compliance.Feature{
+		Name:       "std::any",
+		CppVersion: 17,
+		PaperName:  sql.NullString{String: "P0220R1", Valid: true},
+		PaperLink:  sql.NullString{String: "https://wg21.link/P0220R1", Valid: true},
+	}
+	libraryFeature.SetSupport(fixtureLibrarySupport(compliance.LibraryLibstdcxx, 1, "7", ""))
+	libraryFeature.SetSupport(fixtureLibrarySupport(compliance.LibraryMsvcStl, 1, "19.10", ""))
+	libraryFeature.SetSupport(fixtureLibrarySupport("apple_libcxx", 1, "10.0.0", ""))
+
+	text, err = compliance.FeatureToTwitterReport(reportOptions, nil, &libraryFeature)
+
+	if err != nil {
+		log.Printf("Report when a new library feature is added to the listing:\n Error: %v\n\n", err)
+	} else {
+		log.Printf("Report when a new library feature is added to the listing:\n%v\n\n", text)
+	}
+
 	return nil
 }
 
-func initConfig() {
-	//viper.SetDefault("Port", "8080")
-	viper.SetDefault("DatabaseConnection", "./data.db")
-	viper.SetDefault("MigrateDir", "./migrations")
-	viper.SetDefault("StorageMode", "sqlite3")
-	viper.SetDefault("SafeMode", true)
-	viper.SetDefault("SafeModeMaxReports", 5)
-	viper.SetDefault("WebScrapeInterval", 300)
-	viper.SetDefault("TwitterReportInterval", 300)
-	viper.SetDefault("SupressReporting", false)
-	viper.SetDefault("DryReporting", true)
-
-	var cfgFile string
+// config file given with --config
+var cfgFile string
 
-	rootCommand.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is config.toml)")
+// command line flags that override a setting of the config, by the key of the setting
+var configFlags = map[string]*pflag.Flag{}
+
+// bindConfigFlag lets the flag override the setting of the config
+func bindConfigFlag(key string, flag *pflag.Flag) {
+	viper.BindPFlag(key, flag)
+	configFlags[key] = flag
+}
+
+// setConfigDefaults gives every setting that may be left out of the config its default
+func setConfigDefaults(v *viper.Viper) {
+	//v.SetDefault("Port", "8080")
+	v.SetDefault("DatabaseConnection", "./data.db")
+	v.SetDefault("MigrateDir", "./migrations")
+	v.SetDefault("DatabaseSizeAlert", 0)
+	v.SetDefault("DatabaseRotateSize", 0)
+	v.SetDefault("DatabaseArchiveDir", "./archive")
+	v.SetDefault("DatabaseArchiveAge", 365)
+	v.SetDefault("DatabaseCheckInterval", 3600)
+	v.SetDefault("Mode", modeAll)
+	v.SetDefault("StorageMode", "sqlite3")
+	v.SetDefault("StorageMiddleware", []string{})
+	v.SetDefault("StorageCacheTTL", 60)
+	v.SetDefault("StorageRetryAttempts", 3)
+	v.SetDefault("StorageRetryBackoff", 100)
+	v.SetDefault("SafeMode", true)
+	v.SetDefault("SafeModeMaxReports", 5)
+	v.SetDefault("WebScrapeInterval", 300)
+	v.SetDefault("WebScrapeJitter", 60)
+	v.SetDefault("WebScrapeAlign", "")
+	v.SetDefault("ScrapeSchedule", "")
+	v.SetDefault("Targets", []string{compliance.LanguageCpp})
+	v.SetDefault("TwitterReportInterval", 300)
+	v.SetDefault("TwitterReportJitter", 0)
+	v.SetDefault("TwitterReportAlign", "")
+	v.SetDefault("TwitterReportSchedule", "")
+	v.SetDefault("ReportMaxPerHour", 0)
+	v.SetDefault("ReportMinSpacing", 0)
+	v.SetDefault("ReportQuietHours", "")
+	v.SetDefault("ReportCompilers", []string{})
+	v.SetDefault("ReportLanguages", []string{})
+	v.SetDefault("YearReview", false)
+	v.SetDefault("YearReviewInterval", 3600)
+	v.SetDefault("WeeklySummary", false)
+	v.SetDefault("WeeklySummarySchedule", "0 9 * * 1")
+	v.SetDefault("WeeklySummaryMaxTweets", 4)
+	v.SetDefault("AggregateInterval", 3600)
+	v.SetDefault("MilestoneReports", false)
+	v.SetDefault("Milestones", []int{50, 75, 90, 100})
+	v.SetDefault("EngagementInterval", 3600)
+	v.SetDefault("EngagementWindow", 7)
+	v.SetDefault("ReportMaxAttempts", 5)
+	v.SetDefault("ReportRetryBackoff", 60)
+	v.SetDefault("ReportMaxBackoff", 3600)
+	v.SetDefault("CatchUpAfter", 21600)
+	v.SetDefault("CatchUpMaxTweets", 4)
+	v.SetDefault("ReportPaperLinks", false)
+	v.SetDefault("ReportLinksIfRoom", false)
+	v.SetDefault("ReportCppreferenceLinks", false)
+	v.SetDefault("ReportHashtags", []string{})
+	v.SetDefault("ReportMentions", map[string]string{})
+	v.SetDefault("LinkShortener", "")
+	v.SetDefault("LinkShortenerUrl", "")
+	v.SetDefault("LinkShortenerToken", "")
+	v.SetDefault("QueueBackend", "database")
+	v.SetDefault("RedisAddress", "localhost:6379")
+	v.SetDefault("RedisPassword", "")
+	v.SetDefault("RedisDatabase", 0)
+	v.SetDefault("RedisKeyPrefix", "cppimpbot")
+	v.SetDefault("Suggestions", false)
+	v.SetDefault("SuggestionLimit", 3)
+	v.SetDefault("SuggestionWindow", 86400)
+	v.SetDefault("ReportApproval", false)
+	v.SetDefault("ReportHooks", "")
+	v.SetDefault("ReportThreads", true)
+	v.SetDefault("ReportBatching", false)
+	v.SetDefault("ReportBatchDetails", true)
+	v.SetDefault("ReportBatchMaxTweets", 4)
+	v.SetDefault("Reporters", []string{})
+	v.SetDefault("ReportAfter", map[string][]string{})
+	v.SetDefault("ReportImageCards", false)
+	v.SetDefault("ReportTextDir", "")
+	v.SetDefault("ReportChangesOnly", false)
+	v.SetDefault("SupressReporting", false)
+	v.SetDefault("DryReporting", true)
+	v.SetDefault("SlackWebhookUrl", "")
+	v.SetDefault("SlackChannel", "")
+	v.SetDefault("SlackAlerts", false)
+	v.SetDefault("TelegramBotToken", "")
+	v.SetDefault("TelegramChatId", "")
+	v.SetDefault("TelegramMaintainerChatId", "")
+	v.SetDefault("MaintainerNotifiers", []string{})
+	v.SetDefault("MaintainerEmail", []string{})
+	v.SetDefault("NtfyServer", "")
+	v.SetDefault("NtfyTopic", "")
+	v.SetDefault("NtfyToken", "")
+	v.SetDefault("PushoverToken", "")
+	v.SetDefault("PushoverUser", "")
+	v.SetDefault("SmtpAddress", "")
+	v.SetDefault("SmtpUsername", "")
+	v.SetDefault("SmtpPassword", "")
+	v.SetDefault("DigestFrom", "cppimpbot@localhost")
+	v.SetDefault("DigestTo", []string{})
+	v.SetDefault("DigestInterval", 24)
+	v.SetDefault("DigestCheckInterval", 600)
+	v.SetDefault("MentionReplies", false)
+	v.SetDefault("MentionPollInterval", 120)
+	v.SetDefault("MentionUserLimit", 3)
+	v.SetDefault("MentionUserWindow", 3600)
+	v.SetDefault("MentionMaxReplies", 5)
+	v.SetDefault("BlueskyHost", bluesky.DefaultHost)
+	v.SetDefault("BlueskyHandle", "")
+	v.SetDefault("BlueskyAppPassword", "")
+	v.SetDefault("ObjectiveWindow", 28)
+	v.SetDefault("ObjectiveReportInterval", 604800)
+	v.SetDefault("ObjectiveCheckInterval", 3600)
+	v.SetDefault("ApiAddress", "")
+	v.SetDefault("DashboardAddress", "")
+	v.SetDefault("HealthAddress", "")
+	v.SetDefault("HealthScrapeAge", 0)
+	v.SetDefault("CorrectionOutbox", "")
+	v.SetDefault("ExploreAddress", "localhost:8081")
+	v.SetDefault("ExploreMaxRows", 1000)
+	v.SetDefault("ExperimentalFeatures", []string{})
+	v.SetDefault("ScrapeMaxAttempts", 4)
+	v.SetDefault("ScrapeBackoff", 5)
+	v.SetDefault("ScrapeMaxBackoff", 60)
+	v.SetDefault("ScrapeRetryJitter", 5)
+	v.SetDefault("MaintenancePause", 3600)
+	v.SetDefault("ScrapeUrl", scraper.DefaultURL)
+	v.SetDefault("ScrapeCUrl", scraper.DefaultCURL)
+	v.SetDefault("ScrapeUserAgent", "cppimpbot")
+	v.SetDefault("ScrapeTimeout", 30)
+	v.SetDefault("ScrapeProxy", "")
+	v.SetDefault("FingerprintMaxRowChange", 0.2)
+	v.SetDefault("StrictParsing", false)
+	v.SetDefault("ScrapeMinKnown", 0.8)
+	v.SetDefault("ScrapeSnapshots", true)
+	v.SetDefault("ScrapeSnapshotKeep", 50)
+	v.SetDefault("ScrapeDefectReports", false)
+	v.SetDefault("GccStatusUrl", scraper.DefaultGccStatusURL)
+	v.SetDefault("ClangStatusUrl", scraper.DefaultClangStatusURL)
+	v.SetDefault("DiscrepancyAlerts", false)
+	v.SetDefault("FullHistory", false)
+	v.SetDefault("ReleasePollInterval", 3600)
+	v.SetDefault("LogLevel", "info")
+	v.SetDefault("LogFormat", "text")
+}
 
-	failOnMissingConfig := false
+// setConfigFile points v at the file given with --config, or config.toml in the working directory
+func setConfigFile(v *viper.Viper) {
 	if cfgFile != "" {
-		failOnMissingConfig = true
-		viper.SetConfigFile(cfgFile)
+		v.SetConfigFile(cfgFile)
 	} else {
-		viper.AddConfigPath(".")
-		viper.SetConfigName("config")
+		v.AddConfigPath(".")
+		v.SetConfigName("config")
 	}
+}
+
+func initConfig() {
+	setConfigDefaults(viper.GetViper())
+	setConfigFile(viper.GetViper())
+	failOnMissingConfig := cfgFile != ""
 
 	err := viper.ReadInConfig()            //find and read the config file
 	if failOnMissingConfig && err != nil { // Handle errors reading the config file
-		log.Fatal("Failed to read config", err)
+		slog.Error("Failed to read config", "err", err)
+		os.Exit(1)
+	}
+
+	if err := configureLogging(viper.GetString("LogLevel"), viper.GetString("LogFormat")); err != nil {
+		slog.Error("Failed to configure logging", "err", err)
+		os.Exit(1)
 	}
 }
 
 func main() {
+	//registered before the flags are parsed, which is before initConfig runs
+	rootCommand.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is config.toml)")
+	rootCommand.PersistentFlags().String("storage", "", "storage mode, sqlite3 or dummy to keep everything in memory (default is StorageMode of the config)")
+	bindConfigFlag("StorageMode", rootCommand.PersistentFlags().Lookup("storage"))
 	cobra.OnInitialize(initConfig)
 
 	rootCommand.AddCommand(testCommand)
+	rootCommand.AddCommand(exploreCommand)
+	rootCommand.AddCommand(migrateDataCommand)
+	rootCommand.AddCommand(scrapeCommand)
+	rootCommand.AddCommand(backfillCommand)
+	rootCommand.AddCommand(fixTextCommand)
+	rootCommand.AddCommand(whatsNewCommand)
+	rootCommand.AddCommand(archiveCommand)
+	rootCommand.AddCommand(exportCommand)
+	rootCommand.AddCommand(timelineCommand)
+	rootCommand.AddCommand(mergeFeaturesCommand)
+	rootCommand.AddCommand(yearReviewCommand)
+	rootCommand.AddCommand(aggregatesCommand)
+	rootCommand.AddCommand(objectivesCommand)
+	rootCommand.AddCommand(queryCommand)
+	rootCommand.AddCommand(digestCommand)
+	rootCommand.AddCommand(weeklySummaryCommand)
+	rootCommand.AddCommand(ignoreCommand)
+	rootCommand.AddCommand(preflightCommand)
+	rootCommand.AddCommand(variantsCommand)
+	rootCommand.AddCommand(deadLettersCommand)
+	rootCommand.AddCommand(historyCommand)
+	rootCommand.AddCommand(suggestionsCommand)
+	rootCommand.AddCommand(reportCommand)
+	rootCommand.AddCommand(resumeReportingCommand)
+	rootCommand.AddCommand(approveCommand)
+	rootCommand.AddCommand(rejectCommand)
+	rootCommand.AddCommand(serviceCommand)
+	rootCommand.AddCommand(lintReportsCommand)
+	rootCommand.AddCommand(queueCommand)
+	rootCommand.AddCommand(compareCommand)
+	rootCommand.AddCommand(discrepanciesCommand)
+	rootCommand.AddCommand(snapshotsCommand)
+	rootCommand.AddCommand(reprocessCommand)
+
+	execute := func() error {
+		return rootCommand.Execute()
+	}
 
-	if err := rootCommand.Execute(); err != nil {
-		os.Exit(1)
+	if ran, err := runAsService(execute); ran {
+		if err != nil {
+			slog.Error("could not run as a service", "err", err)
+			os.Exit(1)
+		}
+		return
+	} else if err != nil {
+		slog.Warn("could not tell if started as a service, running from the console", "err", err)
+	}
+
+	if err := execute(); err != nil {
+		os.Exit(exitCode(err))
+	}
+
+	if restartRequested {
+		slog.Info("restarting with the reloaded config...")
+		if err := restart(); err != nil {
+			slog.Error("could not restart", "err", err)
+			os.Exit(1)
+		}
 	}
 }
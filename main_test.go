@@ -0,0 +1,275 @@
+package main
+
+import (
+	"cppimpbot/compliance"
+	"cppimpbot/scraper"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScheduleJob(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 5, 0, 0, time.UTC)
+
+	for _, test := range []struct {
+		what     string
+		interval int
+		align    string
+		cron     string
+		delay    time.Duration
+	}{
+		{"interval", 300, "", "", 5 * time.Minute},
+		{"aligned interval", 3600, "17m", "", 12 * time.Minute},
+		{"schedule", 0, "", "*/15 * * * *", 10 * time.Minute},
+		{"schedule over interval", 300, "", "*/15 * * * *", 10 * time.Minute},
+	} {
+		job, err := scheduleJob(test.what, test.interval, 0, test.align, test.cron)
+		if err != nil {
+			t.Errorf("%v: %v", test.what, err)
+			continue
+		}
+		if delay := job.NextDelay(now); delay != test.delay {
+			t.Errorf("%v: next run in %v, want %v", test.what, delay, test.delay)
+		}
+	}
+
+	for _, test := range []struct {
+		what     string
+		interval int
+		align    string
+		cron     string
+	}{
+		{"no interval", 0, "", ""},
+		{"negative interval", -60, "", ""},
+		{"aligned without an interval", 0, "17m", ""},
+		{"schedule and align", 0, "17m", "*/15 * * * *"},
+		{"invalid schedule", 300, "", "every day"},
+		{"invalid align", 3600, "soon", ""},
+	} {
+		if job, err := scheduleJob(test.what, test.interval, 0, test.align, test.cron); err == nil {
+			t.Errorf("%v: scheduled to run in %v", test.what, job.NextDelay(now))
+		}
+	}
+}
+
+func TestScheduleJobAlign(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 40, 0, 0, time.UTC)
+
+	for _, test := range []struct {
+		interval int
+		jitter   int
+		align    string
+		next     string
+	}{
+		{3600, 0, "", "13:40"},
+		{3600, 0, "17m", "13:17"},
+		{3600, 0, "40m", "13:40"},
+		{7200, 0, "17m", "14:17"},
+		{3600, 60, "17m", "13:17"},
+	} {
+		job, err := scheduleJob("scrape cppreference", test.interval, test.jitter, test.align, "")
+		if err != nil {
+			t.Errorf("interval %v align %q: %v", test.interval, test.align, err)
+			continue
+		}
+
+		delay := job.NextDelay(now)
+		job.Jitter = 0
+		base := job.NextDelay(now)
+		if next := now.Add(base).Format("15:04"); next != test.next {
+			t.Errorf("interval %v align %q: next run at %v, want %v", test.interval, test.align, next, test.next)
+		}
+		if delay < base || delay-base > time.Duration(test.jitter)*time.Second {
+			t.Errorf("interval %v align %q: jitter of %v, want at most %vs", test.interval, test.align, delay-base, test.jitter)
+		}
+	}
+
+	for _, align := range []string{"-5m", ":17"} {
+		want := "invalid Align " + align + " of scrape cppreference, expected a duration like 17m"
+		if _, err := scheduleJob("scrape cppreference", 3600, 0, align, ""); err == nil || err.Error() != want {
+			t.Errorf("align %q: got %v, want %v", align, err, want)
+		}
+	}
+}
+
+func TestSourceConfigs(t *testing.T) {
+	cfg := &Configuration{WebScrapeInterval: 300, Targets: []string{compliance.LanguageCpp, compliance.LanguageC}}
+	want := []SourceConfig{{Name: "cppreference", Interval: 300}, {Name: "cppreference-c", Interval: 300}}
+	if configs := sourceConfigs(cfg); !reflect.DeepEqual(configs, want) {
+		t.Errorf("sources of the targets: got %+v, want %+v", configs, want)
+	}
+
+	cfg.Sources = []SourceConfig{{Name: "cppreference-c", Interval: 60}}
+	if configs := sourceConfigs(cfg); !reflect.DeepEqual(configs, cfg.Sources) {
+		t.Errorf("configured sources: got %+v, want %+v", configs, cfg.Sources)
+	}
+
+	if err := checkTargets(cfg); err != nil {
+		t.Errorf("targets %v: %v", cfg.Targets, err)
+	}
+	cfg.Targets = []string{compliance.LanguageCppDR}
+	if err := checkTargets(cfg); err == nil || err.Error() != "unknown language in Targets: cppdr, the targets are cpp and c" {
+		t.Errorf("targets %v: got %v", cfg.Targets, err)
+	}
+}
+
+// sourcePage is a compiler support page with a feature table and a table of defect reports
+const sourcePage = `<html><body>
+<h3><span class="mw-headline">C++20 core language features</span></h3>
+<table><tr><th>C++20 feature</th><th>Paper(s)</th><th>GCC</th><th>Clang</th></tr>
+<tr><td>A feature</td><td><a href="https://wg21.link/P0005R1">P0005R1</a></td><td class="table-yes">10</td><td class="table-no"></td></tr></table>
+<h3><span class="mw-headline">C++ defect reports</span></h3>
+<table><tr><th>DR</th><th>Title</th><th>GCC</th><th>Clang</th></tr>
+<tr><td><a href="https://cplusplus.github.io/CWG/issues/2518.html">CWG2518</a></td><td>Conformance requirements and #error/#warning</td><td class="table-yes">13</td><td class="table-no"></td></tr></table>
+</body></html>`
+
+func TestSources(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(sourcePage))
+	}))
+	defer server.Close()
+
+	cfg := &Configuration{ScrapeUrl: server.URL, ScrapeCUrl: server.URL}
+	for _, name := range []string{"cppreference", "cppreference-c"} {
+		source, err := sources[name](cfg)
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+		if source.Name() != name {
+			t.Errorf("%v: source is named %v", name, source.Name())
+		}
+
+		scraped, err := scraper.ScrapeSource(source)
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+		if len(scraped.Versions) != 1 {
+			t.Errorf("%v: got %v tables, want 1", name, len(scraped.Versions))
+		}
+
+		conditional, ok := source.(scraper.ConditionalSource)
+		if !ok {
+			t.Fatalf("%v: source doesn't send conditional requests", name)
+		}
+		if _, validators := conditional.Conditional(); validators.ETag != `"v1"` {
+			t.Errorf("%v: got validators %+v", name, *validators)
+		}
+		if _, err := scraper.ScrapeSource(source); err != scraper.ErrNotModified {
+			t.Errorf("%v: scraping the unchanged page gave %v", name, err)
+		}
+	}
+}
+
+func TestDefectReports(t *testing.T) {
+	for _, defectReports := range []bool{false, true} {
+		scraped, err := scraper.ScrapeFromReader(strings.NewReader(sourcePage), true, defectReports)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := 1
+		if defectReports {
+			want = 2
+		}
+		if len(scraped.Versions) != want || len(scraped.Errors) != 0 {
+			t.Errorf("defect reports %v: got %v tables and errors %v, want %v tables", defectReports, len(scraped.Versions), scraped.Errors, want)
+		}
+	}
+
+	scraped, err := scraper.ScrapeFromReader(strings.NewReader(sourcePage), true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	version := scraped.Versions[1]
+	if version.Language != compliance.LanguageCppDR || version.Title != "C++ defect reports" {
+		t.Fatalf("got table %v (%v %v)", version.Title, version.Language, version.Version)
+	}
+
+	next := featureFromScraped(&version, &version.Features[0])
+	previous := copyTestFeature(next)
+	previous.Compilers = nil
+	previous.SetSupport(testSupport(compliance.CompilerGcc, 0, "", ""))
+	previous.SetSupport(testSupport(compliance.CompilerClang, 0, "", ""))
+	change, err := compliance.DiffFeatures(&previous, &next)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if slug := compliance.BaseSlug(&next); slug != "cppdr-cwg2518" {
+		t.Errorf("got slug %v", slug)
+	}
+	if link := compliance.CppreferenceLink(scraper.DefaultURL, &next); link != "https://en.cppreference.com/w/cpp/compiler_support#C.2B.2B_defect_reports" {
+		t.Errorf("got link %v", link)
+	}
+	report := `[Support Update] C++ DR - "Conformance requirements and #error/#warning".` + "\n\nFrom:\nGCC - [no] \n\nTo:\nGCC - [yes] 13"
+	if text := compliance.ChangeReportText(compliance.ReportOptions{}, change); text != report {
+		t.Errorf("got report %q, want %q", text, report)
+	}
+}
+
+func testSupport(compiler string, support int, displayText string, extraText string) compliance.CompilerSupport {
+	return compliance.CompilerSupport{
+		Compiler:    compiler,
+		Kind:        compliance.KindCompiler,
+		Support:     support,
+		DisplayText: sql.NullString{String: displayText, Valid: true},
+		ExtraText:   sql.NullString{String: extraText, Valid: true},
+	}
+}
+
+func testLibrarySupport(library string, support int, displayText string, extraText string) compliance.CompilerSupport {
+	result := testSupport(library, support, displayText, extraText)
+	result.Kind = compliance.KindLibrary
+	return result
+}
+
+// copyTestFeature copies a feature so that changing support of the copy leaves the original alone
+func copyTestFeature(feature compliance.Feature) compliance.Feature {
+	feature.Compilers = append([]compliance.CompilerSupport(nil), feature.Compilers...)
+	return feature
+}
+
+// sampleFeature is a C++20 feature only Clang supports, with the support given set on top
+func sampleFeature(supports ...compliance.CompilerSupport) compliance.Feature {
+	feature := compliance.Feature{
+		Name:       "Initializer list constructors in class template argument deduction",
+		CppVersion: 20,
+		PaperName:  sql.NullString{String: "P0702R1", Valid: true},
+		PaperLink:  sql.NullString{String: "https://wg21.link/P0702R1", Valid: true},
+	}
+	feature.SetSupport(testSupport(compliance.CompilerGcc, 0, "", ""))
+	feature.SetSupport(testSupport(compliance.CompilerClang, 1, "6 (partial)*", "only supported if flag supplied"))
+	feature.SetSupport(testSupport(compliance.CompilerMsvc, 0, "", ""))
+	feature.SetSupport(testSupport("apple_clang", 0, "", ""))
+
+	for _, support := range supports {
+		feature.SetSupport(support)
+	}
+
+	return feature
+}
+
+// sampleSupported is the sample feature after GCC, MSVC and Apple Clang gained support
+func sampleSupported() compliance.Feature {
+	return sampleFeature(testSupport(compliance.CompilerGcc, 1, "9*", "still some bugs"), testSupport(compliance.CompilerMsvc, 1, "19.20", ""), testSupport("apple_clang", 1, "10.0.1", ""))
+}
+
+func sampleChange(t *testing.T, previous *compliance.Feature, next *compliance.Feature) compliance.Change {
+	t.Helper()
+
+	change, err := compliance.DiffFeatures(previous, next)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return change
+}
@@ -0,0 +1,143 @@
+package mentions
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	MinQueryLength     = 3
+	MaxQueryLength     = 100
+	MaxMentionsInTweet = 3
+)
+
+var (
+	paperPattern   = regexp.MustCompile(`(?i)\b([PN]\d{3,4})(R\d+)?\b`)
+	mentionPattern = regexp.MustCompile(`@\w+`)
+	linkPattern    = regexp.MustCompile(`(?i)https?://`)
+
+	//the ways a mention asks about a feature by name, like "support for modules?" or "is constexpr new supported?"
+	namePatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)^(?:support|supported|status)(?:\s+(?:of|for))?\s*:?\s+(.+)$`),
+		regexp.MustCompile(`(?i)^is\s+(.+?)\s+supported$`),
+	}
+)
+
+// Query is what a mention asks the bot about: either a paper number or a piece of a feature name
+type Query struct {
+	Text    string
+	IsPaper bool
+}
+
+// spam tells if a text mentions too many accounts or links somewhere
+func spam(text string) bool {
+	return len(mentionPattern.FindAllString(text, -1)) > MaxMentionsInTweet || linkPattern.MatchString(text)
+}
+
+func stripMentions(text string) string {
+	stripped := mentionPattern.ReplaceAllString(text, "")
+	stripped = strings.Join(strings.Fields(stripped), " ")
+
+	return strings.Trim(stripped, "?!.\"' ")
+}
+
+func nameQuery(name string) (Query, bool) {
+	if len(name) < MinQueryLength || len(name) > MaxQueryLength {
+		return Query{}, false
+	}
+
+	return Query{Text: name}, true
+}
+
+// ParseQuery extracts the query from the text of a mention. ok is false if the mention looks like spam or doesn't ask
+// anything, which is everything but a paper number or a name asked about like "support for modules", "status of
+// concepts" or "is modules supported?", so chatting with the bot or thanking it gets no reply
+func ParseQuery(text string) (query Query, ok bool) {
+	if spam(text) {
+		return Query{}, false
+	}
+
+	if paper := paperPattern.FindStringSubmatch(text); paper != nil {
+		return Query{Text: strings.ToUpper(paper[1]), IsPaper: true}, true
+	}
+
+	stripped := stripMentions(text)
+	for _, pattern := range namePatterns {
+		if match := pattern.FindStringSubmatch(stripped); match != nil {
+			return nameQuery(strings.Trim(match[1], "?!.\"' "))
+		}
+	}
+
+	return Query{}, false
+}
+
+// ParseSuggestion extracts the paper or the feature name a suggestion is about. unlike a mention, all of the text is
+// taken as the name if it has no paper number. ok is false if it looks like spam or is too short or long for a name
+func ParseSuggestion(text string) (query Query, ok bool) {
+	if spam(text) {
+		return Query{}, false
+	}
+
+	if paper := paperPattern.FindStringSubmatch(text); paper != nil {
+		return Query{Text: strings.ToUpper(paper[1]), IsPaper: true}, true
+	}
+
+	return nameQuery(stripMentions(text))
+}
+
+// UserLimiter keeps track of how many replies every user got recently. users who got none within the window are
+// forgotten, so the users seen over time don't pile up
+type UserLimiter struct {
+	limit  int
+	window time.Duration
+	sent   map[string][]time.Time
+	pruned time.Time
+	mutex  sync.Mutex
+}
+
+func NewUserLimiter(limit int, window time.Duration) *UserLimiter {
+	return &UserLimiter{
+		limit:  limit,
+		window: window,
+		sent:   map[string][]time.Time{},
+	}
+}
+
+// prune forgets the users without replies within the window, at most once a window
+func (l *UserLimiter) prune(now time.Time) {
+	if now.Sub(l.pruned) < l.window {
+		return
+	}
+	l.pruned = now
+
+	for user, sent := range l.sent {
+		if len(sent) == 0 || now.Sub(sent[len(sent)-1]) >= l.window {
+			delete(l.sent, user)
+		}
+	}
+}
+
+// Allow reports if the user may get another reply, and counts it if so
+func (l *UserLimiter) Allow(user string, now time.Time) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.prune(now)
+
+	var recent []time.Time
+	for _, sent := range l.sent[user] {
+		if now.Sub(sent) < l.window {
+			recent = append(recent, sent)
+		}
+	}
+
+	if len(recent) >= l.limit {
+		l.sent[user] = recent
+		return false
+	}
+
+	l.sent[user] = append(recent, now)
+	return true
+}
@@ -0,0 +1,66 @@
+package mentions
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseQuery(t *testing.T) {
+	for _, test := range []struct {
+		text  string
+		query Query
+		ok    bool
+	}{
+		{"@cppimpbot what about P1103R3?", Query{Text: "P1103", IsPaper: true}, true},
+		{"@cppimpbot support for modules?", Query{Text: "modules"}, true},
+		{"@cppimpbot status of constexpr new", Query{Text: "constexpr new"}, true},
+		{"@cppimpbot is pack indexing supported?", Query{Text: "pack indexing"}, true},
+		{"@cppimpbot Support: coroutines", Query{Text: "coroutines"}, true},
+		{"@cppimpbot thanks, great bot!", Query{}, false},
+		{"@cppimpbot modules", Query{}, false},
+		{"@cppimpbot support for ab", Query{}, false},
+		{"@cppimpbot support for modules https://example.com", Query{}, false},
+		{"@a @b @c @d support for modules", Query{}, false},
+	} {
+		query, ok := ParseQuery(test.text)
+		if query != test.query || ok != test.ok {
+			t.Errorf("%q: got %+v, %v, want %+v, %v", test.text, query, ok, test.query, test.ok)
+		}
+	}
+}
+
+func TestParseSuggestion(t *testing.T) {
+	if query, ok := ParseSuggestion("Pack indexing"); !ok || query != (Query{Text: "Pack indexing"}) {
+		t.Errorf("name: got %+v, %v", query, ok)
+	}
+	if query, ok := ParseSuggestion("p2662r3"); !ok || query != (Query{Text: "P2662", IsPaper: true}) {
+		t.Errorf("paper: got %+v, %v", query, ok)
+	}
+	if _, ok := ParseSuggestion("see https://example.com"); ok {
+		t.Errorf("link was taken")
+	}
+}
+
+func TestUserLimiter(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	limiter := NewUserLimiter(2, time.Hour)
+
+	if !limiter.Allow("ada", now) || !limiter.Allow("ada", now.Add(time.Minute)) {
+		t.Fatalf("replies within the limit were refused")
+	}
+	if limiter.Allow("ada", now.Add(2*time.Minute)) {
+		t.Errorf("reply over the limit was allowed")
+	}
+	if !limiter.Allow("grace", now.Add(2*time.Minute)) {
+		t.Errorf("other user was refused")
+	}
+	if !limiter.Allow("ada", now.Add(61*time.Minute)) {
+		t.Errorf("reply after the first one left the window was refused")
+	}
+
+	//a window later only users with replies within it are kept
+	limiter.Allow("linus", now.Add(3*time.Hour))
+	if len(limiter.sent) != 1 {
+		t.Errorf("kept %v users, want only the one of the last window", len(limiter.sent))
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var mergeFeaturesCommand = &cobra.Command{
+	Use:   "merge-features <old feature> <new feature>",
+	Short: "Join the history of a renamed feature that got listed as a new one, by slug or name",
+	Long: `Join the history of a renamed feature that got listed as a new one, by slug or name.
+The entries of the old feature take the slug of the new one, so both show up as one history,
+and the old name is recorded as an alias of the new one.`,
+	Args: cobra.ExactArgs(2),
+	RunE: mergeFeaturesCmdFunc,
+}
+
+func mergeFeaturesCmdFunc(cmd *cobra.Command, args []string) error {
+	cfg := &Configuration{}
+
+	if err := viper.Unmarshal(cfg); err != nil {
+		return err
+	}
+
+	if cfg.StorageMode != "sqlite3" {
+		return fmt.Errorf("merge-features needs storageMode sqlite3, not %s", cfg.StorageMode)
+	}
+
+	complianceStorageService, err := openSqliteService(cfg)
+	if err != nil {
+		return err
+	}
+	defer complianceStorageService.Close(context.Background())
+
+	fromName, intoName, err := complianceStorageService.MergeFeatures(context.Background(), args[0], args[1])
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("merged the history of '%v' into '%v'\n", fromName, intoName)
+
+	return nil
+}
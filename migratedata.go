@@ -0,0 +1,270 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"cppimpbot/util"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// migration versions of the two storage layouts. v2 covers every migration from normalizedSchemaVersion on
+const (
+	wideSchemaVersion       = 2
+	normalizedSchemaVersion = 4
+)
+
+var migrateDataCommand = &cobra.Command{
+	Use:   "migrate-data <v1|v2>",
+	Short: "Convert the database between the wide (v1) and normalized (v2) schema, keeping a backup",
+	Args:  cobra.ExactArgs(1),
+	RunE:  migrateDataCmdFunc,
+}
+
+var migrateDataForce bool
+
+func init() {
+	migrateDataCommand.Flags().BoolVar(&migrateDataForce, "force", false, "convert to v1 even if entries or support don't fit in it and are dropped")
+}
+
+type wideFeature struct {
+	Name             string
+	Timestamp        time.Time
+	GccSupport       int            `db:"gcc_support"`
+	GccDisplayText   sql.NullString `db:"gcc_display_text"`
+	GccExtraText     sql.NullString `db:"gcc_extra_text"`
+	ClangSupport     int            `db:"clang_support"`
+	ClangDisplayText sql.NullString `db:"clang_display_text"`
+	ClangExtraText   sql.NullString `db:"clang_extra_text"`
+	MsvcSupport      int            `db:"msvc_support"`
+	MsvcDisplayText  sql.NullString `db:"msvc_display_text"`
+	MsvcExtraText    sql.NullString `db:"msvc_extra_text"`
+}
+
+type snapshotCell struct {
+	support     int
+	displayText sql.NullString
+	extraText   sql.NullString
+}
+
+// dataSnapshot maps every entry to the cells of the compilers both schemas can hold
+type dataSnapshot map[string]map[string]snapshotCell
+
+func snapshotKey(name string, timestamp time.Time) string {
+	return fmt.Sprintf("%s@%d", name, timestamp.UnixNano())
+}
+
+func wideSnapshot(db *sqlx.DB) (dataSnapshot, error) {
+	query := `SELECT name, timestamp,
+		 gcc_support, gcc_display_text, gcc_extra_text,
+		 clang_support, clang_display_text, clang_extra_text,
+		 msvc_support, msvc_display_text, msvc_extra_text
+		FROM features`
+
+	var rows []wideFeature
+	if err := db.Select(&rows, query); err != nil {
+		return nil, err
+	}
+
+	result := dataSnapshot{}
+	for _, row := range rows {
+		result[snapshotKey(row.Name, row.Timestamp)] = map[string]snapshotCell{
+			compliance.CompilerGcc:   {row.GccSupport, row.GccDisplayText, row.GccExtraText},
+			compliance.CompilerClang: {row.ClangSupport, row.ClangDisplayText, row.ClangExtraText},
+			compliance.CompilerMsvc:  {row.MsvcSupport, row.MsvcDisplayText, row.MsvcExtraText},
+		}
+	}
+
+	return result, nil
+}
+
+// normalizedSnapshot maps the C++ entries, the only ones the v1 schema holds
+func normalizedSnapshot(db *sqlx.DB) (dataSnapshot, error) {
+	entries, err := compliance.NewSqliteService(db).GetEntriesSince(context.Background(), time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	result := dataSnapshot{}
+	for _, entry := range entries {
+		if entry.Language != compliance.LanguageCpp {
+			continue
+		}
+
+		cells := map[string]snapshotCell{}
+
+		for _, compiler := range compliance.PrimaryCompilers {
+			if support := entry.SupportFor(compiler); support != nil {
+				cells[compiler] = snapshotCell{support.Support, support.DisplayText, support.ExtraText}
+			}
+		}
+
+		result[snapshotKey(entry.Name, entry.Timestamp)] = cells
+	}
+
+	return result, nil
+}
+
+// downgradeLoss counts what converting to v1 drops: the entries of languages other than C++, and the support of
+// compilers and libraries other than GCC, Clang and MSVC
+func downgradeLoss(db *sqlx.DB) (entries int, cells int, err error) {
+	if err := db.Get(&entries, "SELECT COUNT(*) FROM features WHERE language<>?", compliance.LanguageCpp); err != nil {
+		return 0, 0, err
+	}
+
+	query, args, err := sqlx.In(`SELECT COUNT(*) FROM compiler_support cs
+		JOIN features f ON f.id=cs.feature_id
+		WHERE f.language=? AND cs.compiler NOT IN (?)`, compliance.LanguageCpp, compliance.PrimaryCompilers)
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := db.Get(&cells, query, args...); err != nil {
+		return 0, 0, err
+	}
+
+	return entries, cells, nil
+}
+
+func compareSnapshots(before dataSnapshot, after dataSnapshot) error {
+	if len(before) != len(after) {
+		return fmt.Errorf("entry count differs: %v before, %v after", len(before), len(after))
+	}
+
+	for key, beforeCells := range before {
+		afterCells, ok := after[key]
+		if !ok {
+			return fmt.Errorf("entry %v is missing after migration", key)
+		}
+
+		for compiler, cell := range beforeCells {
+			if afterCells[compiler] != cell {
+				return fmt.Errorf("%v support of entry %v differs: %+v before, %+v after", compiler, key, cell, afterCells[compiler])
+			}
+		}
+	}
+
+	return nil
+}
+
+func migrateDataCmdFunc(cmd *cobra.Command, args []string) error {
+	cfg := &Configuration{}
+
+	if err := viper.Unmarshal(cfg); err != nil {
+		return err
+	}
+
+	return migrateData(cfg, args[0], migrateDataForce)
+}
+
+// migrateData converts the database to the schema of target, restoring the backup if the entries read back differ.
+// converting to v1 is refused if it would drop data, unless force is set
+func migrateData(cfg *Configuration, target string, force bool) error {
+	if cfg.StorageMode != "sqlite3" {
+		return fmt.Errorf("migrate-data needs storageMode sqlite3, not %s", cfg.StorageMode)
+	}
+
+	var readBefore, readAfter func(*sqlx.DB) (dataSnapshot, error)
+
+	switch target {
+	case "v2":
+		readBefore, readAfter = wideSnapshot, normalizedSnapshot
+	case "v1":
+		readBefore, readAfter = normalizedSnapshot, wideSnapshot
+	default:
+		return fmt.Errorf("unknown data version '%s', expected v1 or v2", target)
+	}
+
+	version, err := util.SqliteMigrationVersion(cfg.Database)
+	if err != nil {
+		return err
+	}
+
+	if target == "v2" && version != wideSchemaVersion {
+		return fmt.Errorf("database is at migration version %v, converting to v2 needs the v1 schema (version %v)", version, wideSchemaVersion)
+	}
+	if target == "v1" && version < normalizedSchemaVersion {
+		return fmt.Errorf("database is at migration version %v, converting to v1 needs the v2 schema (version %v or later)", version, normalizedSchemaVersion)
+	}
+
+	backupPath, err := util.BackupFile(cfg.Database, fmt.Sprintf("migration-%v-backup", version))
+	if err != nil {
+		return fmt.Errorf("could not back up database: %v", err)
+	}
+	slog.Info("backed up database", "path", backupPath)
+
+	restore := func(reason error) error {
+		slog.Error("migration failed, restoring backup", "err", reason)
+		if err := util.CopyFile(backupPath, cfg.Database); err != nil {
+			return fmt.Errorf("%v. restoring the backup failed as well, copy %v back manually: %v", reason, backupPath, err)
+		}
+		return reason
+	}
+
+	//the storage service reads the v2 schema as of the latest migration
+	if target == "v1" {
+		if err := util.SqliteMigrateUp(cfg.Database, cfg.MigrateDir); err != nil {
+			return restore(err)
+		}
+	}
+
+	db, err := util.SqliteConnect(cfg.Database)
+	if err != nil {
+		return restore(err)
+	}
+
+	if target == "v1" {
+		entries, cells, err := downgradeLoss(db)
+		if err != nil {
+			db.Close()
+			return restore(fmt.Errorf("could not count what v1 can't hold: %v", err))
+		}
+
+		if entries > 0 || cells > 0 {
+			if !force {
+				db.Close()
+				return restore(fmt.Errorf("v1 only holds the GCC, Clang and MSVC support of C++ features, converting would drop %v entries of other languages and %v support cells of other compilers. use --force to drop them", entries, cells))
+			}
+			slog.Warn("dropping what v1 can't hold", "entries", entries, "cells", cells)
+		}
+	}
+
+	before, err := readBefore(db)
+	db.Close()
+	if err != nil {
+		return restore(fmt.Errorf("could not read data before migrating: %v", err))
+	}
+
+	if target == "v2" {
+		err = util.SqliteMigrateUp(cfg.Database, cfg.MigrateDir)
+	} else {
+		err = util.SqliteMigrateDownTo(cfg.Database, cfg.MigrateDir, wideSchemaVersion)
+	}
+	if err != nil {
+		return restore(err)
+	}
+
+	db, err = util.SqliteConnect(cfg.Database)
+	if err != nil {
+		return restore(err)
+	}
+
+	after, err := readAfter(db)
+	db.Close()
+	if err != nil {
+		return restore(fmt.Errorf("could not read data after migrating: %v", err))
+	}
+
+	if err := compareSnapshots(before, after); err != nil {
+		return restore(err)
+	}
+
+	slog.Info("converted and verified entries. the backup can be copied back to undo this", "entries", len(after), "schema", target, "backup", backupPath)
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"cppimpbot/util"
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+func newMigrateDataDatabase(t *testing.T, features ...compliance.Feature) *Configuration {
+	t.Helper()
+
+	cfg := &Configuration{StorageMode: "sqlite3", Database: filepath.Join(t.TempDir(), "cppimpbot.db"), MigrateDir: "migrations"}
+	if err := util.SqliteMigrateUp(cfg.Database, cfg.MigrateDir); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := util.SqliteConnect(cfg.Database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	service := compliance.NewSqliteService(db)
+	for i := range features {
+		if err := service.CreateEntry(context.Background(), &features[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return cfg
+}
+
+func migrateDataSupport(compiler string, kind string) compliance.CompilerSupport {
+	return compliance.CompilerSupport{Compiler: compiler, Kind: kind, Support: compliance.SupportYes, DisplayText: sql.NullString{String: "11", Valid: true}}
+}
+
+func TestMigrateDataDowngrade(t *testing.T) {
+	cppFeature := compliance.Feature{Name: "Modules", CppVersion: 20, Compilers: []compliance.CompilerSupport{
+		migrateDataSupport(compliance.CompilerGcc, compliance.KindCompiler),
+	}}
+
+	for _, test := range []struct {
+		what     string
+		features []compliance.Feature
+		fits     bool
+	}{
+		{"C++ features of primary compilers", []compliance.Feature{cppFeature}, true},
+		{"C features", []compliance.Feature{cppFeature, {Language: compliance.LanguageC, Name: "_BitInt", CppVersion: 23}}, false},
+		{"library support", []compliance.Feature{{Name: "Ranges", CppVersion: 20, Compilers: []compliance.CompilerSupport{
+			migrateDataSupport("libstdcxx", compliance.KindLibrary),
+		}}}, false},
+	} {
+		cfg := newMigrateDataDatabase(t, test.features...)
+		latest, err := util.SqliteMigrationVersion(cfg.Database)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = migrateData(cfg, "v1", false)
+		if test.fits != (err == nil) {
+			t.Errorf("%v: converting without force gave %v", test.what, err)
+		}
+		if !test.fits {
+			if version, _ := util.SqliteMigrationVersion(cfg.Database); version != latest {
+				t.Errorf("%v: refused conversion left the database at version %v, want %v", test.what, version, latest)
+			}
+
+			if err := migrateData(cfg, "v1", true); err != nil {
+				t.Errorf("%v: forced conversion gave %v", test.what, err)
+			}
+		}
+
+		if version, _ := util.SqliteMigrationVersion(cfg.Database); version != wideSchemaVersion {
+			t.Errorf("%v: converted database is at version %v, want %v", test.what, version, wideSchemaVersion)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+-- +goose Up
+CREATE TABLE `compiler_support` (
+  `feature_id` INTEGER NOT NULL,
+  `compiler` TEXT NOT NULL,
+  `support` INT NOT NULL,
+  `display_text` TEXT,
+  `extra_text` TEXT,
+  PRIMARY KEY (feature_id, compiler)
+  );
+
+INSERT INTO `compiler_support` (feature_id, compiler, support, display_text, extra_text)
+  SELECT rowid, 'gcc', gcc_support, gcc_display_text, gcc_extra_text FROM `features`;
+INSERT INTO `compiler_support` (feature_id, compiler, support, display_text, extra_text)
+  SELECT rowid, 'clang', clang_support, clang_display_text, clang_extra_text FROM `features`;
+INSERT INTO `compiler_support` (feature_id, compiler, support, display_text, extra_text)
+  SELECT rowid, 'msvc', msvc_support, msvc_display_text, msvc_extra_text FROM `features`;
+
+CREATE TABLE `features_normalized` (
+  `name` TEXT,
+  `timestamp` DATETIME,
+  `cpp_version` INT NOT NULL,
+  `paper_name` TEXT,
+  `paper_link` TEXT,
+  `reported_to_twitter` BOOLEAN,
+  `reported_broken` BOOLEAN,
+  PRIMARY KEY (name, timestamp)
+  );
+
+INSERT INTO `features_normalized` (rowid, name, timestamp, cpp_version, paper_name, paper_link, reported_to_twitter, reported_broken)
+  SELECT rowid, name, timestamp, cpp_version, paper_name, paper_link, reported_to_twitter, reported_broken FROM `features`;
+
+DROP TABLE `features`;
+ALTER TABLE `features_normalized` RENAME TO `features`;
+
+-- +goose Down
+CREATE TABLE `features_wide` (
+  `name` TEXT,
+  `timestamp` DATETIME,
+  `cpp_version` INT NOT NULL,
+  `paper_name` TEXT,
+  `paper_link` TEXT,
+  `gcc_support` INT NOT NULL,
+  `gcc_display_text` TEXT,
+  `gcc_extra_text` TEXT,
+  `clang_support` INT NOT NULL,
+  `clang_display_text` TEXT,
+  `clang_extra_text` TEXT,
+  `msvc_support` INT NOT NULL,
+  `msvc_display_text` TEXT,
+  `msvc_extra_text` TEXT,
+  `reported_to_twitter` BOOLEAN,
+  `reported_broken` BOOLEAN,
+  PRIMARY KEY (name, timestamp)
+  );
+
+INSERT INTO `features_wide`
+  SELECT f.name, f.timestamp, f.cpp_version, f.paper_name, f.paper_link,
+    COALESCE(gcc.support, 0), gcc.display_text, gcc.extra_text,
+    COALESCE(clang.support, 0), clang.display_text, clang.extra_text,
+    COALESCE(msvc.support, 0), msvc.display_text, msvc.extra_text,
+    f.reported_to_twitter, f.reported_broken
+  FROM `features` f
+  LEFT JOIN `compiler_support` gcc ON gcc.feature_id = f.rowid AND gcc.compiler = 'gcc'
+  LEFT JOIN `compiler_support` clang ON clang.feature_id = f.rowid AND clang.compiler = 'clang'
+  LEFT JOIN `compiler_support` msvc ON msvc.feature_id = f.rowid AND msvc.compiler = 'msvc';
+
+DROP TABLE `features`;
+ALTER TABLE `features_wide` RENAME TO `features`;
+DROP TABLE `compiler_support`;
@@ -0,0 +1,48 @@
+-- +goose Up
+-- compiler support is stored as cell changes: a row is only written when a compiler's cell differs from the previous entry of the feature
+ALTER TABLE `compiler_support` ADD COLUMN `timestamp` DATETIME;
+
+UPDATE `compiler_support` SET `timestamp`=(SELECT f.timestamp FROM `features` f WHERE f.rowid=compiler_support.feature_id);
+
+DELETE FROM `compiler_support` WHERE EXISTS (
+  SELECT 1 FROM `features` f
+  JOIN `features` prev ON prev.name=f.name
+    AND prev.timestamp=(SELECT MAX(p.timestamp) FROM `features` p WHERE p.name=f.name AND p.timestamp<f.timestamp)
+  JOIN `compiler_support` pcs ON pcs.feature_id=prev.rowid AND pcs.compiler=compiler_support.compiler
+  WHERE f.rowid=compiler_support.feature_id
+    AND pcs.support=compiler_support.support
+    AND pcs.display_text IS compiler_support.display_text
+    AND pcs.extra_text IS compiler_support.extra_text
+  );
+
+CREATE INDEX `compiler_support_timestamp` ON `compiler_support` (timestamp);
+
+-- +goose Down
+DROP INDEX `compiler_support_timestamp`;
+
+-- fill in the unchanged cells of every entry from the latest change before it
+INSERT INTO `compiler_support` (feature_id, compiler, support, display_text, extra_text, timestamp)
+  SELECT f.rowid, cs.compiler, cs.support, cs.display_text, cs.extra_text, f.timestamp
+  FROM `features` f
+  JOIN `features` pf ON pf.name=f.name AND pf.timestamp<f.timestamp
+  JOIN `compiler_support` cs ON cs.feature_id=pf.rowid
+  WHERE NOT EXISTS (SELECT 1 FROM `compiler_support` x WHERE x.feature_id=f.rowid AND x.compiler=cs.compiler)
+    AND cs.timestamp=(
+      SELECT MAX(c2.timestamp) FROM `compiler_support` c2
+      JOIN `features` f2 ON f2.rowid=c2.feature_id
+      WHERE f2.name=f.name AND c2.compiler=cs.compiler AND c2.timestamp<f.timestamp);
+
+CREATE TABLE `compiler_support_full` (
+  `feature_id` INTEGER NOT NULL,
+  `compiler` TEXT NOT NULL,
+  `support` INT NOT NULL,
+  `display_text` TEXT,
+  `extra_text` TEXT,
+  PRIMARY KEY (feature_id, compiler)
+  );
+
+INSERT INTO `compiler_support_full` (feature_id, compiler, support, display_text, extra_text)
+  SELECT feature_id, compiler, support, display_text, extra_text FROM `compiler_support` ORDER BY rowid;
+
+DROP TABLE `compiler_support`;
+ALTER TABLE `compiler_support_full` RENAME TO `compiler_support`;
@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE `fingerprints` (
+  `source` TEXT NOT NULL,
+  `timestamp` DATETIME NOT NULL,
+  `data` TEXT NOT NULL,
+  PRIMARY KEY (source, timestamp)
+  );
+
+-- +goose Down
+DROP TABLE `fingerprints`;
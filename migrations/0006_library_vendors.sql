@@ -0,0 +1,46 @@
+-- +goose Up
+-- library tables list standard library implementations, which get their own keys and kind 'library'
+ALTER TABLE `compiler_support` ADD COLUMN `kind` TEXT NOT NULL DEFAULT 'compiler';
+
+-- a feature is a library feature if it was ever scraped with library columns. features last scraped into the wide schema can't be told apart and stay compiler features
+UPDATE `compiler_support` SET `kind`='library' WHERE feature_id IN (
+  SELECT f.rowid FROM `features` f WHERE f.name IN (
+    SELECT lf.name FROM `features` lf
+    JOIN `compiler_support` lcs ON lcs.feature_id=lf.rowid
+    WHERE lcs.compiler IN ('gcc_libstdc', 'clang_libc', 'msvc_stl')));
+
+UPDATE `compiler_support` SET `compiler`=CASE `compiler`
+    WHEN 'gcc_libstdc' THEN 'libstdcxx'
+    WHEN 'gcc' THEN 'libstdcxx'
+    WHEN 'clang_libc' THEN 'libcxx'
+    WHEN 'clang' THEN 'libcxx'
+    WHEN 'msvc' THEN 'msvc_stl'
+    WHEN 'apple_clang' THEN 'apple_libcxx'
+    ELSE `compiler` END
+  WHERE `kind`='library';
+
+-- +goose Down
+UPDATE `compiler_support` SET `compiler`=CASE `compiler`
+    WHEN 'libstdcxx' THEN 'gcc_libstdc'
+    WHEN 'libcxx' THEN 'clang_libc'
+    WHEN 'apple_libcxx' THEN 'apple_clang'
+    ELSE `compiler` END
+  WHERE `kind`='library';
+
+CREATE TABLE `compiler_support_compilers` (
+  `feature_id` INTEGER NOT NULL,
+  `compiler` TEXT NOT NULL,
+  `support` INT NOT NULL,
+  `display_text` TEXT,
+  `extra_text` TEXT,
+  `timestamp` DATETIME,
+  PRIMARY KEY (feature_id, compiler)
+  );
+
+INSERT INTO `compiler_support_compilers` (rowid, feature_id, compiler, support, display_text, extra_text, timestamp)
+  SELECT rowid, feature_id, compiler, support, display_text, extra_text, timestamp FROM `compiler_support`;
+
+DROP INDEX `compiler_support_timestamp`;
+DROP TABLE `compiler_support`;
+ALTER TABLE `compiler_support_compilers` RENAME TO `compiler_support`;
+CREATE INDEX `compiler_support_timestamp` ON `compiler_support` (timestamp);
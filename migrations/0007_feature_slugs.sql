@@ -0,0 +1,24 @@
+-- +goose Up
+-- slugs are filled in by the service on startup, as they are derived from paper numbers and names
+ALTER TABLE `features` ADD COLUMN `slug` TEXT NOT NULL DEFAULT '';
+CREATE INDEX `features_slug` ON `features` (slug);
+
+-- +goose Down
+DROP INDEX `features_slug`;
+
+CREATE TABLE `features_unslugged` (
+  `name` TEXT,
+  `timestamp` DATETIME,
+  `cpp_version` INT NOT NULL,
+  `paper_name` TEXT,
+  `paper_link` TEXT,
+  `reported_to_twitter` BOOLEAN,
+  `reported_broken` BOOLEAN,
+  PRIMARY KEY (name, timestamp)
+  );
+
+INSERT INTO `features_unslugged` (rowid, name, timestamp, cpp_version, paper_name, paper_link, reported_to_twitter, reported_broken)
+  SELECT rowid, name, timestamp, cpp_version, paper_name, paper_link, reported_to_twitter, reported_broken FROM `features`;
+
+DROP TABLE `features`;
+ALTER TABLE `features_unslugged` RENAME TO `features`;
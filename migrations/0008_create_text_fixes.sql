@@ -0,0 +1,14 @@
+-- +goose Up
+-- audit log of bulk edits of historical support texts
+CREATE TABLE `text_fixes` (
+  `timestamp` DATETIME NOT NULL,
+  `feature_id` INTEGER NOT NULL,
+  `compiler` TEXT NOT NULL,
+  `field` TEXT NOT NULL,
+  `pattern` TEXT NOT NULL,
+  `old_text` TEXT NOT NULL,
+  `new_text` TEXT NOT NULL
+  );
+
+-- +goose Down
+DROP TABLE `text_fixes`;
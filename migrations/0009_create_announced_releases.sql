@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE `announced_releases` (
+  `compiler` TEXT NOT NULL,
+  `version` TEXT NOT NULL,
+  `timestamp` DATETIME NOT NULL,
+  PRIMARY KEY (compiler, version)
+  );
+
+-- +goose Down
+DROP TABLE `announced_releases`;
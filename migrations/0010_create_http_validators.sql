@@ -0,0 +1,11 @@
+-- +goose Up
+-- ETag and Last-Modified of the last fetched version of every scraped page, for conditional requests
+CREATE TABLE `http_validators` (
+  `url` TEXT NOT NULL PRIMARY KEY,
+  `etag` TEXT NOT NULL,
+  `last_modified` TEXT NOT NULL,
+  `timestamp` DATETIME NOT NULL
+  );
+
+-- +goose Down
+DROP TABLE `http_validators`;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- earlier names of features. alias is the old name, name the one it was renamed to
+CREATE TABLE `feature_aliases` (
+  `alias` TEXT NOT NULL PRIMARY KEY,
+  `name` TEXT NOT NULL,
+  `source` TEXT NOT NULL,
+  `timestamp` DATETIME NOT NULL
+  );
+
+CREATE INDEX `feature_aliases_name` ON `feature_aliases` (name);
+
+-- +goose Down
+DROP INDEX `feature_aliases_name`;
+DROP TABLE `feature_aliases`;
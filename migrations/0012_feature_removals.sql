@@ -0,0 +1,28 @@
+-- +goose Up
+-- a feature that disappears from the page gets an entry with removed set, which is its latest entry until it comes back
+ALTER TABLE `features` ADD COLUMN `removed` BOOLEAN NOT NULL DEFAULT 0;
+
+-- +goose Down
+DELETE FROM `compiler_support` WHERE feature_id IN (SELECT rowid FROM `features` WHERE removed);
+DELETE FROM `features` WHERE removed;
+
+DROP INDEX `features_slug`;
+
+CREATE TABLE `features_unremoved` (
+  `name` TEXT,
+  `timestamp` DATETIME,
+  `cpp_version` INT NOT NULL,
+  `paper_name` TEXT,
+  `paper_link` TEXT,
+  `reported_to_twitter` BOOLEAN,
+  `reported_broken` BOOLEAN,
+  `slug` TEXT NOT NULL DEFAULT '',
+  PRIMARY KEY (name, timestamp)
+  );
+
+INSERT INTO `features_unremoved` (rowid, name, timestamp, cpp_version, paper_name, paper_link, reported_to_twitter, reported_broken, slug)
+  SELECT rowid, name, timestamp, cpp_version, paper_name, paper_link, reported_to_twitter, reported_broken, slug FROM `features`;
+
+DROP TABLE `features`;
+ALTER TABLE `features_unremoved` RENAME TO `features`;
+CREATE INDEX `features_slug` ON `features` (slug);
@@ -0,0 +1,8 @@
+-- +goose Up
+CREATE TABLE `year_reviews` (
+  `year` INTEGER NOT NULL PRIMARY KEY,
+  `timestamp` DATETIME NOT NULL
+  );
+
+-- +goose Down
+DROP TABLE `year_reviews`;
@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE `objective_events` (
+  `objective` TEXT NOT NULL,
+  `timestamp` DATETIME NOT NULL,
+  `good` BOOLEAN NOT NULL
+  );
+
+CREATE INDEX `objective_events_objective` ON `objective_events` (`objective`, `timestamp`);
+
+-- +goose Down
+DROP TABLE `objective_events`;
@@ -0,0 +1,8 @@
+-- +goose Up
+CREATE TABLE `digests` (
+  `timestamp` DATETIME NOT NULL,
+  `changes` INTEGER NOT NULL
+  );
+
+-- +goose Down
+DROP TABLE `digests`;
@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE `report_posts` (
+  `tweet_id` INTEGER NOT NULL PRIMARY KEY,
+  `feature_id` INTEGER NOT NULL,
+  `kind` TEXT NOT NULL,
+  `variant` TEXT NOT NULL,
+  `timestamp` DATETIME NOT NULL,
+  `likes` INTEGER NOT NULL DEFAULT 0,
+  `retweets` INTEGER NOT NULL DEFAULT 0
+  );
+
+CREATE INDEX `report_posts_timestamp` ON `report_posts` (`timestamp`);
+
+-- +goose Down
+DROP TABLE `report_posts`;
@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE `report_attempts` (
+  `feature_id` INTEGER NOT NULL,
+  `channel` TEXT NOT NULL,
+  `attempts` INTEGER NOT NULL DEFAULT 0,
+  `last_error` TEXT NOT NULL DEFAULT '',
+  `last_attempt` DATETIME NOT NULL,
+  `next_attempt` DATETIME NOT NULL,
+  `dead_letter` BOOLEAN NOT NULL DEFAULT 0,
+  PRIMARY KEY (`feature_id`, `channel`)
+  );
+
+-- +goose Down
+DROP TABLE `report_attempts`;
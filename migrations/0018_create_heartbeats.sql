@@ -0,0 +1,8 @@
+-- +goose Up
+CREATE TABLE `heartbeats` (
+  `id` INTEGER NOT NULL PRIMARY KEY CHECK (`id` = 1),
+  `timestamp` DATETIME NOT NULL
+  );
+
+-- +goose Down
+DROP TABLE `heartbeats`;
@@ -0,0 +1,42 @@
+-- +goose Up
+-- every channel an entry is reported on gets a row, so reporters can come and go without a column each
+CREATE TABLE `reports` (
+  `entry_id` INTEGER NOT NULL,
+  `channel` TEXT NOT NULL,
+  `reported_at` DATETIME NOT NULL,
+  `status` TEXT NOT NULL,
+  PRIMARY KEY (`entry_id`, `channel`)
+  );
+
+-- entries without a flag never showed up as unreported, so they count as reported
+INSERT INTO `reports` (entry_id, channel, reported_at, status)
+  SELECT rowid, 'twitter', timestamp, 'reported' FROM `features` WHERE COALESCE(reported_to_twitter, 1);
+
+DROP INDEX `features_slug`;
+
+CREATE TABLE `features_reported` (
+  `name` TEXT,
+  `timestamp` DATETIME,
+  `cpp_version` INT NOT NULL,
+  `paper_name` TEXT,
+  `paper_link` TEXT,
+  `reported_broken` BOOLEAN,
+  `slug` TEXT NOT NULL DEFAULT '',
+  `removed` BOOLEAN NOT NULL DEFAULT 0,
+  PRIMARY KEY (name, timestamp)
+  );
+
+INSERT INTO `features_reported` (rowid, name, timestamp, cpp_version, paper_name, paper_link, reported_broken, slug, removed)
+  SELECT rowid, name, timestamp, cpp_version, paper_name, paper_link, reported_broken, slug, removed FROM `features`;
+
+DROP TABLE `features`;
+ALTER TABLE `features_reported` RENAME TO `features`;
+CREATE INDEX `features_slug` ON `features` (slug);
+
+-- +goose Down
+ALTER TABLE `features` ADD COLUMN `reported_to_twitter` BOOLEAN;
+
+UPDATE `features` SET reported_to_twitter=EXISTS (
+  SELECT 1 FROM `reports` WHERE entry_id=features.rowid AND channel='twitter');
+
+DROP TABLE `reports`;
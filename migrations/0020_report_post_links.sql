@@ -0,0 +1,21 @@
+-- +goose Up
+-- the link a report included, and the short one it was posted as, to attribute the clicks counted by the shortener
+ALTER TABLE `report_posts` ADD COLUMN `link` TEXT NOT NULL DEFAULT '';
+ALTER TABLE `report_posts` ADD COLUMN `short_link` TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+CREATE TABLE `report_posts_unlinked` (
+  `tweet_id` INTEGER NOT NULL PRIMARY KEY,
+  `feature_id` INTEGER NOT NULL,
+  `kind` TEXT NOT NULL,
+  `variant` TEXT NOT NULL,
+  `timestamp` DATETIME NOT NULL,
+  `likes` INTEGER NOT NULL DEFAULT 0,
+  `retweets` INTEGER NOT NULL DEFAULT 0
+  );
+
+INSERT INTO `report_posts_unlinked` SELECT tweet_id, feature_id, kind, variant, timestamp, likes, retweets FROM `report_posts`;
+
+DROP TABLE `report_posts`;
+ALTER TABLE `report_posts_unlinked` RENAME TO `report_posts`;
+CREATE INDEX `report_posts_timestamp` ON `report_posts` (`timestamp`);
@@ -0,0 +1,46 @@
+-- +goose Up
+-- entries get an id of their own instead of being keyed by name and timestamp. ids are taken over from the rowids the
+-- support cells, reports and text fixes already refer to, and are never reused so those references can't go stale
+DROP INDEX `features_slug`;
+
+CREATE TABLE `features_identified` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `name` TEXT,
+  `timestamp` DATETIME,
+  `cpp_version` INT NOT NULL,
+  `paper_name` TEXT,
+  `paper_link` TEXT,
+  `reported_broken` BOOLEAN,
+  `slug` TEXT NOT NULL DEFAULT '',
+  `removed` BOOLEAN NOT NULL DEFAULT 0,
+  UNIQUE (name, timestamp)
+  );
+
+INSERT INTO `features_identified` (id, name, timestamp, cpp_version, paper_name, paper_link, reported_broken, slug, removed)
+  SELECT rowid, name, timestamp, cpp_version, paper_name, paper_link, reported_broken, slug, removed FROM `features`;
+
+DROP TABLE `features`;
+ALTER TABLE `features_identified` RENAME TO `features`;
+CREATE INDEX `features_slug` ON `features` (slug);
+
+-- +goose Down
+DROP INDEX `features_slug`;
+
+CREATE TABLE `features_unidentified` (
+  `name` TEXT,
+  `timestamp` DATETIME,
+  `cpp_version` INT NOT NULL,
+  `paper_name` TEXT,
+  `paper_link` TEXT,
+  `reported_broken` BOOLEAN,
+  `slug` TEXT NOT NULL DEFAULT '',
+  `removed` BOOLEAN NOT NULL DEFAULT 0,
+  PRIMARY KEY (name, timestamp)
+  );
+
+INSERT INTO `features_unidentified` (rowid, name, timestamp, cpp_version, paper_name, paper_link, reported_broken, slug, removed)
+  SELECT id, name, timestamp, cpp_version, paper_name, paper_link, reported_broken, slug, removed FROM `features`;
+
+DROP TABLE `features`;
+ALTER TABLE `features_unidentified` RENAME TO `features`;
+CREATE INDEX `features_slug` ON `features` (slug);
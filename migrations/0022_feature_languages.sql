@@ -0,0 +1,55 @@
+-- +goose Up
+-- features are partitioned by language, so C and C++ features of the same name keep separate histories. everything
+-- stored so far came from the C++ page
+DROP INDEX `features_slug`;
+
+CREATE TABLE `features_languages` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `language` TEXT NOT NULL DEFAULT 'cpp',
+  `name` TEXT,
+  `timestamp` DATETIME,
+  `cpp_version` INT NOT NULL,
+  `paper_name` TEXT,
+  `paper_link` TEXT,
+  `reported_broken` BOOLEAN,
+  `slug` TEXT NOT NULL DEFAULT '',
+  `removed` BOOLEAN NOT NULL DEFAULT 0,
+  UNIQUE (language, name, timestamp)
+  );
+
+INSERT INTO `features_languages` (id, language, name, timestamp, cpp_version, paper_name, paper_link, reported_broken, slug, removed)
+  SELECT id, 'cpp', name, timestamp, cpp_version, paper_name, paper_link, reported_broken, slug, removed FROM `features`;
+
+DROP TABLE `features`;
+ALTER TABLE `features_languages` RENAME TO `features`;
+CREATE INDEX `features_slug` ON `features` (slug);
+
+-- +goose Down
+-- only the C++ partition fits the old table, the history of the other languages is dropped along with what refers to it
+DELETE FROM `compiler_support` WHERE feature_id IN (SELECT id FROM `features` WHERE language<>'cpp');
+DELETE FROM `text_fixes` WHERE feature_id IN (SELECT id FROM `features` WHERE language<>'cpp');
+DELETE FROM `report_posts` WHERE feature_id IN (SELECT id FROM `features` WHERE language<>'cpp');
+DELETE FROM `report_attempts` WHERE feature_id IN (SELECT id FROM `features` WHERE language<>'cpp');
+DELETE FROM `reports` WHERE entry_id IN (SELECT id FROM `features` WHERE language<>'cpp');
+
+DROP INDEX `features_slug`;
+
+CREATE TABLE `features_unpartitioned` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `name` TEXT,
+  `timestamp` DATETIME,
+  `cpp_version` INT NOT NULL,
+  `paper_name` TEXT,
+  `paper_link` TEXT,
+  `reported_broken` BOOLEAN,
+  `slug` TEXT NOT NULL DEFAULT '',
+  `removed` BOOLEAN NOT NULL DEFAULT 0,
+  UNIQUE (name, timestamp)
+  );
+
+INSERT INTO `features_unpartitioned` (id, name, timestamp, cpp_version, paper_name, paper_link, reported_broken, slug, removed)
+  SELECT id, name, timestamp, cpp_version, paper_name, paper_link, reported_broken, slug, removed FROM `features` WHERE language='cpp';
+
+DROP TABLE `features`;
+ALTER TABLE `features_unpartitioned` RENAME TO `features`;
+CREATE INDEX `features_slug` ON `features` (slug);
@@ -0,0 +1,27 @@
+-- +goose Up
+-- features and papers followers would like the bot to keep an eye on, waiting for the maintainer to decide on them
+CREATE TABLE `suggestions` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `source` TEXT NOT NULL,
+  `author` TEXT NOT NULL DEFAULT '',
+  `text` TEXT NOT NULL,
+  `paper` TEXT NOT NULL DEFAULT '',
+  `name` TEXT NOT NULL DEFAULT '',
+  `timestamp` DATETIME NOT NULL,
+  `status` TEXT NOT NULL DEFAULT 'pending',
+  `decided` DATETIME
+  );
+
+-- approved suggestions, matched by paper number or by a piece of the name against new listings
+CREATE TABLE `watchlist` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `paper` TEXT NOT NULL DEFAULT '',
+  `name` TEXT NOT NULL DEFAULT '',
+  `suggestion_id` INTEGER,
+  `added` DATETIME NOT NULL,
+  `listed_entry_id` INTEGER
+  );
+
+-- +goose Down
+DROP TABLE `watchlist`;
+DROP TABLE `suggestions`;
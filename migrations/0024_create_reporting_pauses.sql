@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE `reporting_pauses` (
+  `id` INTEGER NOT NULL PRIMARY KEY CHECK (`id` = 1),
+  `paused_at` DATETIME NOT NULL,
+  `pending` INTEGER NOT NULL,
+  `resumed_at` DATETIME,
+  `acknowledged_id` INTEGER NOT NULL DEFAULT 0
+  );
+
+-- +goose Down
+DROP TABLE `reporting_pauses`;
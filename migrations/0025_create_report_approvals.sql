@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE `report_approvals` (
+  `entry_id` INTEGER NOT NULL,
+  `channel` TEXT NOT NULL,
+  `status` TEXT NOT NULL DEFAULT 'pending',
+  `text` TEXT NOT NULL,
+  `variant` TEXT NOT NULL,
+  `link` TEXT NOT NULL DEFAULT '',
+  `short_link` TEXT NOT NULL DEFAULT '',
+  `created` DATETIME NOT NULL,
+  `decided` DATETIME,
+  PRIMARY KEY (`entry_id`, `channel`)
+  );
+
+-- +goose Down
+DROP TABLE `report_approvals`;
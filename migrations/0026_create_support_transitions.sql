@@ -0,0 +1,20 @@
+-- +goose Up
+-- every change of the support level of a compiler, classified, for analytics. kept apart from the entries so archiving
+-- them leaves the history of transitions whole
+CREATE TABLE `support_transitions` (
+  `entry_id` INTEGER NOT NULL,
+  `language` TEXT NOT NULL,
+  `slug` TEXT NOT NULL,
+  `cpp_version` INTEGER NOT NULL,
+  `compiler` TEXT NOT NULL,
+  `previous_support` INTEGER NOT NULL,
+  `support` INTEGER NOT NULL,
+  `transition` TEXT NOT NULL,
+  `timestamp` DATETIME NOT NULL,
+  PRIMARY KEY (`entry_id`, `compiler`)
+  );
+
+CREATE INDEX `support_transitions_timestamp` ON `support_transitions` (`timestamp`);
+
+-- +goose Down
+DROP TABLE `support_transitions`;
@@ -0,0 +1,19 @@
+-- +goose Up
+-- disagreements between cppreference and the sources it is cross-checked with, open until a check finds them no more
+CREATE TABLE `discrepancies` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `probe` TEXT NOT NULL,
+  `key` TEXT NOT NULL,
+  `language` TEXT NOT NULL,
+  `slug` TEXT NOT NULL,
+  `compiler` TEXT NOT NULL,
+  `problem` TEXT NOT NULL,
+  `evidence` TEXT NOT NULL DEFAULT '',
+  `found` DATETIME NOT NULL,
+  `resolved` DATETIME
+  );
+
+CREATE INDEX `discrepancies_open` ON `discrepancies` (`probe`, `resolved`);
+
+-- +goose Down
+DROP TABLE `discrepancies`;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- the pages of scrapes that parsed differently than the scrape before, gzipped, for looking into bad diffs and parsing
+-- them again with a better parser
+CREATE TABLE `scrapes` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `source` TEXT NOT NULL,
+  `timestamp` DATETIME NOT NULL,
+  `hash` TEXT NOT NULL,
+  `features` INTEGER NOT NULL,
+  `page` BLOB NOT NULL
+  );
+
+CREATE INDEX `scrapes_source` ON `scrapes` (`source`, `timestamp`);
+
+-- +goose Down
+DROP TABLE `scrapes`;
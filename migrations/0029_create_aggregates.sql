@@ -0,0 +1,19 @@
+-- +goose Up
+-- how much of the current features of every standard, of one kind, each compiler supported over time. a row is only
+-- added when the counts changed
+CREATE TABLE `aggregates` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `timestamp` DATETIME NOT NULL,
+  `language` TEXT NOT NULL,
+  `cpp_version` INTEGER NOT NULL,
+  `kind` TEXT NOT NULL,
+  `compiler` TEXT NOT NULL,
+  `full` INTEGER NOT NULL,
+  `partial` INTEGER NOT NULL,
+  `total` INTEGER NOT NULL
+  );
+
+CREATE INDEX `aggregates_key` ON `aggregates` (`language`, `cpp_version`, `kind`, `compiler`, `timestamp`);
+
+-- +goose Down
+DROP TABLE `aggregates`;
@@ -0,0 +1,8 @@
+-- +goose Up
+CREATE TABLE `weekly_summaries` (
+  `timestamp` DATETIME NOT NULL,
+  `changes` INTEGER NOT NULL
+  );
+
+-- +goose Down
+DROP TABLE `weekly_summaries`;
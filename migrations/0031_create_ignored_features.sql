@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE `ignored_features` (
+  `slug` TEXT NOT NULL PRIMARY KEY,
+  `name` TEXT NOT NULL,
+  `reason` TEXT NOT NULL DEFAULT '',
+  `timestamp` DATETIME NOT NULL
+  );
+
+-- +goose Down
+DROP TABLE `ignored_features`;
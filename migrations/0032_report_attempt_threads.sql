@@ -0,0 +1,24 @@
+-- +goose Up
+-- how much of a report thread failed attempts posted, so the next attempt replies to the last tweet instead of posting
+-- the thread from its start again
+ALTER TABLE `report_attempts` ADD COLUMN `thread_posted` INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE `report_attempts` ADD COLUMN `thread_first_id` INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE `report_attempts` ADD COLUMN `thread_last_id` INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+CREATE TABLE `report_attempts_unthreaded` (
+  `feature_id` INTEGER NOT NULL,
+  `channel` TEXT NOT NULL,
+  `attempts` INTEGER NOT NULL DEFAULT 0,
+  `last_error` TEXT NOT NULL DEFAULT '',
+  `last_attempt` DATETIME NOT NULL,
+  `next_attempt` DATETIME NOT NULL,
+  `dead_letter` BOOLEAN NOT NULL DEFAULT 0,
+  PRIMARY KEY (`feature_id`, `channel`)
+  );
+
+INSERT INTO `report_attempts_unthreaded`
+  SELECT feature_id, channel, attempts, last_error, last_attempt, next_attempt, dead_letter FROM `report_attempts`;
+
+DROP TABLE `report_attempts`;
+ALTER TABLE `report_attempts_unthreaded` RENAME TO `report_attempts`;
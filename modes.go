@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+)
+
+// what a running service does, so scraping and reporting can be deployed apart sharing the database, like scraping on
+// a server and reporting from a box with the twitter credentials
+const (
+	modeAll    = "all"    //scrapes and reports
+	modeScrape = "scrape" //only scrapes into the database, along with what keeps the database in shape
+	modeReport = "report" //only reports what another service scraped into the database, and answers mentions
+)
+
+func init() {
+	rootCommand.Flags().String("mode", "", "what to run, all, scrape or report (default is Mode of the config)")
+	bindConfigFlag("Mode", rootCommand.Flags().Lookup("mode"))
+}
+
+// checkMode fails on an unknown Mode, or one splitting the work with another service that can't see the database
+func checkMode(cfg *Configuration) error {
+	switch cfg.Mode {
+	case modeAll:
+		return nil
+	case modeScrape, modeReport:
+		if cfg.StorageMode != "sqlite3" {
+			return fmt.Errorf("Mode %s shares the database with another service, which needs storageMode sqlite3, not %s", cfg.Mode, cfg.StorageMode)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown Mode: %s, expected %s, %s or %s", cfg.Mode, modeAll, modeScrape, modeReport)
+	}
+}
+
+// modeScrapes tells if the service scrapes the sources
+func modeScrapes(cfg *Configuration) bool {
+	return cfg.Mode != modeReport
+}
+
+// modeReports tells if the service reports the changes and does what else posts
+func modeReports(cfg *Configuration) bool {
+	return cfg.Mode != modeScrape
+}
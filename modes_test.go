@@ -0,0 +1,48 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestModes(t *testing.T) {
+	for _, test := range []struct {
+		cfg     Configuration
+		scrapes bool
+		reports bool
+		checks  []string
+	}{
+		{Configuration{Mode: modeAll, StorageMode: "dummy"}, true, true, []string{"database", "reporter", "scrapes", "plugins"}},
+		{Configuration{Mode: modeScrape, StorageMode: "sqlite3"}, true, false, []string{"database", "scrapes", "plugins"}},
+		{Configuration{Mode: modeReport, StorageMode: "sqlite3"}, false, true, []string{"database", "reporter", "scrapes", "plugins"}},
+	} {
+		if err := checkMode(&test.cfg); err != nil {
+			t.Errorf("%v with %v: %v", test.cfg.Mode, test.cfg.StorageMode, err)
+			continue
+		}
+		if modeScrapes(&test.cfg) != test.scrapes || modeReports(&test.cfg) != test.reports {
+			t.Errorf("%v: scrapes %v, reports %v", test.cfg.Mode, modeScrapes(&test.cfg), modeReports(&test.cfg))
+		}
+
+		var checks []string
+		for _, check := range healthChecks(&test.cfg, nil, time.Now(), nil, nil) {
+			checks = append(checks, check.Name)
+		}
+		if !reflect.DeepEqual(checks, test.checks) {
+			t.Errorf("%v: got health checks %v, want %v", test.cfg.Mode, checks, test.checks)
+		}
+	}
+
+	for _, test := range []struct {
+		cfg Configuration
+		err string
+	}{
+		{Configuration{Mode: modeReport, StorageMode: "dummy"}, "Mode report shares the database with another service, which needs storageMode sqlite3, not dummy"},
+		{Configuration{Mode: "tweet", StorageMode: "sqlite3"}, "unknown Mode: tweet, expected all, scrape or report"},
+	} {
+		if err := checkMode(&test.cfg); err == nil || err.Error() != test.err {
+			t.Errorf("%v with %v: got %v, want %v", test.cfg.Mode, test.cfg.StorageMode, err, test.err)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"cppimpbot/digest"
+	"cppimpbot/notify"
+	"fmt"
+
+	"github.com/dghubble/go-twitter/twitter"
+)
+
+// notifierBackends are the ways of reaching the maintainer compiled in, each registered from its own file, which a
+// build tag like notelegram leaves out
+var notifierBackends = map[string]func(cfg *Configuration, client *twitter.Client) (notify.Notifier, error){}
+
+func init() {
+	notifierBackends["twitter"] = twitterNotifier
+	notifierBackends["email"] = emailNotifier
+	notifierBackends["ntfy"] = ntfyNotifier
+	notifierBackends["pushover"] = pushoverNotifier
+}
+
+// twitterNotifier sends the messages to the maintainer as twitter direct messages
+func twitterNotifier(cfg *Configuration, client *twitter.Client) (notify.Notifier, error) {
+	return notify.Func(func(message string) error {
+		return sendMaintainerMessage(cfg, client, message)
+	}), nil
+}
+
+// emailNotifier mails the messages to MaintainerEmail through the SMTP server of the digests
+func emailNotifier(cfg *Configuration, client *twitter.Client) (notify.Notifier, error) {
+	if cfg.SmtpAddress == "" || len(cfg.MaintainerEmail) == 0 {
+		return nil, fmt.Errorf("the email notifier needs SmtpAddress and MaintainerEmail")
+	}
+
+	mailer := &digest.Mailer{Address: cfg.SmtpAddress, Username: cfg.SmtpUsername, Password: cfg.SmtpPassword, From: cfg.DigestFrom}
+	return &notify.Email{Mailer: mailer, To: cfg.MaintainerEmail}, nil
+}
+
+// ntfyNotifier pushes the messages to the NtfyTopic the maintainer is subscribed to
+func ntfyNotifier(cfg *Configuration, client *twitter.Client) (notify.Notifier, error) {
+	return notify.NewNtfy(cfg.NtfyServer, cfg.NtfyTopic, cfg.NtfyToken)
+}
+
+// pushoverNotifier pushes the messages to the devices of PushoverUser through the application of PushoverToken
+func pushoverNotifier(cfg *Configuration, client *twitter.Client) (notify.Notifier, error) {
+	return notify.NewPushover(cfg.PushoverToken, cfg.PushoverUser)
+}
+
+// maintainerNotifiers gives the names of the notifiers in MaintainerNotifiers. if there are none, the maintainer gets
+// the messages in the private Telegram chat if one is configured and as twitter direct messages otherwise
+func maintainerNotifiers(cfg *Configuration) []string {
+	if len(cfg.MaintainerNotifiers) > 0 {
+		return cfg.MaintainerNotifiers
+	}
+
+	if _, ok := notifierBackends["telegram"]; ok && cfg.TelegramBotToken != "" && cfg.TelegramMaintainerChatId != "" {
+		return []string{"telegram"}
+	}
+
+	return []string{"twitter"}
+}
+
+// newNotifier sets up the notifiers the maintainer is reached through, each of which gets every message
+func newNotifier(cfg *Configuration, client *twitter.Client) (notify.Notifier, error) {
+	var result notify.All
+
+	for _, name := range maintainerNotifiers(cfg) {
+		backend, ok := notifierBackends[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown notifier in MaintainerNotifiers or not compiled in: %s", name)
+		}
+
+		notifier, err := backend(cfg, client)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, notifier)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewNotifier(t *testing.T) {
+	//without telegram compiled in, the maintainer chat is messaged on twitter
+	telegram := []string{"twitter"}
+	if _, ok := notifierBackends["telegram"]; ok {
+		telegram = []string{"telegram"}
+	}
+
+	for _, test := range []struct {
+		cfg       Configuration
+		notifiers []string
+		err       string
+	}{
+		{Configuration{}, []string{"twitter"}, ""},
+		{Configuration{TelegramBotToken: "token", TelegramMaintainerChatId: "42"}, telegram, ""},
+		{Configuration{MaintainerNotifiers: []string{"twitter", "ntfy"}, NtfyTopic: "cppimpbot-alerts"}, []string{"twitter", "ntfy"}, ""},
+		{Configuration{MaintainerNotifiers: []string{"email"}}, []string{"email"}, "the email notifier needs SmtpAddress and MaintainerEmail"},
+		{Configuration{MaintainerNotifiers: []string{"ntfy"}}, []string{"ntfy"}, "ntfy needs a topic to publish to"},
+		{Configuration{MaintainerNotifiers: []string{"ntfy", "pushover"}, NtfyTopic: "cppimpbot-alerts", PushoverToken: "app", PushoverUser: "maintainer"},
+			[]string{"ntfy", "pushover"}, ""},
+		{Configuration{MaintainerNotifiers: []string{"pushover"}, PushoverToken: "app"}, []string{"pushover"},
+			"pushover needs the token of an application and the key of the user to push to"},
+		{Configuration{MaintainerNotifiers: []string{"pager"}}, []string{"pager"}, "unknown notifier in MaintainerNotifiers or not compiled in: pager"},
+	} {
+		notifiers := maintainerNotifiers(&test.cfg)
+		if !reflect.DeepEqual(notifiers, test.notifiers) {
+			t.Errorf("%v: got notifiers %v, want %v", test.cfg.MaintainerNotifiers, notifiers, test.notifiers)
+		}
+
+		_, err := newNotifier(&test.cfg, nil)
+		if (err == nil) != (test.err == "") || (err != nil && err.Error() != test.err) {
+			t.Errorf("%v: got %v, want %q", notifiers, err, test.err)
+		}
+	}
+}
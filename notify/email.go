@@ -0,0 +1,23 @@
+package notify
+
+import (
+	"cppimpbot/digest"
+	"fmt"
+	"html"
+	"strings"
+)
+
+// Email mails the messages to the maintainer
+type Email struct {
+	Mailer *digest.Mailer
+	To     []string
+}
+
+func (e *Email) Notify(message string) error {
+	subject := message
+	if i := strings.IndexAny(subject, ".\n"); i > 0 {
+		subject = subject[:i]
+	}
+
+	return e.Mailer.Send(e.To, fmt.Sprintf("cppimpbot: %v", subject), fmt.Sprintf("<pre>%v</pre>", html.EscapeString(message)))
+}
@@ -0,0 +1,38 @@
+// Package notify reaches the maintainer privately when the bot needs attention, like when safe mode paused reporting
+// or a change could not be turned into a report
+package notify
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Notifier sends a message to the maintainer
+type Notifier interface {
+	Notify(message string) error
+}
+
+// Func is a function sending a message to the maintainer
+type Func func(message string) error
+
+func (f Func) Notify(message string) error {
+	return f(message)
+}
+
+// All sends every message through each of its notifiers, failing if any of them did
+type All []Notifier
+
+func (a All) Notify(message string) error {
+	var failures []string
+	for _, notifier := range a {
+		if err := notifier.Notify(message); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("%v of %v notifiers failed: %v", len(failures), len(a), strings.Join(failures, ", "))
+	}
+
+	return nil
+}
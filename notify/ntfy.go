@@ -0,0 +1,67 @@
+package notify
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultNtfyServer is the public ntfy server, topics on it are readable by anyone knowing their name
+	DefaultNtfyServer = "https://ntfy.sh"
+	requestTimeout    = 10 * time.Second
+)
+
+// Ntfy publishes the messages to a topic of an ntfy server, which pushes them to the phones subscribed to it
+type Ntfy struct {
+	server string
+	topic  string
+	token  string
+	client *http.Client
+}
+
+// NewNtfy publishes to the topic on the server, the public one if empty. the access token is only needed for topics
+// that are protected
+func NewNtfy(server string, topic string, token string) (*Ntfy, error) {
+	if topic == "" {
+		return nil, errors.New("ntfy needs a topic to publish to")
+	}
+	if server == "" {
+		server = DefaultNtfyServer
+	}
+
+	return &Ntfy{
+		server: strings.TrimSuffix(server, "/"),
+		topic:  topic,
+		token:  token,
+		client: &http.Client{Timeout: requestTimeout},
+	}, nil
+}
+
+func (n *Ntfy) Notify(message string) error {
+	request, err := http.NewRequest(http.MethodPost, n.server+"/"+n.topic, strings.NewReader(message))
+	if err != nil {
+		return errors.Wrap(err, "invalid ntfy server")
+	}
+	request.Header.Set("Title", "cppimpbot")
+	if n.token != "" {
+		request.Header.Set("Authorization", "Bearer "+n.token)
+	}
+
+	response, err := n.client.Do(request)
+	if err != nil {
+		return errors.Wrap(err, "could not reach ntfy")
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(response.Body)
+		return fmt.Errorf("ntfy answered %v: %s", response.Status, strings.TrimSpace(string(body)))
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package notify
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNtfy(t *testing.T) {
+	var published []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if r.Method != http.MethodPost || r.Header.Get("Title") != "cppimpbot" || r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("got %v %v, title %q, authorization %q", r.Method, r.URL.Path, r.Header.Get("Title"), r.Header.Get("Authorization"))
+		}
+		published = append(published, r.URL.Path+": "+string(body))
+
+		if r.URL.Path == "/closed" {
+			http.Error(w, "forbidden", http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+
+	for _, test := range []struct {
+		topic string
+		err   string
+	}{
+		{"cppimpbot-alerts", ""},
+		{"closed", "1 of 1 notifiers failed: ntfy answered 403 Forbidden: forbidden"},
+	} {
+		ntfy, err := NewNtfy(server.URL+"/", test.topic, "secret")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = All{ntfy}.Notify("Hello! Reporting is paused by safe mode.")
+		if (err == nil) != (test.err == "") || (err != nil && err.Error() != test.err) {
+			t.Errorf("%v: got %v, want %q", test.topic, err, test.err)
+		}
+	}
+
+	want := []string{"/cppimpbot-alerts: Hello! Reporting is paused by safe mode.", "/closed: Hello! Reporting is paused by safe mode."}
+	if len(published) != 2 || published[0] != want[0] || published[1] != want[1] {
+		t.Errorf("got %q, want %q", published, want)
+	}
+
+	if _, err := NewNtfy("", "", ""); err == nil {
+		t.Errorf("ntfy without a topic was set up")
+	}
+}
@@ -0,0 +1,68 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const pushoverUrl = "https://api.pushover.net/1/messages.json"
+
+// Pushover pushes the messages to the devices of a Pushover user through the application of the token
+type Pushover struct {
+	token  string
+	user   string
+	client *http.Client
+}
+
+func NewPushover(token string, user string) (*Pushover, error) {
+	if token == "" || user == "" {
+		return nil, errors.New("pushover needs the token of an application and the key of the user to push to")
+	}
+
+	return &Pushover{token: token, user: user, client: &http.Client{Timeout: requestTimeout}}, nil
+}
+
+type pushoverResponse struct {
+	Status int      `json:"status"`
+	Errors []string `json:"errors"`
+}
+
+func (p *Pushover) Notify(message string) error {
+	values := url.Values{
+		"token":   {p.token},
+		"user":    {p.user},
+		"title":   {"cppimpbot"},
+		"message": {limitRunes(message, 1024)},
+	}
+
+	response, err := p.client.PostForm(pushoverUrl, values)
+	if err != nil {
+		return errors.Wrap(err, "could not reach pushover")
+	}
+	defer response.Body.Close()
+
+	result := pushoverResponse{}
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return errors.Wrapf(err, "could not decode pushover answer to %v", response.Status)
+	}
+
+	if result.Status != 1 {
+		return errors.Errorf("pushover refused the message: %v", strings.Join(result.Errors, ", "))
+	}
+
+	return nil
+}
+
+// limitRunes cuts the message to the length a push allows, as pushover refuses longer ones
+func limitRunes(message string, limit int) string {
+	runes := []rune(message)
+	if len(runes) <= limit {
+		return message
+	}
+
+	return string(runes[:limit-1]) + "…"
+}
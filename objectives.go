@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"cppimpbot/slo"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var objectivesCommand = &cobra.Command{
+	Use:   "objectives",
+	Short: "Print how the configured service level objectives fare over the objective window",
+	RunE:  objectivesCmdFunc,
+}
+
+func validateObjectives(cfg *Configuration) error {
+	names := map[string]bool{}
+
+	for i := range cfg.Objectives {
+		if err := cfg.Objectives[i].Validate(); err != nil {
+			return err
+		}
+
+		if names[cfg.Objectives[i].Name] {
+			return fmt.Errorf("objective %v configured more than once", cfg.Objectives[i].Name)
+		}
+		names[cfg.Objectives[i].Name] = true
+	}
+
+	return nil
+}
+
+// recordScrape stores the outcome of a scrape for the scrape_success objectives covering the source
+func recordScrape(cfg *Configuration, service compliance.Service, source string, good bool) {
+	for _, objective := range cfg.Objectives {
+		if objective.Kind != slo.KindScrapeSuccess || (objective.Source != "" && objective.Source != source) {
+			continue
+		}
+
+		if err := service.StoreObjectiveEvent(context.Background(), objective.Name, good); err != nil {
+			slog.Error("error storing event of objective", "objective", objective.Name, "err", err)
+		}
+	}
+}
+
+// recordPostLatency stores how long a change took from being scraped to being posted for the post_latency objectives
+func recordPostLatency(cfg *Configuration, service compliance.Service, latency time.Duration) {
+	for _, objective := range cfg.Objectives {
+		if objective.Kind != slo.KindPostLatency {
+			continue
+		}
+
+		if err := service.StoreObjectiveEvent(context.Background(), objective.Name, objective.Good(latency)); err != nil {
+			slog.Error("error storing event of objective", "objective", objective.Name, "err", err)
+		}
+	}
+}
+
+func objectiveWindow(cfg *Configuration) time.Duration {
+	return time.Duration(cfg.ObjectiveWindow) * 24 * time.Hour
+}
+
+// objectiveStatuses counts the events of every objective over the objective window
+func objectiveStatuses(cfg *Configuration, service compliance.Service) ([]slo.Status, error) {
+	since := time.Now().Add(-objectiveWindow(cfg))
+
+	var result []slo.Status
+	for _, objective := range cfg.Objectives {
+		good, total, err := service.GetObjectiveCounts(context.Background(), objective.Name, since)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, slo.Status{Objective: objective, Good: good, Total: total})
+	}
+
+	return result, nil
+}
+
+// objectivesReportJob logs how the objectives fare, and announces it on the reporters that take reports about the bot,
+// like Slack
+func objectivesReportJob(cfg *Configuration, service compliance.Service, channels reporters) func() {
+	return func() {
+		statuses, err := objectiveStatuses(cfg, service)
+		if err != nil {
+			slog.Error("error getting the status of the objectives", "err", err)
+			return
+		}
+
+		report := slo.Report(statuses, objectiveWindow(cfg))
+		slog.Info(report)
+
+		channels.Announce(report)
+	}
+}
+
+// errorBudgetJob alerts the maintainer when the error budget of an objective runs out. an objective is only alerted
+// about again once its budget recovered in between
+func errorBudgetJob(cfg *Configuration, service compliance.Service, alert func(message string)) func() {
+	alerted := map[string]bool{}
+
+	return func() {
+		statuses, err := objectiveStatuses(cfg, service)
+		if err != nil {
+			slog.Error("error getting the status of the objectives", "err", err)
+			return
+		}
+
+		for i := range statuses {
+			status := &statuses[i]
+			name := status.Objective.Name
+
+			if !status.Exhausted() {
+				alerted[name] = false
+				continue
+			}
+
+			if alerted[name] {
+				continue
+			}
+
+			alert(fmt.Sprintf("Hello! The error budget of objective %v is exhausted: %.2f%% of %v events were good over the last %v days, the target is %.2f%%.",
+				name, 100*status.Compliance(), status.Total, cfg.ObjectiveWindow, 100*status.Objective.Target))
+			alerted[name] = true
+		}
+	}
+}
+
+func objectivesCmdFunc(cmd *cobra.Command, args []string) error {
+	cfg := &Configuration{}
+
+	if err := viper.Unmarshal(cfg); err != nil {
+		return err
+	}
+
+	if cfg.StorageMode != "sqlite3" {
+		return fmt.Errorf("objectives needs storageMode sqlite3, not %s", cfg.StorageMode)
+	}
+
+	if err := validateObjectives(cfg); err != nil {
+		return err
+	}
+
+	if len(cfg.Objectives) == 0 {
+		return fmt.Errorf("no Objectives are configured")
+	}
+
+	complianceStorageService, err := openSqliteService(cfg)
+	if err != nil {
+		return err
+	}
+	defer complianceStorageService.Close(context.Background())
+
+	statuses, err := objectiveStatuses(cfg, complianceStorageService)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(slo.Report(statuses, objectiveWindow(cfg)))
+
+	return nil
+}
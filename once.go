@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/schedule"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// exit codes of a run with --once, so whatever runs the bot on a schedule can tell how it went. if several things
+// went wrong the highest code is used
+const (
+	exitFailed          = 1 //the bot could not run at all, like with an invalid config
+	exitScrapeFailed    = 2 //scraping a source failed, the changes of the other sources were reported all the same
+	exitReportFailed    = 3 //changes could not be reported, or what to report could not be looked at
+	exitReportingPaused = 4 //reporting is paused by safe mode until the resume-reporting command
+)
+
+var runOnce bool
+
+func init() {
+	rootCommand.Flags().BoolVar(&runOnce, "once", false, "scrape every source, report what changed and exit instead of running on a schedule, for cron jobs and CI")
+}
+
+// exitCodeError ends the bot with the given exit code instead of 1
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+func (e *exitCodeError) Error() string {
+	return e.err.Error()
+}
+
+// exitCode gives the code the bot exits with after the error of the command that ran, 0 if there was none
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var codeErr *exitCodeError
+	if errors.As(err, &codeErr) {
+		return codeErr.code
+	}
+
+	return exitFailed
+}
+
+// onceResult turns how the scrapes and the run of the reporter went into the error the bot exits with, nil if all
+// went well. reports held back by the pace or waiting for approval are posted by a later run, so they don't count
+func onceResult(failedScrapes []string, run reportRun) error {
+	var problems []string
+	code := 0
+
+	if len(failedScrapes) > 0 {
+		problems = append(problems, fmt.Sprintf("scraping failed for %v", strings.Join(failedScrapes, ", ")))
+		code = exitScrapeFailed
+	}
+	if run.failed > 0 {
+		problems = append(problems, fmt.Sprintf("%v changes could not be reported", run.failed))
+		code = exitReportFailed
+	}
+	if run.incomplete || run.stop {
+		problems = append(problems, "could not look at what to report")
+		code = exitReportFailed
+	}
+	if run.paused {
+		problems = append(problems, "reporting is paused by safe mode")
+		code = exitReportingPaused
+	}
+
+	if code == 0 {
+		return nil
+	}
+
+	return &exitCodeError{code: code, err: fmt.Errorf("%s", strings.Join(problems, ", "))}
+}
+
+// runOnceCycle scrapes every source one after the other and runs the reporter once, instead of starting the schedule.
+// the other periodic jobs like the release feeds and digests don't run. the reporter is nil if the mode doesn't report
+func runOnceCycle(scrapeJobs []schedule.Job, scrapes *scrapeHealth, reporter *twitterReporter) error {
+	slog.Info("running once", "sources", len(scrapeJobs))
+
+	for _, job := range scrapeJobs {
+		slog.Info("running job once", "job", job.Name)
+		job.Run()
+	}
+
+	failedScrapes := scrapes.failures()
+	var run reportRun
+	if reporter != nil {
+		run = reporter.run(context.Background())
+	}
+
+	err := onceResult(failedScrapes, run)
+	if err != nil {
+		slog.Error("run once finished with problems", "exitCode", exitCode(err), "err", err)
+	} else {
+		slog.Info("run once finished")
+	}
+
+	return err
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"cppimpbot/limits"
+	"cppimpbot/notify"
+	"cppimpbot/schedule"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newOnceReporter sets up a reporter of two new features, with the messages to the maintainer kept in notified
+func newOnceReporter(t *testing.T, cfg *Configuration, notified *[]string) *twitterReporter {
+	t.Helper()
+
+	templates, err := compliance.NewReportTemplates(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hooks, err := compliance.LoadHooks("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pace, err := limits.NewPace(0, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	service := compliance.NewMemoryService()
+	for _, name := range []string{"Modules", "Coroutines"} {
+		feature := &compliance.Feature{Name: name, CppVersion: 20, Compilers: []compliance.CompilerSupport{testSupport("gcc", 1, "11", "")}}
+		if err := service.CreateEntry(context.Background(), feature); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	notifier := notify.Func(func(message string) error {
+		*notified = append(*notified, message)
+		return nil
+	})
+
+	return &twitterReporter{cfg: cfg, service: service, templates: templates, hooks: hooks, pace: pace,
+		notifier: notifier, alert: func(message string) {}, scrapeScheduler: schedule.New()}
+}
+
+func TestRunOnce(t *testing.T) {
+	var notified []string
+	paused := newOnceReporter(t, &Configuration{SafeMode: true, SafeModeMaxReports: 1}, &notified).run(context.Background())
+	if paused != (reportRun{paused: true}) || exitCode(onceResult(nil, paused)) != exitReportingPaused {
+		t.Errorf("safe mode: got %+v", paused)
+	}
+	if len(notified) != 1 || !strings.Contains(notified[0], "There were too many reports for safe mode (limit is 1)") {
+		t.Errorf("safe mode: notified %q", notified)
+	}
+
+	reporter := newOnceReporter(t, &Configuration{SupressReporting: true}, &notified)
+	suppressed := reporter.run(context.Background())
+	unreported, err := reporter.service.GetUnreported(context.Background(), compliance.ChannelTwitter)
+	if suppressed != (reportRun{}) || len(unreported) != 0 || err != nil {
+		t.Errorf("suppressed: got %+v with %v left unreported, %v", suppressed, len(unreported), err)
+	}
+
+	//a reporter shutting down leaves the entries to the next run
+	shuttingDown, cancel := context.WithCancel(context.Background())
+	cancel()
+	reporter = newOnceReporter(t, &Configuration{SupressReporting: true}, &notified)
+	stopped := reporter.run(shuttingDown)
+	unreported, err = reporter.service.GetUnreported(context.Background(), compliance.ChannelTwitter)
+	if stopped != (reportRun{incomplete: true}) || len(unreported) != 2 || err != nil {
+		t.Errorf("shutting down: got %+v with %v left unreported, %v", stopped, len(unreported), err)
+	}
+
+	//a dry run posts nothing, so it leaves the pace to the real posts
+	reporter = newOnceReporter(t, &Configuration{ReportBatching: true, DryReporting: true}, &notified)
+	if reporter.pace, err = limits.NewPace(0, time.Hour, ""); err != nil {
+		t.Fatal(err)
+	}
+	if dry := reporter.run(context.Background()); dry != (reportRun{}) {
+		t.Errorf("dry batch: got %+v", dry)
+	}
+	if wait, why := reporter.pace.Wait(time.Now()); wait > 0 {
+		t.Errorf("dry batch: counted against the pace, waiting %v for %v", wait, why)
+	}
+
+	scrapes := newScrapeHealth(time.Now())
+	scrapes.add("cppreference", schedule.Job{Interval: time.Hour})
+	scrapes.add("mirror", schedule.Job{Interval: time.Hour})
+	scrapes.record("cppreference", nil, time.Now())
+	scrapes.record("mirror", fmt.Errorf("connection refused"), time.Now())
+
+	for _, test := range []struct {
+		what   string
+		result error
+		code   int
+		err    string
+	}{
+		{"all went well", onceResult(nil, suppressed), 0, ""},
+		{"failed scrape", onceResult(scrapes.failures(), suppressed), exitScrapeFailed, "scraping failed for mirror: connection refused"},
+		{"failed reports", onceResult(scrapes.failures(), reportRun{failed: 2}), exitReportFailed,
+			"scraping failed for mirror: connection refused, 2 changes could not be reported"},
+		{"incomplete run", onceResult(nil, reportRun{incomplete: true}), exitReportFailed, "could not look at what to report"},
+	} {
+		if code := exitCode(test.result); code != test.code {
+			t.Errorf("%v: got exit code %v, want %v", test.what, code, test.code)
+		}
+		if (test.result == nil) != (test.err == "") || (test.result != nil && test.result.Error() != test.err) {
+			t.Errorf("%v: got %v, want %q", test.what, test.result, test.err)
+		}
+	}
+}
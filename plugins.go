@@ -0,0 +1,102 @@
+package main
+
+import (
+	"cppimpbot/compliance"
+	"cppimpbot/plugins"
+	"fmt"
+	"log/slog"
+)
+
+// startPlugins starts the configured plugins, stopping those already started if one fails, as the sources and
+// reporters the config relies on would be missing
+func startPlugins(cfg *Configuration) ([]*plugins.Plugin, error) {
+	var started []*plugins.Plugin
+
+	for _, config := range cfg.Plugins {
+		if _, ok := builtinSource(config.Name); ok {
+			stopPlugins(started)
+			return nil, fmt.Errorf("plugin name %v is taken by a built-in source", config.Name)
+		}
+		for _, plugin := range started {
+			if plugin.Name == config.Name {
+				stopPlugins(started)
+				return nil, fmt.Errorf("plugin %v configured more than once", config.Name)
+			}
+		}
+
+		plugin, err := plugins.Start(config)
+		if err != nil {
+			stopPlugins(started)
+			return nil, err
+		}
+		slog.Info("started plugin", "plugin", plugin.Name, "report", plugin.Can(plugins.CapabilityReport), "scrape", plugin.Can(plugins.CapabilityScrape))
+
+		started = append(started, plugin)
+	}
+
+	return started, nil
+}
+
+func stopPlugins(started []*plugins.Plugin) {
+	for _, plugin := range started {
+		slog.Info("stopping plugin", "plugin", plugin.Name)
+		if err := plugin.Stop(); err != nil {
+			slog.Warn("plugin exited with error", "plugin", plugin.Name, "err", err)
+		}
+	}
+}
+
+// reportChangeToPlugins hands the change to the plugins that report changes, or only logs it if a dry run. text is
+// what is tweeted about the change, changes without one are skipped. failing plugins are only logged, as the tweet
+// decides if the change counts as reported
+func reportChangeToPlugins(cfg *Configuration, started []*plugins.Plugin, change compliance.Change, text string) {
+	if text == "" {
+		return
+	}
+
+	for _, plugin := range started {
+		if !plugin.Can(plugins.CapabilityReport) {
+			continue
+		}
+
+		if cfg.DryReporting {
+			slog.Info("Dry run: reporting to plugin", "channel", plugin.Name, "feature", change.Feature.Name)
+			continue
+		}
+
+		if err := plugin.Report(plugins.NewChangeEvent(change, text)); err != nil {
+			slog.Error("error reporting change to plugin", "channel", plugin.Name, "feature", change.Feature.Name, "err", err)
+		}
+	}
+}
+
+// pluginSource gives the scrape function of the source plugin configured under the name, if there is one
+func pluginSource(started []*plugins.Plugin, name string) (func(cfg *Configuration, complianceStorageService compliance.Service, alert func(message string)) error, bool) {
+	for _, plugin := range started {
+		if plugin.Name != name || !plugin.Can(plugins.CapabilityScrape) {
+			continue
+		}
+
+		source := plugin
+		return func(cfg *Configuration, complianceStorageService compliance.Service, alert func(message string)) error {
+			result, err := source.Scrape()
+			if err != nil {
+				slog.Error("error when scraping plugin", "source", source.Name, "err", err)
+				return err
+			}
+
+			scraped, err := result.CppSupport()
+			if err != nil {
+				slog.Error("plugin scraped invalid data", "source", source.Name, "err", err)
+				return err
+			}
+
+			checkFingerprint(cfg, complianceStorageService, source.Name, scraped, alert)
+			storeScraped(complianceStorageService, scraped)
+
+			return nil
+		}, true
+	}
+
+	return nil, false
+}
@@ -0,0 +1,239 @@
+package plugins
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultTimeout = 30 * time.Second
+	stopTimeout    = 5 * time.Second //how long a plugin gets to exit once its input is closed
+)
+
+// Config is a plugin binary started along the bot
+type Config struct {
+	Name    string
+	Path    string
+	Args    []string
+	Timeout int //seconds a call may take, 30 if not set
+}
+
+// Plugin is a started plugin binary, talked to over its standard input and output
+type Plugin struct {
+	Name string
+
+	capabilities map[string]bool
+	timeout      time.Duration
+
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	exited  chan struct{} //closed once the plugin closed its output
+	readers sync.WaitGroup
+
+	mutex   sync.Mutex
+	nextID  int64
+	pending map[int64]chan response
+	closed  bool
+}
+
+// Start starts the plugin binary and asks it what it can do
+func Start(config Config) (*Plugin, error) {
+	if config.Name == "" || config.Path == "" {
+		return nil, fmt.Errorf("plugins need a name and the path of their binary")
+	}
+
+	cmd := exec.Command(config.Path, config.Args...)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not set up the input of plugin %v", config.Name)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not set up the output of plugin %v", config.Name)
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not set up the error output of plugin %v", config.Name)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, errors.Wrapf(err, "could not start plugin %v", config.Name)
+	}
+
+	plugin := &Plugin{
+		Name:         config.Name,
+		capabilities: map[string]bool{},
+		timeout:      time.Duration(config.Timeout) * time.Second,
+		cmd:          cmd,
+		stdin:        stdin,
+		exited:       make(chan struct{}),
+		pending:      map[int64]chan response{},
+	}
+	if plugin.timeout <= 0 {
+		plugin.timeout = defaultTimeout
+	}
+
+	plugin.readers.Add(2)
+	go plugin.readResponses(stdout)
+	go plugin.logErrors(stderr)
+
+	result := InitResult{}
+	if err := plugin.call(MethodInit, InitParams{Protocol: ProtocolVersion, Name: config.Name}, &result); err != nil {
+		plugin.Stop()
+		return nil, errors.Wrapf(err, "could not initialize plugin %v", config.Name)
+	}
+
+	for _, capability := range result.Capabilities {
+		if capability != CapabilityReport && capability != CapabilityScrape {
+			slog.Warn("plugin announced unknown capability, ignoring it", "plugin", config.Name, "capability", capability)
+			continue
+		}
+		plugin.capabilities[capability] = true
+	}
+
+	return plugin, nil
+}
+
+// Can tells if the plugin announced the capability
+func (p *Plugin) Can(capability string) bool {
+	return p.capabilities[capability]
+}
+
+// Exited tells if the plugin closed its output, after which calls to it fail
+func (p *Plugin) Exited() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.closed
+}
+
+// Report hands a change about to be reported to the plugin
+func (p *Plugin) Report(event ChangeEvent) error {
+	return p.call(MethodReport, event, nil)
+}
+
+// Scrape asks the plugin to scrape its source
+func (p *Plugin) Scrape() (ScrapeResult, error) {
+	result := ScrapeResult{}
+	err := p.call(MethodScrape, nil, &result)
+
+	return result, err
+}
+
+// Stop closes the input of the plugin, which tells it to exit, and kills it if it doesn't in time
+func (p *Plugin) Stop() error {
+	p.stdin.Close()
+
+	select {
+	case <-p.exited:
+	case <-time.After(stopTimeout):
+		slog.Warn("plugin did not exit in time, killing it", "plugin", p.Name)
+		p.cmd.Process.Kill()
+	}
+
+	//the pipes are closed by Wait, so it has to wait for them to be read
+	p.readers.Wait()
+
+	return p.cmd.Wait()
+}
+
+func (p *Plugin) call(method string, params interface{}, result interface{}) error {
+	answer := make(chan response, 1)
+
+	p.mutex.Lock()
+	if p.closed {
+		p.mutex.Unlock()
+		return fmt.Errorf("plugin %v exited", p.Name)
+	}
+	p.nextID++
+	id := p.nextID
+	p.pending[id] = answer
+
+	data, err := json.Marshal(request{ID: id, Method: method, Params: params})
+	if err == nil {
+		_, err = p.stdin.Write(append(data, '\n'))
+	}
+	if err != nil {
+		delete(p.pending, id)
+	}
+	p.mutex.Unlock()
+
+	if err != nil {
+		return errors.Wrapf(err, "could not send %v to plugin %v", method, p.Name)
+	}
+
+	select {
+	case response, ok := <-answer:
+		if !ok {
+			return fmt.Errorf("plugin %v exited", p.Name)
+		}
+		if response.Error != "" {
+			return fmt.Errorf("plugin %v failed %v: %v", p.Name, method, response.Error)
+		}
+		if result == nil || len(response.Result) == 0 {
+			return nil
+		}
+		return errors.Wrapf(json.Unmarshal(response.Result, result), "could not decode the answer of plugin %v to %v", p.Name, method)
+	case <-time.After(p.timeout):
+		p.mutex.Lock()
+		delete(p.pending, id)
+		p.mutex.Unlock()
+		return fmt.Errorf("plugin %v did not answer %v within %v", p.Name, method, p.timeout)
+	}
+}
+
+// readResponses hands the responses of the plugin to the calls waiting for them, until the plugin closes its output
+func (p *Plugin) readResponses(stdout io.Reader) {
+	defer p.readers.Done()
+
+	decoder := json.NewDecoder(stdout)
+
+	for {
+		response := response{}
+		if err := decoder.Decode(&response); err != nil {
+			if err != io.EOF {
+				slog.Error("error reading the output of plugin, no longer listening to it", "plugin", p.Name, "err", err)
+			}
+			break
+		}
+
+		p.mutex.Lock()
+		answer, ok := p.pending[response.ID]
+		delete(p.pending, response.ID)
+		p.mutex.Unlock()
+
+		if !ok {
+			slog.Warn("plugin answered unknown or timed out call", "plugin", p.Name, "call", response.ID)
+			continue
+		}
+		answer <- response
+	}
+
+	p.mutex.Lock()
+	p.closed = true
+	for id, answer := range p.pending {
+		close(answer)
+		delete(p.pending, id)
+	}
+	p.mutex.Unlock()
+
+	close(p.exited)
+}
+
+func (p *Plugin) logErrors(stderr io.Reader) {
+	defer p.readers.Done()
+
+	scanner := bufio.NewScanner(stderr)
+	for scanner.Scan() {
+		slog.Info(scanner.Text(), "plugin", p.Name)
+	}
+}
@@ -0,0 +1,196 @@
+package plugins
+
+import (
+	"cppimpbot/compliance"
+	"cppimpbot/scraper"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ProtocolVersion is sent to every plugin when it is started, for it to refuse versions it doesn't speak
+const ProtocolVersion = 1
+
+// methods the core calls. the messages are JSON objects, one per line, written to the standard input of the plugin,
+// which answers each with a response of the same id on its standard output. what it writes to standard error is logged
+const (
+	MethodInit   = "init"   //params InitParams, result InitResult
+	MethodReport = "report" //params ChangeEvent, result ignored
+	MethodScrape = "scrape" //no params, result ScrapeResult
+)
+
+// capabilities a plugin announces in its InitResult
+const (
+	CapabilityReport = "report" //wants to hear about every reported change
+	CapabilityScrape = "scrape" //can be configured as a source
+)
+
+type request struct {
+	ID     int64       `json:"id"`
+	Method string      `json:"method"`
+	Params interface{} `json:"params,omitempty"`
+}
+
+type response struct {
+	ID     int64           `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  string          `json:"error"`
+}
+
+// InitParams tells a plugin the protocol version and the name it is configured under
+type InitParams struct {
+	Protocol int    `json:"protocol"`
+	Name     string `json:"name"`
+}
+
+// InitResult is what the plugin can do
+type InitResult struct {
+	Capabilities []string `json:"capabilities"`
+}
+
+// Support is the support of a compiler for a feature
+type Support struct {
+	Compiler string `json:"compiler"`
+	Support  string `json:"support"` //yes, partial or no
+	Display  string `json:"display,omitempty"`
+	Extra    string `json:"extra,omitempty"`
+}
+
+// Delta is how the support of a compiler changed. From is empty for new listings
+type Delta struct {
+	Compiler string   `json:"compiler"`
+	From     *Support `json:"from,omitempty"`
+	To       Support  `json:"to"`
+}
+
+// ChangeEvent is a change about to be reported, with the text the bot would post about it
+type ChangeEvent struct {
+	EntryID      int64     `json:"entry_id"`
+	Kind         string    `json:"kind"`
+	Language     string    `json:"language"`
+	Standard     string    `json:"standard"`
+	Name         string    `json:"name"`
+	PreviousName string    `json:"previous_name,omitempty"`
+	Slug         string    `json:"slug"`
+	Paper        string    `json:"paper,omitempty"`
+	PaperLink    string    `json:"paper_link,omitempty"`
+	Timestamp    time.Time `json:"timestamp"`
+	Deltas       []Delta   `json:"deltas"`
+	Text         string    `json:"text"`
+}
+
+// ScrapedFeature is a row of a table a source plugin scraped
+type ScrapedFeature struct {
+	Name      string    `json:"name"`
+	Paper     string    `json:"paper,omitempty"`
+	PaperLink string    `json:"paper_link,omitempty"`
+	Support   []Support `json:"support"`
+}
+
+// ScrapedTable is the features of a standard revision. Compiler of their support is the column header, like the one on
+// cppreference, or the compiler key
+type ScrapedTable struct {
+	Language string           `json:"language"` //cpp, c or cppdr
+	Version  int              `json:"version"`  //two digit year of the revision, like 23
+	Library  bool             `json:"library"`  //the columns are standard library implementations instead of compilers
+	Features []ScrapedFeature `json:"features"`
+}
+
+// ScrapeResult is what a source plugin scraped
+type ScrapeResult struct {
+	Tables []ScrapedTable `json:"tables"`
+}
+
+func supportOf(support compliance.CompilerSupport) Support {
+	return Support{
+		Compiler: support.Compiler,
+		Support:  compliance.SupportLevelName(support.Support),
+		Display:  support.DisplayText.String,
+		Extra:    support.ExtraText.String,
+	}
+}
+
+// NewChangeEvent describes a change for plugins, text being what the bot posts about it
+func NewChangeEvent(change compliance.Change, text string) ChangeEvent {
+	feature := change.Feature
+	event := ChangeEvent{
+		EntryID:   feature.Id,
+		Kind:      change.Kind,
+		Language:  feature.Language,
+		Standard:  feature.Standard(),
+		Name:      feature.Name,
+		Slug:      feature.Slug,
+		Paper:     feature.PaperName.String,
+		PaperLink: feature.PaperLink.String,
+		Timestamp: feature.Timestamp,
+		Deltas:    []Delta{},
+		Text:      text,
+	}
+
+	if change.Previous != nil {
+		event.PreviousName = change.Previous.Name
+	}
+
+	for _, delta := range change.Deltas {
+		converted := Delta{Compiler: delta.Compiler, To: supportOf(delta.Next)}
+		if change.Kind != compliance.ChangeNewListing {
+			from := supportOf(delta.Previous)
+			converted.From = &from
+		}
+		event.Deltas = append(event.Deltas, converted)
+	}
+
+	return event
+}
+
+func supportLevel(name string) (int, error) {
+	switch name {
+	case "yes":
+		return compliance.SupportYes, nil
+	case "partial":
+		return compliance.SupportPartial, nil
+	case "no":
+		return compliance.SupportNo, nil
+	default:
+		return 0, fmt.Errorf("unknown support '%v'", name)
+	}
+}
+
+// CppSupport turns what a source plugin scraped into what the scrapers of the bot give, to be stored the same way
+func (r *ScrapeResult) CppSupport() (scraper.CppSupport, error) {
+	result := scraper.CppSupport{}
+
+	for _, table := range r.Tables {
+		if !compliance.IsLanguage(table.Language) {
+			return result, fmt.Errorf("unknown language '%v'", table.Language)
+		}
+
+		version := scraper.CppVersionSupport{Language: table.Language, Version: table.Version, Library: table.Library, DefectReports: table.Language == scraper.LanguageCppDR}
+		for _, feature := range table.Features {
+			if feature.Name == "" {
+				return result, fmt.Errorf("feature without name in %v", compliance.StandardName(table.Language, table.Version))
+			}
+
+			scraped := scraper.CppFeature{Name: feature.Name, PaperName: feature.Paper, PaperLink: feature.PaperLink}
+			for _, support := range feature.Support {
+				level, err := supportLevel(support.Support)
+				if err != nil {
+					return result, fmt.Errorf("%v of '%v': %v", support.Compiler, feature.Name, err)
+				}
+
+				scraped.Compilers = append(scraped.Compilers, scraper.CompilerSupport{
+					Compiler:      support.Compiler,
+					Support:       level,
+					DisplayString: support.Display,
+					ExtraString:   support.Extra,
+				})
+			}
+
+			version.Features = append(version.Features, scraped)
+		}
+
+		result.Versions = append(result.Versions, version)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"cppimpbot/limits"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var preflightCommand = &cobra.Command{
+	Use:   "preflight",
+	Short: "Check how the unreported changes fit the length limits of every target before they are posted",
+	Long: `Check how the unreported changes fit the length limits of every target before they are posted.
+Every change is counted the way each target counts, and changes that don't fit a single post are listed with
+the amount of posts they are split into, as a thread where the target has threads.`,
+	RunE: preflightCmdFunc,
+}
+
+var preflightVerbose bool
+
+func init() {
+	preflightCommand.Flags().BoolVarP(&preflightVerbose, "verbose", "v", false, "print the posts of changes that are split")
+}
+
+func preflightCmdFunc(cmd *cobra.Command, args []string) error {
+	cfg := &Configuration{}
+
+	if err := viper.Unmarshal(cfg); err != nil {
+		return err
+	}
+
+	if cfg.StorageMode != "sqlite3" {
+		return fmt.Errorf("preflight needs storageMode sqlite3, not %s", cfg.StorageMode)
+	}
+
+	complianceStorageService, err := openSqliteService(cfg)
+	if err != nil {
+		return err
+	}
+	defer complianceStorageService.Close(context.Background())
+
+	reportOptions, err := loadReportOptions(cfg)
+	if err != nil {
+		return err
+	}
+
+	entries, err := complianceStorageService.GetUnreported(context.Background(), compliance.ChannelTwitter)
+	if err != nil {
+		return err
+	}
+
+	split := 0
+	for i := range entries {
+		previous, err := complianceStorageService.GetPreviousEntry(context.Background(), entries[i].Id)
+		if err != nil {
+			return err
+		}
+
+		change, err := compliance.DiffFeatures(previous, &entries[i])
+		if err != nil {
+			fmt.Printf("'%v': can't be turned into a report: %v\n", entries[i].Name, err)
+			continue
+		}
+		change = reportedChange(cfg, change)
+
+		text := compliance.ChangeReportText(reportOptions, change)
+		if text == "" {
+			continue
+		}
+
+		for _, target := range limits.Targets {
+			if target.Fits(text) {
+				continue
+			}
+
+			posts := target.Split(text)
+			form := "posts"
+			if target.Threads {
+				form = "thread"
+			}
+
+			fmt.Printf("'%v': %v of %v on %v, posted as a %v-part %v\n", entries[i].Name, target.Count(text), target.Limit, target.Name, len(posts), form)
+			if preflightVerbose {
+				for _, post := range posts {
+					fmt.Printf("  | %v\n", post)
+				}
+			}
+			split++
+		}
+	}
+
+	fmt.Printf("%v unreported changes, %v times they don't fit a single post of a target\n", len(entries), split)
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"cppimpbot/util"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var queryCommand = &cobra.Command{
+	Use:   "query <sql> [args...]",
+	Short: "Run a read-only SELECT against the database, with the args bound in order",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  queryCmdFunc,
+}
+
+var queryJson bool
+
+func init() {
+	queryCommand.Flags().BoolVar(&queryJson, "json", false, "print the result as JSON instead of a table")
+}
+
+func printQueryTable(result *compliance.QueryResult) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(writer, strings.Join(result.Columns, "\t"))
+	for _, row := range result.Rows {
+		values := make([]string, len(row))
+		for i, value := range row {
+			if value == nil {
+				values[i] = "NULL"
+			} else {
+				values[i] = fmt.Sprint(value)
+			}
+		}
+		fmt.Fprintln(writer, strings.Join(values, "\t"))
+	}
+
+	writer.Flush()
+}
+
+func queryCmdFunc(cmd *cobra.Command, args []string) error {
+	cfg := &Configuration{}
+
+	if err := viper.Unmarshal(cfg); err != nil {
+		return err
+	}
+
+	if cfg.StorageMode != "sqlite3" {
+		return fmt.Errorf("query needs storageMode sqlite3, not %s", cfg.StorageMode)
+	}
+
+	db, err := util.SqliteConnectReadOnly(cfg.Database)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var queryArgs []interface{}
+	for _, arg := range args[1:] {
+		queryArgs = append(queryArgs, arg)
+	}
+
+	result, err := compliance.NewSqliteService(db).QueryReadOnly(context.Background(), args[0], queryArgs, cfg.ExploreMaxRows)
+	if err != nil {
+		return err
+	}
+
+	if queryJson {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	}
+
+	printQueryTable(result)
+	if result.Truncated {
+		fmt.Printf("(only the first %v rows, see ExploreMaxRows)\n", cfg.ExploreMaxRows)
+	}
+
+	return nil
+}
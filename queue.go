@@ -0,0 +1,272 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"cppimpbot/limits"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var queueCommand = &cobra.Command{
+	Use:   "queue",
+	Short: "List the entries waiting to be reported, with where they go and a preview of every post",
+	Long: `List the entries waiting to be reported, with the kind of change, how long they have been waiting, where they go
+and a preview of what is posted on every channel, as the next tick of the reporter would post them. Reports with several
+variants show all of them, as one is picked at random when posting. Paper links are shown before shortening.`,
+	Args: cobra.NoArgs,
+	RunE: queueCmdFunc,
+}
+
+// queueStatus tells what the next tick does with the entry, and if it is posted at all
+func queueStatus(cfg *Configuration, service compliance.Service, entry *compliance.Feature, change compliance.Change, now time.Time) (status string, posted bool, err error) {
+	attempt, err := service.GetReportAttempt(context.Background(), entry.Id, compliance.ChannelTwitter)
+	if err != nil {
+		return "", false, err
+	}
+	if attempt != nil && !attempt.Due(now) {
+		return fmt.Sprintf("retried at %v after %v failed attempts, last: %v", attempt.NextAttempt.Format(historyTimeFormat), attempt.Attempts, attempt.LastError), false, nil
+	}
+
+	if !reportableKind(change.Kind) {
+		return "not worth reporting or muted, marked as reported without a post", false, nil
+	}
+	if cfg.SupressReporting {
+		return "supressed, marked as reported without a post", false, nil
+	}
+
+	if cfg.ReportApproval {
+		approval, err := service.GetReportApproval(context.Background(), entry.Id, compliance.ChannelTwitter)
+		if err != nil {
+			return "", false, err
+		}
+
+		switch {
+		case approval == nil:
+			return "rendered and sent to the maintainer for approval", false, nil
+		case approval.Status == compliance.ApprovalPending:
+			return "waits for approval", false, nil
+		case approval.Status == compliance.ApprovalRejected:
+			return "rejected, skipped without a post", false, nil
+		}
+		return "approved, posted as approved", true, nil
+	}
+
+	if cfg.ReportBatching {
+		return "posted in the batch of all queued changes", true, nil
+	}
+
+	return "posted", true, nil
+}
+
+// queueReporters gives the names of the reporters changes go to besides twitter, as newReporters sets them up
+func queueReporters(cfg *Configuration) (names []string) {
+	configured := cfg.Reporters
+	if len(configured) == 0 {
+		configured = compiledReporters()
+	}
+
+	for _, name := range configured {
+		if registration, ok := reporterRegistry[name]; ok && registration.new(cfg, compliance.ReportOptions{}) != nil {
+			names = append(names, name)
+		}
+	}
+
+	return
+}
+
+// queueRouting names where the report of the change goes
+func queueRouting(cfg *Configuration, change compliance.Change) []string {
+	routing := []string{compliance.ChannelTwitter}
+	if len(reportCard(cfg, change)) > 0 {
+		routing[0] += " with an image card"
+	}
+
+	routing = append(routing, queueReporters(cfg)...)
+	for _, plugin := range cfg.Plugins {
+		routing = append(routing, fmt.Sprintf("plugin %v if it reports", plugin.Name))
+	}
+
+	return routing
+}
+
+// printPreview prints the lines of a post indented under its channel
+func printPreview(channel string, text string) {
+	fmt.Printf("  %v:\n", channel)
+	for _, line := range strings.Split(text, "\n") {
+		fmt.Printf("    | %v\n", line)
+	}
+}
+
+// previewTwitter prints the tweets of the report, with every variant it may be posted with
+func previewTwitter(cfg *Configuration, reportOptions compliance.ReportOptions, service compliance.Service, templates *compliance.ReportTemplates, hooks *compliance.Hooks, entry *compliance.Feature, change compliance.Change) error {
+	if cfg.ReportApproval {
+		approval, err := service.GetReportApproval(context.Background(), entry.Id, compliance.ChannelTwitter)
+		if err != nil {
+			return err
+		}
+		if approval != nil && approval.Status == compliance.ApprovalApproved {
+			printPreview("twitter, as approved", strings.Join(limits.Twitter.Split(approval.Text), "\n--\n"))
+			return nil
+		}
+	}
+
+	link, _ := reportLink(cfg, reportOptions, nil, change)
+	texts, err := templates.RenderEach(reportOptions, change, templateLink(cfg, link))
+	if err != nil {
+		return err
+	}
+
+	var variants []string
+	for variant := range texts {
+		variants = append(variants, variant)
+	}
+	sort.Strings(variants)
+
+	for _, variant := range variants {
+		text := reportFooter(cfg, change, hooks.Text(reportOptions, change, templateLink(cfg, link), texts[variant]), link)
+		if text == "" {
+			printPreview(fmt.Sprintf("twitter, variant %v", variant), "(left out)")
+			continue
+		}
+
+		printPreview(fmt.Sprintf("twitter, variant %v", variant), strings.Join(limits.Twitter.Split(text), "\n--\n"))
+	}
+
+	return nil
+}
+
+func queueCmdFunc(cmd *cobra.Command, args []string) error {
+	cfg := &Configuration{}
+
+	if err := viper.Unmarshal(cfg); err != nil {
+		return err
+	}
+
+	if cfg.StorageMode != "sqlite3" {
+		return fmt.Errorf("queue needs storageMode sqlite3, not %s", cfg.StorageMode)
+	}
+
+	complianceStorageService, err := openSqliteService(cfg)
+	if err != nil {
+		return err
+	}
+	defer complianceStorageService.Close(context.Background())
+
+	reportOptions, err := loadReportOptions(cfg)
+	if err != nil {
+		return err
+	}
+
+	reportTemplates, err := compliance.NewReportTemplates(cfg.ReportVariants)
+	if err != nil {
+		return err
+	}
+
+	reportHooks, err := compliance.LoadHooks(cfg.ReportHooks)
+	if err != nil {
+		return err
+	}
+
+	//fails on reporters that aren't compiled in, like the bot would
+	if _, err := newReporters(cfg, reportOptions); err != nil {
+		return err
+	}
+
+	pause, err := complianceStorageService.GetReportingPause(context.Background())
+	if err != nil {
+		return err
+	}
+
+	entries, err := complianceStorageService.GetUnreported(context.Background(), compliance.ChannelTwitter)
+	if err != nil {
+		return err
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("no entries wait to be reported")
+		return nil
+	}
+
+	now := time.Now()
+	if pause.Paused() {
+		fmt.Printf("reporting is paused by safe mode since %v, nothing is posted until resume-reporting\n", pause.PausedAt.Format(historyTimeFormat))
+	} else if due := len(pendingReports(complianceStorageService, entries, now)); cfg.SafeMode && due > cfg.SafeModeMaxReports {
+		fmt.Printf("%v entries are due, more than the %v of safe mode. the next tick pauses reporting instead of posting them\n", due, cfg.SafeModeMaxReports)
+	}
+	if cfg.DryReporting {
+		fmt.Println("dry run, reports are only logged")
+	}
+	fmt.Printf("%v entries wait to be reported:\n\n", len(entries))
+
+	threads := map[string]int64{}
+	var batch []compliance.Change
+
+	for i := range entries {
+		entry := &entries[i]
+
+		previous, err := complianceStorageService.GetPreviousEntry(context.Background(), entry.Id)
+		if err != nil {
+			return err
+		}
+
+		change, err := compliance.DiffFeatures(previous, entry)
+		if err != nil {
+			fmt.Printf("entry %v, %v \"%v\": can't be turned into a report, the maintainer is told instead: %v\n\n", entry.Id, entry.Standard(), entry.Name, err)
+			continue
+		}
+		change = reportHooks.Filter(reportOptions, withoutIgnored(complianceStorageService, reportedChange(cfg, change)))
+
+		kind := change.Kind
+		if kind == "" {
+			kind = "unreported"
+		}
+		fmt.Printf("entry %v, %v \"%v\": %v change, waiting %v since %v\n", entry.Id, entry.Standard(), entry.Name, kind, now.Sub(entry.Timestamp).Round(time.Minute), entry.Timestamp.Format(historyTimeFormat))
+
+		status, posted, err := queueStatus(cfg, complianceStorageService, entry, change, now)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("  %v\n", status)
+
+		if !posted {
+			fmt.Println()
+			continue
+		}
+
+		fmt.Printf("  goes to %v\n", strings.Join(queueRouting(cfg, change), ", "))
+
+		if cfg.ReportBatching {
+			batch = append(batch, change)
+		} else {
+			if replyTo, ok := threads[reportThreadKey(entry)]; ok && cfg.ReportThreads {
+				fmt.Printf("  as a reply to the report of entry %v\n", replyTo)
+			}
+			threads[reportThreadKey(entry)] = entry.Id
+
+			if err := previewTwitter(cfg, reportOptions, complianceStorageService, reportTemplates, reportHooks, entry, change); err != nil {
+				return err
+			}
+		}
+
+		for _, name := range queueReporters(cfg) {
+			if preview := reporterRegistry[name].preview; preview != nil {
+				if text := preview(reportOptions, change); text != "" {
+					printPreview(name, text)
+				}
+			}
+		}
+		fmt.Println()
+	}
+
+	if len(batch) > 0 {
+		printPreview("twitter, the batch of all of them", strings.Join(compliance.BatchToTwitterThread(reportOptions, batch, cfg.ReportBatchDetails, cfg.ReportBatchMaxTweets), "\n--\n"))
+	}
+
+	return nil
+}
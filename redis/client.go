@@ -0,0 +1,180 @@
+package redis
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const timeout = 5 * time.Second
+
+// Error is an error reply of the server
+type Error string
+
+func (e Error) Error() string {
+	return string(e)
+}
+
+// Client sends commands to a Redis server over a single connection, which is dialed again after it broke
+type Client struct {
+	address  string
+	password string
+	database int
+	conn     net.Conn
+	reader   *bufio.Reader
+	mutex    sync.Mutex
+}
+
+// NewClient makes a client of the server at address. the connection is made with the first command
+func NewClient(address string, password string, database int) *Client {
+	return &Client{
+		address:  address,
+		password: password,
+		database: database,
+	}
+}
+
+func (c *Client) connect() error {
+	conn, err := net.DialTimeout("tcp", c.address, timeout)
+	if err != nil {
+		return errors.Wrap(err, "could not connect to redis")
+	}
+	c.conn, c.reader = conn, bufio.NewReader(conn)
+
+	if c.password != "" {
+		if err := c.setup("AUTH", c.password); err != nil {
+			c.disconnect()
+			return errors.Wrap(err, "could not authenticate to redis")
+		}
+	}
+	if c.database != 0 {
+		if err := c.setup("SELECT", strconv.Itoa(c.database)); err != nil {
+			c.disconnect()
+			return errors.Wrap(err, "could not select redis database")
+		}
+	}
+
+	return nil
+}
+
+// setup sends a command setting up a fresh connection, for which error replies are as bad as a broken connection
+func (c *Client) setup(args ...string) error {
+	reply, err := c.send(args...)
+	if err != nil {
+		return err
+	}
+
+	if replyErr, ok := reply.(Error); ok {
+		return replyErr
+	}
+
+	return nil
+}
+
+func (c *Client) disconnect() {
+	if c.conn != nil {
+		c.conn.Close()
+	}
+	c.conn, c.reader = nil, nil
+}
+
+func (c *Client) send(args ...string) (interface{}, error) {
+	c.conn.SetDeadline(time.Now().Add(timeout))
+
+	command := fmt.Sprintf("*%v\r\n", len(args))
+	for _, arg := range args {
+		command += fmt.Sprintf("$%v\r\n%v\r\n", len(arg), arg)
+	}
+
+	if _, err := io.WriteString(c.conn, command); err != nil {
+		return nil, err
+	}
+
+	return c.readReply()
+}
+
+// readReply reads a reply: a string, an int64, nil, an Error or a slice of replies
+func (c *Client) readReply() (interface{}, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 {
+		return nil, fmt.Errorf("malformed redis reply %q", line)
+	}
+	kind, content := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return content, nil
+	case '-':
+		return Error(content), nil
+	case ':':
+		return strconv.ParseInt(content, 10, 64)
+	case '$':
+		length, err := strconv.Atoi(content)
+		if err != nil || length < 0 {
+			return nil, err
+		}
+
+		data := make([]byte, length+2)
+		if _, err := io.ReadFull(c.reader, data); err != nil {
+			return nil, err
+		}
+		return string(data[:length]), nil
+	case '*':
+		count, err := strconv.Atoi(content)
+		if err != nil || count < 0 {
+			return nil, err
+		}
+
+		result := make([]interface{}, count)
+		for i := range result {
+			if result[i], err = c.readReply(); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("unknown redis reply %q", line)
+	}
+}
+
+// Do sends a command and gives its reply. error replies are returned as errors
+func (c *Client) Do(args ...string) (interface{}, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.conn == nil {
+		if err := c.connect(); err != nil {
+			return nil, err
+		}
+	}
+
+	reply, err := c.send(args...)
+	if err != nil {
+		//the connection is in an unknown state after a failed read or write
+		c.disconnect()
+		return nil, errors.Wrapf(err, "redis %v failed", args[0])
+	}
+
+	if replyErr, ok := reply.(Error); ok {
+		return nil, replyErr
+	}
+
+	return reply, nil
+}
+
+func (c *Client) Close() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.disconnect()
+	return nil
+}
@@ -0,0 +1,235 @@
+package redis
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeServer speaks enough of the Redis protocol for the queue: AUTH, SELECT and the sorted set commands it uses.
+// every command it gets is recorded, and connections are dropped after dropAfter commands if it is set
+type fakeServer struct {
+	listener  net.Listener
+	password  string
+	dropAfter int
+
+	mutex    sync.Mutex
+	commands [][]string
+	sets     map[string]map[string]bool
+	conns    int
+}
+
+func newFakeServer(t *testing.T, password string) *fakeServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	server := &fakeServer{listener: listener, password: password, sets: map[string]map[string]bool{}}
+	go server.accept()
+
+	return server
+}
+
+func (f *fakeServer) address() string {
+	return f.listener.Addr().String()
+}
+
+func (f *fakeServer) accept() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		f.mutex.Lock()
+		f.conns++
+		f.mutex.Unlock()
+
+		go f.serve(conn)
+	}
+}
+
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil {
+		return nil, err
+	}
+
+	var args []string
+	for i := 0; i < count; i++ {
+		if _, err := reader.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		arg, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, strings.TrimSuffix(arg, "\r\n"))
+	}
+
+	return args, nil
+}
+
+func (f *fakeServer) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	authenticated := f.password == ""
+
+	for served := 0; ; served++ {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		if f.dropAfter > 0 && served == f.dropAfter {
+			return
+		}
+
+		f.mutex.Lock()
+		f.commands = append(f.commands, args)
+		reply := f.reply(args, &authenticated)
+		f.mutex.Unlock()
+
+		if _, err := io.WriteString(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+func (f *fakeServer) reply(args []string, authenticated *bool) string {
+	switch {
+	case args[0] == "AUTH":
+		if len(args) != 2 || args[1] != f.password {
+			return "-WRONGPASS invalid password\r\n"
+		}
+		*authenticated = true
+		return "+OK\r\n"
+	case !*authenticated:
+		return "-NOAUTH Authentication required.\r\n"
+	case args[0] == "SELECT":
+		return "+OK\r\n"
+	case args[0] == "ZADD" && len(args) >= 4 && len(args)%2 == 0:
+		set := f.sets[args[1]]
+		if set == nil {
+			set = map[string]bool{}
+			f.sets[args[1]] = set
+		}
+		added := 0
+		for i := 3; i < len(args); i += 2 {
+			if !set[args[i]] {
+				set[args[i]] = true
+				added++
+			}
+		}
+		return fmt.Sprintf(":%v\r\n", added)
+	case args[0] == "ZREM" && len(args) == 3:
+		if !f.sets[args[1]][args[2]] {
+			return ":0\r\n"
+		}
+		delete(f.sets[args[1]], args[2])
+		return ":1\r\n"
+	case args[0] == "ZRANGE" && len(args) == 4:
+		var members []string
+		for member := range f.sets[args[1]] {
+			members = append(members, member)
+		}
+		sort.Slice(members, func(i, j int) bool {
+			a, _ := strconv.Atoi(members[i])
+			b, _ := strconv.Atoi(members[j])
+			return a < b
+		})
+
+		reply := fmt.Sprintf("*%v\r\n", len(members))
+		for _, member := range members {
+			reply += fmt.Sprintf("$%v\r\n%v\r\n", len(member), member)
+		}
+		return reply
+	default:
+		return fmt.Sprintf("-ERR wrong number of arguments for '%v' command\r\n", strings.ToLower(args[0]))
+	}
+}
+
+func (f *fakeServer) sent() [][]string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return append([][]string{}, f.commands...)
+}
+
+func TestClientSetup(t *testing.T) {
+	server := newFakeServer(t, "secret")
+	client := NewClient(server.address(), "secret", 2)
+	defer client.Close()
+
+	if _, err := client.Do("ZADD", "set", "1", "1"); err != nil {
+		t.Fatal(err)
+	}
+
+	commands := fmt.Sprint(server.sent())
+	if commands != "[[AUTH secret] [SELECT 2] [ZADD set 1 1]]" {
+		t.Errorf("got commands %v", commands)
+	}
+
+	wrong := NewClient(server.address(), "wrong", 0)
+	defer wrong.Close()
+	if _, err := wrong.Do("ZRANGE", "set", "0", "-1"); err == nil || !strings.Contains(err.Error(), "WRONGPASS") {
+		t.Errorf("wrong password gave %v", err)
+	}
+}
+
+func TestClientReplies(t *testing.T) {
+	server := newFakeServer(t, "")
+	client := NewClient(server.address(), "", 0)
+	defer client.Close()
+
+	if reply, err := client.Do("ZADD", "set", "7", "7", "3", "3"); reply != int64(2) || err != nil {
+		t.Errorf("ZADD gave %#v, %v", reply, err)
+	}
+	if reply, err := client.Do("ZRANGE", "set", "0", "-1"); fmt.Sprintf("%#v", reply) != `[]interface {}{"3", "7"}` || err != nil {
+		t.Errorf("ZRANGE gave %#v, %v", reply, err)
+	}
+
+	_, err := client.Do("ZADD", "set")
+	if _, ok := err.(Error); !ok {
+		t.Errorf("error reply gave %#v", err)
+	}
+	if reply, err := client.Do("ZREM", "set", "7"); reply != int64(1) || err != nil {
+		t.Errorf("error reply broke the connection: %#v, %v", reply, err)
+	}
+}
+
+func TestClientReconnect(t *testing.T) {
+	server := newFakeServer(t, "")
+	server.dropAfter = 1
+	client := NewClient(server.address(), "", 0)
+	defer client.Close()
+
+	if _, err := client.Do("ZADD", "set", "1", "1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Do("ZADD", "set", "2", "2"); err == nil {
+		t.Fatalf("command on a dropped connection succeeded")
+	}
+	if _, err := client.Do("ZADD", "set", "2", "2"); err != nil {
+		t.Errorf("command after a dropped connection gave %v", err)
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	if server.conns != 2 {
+		t.Errorf("made %v connections, want 2", server.conns)
+	}
+}
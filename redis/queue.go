@@ -0,0 +1,74 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// Queue keeps the entries waiting to be reported in a sorted set per channel, scored by id so they come out in the
+// order they were stored. pushing an id that is queued already changes nothing
+type Queue struct {
+	client *Client
+	prefix string
+}
+
+// NewQueue makes a queue keeping its sets under keys starting with prefix
+func NewQueue(client *Client, prefix string) *Queue {
+	return &Queue{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+func (q *Queue) key(channel string) string {
+	return q.prefix + ":pending:" + channel
+}
+
+func (q *Queue) Push(ctx context.Context, channel string, ids ...int64) error {
+	//ZADD needs at least one member
+	if len(ids) == 0 {
+		return nil
+	}
+
+	args := []string{"ZADD", q.key(channel)}
+	for _, id := range ids {
+		member := strconv.FormatInt(id, 10)
+		args = append(args, member, member)
+	}
+
+	_, err := q.client.Do(args...)
+	return err
+}
+
+func (q *Queue) Pending(ctx context.Context, channel string) ([]int64, error) {
+	reply, err := q.client.Do("ZRANGE", q.key(channel), "0", "-1")
+	if err != nil {
+		return nil, err
+	}
+
+	members, ok := reply.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected redis reply %v to ZRANGE", reply)
+	}
+
+	var result []int64
+	for _, member := range members {
+		id, err := strconv.ParseInt(fmt.Sprint(member), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("queue of %v holds '%v', which is no entry id", channel, member)
+		}
+		result = append(result, id)
+	}
+
+	return result, nil
+}
+
+func (q *Queue) Ack(ctx context.Context, channel string, id int64) error {
+	_, err := q.client.Do("ZREM", q.key(channel), strconv.FormatInt(id, 10))
+	return err
+}
+
+func (q *Queue) Close() error {
+	return q.client.Close()
+}
@@ -0,0 +1,69 @@
+package redis
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestQueue(t *testing.T) {
+	server := newFakeServer(t, "")
+	queue := NewQueue(NewClient(server.address(), "", 0), "bot")
+	defer queue.Close()
+	ctx := context.Background()
+
+	if err := queue.Push(ctx, "twitter", 12, 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := queue.Push(ctx, "twitter", 3, 5); err != nil {
+		t.Fatal(err)
+	}
+	if err := queue.Push(ctx, "mastodon", 4); err != nil {
+		t.Fatal(err)
+	}
+
+	pending, err := queue.Pending(ctx, "twitter")
+	if err != nil || !reflect.DeepEqual(pending, []int64{3, 5, 12}) {
+		t.Fatalf("got pending %v, %v, want 3 5 12", pending, err)
+	}
+
+	if err := queue.Ack(ctx, "twitter", 5); err != nil {
+		t.Fatal(err)
+	}
+	if pending, err := queue.Pending(ctx, "twitter"); err != nil || !reflect.DeepEqual(pending, []int64{3, 12}) {
+		t.Errorf("got pending %v, %v after ack, want 3 12", pending, err)
+	}
+	if pending, err := queue.Pending(ctx, "mastodon"); err != nil || !reflect.DeepEqual(pending, []int64{4}) {
+		t.Errorf("got pending %v, %v of the other channel, want 4", pending, err)
+	}
+}
+
+func TestQueueEmptyPush(t *testing.T) {
+	server := newFakeServer(t, "")
+	queue := NewQueue(NewClient(server.address(), "", 0), "bot")
+	defer queue.Close()
+
+	if err := queue.Push(context.Background(), "twitter"); err != nil {
+		t.Fatal(err)
+	}
+	if commands := server.sent(); len(commands) != 0 {
+		t.Errorf("pushing no ids sent %v", commands)
+	}
+
+	if pending, err := queue.Pending(context.Background(), "twitter"); err != nil || len(pending) != 0 {
+		t.Errorf("got pending %v, %v, want none", pending, err)
+	}
+}
+
+func TestQueueForeignMember(t *testing.T) {
+	server := newFakeServer(t, "")
+	queue := NewQueue(NewClient(server.address(), "", 0), "bot")
+	defer queue.Close()
+
+	if _, err := queue.client.Do("ZADD", "bot:pending:twitter", "1", "first"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := queue.Pending(context.Background(), "twitter"); err == nil {
+		t.Errorf("member that is no id was read")
+	}
+}
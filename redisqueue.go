@@ -0,0 +1,28 @@
+//go:build !noredis
+// +build !noredis
+
+package main
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"cppimpbot/redis"
+	"fmt"
+)
+
+func init() {
+	queueBackends["redis"] = redisQueue
+}
+
+// redisQueue keeps the queue of entries waiting to be reported in Redis, synced with the database on startup
+func redisQueue(cfg *Configuration, service compliance.Service) (compliance.Service, error) {
+	queue := redis.NewQueue(redis.NewClient(cfg.RedisAddress, cfg.RedisPassword, cfg.RedisDatabase), cfg.RedisKeyPrefix)
+	queued := compliance.NewQueuedService(service, queue, []string{compliance.ChannelTwitter})
+
+	if err := queued.Sync(context.Background()); err != nil {
+		queue.Close()
+		return nil, fmt.Errorf("could not sync the report queue: %v", err)
+	}
+
+	return queued, nil
+}
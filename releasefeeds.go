@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"cppimpbot/releases"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/dghubble/go-twitter/twitter"
+)
+
+type ReleaseFeedConfig struct {
+	Compiler       string //key of the compiler the feed announces, like gcc
+	Url            string
+	VersionPattern string //regex matched against the entry titles, its first group is the version
+}
+
+// releaseFeedJob holds what checking one release feed needs between polls
+type releaseFeedJob struct {
+	feed    ReleaseFeedConfig
+	pattern *regexp.Regexp
+}
+
+func releaseFeedJobs(cfg *Configuration) ([]releaseFeedJob, error) {
+	var result []releaseFeedJob
+
+	for _, feed := range cfg.ReleaseFeeds {
+		pattern, err := regexp.Compile(feed.VersionPattern)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, releaseFeedJob{feed, pattern})
+	}
+
+	return result, nil
+}
+
+// postTweet posts a tweet, or only logs it if reporting is supressed or a dry run
+func postTweet(cfg *Configuration, client *twitter.Client, text string) error {
+	if cfg.SupressReporting {
+		slog.Info("got report which will be supressed", "channel", compliance.ChannelTwitter, "tweet", text)
+		return nil
+	}
+
+	if cfg.DryReporting {
+		slog.Info("Dry run: posting tweet", "channel", compliance.ChannelTwitter, "tweet", text)
+		return nil
+	}
+
+	slog.Info("posting tweet", "channel", compliance.ChannelTwitter, "tweet", text)
+	_, _, err := client.Statuses.Update(text, nil)
+
+	return err
+}
+
+// threadError is a thread that failed after some of its tweets were posted, which a retry can resume from
+type threadError struct {
+	posted  int //tweets posted before the failure
+	firstID int64
+	lastID  int64
+	err     error
+}
+
+func (e *threadError) Error() string {
+	return fmt.Sprintf("failed after posting %v tweets of the thread: %v", e.posted, e.err)
+}
+
+// postTweetThread posts the tweets as replies to each other, the first one with the given params, and gives the ids of
+// the first and the last one posted. a reply failing after the first tweet is out fails with a *threadError telling how
+// far the thread got
+func postTweetThread(client *twitter.Client, tweets []string, params *twitter.StatusUpdateParams) (firstID int64, lastID int64, err error) {
+	for i, tweet := range tweets {
+		slog.Info("posting tweet", "channel", compliance.ChannelTwitter, "tweet", tweet)
+
+		posted, _, err := client.Statuses.Update(tweet, params)
+		if err != nil {
+			if i == 0 {
+				return 0, 0, err
+			}
+
+			slog.Error("error posting tweet of a thread, leaving out the rest", "channel", compliance.ChannelTwitter, "tweet", i+1, "tweets", len(tweets), "err", err)
+			return firstID, lastID, &threadError{posted: i, firstID: firstID, lastID: lastID, err: err}
+		}
+
+		if i == 0 {
+			firstID = posted.ID
+		}
+		lastID = posted.ID
+		params = &twitter.StatusUpdateParams{InReplyToStatusID: posted.ID}
+	}
+
+	return firstID, lastID, nil
+}
+
+// postThread posts the tweets as a thread, the first one with the GIF attached if there is one, or only logs them if
+// reporting is supressed or a dry run
+func postThread(cfg *Configuration, client *twitter.Client, httpClient *http.Client, tweets []string, gif []byte) error {
+	if cfg.SupressReporting || cfg.DryReporting {
+		for _, tweet := range tweets {
+			if err := postTweet(cfg, client, tweet); err != nil {
+				return err
+			}
+		}
+		if len(gif) > 0 {
+			slog.Info("the first tweet would have a GIF attached", "channel", compliance.ChannelTwitter, "bytes", len(gif))
+		}
+		return nil
+	}
+
+	params := &twitter.StatusUpdateParams{}
+	if len(gif) > 0 {
+		mediaID, err := uploadMedia(httpClient, gif, "image/gif", "tweet_gif")
+		if err != nil {
+			return err
+		}
+		params.MediaIds = []int64{mediaID}
+	}
+
+	_, _, err := postTweetThread(client, tweets, params)
+
+	return err
+}
+
+// newlySupported lists the features whose support of the compiler names the released version, leaving out those that
+// already matched an earlier release, as a cell saying "14" matches 14.1.0 as well as 14.2.0
+func newlySupported(features []compliance.Feature, compiler string, version string, earlier []string) (result []compliance.Feature) {
+	for _, feature := range features {
+		support := feature.SupportFor(compiler)
+		if support == nil || support.Support == compliance.SupportNo {
+			continue
+		}
+
+		if !releases.VersionMatches(support.Version, version) {
+			continue
+		}
+
+		matchedEarlier := false
+		for _, earlierVersion := range earlier {
+			if releases.VersionMatches(support.Version, earlierVersion) {
+				matchedEarlier = true
+			}
+		}
+
+		if !matchedEarlier {
+			result = append(result, feature)
+		}
+	}
+
+	return
+}
+
+// checkReleaseFeed posts a roundup for every release in the feed that wasn't seen before. the first time a feed is
+// checked its releases are only recorded, so that adding a feed doesn't announce its whole history
+func checkReleaseFeed(cfg *Configuration, client *twitter.Client, complianceStorageService compliance.Service, job releaseFeedJob) {
+	compiler := job.feed.Compiler
+
+	entries, err := releases.Fetch(&http.Client{Timeout: time.Duration(cfg.ScrapeTimeout) * time.Second}, job.feed.Url, cfg.ScrapeUserAgent)
+	if err != nil {
+		slog.Error("error fetching release feed", "compiler", compiler, "err", err)
+		return
+	}
+
+	found := releases.Find(entries, compiler, job.pattern)
+
+	announced, err := complianceStorageService.GetAnnouncedReleases(context.Background(), compiler)
+	if err != nil {
+		slog.Error("error getting announced releases", "compiler", compiler, "err", err)
+		return
+	}
+
+	firstCheck := len(announced) == 0
+	isAnnounced := map[string]bool{}
+	for _, version := range announced {
+		isAnnounced[version] = true
+	}
+
+	var latest []compliance.Feature
+	if !firstCheck {
+		if latest, err = complianceStorageService.GetLatestEntries(context.Background()); err != nil {
+			slog.Error("error getting latest entries for release roundup", "compiler", compiler, "err", err)
+			return
+		}
+	}
+
+	//feeds list the newest release first
+	for i := len(found) - 1; i >= 0; i-- {
+		release := found[i]
+		if isAnnounced[release.Version] {
+			continue
+		}
+
+		if err := complianceStorageService.StoreAnnouncedRelease(context.Background(), compiler, release.Version); err != nil {
+			slog.Error("error storing release, not announcing it", "compiler", compiler, "release", release.Version, "err", err)
+			continue
+		}
+
+		previous := announced
+		announced = append(announced, release.Version)
+		isAnnounced[release.Version] = true
+
+		if firstCheck {
+			slog.Info("recorded existing release", "compiler", compiler, "release", release.Version)
+			continue
+		}
+
+		features := newlySupported(latest, compiler, release.Version, previous)
+		if len(features) == 0 {
+			slog.Info("release listed by no tracked feature", "compiler", compiler, "release", release.Version)
+			continue
+		}
+
+		if err := postThread(cfg, client, nil, compliance.ReleaseToTwitterThread(compiler, release.Version, features), nil); err != nil {
+			slog.Error("error posting release roundup", "channel", compliance.ChannelTwitter, "compiler", compiler, "release", release.Version, "err", err)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package releases
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Entry is one item of an RSS or Atom feed
+type Entry struct {
+	Title string
+	Link  string
+}
+
+type rssFeed struct {
+	Items []struct {
+		Title string `xml:"title"`
+		Link  string `xml:"link"`
+	} `xml:"channel>item"`
+}
+
+type atomFeed struct {
+	Entries []struct {
+		Title string `xml:"title"`
+		Links []struct {
+			Href string `xml:"href,attr"`
+		} `xml:"link"`
+	} `xml:"entry"`
+}
+
+// ParseFeed reads the entries of an RSS 2.0 or Atom feed
+func ParseFeed(reader io.Reader) ([]Entry, error) {
+	decoder := xml.NewDecoder(reader)
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("could not find the feed element: %v", err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		var result []Entry
+
+		switch start.Name.Local {
+		case "rss":
+			feed := rssFeed{}
+			if err := decoder.DecodeElement(&feed, &start); err != nil {
+				return nil, err
+			}
+
+			for _, item := range feed.Items {
+				result = append(result, Entry{strings.TrimSpace(item.Title), strings.TrimSpace(item.Link)})
+			}
+		case "feed":
+			feed := atomFeed{}
+			if err := decoder.DecodeElement(&feed, &start); err != nil {
+				return nil, err
+			}
+
+			for _, entry := range feed.Entries {
+				link := ""
+				if len(entry.Links) > 0 {
+					link = entry.Links[0].Href
+				}
+				result = append(result, Entry{strings.TrimSpace(entry.Title), strings.TrimSpace(link)})
+			}
+		default:
+			return nil, fmt.Errorf("'%s' is neither an RSS nor an Atom feed", start.Name.Local)
+		}
+
+		return result, nil
+	}
+}
+
+// Fetch downloads and parses a feed
+func Fetch(client *http.Client, url string, userAgent string) ([]Entry, error) {
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if userAgent != "" {
+		request.Header.Set("User-Agent", userAgent)
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("got status %v from %v", response.Status, url)
+	}
+
+	return ParseFeed(response.Body)
+}
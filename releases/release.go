@@ -0,0 +1,54 @@
+package releases
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Release is a compiler version announced in a feed
+type Release struct {
+	Compiler string
+	Version  string
+	Link     string
+}
+
+// Find picks the releases out of feed entries. pattern is matched against the entry titles and its first group, or the
+// whole match if it has none, is the version. the result is in feed order without duplicates
+func Find(entries []Entry, compiler string, pattern *regexp.Regexp) (result []Release) {
+	seen := map[string]bool{}
+
+	for _, entry := range entries {
+		match := pattern.FindStringSubmatch(entry.Title)
+		if match == nil {
+			continue
+		}
+
+		version := match[0]
+		if len(match) > 1 {
+			version = match[1]
+		}
+
+		if version == "" || seen[version] {
+			continue
+		}
+		seen[version] = true
+
+		result = append(result, Release{
+			Compiler: compiler,
+			Version:  version,
+			Link:     entry.Link,
+		})
+	}
+
+	return
+}
+
+// VersionMatches tells if a version mentioned in a support cell, like "14" or "19.38", names the released version.
+// the support version has to be the release version or a prefix of it ending at a dot
+func VersionMatches(supportVersion string, releaseVersion string) bool {
+	if supportVersion == "" {
+		return false
+	}
+
+	return supportVersion == releaseVersion || strings.HasPrefix(releaseVersion, supportVersion+".")
+}
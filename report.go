@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"cppimpbot/shortener"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dghubble/go-twitter/twitter"
+	"github.com/dghubble/oauth1"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var reportCommand = &cobra.Command{
+	Use:   "report",
+	Short: "Work with the reports of stored entries",
+}
+
+var reportResendCommand = &cobra.Command{
+	Use:   "resend",
+	Short: "Render the report of a stored entry again and post it, whether it was reported already or not",
+	Long: `Render the report of a stored entry again and post it, whether it was reported already or not, for when a tweet
+got deleted or failed without anyone noticing. The report is rendered as the reporter would render it now, honoring
+ReportCompilers, ReportLanguages, ReportRules, the report variants, ReportHooks, SupressReporting and DryReporting. Once
+posted the entry counts as reported and its failed attempts are forgotten.`,
+	Args: cobra.NoArgs,
+	RunE: reportResendCmdFunc,
+}
+
+var reportResendID int64
+var reportResendChannel string
+
+func init() {
+	reportResendCommand.Flags().Int64Var(&reportResendID, "id", 0, "id of the entry to report again")
+	reportResendCommand.Flags().StringVar(&reportResendChannel, "channel", compliance.ChannelTwitter, "channel to report the entry on")
+	reportResendCommand.MarkFlagRequired("id")
+
+	reportCommand.AddCommand(reportResendCommand)
+}
+
+// withCard uploads the image card of the report of the entry and attaches it to the first tweet of the thread posted
+// with params. a card that fails to upload is left out rather than holding the report back
+func withCard(httpClient *http.Client, entryID int64, card []byte, params *twitter.StatusUpdateParams) *twitter.StatusUpdateParams {
+	if len(card) == 0 {
+		return params
+	}
+
+	mediaID, err := uploadMedia(httpClient, card, "image/png", "tweet_image")
+	if err != nil {
+		slog.Warn("error uploading the image card, posting without it", "channel", compliance.ChannelTwitter, "entry", entryID, "err", err)
+		return params
+	}
+
+	if params == nil {
+		params = &twitter.StatusUpdateParams{}
+	}
+	params.MediaIds = []int64{mediaID}
+
+	return params
+}
+
+func reportResendCmdFunc(cmd *cobra.Command, args []string) error {
+	cfg := &Configuration{}
+
+	if err := viper.Unmarshal(cfg); err != nil {
+		return err
+	}
+
+	if cfg.StorageMode != "sqlite3" {
+		return fmt.Errorf("report resend needs storageMode sqlite3, not %s", cfg.StorageMode)
+	}
+
+	if reportResendChannel != compliance.ChannelTwitter {
+		return fmt.Errorf("unknown channel '%v', entries are only reported on %v", reportResendChannel, compliance.ChannelTwitter)
+	}
+
+	complianceStorageService, err := openSqliteService(cfg)
+	if err != nil {
+		return err
+	}
+	defer complianceStorageService.Close(context.Background())
+
+	entries, err := complianceStorageService.GetEntriesByID(context.Background(), []int64{reportResendID})
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("there is no entry %v", reportResendID)
+	}
+	entry := entries[0]
+
+	previous, err := complianceStorageService.GetPreviousEntry(context.Background(), entry.Id)
+	if err != nil {
+		return err
+	}
+
+	change, err := compliance.DiffFeatures(previous, &entry)
+	if err != nil {
+		return fmt.Errorf("could not turn entry %v into a report: %v", entry.Id, err)
+	}
+
+	reportOptions, err := loadReportOptions(cfg)
+	if err != nil {
+		return err
+	}
+
+	reportTemplates, err := compliance.NewReportTemplates(cfg.ReportVariants)
+	if err != nil {
+		return err
+	}
+
+	reportHooks, err := compliance.LoadHooks(cfg.ReportHooks)
+	if err != nil {
+		return err
+	}
+
+	linkShortener, err := shortener.New(cfg.LinkShortener, cfg.LinkShortenerUrl, cfg.LinkShortenerToken)
+	if err != nil {
+		return err
+	}
+
+	change = reportHooks.Filter(reportOptions, reportedChange(cfg, change))
+
+	twitterThread, variant, link, shortLink := renderTwitterReport(cfg, reportOptions, reportTemplates, reportHooks, linkShortener, change)
+	if len(twitterThread) == 0 {
+		return fmt.Errorf("entry %v is a change that isn't worth reporting, there is nothing to resend", entry.Id)
+	}
+	twitterReport := strings.Join(twitterThread, "\n\n")
+
+	if cfg.SupressReporting {
+		slog.Info("got report which will be supressed", "channel", compliance.ChannelTwitter, "entry", entry.Id, "feature", entry.Name, "tweet", twitterReport)
+		return nil
+	}
+
+	card := reportCard(cfg, change)
+
+	if cfg.DryReporting {
+		if len(card) > 0 {
+			slog.Info("Dry run: attaching an image card", "channel", compliance.ChannelTwitter, "entry", entry.Id, "feature", entry.Name, "bytes", len(card))
+		}
+		slog.Info("Dry run: posting tweet", "channel", compliance.ChannelTwitter, "entry", entry.Id, "feature", entry.Name, "tweet", twitterReport)
+		return nil
+	}
+
+	httpClient := oauth1.NewConfig(cfg.ConsumerKey, cfg.ConsumerSecret).Client(oauth1.NoContext, oauth1.NewToken(cfg.AccessToken, cfg.AccessSecret))
+	tweetID, _, err := postTweetThread(twitter.NewClient(httpClient), twitterThread, withCard(httpClient, entry.Id, card, nil))
+	if err != nil {
+		return err
+	}
+
+	post := &compliance.ReportPost{TweetID: tweetID, FeatureID: entry.Id, Kind: change.Kind, Variant: variant, Timestamp: time.Now(), Link: link, ShortLink: shortLink}
+	if err := complianceStorageService.StoreReportPost(context.Background(), post); err != nil {
+		slog.Error("error remembering the posted report", "channel", compliance.ChannelTwitter, "entry", entry.Id, "feature", entry.Name, "err", err)
+	}
+
+	if err := complianceStorageService.SetReported(context.Background(), entry.Id, reportResendChannel); err != nil {
+		return err
+	}
+
+	if err := complianceStorageService.ClearReportAttempt(context.Background(), entry.Id, reportResendChannel); err != nil {
+		slog.Error("error clearing report attempts", "channel", reportResendChannel, "entry", entry.Id, "feature", entry.Name, "err", err)
+	}
+
+	slog.Info("reported entry again", "channel", reportResendChannel, "entry", entry.Id, "feature", entry.Name)
+
+	return nil
+}
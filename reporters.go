@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+)
+
+// reporter posts changes and maintainer alerts somewhere besides twitter. the tweet decides if a change counts as
+// reported, so failing to post a change on a reporter is only recorded, not retried
+type reporter interface {
+	ReportChange(change compliance.Change) error
+	Alert(message string)
+}
+
+// announcer is a reporter that takes reports about the bot itself, like how the objectives fare
+type announcer interface {
+	Announce(message string)
+}
+
+// summarizer is a reporter that takes the weekly summary of the changes, besides the changes themselves
+type summarizer interface {
+	PostSummary(text string) error
+}
+
+// reporterRegistration is how a reporter compiled into the binary is set up
+type reporterRegistration struct {
+	new     func(cfg *Configuration, options compliance.ReportOptions) reporter       //nil if the reporter isn't configured
+	lint    func(options compliance.ReportOptions, change compliance.Change) []string //what the reporter would fail to post of the change, for lint-reports
+	preview func(options compliance.ReportOptions, change compliance.Change) string   //what the reporter would post of the change, empty if nothing
+}
+
+// reporterRegistry holds the reporters compiled in. each registers itself from its own file, which a build tag like
+// noslack leaves out for a slimmer binary
+var reporterRegistry = map[string]reporterRegistration{}
+
+func registerReporter(name string, registration reporterRegistration) {
+	reporterRegistry[name] = registration
+}
+
+// compiledReporters gives the names of the reporters compiled in, sorted
+func compiledReporters() (names []string) {
+	for name := range reporterRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return
+}
+
+// namedReporter is a reporter with the name it is registered under, which ReportAfter refers to it by
+type namedReporter struct {
+	reporter
+	name  string
+	after []string //channels that must have posted a change before this one posts it
+}
+
+// reporters are the reporters changes and alerts go to, each after the channels it posts after
+type reporters []namedReporter
+
+// newReporters sets up the reporters named in Reporters, or all compiled in if it's empty. the ones not configured,
+// like Slack without a webhook, are left out. naming one that isn't compiled in is an error, and so is ReportAfter
+// making a reporter post after one that isn't set up
+func newReporters(cfg *Configuration, options compliance.ReportOptions) (reporters, error) {
+	names := cfg.Reporters
+	if len(names) == 0 {
+		names = compiledReporters()
+	}
+
+	var result reporters
+	for _, name := range names {
+		registration, ok := reporterRegistry[name]
+		if !ok {
+			compiled := strings.Join(compiledReporters(), ", ")
+			if compiled == "" {
+				compiled = "none"
+			}
+			return nil, fmt.Errorf("reporter %v is not compiled in, compiled in are: %v", name, compiled)
+		}
+
+		if configured := registration.new(cfg, options); configured != nil {
+			result = append(result, namedReporter{configured, name, cfg.ReportAfter[name]})
+		}
+	}
+
+	return orderReporters(result)
+}
+
+// orderReporters sorts the reporters so that every one comes after the channels it posts after, keeping their order
+// otherwise. twitter always posts first, so it is always met as a dependency
+func orderReporters(list reporters) (reporters, error) {
+	setUp := map[string]bool{compliance.ChannelTwitter: true}
+	for _, reporter := range list {
+		setUp[reporter.name] = true
+	}
+	for _, reporter := range list {
+		for _, dependency := range reporter.after {
+			if !setUp[dependency] {
+				return nil, fmt.Errorf("reporter %v posts after %v, which is not set up", reporter.name, dependency)
+			}
+		}
+	}
+
+	ordered := map[string]bool{compliance.ChannelTwitter: true}
+	var result reporters
+	for len(result) < len(list) {
+		progress := false
+		for _, reporter := range list {
+			ready := !ordered[reporter.name]
+			for _, dependency := range reporter.after {
+				ready = ready && ordered[dependency]
+			}
+			if ready {
+				result = append(result, reporter)
+				ordered[reporter.name] = true
+				progress = true
+			}
+		}
+
+		if !progress {
+			var waiting []string
+			for _, reporter := range list {
+				if !ordered[reporter.name] {
+					waiting = append(waiting, reporter.name)
+				}
+			}
+			return nil, fmt.Errorf("ReportAfter makes %v wait for each other", strings.Join(waiting, ", "))
+		}
+	}
+
+	return result, nil
+}
+
+// reportStatus is how posting a change went on a reporter
+type reportStatus struct {
+	channel string
+	status  string //compliance.ReportStatusReported, ReportStatusFailed or ReportStatusBlocked
+	err     error
+}
+
+// ReportChange posts the change on every reporter in order. reporters posting after a channel that didn't post it
+// leave it out as well. gives how it went on every one of them
+func (r reporters) ReportChange(change compliance.Change) (result []reportStatus) {
+	statuses := map[string]string{compliance.ChannelTwitter: compliance.ReportStatusReported}
+
+	for _, reporter := range r {
+		status := reportStatus{channel: reporter.name, status: compliance.ReportStatusReported}
+		for _, dependency := range reporter.after {
+			if statuses[dependency] != compliance.ReportStatusReported {
+				status.status = compliance.ReportStatusBlocked
+				status.err = fmt.Errorf("it was not posted on %v", dependency)
+				break
+			}
+		}
+
+		if status.status == compliance.ReportStatusReported {
+			if err := reporter.ReportChange(change); err != nil {
+				status.status = compliance.ReportStatusFailed
+				status.err = err
+			}
+		}
+
+		statuses[reporter.name] = status.status
+		result = append(result, status)
+	}
+
+	return
+}
+
+// reportOnChannels posts the change of the entry on the reporters, and records how it went on every one of them unless
+// it is a dry run
+func reportOnChannels(cfg *Configuration, service compliance.Service, channels reporters, entryID int64, change compliance.Change) {
+	for _, status := range channels.ReportChange(change) {
+		switch status.status {
+		case compliance.ReportStatusFailed:
+			slog.Error("error posting the change", "channel", status.channel, "entry", entryID, "feature", change.Feature.Name, "err", status.err)
+		case compliance.ReportStatusBlocked:
+			slog.Info("not posting the change", "channel", status.channel, "entry", entryID, "feature", change.Feature.Name, "reason", status.err)
+		}
+
+		//changes not worth reporting are left out by the reporters themselves, nothing to record
+		if cfg.DryReporting || !reportableKind(change.Kind) {
+			continue
+		}
+		if err := service.SetReportStatus(context.Background(), entryID, status.channel, status.status); err != nil {
+			slog.Error("error recording the report status", "channel", status.channel, "entry", entryID, "feature", change.Feature.Name, "err", err)
+		}
+	}
+}
+
+func (r reporters) Alert(message string) {
+	for _, reporter := range r {
+		reporter.Alert(message)
+	}
+}
+
+func (r reporters) Announce(message string) {
+	for _, named := range r {
+		if announcer, ok := named.reporter.(announcer); ok {
+			announcer.Announce(message)
+		}
+	}
+}
+
+// PostSummary posts the weekly summary on every reporter taking it, and gives the channels it failed on
+func (r reporters) PostSummary(text string) (failed []string) {
+	for _, named := range r {
+		if summarizer, ok := named.reporter.(summarizer); ok {
+			if err := summarizer.PostSummary(text); err != nil {
+				slog.Error("error posting the weekly summary", "channel", named.name, "err", err)
+				failed = append(failed, named.name)
+			}
+		}
+	}
+
+	return
+}
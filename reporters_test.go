@@ -0,0 +1,78 @@
+package main
+
+import (
+	"cppimpbot/compliance"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// testReporter is a reporter that fails to post with err, if set, and keeps the summaries it posted
+type testReporter struct {
+	err       error
+	summaries *[]string
+}
+
+func (r testReporter) ReportChange(change compliance.Change) error {
+	return r.err
+}
+
+func (r testReporter) Alert(message string) {
+}
+
+func (r testReporter) PostSummary(text string) error {
+	if r.err == nil && r.summaries != nil {
+		*r.summaries = append(*r.summaries, text)
+	}
+	return r.err
+}
+
+func TestOrderReporters(t *testing.T) {
+	channels, err := orderReporters(reporters{
+		{testReporter{}, "telegram", []string{"slack"}},
+		{testReporter{err: fmt.Errorf("webhook gone")}, "slack", []string{compliance.ChannelTwitter}},
+		{testReporter{}, "archive", nil},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base, supported := sampleFeature(), sampleSupported()
+	var got []string
+	for _, status := range channels.ReportChange(sampleChange(t, &base, &supported)) {
+		got = append(got, fmt.Sprintf("%v: %v %v", status.channel, status.status, status.err))
+	}
+	want := []string{"slack: failed webhook gone", "archive: reported <nil>", "telegram: blocked it was not posted on slack"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	for _, test := range []struct {
+		what string
+		list reporters
+		err  string
+	}{
+		{"cycle", reporters{{testReporter{}, "telegram", []string{"slack"}}, {testReporter{}, "slack", []string{"telegram"}}},
+			"ReportAfter makes telegram, slack wait for each other"},
+		{"missing", reporters{{testReporter{}, "telegram", []string{"mastodon"}}}, "reporter telegram posts after mastodon, which is not set up"},
+	} {
+		if _, err := orderReporters(test.list); err == nil || err.Error() != test.err {
+			t.Errorf("%v: got %v, want %v", test.what, err, test.err)
+		}
+	}
+}
+
+func TestPostSummary(t *testing.T) {
+	var summaries []string
+	failed := reporters{
+		{reporter: testReporter{summaries: &summaries}, name: "logged"},
+		{reporter: testReporter{err: fmt.Errorf("webhook is down")}, name: "failing"},
+	}.PostSummary("5 changes this week")
+
+	if !reflect.DeepEqual(failed, []string{"failing"}) {
+		t.Errorf("got failed channels %v, want [failing]", failed)
+	}
+	if !reflect.DeepEqual(summaries, []string{"5 changes this week"}) {
+		t.Errorf("got summaries %q", summaries)
+	}
+}
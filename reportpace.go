@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"cppimpbot/limits"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// newReportPace sets up the pace reports are posted at according to ReportMaxPerHour, ReportMinSpacing and
+// ReportQuietHours. the reports posted before a restart still count against it
+func newReportPace(cfg *Configuration, service compliance.Service) (*limits.Pace, error) {
+	pace, err := limits.NewPace(cfg.ReportMaxPerHour, time.Duration(cfg.ReportMinSpacing)*time.Second, cfg.ReportQuietHours)
+	if err != nil {
+		return nil, fmt.Errorf("invalid report pace: %v", err)
+	}
+
+	if service == nil {
+		return pace, nil
+	}
+
+	window := time.Hour
+	if spacing := time.Duration(cfg.ReportMinSpacing) * time.Second; spacing > window {
+		window = spacing
+	}
+
+	posts, err := service.GetReportPostsSince(context.Background(), time.Now().Add(-window))
+	if err != nil {
+		slog.Error("error getting recent reports, the pace starts over", "err", err)
+		return pace, nil
+	}
+
+	for _, post := range posts {
+		pace.Posted(post.Timestamp)
+	}
+
+	return pace, nil
+}
+
+// heldBack logs why reports are held back by the pace, at info level only when the reason changes so quiet hours
+// don't fill the log
+func heldBack(reason *string, why string, wait time.Duration, pending int) {
+	if *reason != why {
+		slog.Info("holding back reports", "channel", compliance.ChannelTwitter, "reason", why, "for", wait.Round(time.Second), "pending", pending)
+	} else {
+		slog.Debug("holding back reports", "channel", compliance.ChannelTwitter, "reason", why, "for", wait.Round(time.Second), "pending", pending)
+	}
+
+	*reason = why
+}
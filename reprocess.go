@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"cppimpbot/util"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var reprocessCommand = &cobra.Command{
+	Use:   "reprocess <new database>",
+	Short: "Replay the kept pages of the scrapes through the current parser into a fresh history, in a new database",
+	Long: `Replay the kept pages of the scrapes, oldest first, through the current parser and diffing into a fresh history of
+the features, written to a new database at the given path. The pages are copied along, so it can be reprocessed again.
+Nothing of the replayed history is reported, and pages the current parser reads a broken scrape out of are skipped.
+Once the new history looks right, for example with history list, stop the bot and swap it in for the Database. Posts,
+approvals and the rest of what the bot remembers are not carried over.`,
+	Args: cobra.ExactArgs(1),
+	RunE: reprocessCmdFunc,
+}
+
+var reprocessSource string
+
+func init() {
+	reprocessCommand.Flags().StringVar(&reprocessSource, "source", "", "only replay the pages of this source")
+}
+
+func reprocessCmdFunc(cmd *cobra.Command, args []string) error {
+	cfg := &Configuration{}
+
+	if err := viper.Unmarshal(cfg); err != nil {
+		return err
+	}
+
+	if cfg.StorageMode != "sqlite3" {
+		return fmt.Errorf("reprocess needs storageMode sqlite3, not %s", cfg.StorageMode)
+	}
+
+	target, err := filepath.Abs(args[0])
+	if err != nil {
+		return err
+	}
+	if current, err := filepath.Abs(cfg.Database); err == nil && current == target {
+		return fmt.Errorf("the history is reprocessed into a new database, not into %v itself", cfg.Database)
+	}
+	if _, err := os.Stat(target); err == nil {
+		return fmt.Errorf("%v exists already, the history is reprocessed into a new database", args[0])
+	}
+
+	complianceStorageService, err := openSqliteService(cfg)
+	if err != nil {
+		return err
+	}
+	defer complianceStorageService.Close(context.Background())
+
+	snapshots, err := complianceStorageService.GetScrapeSnapshots(context.Background(), reprocessSource, 0)
+	if err != nil {
+		return err
+	}
+	if len(snapshots) == 0 {
+		return fmt.Errorf("no pages kept to reprocess, they are kept with ScrapeSnapshots")
+	}
+
+	if err := util.SqliteMigrateUp(target, cfg.MigrateDir); err != nil {
+		return err
+	}
+
+	db, err := util.SqliteConnect(target)
+	if err != nil {
+		return err
+	}
+	freshService := compliance.NewSqliteService(db)
+	freshService.FullHistory = complianceStorageService.FullHistory
+	defer freshService.Close(context.Background())
+
+	replayed, err := reprocessSnapshots(cfg, complianceStorageService, freshService, snapshots)
+	if err != nil {
+		return err
+	}
+
+	latest, err := freshService.GetLatestEntries(context.Background())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("replayed %v of %v pages into %v, %v features\n", replayed, len(snapshots), args[0], len(latest))
+	return nil
+}
+
+// reprocessSnapshots replays the listed snapshots, newest first as they are listed, oldest first into the fresh service
+// and copies them along. it gives how many were replayed
+func reprocessSnapshots(cfg *Configuration, service compliance.Service, freshService *compliance.SqliteService, snapshots []compliance.ScrapeSnapshot) (int, error) {
+	replayed := 0
+
+	for i := len(snapshots) - 1; i >= 0; i-- {
+		snapshot, err := service.GetScrapeSnapshot(context.Background(), snapshots[i].Id)
+		if err != nil {
+			return replayed, err
+		}
+		if snapshot == nil {
+			continue
+		}
+
+		copied := *snapshot
+		if err := freshService.StoreScrapeSnapshot(context.Background(), &copied); err != nil {
+			return replayed, err
+		}
+
+		scraped, err := parseSnapshot(cfg, snapshot)
+		if err != nil {
+			slog.Warn("skipping page, it can't be parsed", "snapshot", snapshot.Id, "source", snapshot.Source, "err", err)
+			continue
+		}
+
+		stored, err := freshService.GetLatestEntries(context.Background())
+		if err != nil {
+			return replayed, err
+		}
+		if err := validateScrape(cfg, stored, scraped); err != nil {
+			slog.Warn("skipping page, it looks broken", "snapshot", snapshot.Id, "source", snapshot.Source, "err", err)
+			continue
+		}
+
+		slog.Info("replaying page", "snapshot", snapshot.Id, "source", snapshot.Source, "date", snapshot.Timestamp)
+
+		date := snapshot.Timestamp
+		freshService.Now = func() time.Time { return date }
+		storeScraped(freshService, scraped)
+
+		if err := setReplayedReported(freshService, date); err != nil {
+			return replayed, err
+		}
+
+		replayed++
+	}
+
+	return replayed, nil
+}
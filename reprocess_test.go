@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"cppimpbot/util"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// reprocessPage is a compiler support page listing a C++20 feature with the given GCC cell
+func reprocessPage(gcc string) string {
+	return `<html><body>
+<h3><span class="mw-headline">C++20 core language features</span></h3>
+<table><tr><th>C++20 feature</th><th>Paper(s)</th><th>GCC</th><th>Clang</th></tr>
+<tr><td>Replayed feature</td><td><a href="https://wg21.link/P0004R1">P0004R1</a></td>` + gcc + `<td class="table-no"></td></tr></table>
+</body></html>`
+}
+
+func TestReprocessSnapshots(t *testing.T) {
+	service := compliance.NewMemoryService()
+	//the page in the middle was taken while cppreference was down for maintenance
+	for i, page := range []string{reprocessPage(`<td class="table-no"></td>`), "<html><body></body></html>", reprocessPage(`<td class="table-yes">12</td>`)} {
+		snapshot, err := compliance.NewScrapeSnapshot("cppreference", time.Date(2020, 1, 1+i, 0, 0, 0, 0, time.UTC), fmt.Sprint(i), 1, page)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := service.StoreScrapeSnapshot(context.Background(), snapshot); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	db, err := util.SqliteConnect(newMigrateDataDatabase(t).Database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	freshService := compliance.NewSqliteService(db)
+	defer freshService.Close(context.Background())
+
+	snapshots, err := service.GetScrapeSnapshots(context.Background(), "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed, err := reprocessSnapshots(&Configuration{ScrapeMinKnown: 0.8}, service, freshService, snapshots); replayed != 2 || err != nil {
+		t.Fatalf("replayed %v of %v: %v", replayed, len(snapshots), err)
+	}
+
+	entries, err := freshService.GetEntriesSince(context.Background(), time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var history []string
+	for _, entry := range entries {
+		history = append(history, fmt.Sprintf("%v %v: gcc %v", entry.Timestamp.UTC().Format(historyTimeFormat), entry.Name, entry.SupportFor(compliance.CompilerGcc).Support))
+	}
+	want := fmt.Sprint([]string{"2020-01-01 00:00 Replayed feature: gcc 0", "2020-01-03 00:00 Replayed feature: gcc 1"})
+	if fmt.Sprint(history) != want {
+		t.Errorf("got history %v, want %v", history, want)
+	}
+
+	//replayed history was reported long ago
+	if unreported, err := freshService.GetUnreported(context.Background(), compliance.ChannelTwitter); len(unreported) != 0 || err != nil {
+		t.Errorf("got %v unreported, %v", len(unreported), err)
+	}
+	if copied, err := freshService.GetScrapeSnapshots(context.Background(), "", 0); len(copied) != 3 || err != nil {
+		t.Errorf("copied %v of 3 pages, %v", len(copied), err)
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"cppimpbot/compliance"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var resumeReportingCommand = &cobra.Command{
+	Use:   "resume-reporting",
+	Short: "Resume reporting after safe mode paused it, with all or only the approved pending reports",
+	Long: `List the reports waiting since safe mode paused reporting.
+With --all reporting resumes and all of them are posted. With --review they are shown one by one to approve or skip,
+and reporting resumes once all are decided. Skipped reports are never posted. The running bot picks the decision up on
+its next tick.`,
+	Args: cobra.NoArgs,
+	RunE: resumeReportingCmdFunc,
+}
+
+var resumeReportingAll bool
+var resumeReportingReview bool
+
+func init() {
+	resumeReportingCommand.Flags().BoolVar(&resumeReportingAll, "all", false, "resume with all pending reports")
+	resumeReportingCommand.Flags().BoolVar(&resumeReportingReview, "review", false, "approve or skip the pending reports one by one before resuming")
+}
+
+// resyncReportQueue drops the entries skipped while reporting was paused from an external report queue
+func resyncReportQueue(service compliance.Service) {
+	queued, ok := service.(*compliance.QueuedService)
+	if !ok {
+		return
+	}
+
+	if err := queued.Sync(context.Background()); err != nil {
+		slog.Error("error syncing the report queue, skipped reports may still be posted", "err", err)
+	}
+}
+
+// pendingChange gives the report the pending entry would get, empty if it is not worth reporting
+func pendingChange(cfg *Configuration, reportOptions compliance.ReportOptions, service compliance.Service, entry *compliance.Feature) (string, error) {
+	previous, err := service.GetPreviousEntry(context.Background(), entry.Id)
+	if err != nil {
+		return "", err
+	}
+
+	change, err := compliance.DiffFeatures(previous, entry)
+	if err != nil {
+		return "", err
+	}
+
+	return compliance.DefaultReportText(reportOptions, reportedChange(cfg, change), ""), nil
+}
+
+// reviewPending asks about every pending entry whether to report it, and gives the ones to skip. ok is false if the
+// maintainer quit before deciding all of them
+func reviewPending(cfg *Configuration, reportOptions compliance.ReportOptions, service compliance.Service, entries []compliance.Feature) (skip []int64, ok bool, err error) {
+	input := bufio.NewReader(os.Stdin)
+
+	for i := range entries {
+		entry := &entries[i]
+
+		text, err := pendingChange(cfg, reportOptions, service, entry)
+		if err != nil {
+			return nil, false, err
+		}
+		if text == "" {
+			text = "(not worth reporting, would be marked as reported without a post)"
+		}
+
+		fmt.Printf("\n%v of %v: entry %v, %v \"%v\" scraped %v\n%v\n", i+1, len(entries), entry.Id, entry.Standard(), entry.Name, entry.Timestamp.Format(historyTimeFormat), text)
+
+		for {
+			fmt.Print("report it? [y]es, [n]o, [q]uit: ")
+			answer, err := input.ReadString('\n')
+			if err != nil {
+				return nil, false, err
+			}
+
+			switch strings.ToLower(strings.TrimSpace(answer)) {
+			case "y", "yes":
+			case "n", "no":
+				skip = append(skip, entry.Id)
+			case "q", "quit":
+				return nil, false, nil
+			default:
+				continue
+			}
+			break
+		}
+	}
+
+	return skip, true, nil
+}
+
+func resumeReportingCmdFunc(cmd *cobra.Command, args []string) error {
+	cfg := &Configuration{}
+
+	if err := viper.Unmarshal(cfg); err != nil {
+		return err
+	}
+
+	if cfg.StorageMode != "sqlite3" {
+		return fmt.Errorf("resume-reporting needs storageMode sqlite3, not %s", cfg.StorageMode)
+	}
+
+	if resumeReportingAll && resumeReportingReview {
+		return fmt.Errorf("either resume with --all or --review the pending reports, not both")
+	}
+
+	complianceStorageService, err := openSqliteService(cfg)
+	if err != nil {
+		return err
+	}
+	defer complianceStorageService.Close(context.Background())
+
+	reportOptions, err := loadReportOptions(cfg)
+	if err != nil {
+		return err
+	}
+
+	pause, err := complianceStorageService.GetReportingPause(context.Background())
+	if err != nil {
+		return err
+	}
+	if !pause.Paused() {
+		fmt.Println("reporting is not paused")
+		return nil
+	}
+
+	entries, err := complianceStorageService.GetUnreported(context.Background(), compliance.ChannelTwitter)
+	if err != nil {
+		return err
+	}
+
+	if !resumeReportingAll && !resumeReportingReview {
+		fmt.Printf("reporting is paused since %v, when %v reports were waiting. waiting now:\n\n", pause.PausedAt.Format(historyTimeFormat), pause.Pending)
+
+		writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(writer, "entry\tstandard\tname\tscraped\treport")
+		for i := range entries {
+			text, err := pendingChange(cfg, reportOptions, complianceStorageService, &entries[i])
+			if err != nil {
+				text = err.Error()
+			}
+			summary := "-"
+			if text != "" {
+				summary = strings.SplitN(text, "\n", 2)[0]
+			}
+			fmt.Fprintf(writer, "%v\t%v\t%v\t%v\t%v\n", entries[i].Id, entries[i].Standard(), entries[i].Name, entries[i].Timestamp.Format(historyTimeFormat), summary)
+		}
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+
+		fmt.Println("\nresume with --all to report all of them, or --review to approve them one by one")
+		return nil
+	}
+
+	var skip []int64
+	if resumeReportingReview {
+		var ok bool
+		skip, ok, err = reviewPending(cfg, reportOptions, complianceStorageService, entries)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Println("nothing decided, reporting stays paused")
+			return nil
+		}
+	}
+
+	for _, id := range skip {
+		if err := complianceStorageService.SetReportSkipped(context.Background(), id, compliance.ChannelTwitter); err != nil {
+			return err
+		}
+		slog.Info("skipped entry", "channel", compliance.ChannelTwitter, "entry", id)
+	}
+
+	var acknowledged int64
+	for _, entry := range entries {
+		if entry.Id > acknowledged {
+			acknowledged = entry.Id
+		}
+	}
+
+	if err := complianceStorageService.ResumeReporting(context.Background(), acknowledged); err != nil {
+		return err
+	}
+	slog.Info("resumed reporting", "reports", len(entries)-len(skip), "pending", len(entries))
+
+	return nil
+}
@@ -0,0 +1,166 @@
+package schedule
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// shortcuts of cron expressions
+var cronShortcuts = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Cron is a cron expression with the five fields minute, hour, day of month, month and day of week, like "17 */2 * * *"
+// for every two hours at :17 or "0 9-17 * * 1-5" for every hour during business hours. fields are numbers, ranges,
+// lists and steps, days of the week from 0 for Sunday to 7 for Sunday again. the times are in the local time zone
+type Cron struct {
+	spec       string
+	minutes    uint64
+	hours      uint64
+	days       uint64
+	months     uint64
+	weekdays   uint64
+	anyDay     bool //the day of month is *
+	anyWeekday bool //the day of week is *
+}
+
+// ParseCron reads a cron expression, or a shortcut like @hourly or @daily
+func ParseCron(spec string) (*Cron, error) {
+	expression := strings.TrimSpace(spec)
+	if shortcut, ok := cronShortcuts[strings.ToLower(expression)]; ok {
+		expression = shortcut
+	}
+
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return nil, errors.Errorf("cron expression %q needs 5 fields, minute hour day month weekday", spec)
+	}
+
+	c := &Cron{spec: spec, anyDay: fields[2] == "*", anyWeekday: fields[4] == "*"}
+
+	var err error
+	if c.minutes, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, errors.Wrapf(err, "invalid minute in %q", spec)
+	}
+	if c.hours, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, errors.Wrapf(err, "invalid hour in %q", spec)
+	}
+	if c.days, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, errors.Wrapf(err, "invalid day of month in %q", spec)
+	}
+	if c.months, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, errors.Wrapf(err, "invalid month in %q", spec)
+	}
+	if c.weekdays, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, errors.Wrapf(err, "invalid day of week in %q", spec)
+	}
+	if c.weekdays&(1<<7) != 0 {
+		c.weekdays |= 1 //7 is Sunday too
+	}
+
+	if c.Next(time.Now()).IsZero() {
+		return nil, errors.Errorf("cron expression %q never matches", spec)
+	}
+
+	return c, nil
+}
+
+// parseCronField reads a comma separated list of values, ranges like 9-17 and steps like */15 or 0-30/10 into a bit
+// per value
+func parseCronField(field string, min int, max int) (uint64, error) {
+	var result uint64
+
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step < 1 {
+				return 0, errors.Errorf("invalid step %q", part[i+1:])
+			}
+			part = part[:i]
+		}
+
+		low, high := min, max
+		switch {
+		case part == "*":
+		case strings.Contains(part, "-"):
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			if low, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, errors.Errorf("invalid value %q", bounds[0])
+			}
+			if high, err = strconv.Atoi(bounds[1]); err != nil {
+				return 0, errors.Errorf("invalid value %q", bounds[1])
+			}
+		default:
+			value, err := strconv.Atoi(part)
+			if err != nil {
+				return 0, errors.Errorf("invalid value %q", part)
+			}
+			low = value
+			if step == 1 {
+				high = value
+			}
+		}
+
+		if low < min || high > max || low > high {
+			return 0, errors.Errorf("%q is out of range %v-%v", part, min, max)
+		}
+
+		for value := low; value <= high; value += step {
+			result |= 1 << uint(value)
+		}
+	}
+
+	return result, nil
+}
+
+func (c *Cron) String() string {
+	return c.spec
+}
+
+func (c *Cron) dayMatches(t time.Time) bool {
+	day := c.days&(1<<uint(t.Day())) != 0
+	weekday := c.weekdays&(1<<uint(t.Weekday())) != 0
+
+	//like cron, if both are restricted a day matching either one does
+	if !c.anyDay && !c.anyWeekday {
+		return day || weekday
+	}
+
+	return day && weekday
+}
+
+// Next gives the first time after the given one that the expression matches, or the zero time if it matches none in
+// the next five years
+func (c *Cron) Next(after time.Time) time.Time {
+	after = after.In(time.Local)
+	t := time.Date(after.Year(), after.Month(), after.Day(), after.Hour(), after.Minute(), 0, 0, after.Location()).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		switch {
+		case c.months&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case c.hours&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case c.minutes&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
@@ -0,0 +1,56 @@
+package schedule
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCron(t *testing.T) {
+	now := time.Date(2024, 3, 1, 18, 40, 0, 0, time.Local) //a Friday
+
+	for _, test := range []struct {
+		spec    string
+		next    string
+		then    string
+		longest time.Duration
+	}{
+		{"0 */2 * * *", "Fri 2024-03-01 20:00", "Fri 2024-03-01 22:00", 2 * time.Hour},
+		{"17 * * * *", "Fri 2024-03-01 19:17", "Fri 2024-03-01 20:17", time.Hour},
+		{"*/5 9-17 * * 1-5", "Mon 2024-03-04 09:00", "Mon 2024-03-04 09:05", 63*time.Hour + 5*time.Minute},
+		{"0 0 1,15 * *", "Fri 2024-03-15 00:00", "Mon 2024-04-01 00:00", 408 * time.Hour},
+		{"0 12 * * 7", "Sun 2024-03-03 12:00", "Sun 2024-03-10 12:00", 168 * time.Hour},
+		{"@daily", "Sat 2024-03-02 00:00", "Sun 2024-03-03 00:00", 24 * time.Hour},
+	} {
+		cron, err := ParseCron(test.spec)
+		if err != nil {
+			t.Errorf("%q: %v", test.spec, err)
+			continue
+		}
+
+		next := cron.Next(now)
+		if next.Format("Mon 2006-01-02 15:04") != test.next || cron.Next(next).Format("Mon 2006-01-02 15:04") != test.then {
+			t.Errorf("%q: next at %v, then %v, want %v, then %v", test.spec, next, cron.Next(next), test.next, test.then)
+		}
+
+		job := Job{Cron: cron}
+		if longest := job.Period(now); longest != test.longest {
+			t.Errorf("%q: longest wait %v, want %v", test.spec, longest, test.longest)
+		}
+	}
+}
+
+func TestCronInvalid(t *testing.T) {
+	for _, test := range []struct {
+		spec string
+		err  string
+	}{
+		{"0 0 30 2 *", `cron expression "0 0 30 2 *" never matches`},
+		{"61 * * * *", `invalid minute in "61 * * * *": "61" is out of range 0-59`},
+		{"* * *", `cron expression "* * *" needs 5 fields, minute hour day month weekday`},
+	} {
+		if _, err := ParseCron(test.spec); err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%q: got %v, want %v", test.spec, err, test.err)
+		}
+	}
+}
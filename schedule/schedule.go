@@ -0,0 +1,201 @@
+package schedule
+
+import (
+	"log/slog"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Job is something the scheduler runs periodically. a job never runs concurrently with itself
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Jitter   time.Duration //a random delay up to this is added to every wait
+	Aligned  bool          //if set, runs are at Offset into every Interval counted from midnight UTC, not an Interval apart
+	Offset   time.Duration //like 17 minutes with an Interval of an hour, to run every hour at :17
+	Cron     *Cron         //if set, runs are at the times it matches instead, Interval and alignment aren't used
+	Once     bool          //if set, the job runs a single time right after starting instead of periodically
+	Run      func()
+}
+
+// JobStatus is how a job fared so far
+type JobStatus struct {
+	Name         string
+	Interval     time.Duration
+	Schedule     string //cron expression the job runs on, empty if it runs every Interval
+	Runs         int
+	Running      bool
+	LastRun      time.Time
+	LastDuration time.Duration
+	NextRun      time.Time //zero for jobs that run once
+}
+
+// Scheduler runs all periodic work of the bot, each job on its own interval
+type Scheduler struct {
+	jobs     []Job
+	triggers []chan struct{}
+	quit     chan struct{}
+	wg       sync.WaitGroup
+
+	mutex  sync.Mutex
+	status []JobStatus
+}
+
+func New() *Scheduler {
+	return &Scheduler{
+		quit: make(chan struct{}),
+	}
+}
+
+func (s *Scheduler) Add(job Job) {
+	s.jobs = append(s.jobs, job)
+	s.triggers = append(s.triggers, make(chan struct{}, 1))
+	status := JobStatus{Name: job.Name, Interval: job.Interval}
+	if job.Cron != nil {
+		status.Schedule = job.Cron.String()
+	}
+	s.status = append(s.status, status)
+}
+
+func (s *Scheduler) Start() {
+	for i, job := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(job, i)
+	}
+}
+
+// Trigger makes the periodic jobs whose name matches run right away instead of when their interval is up, after which
+// their interval starts over, or they wait for their next aligned run. gives how many jobs were triggered
+func (s *Scheduler) Trigger(match func(name string) bool) int {
+	triggered := 0
+
+	for i, job := range s.jobs {
+		if job.Once || !match(job.Name) {
+			continue
+		}
+
+		select {
+		case s.triggers[i] <- struct{}{}:
+		default: //triggered already and not run yet
+		}
+		triggered++
+	}
+
+	return triggered
+}
+
+// Status gives how every job fared so far, in the order they were added
+func (s *Scheduler) Status() []JobStatus {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]JobStatus(nil), s.status...)
+}
+
+// Stop makes all jobs stop and waits for running ones to finish
+func (s *Scheduler) Stop() {
+	close(s.quit)
+	s.wg.Wait()
+}
+
+// NextDelay gives how long the job waits from now until its next run, jitter included
+func (j *Job) NextDelay(now time.Time) time.Duration {
+	delay := j.Interval
+	if j.Cron != nil {
+		delay = j.Cron.Next(now).Sub(now)
+	} else if j.Aligned && j.Interval > 0 {
+		//truncating counts from the zero time, which for intervals dividing a day is the same as from midnight UTC
+		next := now.Truncate(j.Interval).Add(j.Offset % j.Interval)
+		if !next.After(now) {
+			next = next.Add(j.Interval)
+		}
+		delay = next.Sub(now)
+	}
+	if j.Jitter > 0 {
+		delay += time.Duration(rand.Int63n(int64(j.Jitter)))
+	}
+	return delay
+}
+
+// Period gives the longest wait between runs of the job without jitter. for cron schedules it is the longest one between
+// the runs of the next eight days, which covers the nights and weekends of a schedule like every hour in business hours
+func (j *Job) Period(now time.Time) time.Duration {
+	if j.Cron == nil {
+		return j.Interval
+	}
+
+	horizon := now.AddDate(0, 0, 8)
+	var result time.Duration
+	var last time.Time
+	for next := j.Cron.Next(now); !next.IsZero(); next = j.Cron.Next(next) {
+		if !last.IsZero() {
+			if next.Sub(last) > result {
+				result = next.Sub(last)
+			}
+			if next.After(horizon) {
+				break
+			}
+		}
+		last = next
+	}
+
+	return result
+}
+
+// run runs the job and keeps track of how it went
+func (s *Scheduler) run(job *Job, index int) {
+	start := time.Now()
+	s.mutex.Lock()
+	s.status[index].Running = true
+	s.mutex.Unlock()
+
+	job.Run()
+
+	s.mutex.Lock()
+	s.status[index].Running = false
+	s.status[index].Runs++
+	s.status[index].LastRun = start
+	s.status[index].LastDuration = time.Since(start)
+	s.mutex.Unlock()
+}
+
+func (s *Scheduler) loop(job Job, index int) {
+	defer s.wg.Done()
+
+	if job.Once {
+		slog.Info("running job once", "job", job.Name)
+		s.run(&job, index)
+		return
+	}
+
+	if job.Cron != nil {
+		slog.Info("starting job", "job", job.Name, "schedule", job.Cron.String(), "jitter", job.Jitter)
+	} else if job.Aligned {
+		slog.Info("starting job", "job", job.Name, "interval", job.Interval, "offset", job.Offset, "jitter", job.Jitter)
+	} else {
+		slog.Info("starting job", "job", job.Name, "interval", job.Interval, "jitter", job.Jitter)
+	}
+
+	for {
+		delay := job.NextDelay(time.Now())
+		timer := time.NewTimer(delay)
+
+		s.mutex.Lock()
+		s.status[index].NextRun = time.Now().Add(delay)
+		s.mutex.Unlock()
+
+		select {
+		case <-timer.C:
+			s.run(&job, index)
+		case <-s.triggers[index]:
+			timer.Stop()
+			slog.Info("running job now as it was triggered", "job", job.Name)
+			s.run(&job, index)
+		case <-s.quit:
+			timer.Stop()
+			slog.Info("stopping job", "job", job.Name)
+			return
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"cppimpbot/scraper"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var scrapeCommand = &cobra.Command{
+	Use:   "scrape",
+	Short: "Scrape the compiler support page once, or parse a saved copy of it, and print the result",
+	RunE:  scrapeCmdFunc,
+}
+
+var scrapeFile string
+var scrapeStore bool
+
+func init() {
+	scrapeCommand.Flags().StringVar(&scrapeFile, "file", "", "parse this saved html page instead of fetching cppreference")
+	scrapeCommand.Flags().BoolVar(&scrapeStore, "store", false, "record the parsed features in the database like the service does")
+}
+
+func printScraped(scraped scraper.CppSupport) {
+	for _, version := range scraped.Versions {
+		fmt.Printf("%v (%v features)\n", compliance.StandardName(version.Language, version.Version), len(version.Features))
+
+		for _, feature := range version.Features {
+			fmt.Printf("  %v [%v %v]\n", feature.Name, feature.PaperName, feature.PaperLink)
+
+			for _, support := range feature.Compilers {
+				key := compliance.CompilerKey(support.Compiler)
+				if version.Library {
+					key = compliance.LibraryKey(support.Compiler)
+				}
+
+				fmt.Printf("    %v: %v %v", key, compliance.SupportLevelName(support.Support), support.DisplayString)
+				if support.ExtraString != "" {
+					fmt.Printf(" (%v)", support.ExtraString)
+				}
+				fmt.Println()
+			}
+		}
+	}
+
+	if len(scraped.Errors) > 0 {
+		fmt.Printf("skipped %v rows:\n%v\n", len(scraped.Errors), scraper.ErrorReport(scraped.Errors))
+	}
+}
+
+func scrapeCmdFunc(cmd *cobra.Command, args []string) error {
+	cfg := &Configuration{}
+
+	if err := viper.Unmarshal(cfg); err != nil {
+		return err
+	}
+
+	var scraped scraper.CppSupport
+
+	if scrapeFile != "" {
+		file, err := os.Open(scrapeFile)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		if scraped, err = scraper.ScrapeFromReader(file, cfg.StrictParsing, cfg.ScrapeDefectReports); err != nil {
+			return err
+		}
+	} else {
+		cppScraper, err := newScraper(cfg, "cppreference", cfg.ScrapeUrl)
+		if err != nil {
+			return err
+		}
+
+		if scraped, err = cppScraper.Scrape(); err != nil {
+			return err
+		}
+	}
+
+	printScraped(scraped)
+
+	if !scrapeStore {
+		return nil
+	}
+
+	if cfg.StorageMode != "sqlite3" {
+		return fmt.Errorf("storing needs storageMode sqlite3, not %s", cfg.StorageMode)
+	}
+
+	complianceStorageService, err := openSqliteService(cfg)
+	if err != nil {
+		return err
+	}
+	defer complianceStorageService.Close(context.Background())
+
+	stored, err := complianceStorageService.GetLatestEntries(context.Background())
+	if err != nil {
+		return err
+	}
+	if err := validateScrape(cfg, stored, scraped); err != nil {
+		return fmt.Errorf("not storing the scrape, it looks broken: %v", err)
+	}
+
+	storeScraped(complianceStorageService, scraped)
+
+	return nil
+}
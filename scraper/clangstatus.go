@@ -0,0 +1,60 @@
+package scraper
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// DefaultClangStatusURL is the page Clang keeps on its own support of the C++ standards
+const DefaultClangStatusURL = "https://clang.llvm.org/cxx_status.html"
+
+// ClangStatusScraper fetches and parses the C++ status page of Clang, to cross-check cppreference with. its tables
+// have the support of Clang as their only compiler column, named Clang
+type ClangStatusScraper struct {
+	*Scraper
+}
+
+// NewClangStatusScraper creates a scraper of the C++ status page of Clang at url through client. zero values fall back
+// to http.DefaultClient and DefaultClangStatusURL
+func NewClangStatusScraper(client *http.Client, url string) *ClangStatusScraper {
+	if url == "" {
+		url = DefaultClangStatusURL
+	}
+
+	return &ClangStatusScraper{NewScraper(client, url)}
+}
+
+// Scrape fetches the status page, retrying according to the retry policy, and parses it
+func (s *ClangStatusScraper) Scrape() (CppSupport, error) {
+	return ScrapeSource(s)
+}
+
+// clangSupport reads the support level of a status cell by its class, like "full" or "none", or else by its text,
+// which names the first Clang supporting the proposal or says "No". support in a Clang yet to be released, classed
+// "unreleased", counts as support like on cppreference, which lists it already. rows of proposals that don't apply to
+// Clang, classed "na", are skipped
+func clangSupport(cell *goquery.Selection) (int, bool) {
+	text := strings.ToLower(strings.TrimSpace(cell.Text()))
+
+	switch {
+	case cell.HasClass("na"):
+		return 0, false
+	case cell.HasClass("partial") || strings.Contains(text, "partial"):
+		return 2, true
+	case cell.HasClass("none"):
+		return 0, true
+	case cell.HasClass("full") || cell.HasClass("unreleased"):
+		return 1, true
+	case strings.HasPrefix(text, "clang "):
+		return 1, true
+	}
+
+	return 0, true
+}
+
+// Parse reads the table of every C++ standard out of the status page
+func (s *ClangStatusScraper) Parse(document *goquery.Document) (CppSupport, error) {
+	return parseStatusPage(document, s.URL, "Clang", clangSupport)
+}
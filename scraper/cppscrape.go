@@ -2,28 +2,14 @@ package scraper
 
 import (
 	"fmt"
-	"log"
-	"net/http"
+	"log/slog"
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
 )
 
-func parseCppVersion(text string) (int, error) {
-	if strings.Contains(text, "11") {
-		return 11, nil
-	} else if strings.Contains(text, "14") {
-		return 14, nil
-	} else if strings.Contains(text, "17") {
-		return 17, nil
-	} else if strings.Contains(text, "2a") || strings.Contains(text, "20") {
-		return 20, nil
-	}
-
-	return 0, fmt.Errorf("could not parse CPP version from '%s'", text)
-}
-
 type CompilerSupport struct {
+	Compiler      string
 	Support       int
 	DisplayString string
 	ExtraString   string
@@ -34,18 +20,31 @@ type CppFeature struct {
 	PaperName string
 	PaperLink string
 
-	GccSupport   CompilerSupport
-	ClangSupport CompilerSupport
-	MsvcSupport  CompilerSupport
+	Compilers []CompilerSupport
 }
 
 type CppVersionSupport struct {
+	Language string //LanguageCpp, LanguageC or LanguageCppDR, by the heading of the table
 	Version  int
 	Features []CppFeature
+
+	//library tables list standard library implementations in their columns instead of compilers
+	Library bool
+
+	//the table lists defect reports instead of the features of a standard, with a DR where features have a paper. its
+	//Language is LanguageCppDR and its Version 0, as a DR applies to every standard it affects
+	DefectReports bool
+
+	//how the table looked, used to notice when the page layout changes
+	Title   string
+	Headers []string
 }
 
 type CppSupport struct {
 	Versions []CppVersionSupport
+
+	//rows skipped by the strict parser
+	Errors []RowError
 }
 
 func supportFromElement(element *goquery.Selection) int {
@@ -58,38 +57,131 @@ func supportFromElement(element *goquery.Selection) int {
 	}
 }
 
-func ScrapeCppSupport() (result CppSupport, err error) {
-	// Make HTTP request
-	siteLink := "https://en.cppreference.com/w/cpp/compiler_support"
-	response, err := http.Get(siteLink)
-	if err != nil {
-		log.Printf("%v\n", err)
-		return
+func compilerSupportFromElement(compiler string, element *goquery.Selection) CompilerSupport {
+	supportsString := element.Text()
+	supportsString = strings.TrimSpace(supportsString)
+	supportsStringExtra := element.Children().First().AttrOr("title", "")
+	supportsStringExtra = strings.TrimSpace(supportsStringExtra)
+
+	return CompilerSupport{
+		Compiler:      compiler,
+		Support:       supportFromElement(element),
+		DisplayString: supportsString,
+		ExtraString:   supportsStringExtra,
 	}
-	defer response.Body.Close()
+}
+
+// tableHeaders gives the texts of the heading row of the table, one per column
+func tableHeaders(rows []tableRow) []string {
+	var headers []string
+
+	for _, row := range rows {
+		if !row.heading {
+			continue
+		}
 
-	// Create a goquery document from the HTTP response
-	document, err := goquery.NewDocumentFromReader(response.Body)
-	if err != nil {
-		log.Printf("Error loading HTTP response body: %v\n", err)
-		return
+		for column := range row.cells {
+			headers = append(headers, strings.TrimSpace(cellAt(row, column).Text()))
+		}
+
+		break
 	}
 
+	return headers
+}
+
+// tableColumns is where the rows of a table have the feature name, the paper and the support of every compiler
+type tableColumns struct {
+	feature   int
+	paper     int
+	compilers []string //compiler names indexed by column, empty for the feature and paper columns
+}
+
+// columnsOf works out what the columns of a table hold from its headers, like "C++20 feature", "Paper(s)" and "GCC",
+// so that a column inserted or moved on cppreference doesn't shift the data of a row into the wrong places. tables
+// missing the feature or paper column, or without any compiler, can't be read. in tables of defect reports the title of
+// a DR takes the place of the feature and the DR itself, like "DR" or "CWG issue", the place of the paper
+func columnsOf(headers []string, defectReports bool) (tableColumns, error) {
+	columns := tableColumns{feature: -1, paper: -1, compilers: make([]string, len(headers))}
+
+	isFeature := func(header string) bool {
+		return strings.Contains(header, "feature") || defectReports && strings.Contains(header, "title")
+	}
+	isPaper := func(header string) bool {
+		return strings.HasPrefix(header, "paper") ||
+			defectReports && (strings.HasPrefix(header, "dr") || strings.Contains(header, "issue") || strings.Contains(header, "defect"))
+	}
+
+	hasCompilers := false
+	for i, header := range headers {
+		lower := strings.ToLower(header)
+
+		switch {
+		case columns.feature < 0 && isFeature(lower):
+			columns.feature = i
+		case columns.paper < 0 && isPaper(lower):
+			columns.paper = i
+		case header != "":
+			columns.compilers[i] = header
+			hasCompilers = true
+		}
+	}
+
+	switch {
+	case columns.feature < 0:
+		return columns, fmt.Errorf("has no feature column among the headers [%v]", strings.Join(headers, " | "))
+	case columns.paper < 0:
+		return columns, fmt.Errorf("has no paper column among the headers [%v]", strings.Join(headers, " | "))
+	case !hasCompilers:
+		return columns, fmt.Errorf("has no compiler columns among the headers [%v]", strings.Join(headers, " | "))
+	}
+
+	return columns, nil
+}
+
+// headerRow gives the heading row of the table, the first one with th cells
+func headerRow(table *goquery.Selection) *goquery.Selection {
+	return table.Find("tr").FilterFunction(func(index int, rowElement *goquery.Selection) bool {
+		return rowElement.Has("th").Length() > 0
+	}).First()
+}
+
+// parseCppSupport reads the feature tables of every C++ or C version out of the compiler support page, and the table
+// of C++ defect reports if defectReports is set. in strict mode rows that would need guessing are skipped and listed in
+// the result's Errors instead
+func parseCppSupport(document *goquery.Document, strict bool, defectReports bool) (result CppSupport, err error) {
 	document.Find(".mw-headline").Each(func(index int, element *goquery.Selection) {
 		titleText := element.Text()
 
-		if !strings.Contains(titleText, "features") {
-			return
-		}
+		versionData := CppVersionSupport{}
 
-		cppVersion, err := parseCppVersion(titleText)
-		if err != nil {
-			log.Print(err)
+		switch {
+		case IsDefectReportHeading(titleText):
+			if !defectReports {
+				return
+			}
+			if !strings.Contains(titleText, "C++") {
+				slog.Debug("skipping table, only defect reports against C++ are read", "table", strings.TrimSpace(titleText))
+				return
+			}
+
+			versionData.Language = LanguageCppDR
+			versionData.DefectReports = true
+		case strings.Contains(titleText, "features"):
+			language, cppVersion, err := ParseStandard(titleText)
+			if err != nil {
+				slog.Warn("skipping table", "err", err)
+				return
+			}
+
+			versionData.Language = language
+			versionData.Version = cppVersion
+		default:
 			return
 		}
 
-		versionData := CppVersionSupport{}
-		versionData.Version = cppVersion
+		versionData.Title = strings.TrimSpace(titleText)
+		versionData.Library = strings.Contains(strings.ToLower(titleText), "library")
 
 		table := element.Parent()
 
@@ -109,23 +201,37 @@ func ScrapeCppSupport() (result CppSupport, err error) {
 			println("had no table...")
 		}
 
-		table.Find("tr").Each(func(rowIndex int, rowElement *goquery.Selection) {
-			isHeading := rowElement.Has("th").Length() > 0
+		rows := tableRows(table)
+		versionData.Headers = tableHeaders(rows)
 
-			if isHeading {
-				return
+		//a table that can't be read is left out whole rather than read wrong, which the maintainer hears about
+		columns, err := columnsOf(versionData.Headers, versionData.DefectReports)
+		if err != nil {
+			result.Errors = append(result.Errors, newRowError(versionData.Title, 0, headerRow(table), err.Error()+", so the whole table was skipped"))
+			return
+		}
+
+		for _, row := range rows {
+			if row.heading {
+				continue
 			}
 
-			featureData := CppFeature{}
+			if strict {
+				if reason := checkRow(row, versionData.Headers, columns); reason != "" {
+					result.Errors = append(result.Errors, newRowError(versionData.Title, row.index, row.element, reason))
+					continue
+				}
+			}
 
-			titleDataElement := rowElement.Children().First()
+			featureData := CppFeature{}
+			titleDataElement := cellAt(row, columns.feature)
 			featureTitle := titleDataElement.Text()
 			featureTitle = strings.TrimSpace(featureTitle)
 
 			featureData.Name = featureTitle
 
-			paperDataElement := titleDataElement.Next()
-			hrefElement := paperDataElement.First().Children().First()
+			paperDataElement := cellAt(row, columns.paper)
+			hrefElement := paperDataElement.Children().First()
 			featurePaperTitle := hrefElement.Text()
 			featurePaperTitle = strings.TrimSpace(featurePaperTitle)
 			featurePaperLink := hrefElement.AttrOr("href", "NO LINK")
@@ -134,52 +240,31 @@ func ScrapeCppSupport() (result CppSupport, err error) {
 			featureData.PaperName = featurePaperTitle
 			featureData.PaperLink = featurePaperLink
 
-			//paperDataElement.Next() //version data element
-
-			gccDataElement := paperDataElement.Next()
-			gccSupports := supportFromElement(gccDataElement)
-			gccSupportsString := gccDataElement.Text()
-			gccSupportsString = strings.TrimSpace(gccSupportsString)
-			gccSupportsStringExtra := gccDataElement.Children().First().AttrOr("title", "")
-			gccSupportsStringExtra = strings.TrimSpace(gccSupportsStringExtra)
-
-			featureData.GccSupport.Support = gccSupports
-			featureData.GccSupport.DisplayString = gccSupportsString
-			featureData.GccSupport.ExtraString = gccSupportsStringExtra
-
-			clangDataElement := gccDataElement.Next()
-			clangSupports := supportFromElement(clangDataElement)
-			clangSupportsString := clangDataElement.Text()
-			clangSupportsString = strings.TrimSpace(clangSupportsString)
-			clangSupportsStringExtra := clangDataElement.Children().First().AttrOr("title", "")
-			clangSupportsStringExtra = strings.TrimSpace(clangSupportsStringExtra)
-
-			featureData.ClangSupport.Support = clangSupports
-			featureData.ClangSupport.DisplayString = clangSupportsString
-			featureData.ClangSupport.ExtraString = clangSupportsStringExtra
-
-			msvcDataElement := clangDataElement.Next()
-			msvcSupports := supportFromElement(msvcDataElement)
-			msvcSupportsString := msvcDataElement.Text()
-			msvcSupportsString = strings.TrimSpace(msvcSupportsString)
-			msvcSupportsStringExtra := msvcDataElement.Children().First().AttrOr("title", "")
-			msvcSupportsStringExtra = strings.TrimSpace(msvcSupportsStringExtra)
-
-			featureData.MsvcSupport.Support = msvcSupports
-			featureData.MsvcSupport.DisplayString = msvcSupportsString
-			featureData.MsvcSupport.ExtraString = msvcSupportsStringExtra
+			//the other columns are the compilers, named by the table headers
+			for columnIndex, compilerDataElement := range row.cells {
+				if columnIndex >= len(columns.compilers) || columns.compilers[columnIndex] == "" || compilerDataElement == nil {
+					continue
+				}
+
+				featureData.Compilers = append(featureData.Compilers, compilerSupportFromElement(columns.compilers[columnIndex], compilerDataElement))
+			}
 
 			//fmt.Printf("href elem:%v\n", goquery.NodeName(hrefElement))
 			//fmt.Printf("title: %v, paper: %v, link: %v\n", featureTitle, featurePaperTitle, featurePaperLink)
-			//fmt.Printf("  gcc support: %v - %v (%v)\n", gccSupports, gccSupportsString, gccSupportsStringExtra)
-			//fmt.Printf("  clang support: %v - %v (%v)\n", clangSupports, clangSupportsString, clangSupportsStringExtra)
-			//fmt.Printf("  msvc support: %v - %v (%v)\n", msvcSupports, msvcSupportsString, msvcSupportsStringExtra)
+			//for _, compiler := range featureData.Compilers {
+			//	fmt.Printf("  %v support: %v - %v (%v)\n", compiler.Compiler, compiler.Support, compiler.DisplayString, compiler.ExtraString)
+			//}
 
 			versionData.Features = append(versionData.Features, featureData)
-		})
+		}
 
 		result.Versions = append(result.Versions, versionData)
 	})
 
+	//a page without any feature table is not something to draw conclusions from
+	if len(result.Versions) == 0 {
+		return result, MaintenanceError{"no feature tables found on the page"}
+	}
+
 	return result, nil
 }
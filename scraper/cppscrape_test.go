@@ -0,0 +1,115 @@
+package scraper
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// spanningPage has cells spanning rows and columns
+const spanningPage = `<html><body>
+<h3><span class="mw-headline">C++20 core language features</span></h3>
+<table><tr><th>C++20 feature</th><th>Paper(s)</th><th>GCC</th><th>Clang</th><th>MSVC</th></tr>
+<tr><td rowspan="2">Grouped feature</td><td><a href="https://wg21.link/P0002R1">P0002R1</a></td><td class="table-yes" rowspan="2">10</td><td class="table-no"></td><td class="table-yes">19.20</td></tr>
+<tr><td><a href="https://wg21.link/P0003R1">P0003R1</a></td><td class="table-yes">12</td><td class="table-no"></td></tr>
+<tr><td>Shared support</td><td><a href="https://wg21.link/P0004R1">P0004R1</a></td><td class="table-yes" colspan="2">11</td><td class="table-maybe">19.28</td></tr></table>
+</body></html>`
+
+// scrapedFeatures lists the features of every table like "C++20: Name P0001R1 [{GCC 1 10 }]"
+func scrapedFeatures(support CppSupport) []string {
+	var result []string
+	for _, version := range support.Versions {
+		for _, feature := range version.Features {
+			result = append(result, fmt.Sprintf("%v: %v %v %v", version.Title, feature.Name, feature.PaperName, feature.Compilers))
+		}
+	}
+
+	return result
+}
+
+func TestScrapeColumns(t *testing.T) {
+	page := `<html><body>
+<h3><span class="mw-headline">C++20 core language features</span></h3>
+<table><tr><th>MSVC</th><th>C++20 feature</th><th>GCC</th><th>Paper(s)</th></tr>
+<tr><td class="table-yes">19.20</td><td>Reordered columns</td><td class="table-no"></td><td><a href="https://wg21.link/P0001R1">P0001R1</a></td></tr></table>
+<h3><span class="mw-headline">C++23 core language features</span></h3>
+<table><tr><th>C++23 feature</th><th>GCC</th><th>Clang</th></tr>
+<tr><td>No paper column</td><td class="table-yes">12</td><td class="table-no"></td></tr></table>
+</body></html>`
+
+	support, err := ScrapeFromReader(strings.NewReader(page), false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"C++20 core language features: Reordered columns P0001R1 [{MSVC 1 19.20 } {GCC 0  }]"}
+	if features := scrapedFeatures(support); !reflect.DeepEqual(features, want) {
+		t.Errorf("got features %q, want %q", features, want)
+	}
+	if len(support.Errors) != 1 || support.Errors[0].Error() != "row 0 of 'C++23 core language features': has no paper column among the headers [C++23 feature | GCC | Clang], so the whole table was skipped" {
+		t.Errorf("got errors %v", support.Errors)
+	}
+}
+
+func TestScrapeSpanningCells(t *testing.T) {
+	want := []string{
+		"C++20 core language features: Grouped feature P0002R1 [{GCC 1 10 } {Clang 0  } {MSVC 1 19.20 }]",
+		"C++20 core language features: Grouped feature P0003R1 [{GCC 1 10 } {Clang 1 12 } {MSVC 0  }]",
+		"C++20 core language features: Shared support P0004R1 [{GCC 1 11 } {Clang 1 11 } {MSVC 2 19.28 }]",
+	}
+
+	for _, strict := range []bool{false, true} {
+		support, err := ScrapeFromReader(strings.NewReader(spanningPage), strict, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if features := scrapedFeatures(support); !reflect.DeepEqual(features, want) {
+			t.Errorf("strict %v: got features %q, want %q", strict, features, want)
+		}
+		if len(support.Errors) != 0 {
+			t.Errorf("strict %v: got errors %v", strict, support.Errors)
+		}
+	}
+}
+
+func TestScrapeDefectReports(t *testing.T) {
+	page := `<html><body>
+<h3><span class="mw-headline">C++20 core language features</span></h3>
+<table><tr><th>C++20 feature</th><th>Paper(s)</th><th>GCC</th><th>Clang</th></tr>
+<tr><td>A feature</td><td><a href="https://wg21.link/P0005R1">P0005R1</a></td><td class="table-yes">10</td><td class="table-no"></td></tr></table>
+<h3><span class="mw-headline">C++ defect reports</span></h3>
+<table><tr><th>DR</th><th>Title</th><th>GCC</th><th>Clang</th></tr>
+<tr><td><a href="https://cplusplus.github.io/CWG/issues/2518.html">CWG2518</a></td><td>Conformance requirements and #error/#warning</td><td class="table-yes">13</td><td class="table-no"></td></tr></table>
+</body></html>`
+
+	for _, test := range []struct {
+		defectReports bool
+		tables        []string
+	}{
+		{false, []string{"C++20 core language features (cpp 20) has 1 rows"}},
+		{true, []string{"C++20 core language features (cpp 20) has 1 rows", "C++ defect reports (cppdr 0) has 1 rows"}},
+	} {
+		support, err := ScrapeFromReader(strings.NewReader(page), true, test.defectReports)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var tables []string
+		for _, version := range support.Versions {
+			tables = append(tables, fmt.Sprintf("%v (%v %v) has %v rows", version.Title, version.Language, version.Version, len(version.Features)))
+		}
+		if !reflect.DeepEqual(tables, test.tables) || len(support.Errors) != 0 {
+			t.Errorf("defect reports %v: got tables %q with errors %v, want %q", test.defectReports, tables, support.Errors, test.tables)
+		}
+
+		if test.defectReports {
+			reports := support.Versions[1]
+			feature := reports.Features[0]
+			if !reports.DefectReports || feature.Name != "Conformance requirements and #error/#warning" || feature.PaperName != "CWG2518" {
+				t.Errorf("got defect report %+v of a table with DefectReports %v", feature, reports.DefectReports)
+			}
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package scraper
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TableFingerprint describes the structure of one feature table
+type TableFingerprint struct {
+	Section string
+	Headers []string
+	Rows    int
+}
+
+// Fingerprint describes the structure of the scraped page, without the data in it
+type Fingerprint struct {
+	Tables []TableFingerprint
+}
+
+func FingerprintOf(support CppSupport) Fingerprint {
+	result := Fingerprint{}
+
+	for _, version := range support.Versions {
+		result.Tables = append(result.Tables, TableFingerprint{
+			Section: version.Title,
+			Headers: version.Headers,
+			Rows:    len(version.Features),
+		})
+	}
+
+	return result
+}
+
+func (f Fingerprint) table(section string) *TableFingerprint {
+	for i := range f.Tables {
+		if f.Tables[i].Section == section {
+			return &f.Tables[i]
+		}
+	}
+
+	return nil
+}
+
+// Drift lists how next differs structurally from previous. sections and headers must match exactly,
+// row counts may change by up to maxRowChange (a fraction of the previous count) since features are added over time
+func Drift(previous Fingerprint, next Fingerprint, maxRowChange float64) (drift []string) {
+	for _, previousTable := range previous.Tables {
+		nextTable := next.table(previousTable.Section)
+
+		if nextTable == nil {
+			drift = append(drift, fmt.Sprintf("section '%v' disappeared", previousTable.Section))
+			continue
+		}
+
+		previousHeaders := strings.Join(previousTable.Headers, " | ")
+		nextHeaders := strings.Join(nextTable.Headers, " | ")
+		if previousHeaders != nextHeaders {
+			drift = append(drift, fmt.Sprintf("headers of '%v' changed from [%v] to [%v]", previousTable.Section, previousHeaders, nextHeaders))
+		}
+
+		rowChange := nextTable.Rows - previousTable.Rows
+		if rowChange < 0 {
+			rowChange = -rowChange
+		}
+		if previousTable.Rows > 0 && float64(rowChange)/float64(previousTable.Rows) > maxRowChange {
+			drift = append(drift, fmt.Sprintf("rows of '%v' changed from %v to %v", previousTable.Section, previousTable.Rows, nextTable.Rows))
+		}
+	}
+
+	for _, nextTable := range next.Tables {
+		if previous.table(nextTable.Section) == nil {
+			drift = append(drift, fmt.Sprintf("section '%v' appeared", nextTable.Section))
+		}
+	}
+
+	return
+}
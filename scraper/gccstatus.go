@@ -0,0 +1,56 @@
+package scraper
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// DefaultGccStatusURL is the page GCC keeps on its own support of the C++ standards
+const DefaultGccStatusURL = "https://gcc.gnu.org/projects/cxx-status.html"
+
+// GccStatusScraper fetches and parses the C++ status page of GCC, to cross-check cppreference with. its tables have
+// the support of GCC as their only compiler column, named GCC
+type GccStatusScraper struct {
+	*Scraper
+}
+
+// NewGccStatusScraper creates a scraper of the C++ status page of GCC at url through client. zero values fall back to
+// http.DefaultClient and DefaultGccStatusURL
+func NewGccStatusScraper(client *http.Client, url string) *GccStatusScraper {
+	if url == "" {
+		url = DefaultGccStatusURL
+	}
+
+	return &GccStatusScraper{NewScraper(client, url)}
+}
+
+// Scrape fetches the status page, retrying according to the retry policy, and parses it
+func (s *GccStatusScraper) Scrape() (CppSupport, error) {
+	return ScrapeSource(s)
+}
+
+// gccSupport reads the support level of a status cell by its class, like "supported", or else by its text, which
+// names the first GCC version supporting the proposal or says "No"
+func gccSupport(cell *goquery.Selection) (int, bool) {
+	text := strings.ToLower(strings.TrimSpace(cell.Text()))
+
+	switch {
+	case cell.HasClass("partial") || strings.Contains(text, "partial"):
+		return 2, true
+	case cell.HasClass("unsupported"):
+		return 0, true
+	case cell.HasClass("supported"):
+		return 1, true
+	case text != "" && text[0] >= '0' && text[0] <= '9':
+		return 1, true
+	}
+
+	return 0, true
+}
+
+// Parse reads the table of every C++ standard out of the status page
+func (s *GccStatusScraper) Parse(document *goquery.Document) (CppSupport, error) {
+	return parseStatusPage(document, s.URL, "GCC", gccSupport)
+}
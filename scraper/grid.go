@@ -0,0 +1,105 @@
+package scraper
+
+import (
+	"strconv"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// tableRow is a row of a table as it is shown, with a cell per column. cells spanning several rows or columns appear in
+// every one of them, and columns no cell covers are left nil
+type tableRow struct {
+	index   int //position among the tr elements of the table
+	element *goquery.Selection
+	heading bool
+	cells   []*goquery.Selection
+}
+
+// span gives how many rows or columns a cell spans by the attribute, like "rowspan". missing and broken values span one
+func span(cell *goquery.Selection, attribute string) int {
+	count, err := strconv.Atoi(cell.AttrOr(attribute, "1"))
+	if err != nil || count < 1 {
+		return 1
+	}
+
+	return count
+}
+
+// tableRows expands the rowspan and colspan of the cells of a table, so that the n-th cell of every row is in the n-th
+// column. reading the cells of a tr by position instead gives the values of grouped features to the wrong columns
+func tableRows(table *goquery.Selection) []tableRow {
+	type pending struct {
+		cell *goquery.Selection
+		rows int //rows below still covered by the cell
+	}
+	var spanning []*pending
+
+	//cells of rows above taking up the column, if any
+	carried := func(column int) *goquery.Selection {
+		if column >= len(spanning) || spanning[column] == nil {
+			return nil
+		}
+
+		cell := spanning[column].cell
+		if spanning[column].rows--; spanning[column].rows == 0 {
+			spanning[column] = nil
+		}
+
+		return cell
+	}
+
+	var rows []tableRow
+	table.Find("tr").Each(func(rowIndex int, rowElement *goquery.Selection) {
+		row := tableRow{
+			index:   rowIndex,
+			element: rowElement,
+			heading: rowElement.Has("th").Length() > 0,
+		}
+
+		column := 0
+		rowElement.Children().Each(func(cellIndex int, cell *goquery.Selection) {
+			for {
+				above := carried(column)
+				if above == nil {
+					break
+				}
+				row.cells = append(row.cells, above)
+				column++
+			}
+
+			rowSpan := span(cell, "rowspan")
+			for i := 0; i < span(cell, "colspan"); i++ {
+				row.cells = append(row.cells, cell)
+
+				if rowSpan > 1 {
+					for len(spanning) <= column {
+						spanning = append(spanning, nil)
+					}
+					spanning[column] = &pending{cell, rowSpan - 1}
+				}
+				column++
+			}
+		})
+
+		//cells from above at the end of the row, with a gap where nothing covers the columns in between
+		for ; column < len(spanning); column++ {
+			row.cells = append(row.cells, carried(column))
+		}
+		for len(row.cells) > 0 && row.cells[len(row.cells)-1] == nil {
+			row.cells = row.cells[:len(row.cells)-1]
+		}
+
+		rows = append(rows, row)
+	})
+
+	return rows
+}
+
+// cellAt gives the cell of the row in the column, an empty selection for columns no cell covers
+func cellAt(row tableRow, column int) *goquery.Selection {
+	if column >= len(row.cells) || row.cells[column] == nil {
+		return &goquery.Selection{}
+	}
+
+	return row.cells[column]
+}
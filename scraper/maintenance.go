@@ -0,0 +1,51 @@
+package scraper
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// MaintenanceError means cppreference served something other than the real page, like a maintenance notice or a login wall.
+// scraping should back off for a while instead of retrying right away or parsing it
+type MaintenanceError struct {
+	Reason string
+}
+
+func (e MaintenanceError) Error() string {
+	return "cppreference seems to be under maintenance: " + e.Reason
+}
+
+func IsMaintenance(err error) bool {
+	_, ok := err.(MaintenanceError)
+	return ok
+}
+
+var maintenanceTitleWords = []string{"maintenance", "log in", "login required", "permission error", "read-only"}
+
+func isWikiPage(document *goquery.Document) bool {
+	return document.Find("#mw-content-text, .mediawiki, #cpp-content-base").Length() > 0
+}
+
+// detectMaintenance checks a fetched page for signs that it isn't the real compiler support page
+func detectMaintenance(statusCode int, document *goquery.Document) error {
+	title := strings.ToLower(strings.TrimSpace(document.Find("title").Text()))
+
+	if statusCode == http.StatusServiceUnavailable && isWikiPage(document) {
+		return MaintenanceError{fmt.Sprintf("wiki served status %v with title '%v'", statusCode, title)}
+	}
+
+	for _, word := range maintenanceTitleWords {
+		if strings.Contains(title, word) {
+			return MaintenanceError{fmt.Sprintf("page title is '%v'", title)}
+		}
+	}
+
+	if document.Find("#userloginForm, form[name=userlogin]").Length() > 0 {
+		return MaintenanceError{"page is a login form"}
+	}
+
+	return nil
+}
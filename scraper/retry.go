@@ -0,0 +1,67 @@
+package scraper
+
+import (
+	"log/slog"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy decides how often and how long apart failed fetches are tried again
+type RetryPolicy struct {
+	MaxAttempts int           //total attempts, including the first one
+	Backoff     time.Duration //wait before the first retry. doubled for every following one
+	MaxBackoff  time.Duration //upper bound of the doubled wait
+	Jitter      time.Duration //a random delay up to this is added to every wait
+}
+
+var NoRetry = RetryPolicy{MaxAttempts: 1}
+
+// temporaryError marks failures that are worth trying again, like network errors and 5xx responses
+type temporaryError struct {
+	err error
+}
+
+func (e temporaryError) Error() string {
+	return e.err.Error()
+}
+
+func (p RetryPolicy) delay(retry int) time.Duration {
+	delay := p.Backoff
+	for i := 0; i < retry && (p.MaxBackoff <= 0 || delay < p.MaxBackoff); i++ {
+		delay *= 2
+	}
+
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+
+	if p.Jitter > 0 {
+		delay += time.Duration(rand.Int63n(int64(p.Jitter)))
+	}
+
+	return delay
+}
+
+// do runs fn until it succeeds, fails with an error that isn't temporary, or runs out of attempts
+func (p RetryPolicy) do(fn func() error) error {
+	var err error
+
+	for attempt := 0; ; attempt++ {
+		err = fn()
+
+		temporary, isTemporary := err.(temporaryError)
+		if err == nil {
+			return nil
+		} else if !isTemporary {
+			return err
+		}
+
+		if attempt+1 >= p.MaxAttempts {
+			return temporary.err
+		}
+
+		delay := p.delay(attempt)
+		slog.Warn("attempt failed, retrying", "attempt", attempt+1, "delay", delay, "err", err)
+		time.Sleep(delay)
+	}
+}
@@ -0,0 +1,170 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+const DefaultURL = "https://en.cppreference.com/w/cpp/compiler_support"
+
+// DefaultCURL is the compiler support page of C, which has the same layout
+const DefaultCURL = "https://en.cppreference.com/w/c/compiler_support"
+
+// ErrNotModified is returned by Scrape when the page didn't change since the Validators were taken
+var ErrNotModified = errors.New("page not modified")
+
+// Validators identify a version of the page for conditional requests
+type Validators struct {
+	ETag         string
+	LastModified string
+}
+
+// Scraper fetches and parses the compiler support page, the Source of cppreference
+type Scraper struct {
+	Client    *http.Client
+	URL       string
+	UserAgent string        //sent with every request if not empty
+	Timeout   time.Duration //per request, including reading the body. no timeout if zero
+	Retry     RetryPolicy
+	Strict    bool //skip rows that can't be parsed without guessing, see CppSupport.Errors
+
+	//also read the table of C++ defect reports, see CppVersionSupport.DefectReports
+	DefectReports bool
+
+	//name the source is configured as, the URL if empty
+	SourceName string
+
+	//sent along to only get the page if it changed. updated with those of the fetched page by every successful Fetch
+	Validators Validators
+}
+
+// NewScraper creates a scraper fetching url through client. zero values fall back to http.DefaultClient and DefaultURL
+func NewScraper(client *http.Client, url string) *Scraper {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if url == "" {
+		url = DefaultURL
+	}
+
+	return &Scraper{
+		Client: client,
+		URL:    url,
+		Retry:  NoRetry,
+	}
+}
+
+// fetchDocument downloads and parses the page, classifying failures as temporary where a retry might help
+func (s *Scraper) fetchDocument() (*goquery.Document, error) {
+	ctx := context.Background()
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.Timeout)
+		defer cancel()
+	}
+
+	request, err := http.NewRequest(http.MethodGet, s.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	request = request.WithContext(ctx)
+
+	if s.UserAgent != "" {
+		request.Header.Set("User-Agent", s.UserAgent)
+	}
+	if s.Validators.ETag != "" {
+		request.Header.Set("If-None-Match", s.Validators.ETag)
+	}
+	if s.Validators.LastModified != "" {
+		request.Header.Set("If-Modified-Since", s.Validators.LastModified)
+	}
+
+	response, err := s.Client.Do(request)
+	if err != nil {
+		return nil, temporaryError{err}
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotModified {
+		return nil, ErrNotModified
+	}
+
+	// Create a goquery document from the HTTP response
+	document, err := goquery.NewDocumentFromReader(response.Body)
+	if err != nil {
+		return nil, temporaryError{fmt.Errorf("error loading HTTP response body: %v", err)}
+	}
+
+	if err := detectMaintenance(response.StatusCode, document); err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode >= 500 || response.StatusCode == http.StatusTooManyRequests {
+		return nil, temporaryError{fmt.Errorf("got status %v from %v", response.Status, s.URL)}
+	} else if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("got status %v from %v", response.Status, s.URL)
+	}
+
+	s.Validators = Validators{
+		ETag:         response.Header.Get("ETag"),
+		LastModified: response.Header.Get("Last-Modified"),
+	}
+
+	return document, nil
+}
+
+// Name gives the name the source is configured as
+func (s *Scraper) Name() string {
+	if s.SourceName == "" {
+		return s.URL
+	}
+
+	return s.SourceName
+}
+
+// Conditional gives the page and the validators it is fetched with
+func (s *Scraper) Conditional() (string, *Validators) {
+	return s.URL, &s.Validators
+}
+
+// Fetch gets the page, retrying according to the retry policy
+func (s *Scraper) Fetch() (*goquery.Document, error) {
+	var document *goquery.Document
+
+	err := s.Retry.do(func() (err error) {
+		document, err = s.fetchDocument()
+		return
+	})
+	if err != nil && err != ErrNotModified {
+		slog.Error("error fetching page", "url", s.URL, "err", err)
+	}
+
+	return document, err
+}
+
+// Parse reads the feature tables out of the page
+func (s *Scraper) Parse(document *goquery.Document) (CppSupport, error) {
+	return parseCppSupport(document, s.Strict, s.DefectReports)
+}
+
+// Scrape fetches the page, retrying according to the retry policy, and parses it
+func (s *Scraper) Scrape() (CppSupport, error) {
+	return ScrapeSource(s)
+}
+
+// ScrapeFromReader parses a saved copy of the compiler support page, with the defect reports if defectReports is set
+func ScrapeFromReader(reader io.Reader, strict bool, defectReports bool) (CppSupport, error) {
+	document, err := goquery.NewDocumentFromReader(reader)
+	if err != nil {
+		return CppSupport{}, err
+	}
+
+	return parseCppSupport(document, strict, defectReports)
+}
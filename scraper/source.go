@@ -0,0 +1,53 @@
+package scraper
+
+import (
+	"fmt"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Source is a page the support of compilers is scraped from, like the compiler support page of cppreference. pages
+// laid out differently, like the C++ status page of GCC, get sources of their own
+type Source interface {
+	//Name is what the source is configured as and its fingerprints and alerts are named after, like cppreference
+	Name() string
+
+	//Fetch gets the page, or ErrNotModified if it is known to be unchanged
+	Fetch() (*goquery.Document, error)
+
+	//Parse reads the support of compilers out of a page, fetched or saved
+	Parse(document *goquery.Document) (CppSupport, error)
+}
+
+// ConditionalSource is a source that only fetches its page if it changed since the validators were taken. url is what
+// the validators are stored by, and Fetch sends validators along and updates them with those of the page it got
+type ConditionalSource interface {
+	Source
+	Conditional() (url string, validators *Validators)
+}
+
+// ScrapeSource fetches the page of the source and parses it
+func ScrapeSource(source Source) (CppSupport, error) {
+	document, err := source.Fetch()
+	if err != nil {
+		return CppSupport{}, err
+	}
+
+	return source.Parse(document)
+}
+
+// ScrapeSourcePage fetches the page of the source and parses it like ScrapeSource, giving the html of the page too
+func ScrapeSourcePage(source Source) (CppSupport, string, error) {
+	document, err := source.Fetch()
+	if err != nil {
+		return CppSupport{}, "", err
+	}
+
+	page, err := document.Html()
+	if err != nil {
+		return CppSupport{}, "", fmt.Errorf("could not render the page: %v", err)
+	}
+
+	scraped, err := source.Parse(document)
+	return scraped, page, err
+}
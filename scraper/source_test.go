@@ -0,0 +1,83 @@
+package scraper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// listSource serves a fixed page listing features as items of the standard they are part of, naming no compilers
+type listSource struct {
+	page string
+}
+
+func (s listSource) Name() string {
+	return "list"
+}
+
+func (s listSource) Fetch() (*goquery.Document, error) {
+	return goquery.NewDocumentFromReader(strings.NewReader(s.page))
+}
+
+func (s listSource) Parse(document *goquery.Document) (CppSupport, error) {
+	var result CppSupport
+
+	document.Find("li").Each(func(index int, element *goquery.Selection) {
+		version, _ := strconv.Atoi(element.AttrOr("data-version", ""))
+		result.Versions = append(result.Versions, CppVersionSupport{
+			Language: LanguageCpp,
+			Version:  version,
+			Features: []CppFeature{{Name: element.Text()}},
+		})
+	})
+
+	return result, nil
+}
+
+func TestScrapeSource(t *testing.T) {
+	support, err := ScrapeSource(listSource{`<ul><li data-version="23">Listed elsewhere</li></ul>`})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []CppVersionSupport{{Language: LanguageCpp, Version: 23, Features: []CppFeature{{Name: "Listed elsewhere"}}}}
+	if !reflect.DeepEqual(support.Versions, want) {
+		t.Errorf("got %+v, want %+v", support.Versions, want)
+	}
+
+	if _, ok := Source(listSource{}).(ConditionalSource); ok {
+		t.Errorf("source without validators is conditional")
+	}
+}
+
+func TestScraperConditional(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(spanningPage))
+	}))
+	defer server.Close()
+
+	scraper := NewScraper(nil, server.URL)
+	support, err := ScrapeSource(scraper)
+	if err != nil || len(support.Versions) != 1 {
+		t.Fatalf("first scrape got %v tables, %v", len(support.Versions), err)
+	}
+
+	url, validators := scraper.Conditional()
+	if url != server.URL || *validators != (Validators{ETag: `"v1"`}) {
+		t.Errorf("got validators %+v of %v", *validators, url)
+	}
+
+	if _, err := ScrapeSource(scraper); err != ErrNotModified {
+		t.Errorf("scrape of an unchanged page gave %v", err)
+	}
+}
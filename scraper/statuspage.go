@@ -0,0 +1,120 @@
+package scraper
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// statusColumns is where the rows of a table of a status page have the feature name, its proposals and the support of
+// the compiler
+type statusColumns struct {
+	feature  int
+	proposal int
+	compiler int
+}
+
+// statusColumnsOf works out what the columns of a table of the status page of the compiler hold from its headers, like
+// "Language Feature", "C++26 Proposal", "Available in GCC?" and "SD-6 Feature Test"
+func statusColumnsOf(headers []string, compiler string) (statusColumns, error) {
+	columns := statusColumns{feature: -1, proposal: -1, compiler: -1}
+
+	for i, header := range headers {
+		lower := strings.ToLower(header)
+
+		switch {
+		case columns.feature < 0 && strings.Contains(lower, "feature") && !strings.Contains(lower, "test"):
+			columns.feature = i
+		case columns.proposal < 0 && (strings.Contains(lower, "proposal") || strings.Contains(lower, "paper")):
+			columns.proposal = i
+		case columns.compiler < 0 && (strings.Contains(lower, strings.ToLower(compiler)) || strings.Contains(lower, "available")):
+			columns.compiler = i
+		}
+	}
+
+	if columns.feature < 0 || columns.proposal < 0 || columns.compiler < 0 {
+		return columns, fmt.Errorf("lacks the feature, proposal or %v column among the headers [%v]", compiler, strings.Join(headers, " | "))
+	}
+
+	return columns, nil
+}
+
+// parseStatusPage reads the table of every C++ standard out of the status page a compiler keeps on its own support,
+// found at url, with the compiler as the only compiler column. a row becomes a feature per proposal it links to, as
+// compilers list some features with several proposals that cppreference lists one by one. support reads the level of
+// a support cell, and tells if the cell is about support at all
+func parseStatusPage(document *goquery.Document, url string, compiler string, support func(cell *goquery.Selection) (int, bool)) (result CppSupport, err error) {
+	document.Find("h2").Each(func(index int, heading *goquery.Selection) {
+		titleText := strings.TrimSpace(heading.Text())
+
+		//like the technical specifications, or the standards from before cppreference has tables of
+		match := cppVersionPattern.FindStringSubmatch(titleText)
+		if match == nil || match[1] == "98" || match[1] == "03" {
+			return
+		}
+
+		version, err := ParseCppVersion(titleText)
+		if err != nil {
+			slog.Warn("skipping table", "url", url, "err", err)
+			return
+		}
+
+		table := heading.NextUntil("h2").Filter("table").First()
+		if table.Length() == 0 {
+			return
+		}
+
+		rows := tableRows(table)
+		versionData := CppVersionSupport{
+			Language: LanguageCpp,
+			Version:  version,
+			Title:    titleText,
+			Headers:  tableHeaders(rows),
+		}
+
+		columns, err := statusColumnsOf(versionData.Headers, compiler)
+		if err != nil {
+			result.Errors = append(result.Errors, newRowError(titleText, 0, headerRow(table), err.Error()+", so the whole table was skipped"))
+			return
+		}
+
+		for _, row := range rows {
+			if row.heading {
+				continue
+			}
+
+			status := cellAt(row, columns.compiler)
+			level, ok := support(status)
+			if !ok {
+				continue
+			}
+
+			name := strings.TrimSpace(cellAt(row, columns.feature).Text())
+			compilerSupport := CompilerSupport{
+				Compiler:      compiler,
+				Support:       level,
+				DisplayString: strings.TrimSpace(status.Text()),
+			}
+
+			cellAt(row, columns.proposal).Find("a").Each(func(linkIndex int, link *goquery.Selection) {
+				versionData.Features = append(versionData.Features, CppFeature{
+					Name:      name,
+					PaperName: strings.TrimSpace(link.Text()),
+					PaperLink: strings.TrimSpace(link.AttrOr("href", "")),
+					Compilers: []CompilerSupport{compilerSupport},
+				})
+			})
+		}
+
+		result.Versions = append(result.Versions, versionData)
+	})
+
+	//an empty result would look like every disagreement with cppreference was resolved
+	if len(result.Versions) == 0 {
+		return result, fmt.Errorf("no C++ status tables found on %v", url)
+	}
+
+	return result, nil
+}
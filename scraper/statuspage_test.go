@@ -0,0 +1,97 @@
+package scraper
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func statusDocument(t *testing.T, page string) *goquery.Document {
+	t.Helper()
+
+	document, err := goquery.NewDocumentFromReader(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return document
+}
+
+func TestGccStatus(t *testing.T) {
+	page := `<html><body>
+<h2 id="cxx23">C++23 Support in GCC</h2>
+<p>GCC has experimental support for the latest revision of the C++ standard.</p>
+<table class="cxxstatus">
+<tr class="separator"><th>Language Feature</th><th>Proposal</th><th>Available in GCC?</th><th>SD-6 Feature Test</th></tr>
+<tr><td>Deducing this</td><td><a href="https://wg21.link/P0847R7">P0847R7</a></td><td class="supported">14</td><td>__cpp_explicit_this_parameter</td></tr>
+<tr><td rowspan="2">Grouped feature</td><td><a href="https://wg21.link/P0002R1">P0002R1</a> <a href="https://wg21.link/P0003R1">P0003R1</a></td><td class="partial">12 (partial)</td><td></td></tr>
+<tr><td><a href="https://wg21.link/P0004R1">P0004R1</a></td><td class="unsupported">No</td><td></td></tr>
+</table>
+<h2 id="tses">Technical Specifications</h2>
+<h2 id="cxx98">C++98 Support in GCC</h2>
+</body></html>`
+
+	scraper := NewGccStatusScraper(nil, "")
+	support, err := scraper.Parse(statusDocument(t, page))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//a row of several papers lists each of them
+	want := []string{
+		"C++23 Support in GCC: Deducing this P0847R7 [{GCC 1 14 }]",
+		"C++23 Support in GCC: Grouped feature P0002R1 [{GCC 2 12 (partial) }]",
+		"C++23 Support in GCC: Grouped feature P0003R1 [{GCC 2 12 (partial) }]",
+		"C++23 Support in GCC: Grouped feature P0004R1 [{GCC 0 No }]",
+	}
+	if features := scrapedFeatures(support); !reflect.DeepEqual(features, want) {
+		t.Errorf("got features %q, want %q", features, want)
+	}
+	if version := support.Versions[0]; version.Language != LanguageCpp || version.Version != 23 {
+		t.Errorf("got table of %v %v", version.Language, version.Version)
+	}
+
+	_, err = scraper.Parse(statusDocument(t, "<html><body><h2>Down for maintenance</h2></body></html>"))
+	if err == nil || err.Error() != fmt.Sprintf("no C++ status tables found on %v", DefaultGccStatusURL) {
+		t.Errorf("page without tables gave %v", err)
+	}
+}
+
+func TestClangStatus(t *testing.T) {
+	page := `<html><body>
+<h2 id="cxx26">C++2c implementation status</h2>
+<p>Clang has support for some of the features of the C++ standard following C++23.</p>
+<table width="689" border="1" cellspacing="0">
+ <tr><th>Language Feature</th><th>C++26 Proposal</th><th>Available in Clang?</th></tr>
+ <tr><td>Pack Indexing</td><td><a href="https://wg21.link/P2662R3">P2662R3</a></td><td class="full" align="center">Clang 19</td></tr>
+ <tr><td>Trivially relocatable</td><td><a href="https://wg21.link/P2786R13">P2786R13</a></td><td class="unreleased" align="center">Clang 21</td></tr>
+ <tr><td>Contracts</td><td><a href="https://wg21.link/P2900R14">P2900R14</a></td><td class="none" align="center">No</td></tr>
+ <tr><td>Only for other implementations</td><td><a href="https://wg21.link/P0005R1">P0005R1</a></td><td class="na" align="center">N/A</td></tr>
+</table>
+<h2 id="cxx23">C++23 implementation status</h2>
+<table width="689" border="1" cellspacing="0">
+ <tr><th>Language Feature</th><th>C++23 Proposal</th><th>Available in Clang?</th></tr>
+ <tr><td>Deducing this</td><td><a href="https://wg21.link/P0847R7">P0847R7</a></td><td class="partial" align="center">Clang 18 (Partial)</td></tr>
+</table>
+<h2 id="cxx98">C++98 implementation status</h2>
+</body></html>`
+
+	support, err := NewClangStatusScraper(nil, "").Parse(statusDocument(t, page))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//papers that don't apply to clang are left out
+	want := []string{
+		"C++2c implementation status: Pack Indexing P2662R3 [{Clang 1 Clang 19 }]",
+		"C++2c implementation status: Trivially relocatable P2786R13 [{Clang 1 Clang 21 }]",
+		"C++2c implementation status: Contracts P2900R14 [{Clang 0 No }]",
+		"C++23 implementation status: Deducing this P0847R7 [{Clang 2 Clang 18 (Partial) }]",
+	}
+	if features := scrapedFeatures(support); !reflect.DeepEqual(features, want) {
+		t.Errorf("got features %q, want %q", features, want)
+	}
+}
@@ -0,0 +1,80 @@
+package scraper
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// longest piece of a row's html kept in a row error
+const maxRowErrorHtml = 500
+
+// RowError is a table row the strict parser refused to guess values for
+type RowError struct {
+	Section string
+	Row     int
+	Reason  string
+	Html    string
+}
+
+func (e RowError) Error() string {
+	return fmt.Sprintf("row %v of '%v': %v", e.Row, e.Section, e.Reason)
+}
+
+// ErrorReport lists the skipped rows in a form that fits in a message to the maintainer
+func ErrorReport(errors []RowError) string {
+	var lines []string
+
+	for _, rowError := range errors {
+		lines = append(lines, fmt.Sprintf("- %v\n  %v", rowError.Error(), rowError.Html))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func newRowError(section string, row int, element *goquery.Selection, reason string) RowError {
+	html, err := goquery.OuterHtml(element)
+	if err != nil {
+		html = fmt.Sprintf("(html unavailable: %v)", err)
+	}
+
+	html = strings.Join(strings.Fields(html), " ")
+	if len(html) > maxRowErrorHtml {
+		html = html[:maxRowErrorHtml] + "..."
+	}
+
+	return RowError{
+		Section: section,
+		Row:     row,
+		Reason:  reason,
+		Html:    html,
+	}
+}
+
+// checkRow finds what the lenient parser would have had to guess about a row. an empty string means the row is fine
+func checkRow(row tableRow, headers []string, columns tableColumns) string {
+	if len(row.cells) != len(headers) {
+		return fmt.Sprintf("has %v cells but the table has %v columns", len(row.cells), len(headers))
+	}
+
+	for index, cell := range row.cells {
+		if cell == nil {
+			return fmt.Sprintf("has no %v cell", headers[index])
+		}
+	}
+
+	if strings.TrimSpace(cellAt(row, columns.feature).Text()) == "" {
+		return "has no feature name"
+	}
+
+	for index, cell := range row.cells {
+		if columns.compilers[index] == "" || strings.TrimSpace(cell.Text()) == "" || cell.HasClass("table-yes") || cell.HasClass("table-no") || cell.HasClass("table-maybe") {
+			continue
+		}
+
+		return fmt.Sprintf("%v cell has no recognised support class", headers[index])
+	}
+
+	return ""
+}
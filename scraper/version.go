@@ -0,0 +1,112 @@
+package scraper
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// CppRevision is a C++ standard revision as cppreference names it, both by its final number and
+// by the working draft name (like "2b") used in headings before the standard was published
+type CppRevision struct {
+	Version int
+	Draft   string
+}
+
+// KnownCppRevisions are the standard revisions the page is expected to list. a heading with a
+// published number that isn't in here is still parsed but logged, an unknown draft name is an error
+var KnownCppRevisions = []CppRevision{
+	{Version: 11, Draft: "0x"},
+	{Version: 14, Draft: "1y"},
+	{Version: 17, Draft: "1z"},
+	{Version: 20, Draft: "2a"},
+	{Version: 23, Draft: "2b"},
+	{Version: 26, Draft: "2c"},
+}
+
+var cppVersionPattern = regexp.MustCompile(`(?i)C\+\+\s?(\d[0-9a-z])\b`)
+
+// ParseCppVersion finds the standard revision in a section heading like "C++23 core language features"
+func ParseCppVersion(text string) (int, error) {
+	match := cppVersionPattern.FindStringSubmatch(text)
+	if match == nil {
+		return 0, fmt.Errorf("could not parse CPP version from '%s'", text)
+	}
+
+	name := strings.ToLower(match[1])
+
+	for _, revision := range KnownCppRevisions {
+		if name == revision.Draft || name == strconv.Itoa(revision.Version) {
+			return revision.Version, nil
+		}
+	}
+
+	version, err := strconv.Atoi(name)
+	if err != nil {
+		return 0, fmt.Errorf("unknown C++ draft '%s' in '%s', add it to KnownCppRevisions", name, text)
+	}
+
+	slog.Warn("unknown C++ revision, add it to KnownCppRevisions", "version", version, "heading", text)
+
+	return version, nil
+}
+
+// languages a section heading can be about, keyed as the compliance package keys them
+const (
+	LanguageCpp   = "cpp"
+	LanguageC     = "c"
+	LanguageCppDR = "cppdr" //defect reports against C++, which apply to every standard they affect instead of one
+)
+
+// KnownCRevisions are the C standard revisions the C compiler support page is expected to list
+var KnownCRevisions = []CppRevision{
+	{Version: 99, Draft: "9x"},
+	{Version: 11, Draft: "1x"},
+	{Version: 17, Draft: ""},
+	{Version: 23, Draft: "2x"},
+}
+
+var cVersionPattern = regexp.MustCompile(`(?i)\bC\s?(\d[0-9a-z])\b`)
+
+// ParseCVersion finds the standard revision in a section heading like "C23 core language features"
+func ParseCVersion(text string) (int, error) {
+	match := cVersionPattern.FindStringSubmatch(text)
+	if match == nil {
+		return 0, fmt.Errorf("could not parse C version from '%s'", text)
+	}
+
+	name := strings.ToLower(match[1])
+
+	for _, revision := range KnownCRevisions {
+		if name == revision.Draft || name == strconv.Itoa(revision.Version) {
+			return revision.Version, nil
+		}
+	}
+
+	version, err := strconv.Atoi(name)
+	if err != nil {
+		return 0, fmt.Errorf("unknown C draft '%s' in '%s', add it to KnownCRevisions", name, text)
+	}
+
+	slog.Warn("unknown C revision, add it to KnownCRevisions", "version", version, "heading", text)
+
+	return version, nil
+}
+
+// ParseStandard finds the language and standard revision a section heading is about, C++ if it names both
+func ParseStandard(text string) (language string, version int, err error) {
+	if cppVersionPattern.MatchString(text) {
+		version, err = ParseCppVersion(text)
+		return LanguageCpp, version, err
+	}
+
+	version, err = ParseCVersion(text)
+	return LanguageC, version, err
+}
+
+// IsDefectReportHeading tells if a section heading is about a table of defect reports, like "C++ defect reports"
+func IsDefectReportHeading(text string) bool {
+	return strings.Contains(strings.ToLower(text), "defect report")
+}
@@ -0,0 +1,61 @@
+package scraper
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCppVersion(t *testing.T) {
+	for _, test := range []struct {
+		heading string
+		version int
+		err     string
+	}{
+		{"C++11 features", 11, ""},
+		{"C++2a core language features", 20, ""},
+		{"C++20 library features", 20, ""},
+		{"C++2b features", 23, ""},
+		{"C++23 core language features", 23, ""},
+		{"C++2c library features", 26, ""},
+		{"C++29 features", 29, ""},
+		{"C++2d features", 0, "unknown C++ draft '2d'"},
+		{"Technical specifications", 0, "could not parse CPP version"},
+	} {
+		version, err := ParseCppVersion(test.heading)
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("%v: got %v, %v, want an error about %v", test.heading, version, err, test.err)
+			}
+			continue
+		}
+		if version != test.version || err != nil {
+			t.Errorf("%v: got %v, %v, want %v", test.heading, version, err, test.version)
+		}
+	}
+}
+
+func TestParseStandard(t *testing.T) {
+	for _, test := range []struct {
+		heading  string
+		language string
+		version  int
+		err      string
+	}{
+		{"C99 core language features", LanguageC, 99, ""},
+		{"C2x core language features", LanguageC, 23, ""},
+		{"C23 core language features", LanguageC, 23, ""},
+		{"C++23 core language features", LanguageCpp, 23, ""},
+		{"C2y features", "", 0, "unknown C draft '2y'"},
+	} {
+		language, version, err := ParseStandard(test.heading)
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("%v: got %v %v, %v, want an error about %v", test.heading, language, version, err, test.err)
+			}
+			continue
+		}
+		if language != test.language || version != test.version || err != nil {
+			t.Errorf("%v: got %v %v, %v, want %v %v", test.heading, language, version, err, test.language, test.version)
+		}
+	}
+}
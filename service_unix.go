@@ -0,0 +1,21 @@
+//go:build !windows
+// +build !windows
+
+package main
+
+import (
+	"fmt"
+)
+
+// runAsService never runs the bot, outside windows services are plain processes stopped with SIGTERM
+func runAsService(run func() error) (ran bool, err error) {
+	return false, nil
+}
+
+func installService(name string, args []string) error {
+	return fmt.Errorf("services are only installed on windows, run the bot with the init system of the host instead")
+}
+
+func removeService(name string) error {
+	return fmt.Errorf("services are only removed on windows")
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// file the bot logs to when running as a service, next to its binary
+const serviceLogFile = "cppimpbot.log"
+
+// how long the service manager is told stopping may take, shutting down waits for running scrapes
+const serviceStopHint = 60000
+
+// serviceHandler runs the bot under the service manager, turning stop requests into a graceful shutdown
+type serviceHandler struct {
+	run func() error
+}
+
+func (h *serviceHandler) Execute(args []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	status <- svc.Status{State: svc.StartPending}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- h.run()
+	}()
+
+	status <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				slog.Error("bot stopped", "err", err)
+				return true, 1
+			}
+			return false, 0
+		case request := <-requests:
+			switch request.Cmd {
+			case svc.Interrogate:
+				status <- request.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				status <- svc.Status{State: svc.StopPending, WaitHint: serviceStopHint}
+				select {
+				case stopRequests <- struct{}{}:
+				default: //asked to stop already
+				}
+			}
+		}
+	}
+}
+
+// runAsService runs the bot as a service if the service manager started it. services start in the system directory
+// without a console, so it runs from the directory of the binary and logs to serviceLogFile there. ran is false if the
+// bot was started from a console
+func runAsService(run func() error) (ran bool, err error) {
+	interactive, err := svc.IsAnInteractiveSession()
+	if err != nil {
+		return false, err
+	}
+	if interactive {
+		return false, nil
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return true, err
+	}
+	dir := filepath.Dir(executable)
+
+	if err := os.Chdir(dir); err != nil {
+		return true, err
+	}
+
+	logFile, err := os.OpenFile(filepath.Join(dir, serviceLogFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return true, err
+	}
+	defer logFile.Close()
+	log.SetOutput(logFile)
+
+	return true, svc.Run(serviceName, &serviceHandler{run})
+}
+
+// installService registers the binary as a service started with the host, run with the given arguments
+func installService(name string, args []string) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	manager, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer manager.Disconnect()
+
+	if existing, err := manager.OpenService(name); err == nil {
+		existing.Close()
+		return fmt.Errorf("service %v is installed already", name)
+	}
+
+	config := mgr.Config{
+		DisplayName: "C++ compiler compliance bot",
+		Description: "Reports changes of the C and C++ compiler support listed on cppreference",
+		StartType:   mgr.StartAutomatic,
+	}
+
+	service, err := manager.CreateService(name, executable, config, args...)
+	if err != nil {
+		return err
+	}
+
+	return service.Close()
+}
+
+// removeService unregisters the service, which the service manager stops first if it runs
+func removeService(name string) error {
+	manager, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer manager.Disconnect()
+
+	service, err := manager.OpenService(name)
+	if err != nil {
+		return fmt.Errorf("service %v is not installed: %v", name, err)
+	}
+	defer service.Close()
+
+	return service.Delete()
+}
@@ -0,0 +1,135 @@
+package shortener
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// kinds of shorteners that can be configured
+const (
+	KindYourls = "yourls"
+	KindBitly  = "bitly"
+)
+
+const (
+	bitlyUrl       = "https://api-ssl.bitly.com/v4/shorten"
+	requestTimeout = 10 * time.Second
+)
+
+// Shortener turns links into short ones that count the clicks on them
+type Shortener interface {
+	Shorten(link string) (string, error)
+}
+
+// New makes a shortener of the given kind. apiUrl is the API endpoint of self-hosted ones, token the signature or
+// access token the API wants. an empty kind gives no shortener
+func New(kind string, apiUrl string, token string) (Shortener, error) {
+	client := &http.Client{Timeout: requestTimeout}
+
+	switch kind {
+	case "":
+		return nil, nil
+	case KindYourls:
+		if apiUrl == "" {
+			return nil, fmt.Errorf("the %v link shortener needs the url of its API", kind)
+		}
+		return &Yourls{apiUrl: apiUrl, signature: token, client: client}, nil
+	case KindBitly:
+		if token == "" {
+			return nil, fmt.Errorf("the %v link shortener needs an access token", kind)
+		}
+		return &Bitly{token: token, client: client}, nil
+	default:
+		return nil, fmt.Errorf("unknown link shortener '%v'", kind)
+	}
+}
+
+// Yourls shortens links with a self-hosted YOURLS instance
+type Yourls struct {
+	apiUrl    string
+	signature string
+	client    *http.Client
+}
+
+type yourlsResponse struct {
+	ShortUrl string `json:"shorturl"`
+	Message  string `json:"message"`
+}
+
+func (y *Yourls) Shorten(link string) (string, error) {
+	values := url.Values{
+		"action":    {"shorturl"},
+		"format":    {"json"},
+		"url":       {link},
+		"signature": {y.signature},
+	}
+
+	response, err := y.client.PostForm(y.apiUrl, values)
+	if err != nil {
+		return "", errors.Wrap(err, "could not reach yourls")
+	}
+	defer response.Body.Close()
+
+	result := yourlsResponse{}
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return "", errors.Wrapf(err, "could not decode yourls answer to %v", response.Status)
+	}
+
+	//links shortened before are refused as duplicates, but their short url comes along
+	if result.ShortUrl == "" {
+		return "", fmt.Errorf("yourls did not shorten %v: %v", link, result.Message)
+	}
+
+	return result.ShortUrl, nil
+}
+
+// Bitly shortens links with bit.ly
+type Bitly struct {
+	token  string
+	client *http.Client
+}
+
+func (b *Bitly) Shorten(link string) (string, error) {
+	payload, err := json.Marshal(map[string]string{"long_url": link})
+	if err != nil {
+		return "", err
+	}
+
+	request, err := http.NewRequest(http.MethodPost, bitlyUrl, bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Authorization", "Bearer "+b.token)
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := b.client.Do(request)
+	if err != nil {
+		return "", errors.Wrap(err, "could not reach bitly")
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return "", err
+	}
+
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf("bitly answered %v: %s", response.Status, body)
+	}
+
+	result := struct {
+		Link string `json:"link"`
+	}{}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", errors.Wrap(err, "could not decode bitly answer")
+	}
+
+	return result.Link, nil
+}
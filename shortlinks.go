@@ -0,0 +1,69 @@
+package main
+
+import (
+	"cppimpbot/compliance"
+	"cppimpbot/shortener"
+	"log/slog"
+)
+
+// reportLink gives the paper link a report of the change includes, and its short link if there is a shortener. dry
+// runs and changes not worth reporting don't get links shortened, so the shortener only counts posted ones
+func reportLink(cfg *Configuration, reportOptions compliance.ReportOptions, linkShortener shortener.Shortener, change compliance.Change) (link string, shortLink string) {
+	if !(cfg.ReportPaperLinks || cfg.ReportLinksIfRoom) || compliance.ChangeReportText(reportOptions, change) == "" {
+		return "", ""
+	}
+
+	link = change.Feature.PaperLink.String
+	if link == "" || linkShortener == nil || cfg.DryReporting || cfg.SupressReporting {
+		return link, ""
+	}
+
+	shortLink, err := linkShortener.Shorten(link)
+	if err != nil {
+		slog.Warn("error shortening link, posting it as it is", "link", link, "err", err)
+		return link, ""
+	}
+
+	return link, shortLink
+}
+
+// templateLink gives the link the report templates and hooks get, none if links are only added where there is room
+func templateLink(cfg *Configuration, postedLink string) string {
+	if cfg.ReportLinksIfRoom {
+		return ""
+	}
+
+	return postedLink
+}
+
+// supportPage gives the compiler support page features of the language are scraped from
+func supportPage(cfg *Configuration, language string) string {
+	if language == compliance.LanguageC {
+		return cfg.ScrapeCUrl
+	}
+
+	return cfg.ScrapeUrl
+}
+
+// withRoomLinks ends the text of a report with the paper link and the cppreference table of the feature where its
+// tweets have room for them, with ReportLinksIfRoom
+func withRoomLinks(cfg *Configuration, change compliance.Change, text string, postedLink string) string {
+	if !cfg.ReportLinksIfRoom || text == "" {
+		return text
+	}
+
+	links := []string{postedLink}
+	if cfg.ReportCppreferenceLinks {
+		links = append(links, compliance.CppreferenceLink(supportPage(cfg, change.Feature.Language), change.Feature))
+	}
+
+	return compliance.WithLinks(text, links)
+}
+
+// reportFooter ends the text of a report with what the config adds to all of them where there is room: the links, then
+// the mentions of the compilers the report is about and the hashtags
+func reportFooter(cfg *Configuration, change compliance.Change, text string, postedLink string) string {
+	text = withRoomLinks(cfg, change, text, postedLink)
+
+	return compliance.WithTags(text, compliance.ReportTags(change, cfg.ReportHashtags, cfg.ReportMentions))
+}
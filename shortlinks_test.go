@@ -0,0 +1,65 @@
+package main
+
+import (
+	"cppimpbot/compliance"
+	"cppimpbot/limits"
+	"cppimpbot/scraper"
+	"database/sql"
+	"testing"
+)
+
+func TestReportFooter(t *testing.T) {
+	base, gained, supported := sampleFeature(), sampleFeature(testSupport(compliance.CompilerGcc, 1, "9*", "still some bugs")), sampleSupported()
+	longBase, longSupported := copyTestFeature(base), copyTestFeature(supported)
+	longBase.Name += " for aggregates, unions and nested braced lists"
+	longSupported.Name = longBase.Name
+
+	library := compliance.Feature{
+		Name:       "std::any",
+		CppVersion: 17,
+		PaperName:  sql.NullString{String: "P0220R1", Valid: true},
+		PaperLink:  sql.NullString{String: "https://wg21.link/P0220R1", Valid: true},
+	}
+	library.SetSupport(testLibrarySupport(compliance.LibraryLibstdcxx, 1, "7", ""))
+	library.SetSupport(testLibrarySupport(compliance.LibraryMsvcStl, 1, "19.10", ""))
+	library.SetSupport(testLibrarySupport("apple_libcxx", 1, "10.0.0", ""))
+
+	header := `[Support Update] C++20 - "Initializer list constructors in class template argument deduction".` + "\n\n"
+	gainedText := header + "From:\nGCC - [no] \n\nTo:\nGCC - [yes] 9*(still some bugs)"
+	supportedText := header + "From:\nGCC - [no] \nMSVC - [no] \nApple Clang - [no] \n\nTo:\nGCC - [yes] 9*(still some bugs)\nMSVC - [yes] 19.20\nApple Clang - [yes] 10.0.1"
+	//the long name leaves room for the paper, but only once the report is cut short
+	longText := `[Support Update] C++20 - "Initializer list constructors in class template argument deduction for aggregates, unions and nested braced lists".` +
+		"\n\nFrom:\nGCC - [no] \nMSVC - [no] \nApple Clang - [no] \n\nTo:\nGCC - [yes] 9*(still some bugs)\nMSVC - [yes] 19.20\nAp...\n\nhttps://wg21.link/P0702R1"
+	table := "\nhttps://en.cppreference.com/w/cpp/compiler_support#C.2B.2B20_core_language_features"
+
+	links := &Configuration{ReportLinksIfRoom: true, ReportCppreferenceLinks: true, ScrapeUrl: scraper.DefaultURL, ScrapeCUrl: scraper.DefaultCURL}
+	tags := &Configuration{ReportLinksIfRoom: true, ReportHashtags: []string{"cpp", "#cplusplus"},
+		ReportMentions: map[string]string{compliance.CompilerMsvc: "@VisualC", compliance.CompilerGcc: "gcc"}}
+
+	for _, test := range []struct {
+		what     string
+		cfg      *Configuration
+		previous *compliance.Feature
+		next     *compliance.Feature
+		tweet    string
+	}{
+		{"links", links, &base, &gained, gainedText + "\n\nhttps://wg21.link/P0702R1" + table},
+		{"links of a longer report", links, &base, &supported, supportedText + "\n\nhttps://wg21.link/P0702R1" + table},
+		{"links without room", links, &longBase, &longSupported, longText},
+		{"links of a library feature", links, nil, &library, "[New Listing] C++17 - \"std::any\".\n\nSupport:\nlibstdc++ - [yes] 7\nlibc++ - [no] \n" +
+			"MSVC STL - [yes] 19.10\nApple libc++ - [yes] 10.0.0\n\nhttps://wg21.link/P0220R1\nhttps://en.cppreference.com/w/cpp/compiler_support#C.2B.2B17_library_features"},
+		{"tags", tags, &base, &gained, gainedText + "\n\nhttps://wg21.link/P0702R1\n\n@gcc #cpp #cplusplus"},
+		{"tags of a longer report", tags, &base, &supported, supportedText + "\n\nhttps://wg21.link/P0702R1\n\n@gcc @VisualC #cpp"},
+		{"tags without room", tags, &longBase, &longSupported, longText},
+	} {
+		change := sampleChange(t, test.previous, test.next)
+		tweets := limits.Twitter.Split(reportFooter(test.cfg, change, compliance.DefaultReportText(compliance.ReportOptions{}, change, ""), test.next.PaperLink.String))
+
+		if len(tweets) != 1 || tweets[0] != test.tweet {
+			t.Errorf("%v: got %q, want %q", test.what, tweets, test.tweet)
+		}
+		if !limits.Twitter.Fits(tweets[0]) {
+			t.Errorf("%v: tweet is %v long", test.what, limits.Twitter.Count(tweets[0]))
+		}
+	}
+}
@@ -0,0 +1,41 @@
+//go:build !windows
+// +build !windows
+
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyControls sends the runtime controls to the channel as their signals come in: SIGHUP reloads the config,
+// SIGUSR1 scrapes right away and SIGUSR2 dumps the internal state to the log
+func notifyControls(controls chan<- int) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP, syscall.SIGUSR1, syscall.SIGUSR2)
+
+	go func() {
+		for sig := range signals {
+			switch sig {
+			case syscall.SIGHUP:
+				controls <- controlReload
+			case syscall.SIGUSR1:
+				controls <- controlScrape
+			case syscall.SIGUSR2:
+				controls <- controlDump
+			}
+		}
+	}()
+}
+
+// restart replaces the process with a fresh one of the same binary and arguments, keeping its pid for the service
+// manager
+func restart() error {
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	return syscall.Exec(executable, os.Args, os.Environ())
+}
@@ -0,0 +1,13 @@
+package main
+
+import (
+	"fmt"
+)
+
+// notifyControls does nothing, windows has no signals for the runtime controls
+func notifyControls(controls chan<- int) {
+}
+
+func restart() error {
+	return fmt.Errorf("restarting is not supported on windows")
+}
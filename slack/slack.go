@@ -0,0 +1,221 @@
+package slack
+
+import (
+	"bytes"
+	"cppimpbot/compliance"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	maxFieldsPerSection = 10 //Block Kit refuses sections with more fields
+	maxBlocks           = 50
+	maxSectionText      = 3000
+	maxFieldText        = 2000
+	postTimeout         = 10 * time.Second
+)
+
+// Text is a Block Kit text object
+type Text struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// Block is a Block Kit layout block. only what the reports use is here
+type Block struct {
+	Type     string `json:"type"`
+	Text     *Text  `json:"text,omitempty"`
+	Fields   []Text `json:"fields,omitempty"`
+	Elements []Text `json:"elements,omitempty"`
+}
+
+// Message is the payload of an incoming webhook. Text is shown in notifications and where blocks can't be
+type Message struct {
+	Channel string  `json:"channel,omitempty"`
+	Text    string  `json:"text"`
+	Blocks  []Block `json:"blocks,omitempty"`
+}
+
+// Webhook posts messages to a Slack incoming webhook
+type Webhook struct {
+	url     string
+	channel string
+	client  *http.Client
+}
+
+// NewWebhook makes a webhook posting to the given url. channel overrides the channel the webhook was set up for, if
+// the webhook allows it, and is left alone if empty
+func NewWebhook(url string, channel string) *Webhook {
+	return &Webhook{
+		url:     url,
+		channel: channel,
+		client:  &http.Client{Timeout: postTimeout},
+	}
+}
+
+// Post sends the message to the webhook
+func (w *Webhook) Post(message Message) error {
+	if message.Channel == "" {
+		message.Channel = w.channel
+	}
+
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return errors.Wrap(err, "could not encode slack message")
+	}
+
+	response, err := w.client.Post(w.url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return errors.Wrap(err, "could not post to slack")
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(response.Body)
+		return fmt.Errorf("slack answered %v: %s", response.Status, body)
+	}
+
+	return nil
+}
+
+func markdown(text string) *Text {
+	return &Text{Type: "mrkdwn", Text: text}
+}
+
+// escaped escapes the characters Slack treats as control characters in message text
+func escaped(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// TextChannel is the channel the wording of Slack messages is registered under, see compliance.LoadTexts
+const TextChannel = "slack"
+
+// built-in wording of the messages. headline is the notification text, the kinds the summary above a field per
+// compiler, which field renders from the kind and the listing line of the compiler
+const commonText = `{{define "title"}}{{if eq .Kind "removed"}}Removed Listing{{else if eq .Kind "new_listing"}}New Listing{{else if eq .Kind "rename"}}Renamed{{else if eq .Kind "paper"}}Paper Update{{else if eq .Kind "support"}}Support Update{{else}}Text Update{{end}}{{end}}
+{{define "compilerName"}}{{escape (compiler .)}}{{end}}
+{{define "supportLevel"}}{{if eq . 1}}:white_check_mark:{{else if eq . 2}}:large_orange_diamond:{{else}}:x:{{end}} {{level .}}{{end}}
+{{define "supportText"}}{{escape (supportText .)}}{{end}}
+{{define "paperRef"}}{{if .Link}}<{{.Link}}|{{escape .String}}>{{else}}{{escape .String}}{{end}}{{end}}`
+
+var texts = map[string]string{
+	compliance.ChangeRemoved:    `*[{{template "title" .}}]* {{.Standard}} - *{{escape .Name}}* is no longer listed.`,
+	compliance.ChangeNewListing: `*[{{template "title" .}}]* {{.Standard}} - *{{escape .Name}}*`,
+	compliance.ChangeRename:     `*[{{template "title" .}}]* {{.Standard}} - *{{escape .Name}}*, previously listed as *{{escape .PreviousName}}*.`,
+	compliance.ChangePaper:      `*[{{template "title" .}}]* {{.Standard}} - *{{escape .Name}}*, paper {{template "paperChange" .}}`,
+	compliance.ChangeSupport:    `*[{{template "title" .}}]* {{.Standard}} - *{{escape .Name}}*`,
+	compliance.ChangeText:       `*[{{template "title" .}}]* {{.Standard}} - *{{escape .Name}}*`,
+	"headline":                  `[{{template "title" .}}] {{.Standard}} - "{{.Name}}"`,
+	"field": `*{{template "compilerName" .Delta.Compiler}}*
+{{template "supportChange" .Delta}}`,
+	"paperLine": `Paper: {{if .PaperLink}}<{{.PaperLink}}|{{escape .Paper}}>{{else}}{{escape .Paper}}{{end}}`,
+}
+
+func init() {
+	compliance.RegisterTexts(TextChannel, commonText, texts, template.FuncMap{"escape": escaped})
+}
+
+// fieldData is what the field text gets, the listing line of the delta of a compiler
+type fieldData struct {
+	Kind  string
+	Delta compliance.ListingLine
+}
+
+// deltaFields gives a field per compiler, with its support before and after the change
+func deltaFields(options compliance.ReportOptions, change compliance.Change, lines []compliance.ListingLine) (result []Text) {
+	for _, line := range lines {
+		text := compliance.RenderText(options, TextChannel, "field", fieldData{change.Kind, line})
+		result = append(result, Text{Type: "mrkdwn", Text: text})
+	}
+
+	return
+}
+
+// ChangeMessage renders a change as a message with a field per compiler delta, worded as the options say. ok is false
+// for changes not worth reporting, the same ones the tweets leave out
+func ChangeMessage(options compliance.ReportOptions, change compliance.Change) (message Message, ok bool) {
+	data := compliance.NewTextData(options, change, "")
+
+	summary := compliance.RenderText(options, TextChannel, change.Kind, data)
+	if summary == "" {
+		return Message{}, false
+	}
+
+	message.Text = compliance.RenderText(options, TextChannel, "headline", data)
+	message.Blocks = append(message.Blocks, Block{Type: "section", Text: markdown(summary)})
+
+	fields := deltaFields(options, change, data.Lines)
+	for len(fields) > 0 {
+		count := len(fields)
+		if count > maxFieldsPerSection {
+			count = maxFieldsPerSection
+		}
+
+		message.Blocks = append(message.Blocks, Block{Type: "section", Fields: fields[:count]})
+		fields = fields[count:]
+	}
+
+	if data.Paper != "" {
+		message.Blocks = append(message.Blocks, Block{Type: "context", Elements: []Text{*markdown(compliance.RenderText(options, TextChannel, "paperLine", data))}})
+	}
+
+	return message, true
+}
+
+// Plain gives the texts of the blocks of the message a line each, to preview it where Block Kit can't be shown
+func (m Message) Plain() string {
+	var lines []string
+	for _, block := range m.Blocks {
+		if block.Text != nil {
+			lines = append(lines, block.Text.Text)
+		}
+		for _, field := range append(block.Fields, block.Elements...) {
+			lines = append(lines, strings.Replace(field.Text, "\n", " ", -1))
+		}
+	}
+
+	if len(lines) == 0 {
+		return m.Text
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Problems lists what Block Kit would refuse the message for, like texts longer than a block may have
+func (m Message) Problems() (result []string) {
+	if strings.TrimSpace(m.Text) == "" {
+		result = append(result, "empty notification text")
+	}
+	if len(m.Blocks) > maxBlocks {
+		result = append(result, fmt.Sprintf("%v blocks of at most %v", len(m.Blocks), maxBlocks))
+	}
+
+	for i, block := range m.Blocks {
+		if block.Text != nil && utf8.RuneCountInString(block.Text.Text) > maxSectionText {
+			result = append(result, fmt.Sprintf("text of block %v is %v characters of at most %v", i+1, utf8.RuneCountInString(block.Text.Text), maxSectionText))
+		}
+		for _, field := range append(block.Fields, block.Elements...) {
+			if utf8.RuneCountInString(field.Text) > maxFieldText {
+				result = append(result, fmt.Sprintf("a field of block %v is %v characters of at most %v", i+1, utf8.RuneCountInString(field.Text), maxFieldText))
+			}
+		}
+	}
+
+	return
+}
+
+// AlertMessage renders a maintainer alert, like a safe mode trip or a scrape error
+func AlertMessage(text string) Message {
+	return Message{
+		Text:   text,
+		Blocks: []Block{{Type: "section", Text: markdown(":rotating_light: " + escaped(text))}},
+	}
+}
@@ -0,0 +1,78 @@
+package slack
+
+import (
+	"cppimpbot/compliance"
+	"database/sql"
+	"encoding/json"
+	"testing"
+)
+
+func support(compiler string, level int, displayText string, extraText string) compliance.CompilerSupport {
+	return compliance.CompilerSupport{
+		Compiler:    compiler,
+		Kind:        compliance.KindCompiler,
+		Support:     level,
+		DisplayText: sql.NullString{String: displayText, Valid: true},
+		ExtraText:   sql.NullString{String: extraText, Valid: extraText != ""},
+	}
+}
+
+// supportChange gives GCC, MSVC and Apple Clang gaining support of a feature named name
+func supportChange(t *testing.T, name string) compliance.Change {
+	t.Helper()
+
+	previous := &compliance.Feature{Name: name, CppVersion: 20,
+		PaperName: sql.NullString{String: "P0702R1", Valid: true}, PaperLink: sql.NullString{String: "https://wg21.link/P0702R1", Valid: true}}
+	for _, compiler := range []string{compliance.CompilerGcc, compliance.CompilerMsvc, "apple_clang"} {
+		previous.SetSupport(support(compiler, 0, "", ""))
+	}
+	previous.SetSupport(support(compliance.CompilerClang, 1, "6", ""))
+
+	next := *previous
+	next.Compilers = append([]compliance.CompilerSupport(nil), previous.Compilers...)
+	next.SetSupport(support(compliance.CompilerGcc, 1, "9*", "still some bugs"))
+	next.SetSupport(support(compliance.CompilerMsvc, 1, "19.20", ""))
+	next.SetSupport(support("apple_clang", 1, "10.0.1", ""))
+
+	change, err := compliance.DiffFeatures(previous, &next)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return change
+}
+
+func TestChangeMessage(t *testing.T) {
+	message, ok := ChangeMessage(compliance.ReportOptions{}, supportChange(t, "Initializer list constructors in class template argument deduction"))
+	if !ok {
+		t.Fatal("support update has no message")
+	}
+
+	want := `{"text":"[Support Update] C++20 - \"Initializer list constructors in class template argument deduction\"","blocks":[` +
+		`{"type":"section","text":{"type":"mrkdwn","text":"*[Support Update]* C++20 - *Initializer list constructors in class template argument deduction*"}},` +
+		`{"type":"section","fields":[{"type":"mrkdwn","text":"*GCC*\n:x: no → :white_check_mark: yes 9* (still some bugs)"},` +
+		`{"type":"mrkdwn","text":"*MSVC*\n:x: no → :white_check_mark: yes 19.20"},{"type":"mrkdwn","text":"*Apple Clang*\n:x: no → :white_check_mark: yes 10.0.1"}]},` +
+		`{"type":"context","elements":[{"type":"mrkdwn","text":"Paper: \u003chttps://wg21.link/P0702R1|P0702R1\u003e"}]}]}`
+	if messageJson, _ := json.Marshal(message); string(messageJson) != want {
+		t.Errorf("got\n%s\nwant\n%v", messageJson, want)
+	}
+	if problems := message.Problems(); len(problems) != 0 {
+		t.Errorf("got problems %v", problems)
+	}
+
+	unreported := supportChange(t, "Modules").OnlyCompilers([]string{compliance.CompilerClang})
+	if _, ok := ChangeMessage(compliance.ReportOptions{}, unreported); ok {
+		t.Errorf("change not worth reporting has a message")
+	}
+}
+
+func TestChangeMessageEscaped(t *testing.T) {
+	message, _ := ChangeMessage(compliance.ReportOptions{}, supportChange(t, "std::vector<bool> & friends"))
+
+	if text := message.Blocks[0].Text.Text; text != "*[Support Update]* C++20 - *std::vector&lt;bool&gt; &amp; friends*" {
+		t.Errorf("got summary %v", text)
+	}
+	if message.Text != `[Support Update] C++20 - "std::vector<bool> & friends"` {
+		t.Errorf("got notification text %v", message.Text)
+	}
+}
@@ -0,0 +1,91 @@
+//go:build !noslack
+// +build !noslack
+
+package main
+
+import (
+	"cppimpbot/compliance"
+	"cppimpbot/slack"
+	"log/slog"
+)
+
+func init() {
+	registerReporter("slack", reporterRegistration{new: newSlackReporter, lint: lintSlackMessage, preview: previewSlackMessage})
+}
+
+// slackReporter posts changes to a Slack incoming webhook, and maintainer alerts too if SlackAlerts is set
+type slackReporter struct {
+	cfg     *Configuration
+	options compliance.ReportOptions
+	webhook *slack.Webhook
+}
+
+// newSlackReporter gives the configured Slack reporter, or nil if Slack reporting isn't configured
+func newSlackReporter(cfg *Configuration, options compliance.ReportOptions) reporter {
+	if cfg.SlackWebhookUrl == "" {
+		return nil
+	}
+
+	return &slackReporter{cfg: cfg, options: options, webhook: slack.NewWebhook(cfg.SlackWebhookUrl, cfg.SlackChannel)}
+}
+
+// ReportChange posts the summary of a change to Slack, or only logs it if a dry run. changes not worth reporting are
+// skipped
+func (r *slackReporter) ReportChange(change compliance.Change) error {
+	message, ok := slack.ChangeMessage(r.options, change)
+	if !ok {
+		return nil
+	}
+
+	if r.cfg.DryReporting {
+		slog.Info("Dry run: posting", "channel", slack.TextChannel, "feature", change.Feature.Name, "message", message.Text)
+		return nil
+	}
+
+	return r.webhook.Post(message)
+}
+
+// Alert posts a maintainer alert to Slack if alerts are configured to go there as well
+func (r *slackReporter) Alert(message string) {
+	if !r.cfg.SlackAlerts {
+		return
+	}
+
+	if err := r.webhook.Post(slack.AlertMessage(message)); err != nil {
+		slog.Error("did not manage to alert the maintainer", "channel", slack.TextChannel, "err", err)
+	}
+}
+
+// Announce posts a report about the bot to Slack
+func (r *slackReporter) Announce(message string) {
+	if err := r.webhook.Post(slack.Message{Text: message}); err != nil {
+		slog.Error("error posting", "channel", slack.TextChannel, "err", err)
+	}
+}
+
+// PostSummary posts the weekly summary to Slack, or only logs it if a dry run
+func (r *slackReporter) PostSummary(text string) error {
+	if r.cfg.DryReporting {
+		slog.Info("Dry run: posting", "channel", slack.TextChannel, "message", text)
+		return nil
+	}
+
+	return r.webhook.Post(slack.Message{Text: text})
+}
+
+func lintSlackMessage(options compliance.ReportOptions, change compliance.Change) []string {
+	message, ok := slack.ChangeMessage(options, change)
+	if !ok {
+		return []string{"no message"}
+	}
+
+	return message.Problems()
+}
+
+func previewSlackMessage(options compliance.ReportOptions, change compliance.Change) string {
+	if message, ok := slack.ChangeMessage(options, change); ok {
+		return message.Plain()
+	}
+
+	return ""
+}
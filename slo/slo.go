@@ -0,0 +1,106 @@
+package slo
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// kinds of objectives, by what their events measure
+const (
+	KindScrapeSuccess = "scrape_success" //an event per scrape, good if it succeeded
+	KindPostLatency   = "post_latency"   //an event per posted change, good if it was posted within Threshold of being scraped
+)
+
+// Objective is a service level objective as configured: the fraction of events that have to be good
+type Objective struct {
+	Name      string  //key the events are stored under, so renaming an objective starts it over
+	Kind      string  //scrape_success or post_latency
+	Target    float64 //fraction of good events, like 0.99
+	Threshold int     //seconds a change may take from being scraped to being posted, for post_latency
+	Source    string  //source whose scrapes count, for scrape_success. all sources if empty
+}
+
+// Validate tells what is wrong with the objective, if anything
+func (o *Objective) Validate() error {
+	if o.Name == "" {
+		return fmt.Errorf("objective without a name")
+	}
+
+	if o.Kind != KindScrapeSuccess && o.Kind != KindPostLatency {
+		return fmt.Errorf("objective %v has unknown kind '%v'", o.Name, o.Kind)
+	}
+
+	if o.Target <= 0 || o.Target > 1 {
+		return fmt.Errorf("objective %v needs a target between 0 and 1, not %v", o.Name, o.Target)
+	}
+
+	if o.Kind == KindPostLatency && o.Threshold <= 0 {
+		return fmt.Errorf("objective %v needs a positive threshold", o.Name)
+	}
+
+	return nil
+}
+
+// Good tells if a post that took the given time meets a post_latency objective
+func (o *Objective) Good(latency time.Duration) bool {
+	return latency <= time.Duration(o.Threshold)*time.Second
+}
+
+// Status is how an objective fared over a window of time
+type Status struct {
+	Objective Objective
+	Good      int
+	Total     int
+}
+
+// Compliance gives the fraction of good events. without events, the objective is met
+func (s *Status) Compliance() float64 {
+	if s.Total == 0 {
+		return 1
+	}
+
+	return float64(s.Good) / float64(s.Total)
+}
+
+// BudgetUsed gives the fraction of the error budget spent, the bad events over the bad events the target allows.
+// it goes past 1 once the budget is exhausted
+func (s *Status) BudgetUsed() float64 {
+	bad := float64(s.Total - s.Good)
+	allowed := (1 - s.Objective.Target) * float64(s.Total)
+
+	if bad == 0 {
+		return 0
+	}
+	if allowed == 0 {
+		return math.Inf(1)
+	}
+
+	return bad / allowed
+}
+
+// Exhausted tells if there were more bad events than the target allows
+func (s *Status) Exhausted() bool {
+	return s.BudgetUsed() > 1
+}
+
+// Report renders the statuses of all objectives over the window as a message for the maintainer
+func Report(statuses []Status, window time.Duration) string {
+	lines := []string{fmt.Sprintf("cppimpbot: service level objectives over the last %v days", int(window.Hours()/24))}
+
+	for i := range statuses {
+		status := &statuses[i]
+
+		line := fmt.Sprintf("- %v: %.2f%% of %v events good, target %.2f%%", status.Objective.Name, 100*status.Compliance(), status.Total, 100*status.Objective.Target)
+		if status.Exhausted() {
+			line += ", error budget exhausted"
+		} else {
+			line += fmt.Sprintf(", %.0f%% of the error budget used", 100*status.BudgetUsed())
+		}
+
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,22 @@
+package slo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReport(t *testing.T) {
+	scrape := Status{Objective: Objective{Name: "scrape-success", Kind: KindScrapeSuccess, Target: 0.99}, Good: 995, Total: 1000}
+	latency := Status{Objective: Objective{Name: "post-latency", Kind: KindPostLatency, Target: 0.9, Threshold: 1800}, Good: 17, Total: 20}
+
+	if scrape.Exhausted() || !latency.Exhausted() {
+		t.Errorf("got exhausted %v and %v, want only the latency objective exhausted", scrape.Exhausted(), latency.Exhausted())
+	}
+
+	report := `cppimpbot: service level objectives over the last 28 days
+- scrape-success: 99.50% of 1000 events good, target 99.00%, 50% of the error budget used
+- post-latency: 85.00% of 20 events good, target 90.00%, error budget exhausted`
+	if got := Report([]Status{scrape, latency}, 28*24*time.Hour); got != report {
+		t.Errorf("got\n%v\nwant\n%v", got, report)
+	}
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"cppimpbot/scraper"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var snapshotsCommand = &cobra.Command{
+	Use:   "snapshots",
+	Short: "List the pages kept of the scrapes, newest first",
+	Long: `List the pages kept of the scrapes, newest first. A page is kept whenever it parsed differently than the one kept
+before, up to ScrapeSnapshotKeep per source, so a bad diff can be looked into and a page parsed again by a better parser.`,
+	Args: cobra.NoArgs,
+	RunE: snapshotsCmdFunc,
+}
+
+var snapshotsPageCommand = &cobra.Command{
+	Use:   "page <id>",
+	Short: "Print the html of a kept page, to save it and parse it with scrape --file",
+	Args:  cobra.ExactArgs(1),
+	RunE:  snapshotsPageCmdFunc,
+}
+
+var snapshotsParseCommand = &cobra.Command{
+	Use:   "parse <id>",
+	Short: "Parse a kept page again with the current parser of its source and print the result like scrape does",
+	Args:  cobra.ExactArgs(1),
+	RunE:  snapshotsParseCmdFunc,
+}
+
+var snapshotsSource string
+var snapshotsLimit int
+
+func init() {
+	snapshotsCommand.Flags().StringVar(&snapshotsSource, "source", "", "only list the pages of this source")
+	snapshotsCommand.Flags().IntVar(&snapshotsLimit, "limit", 20, "most pages to list, 0 for all")
+
+	snapshotsCommand.AddCommand(snapshotsPageCommand)
+	snapshotsCommand.AddCommand(snapshotsParseCommand)
+}
+
+// snapshotScrape keeps the page of the scrape of the source if it parsed differently than the last one kept, and drops
+// the oldest ones beyond ScrapeSnapshotKeep. pages of rejected scrapes are kept too, as they are the most worth a look
+func snapshotScrape(cfg *Configuration, service compliance.Service, source string, scraped scraper.CppSupport, page string) {
+	if !cfg.ScrapeSnapshots {
+		return
+	}
+
+	data, err := json.Marshal(scraped)
+	if err != nil {
+		slog.Error("error encoding scrape for its snapshot", "source", source, "err", err)
+		return
+	}
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	last, err := service.GetScrapeSnapshots(context.Background(), source, 1)
+	if err != nil {
+		slog.Error("error getting last scrape snapshot", "source", source, "err", err)
+		return
+	}
+	if len(last) > 0 && last[0].Hash == hash {
+		return
+	}
+
+	features := 0
+	for _, version := range scraped.Versions {
+		features += len(version.Features)
+	}
+
+	snapshot, err := compliance.NewScrapeSnapshot(source, time.Now(), hash, features, page)
+	if err != nil {
+		slog.Error("error creating scrape snapshot", "source", source, "err", err)
+		return
+	}
+	if err := service.StoreScrapeSnapshot(context.Background(), snapshot); err != nil {
+		slog.Error("error storing scrape snapshot", "source", source, "err", err)
+		return
+	}
+	slog.Info("kept the page of the scrape", "source", source, "snapshot", snapshot.Id, "features", features, "bytes", len(snapshot.Page))
+
+	if cfg.ScrapeSnapshotKeep > 0 {
+		if err := service.PruneScrapeSnapshots(context.Background(), source, cfg.ScrapeSnapshotKeep); err != nil {
+			slog.Error("error dropping old scrape snapshots", "source", source, "err", err)
+		}
+	}
+}
+
+// openSnapshots opens the database the snapshots are kept in for the snapshots commands
+func openSnapshots() (*Configuration, *compliance.SqliteService, error) {
+	cfg := &Configuration{}
+
+	if err := viper.Unmarshal(cfg); err != nil {
+		return nil, nil, err
+	}
+
+	if cfg.StorageMode != "sqlite3" {
+		return nil, nil, fmt.Errorf("snapshots needs storageMode sqlite3, not %s", cfg.StorageMode)
+	}
+
+	service, err := openSqliteService(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return cfg, service, nil
+}
+
+func snapshotsCmdFunc(cmd *cobra.Command, args []string) error {
+	_, service, err := openSnapshots()
+	if err != nil {
+		return err
+	}
+	defer service.Close(context.Background())
+
+	snapshots, err := service.GetScrapeSnapshots(context.Background(), snapshotsSource, snapshotsLimit)
+	if err != nil {
+		return err
+	}
+
+	if len(snapshots) == 0 {
+		fmt.Println("no pages kept")
+		return nil
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "id\tscraped\tsource\tfeatures\thash")
+	for _, snapshot := range snapshots {
+		fmt.Fprintf(writer, "%v\t%v\t%v\t%v\t%v\n", snapshot.Id, snapshot.Timestamp.Format(historyTimeFormat), snapshot.Source,
+			snapshot.Features, snapshot.Hash[:12])
+	}
+
+	return writer.Flush()
+}
+
+// getSnapshot gives the snapshot of the id argument with its page
+func getSnapshot(service compliance.Service, arg string) (*compliance.ScrapeSnapshot, error) {
+	id, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id '%v'", arg)
+	}
+
+	snapshot, err := service.GetScrapeSnapshot(context.Background(), id)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot == nil {
+		return nil, fmt.Errorf("no page kept with id %v", id)
+	}
+
+	return snapshot, nil
+}
+
+func snapshotsPageCmdFunc(cmd *cobra.Command, args []string) error {
+	_, service, err := openSnapshots()
+	if err != nil {
+		return err
+	}
+	defer service.Close(context.Background())
+
+	snapshot, err := getSnapshot(service, args[0])
+	if err != nil {
+		return err
+	}
+
+	html, err := snapshot.Html()
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(html)
+	return nil
+}
+
+func snapshotsParseCmdFunc(cmd *cobra.Command, args []string) error {
+	cfg, service, err := openSnapshots()
+	if err != nil {
+		return err
+	}
+	defer service.Close(context.Background())
+
+	snapshot, err := getSnapshot(service, args[0])
+	if err != nil {
+		return err
+	}
+
+	scraped, err := parseSnapshot(cfg, snapshot)
+	if err != nil {
+		return err
+	}
+
+	printScraped(scraped)
+	return nil
+}
+
+// parseSnapshot parses the kept page with the current parser of the source it was scraped from
+func parseSnapshot(cfg *Configuration, snapshot *compliance.ScrapeSnapshot) (scraper.CppSupport, error) {
+	newSource, ok := sources[snapshot.Source]
+	if !ok {
+		return scraper.CppSupport{}, fmt.Errorf("no built-in source %v to parse the page with", snapshot.Source)
+	}
+
+	source, err := newSource(cfg)
+	if err != nil {
+		return scraper.CppSupport{}, err
+	}
+
+	html, err := snapshot.Html()
+	if err != nil {
+		return scraper.CppSupport{}, err
+	}
+
+	document, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return scraper.CppSupport{}, err
+	}
+
+	return source.Parse(document)
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"cppimpbot/scraper"
+	"fmt"
+	"testing"
+)
+
+func TestSnapshotScrape(t *testing.T) {
+	service := compliance.NewMemoryService()
+	cfg := &Configuration{ScrapeSnapshots: true, ScrapeSnapshotKeep: 2}
+
+	//only pages parsing differently than the last one are kept, and only the two latest of a source
+	for i, count := range []int{1, 1, 2, 3, 3} {
+		snapshotScrape(cfg, service, "test", scrapeOf(compliance.LanguageCpp, featureNames(count)...), fmt.Sprintf("<html>page %v</html>", i))
+	}
+	snapshotScrape(cfg, service, "other", scrapeOf(compliance.LanguageCpp, featureNames(10)...), "<html>other</html>")
+	snapshotScrape(&Configuration{}, service, "other", scraper.CppSupport{}, "<html>off</html>")
+
+	snapshots, err := service.GetScrapeSnapshots(context.Background(), "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var kept []string
+	for _, snapshot := range snapshots {
+		full, err := service.GetScrapeSnapshot(context.Background(), snapshot.Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		html, err := full.Html()
+		if err != nil {
+			t.Fatal(err)
+		}
+		kept = append(kept, fmt.Sprintf("%v %v: %v features, %v", snapshot.Id, snapshot.Source, snapshot.Features, html))
+	}
+
+	want := fmt.Sprint([]string{"4 other: 10 features, <html>other</html>", "3 test: 3 features, <html>page 3</html>", "2 test: 2 features, <html>page 2</html>"})
+	if fmt.Sprint(kept) != want {
+		t.Errorf("got %v, want %v", kept, want)
+	}
+
+	if _, err := parseSnapshot(cfg, &compliance.ScrapeSnapshot{Source: "test"}); err == nil {
+		t.Errorf("parsed the page of an unknown source")
+	}
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var suggestionsCommand = &cobra.Command{
+	Use:   "suggestions",
+	Short: "List the features followers suggested to watch, and approve them into the watch-list or reject them",
+	Args:  cobra.NoArgs,
+	RunE:  suggestionsListCmdFunc,
+}
+
+var suggestionsApproveCommand = &cobra.Command{
+	Use:   "approve <id>",
+	Short: "Put a suggestion on the watch-list, watching for the paper or name read out of it unless given",
+	Args:  cobra.ExactArgs(1),
+	RunE:  suggestionsApproveCmdFunc,
+}
+
+var suggestionsRejectCommand = &cobra.Command{
+	Use:   "reject <id>",
+	Short: "Reject a suggestion",
+	Args:  cobra.ExactArgs(1),
+	RunE:  suggestionsRejectCmdFunc,
+}
+
+var watchlistCommand = &cobra.Command{
+	Use:   "watchlist",
+	Short: "List the watch-list and which of its features got listed",
+	Args:  cobra.NoArgs,
+	RunE:  watchlistCmdFunc,
+}
+
+var suggestionsAll bool
+var suggestionsPaper string
+var suggestionsName string
+
+func init() {
+	suggestionsCommand.Flags().BoolVar(&suggestionsAll, "all", false, "list decided suggestions too")
+	suggestionsApproveCommand.Flags().StringVar(&suggestionsPaper, "paper", "", "paper number to watch for instead, like P1234")
+	suggestionsApproveCommand.Flags().StringVar(&suggestionsName, "name", "", "piece of the feature name to watch for instead")
+
+	suggestionsCommand.AddCommand(suggestionsApproveCommand)
+	suggestionsCommand.AddCommand(suggestionsRejectCommand)
+	suggestionsCommand.AddCommand(watchlistCommand)
+}
+
+// checkWatchlist tells the maintainer when a feature on the watch-list gets listed for the first time
+func checkWatchlist(service compliance.Service, alert func(message string), change compliance.Change) {
+	if change.Kind != compliance.ChangeNewListing {
+		return
+	}
+
+	watchlist, err := service.GetWatchlist(context.Background())
+	if err != nil {
+		slog.Error("error getting the watch-list", "err", err)
+		return
+	}
+
+	feature := change.Feature
+	for i := range watchlist {
+		watch := &watchlist[i]
+		if watch.ListedEntryId.Valid || !watch.Matches(feature) {
+			continue
+		}
+
+		slog.Info("watched feature got listed", "watch", watch.Paper+watch.Name, "feature", feature.Name)
+		alert(fmt.Sprintf("Hello! %v%v from the watch-list is now listed as %v \"%v\".", watch.Paper, watch.Name, feature.Standard(), feature.Name))
+
+		if err := service.SetWatchListed(context.Background(), watch.Id, feature.Id); err != nil {
+			slog.Error("error marking watched feature as listed", "watch", watch.Paper+watch.Name, "feature", feature.Name, "err", err)
+		}
+	}
+}
+
+func openSuggestions() (*compliance.SqliteService, error) {
+	cfg := &Configuration{}
+
+	if err := viper.Unmarshal(cfg); err != nil {
+		return nil, err
+	}
+
+	if cfg.StorageMode != "sqlite3" {
+		return nil, fmt.Errorf("suggestions needs storageMode sqlite3, not %s", cfg.StorageMode)
+	}
+
+	return openSqliteService(cfg)
+}
+
+func suggestionsListCmdFunc(cmd *cobra.Command, args []string) error {
+	service, err := openSuggestions()
+	if err != nil {
+		return err
+	}
+	defer service.Close(context.Background())
+
+	status := compliance.SuggestionPending
+	if suggestionsAll {
+		status = ""
+	}
+
+	suggestions, err := service.GetSuggestions(context.Background(), status)
+	if err != nil {
+		return err
+	}
+
+	if len(suggestions) == 0 {
+		fmt.Println("no suggestions")
+		return nil
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "id\tsuggested\tby\twatch for\tstatus\ttext")
+	for _, suggestion := range suggestions {
+		watchFor := suggestion.Paper
+		if watchFor == "" {
+			watchFor = "\"" + suggestion.Name + "\""
+		}
+
+		fmt.Fprintf(writer, "%v\t%v\t%v\t%v\t%v\t%v\n", suggestion.Id, suggestion.Timestamp.Format(historyTimeFormat), suggestion.Author, watchFor, suggestion.Status, suggestion.Text)
+	}
+
+	return writer.Flush()
+}
+
+func decideSuggestion(arg string, approve bool) error {
+	id, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		return fmt.Errorf("'%s' is not a suggestion id", arg)
+	}
+
+	service, err := openSuggestions()
+	if err != nil {
+		return err
+	}
+	defer service.Close(context.Background())
+
+	if err := service.DecideSuggestion(context.Background(), id, approve, suggestionsPaper, suggestionsName); err != nil {
+		return err
+	}
+
+	if approve {
+		slog.Info("suggestion is on the watch-list", "suggestion", id)
+	} else {
+		slog.Info("rejected suggestion", "suggestion", id)
+	}
+
+	return nil
+}
+
+func suggestionsApproveCmdFunc(cmd *cobra.Command, args []string) error {
+	return decideSuggestion(args[0], true)
+}
+
+func suggestionsRejectCmdFunc(cmd *cobra.Command, args []string) error {
+	return decideSuggestion(args[0], false)
+}
+
+func watchlistCmdFunc(cmd *cobra.Command, args []string) error {
+	service, err := openSuggestions()
+	if err != nil {
+		return err
+	}
+	defer service.Close(context.Background())
+
+	watchlist, err := service.GetWatchlist(context.Background())
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "id\tpaper\tname\tsuggestion\tadded\tlisted entry")
+	for _, watch := range watchlist {
+		suggestion, listed := "-", "-"
+		if watch.SuggestionId.Valid {
+			suggestion = strconv.FormatInt(watch.SuggestionId.Int64, 10)
+		}
+		if watch.ListedEntryId.Valid {
+			listed = strconv.FormatInt(watch.ListedEntryId.Int64, 10)
+		}
+
+		fmt.Fprintf(writer, "%v\t%v\t%v\t%v\t%v\t%v\n", watch.Id, watch.Paper, watch.Name, suggestion, watch.Added.Format(historyTimeFormat), listed)
+	}
+
+	return writer.Flush()
+}
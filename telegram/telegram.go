@@ -0,0 +1,125 @@
+package telegram
+
+import (
+	"bytes"
+	"cppimpbot/compliance"
+	"cppimpbot/limits"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	apiUrl         = "https://api.telegram.org/bot"
+	requestTimeout = 10 * time.Second
+)
+
+// Bot sends messages through the Telegram bot api
+type Bot struct {
+	token  string
+	client *http.Client
+}
+
+func NewBot(token string) *Bot {
+	return &Bot{
+		token:  token,
+		client: &http.Client{Timeout: requestTimeout},
+	}
+}
+
+type sendMessageRequest struct {
+	ChatID                string `json:"chat_id"`
+	Text                  string `json:"text"`
+	ParseMode             string `json:"parse_mode,omitempty"`
+	DisableWebPagePreview bool   `json:"disable_web_page_preview"`
+}
+
+type apiResponse struct {
+	Ok          bool   `json:"ok"`
+	Description string `json:"description"`
+}
+
+func (b *Bot) send(chatID string, text string, parseMode string) error {
+	payload, err := json.Marshal(sendMessageRequest{ChatID: chatID, Text: text, ParseMode: parseMode, DisableWebPagePreview: true})
+	if err != nil {
+		return errors.Wrap(err, "could not encode telegram message")
+	}
+
+	response, err := b.client.Post(apiUrl+b.token+"/sendMessage", "application/json", bytes.NewReader(payload))
+	if err != nil {
+		//the error holds the url, and with it the token
+		return errors.New(strings.Replace(err.Error(), b.token, "<token>", -1))
+	}
+	defer response.Body.Close()
+
+	result := apiResponse{}
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return errors.Wrapf(err, "could not decode telegram answer to %v", response.Status)
+	}
+
+	if !result.Ok {
+		return fmt.Errorf("telegram refused the message: %v", result.Description)
+	}
+
+	return nil
+}
+
+// SendHTML sends a message formatted with the HTML subset telegram supports
+func (b *Bot) SendHTML(chatID string, text string) error {
+	return b.send(chatID, text, "HTML")
+}
+
+// SendText sends a message as plain text, split into several messages if it is longer than telegram allows
+func (b *Bot) SendText(chatID string, text string) error {
+	for _, message := range limits.Telegram.Split(text) {
+		if err := b.send(chatID, message, ""); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// TextChannel is the channel the wording of telegram messages is registered under, see compliance.LoadTexts
+const TextChannel = "telegram"
+
+// built-in wording of the messages. the html function escapes for the HTML subset telegram supports
+const commonText = `{{define "compilerName"}}{{html (compiler .)}}{{end}}
+{{define "supportLevel"}}{{if eq . 1}}✅{{else if eq . 2}}🔶{{else}}❌{{end}} {{level .}}{{end}}
+{{define "supportText"}}{{html (supportText .)}}{{end}}
+{{define "deltas"}}{{if .Lines}}
+{{end}}{{range .Lines}}
+{{template "listingLine" .}}{{end}}{{end}}
+{{define "paperLine"}}{{with .Paper}}
+
+Paper: {{if $.PaperLink}}<a href="{{html $.PaperLink}}">{{html .}}</a>{{else}}{{html .}}{{end}}{{end}}{{end}}
+{{define "paperRef"}}{{if .Link}}<a href="{{html .Link}}">{{html .String}}</a>{{else}}{{html .String}}{{end}}{{end}}`
+
+var texts = map[string]string{
+	compliance.ChangeRemoved:    `<b>[Removed Listing]</b> {{.Standard}} - <b>{{html .Name}}</b> is no longer listed.{{template "deltas" .}}{{template "paperLine" .}}`,
+	compliance.ChangeNewListing: `<b>[New Listing]</b> {{.Standard}} - <b>{{html .Name}}</b>{{template "deltas" .}}{{template "paperLine" .}}`,
+	compliance.ChangeRename:     `<b>[Renamed]</b> {{.Standard}} - <b>{{html .Name}}</b>, previously listed as <b>{{html .PreviousName}}</b>.{{template "deltas" .}}{{template "paperLine" .}}`,
+	compliance.ChangePaper: `<b>[Paper Update]</b> {{.Standard}} - <b>{{html .Name}}</b>
+
+Paper: {{template "paperChange" .}}{{template "deltas" .}}`,
+	compliance.ChangeSupport: `<b>[Support Update]</b> {{.Standard}} - <b>{{html .Name}}</b>{{template "deltas" .}}{{template "paperLine" .}}`,
+	compliance.ChangeText:    `<b>[Text Update]</b> {{.Standard}} - <b>{{html .Name}}</b>{{template "deltas" .}}{{template "paperLine" .}}`,
+}
+
+func init() {
+	compliance.RegisterTexts(TextChannel, commonText, texts, template.FuncMap{"html": html.EscapeString})
+}
+
+// ChangeMessage renders a change as an HTML message with a line per compiler delta, worded as the options say. ok is
+// false for changes not worth reporting, the same ones the tweets leave out
+func ChangeMessage(options compliance.ReportOptions, change compliance.Change) (message string, ok bool) {
+	message = compliance.RenderText(options, TextChannel, change.Kind, compliance.NewTextData(options, change, ""))
+
+	return message, message != ""
+}
@@ -0,0 +1,71 @@
+package telegram
+
+import (
+	"cppimpbot/compliance"
+	"database/sql"
+	"strings"
+	"testing"
+)
+
+func support(compiler string, level int, displayText string, extraText string) compliance.CompilerSupport {
+	return compliance.CompilerSupport{
+		Compiler:    compiler,
+		Kind:        compliance.KindCompiler,
+		Support:     level,
+		DisplayText: sql.NullString{String: displayText, Valid: true},
+		ExtraText:   sql.NullString{String: extraText, Valid: extraText != ""},
+	}
+}
+
+// supportChange gives GCC, MSVC and Apple Clang gaining support of a feature named name
+func supportChange(t *testing.T, name string) compliance.Change {
+	t.Helper()
+
+	previous := &compliance.Feature{Name: name, CppVersion: 20,
+		PaperName: sql.NullString{String: "P0702R1", Valid: true}, PaperLink: sql.NullString{String: "https://wg21.link/P0702R1", Valid: true}}
+	for _, compiler := range []string{compliance.CompilerGcc, compliance.CompilerMsvc, "apple_clang"} {
+		previous.SetSupport(support(compiler, 0, "", ""))
+	}
+	previous.SetSupport(support(compliance.CompilerClang, 1, "6", ""))
+
+	next := *previous
+	next.Compilers = append([]compliance.CompilerSupport(nil), previous.Compilers...)
+	next.SetSupport(support(compliance.CompilerGcc, 1, "9*", "still some bugs"))
+	next.SetSupport(support(compliance.CompilerMsvc, 1, "19.20", ""))
+	next.SetSupport(support("apple_clang", 1, "10.0.1", ""))
+
+	change, err := compliance.DiffFeatures(previous, &next)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return change
+}
+
+func TestChangeMessage(t *testing.T) {
+	message, ok := ChangeMessage(compliance.ReportOptions{}, supportChange(t, "Initializer list constructors in class template argument deduction"))
+
+	want := `<b>[Support Update]</b> C++20 - <b>Initializer list constructors in class template argument deduction</b>
+
+GCC: ❌ no → ✅ yes 9* (still some bugs)
+MSVC: ❌ no → ✅ yes 19.20
+Apple Clang: ❌ no → ✅ yes 10.0.1
+
+Paper: <a href="https://wg21.link/P0702R1">P0702R1</a>`
+	if !ok || strings.TrimSpace(message) != want {
+		t.Errorf("got\n%v\nwant\n%v", message, want)
+	}
+
+	unreported := supportChange(t, "Modules").OnlyCompilers([]string{compliance.CompilerClang})
+	if _, ok := ChangeMessage(compliance.ReportOptions{}, unreported); ok {
+		t.Errorf("change not worth reporting has a message")
+	}
+}
+
+func TestChangeMessageEscaped(t *testing.T) {
+	message, _ := ChangeMessage(compliance.ReportOptions{}, supportChange(t, "std::vector<bool> & friends"))
+
+	if !strings.HasPrefix(message, "<b>[Support Update]</b> C++20 - <b>std::vector&lt;bool&gt; &amp; friends</b>\n") {
+		t.Errorf("got\n%v", message)
+	}
+}
@@ -0,0 +1,109 @@
+//go:build !notelegram
+// +build !notelegram
+
+package main
+
+import (
+	"cppimpbot/compliance"
+	"cppimpbot/limits"
+	"cppimpbot/notify"
+	"cppimpbot/telegram"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/dghubble/go-twitter/twitter"
+)
+
+func init() {
+	registerReporter("telegram", reporterRegistration{new: newTelegramReporter, lint: lintTelegramMessage, preview: previewTelegramMessage})
+	notifierBackends["telegram"] = telegramNotifier
+}
+
+// telegramReporter posts changes to TelegramChatId
+type telegramReporter struct {
+	cfg     *Configuration
+	options compliance.ReportOptions
+	bot     *telegram.Bot
+}
+
+// newTelegramReporter gives the configured Telegram reporter, or nil if no bot token is configured
+func newTelegramReporter(cfg *Configuration, options compliance.ReportOptions) reporter {
+	if cfg.TelegramBotToken == "" {
+		return nil
+	}
+
+	return &telegramReporter{cfg: cfg, options: options, bot: telegram.NewBot(cfg.TelegramBotToken)}
+}
+
+// ReportChange posts the summary of a change to the report chat, or only logs it if a dry run. changes not worth
+// reporting are skipped
+func (r *telegramReporter) ReportChange(change compliance.Change) error {
+	if r.cfg.TelegramChatId == "" {
+		return nil
+	}
+
+	message, ok := telegram.ChangeMessage(r.options, change)
+	if !ok {
+		return nil
+	}
+
+	if r.cfg.DryReporting {
+		slog.Info("Dry run: posting", "channel", telegram.TextChannel, "feature", change.Feature.Name, "message", message)
+		return nil
+	}
+
+	return r.bot.SendHTML(r.cfg.TelegramChatId, message)
+}
+
+// Alert does nothing, alerts reach the maintainer chat through the telegram notifier
+func (r *telegramReporter) Alert(message string) {
+}
+
+// PostSummary posts the weekly summary to the chat, or only logs it if a dry run
+func (r *telegramReporter) PostSummary(text string) error {
+	if r.cfg.TelegramChatId == "" {
+		return nil
+	}
+
+	if r.cfg.DryReporting {
+		slog.Info("Dry run: posting", "channel", telegram.TextChannel, "message", text)
+		return nil
+	}
+
+	return r.bot.SendText(r.cfg.TelegramChatId, text)
+}
+
+// telegramNotifier sends the messages to the maintainer in the private chat TelegramMaintainerChatId
+func telegramNotifier(cfg *Configuration, client *twitter.Client) (notify.Notifier, error) {
+	if cfg.TelegramBotToken == "" || cfg.TelegramMaintainerChatId == "" {
+		return nil, fmt.Errorf("the telegram notifier needs TelegramBotToken and TelegramMaintainerChatId")
+	}
+
+	bot := telegram.NewBot(cfg.TelegramBotToken)
+	return notify.Func(func(message string) error {
+		return bot.SendText(cfg.TelegramMaintainerChatId, message)
+	}), nil
+}
+
+// lintTelegramMessage checks the parts the message is split into, as telegram refuses parts with unclosed tags
+func lintTelegramMessage(options compliance.ReportOptions, change compliance.Change) (problems []string) {
+	message, ok := telegram.ChangeMessage(options, change)
+	if !ok || strings.TrimSpace(message) == "" {
+		return []string{"no message"}
+	}
+
+	parts := limits.Telegram.Split(message)
+	for i, part := range parts {
+		if strings.Count(part, "<b>") != strings.Count(part, "</b>") {
+			problems = append(problems, fmt.Sprintf("part %v of %v has unclosed tags", i+1, len(parts)))
+		}
+	}
+
+	return
+}
+
+func previewTelegramMessage(options compliance.ReportOptions, change compliance.Change) string {
+	message, _ := telegram.ChangeMessage(options, change)
+	return message
+}
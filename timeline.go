@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var timelineCommand = &cobra.Command{
+	Use:   "timeline <feature>",
+	Short: "Draw how the support of a feature evolved as a strip per compiler, by slug or name",
+	Args:  cobra.ExactArgs(1),
+	RunE:  timelineCmdFunc,
+}
+
+var timelineWidth int
+var timelinePlain bool
+var timelineLanguage string
+
+func init() {
+	timelineCommand.Flags().IntVar(&timelineWidth, "width", 60, "characters per strip")
+	timelineCommand.Flags().BoolVar(&timelinePlain, "plain", false, "use characters instead of terminal colors")
+	timelineCommand.Flags().StringVar(&timelineLanguage, "language", compliance.LanguageCpp, "language to look the feature up in by name, cpp, c or cppdr")
+}
+
+// timelineBlocks are the blocks of the strips per support level, colored and plain
+var timelineBlocks = map[string][2]string{
+	"yes":     {"\x1b[32m█\x1b[0m", "#"},
+	"partial": {"\x1b[33m█\x1b[0m", "~"},
+	"no":      {"\x1b[31m█\x1b[0m", "."},
+	"":        {" ", " "},
+}
+
+// renderTimeline draws a strip per compiler where every character stands for an equal stretch of time
+func renderTimeline(entries []compliance.Feature, end time.Time, width int, plain bool) string {
+	var builder strings.Builder
+
+	style := 0
+	if plain {
+		style = 1
+	}
+
+	latest := &entries[len(entries)-1]
+	fmt.Fprintf(&builder, "%v (%v, %v)\n\n", latest.Name, latest.Standard(), latest.Slug)
+
+	lanes := compliance.Timeline(entries, end)
+
+	labelWidth := 0
+	for _, lane := range lanes {
+		if length := len(compliance.CompilerDisplayName(lane.Compiler)); length > labelWidth {
+			labelWidth = length
+		}
+	}
+
+	start := entries[0].Timestamp
+	step := end.Sub(start) / time.Duration(width)
+
+	for i := range lanes {
+		fmt.Fprintf(&builder, "%-*v |", labelWidth, compliance.CompilerDisplayName(lanes[i].Compiler))
+
+		for column := 0; column < width; column++ {
+			level := ""
+			if span := lanes[i].At(start.Add(step*time.Duration(column) + step/2)); span != nil {
+				level = compliance.SupportLevelName(span.Support.Support)
+			}
+
+			builder.WriteString(timelineBlocks[level][style])
+		}
+
+		builder.WriteString("|\n")
+	}
+
+	startText, endText := start.Format("2006-01-02"), end.Format("2006-01-02")
+	gap := width + 2 - len(startText) - len(endText)
+	if gap < 1 {
+		gap = 1
+	}
+	fmt.Fprintf(&builder, "%-*v %v%v%v\n\n", labelWidth, "", startText, strings.Repeat(" ", gap), endText)
+
+	fmt.Fprintf(&builder, "%v yes  %v partial  %v no\n", timelineBlocks["yes"][style], timelineBlocks["partial"][style], timelineBlocks["no"][style])
+
+	return builder.String()
+}
+
+func timelineCmdFunc(cmd *cobra.Command, args []string) error {
+	cfg := &Configuration{}
+
+	if err := viper.Unmarshal(cfg); err != nil {
+		return err
+	}
+
+	if cfg.StorageMode != "sqlite3" {
+		return fmt.Errorf("timeline needs storageMode sqlite3, not %s", cfg.StorageMode)
+	}
+
+	if timelineWidth < 1 {
+		return fmt.Errorf("--width must be positive")
+	}
+
+	if !compliance.IsLanguage(timelineLanguage) {
+		return fmt.Errorf("unknown language '%s'", timelineLanguage)
+	}
+
+	complianceStorageService, err := openSqliteService(cfg)
+	if err != nil {
+		return err
+	}
+	defer complianceStorageService.Close(context.Background())
+
+	entries, err := complianceStorageService.GetFeatureHistoryBySlug(context.Background(), args[0])
+	if err == nil && len(entries) == 0 {
+		entries, err = complianceStorageService.GetFeatureHistory(context.Background(), timelineLanguage, args[0])
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(entries) == 0 {
+		return fmt.Errorf("no feature with slug or name '%s'", args[0])
+	}
+
+	fmt.Print(renderTimeline(entries, time.Now(), timelineWidth, timelinePlain))
+
+	return nil
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"cppimpbot/compliance"
+	"testing"
+	"time"
+)
+
+func TestRenderTimeline(t *testing.T) {
+	start := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	history := []compliance.Feature{sampleFeature(), sampleFeature(testSupport(compliance.CompilerMsvc, 2, "19.20", "not bug free")), sampleSupported()}
+	for i := range history {
+		history[i].Slug = "p0702"
+		history[i].Timestamp = start.AddDate(i, 0, 0)
+	}
+
+	want := `Initializer list constructors in class template argument deduction (C++20, p0702)
+
+GCC         |........................############|
+Clang       |####################################|
+MSVC        |............~~~~~~~~~~~~############|
+Apple Clang |........................############|
+            2019-01-01                  2022-01-01
+
+# yes  ~ partial  . no
+`
+	if timeline := renderTimeline(history, start.AddDate(3, 0, 0), 36, true); timeline != want {
+		t.Errorf("got\n%v\nwant\n%v", timeline, want)
+	}
+}
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"cppimpbot/limits"
+	"cppimpbot/notify"
+	"cppimpbot/plugins"
+	"cppimpbot/schedule"
+	"cppimpbot/shortener"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dghubble/go-twitter/twitter"
+)
+
+// twitterReporter posts the changes not reported yet as tweets, and reports them on the other channels, every time it
+// runs. it remembers whether reporting was paused and why reports were held back between runs
+type twitterReporter struct {
+	cfg             *Configuration
+	client          *twitter.Client
+	httpClient      *http.Client
+	service         compliance.Service
+	options         compliance.ReportOptions
+	templates       *compliance.ReportTemplates
+	hooks           *compliance.Hooks
+	linkShortener   shortener.Shortener
+	channels        reporters
+	startedPlugins  []*plugins.Plugin
+	notifier        notify.Notifier
+	alert           func(message string)
+	pace            *limits.Pace
+	scrapeScheduler *schedule.Scheduler
+
+	wasPaused      bool
+	heldBackReason string
+}
+
+// reportRun is how a run of the reporter went
+type reportRun struct {
+	failed     int  //entries that could not be reported, they are tried again on the next run
+	incomplete bool //what to report could not be looked at
+	paused     bool //reporting is paused by safe mode
+	stop       bool //the reporter can't go on until restarted
+}
+
+// run reports what is unreported, as far as safe mode, approval and the pace let it. once ctx is done it reports no
+// further entries, but an entry it posted is always marked reported
+func (r *twitterReporter) run(ctx context.Context) reportRun {
+	var result reportRun
+
+	if err := r.service.StoreHeartbeat(context.Background(), time.Now()); err != nil {
+		slog.Error("error storing heartbeat", "err", err)
+	}
+
+	pause, err := r.service.GetReportingPause(ctx)
+	if err != nil {
+		slog.Error("error getting reporting pause, not reporting until it is known", "err", err)
+		result.incomplete = true
+		return result
+	}
+
+	if pause.Paused() {
+		if !r.wasPaused {
+			slog.Warn("reporting is paused by safe mode, waiting for the resume-reporting command", "since", pause.PausedAt.Format(time.RFC3339))
+		}
+		r.wasPaused = true
+		result.paused = true
+		return result
+	} else if r.wasPaused {
+		slog.Info("reporting was resumed")
+		resyncReportQueue(r.service)
+		r.wasPaused = false
+	}
+
+	//a batch holds all changes of a scrape, so it waits for the scrape to be done
+	if r.cfg.ReportBatching && scraping(r.scrapeScheduler) {
+		return result
+	}
+
+	unreportedEntries, err := r.service.GetUnreported(ctx, compliance.ChannelTwitter)
+
+	if err != nil {
+		slog.Error("error getting entries not reported", "channel", compliance.ChannelTwitter, "err", err)
+		result.incomplete = true
+		return result
+	}
+
+	unreportedEntries = pendingReports(r.service, unreportedEntries, time.Now())
+
+	amountToReport := len(unreportedEntries)
+
+	//what the maintainer let through when resuming or is looking at for approval doesn't count again
+	unacknowledged := 0
+	for _, entry := range unreportedEntries {
+		if !pause.Acknowledged(entry.Id) && !approvalRequested(r.cfg, r.service, &entry) {
+			unacknowledged++
+		}
+	}
+
+	if unacknowledged > r.cfg.SafeModeMaxReports && r.cfg.SafeMode {
+		slog.Warn("too many entries to report for safe mode... will not report", "entries", amountToReport, "limit", r.cfg.SafeModeMaxReports)
+
+		if err := r.service.PauseReporting(context.Background(), time.Now(), amountToReport); err != nil {
+			slog.Error("error pausing reporting, stopping the reporter until restarted instead", "err", err)
+			result.stop = true
+			return result
+		}
+		r.wasPaused = true
+		result.paused = true
+
+		message := fmt.Sprintf("Hello! There were too many reports for safe mode (limit is %v). I paused reporting until you look into this and resume it with the resume-reporting command. Amount of reports was %v", r.cfg.SafeModeMaxReports, amountToReport)
+		err = r.notifier.Notify(message)
+
+		if err != nil {
+			slog.Error("did not manage to tell the maintainer that there are too many reports", "entries", amountToReport, "err", err)
+		}
+		r.channels.Alert(message)
+
+		return result
+	}
+
+	//reports of a feature or paper that changed more than once reply to the one before, see reportThreadKey
+	threads := map[string]reportThread{}
+	var batch []batchedReport
+
+	for _, entry := range unreportedEntries {
+		if ctx.Err() != nil {
+			slog.Info("shutting down, leaving the rest of the entries to the next run", "channel", compliance.ChannelTwitter)
+			result.incomplete = true
+			return result
+		}
+
+		previous, err := r.service.GetPreviousEntry(ctx, entry.Id)
+
+		if err != nil {
+			slog.Error("error when getting previous feature entry", "entry", entry.Id, "feature", entry.Name, "err", err)
+			result.failed++
+			continue
+		}
+
+		change, err := compliance.DiffFeatures(previous, &entry)
+
+		if err != nil {
+			slog.Error("not capable of turning update into report. will try to report this as private tweet", "entry", entry.Id, "feature", entry.Name, "err", err)
+			result.failed++
+			if entry.ReportedBroken {
+				slog.Info("this error is already reported, skip entry", "entry", entry.Id, "feature", entry.Name)
+				continue
+			}
+
+			message := fmt.Sprintf("Hello! There was an issue with a change on cppreference that I don't know how to turn into a report.\nThe involved entries are '%v' '%v' and '%v' '%v'. \nFull expansion of those:\n\n%v\n\n%v", previous.Name, previous.Timestamp, entry.Name, entry.Timestamp, previous, entry)
+			err = r.notifier.Notify(message)
+
+			if err != nil {
+				slog.Error("did not manage to tell the maintainer that I couldn't report", "channel", compliance.ChannelTwitter, "entry", entry.Id, "feature", entry.Name, "err", err)
+			} else {
+				slog.Info("error report sent.", "entry", entry.Id, "feature", entry.Name)
+				r.service.SetErrorReported(context.Background(), entry.Id)
+			}
+			continue
+		}
+
+		checkWatchlist(r.service, r.alert, change)
+
+		//changes of muted compilers, languages and ignored features are stored all the same, they just don't make it into reports
+		change = r.hooks.Filter(r.options, withoutIgnored(r.service, reportedChange(r.cfg, change)))
+
+		if r.cfg.ReportBatching {
+			//the change outlives this iteration, so it can't point at the loop variable
+			batched := entry
+			change.Feature = &batched
+			batch = append(batch, batchedReport{batched, change})
+			continue
+		}
+
+		approval, waiting := reportApproval(r.cfg, r.service, &entry)
+		if waiting {
+			continue
+		}
+
+		var twitterThread []string
+		var variant, link, shortLink string
+		if approval != nil {
+			//posted as the maintainer approved it, not as it would be rendered now
+			twitterThread, variant, link, shortLink = limits.Twitter.Split(approval.Text), approval.Variant, approval.Link, approval.ShortLink
+		} else {
+			twitterThread, variant, link, shortLink = renderTwitterReport(r.cfg, r.options, r.templates, r.hooks, r.linkShortener, change)
+			if requestApproval(r.cfg, r.service, r.alert, &entry, twitterThread, variant, link, shortLink) {
+				continue
+			}
+		}
+		twitterReport := strings.Join(twitterThread, "\n\n")
+
+		//held back reports stay unreported, so they are posted once the pace allows
+		if twitterReport != "" && !r.cfg.SupressReporting {
+			if wait, why := r.pace.Wait(time.Now()); wait > 0 {
+				heldBack(&r.heldBackReason, why, wait, len(unreportedEntries))
+				break
+			}
+			r.heldBackReason = ""
+		}
+
+		if !r.cfg.SupressReporting {
+			messagePrefix := "Dry run: "
+			thread, threaded := threads[reportThreadKey(&entry)]
+			threaded = threaded && r.cfg.ReportThreads
+			if !r.cfg.DryReporting && twitterReport != "" { //do not post if we do dry run or message is empty
+				var params *twitter.StatusUpdateParams
+				if threaded {
+					slog.Info("posting the report as a reply", "channel", compliance.ChannelTwitter, "entry", entry.Id, "feature", entry.Name, "replyTo", thread.entryID)
+					params = &twitter.StatusUpdateParams{InReplyToStatusID: thread.lastTweetID}
+				}
+				params = withCard(r.httpClient, entry.Id, reportCard(r.cfg, change), params)
+
+				//a thread an earlier attempt broke off is continued below its last tweet
+				remaining, resumed := resumeThread(r.service, &entry, twitterThread)
+				if resumed != nil {
+					slog.Info("resuming the thread of an earlier attempt", "channel", compliance.ChannelTwitter, "entry", entry.Id, "feature", entry.Name, "posted", resumed.ThreadPosted)
+					params = &twitter.StatusUpdateParams{InReplyToStatusID: resumed.ThreadLastID}
+				}
+
+				var tweetID, lastTweetID int64
+				tweetID, lastTweetID, err = postTweetThread(r.client, remaining, params)
+				if resumed != nil {
+					tweetID = resumed.ThreadFirstID
+					if lastTweetID == 0 {
+						lastTweetID = resumed.ThreadLastID
+					}
+				}
+
+				if err == nil {
+					threads[reportThreadKey(&entry)] = reportThread{entryID: entry.Id, lastTweetID: lastTweetID}
+					post := &compliance.ReportPost{TweetID: tweetID, FeatureID: entry.Id, Kind: change.Kind, Variant: variant, Timestamp: time.Now(), Link: link, ShortLink: shortLink}
+					if err := r.service.StoreReportPost(context.Background(), post); err != nil {
+						slog.Error("error remembering the posted report", "channel", compliance.ChannelTwitter, "entry", entry.Id, "feature", entry.Name, "err", err)
+					}
+				}
+			} else if twitterReport != "" {
+				if threaded {
+					slog.Info(messagePrefix+"posting the report as a reply", "channel", compliance.ChannelTwitter, "entry", entry.Id, "feature", entry.Name, "replyTo", thread.entryID)
+				}
+				if card := reportCard(r.cfg, change); len(card) > 0 {
+					slog.Info(messagePrefix+"attaching an image card", "channel", compliance.ChannelTwitter, "entry", entry.Id, "feature", entry.Name, "bytes", len(card))
+				}
+				slog.Info(messagePrefix+"posting tweet", "channel", compliance.ChannelTwitter, "entry", entry.Id, "feature", entry.Name, "tweet", twitterReport)
+				threads[reportThreadKey(&entry)] = reportThread{entryID: entry.Id}
+			} else {
+				if !r.cfg.DryReporting {
+					messagePrefix = ""
+				}
+				slog.Info(messagePrefix+"found change that I don't care about. setting as reported.", "channel", compliance.ChannelTwitter, "entry", entry.Id, "feature", entry.Name)
+			}
+
+			if err != nil {
+				slog.Error("error posting tweet update", "channel", compliance.ChannelTwitter, "entry", entry.Id, "feature", entry.Name, "err", err)
+				recordReportFailure(r.cfg, r.service, r.alert, &entry, err)
+				if thread, ok := err.(*threadError); ok {
+					recordThreadProgress(r.service, &entry, thread)
+				}
+				result.failed++
+				continue
+			} else {
+				reportOnChannels(r.cfg, r.service, r.channels, entry.Id, change)
+				reportChangeToPlugins(r.cfg, r.startedPlugins, change, twitterReport)
+
+				if twitterReport != "" {
+					r.pace.Posted(time.Now())
+				}
+
+				if !r.cfg.DryReporting {
+					if twitterReport != "" {
+						recordPostLatency(r.cfg, r.service, time.Since(entry.Timestamp))
+					}
+					r.service.SetReported(context.Background(), entry.Id, compliance.ChannelTwitter)
+					if err := r.service.ClearReportAttempt(context.Background(), entry.Id, compliance.ChannelTwitter); err != nil {
+						slog.Error("error clearing report attempts", "channel", compliance.ChannelTwitter, "entry", entry.Id, "feature", entry.Name, "err", err)
+					}
+				}
+			}
+		} else {
+			slog.Info("got report which will be supressed", "channel", compliance.ChannelTwitter, "entry", entry.Id, "feature", entry.Name, "tweet", twitterReport)
+			r.service.SetReported(context.Background(), entry.Id, compliance.ChannelTwitter)
+		}
+	}
+
+	if len(batch) > 0 {
+		if wait, why := r.pace.Wait(time.Now()); wait > 0 && !r.cfg.SupressReporting {
+			heldBack(&r.heldBackReason, why, wait, len(batch))
+		} else {
+			r.heldBackReason = ""
+			if err := reportBatch(r.cfg, r.options, r.client, r.service, r.alert, r.channels, r.startedPlugins, batch); err != nil {
+				result.failed += len(batch)
+			} else if !r.cfg.DryReporting {
+				r.pace.Posted(time.Now())
+			}
+		}
+	}
+
+	return result
+}
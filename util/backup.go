@@ -0,0 +1,39 @@
+package util
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// BackupFile copies the file next to itself with a suffix and the current time, and returns the path of the copy
+func BackupFile(path string, suffix string) (string, error) {
+	backupPath := fmt.Sprintf("%s.%s-%s", path, suffix, time.Now().Format("20060102-150405"))
+
+	if err := CopyFile(path, backupPath); err != nil {
+		return "", err
+	}
+
+	return backupPath, nil
+}
+
+func CopyFile(from string, to string) error {
+	source, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	destination, err := os.OpenFile(to, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(destination, source); err != nil {
+		destination.Close()
+		return err
+	}
+
+	return destination.Close()
+}
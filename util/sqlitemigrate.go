@@ -1,7 +1,7 @@
 package util
 
 import (
-	"log"
+	"log/slog"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/pressly/goose"
@@ -17,17 +17,23 @@ func SqliteConnect(connectionString string) (*sqlx.DB, error) {
 		if db != nil {
 			db.Close()
 		}
-		log.Println("Warning in sqlite: ", err)
+		slog.Warn("Warning in sqlite", "err", err)
 	}
 
 	return db, err
 }
 
+// SqliteConnectReadOnly opens the database file so that nothing can be written through the connection. query_only
+// also refuses writes to temporary tables, which mode=ro allows
+func SqliteConnectReadOnly(path string) (*sqlx.DB, error) {
+	return SqliteConnect("file:" + path + "?mode=ro&_query_only=true")
+}
+
 func SqliteMigrateUp(connectionString string, migrateDir string) error {
 	goose.SetDialect("sqlite3")
 	db, err := SqliteConnect(connectionString)
 	if err != nil {
-		log.Printf("Failed to connect to sqlite with connectionString: %s \n %v", connectionString, err)
+		slog.Error("Failed to connect to sqlite", "connectionString", connectionString, "err", err)
 		return err
 	}
 
@@ -62,3 +68,14 @@ func SqliteMigrateDownTo(connectionString string, migrateDir string, toVersion i
 
 	return goose.DownTo(db.DB, migrateDir, toVersion)
 }
+
+func SqliteMigrationVersion(connectionString string) (int64, error) {
+	goose.SetDialect("sqlite3")
+	db, err := SqliteConnect(connectionString)
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	return goose.GetDBVersion(db.DB)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"cppimpbot/scraper"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// validateScrape rejects a scrape that looks like a broken page rather than like cppreference changed, such as a page
+// served empty or cut short during maintenance, which would otherwise be stored as a flood of removals and changes.
+// stored are the latest entries, nil if they aren't known, which skips comparing with the features known so far
+func validateScrape(cfg *Configuration, stored []compliance.Feature, scraped scraper.CppSupport) error {
+	listed := map[string]int{}
+	var languages []string
+
+	for _, version := range scraped.Versions {
+		for _, feature := range version.Features {
+			if strings.TrimSpace(feature.Name) == "" {
+				return fmt.Errorf("a feature of %v has no name", compliance.StandardName(version.Language, version.Version))
+			}
+
+			if _, ok := listed[version.Language]; !ok {
+				languages = append(languages, version.Language)
+			}
+			listed[version.Language]++
+		}
+	}
+
+	if len(languages) == 0 {
+		return fmt.Errorf("no features listed")
+	}
+
+	if cfg.ScrapeMinKnown <= 0 || stored == nil {
+		return nil
+	}
+
+	current := compliance.CurrentFeatures(stored)
+	for _, language := range languages {
+		known := len(compliance.FeaturesOfLanguage(current, language))
+		if float64(listed[language]) < cfg.ScrapeMinKnown*float64(known) {
+			return fmt.Errorf("only %v of the %v known %v features listed, less than %.0f%%", listed[language], known, language, cfg.ScrapeMinKnown*100)
+		}
+	}
+
+	return nil
+}
+
+// last rejection the maintainer was told about per source, so a page broken for a while is only alerted about once
+var sentRejections = struct {
+	sync.Mutex
+	reasons map[string]string
+}{reasons: map[string]string{}}
+
+// checkScrape validates the scrape of the source, alerting the maintainer the first time it is rejected for a reason
+func checkScrape(cfg *Configuration, complianceStorageService compliance.Service, source string, scraped scraper.CppSupport, alert func(message string)) error {
+	stored, err := complianceStorageService.GetLatestEntries(context.Background())
+	if err != nil {
+		slog.Error("error getting latest entries, not comparing the scrape with the known features", "source", source, "err", err)
+		stored = nil
+	}
+
+	validationErr := validateScrape(cfg, stored, scraped)
+
+	reason := ""
+	if validationErr != nil {
+		reason = validationErr.Error()
+	}
+
+	sentRejections.Lock()
+	alreadySent := sentRejections.reasons[source] == reason
+	sentRejections.reasons[source] = reason
+	sentRejections.Unlock()
+
+	if validationErr == nil {
+		return nil
+	}
+
+	slog.Warn("rejecting scrape, not storing it", "source", source, "reason", reason)
+	if !alreadySent {
+		alert(fmt.Sprintf("Hello! The scrape of %v looks broken, so I didn't store it: %v.", source, reason))
+	}
+
+	return fmt.Errorf("rejected scrape: %v", validationErr)
+}
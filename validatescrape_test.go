@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"cppimpbot/scraper"
+	"fmt"
+	"testing"
+)
+
+// scrapeOf is a scrape listing the named features of version 20 of the language
+func scrapeOf(language string, names ...string) scraper.CppSupport {
+	version := scraper.CppVersionSupport{Language: language, Version: 20}
+	for _, name := range names {
+		version.Features = append(version.Features, scraper.CppFeature{Name: name})
+	}
+
+	return scraper.CppSupport{Versions: []scraper.CppVersionSupport{version}}
+}
+
+// featureNames names count features, Feature 0 and up
+func featureNames(count int) (names []string) {
+	for i := 0; i < count; i++ {
+		names = append(names, fmt.Sprintf("Feature %v", i))
+	}
+
+	return
+}
+
+func TestCheckScrape(t *testing.T) {
+	service := compliance.NewMemoryService()
+	for _, name := range featureNames(10) {
+		feature := &compliance.Feature{Name: name, CppVersion: 20, Compilers: []compliance.CompilerSupport{testSupport("gcc", 1, "11", "")}}
+		if err := service.CreateEntry(context.Background(), feature); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := &Configuration{ScrapeMinKnown: 0.8}
+	for _, test := range []struct {
+		what    string
+		scraped scraper.CppSupport
+		err     string
+		alert   bool
+	}{
+		{"complete", scrapeOf(compliance.LanguageCpp, featureNames(10)...), "", false},
+		{"cut short", scrapeOf(compliance.LanguageCpp, featureNames(7)...), "only 7 of the 10 known cpp features listed, less than 80%", true},
+		{"cut short again", scrapeOf(compliance.LanguageCpp, featureNames(7)...), "only 7 of the 10 known cpp features listed, less than 80%", false},
+		{"empty", scraper.CppSupport{}, "no features listed", true},
+		{"nameless", scrapeOf(compliance.LanguageCpp, append([]string{" "}, featureNames(10)...)...), "a feature of C++20 has no name", true},
+		{"new language", scrapeOf(compliance.LanguageC, "Feature 0"), "", false},
+		{"complete again", scrapeOf(compliance.LanguageCpp, featureNames(8)...), "", false},
+	} {
+		var alerts []string
+		err := checkScrape(cfg, service, "validation test", test.scraped, func(message string) {
+			alerts = append(alerts, message)
+		})
+
+		if test.err == "" && err != nil {
+			t.Errorf("%v: %v", test.what, err)
+		}
+		if test.err != "" && (err == nil || err.Error() != "rejected scrape: "+test.err) {
+			t.Errorf("%v: got %v, want %v", test.what, err, test.err)
+		}
+
+		alert := fmt.Sprintf("Hello! The scrape of validation test looks broken, so I didn't store it: %v.", test.err)
+		if test.alert && (len(alerts) != 1 || alerts[0] != alert) {
+			t.Errorf("%v: got alerts %q, want %q", test.what, alerts, alert)
+		}
+		if !test.alert && len(alerts) != 0 {
+			t.Errorf("%v: got alerts %q", test.what, alerts)
+		}
+	}
+
+	if err := validateScrape(&Configuration{}, nil, scrapeOf(compliance.LanguageCpp, "Feature 0")); err != nil {
+		t.Errorf("scrape without known features: %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dghubble/go-twitter/twitter"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var variantsCommand = &cobra.Command{
+	Use:   "variants",
+	Short: "Print how the report variants were received, as mean likes and retweets per post",
+	RunE:  variantsCmdFunc,
+}
+
+const lookupBatchSize = 100 //tweets the twitter api looks up per request
+
+// engagementJob updates the likes and retweets of the reports posted within the engagement window
+func engagementJob(cfg *Configuration, client *twitter.Client, service compliance.Service) func() {
+	return func() {
+		since := time.Now().AddDate(0, 0, -cfg.EngagementWindow)
+
+		posts, err := service.GetReportPostsSince(context.Background(), since)
+		if err != nil {
+			slog.Error("error getting posted reports", "channel", compliance.ChannelTwitter, "err", err)
+			return
+		}
+
+		for start := 0; start < len(posts); start += lookupBatchSize {
+			end := start + lookupBatchSize
+			if end > len(posts) {
+				end = len(posts)
+			}
+
+			var ids []int64
+			for _, post := range posts[start:end] {
+				ids = append(ids, post.TweetID)
+			}
+
+			tweets, _, err := client.Statuses.Lookup(ids, nil)
+			if err != nil {
+				slog.Error("error looking up posted reports", "channel", compliance.ChannelTwitter, "err", err)
+				return
+			}
+
+			for _, tweet := range tweets {
+				if err := service.UpdateReportPostEngagement(context.Background(), tweet.ID, tweet.FavoriteCount, tweet.RetweetCount); err != nil {
+					slog.Error("error updating engagement", "channel", compliance.ChannelTwitter, "tweet", tweet.ID, "err", err)
+				}
+			}
+		}
+	}
+}
+
+func variantsCmdFunc(cmd *cobra.Command, args []string) error {
+	cfg := &Configuration{}
+
+	if err := viper.Unmarshal(cfg); err != nil {
+		return err
+	}
+
+	if cfg.StorageMode != "sqlite3" {
+		return fmt.Errorf("variants needs storageMode sqlite3, not %s", cfg.StorageMode)
+	}
+
+	complianceStorageService, err := openSqliteService(cfg)
+	if err != nil {
+		return err
+	}
+	defer complianceStorageService.Close(context.Background())
+
+	posts, err := complianceStorageService.GetReportPostsSince(context.Background(), time.Time{})
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "kind\tvariant\tposts\tlikes\tretweets")
+	for _, stats := range compliance.SummarizeVariants(posts) {
+		fmt.Fprintf(writer, "%v\t%v\t%v\t%.1f\t%.1f\n", stats.Kind, stats.Variant, stats.Posts, stats.MeanLikes, stats.MeanRetweets)
+	}
+
+	return writer.Flush()
+}
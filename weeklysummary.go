@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/dghubble/go-twitter/twitter"
+	"github.com/dghubble/oauth1"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var weeklySummaryCommand = &cobra.Command{
+	Use:   "weekly-summary",
+	Short: "Print the summary of the changes of the last week, and optionally post it",
+	Long: `Print the summary of the changes of the last 7 days, counted per compiler and per standard with the features
+that came to be fully supported as highlights. With --post it is posted on twitter and every reporter taking it, like
+Slack and Telegram, honoring SupressReporting and DryReporting.`,
+	RunE: weeklySummaryCmdFunc,
+}
+
+var weeklySummarySince string
+var weeklySummaryPost bool
+
+func init() {
+	weeklySummaryCommand.Flags().StringVar(&weeklySummarySince, "since", "", "start of the summary as RFC 3339 time. defaults to a week ago")
+	weeklySummaryCommand.Flags().BoolVar(&weeklySummaryPost, "post", false, "post the summary")
+}
+
+const week = 7 * 24 * time.Hour
+
+// summarizeWeek sums up the reportable changes of the entries stored between since and until, limited to the compilers
+// reported on
+func summarizeWeek(cfg *Configuration, service compliance.Service, since time.Time, until time.Time) (compliance.WeeklySummary, error) {
+	changes, err := digestChanges(cfg, service, since, until)
+	if err != nil {
+		return compliance.WeeklySummary{}, err
+	}
+
+	return compliance.SummarizeWeek(changes, since, until), nil
+}
+
+// postWeeklySummary posts the summary of the changes between since and until as a thread, then on the reporters
+// taking it. the thread decides if it counts as posted, failing reporters are only logged. weeks without changes post
+// nothing but are recorded all the same
+func postWeeklySummary(cfg *Configuration, client *twitter.Client, httpClient *http.Client, service compliance.Service, channels reporters, since time.Time, until time.Time) error {
+	summary, err := summarizeWeek(cfg, service, since, until)
+	if err != nil {
+		return err
+	}
+
+	if summary.Changes == 0 {
+		slog.Info("no changes for the weekly summary", "since", since.Format(time.RFC3339))
+	} else {
+		if err := postThread(cfg, client, httpClient, compliance.WeeklySummaryToTwitterThread(summary, cfg.WeeklySummaryMaxTweets), nil); err != nil {
+			//a thread that broke off is out already, posting it again would repeat its start
+			if _, broken := err.(*threadError); !broken {
+				return err
+			}
+			slog.Error("the thread of the weekly summary broke off, not posting it again", "channel", compliance.ChannelTwitter, "err", err)
+		}
+
+		if !cfg.SupressReporting {
+			channels.PostSummary(compliance.WeeklySummaryText(summary))
+		}
+	}
+
+	if cfg.DryReporting && !cfg.SupressReporting {
+		return nil
+	}
+
+	return service.StoreWeeklySummary(context.Background(), until, summary.Changes)
+}
+
+// weeklySummaryJob posts the summary of the week since the last one, or of the last 7 days if there was none or it
+// was longer ago. a summary posted less than a day ago, like with the weekly-summary command, isn't posted again
+func weeklySummaryJob(cfg *Configuration, client *twitter.Client, httpClient *http.Client, service compliance.Service, channels reporters) func() {
+	return func() {
+		now := time.Now()
+
+		last, err := service.GetLastWeeklySummary(context.Background())
+		if err != nil {
+			slog.Error("error getting the last weekly summary", "err", err)
+			return
+		}
+
+		if now.Sub(last) < 24*time.Hour {
+			return
+		}
+
+		since := now.Add(-week)
+		if last.After(since) {
+			since = last
+		}
+
+		if err := postWeeklySummary(cfg, client, httpClient, service, channels, since, now); err != nil {
+			slog.Error("error posting the weekly summary", "channel", compliance.ChannelTwitter, "err", err)
+		}
+	}
+}
+
+func weeklySummaryCmdFunc(cmd *cobra.Command, args []string) error {
+	cfg := &Configuration{}
+
+	if err := viper.Unmarshal(cfg); err != nil {
+		return err
+	}
+
+	if cfg.StorageMode != "sqlite3" {
+		return fmt.Errorf("weekly-summary needs storageMode sqlite3, not %s", cfg.StorageMode)
+	}
+
+	until := time.Now()
+	since := until.Add(-week)
+	if weeklySummarySince != "" {
+		var err error
+		if since, err = time.Parse(time.RFC3339, weeklySummarySince); err != nil {
+			return fmt.Errorf("--since must be an RFC 3339 time")
+		}
+	}
+
+	complianceStorageService, err := openSqliteService(cfg)
+	if err != nil {
+		return err
+	}
+	defer complianceStorageService.Close(context.Background())
+
+	if weeklySummaryPost {
+		reportOptions, err := loadReportOptions(cfg)
+		if err != nil {
+			return err
+		}
+
+		channels, err := newReporters(cfg, reportOptions)
+		if err != nil {
+			return err
+		}
+
+		httpClient := oauth1.NewConfig(cfg.ConsumerKey, cfg.ConsumerSecret).Client(oauth1.NoContext, oauth1.NewToken(cfg.AccessToken, cfg.AccessSecret))
+		return postWeeklySummary(cfg, twitter.NewClient(httpClient), httpClient, complianceStorageService, channels, since, until)
+	}
+
+	summary, err := summarizeWeek(cfg, complianceStorageService, since, until)
+	if err != nil {
+		return err
+	}
+
+	for _, tweet := range compliance.WeeklySummaryToTwitterThread(summary, cfg.WeeklySummaryMaxTweets) {
+		fmt.Printf("%v\n\n", tweet)
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWeeklySummary(t *testing.T) {
+	now := time.Now().Add(-time.Hour)
+	service := compliance.NewMemoryService()
+	service.Now = func() time.Time {
+		now = now.Add(time.Minute)
+		return now
+	}
+
+	features := []compliance.Feature{
+		{Name: "Modules", CppVersion: 20, Compilers: []compliance.CompilerSupport{testSupport("gcc", 2, "11", ""), testSupport("clang", 0, "", "")}},
+		{Name: "Pack indexing", CppVersion: 26, Compilers: []compliance.CompilerSupport{testSupport("gcc", 0, "", ""), testSupport("clang", 0, "", "")}},
+		{Name: "Embed", CppVersion: 23, Language: compliance.LanguageC, Compilers: []compliance.CompilerSupport{testSupport("gcc", 0, "", "")}},
+	}
+	for i := range features {
+		if err := service.CreateEntry(context.Background(), &features[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	since := now
+	for _, update := range []struct {
+		name    string
+		support compliance.CompilerSupport
+	}{
+		{"Modules", testSupport("gcc", 1, "14", "")},
+		{"Modules", testSupport("clang", 2, "17", "")},
+		{"Pack indexing", testSupport("clang", 1, "19", "")},
+		{"Embed", testSupport("gcc", 1, "15", "")},
+	} {
+		for i := range features {
+			if features[i].Name == update.name {
+				features[i].SetSupport(update.support)
+				if err := service.CreateEntry(context.Background(), &features[i]); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+	}
+	reflection := compliance.Feature{Name: "Reflection", CppVersion: 26, Compilers: []compliance.CompilerSupport{testSupport("clang", 0, "", "")}}
+	if err := service.CreateEntry(context.Background(), &reflection); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &Configuration{WeeklySummaryMaxTweets: 4}
+	summary, err := summarizeWeek(cfg, service, since, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Changes != 5 || summary.NewListings != 1 || len(summary.Highlights) != 4 {
+		t.Errorf("got %v changes, %v new listings and highlights %q", summary.Changes, summary.NewListings, summary.Highlights)
+	}
+
+	//a dry run posts the summary of the last 7 days on the reporters, but doesn't record it
+	var summaries []string
+	cfg.DryReporting = true
+	weeklySummaryJob(cfg, nil, nil, service, reporters{{reporter: testReporter{summaries: &summaries}, name: "logged"}})()
+	if len(summaries) != 1 || !strings.Contains(summaries[0], "8 changes on cppreference, 4 new features listed") {
+		t.Errorf("dry run posted %q", summaries)
+	}
+	if last, err := service.GetLastWeeklySummary(context.Background()); !last.IsZero() || err != nil {
+		t.Errorf("dry run recorded a summary at %v, %v", last, err)
+	}
+
+	//suppressed reports post nothing, but the summary counts as done
+	summaries = nil
+	cfg.DryReporting, cfg.SupressReporting = false, true
+	weeklySummaryJob(cfg, nil, nil, service, reporters{{reporter: testReporter{summaries: &summaries}, name: "logged"}})()
+	if !reflect.DeepEqual(summaries, []string(nil)) {
+		t.Errorf("suppressed run posted %q", summaries)
+	}
+	if last, err := service.GetLastWeeklySummary(context.Background()); last.IsZero() || err != nil {
+		t.Errorf("suppressed run recorded no summary, %v", err)
+	}
+}
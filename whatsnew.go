@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"cppimpbot/compliance"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var whatsNewCommand = &cobra.Command{
+	Use:   "whats-new <compiler> <version>",
+	Short: "List the features whose support names the given compiler version, like whats-new gcc 14",
+	Args:  cobra.ExactArgs(2),
+	RunE:  whatsNewCmdFunc,
+}
+
+var whatsNewFormat string
+
+func init() {
+	whatsNewCommand.Flags().StringVar(&whatsNewFormat, "format", "markdown", "output format, markdown or text")
+}
+
+// whatsNewFeatures picks the features supporting the compiler in the version series, C++ before C and newest standard first
+func whatsNewFeatures(features []compliance.Feature, compiler string, version string) (result []compliance.Feature) {
+	for _, feature := range features {
+		support := feature.SupportFor(compiler)
+		if support != nil && support.Support != compliance.SupportNo && compliance.VersionIn(support.Version, version) {
+			result = append(result, feature)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Language != result[j].Language {
+			return result[i].Language == compliance.LanguageCpp
+		}
+		return compliance.StandardYear(result[i].CppVersion) > compliance.StandardYear(result[j].CppVersion)
+	})
+
+	return
+}
+
+func renderWhatsNew(features []compliance.Feature, compiler string, version string, markdown bool) string {
+	var builder strings.Builder
+
+	title := fmt.Sprintf("What's new in %v %v", compliance.CompilerDisplayName(compiler), version)
+	if markdown {
+		fmt.Fprintf(&builder, "# %v\n", title)
+	} else {
+		fmt.Fprintf(&builder, "%v\n%v\n", title, strings.Repeat("=", len(title)))
+	}
+
+	if len(features) == 0 {
+		builder.WriteString("\nNo tracked feature lists this version.\n")
+		return builder.String()
+	}
+
+	standard := ""
+	for i := range features {
+		feature := &features[i]
+		support := feature.SupportFor(compiler)
+
+		if feature.Standard() != standard {
+			standard = feature.Standard()
+			if markdown {
+				fmt.Fprintf(&builder, "\n## %v\n\n", standard)
+			} else {
+				fmt.Fprintf(&builder, "\n%v\n\n", standard)
+			}
+		}
+
+		paper := feature.PaperName.String
+		if markdown && paper != "" && feature.PaperLink.String != "" {
+			paper = fmt.Sprintf("[%v](%v)", paper, feature.PaperLink.String)
+		}
+
+		name := feature.Name
+		if markdown {
+			name = "**" + name + "**"
+		}
+
+		line := "- " + name
+		if paper != "" {
+			line += " (" + paper + ")"
+		}
+		line += fmt.Sprintf(": %v %v", compliance.SupportLevelName(support.Support), support.DisplayText.String)
+		if support.ExtraText.String != "" {
+			line += fmt.Sprintf(" (%v)", support.ExtraText.String)
+		}
+
+		builder.WriteString(line + "\n")
+	}
+
+	return builder.String()
+}
+
+func whatsNewCmdFunc(cmd *cobra.Command, args []string) error {
+	cfg := &Configuration{}
+
+	if err := viper.Unmarshal(cfg); err != nil {
+		return err
+	}
+
+	if cfg.StorageMode != "sqlite3" {
+		return fmt.Errorf("whats-new needs storageMode sqlite3, not %s", cfg.StorageMode)
+	}
+
+	if whatsNewFormat != "markdown" && whatsNewFormat != "text" {
+		return fmt.Errorf("unknown format '%s', expected markdown or text", whatsNewFormat)
+	}
+
+	//library names like libstdc++ only have a key among the library headers
+	compiler := compliance.CompilerKey(args[0])
+	if libraryKey := compliance.LibraryKey(args[0]); compliance.CompilerDisplayName(compiler) == compiler {
+		compiler = libraryKey
+	}
+	version := args[1]
+
+	complianceStorageService, err := openSqliteService(cfg)
+	if err != nil {
+		return err
+	}
+	defer complianceStorageService.Close(context.Background())
+
+	latest, err := complianceStorageService.GetLatestEntries(context.Background())
+	if err != nil {
+		return err
+	}
+
+	features := whatsNewFeatures(compliance.CurrentFeatures(latest), compiler, version)
+	fmt.Print(renderWhatsNew(features, compiler, version, whatsNewFormat == "markdown"))
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"log/slog"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var serviceCommand = &cobra.Command{
+	Use:   "service",
+	Short: "Install or remove the bot as a Windows service",
+	Long: `Install or remove the bot as a Windows service, started automatically with the host.
+The service runs the bot from the directory of its binary, so relative paths in the config are resolved from there, and
+logs to cppimpbot.log next to the binary. The service uses the config the install command read, config.toml in the
+current directory unless given with --config. On other platforms run the bot with the init system of the host instead, it
+shuts down gracefully on SIGTERM.`,
+}
+
+var serviceInstallCommand = &cobra.Command{
+	Use:   "install",
+	Short: "Install the bot as a Windows service",
+	Args:  cobra.NoArgs,
+	RunE:  serviceInstallCmdFunc,
+}
+
+var serviceRemoveCommand = &cobra.Command{
+	Use:   "remove",
+	Short: "Remove the Windows service of the bot",
+	Args:  cobra.NoArgs,
+	RunE:  serviceRemoveCmdFunc,
+}
+
+// name of the windows service the bot runs as
+var serviceName string
+
+func init() {
+	serviceCommand.PersistentFlags().StringVar(&serviceName, "name", "cppimpbot", "name of the service")
+
+	serviceCommand.AddCommand(serviceInstallCommand)
+	serviceCommand.AddCommand(serviceRemoveCommand)
+}
+
+func serviceInstallCmdFunc(cmd *cobra.Command, args []string) error {
+	var serviceArgs []string
+	if config := viper.ConfigFileUsed(); config != "" {
+		//services don't start where the service was installed from
+		config, err := filepath.Abs(config)
+		if err != nil {
+			return err
+		}
+		serviceArgs = append(serviceArgs, "--config", config)
+	}
+
+	if err := installService(serviceName, serviceArgs); err != nil {
+		return err
+	}
+	slog.Info("installed service", "service", serviceName)
+
+	return nil
+}
+
+func serviceRemoveCmdFunc(cmd *cobra.Command, args []string) error {
+	if err := removeService(serviceName); err != nil {
+		return err
+	}
+	slog.Info("removed service", "service", serviceName)
+
+	return nil
+}
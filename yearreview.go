@@ -0,0 +1,309 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"cppimpbot/compliance"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/dghubble/go-twitter/twitter"
+	"github.com/dghubble/oauth1"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var yearReviewCommand = &cobra.Command{
+	Use:   "year-review <year>",
+	Short: "Print the year in review thread of a year, and optionally post it",
+	Long: `Print the year in review thread of a year, summing up the compliance progress per compiler and library.
+With --post the thread is posted with the matrix animation attached, honoring SupressReporting and DryReporting,
+and the year is marked as reviewed so the scheduled review won't post it again.`,
+	Args: cobra.ExactArgs(1),
+	RunE: yearReviewCmdFunc,
+}
+
+var yearReviewOutput string
+var yearReviewPost bool
+
+func init() {
+	yearReviewCommand.Flags().StringVarP(&yearReviewOutput, "output", "o", "", "file to write the animation of the year to")
+	yearReviewCommand.Flags().BoolVar(&yearReviewPost, "post", false, "post the thread")
+}
+
+const (
+	mediaUploadUrl   = "https://upload.twitter.com/1.1/media/upload.json"
+	mediaChunkSize   = 1024 * 1024
+	yearReviewFrames = 500 //milliseconds every month is shown in the animation
+	yearReviewHold   = 4000
+)
+
+// reviewYear gathers the snapshots of a year, up to now if it isn't over yet, and reviews them
+func reviewYear(service compliance.Service, year int) (compliance.YearReview, error) {
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Now()
+
+	var snapshots [][]compliance.Feature
+	for month := 0; month <= 12; month++ {
+		at := start.AddDate(0, month, 0)
+		if month > 0 && at.After(now) {
+			at = now
+		}
+
+		features, err := service.GetEntriesAt(context.Background(), at)
+		if err != nil {
+			return compliance.YearReview{}, err
+		}
+		//the review counts per C++ version, which the C versions would mix into
+		snapshots = append(snapshots, compliance.CurrentFeatures(compliance.FeaturesOfLanguage(features, compliance.LanguageCpp)))
+
+		if at.Equal(now) {
+			break
+		}
+	}
+
+	return compliance.ReviewYear(year, snapshots), nil
+}
+
+type mediaProcessingInfo struct {
+	State          string `json:"state"`
+	CheckAfterSecs int    `json:"check_after_secs"`
+	Error          *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+type mediaUploadResponse struct {
+	MediaID        int64                `json:"media_id"`
+	ProcessingInfo *mediaProcessingInfo `json:"processing_info"`
+}
+
+// mediaRequest sends a request to the media upload endpoint and decodes the answer, if there is any
+func mediaRequest(httpClient *http.Client, request *http.Request) (*mediaUploadResponse, error) {
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return nil, fmt.Errorf("media upload answered %v: %s", response.Status, body)
+	}
+
+	result := &mediaUploadResponse{}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, result); err != nil {
+			return nil, errors.Wrap(err, "could not decode media upload answer")
+		}
+	}
+
+	return result, nil
+}
+
+func mediaCommand(httpClient *http.Client, values url.Values) (*mediaUploadResponse, error) {
+	request, err := http.NewRequest(http.MethodPost, mediaUploadUrl, bytes.NewBufferString(values.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	return mediaRequest(httpClient, request)
+}
+
+// uploadMedia uploads a file in chunks as the twitter api wants it for GIFs, and waits for it to be processed.
+// the client vendored here has no media support, so this talks to the upload endpoint directly
+func uploadMedia(httpClient *http.Client, data []byte, mediaType string, category string) (int64, error) {
+	initialized, err := mediaCommand(httpClient, url.Values{
+		"command":        {"INIT"},
+		"total_bytes":    {strconv.Itoa(len(data))},
+		"media_type":     {mediaType},
+		"media_category": {category},
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "could not start media upload")
+	}
+	mediaID := strconv.FormatInt(initialized.MediaID, 10)
+
+	for segment := 0; segment*mediaChunkSize < len(data); segment++ {
+		end := (segment + 1) * mediaChunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		writer.WriteField("command", "APPEND")
+		writer.WriteField("media_id", mediaID)
+		writer.WriteField("segment_index", strconv.Itoa(segment))
+		part, err := writer.CreateFormFile("media", "media")
+		if err != nil {
+			return 0, err
+		}
+		part.Write(data[segment*mediaChunkSize : end])
+		writer.Close()
+
+		request, err := http.NewRequest(http.MethodPost, mediaUploadUrl, &body)
+		if err != nil {
+			return 0, err
+		}
+		request.Header.Set("Content-Type", writer.FormDataContentType())
+
+		if _, err := mediaRequest(httpClient, request); err != nil {
+			return 0, errors.Wrapf(err, "could not upload media segment %v", segment)
+		}
+	}
+
+	finalized, err := mediaCommand(httpClient, url.Values{"command": {"FINALIZE"}, "media_id": {mediaID}})
+	if err != nil {
+		return 0, errors.Wrap(err, "could not finish media upload")
+	}
+
+	info := finalized.ProcessingInfo
+	for info != nil && (info.State == "pending" || info.State == "in_progress") {
+		time.Sleep(time.Duration(info.CheckAfterSecs) * time.Second)
+
+		request, err := http.NewRequest(http.MethodGet, mediaUploadUrl+"?"+url.Values{"command": {"STATUS"}, "media_id": {mediaID}}.Encode(), nil)
+		if err != nil {
+			return 0, err
+		}
+
+		status, err := mediaRequest(httpClient, request)
+		if err != nil {
+			return 0, errors.Wrap(err, "could not check media processing")
+		}
+		info = status.ProcessingInfo
+	}
+
+	if info != nil && info.State == "failed" {
+		message := ""
+		if info.Error != nil {
+			message = info.Error.Message
+		}
+		return 0, fmt.Errorf("media processing failed: %v", message)
+	}
+
+	return initialized.MediaID, nil
+}
+
+// postYearReview posts the review of a year unless it was posted already
+func postYearReview(cfg *Configuration, client *twitter.Client, httpClient *http.Client, service compliance.Service, year int) error {
+	posted, err := service.GetYearReviewPosted(context.Background(), year)
+	if err != nil || posted {
+		return err
+	}
+
+	review, err := reviewYear(service, year)
+	if err != nil {
+		return err
+	}
+
+	if len(review.Vendors) == 0 {
+		slog.Info("nothing was listed by the end of the year, there is nothing to review", "year", year)
+		return nil
+	}
+
+	gif, err := yearAnimation(service, year)
+	if err != nil {
+		//a review without the animation is better than none
+		slog.Warn("could not render the animation, posting the review without it", "year", year, "err", err)
+	}
+
+	if err := postThread(cfg, client, httpClient, compliance.YearReviewToTwitterThread(review), gif); err != nil {
+		//a thread that broke off is out already, posting it again would repeat its start
+		if _, broken := err.(*threadError); !broken {
+			return err
+		}
+		slog.Error("the thread of the year review broke off, not posting it again", "channel", compliance.ChannelTwitter, "year", year, "err", err)
+	}
+
+	if cfg.DryReporting && !cfg.SupressReporting {
+		return nil
+	}
+
+	return service.StoreYearReviewPosted(context.Background(), year)
+}
+
+// yearReviewJob posts the review of the previous year once January has come. dry runs aren't stored as posted, so
+// the job remembers the year it handled to not log the thread on every run
+func yearReviewJob(cfg *Configuration, client *twitter.Client, httpClient *http.Client, service compliance.Service) func() {
+	handled := 0
+
+	return func() {
+		now := time.Now()
+		year := now.Year() - 1
+		if now.Month() != time.January || year == handled {
+			return
+		}
+
+		if err := postYearReview(cfg, client, httpClient, service, year); err != nil {
+			slog.Error("error posting the year in review", "channel", compliance.ChannelTwitter, "err", err)
+			return
+		}
+
+		handled = year
+	}
+}
+
+func yearReviewCmdFunc(cmd *cobra.Command, args []string) error {
+	cfg := &Configuration{}
+
+	if err := viper.Unmarshal(cfg); err != nil {
+		return err
+	}
+
+	if cfg.StorageMode != "sqlite3" {
+		return fmt.Errorf("year-review needs storageMode sqlite3, not %s", cfg.StorageMode)
+	}
+
+	year, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("'%s' is not a year", args[0])
+	}
+
+	complianceStorageService, err := openSqliteService(cfg)
+	if err != nil {
+		return err
+	}
+	defer complianceStorageService.Close(context.Background())
+
+	if yearReviewPost {
+		httpClient := oauth1.NewConfig(cfg.ConsumerKey, cfg.ConsumerSecret).Client(oauth1.NoContext, oauth1.NewToken(cfg.AccessToken, cfg.AccessSecret))
+		return postYearReview(cfg, twitter.NewClient(httpClient), httpClient, complianceStorageService, year)
+	}
+
+	review, err := reviewYear(complianceStorageService, year)
+	if err != nil {
+		return err
+	}
+
+	for _, tweet := range compliance.YearReviewToTwitterThread(review) {
+		fmt.Printf("%v\n\n", tweet)
+	}
+
+	if yearReviewOutput != "" {
+		gif, err := yearAnimation(complianceStorageService, year)
+		if err != nil {
+			return err
+		}
+
+		if err := ioutil.WriteFile(yearReviewOutput, gif, 0644); err != nil {
+			return err
+		}
+		slog.Info("wrote the animation", "year", year, "output", yearReviewOutput)
+	}
+
+	return nil
+}